@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Directive that overrides the name of an operation in a method's doc comment, e.g. "//nexus:operation my-operation".
+const operationNameDirective = "nexus:operation"
+
+const nexusImportPath = "github.com/nexus-rpc/sdk-go/nexus"
+
+// service is a service definition parsed from a Go interface.
+type service struct {
+	Name       string
+	Operations []operation
+}
+
+// operation is a single operation of a service, defined by an interface method.
+type operation struct {
+	// Name of the interface method.
+	Method string
+	// Name of the operation used for invocation.
+	Name   string
+	Input  string
+	Output string
+}
+
+type importSpec struct {
+	Name string
+	Path string
+}
+
+// parseServices parses the Go interfaces with the given names in src into service definitions.
+//
+// Every method of an interface defines an operation. Methods take at most one parameter, the operation input, and
+// return at most one result, the operation output. Omitted inputs and outputs are [nexus.NoValue].
+func parseServices(filename string, src []byte, typeNames []string) (pkg string, services []service, imports []importSpec, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	fileImports := make(map[string]importSpec)
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		imp := importSpec{Path: p}
+		name := path.Base(p)
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+			name = spec.Name.Name
+		}
+		fileImports[name] = imp
+	}
+	usedImports := make(map[string]importSpec)
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				interfaces[typeSpec.Name.Name] = iface
+			}
+		}
+	}
+
+	typeString := func(expr ast.Expr) (string, error) {
+		var err error
+		ast.Inspect(expr, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				imp, ok := fileImports[ident.Name]
+				if !ok {
+					err = fmt.Errorf("%s: unknown package %q", fset.Position(ident.Pos()), ident.Name)
+				}
+				usedImports[imp.Path] = imp
+			}
+			return false
+		})
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, expr); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	for _, typeName := range typeNames {
+		iface, ok := interfaces[typeName]
+		if !ok {
+			return "", nil, nil, fmt.Errorf("interface %q not found in %s", typeName, filename)
+		}
+		svc := service{Name: typeName}
+		names := make(map[string]bool)
+		for _, method := range iface.Methods.List {
+			fn, ok := method.Type.(*ast.FuncType)
+			if !ok || len(method.Names) != 1 {
+				return "", nil, nil, fmt.Errorf("%s: embedded interfaces are not supported", fset.Position(method.Pos()))
+			}
+			op := operation{
+				Method: method.Names[0].Name,
+				Name:   method.Names[0].Name,
+				Input:  "nexus.NoValue",
+				Output: "nexus.NoValue",
+			}
+			if method.Doc != nil {
+				for _, comment := range method.Doc.List {
+					text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+					if name, ok := strings.CutPrefix(text, operationNameDirective+" "); ok {
+						op.Name = strings.TrimSpace(name)
+					}
+				}
+			}
+			if names[op.Name] {
+				return "", nil, nil, fmt.Errorf("%s: duplicate operation name %q", fset.Position(method.Pos()), op.Name)
+			}
+			names[op.Name] = true
+
+			params := fieldTypes(fn.Params)
+			results := fieldTypes(fn.Results)
+			if len(params) > 1 || len(results) > 1 {
+				return "", nil, nil, fmt.Errorf("%s: operation methods must have at most one parameter and one result", fset.Position(method.Pos()))
+			}
+			if len(params) == 1 {
+				if op.Input, err = typeString(params[0]); err != nil {
+					return "", nil, nil, err
+				}
+			}
+			if len(results) == 1 {
+				if op.Output, err = typeString(results[0]); err != nil {
+					return "", nil, nil, err
+				}
+			}
+			svc.Operations = append(svc.Operations, op)
+		}
+		if len(svc.Operations) == 0 {
+			return "", nil, nil, fmt.Errorf("interface %q does not define any operations", typeName)
+		}
+		services = append(services, svc)
+	}
+
+	for _, imp := range usedImports {
+		if imp.Path != nexusImportPath && imp.Path != "context" {
+			imports = append(imports, imp)
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path < imports[j].Path })
+	return file.Name.Name, services, imports, nil
+}
+
+// fieldTypes returns the type of every field in the list, expanding fields that declare multiple names.
+func fieldTypes(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, field := range fields.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
+
+var outputTemplate = template.Must(template.New("output").Parse(`// Code generated by nexus-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+{{- range .Imports }}
+	{{ .Name }} "{{ .Path }}"
+{{- end }}
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+{{ range $svc := .Services }}
+// Operation references of the {{ $svc.Name }} service.
+var (
+{{- range $svc.Operations }}
+	{{ $svc.Name }}{{ .Method }}Operation = nexus.NewOperationReference[{{ .Input }}, {{ .Output }}]({{ printf "%q" .Name }})
+{{- end }}
+)
+
+// {{ $svc.Name }}Client is a typed client for the {{ $svc.Name }} service.
+type {{ $svc.Name }}Client struct {
+	client *nexus.Client
+}
+
+// New{{ $svc.Name }}Client creates a {{ $svc.Name }}Client that issues requests via the given client.
+func New{{ $svc.Name }}Client(client *nexus.Client) *{{ $svc.Name }}Client {
+	return &{{ $svc.Name }}Client{client: client}
+}
+{{ range $svc.Operations }}
+// {{ .Method }} executes the {{ printf "%q" .Name }} operation and waits for its result.
+func (c *{{ $svc.Name }}Client) {{ .Method }}(ctx context.Context, input {{ .Input }}, options nexus.ExecuteOperationOptions) ({{ .Output }}, error) {
+	return nexus.ExecuteOperation(ctx, c.client, {{ $svc.Name }}{{ .Method }}Operation, input, options)
+}
+
+// Start{{ .Method }} starts the {{ printf "%q" .Name }} operation.
+func (c *{{ $svc.Name }}Client) Start{{ .Method }}(ctx context.Context, input {{ .Input }}, options nexus.StartOperationOptions) (*nexus.ClientStartOperationResult[{{ .Output }}], error) {
+	return nexus.StartOperation(ctx, c.client, {{ $svc.Name }}{{ .Method }}Operation, input, options)
+}
+{{ end }}
+// {{ $svc.Name }}Handler handles the operations of the {{ $svc.Name }} service.
+//
+// Implementations must embed Unimplemented{{ $svc.Name }}Handler for forward compatibility.
+type {{ $svc.Name }}Handler interface {
+{{- range $svc.Operations }}
+	{{ .Method }}(ctx context.Context, input {{ .Input }}, options nexus.StartOperationOptions) ({{ .Output }}, error)
+{{- end }}
+	mustEmbedUnimplemented{{ $svc.Name }}Handler()
+}
+
+// Unimplemented{{ $svc.Name }}Handler must be embedded into any {{ $svc.Name }}Handler implementation for future
+// compatibility. It implements all operations, returning unimplemented errors.
+type Unimplemented{{ $svc.Name }}Handler struct{}
+{{ range $svc.Operations }}
+// {{ .Method }} implements {{ $svc.Name }}Handler.
+func (Unimplemented{{ $svc.Name }}Handler) {{ .Method }}(ctx context.Context, input {{ .Input }}, options nexus.StartOperationOptions) ({{ .Output }}, error) {
+	var output {{ .Output }}
+	return output, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotImplemented, "not implemented")
+}
+{{ end }}
+func (Unimplemented{{ $svc.Name }}Handler) mustEmbedUnimplemented{{ $svc.Name }}Handler() {}
+
+// Register{{ $svc.Name }}Handler registers the operations of the {{ $svc.Name }} service with the registry, dispatching
+// them to the given handler.
+func Register{{ $svc.Name }}Handler(registry *nexus.OperationRegistry, handler {{ $svc.Name }}Handler) error {
+	return registry.Register(
+{{- range $svc.Operations }}
+		nexus.NewSyncOperationFromReference({{ $svc.Name }}{{ .Method }}Operation, handler.{{ .Method }}),
+{{- end }}
+	)
+}
+{{ end }}`))
+
+// generate returns the formatted Go source generated for the given interfaces in src.
+func generate(filename string, src []byte, typeNames []string) ([]byte, error) {
+	pkg, services, imports, err := parseServices(filename, src, typeNames)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == "nexus" {
+		return nil, fmt.Errorf("cannot generate code into package %q", pkg)
+	}
+	var buf bytes.Buffer
+	err = outputTemplate.Execute(&buf, map[string]any{
+		"Package":  pkg,
+		"Services": services,
+		"Imports":  imports,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `package greet
+
+import (
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+type GreetInput struct {
+	Name string
+}
+
+type Greeter interface {
+	//nexus:operation greet
+	Greet(GreetInput) string
+	Sleep(d time.Duration)
+	Ping() nexus.NoValue
+}
+
+type NotAService interface {
+	Foo(a, b string)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	out, err := generate("greet.go", []byte(testSource), []string{"Greeter"})
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "greet_nexus.go", out, 0)
+	require.NoError(t, err)
+
+	src := string(out)
+	require.Contains(t, src, "// Code generated by nexus-gen. DO NOT EDIT.")
+	require.Contains(t, src, "package greet")
+	require.Contains(t, src, `"time"`)
+	require.Contains(t, src, `GreeterGreetOperation = nexus.NewOperationReference[GreetInput, string]("greet")`)
+	require.Contains(t, src, `GreeterSleepOperation = nexus.NewOperationReference[time.Duration, nexus.NoValue]("Sleep")`)
+	require.Contains(t, src, `GreeterPingOperation  = nexus.NewOperationReference[nexus.NoValue, nexus.NoValue]("Ping")`)
+	require.Contains(t, src, "func (c *GreeterClient) StartGreet(ctx context.Context, input GreetInput, options nexus.StartOperationOptions) (*nexus.ClientStartOperationResult[string], error)")
+	require.Contains(t, src, "Greet(ctx context.Context, input GreetInput, options nexus.StartOperationOptions) (string, error)")
+	require.Contains(t, src, "type UnimplementedGreeterHandler struct{}")
+	require.Contains(t, src, "nexus.NewSyncOperationFromReference(GreeterGreetOperation, handler.Greet),")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := generate("greet.go", []byte(testSource), []string{"Missing"})
+	require.ErrorContains(t, err, `interface "Missing" not found`)
+
+	_, err = generate("greet.go", []byte(testSource), []string{"NotAService"})
+	require.ErrorContains(t, err, "at most one parameter and one result")
+}
@@ -0,0 +1,76 @@
+// Command nexus-gen generates typed Nexus clients, handler scaffolding, and operation references from service
+// definitions declared as Go interfaces.
+//
+// Every method of a service interface defines an operation named after the method. Methods take at most one
+// parameter, the operation input, and return at most one result, the operation output; omitted inputs and outputs are
+// nexus.NoValue. Override the operation name with a "//nexus:operation <name>" directive in the method's doc comment.
+//
+//	//go:generate go run github.com/nexus-rpc/sdk-go/cmd/nexus-gen -type Greeter
+//	type Greeter interface {
+//		//nexus:operation greet
+//		Greet(GreetInput) GreetOutput
+//	}
+//
+// For each service the generator emits:
+//   - <Service><Method>Operation typed operation references,
+//   - a <Service>Client with a method executing and a method starting each operation,
+//   - a <Service>Handler interface, Unimplemented<Service>Handler to embed in implementations, and
+//     Register<Service>Handler for registering an implementation with a nexus.OperationRegistry.
+//
+// Usage:
+//
+//	nexus-gen -type Service[,Service...] [-output file] [file.go]
+//
+// The input file defaults to $GOFILE, set by go generate. The output file defaults to <file>_nexus.go.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("nexus-gen", flag.ContinueOnError)
+	types := flags.String("type", "", "comma separated names of the service interfaces to generate code for")
+	output := flags.String("output", "", "output file name; defaults to <file>_nexus.go")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *types == "" {
+		return fmt.Errorf("-type is required")
+	}
+	var input string
+	switch flags.NArg() {
+	case 0:
+		input = os.Getenv("GOFILE")
+		if input == "" {
+			return fmt.Errorf("no input file given and $GOFILE is not set")
+		}
+	case 1:
+		input = flags.Arg(0)
+	default:
+		return fmt.Errorf("expected at most one input file")
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(input, ".go") + "_nexus.go"
+	}
+
+	src, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	generated, err := generate(input, src, strings.Split(*types, ","))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*output, generated, 0o644)
+}
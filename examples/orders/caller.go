@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Callback header correlating ship completions with the orders they were started for.
+const headerOrderID = "order-id"
+
+// CallerOptions are options for [NewCaller].
+type CallerOptions struct {
+	// Base URL of the server hosting the orders and exports services, see [NewServer].
+	ServerURL string
+	// Bearer token sent to the services and required in completion requests.
+	Token string
+	// URL at which the caller's completion handler is served, see [Caller.CompletionHandler].
+	CallbackURL string
+}
+
+// ShipmentOutcome is the completion of a ship operation as delivered to the caller's completion handler.
+type ShipmentOutcome struct {
+	// Terminal state of the operation.
+	State nexus.OperationState
+	// Set if State is succeeded.
+	Receipt Receipt
+	// Set if State is failed or canceled.
+	Failure *nexus.Failure
+}
+
+// Caller invokes the orders and exports services and receives ship completions.
+type Caller struct {
+	options CallerOptions
+	orders  *nexus.Client
+	exports *nexus.Client
+
+	mu        sync.Mutex
+	shipments map[string]chan ShipmentOutcome
+}
+
+// NewCaller creates a [Caller] from the given options.
+func NewCaller(options CallerOptions) (*Caller, error) {
+	auth := nexus.NewStaticTokenAuthProvider(options.Token)
+	baseURL := strings.TrimSuffix(options.ServerURL, "/")
+	orders, err := nexus.NewClient(nexus.ClientOptions{ServiceBaseURL: baseURL + "/orders", AuthProvider: auth})
+	if err != nil {
+		return nil, err
+	}
+	exports, err := nexus.NewClient(nexus.ClientOptions{ServiceBaseURL: baseURL + "/exports", AuthProvider: auth})
+	if err != nil {
+		return nil, err
+	}
+	return &Caller{
+		options:   options,
+		orders:    orders,
+		exports:   exports,
+		shipments: make(map[string]chan ShipmentOutcome),
+	}, nil
+}
+
+// Quote synchronously prices an order.
+func (c *Caller) Quote(ctx context.Context, order Order) (Quote, error) {
+	return nexus.ExecuteOperation(ctx, c.orders, QuoteOperation, order, nexus.ExecuteOperationOptions{})
+}
+
+// Ship starts shipping an order. The outcome is delivered on the returned channel once the completion is received.
+func (c *Caller) Ship(ctx context.Context, order Order) (*nexus.OperationHandle[Receipt], <-chan ShipmentOutcome, error) {
+	outcome := make(chan ShipmentOutcome, 1)
+	c.mu.Lock()
+	c.shipments[order.ID] = outcome
+	c.mu.Unlock()
+
+	result, err := nexus.StartOperation(ctx, c.orders, ShipOperation, order, nexus.StartOperationOptions{
+		CallbackURL:    c.options.CallbackURL,
+		CallbackHeader: nexus.Header{headerOrderID: order.ID},
+	})
+	if err == nil && result.Pending == nil {
+		err = errors.New("expected ship operation to be asynchronous")
+	}
+	if err != nil {
+		c.mu.Lock()
+		delete(c.shipments, order.ID)
+		c.mu.Unlock()
+		return nil, nil, err
+	}
+	return result.Pending, outcome, nil
+}
+
+// Export exports the given number of rows, waiting for the export to complete.
+func (c *Caller) Export(ctx context.Context, rows int) ([]byte, error) {
+	return nexus.ExecuteOperation(ctx, c.exports, ExportOperation, ExportRequest{Rows: rows}, nexus.ExecuteOperationOptions{})
+}
+
+// StartExport starts exporting the given number of rows without waiting for the export to complete.
+func (c *Caller) StartExport(ctx context.Context, rows int) (*nexus.OperationHandle[[]byte], error) {
+	result, err := nexus.StartOperation(ctx, c.exports, ExportOperation, ExportRequest{Rows: rows}, nexus.StartOperationOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if result.Pending == nil {
+		return nil, errors.New("expected export operation to be asynchronous")
+	}
+	return result.Pending, nil
+}
+
+// CompletionHandler returns an [http.Handler] receiving ship completions, to be served at
+// [CallerOptions.CallbackURL].
+func (c *Caller) CompletionHandler() http.Handler {
+	return nexus.NewCompletionHTTPHandler(nexus.CompletionHandlerOptions{Handler: c})
+}
+
+// CompleteOperation implements nexus.CompletionHandler.
+func (c *Caller) CompleteOperation(ctx context.Context, completion *nexus.CompletionRequest) error {
+	if !validBearerToken(completion.HTTPRequest, c.options.Token) {
+		return nexus.HandlerErrorf(nexus.HandlerErrorTypeUnauthenticated, "invalid bearer token")
+	}
+	orderID := completion.HTTPRequest.Header.Get(headerOrderID)
+	c.mu.Lock()
+	outcome, ok := c.shipments[orderID]
+	delete(c.shipments, orderID)
+	c.mu.Unlock()
+	if !ok {
+		return nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "unknown order: %q", orderID)
+	}
+
+	result := ShipmentOutcome{State: completion.State, Failure: completion.Failure}
+	if completion.Result != nil {
+		if err := completion.Result.Consume(&result.Receipt); err != nil {
+			return nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "invalid receipt")
+		}
+	}
+	outcome <- result
+	return nil
+}
@@ -0,0 +1,149 @@
+// Command orders is an end-to-end example of a Nexus caller and handler.
+//
+// The server hosts two services behind a bearer token authorizer:
+//   - orders, a registry of a synchronous quote operation and an asynchronous ship operation that delivers its
+//     result to the caller's callback URL,
+//   - exports, an [nexus.AsyncHandler] whose export operation is retried on transient failures and whose large
+//     results are offloaded to a payload backend.
+//
+// The caller quotes and ships an order, receiving the shipment's completion via its completion handler, cancels
+// another shipment, and runs and cancels exports. Run it with:
+//
+//	go run ./examples/orders
+//
+// The example's tests run the same flows against an in-process server on every change to the SDK.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := run(ctx, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "orders: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, out io.Writer) error {
+	const token = "example-token"
+	server, err := NewServer(ServerOptions{
+		Token:             token,
+		ShipDelay:         100 * time.Millisecond,
+		ExportDelay:       100 * time.Millisecond,
+		ExportFailures:    2,
+		ExportRetryPolicy: nexus.RetryPolicy{MaxAttempts: 5, InitialInterval: 50 * time.Millisecond},
+	})
+	if err != nil {
+		return err
+	}
+	serverURL, stopServer, err := serve(server)
+	if err != nil {
+		return err
+	}
+	defer stopServer()
+
+	// The completion handler's URL must be known before creating the caller; serve it via a forwarding handler.
+	var completions http.Handler
+	callbackURL, stopCallbacks, err := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		completions.ServeHTTP(w, r)
+	}))
+	if err != nil {
+		return err
+	}
+	defer stopCallbacks()
+
+	caller, err := NewCaller(CallerOptions{ServerURL: serverURL, Token: token, CallbackURL: callbackURL})
+	if err != nil {
+		return err
+	}
+	completions = caller.CompletionHandler()
+	return demo(ctx, caller, out)
+}
+
+// serve serves handler on a local port, returning its URL and a function that stops serving.
+func serve(handler http.Handler) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return "http://" + listener.Addr().String(), func() { server.Close() }, nil
+}
+
+// demo runs the example's flows, reporting their results to out.
+func demo(ctx context.Context, caller *Caller, out io.Writer) error {
+	order := Order{ID: "1001", Items: []string{"keyboard", "mouse"}}
+	quote, err := caller.Quote(ctx, order)
+	if err != nil {
+		return fmt.Errorf("quote: %w", err)
+	}
+	fmt.Fprintf(out, "quoted order %s at %d cents\n", quote.OrderID, quote.Cents)
+
+	_, shipped, err := caller.Ship(ctx, order)
+	if err != nil {
+		return fmt.Errorf("ship: %w", err)
+	}
+	outcome, err := await(ctx, shipped)
+	if err != nil {
+		return fmt.Errorf("ship: %w", err)
+	}
+	fmt.Fprintf(out, "shipment of order %s %s with tracking ID %s\n", order.ID, outcome.State, outcome.Receipt.TrackingID)
+
+	handle, shipped, err := caller.Ship(ctx, Order{ID: "1002", Items: []string{"monitor"}})
+	if err != nil {
+		return fmt.Errorf("ship: %w", err)
+	}
+	if err := handle.Cancel(ctx, nexus.CancelOperationOptions{}); err != nil {
+		return fmt.Errorf("cancel shipment: %w", err)
+	}
+	if outcome, err = await(ctx, shipped); err != nil {
+		return fmt.Errorf("cancel shipment: %w", err)
+	}
+	fmt.Fprintf(out, "shipment of order 1002 %s: %s\n", outcome.State, outcome.Failure.Message)
+
+	data, err := caller.Export(ctx, 10_000)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	fmt.Fprintf(out, "exported %d bytes\n", len(data))
+
+	export, err := caller.StartExport(ctx, 10)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if err := export.Cancel(ctx, nexus.CancelOperationOptions{}); err != nil {
+		return fmt.Errorf("cancel export: %w", err)
+	}
+	_, err = export.GetResult(ctx, nexus.GetOperationResultOptions{Wait: time.Minute})
+	var unsuccessfulError *nexus.UnsuccessfulOperationError
+	if !errors.As(err, &unsuccessfulError) {
+		return fmt.Errorf("cancel export: expected export to be canceled, got: %w", err)
+	}
+	fmt.Fprintf(out, "export %s\n", unsuccessfulError.State)
+	return nil
+}
+
+// await waits for a shipment's outcome.
+func await(ctx context.Context, outcome <-chan ShipmentOutcome) (ShipmentOutcome, error) {
+	select {
+	case <-ctx.Done():
+		return ShipmentOutcome{}, ctx.Err()
+	case o := <-outcome:
+		return o, nil
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "test-token"
+
+func setup(t *testing.T, options ServerOptions) (context.Context, *Caller) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	options.Token = testToken
+	if options.ExportRetryPolicy.InitialInterval == 0 {
+		options.ExportRetryPolicy = nexus.RetryPolicy{MaxAttempts: 5, InitialInterval: time.Millisecond}
+	}
+	server, err := NewServer(options)
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	mux := http.NewServeMux()
+	callbackServer := httptest.NewServer(mux)
+	t.Cleanup(callbackServer.Close)
+
+	caller, err := NewCaller(CallerOptions{ServerURL: httpServer.URL, Token: testToken, CallbackURL: callbackServer.URL + "/callback"})
+	require.NoError(t, err)
+	mux.Handle("/callback", caller.CompletionHandler())
+	return ctx, caller
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	require.NoError(t, run(ctx, &out))
+	require.Equal(t, `quoted order 1001 at 998 cents
+shipment of order 1001 succeeded with tracking ID SHIP-1001
+shipment of order 1002 canceled: shipment canceled
+exported 640000 bytes
+export canceled
+`, out.String())
+}
+
+func TestQuote(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{})
+	quote, err := caller.Quote(ctx, Order{ID: "1", Items: []string{"a", "b", "c"}})
+	require.NoError(t, err)
+	require.Equal(t, Quote{OrderID: "1", Cents: 3 * 499}, quote)
+
+	_, err = caller.Quote(ctx, Order{ID: "2"})
+	var unexpectedResponseError *nexus.UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "order has no items", unexpectedResponseError.Failure.Message)
+}
+
+func TestShip_CompletionDelivered(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{})
+	handle, shipped, err := caller.Ship(ctx, Order{ID: "1", Items: []string{"a"}})
+	require.NoError(t, err)
+	require.Equal(t, "ship-1", handle.ID)
+
+	outcome, err := await(ctx, shipped)
+	require.NoError(t, err)
+	require.Equal(t, ShipmentOutcome{
+		State:   nexus.OperationStateSucceeded,
+		Receipt: Receipt{OrderID: "1", TrackingID: "SHIP-1"},
+	}, outcome)
+
+	info, err := handle.GetInfo(ctx, nexus.GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateSucceeded, info.State)
+}
+
+func TestShip_Cancel(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{ShipDelay: time.Hour})
+	handle, shipped, err := caller.Ship(ctx, Order{ID: "1", Items: []string{"a"}})
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, nexus.CancelOperationOptions{}))
+
+	outcome, err := await(ctx, shipped)
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateCanceled, outcome.State)
+	require.Equal(t, "shipment canceled", outcome.Failure.Message)
+
+	// Cancelation is idempotent.
+	require.NoError(t, handle.Cancel(ctx, nexus.CancelOperationOptions{}))
+}
+
+func TestExport_RetriesTransientFailures(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{ExportFailures: 2})
+	handle, err := caller.StartExport(ctx, 3)
+	require.NoError(t, err)
+	data, err := handle.GetResult(ctx, nexus.GetOperationResultOptions{Wait: 5 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, exportRows(3), data)
+
+	info, err := handle.GetInfo(ctx, nexus.GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Len(t, info.AttemptHistory, 2)
+	require.Equal(t, errWarehouseUnavailable.Error(), info.AttemptHistory[0].Failure.Message)
+}
+
+func TestExport_RetriesExhausted(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{
+		ExportFailures:    5,
+		ExportRetryPolicy: nexus.RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond},
+	})
+	_, err := caller.Export(ctx, 3)
+	var unsuccessfulError *nexus.UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, nexus.OperationStateFailed, unsuccessfulError.State)
+	require.Equal(t, errWarehouseUnavailable.Error(), unsuccessfulError.Failure.Message)
+}
+
+func TestExport_LargePayload(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{PayloadSizeThreshold: 1024})
+	data, err := caller.Export(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, data, 1000*exportRowSize)
+	require.Equal(t, exportRows(1000), data)
+}
+
+func TestExport_Cancel(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{ExportDelay: time.Hour})
+	handle, err := caller.StartExport(ctx, 3)
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, nexus.CancelOperationOptions{}))
+
+	_, err = handle.GetResult(ctx, nexus.GetOperationResultOptions{Wait: 5 * time.Second})
+	var unsuccessfulError *nexus.UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, nexus.OperationStateCanceled, unsuccessfulError.State)
+}
+
+func TestUnauthenticated(t *testing.T) {
+	ctx, caller := setup(t, ServerOptions{})
+	unauthenticated, err := NewCaller(CallerOptions{ServerURL: caller.options.ServerURL, Token: "wrong"})
+	require.NoError(t, err)
+	_, err = unauthenticated.Quote(ctx, Order{ID: "1", Items: []string{"a"}})
+	var unexpectedResponseError *nexus.UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusUnauthorized, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestCompletion_Unauthenticated(t *testing.T) {
+	_, caller := setup(t, ServerOptions{})
+	request := httptest.NewRequest("POST", "/callback", nil)
+	request.Header.Set("Nexus-Operation-State", string(nexus.OperationStateSucceeded))
+	request.Header.Set(headerOrderID, "1")
+	recorder := httptest.NewRecorder()
+	caller.CompletionHandler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Order is the input of the quote and ship operations.
+type Order struct {
+	ID    string   `json:"id"`
+	Items []string `json:"items"`
+}
+
+// Quote is the output of the quote operation.
+type Quote struct {
+	OrderID string `json:"orderId"`
+	Cents   int    `json:"cents"`
+}
+
+// Receipt is the output of the ship operation, delivered to the caller's callback URL.
+type Receipt struct {
+	OrderID    string `json:"orderId"`
+	TrackingID string `json:"trackingId"`
+}
+
+// ExportRequest is the input of the export operation.
+type ExportRequest struct {
+	Rows int `json:"rows"`
+}
+
+// Operations of the orders service, served under /orders.
+var (
+	QuoteOperation = nexus.NewOperationReference[Order, Quote]("quote")
+	ShipOperation  = nexus.NewOperationReference[Order, Receipt]("ship")
+)
+
+// Operations of the exports service, served under /exports.
+var ExportOperation = nexus.NewOperationReference[ExportRequest, []byte]("export")
+
+// Size of a single exported row, see exportRows.
+const exportRowSize = 64
+
+// ServerOptions are options for [NewServer].
+type ServerOptions struct {
+	// Bearer token required in requests to both services and used to authorize completion requests.
+	Token string
+	// Time it takes to ship an order.
+	ShipDelay time.Duration
+	// Time it takes to export rows, per attempt.
+	ExportDelay time.Duration
+	// Number of export attempts that fail with a transient error before an attempt succeeds.
+	ExportFailures int
+	// Retry policy for failed export attempts.
+	ExportRetryPolicy nexus.RetryPolicy
+	// Exports larger than this many bytes are offloaded to a payload backend.
+	PayloadSizeThreshold int
+	// A stuctured logging handler.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewServer creates an [http.Handler] serving the orders service under /orders and the exports service under
+// /exports.
+func NewServer(options ServerOptions) (http.Handler, error) {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	authorizer := bearerTokenAuthorizer(options.Token)
+
+	registry := nexus.OperationRegistry{}
+	err := registry.Register(
+		nexus.NewSyncOperationFromReference(QuoteOperation, quote),
+		&shipOperation{
+			delay:     options.ShipDelay,
+			auth:      nexus.NewStaticTokenAuthProvider(options.Token),
+			logger:    options.Logger,
+			shipments: make(map[string]*shipment),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ordersHandler, err := registry.NewHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := &exporter{delay: options.ExportDelay}
+	exporter.failures.Store(int32(options.ExportFailures))
+	exportsHandler, err := nexus.NewAsyncHandler(nexus.AsyncHandlerOptions{
+		Store:                nexus.NewMemoryOperationStore(),
+		Executor:             exporter.execute,
+		Logger:               options.Logger,
+		PayloadBackend:       nexus.NewMemoryPayloadBackend(),
+		PayloadSizeThreshold: options.PayloadSizeThreshold,
+		RetryPolicies:        map[string]nexus.RetryPolicy{ExportOperation.Name(): options.ExportRetryPolicy},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/orders/", http.StripPrefix("/orders", nexus.NewHTTPHandler(nexus.HandlerOptions{
+		Handler:    ordersHandler,
+		Logger:     options.Logger,
+		Authorizer: authorizer,
+	})))
+	mux.Handle("/exports/", http.StripPrefix("/exports", nexus.NewHTTPHandler(nexus.HandlerOptions{
+		Handler:    exportsHandler,
+		Logger:     options.Logger,
+		Authorizer: authorizer,
+	})))
+	return mux, nil
+}
+
+// bearerTokenAuthorizer rejects requests that do not carry the given bearer token.
+func bearerTokenAuthorizer(token string) nexus.Authorizer {
+	return nexus.AuthorizerFunc(func(ctx context.Context, request *nexus.AuthorizationRequest) error {
+		if !validBearerToken(request.HTTPRequest, token) {
+			return nexus.HandlerErrorf(nexus.HandlerErrorTypeUnauthenticated, "invalid bearer token")
+		}
+		return nil
+	})
+}
+
+func validBearerToken(request *http.Request, token string) bool {
+	return request.Header.Get("Authorization") == "Bearer "+token
+}
+
+func quote(ctx context.Context, order Order, options nexus.StartOperationOptions) (Quote, error) {
+	if len(order.Items) == 0 {
+		return Quote{}, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "order has no items")
+	}
+	return Quote{OrderID: order.ID, Cents: 499 * len(order.Items)}, nil
+}
+
+// shipOperation ships orders asynchronously, delivering receipts to the callback URL of the start request.
+type shipOperation struct {
+	nexus.UnimplementedOperation[Order, Receipt]
+
+	delay  time.Duration
+	auth   nexus.AuthProvider
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	shipments map[string]*shipment
+}
+
+type shipment struct {
+	state    nexus.OperationState
+	canceled chan struct{}
+}
+
+func (o *shipOperation) Name() string {
+	return ShipOperation.Name()
+}
+
+func (o *shipOperation) Start(ctx context.Context, order Order, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[Receipt], error) {
+	if len(order.Items) == 0 {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "order has no items")
+	}
+	if options.CallbackURL == "" {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "a callback URL is required")
+	}
+	operationID := "ship-" + order.ID
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.shipments[operationID]; ok {
+		return &nexus.HandlerStartOperationResultAsync{OperationID: operationID}, nil
+	}
+	s := &shipment{state: nexus.OperationStateRunning, canceled: make(chan struct{})}
+	o.shipments[operationID] = s
+	go o.ship(operationID, order, s, options)
+	return &nexus.HandlerStartOperationResultAsync{OperationID: operationID}, nil
+}
+
+// ship waits for the shipment to be shipped or canceled and delivers the completion to the caller.
+func (o *shipOperation) ship(operationID string, order Order, s *shipment, options nexus.StartOperationOptions) {
+	timer := time.NewTimer(o.delay)
+	select {
+	case <-timer.C:
+	case <-s.canceled:
+		timer.Stop()
+	}
+	var completion nexus.OperationCompletion = &nexus.OperationCompletionUnsuccessful{
+		State:   nexus.OperationStateCanceled,
+		Failure: &nexus.Failure{Message: "shipment canceled"},
+	}
+	if o.succeed(s) {
+		receipt := Receipt{OrderID: order.ID, TrackingID: strings.ToUpper(operationID)}
+		successful, err := nexus.NewOperationCompletionSuccessful(receipt, nexus.OperationCompletionSuccesfulOptions{})
+		if err != nil {
+			o.logger.Error("failed to create completion", "operationID", operationID, "error", err)
+			return
+		}
+		completion = successful
+	}
+	if err := o.deliver(options, completion); err != nil {
+		o.logger.Error("failed to deliver completion", "operationID", operationID, "error", err)
+	}
+}
+
+// deliver sends a completion to the callback URL, echoing the callback header provided by the caller.
+func (o *shipOperation) deliver(options nexus.StartOperationOptions, completion nexus.OperationCompletion) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request, err := nexus.NewCompletionHTTPRequest(ctx, options.CallbackURL, completion)
+	if err != nil {
+		return err
+	}
+	for k, v := range options.CallbackHeader {
+		request.Header.Set(k, v)
+	}
+	if err := nexus.AuthorizeHTTPRequest(request, o.auth); err != nil {
+		return err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected completion response status: %q", response.Status)
+	}
+	return nil
+}
+
+// succeed marks a shipment as succeeded unless it was canceled first.
+func (o *shipOperation) succeed(s *shipment) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if s.state != nexus.OperationStateRunning {
+		return false
+	}
+	s.state = nexus.OperationStateSucceeded
+	return true
+}
+
+func (o *shipOperation) GetInfo(ctx context.Context, operationID string, options nexus.GetOperationInfoOptions) (*nexus.OperationInfo, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s, ok := o.shipments[operationID]
+	if !ok {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "shipment not found")
+	}
+	return &nexus.OperationInfo{ID: operationID, State: s.state}, nil
+}
+
+func (o *shipOperation) Cancel(ctx context.Context, operationID string, options nexus.CancelOperationOptions) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s, ok := o.shipments[operationID]
+	if !ok {
+		return nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "shipment not found")
+	}
+	if s.state == nexus.OperationStateRunning {
+		s.state = nexus.OperationStateCanceled
+		close(s.canceled)
+	}
+	return nil
+}
+
+// exporter executes exports for an [nexus.AsyncHandler], failing the first attempts with a transient error.
+type exporter struct {
+	delay    time.Duration
+	failures atomic.Int32
+}
+
+var errWarehouseUnavailable = errors.New("warehouse unavailable")
+
+func (e *exporter) execute(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (any, error) {
+	var request ExportRequest
+	if err := input.Consume(&request); err != nil {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "invalid export request")
+	}
+	timer := time.NewTimer(e.delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+	if e.failures.Add(-1) >= 0 {
+		return nil, errWarehouseUnavailable
+	}
+	return exportRows(request.Rows), nil
+}
+
+// exportRows renders the given number of fixed size rows.
+func exportRows(rows int) []byte {
+	data := make([]byte, 0, rows*exportRowSize)
+	for i := 0; i < rows; i++ {
+		row := fmt.Sprintf("row-%08d,", i)
+		data = append(data, row...)
+		data = append(data, strings.Repeat("x", exportRowSize-len(row)-1)...)
+		data = append(data, '\n')
+	}
+	return data
+}
@@ -4,7 +4,6 @@ go 1.21
 
 require (
 	github.com/google/uuid v1.3.0
-	github.com/gorilla/mux v1.8.0
 	github.com/stretchr/testify v1.8.4
 )
 
@@ -0,0 +1,49 @@
+package nexus
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// OperationAlreadyStartedError is returned from [Handler.StartOperation] to reject a start request for an operation
+// that was already started, e.g. with the same request ID. Handlers respond with 409 Conflict and the ID of the existing
+// operation in the [HeaderOperationID] header, and clients return a handle to the existing operation with
+// [ClientStartOperationResult.AlreadyStarted] set.
+//
+// Matches [ErrOperationAlreadyStarted] with errors.Is.
+type OperationAlreadyStartedError struct {
+	// ID of the existing operation. Optional, if empty clients fail the start with an error matching
+	// [ErrOperationAlreadyStarted].
+	OperationID string
+}
+
+// Error implements the error interface.
+func (e *OperationAlreadyStartedError) Error() string {
+	if e.OperationID == "" {
+		return ErrOperationAlreadyStarted.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrOperationAlreadyStarted.Error(), e.OperationID)
+}
+
+// Is reports whether target is [ErrOperationAlreadyStarted].
+func (e *OperationAlreadyStartedError) Is(target error) bool {
+	return target == ErrOperationAlreadyStarted
+}
+
+// pendingFromConflictResponse returns a handle to the existing operation identified by a 409 start response, or nil if
+// the response does not identify one.
+func (c *Client) pendingFromConflictResponse(operation string, response *http.Response) *ClientStartOperationResult[*LazyValue] {
+	operationID := response.Header.Get(HeaderOperationID)
+	if operationID == "" {
+		return nil
+	}
+	return &ClientStartOperationResult[*LazyValue]{
+		Pending: &OperationHandle[*LazyValue]{
+			Operation: operation,
+			ID:        operationID,
+			client:    c,
+			polls:     newLongPolls(),
+		},
+		AlreadyStarted: true,
+	}
+}
@@ -0,0 +1,67 @@
+package nexus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_RejectDuplicateStarts(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:                 NewMemoryOperationStore(),
+		DedupeCache:           NewMemoryDedupeCache(time.Minute),
+		RejectDuplicateStarts: true,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	reference := NewOperationReference[NoValue, NoValue]("foo")
+	first, err := StartOperation(ctx, client, reference, nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.False(t, first.AlreadyStarted)
+	second, err := StartOperation(ctx, client, reference, nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.True(t, second.AlreadyStarted)
+	require.Equal(t, first.Pending.ID, second.Pending.ID)
+
+	_, err = handler.StartOperation(ctx, "foo", &LazyValue{Reader: NewReader(strings.NewReader(""), nil)}, StartOperationOptions{RequestID: "request"})
+	var alreadyStartedError *OperationAlreadyStartedError
+	require.ErrorAs(t, err, &alreadyStartedError)
+	require.Equal(t, first.Pending.ID, alreadyStartedError.OperationID)
+	require.ErrorIs(t, err, ErrOperationAlreadyStarted)
+}
+
+// alreadyStartedHandler rejects every start request with an OperationAlreadyStartedError.
+type alreadyStartedHandler struct {
+	UnimplementedHandler
+	operationID string
+}
+
+func (h *alreadyStartedHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, &OperationAlreadyStartedError{OperationID: h.operationID}
+}
+
+func TestOperationAlreadyStartedError(t *testing.T) {
+	handler := &alreadyStartedHandler{operationID: "existing"}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.True(t, result.AlreadyStarted)
+	require.Equal(t, "existing", result.Pending.ID)
+
+	handler.operationID = ""
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, ErrOperationAlreadyStarted)
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, "operation already started", unexpectedResponseError.Failure.Message)
+}
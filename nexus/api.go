@@ -17,30 +17,126 @@ import (
 // Package version.
 const version = "v0.0.6"
 
+// Nexus specific headers.
 const (
-	// Nexus specific headers.
-	headerOperationState = "Nexus-Operation-State"
-	headerOperationID    = "Nexus-Operation-Id"
-	headerRequestID      = "Nexus-Request-Id"
+	// State of an unsuccessful operation, set on failed operation responses and completion requests.
+	HeaderOperationState = "Nexus-Operation-State"
+	// ID of an operation.
+	HeaderOperationID = "Nexus-Operation-Id"
+	// Request ID of a start request, used by handlers to dedupe start requests.
+	HeaderRequestID = "Nexus-Request-Id"
+	// Set to "true" on start responses for operations that were already started, see
+	// [HandlerStartOperationResultAsync.AlreadyStarted].
+	HeaderOperationAlreadyStarted = "Nexus-Operation-Already-Started"
+	// Time by which an operation must complete in RFC 3339 format, see [StartOperationOptions.Deadline].
+	HeaderOperationDeadline = "Nexus-Operation-Deadline"
+	// Time before which an operation should not begin executing in RFC 3339 format, see
+	// [StartOperationOptions.StartTime].
+	HeaderOperationStartTime = "Nexus-Operation-Start-Time"
+	// Delay before an operation should begin executing, e.g. "3600000ms", see [StartOperationOptions.StartDelay].
+	HeaderOperationStartDelay = "Nexus-Operation-Start-Delay"
+	// Mode of a cancel request, see [CancelOperationOptions.Mode].
+	HeaderCancelMode = "Nexus-Cancel-Mode"
+	// Token of a result claim, see [OperationHandle.ClaimResult].
+	HeaderClaimToken = "Nexus-Claim-Token"
+	// Lease duration of a result claim.
+	HeaderClaimLease = "Nexus-Claim-Lease"
+	// Prefix of operation tag headers, see [StartOperationOptions.Tags].
+	HeaderPrefixTag = "Nexus-Tag-"
+	// Prefix of callback headers of start requests, see [StartOperationOptions.CallbackHeader].
+	HeaderPrefixCallback = "Nexus-Callback-"
+	// Prefix of headers describing request and response content, e.g. Content-Type.
+	HeaderPrefixContent = "Content-"
+	// JSON encoded list of callbacks in addition to the callback URL query param, see
+	// [StartOperationOptions.AdditionalCallbacks].
+	HeaderAdditionalCallbacks = "Nexus-Additional-Callbacks"
+	// JSON encoded list of links supplied by the caller of a start request, see [StartOperationOptions.Links].
+	HeaderLinks = "Nexus-Links"
+	// Set by clients that follow result redirects, see [ClientOptions.FollowResultRedirects].
+	HeaderAcceptResultRedirect = "Nexus-Accept-Result-Redirect"
+	// Set by clients that get results in pages, see [OperationHandle.GetResultPages].
+	HeaderAcceptResultPages = "Nexus-Accept-Result-Pages"
+	// Token of the next page of a paged result, absent on the last page, see [ResultPage].
+	HeaderResultNextPageToken = "Nexus-Result-Next-Page-Token"
+	// Prefix for content headers of a redirected result. Regular content headers cannot be used since the redirect
+	// response itself has no content.
+	HeaderPrefixRedirectContent = "Nexus-Redirect-Content-"
+	// Signature of a completion request, see [SignCompletionHTTPRequest].
+	HeaderCompletionSignature = "Nexus-Completion-Signature"
+	// Time a completion request was signed in seconds since the Unix epoch.
+	HeaderCompletionTimestamp = "Nexus-Completion-Timestamp"
+	// Subject whose quota was exceeded, set on responses to start requests rejected for exceeding a quota.
+	HeaderQuotaSubject = "Nexus-Quota-Subject"
+	// Operation limit of the exceeded quota.
+	HeaderQuotaLimitOperations = "Nexus-Quota-Limit-Operations"
+	// Payload bytes limit of the exceeded quota.
+	HeaderQuotaLimitBytes = "Nexus-Quota-Limit-Bytes"
+	// Number of operations accounted to the subject of the exceeded quota.
+	HeaderQuotaUsageOperations = "Nexus-Quota-Usage-Operations"
+	// Payload bytes accounted to the subject of the exceeded quota.
+	HeaderQuotaUsageBytes = "Nexus-Quota-Usage-Bytes"
+	// Identity of the caller asserted by the client, see [ClientOptions.CallerIdentity]. Handlers surface it in
+	// [AuthorizationRequest.Caller] for verification by an [Authorizer].
+	HeaderCallerIdentity = "Nexus-Caller-Identity"
+	// Name of the client library sending a request, see [ClientOptions.ClientName].
+	HeaderClientName = "Nexus-Client-Name"
+	// Version of the client library sending a request, see [ClientOptions.ClientVersion].
+	HeaderClientVersion = "Nexus-Client-Version"
+)
 
-	// General HTTP headers.
-	headerRequestTimeout = "Request-Timeout"
+// General HTTP headers.
+const (
+	// Timeout of a request, e.g. "10s", enforced by handlers.
+	HeaderRequestTimeout = "Request-Timeout"
 )
 
 const contentTypeJSON = "application/json"
 
-// Query param for passing a callback URL.
+const contentTypeEventStream = "text/event-stream"
+
+// Query params.
 const (
-	queryCallbackURL = "callback"
-	// Query param for passing wait duration.
-	queryWait = "wait"
+	// Query param for passing a callback URL.
+	QueryCallbackURL = "callback"
+	// Query param for passing wait duration, see [FormatDurationParam].
+	QueryWait = "wait"
+	// Query param for passing a result claim lease duration.
+	QueryLease = "lease"
+	// Query param for passing a result claim consumer group.
+	QueryGroup = "group"
+	// Query param for requesting termination instead of cancelation.
+	QueryTerminate = "terminate"
+	// Query param for passing a cancelation reason.
+	QueryReason = "reason"
+	// Query param for passing a quarantine resolution action.
+	QueryAction = "action"
+	// Query param for filtering listed operations by name.
+	QueryOperation = "operation"
+	// Query param for filtering listed operations by state.
+	QueryState = "state"
+	// Query param for filtering listed operations by tag, formatted as "key:value".
+	QueryTag = "tag"
+	// Query param for listing operations created after a time in RFC 3339 format.
+	QueryCreatedAfter = "createdAfter"
+	// Query param for listing operations created before a time in RFC 3339 format.
+	QueryCreatedBefore = "createdBefore"
+	// Query param for listing completed operations whose outcome expired before a time in RFC 3339 format.
+	QueryExpiredBefore = "expiredBefore"
+	// Query param for passing the maximum number of listed operations per page.
+	QueryPageSize = "pageSize"
+	// Query param for passing the token of the page of operations to list, or of the page of a paged result to get.
+	QueryPageToken = "pageToken"
 )
 
+// Non-standard HTTP status codes and status codes with Nexus specific meaning.
 const (
-	statusOperationRunning = http.StatusPreconditionFailed
+	// HTTP status code for responses to get result requests for operations that are still running.
+	StatusOperationRunning = http.StatusPreconditionFailed
 	// HTTP status code for failed operation responses.
-	statusOperationFailed   = http.StatusFailedDependency
-	StatusDownstreamError   = 520
+	StatusOperationFailed = http.StatusFailedDependency
+	// HTTP status code for [HandlerErrorTypeDownstreamError].
+	StatusDownstreamError = 520
+	// HTTP status code for [HandlerErrorTypeDownstreamTimeout].
 	StatusDownstreamTimeout = 521
 )
 
@@ -50,8 +146,18 @@ type Failure struct {
 	Message string `json:"message"`
 	// A key-value mapping for additional context. Useful for decoding the 'details' field, if needed.
 	Metadata map[string]string `json:"metadata,omitempty"`
-	// Additional JSON serializable structured data.
+	// Additional JSON serializable structured data. See [Failure.EncodeDetails].
 	Details json.RawMessage `json:"details,omitempty"`
+	// Optional type of the failure, e.g. the class of the error that caused it, for inspecting failures
+	// programmatically.
+	Type string `json:"type,omitempty"`
+	// Set if the failure is not transient and retrying the request or operation is not expected to succeed.
+	NonRetryable bool `json:"nonRetryable,omitempty"`
+	// Optional stack trace of where the failure originated.
+	StackTrace string `json:"stackTrace,omitempty"`
+	// Optional failure that caused this failure, forming a chain of failures. See [FailureError] for inspecting the
+	// chain with [errors.As].
+	Cause *Failure `json:"cause,omitempty"`
 }
 
 // UnsuccessfulOperationError represents "failed" and "canceled" operation results.
@@ -68,15 +174,76 @@ func (e *UnsuccessfulOperationError) Error() string {
 	return fmt.Sprintf("operation %s", e.State)
 }
 
+// Unwrap returns the cause of the operation's failure as a [FailureError], if any.
+func (e *UnsuccessfulOperationError) Unwrap() error {
+	return e.Failure.causeError()
+}
+
 // ErrOperationStillRunning indicates that an operation is still running while trying to get its result.
 var ErrOperationStillRunning = errors.New("operation still running")
 
+// ErrOperationResultGone indicates that the result of a completed operation is no longer retained by the handler, see
+// [AsyncHandlerOptions.Retentions]. Handlers respond with 410 Gone.
+var ErrOperationResultGone = errors.New("operation result gone")
+
+// ErrOperationNotFound indicates that an operation does not exist. Handlers respond with 404 Not Found.
+//
+// Matches [HandlerError]s of type [HandlerErrorTypeNotFound] and [UnexpectedResponseError]s of 404 responses, so clients
+// can check for it with errors.Is regardless of how the handler reported the error.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// ErrOperationAlreadyStarted indicates that a start request was rejected because the operation was already started.
+// Handlers respond with 409 Conflict. See [OperationAlreadyStartedError] for identifying the existing operation.
+var ErrOperationAlreadyStarted = errors.New("operation already started")
+
 // OperationInfo conveys information about an operation.
 type OperationInfo struct {
 	// ID of the operation.
 	ID string `json:"id"`
 	// State of the operation.
 	State OperationState `json:"state"`
+	// Time the handler last recorded a heartbeat for the operation, if it records heartbeats.
+	// A running operation whose heartbeat is overdue is likely no longer executing.
+	LastHeartbeatTime *time.Time `json:"lastHeartbeatTime,omitempty"`
+	// Details reported with the operation's last heartbeat, if any.
+	HeartbeatDetails json.RawMessage `json:"heartbeatDetails,omitempty"`
+	// Time by which the operation must complete, if the operation was started with a deadline.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Time before which the operation does not begin executing, if the operation was started with a delayed start.
+	StartTime *time.Time `json:"startTime,omitempty"`
+	// Time after which the outcome of a completed operation is no longer retained, if the handler expires outcomes.
+	ExpireTime *time.Time `json:"expireTime,omitempty"`
+	// Failed execution attempts of the operation that were retried, oldest first, if the handler retries executions.
+	AttemptHistory []OperationAttempt `json:"attemptHistory,omitempty"`
+	// Set while the operation is quarantined after repeated execution failures, awaiting manual resolution.
+	Quarantine *OperationQuarantine `json:"quarantine,omitempty"`
+	// Arbitrary key-value metadata set by the handler, e.g. for display in dashboards. See
+	// [OperationMetadataCreatedAt] and [OperationMetadataCaller] for well-known keys.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Links to resources related to the operation, e.g. its page in a user interface.
+	Links []Link `json:"links,omitempty"`
+}
+
+// Well-known keys of [OperationInfo.Metadata].
+const (
+	// Time the operation was created, formatted as RFC 3339 with nanoseconds.
+	OperationMetadataCreatedAt = "createdAt"
+	// Identity of the caller that started the operation, see [HandlerInfo.Caller].
+	OperationMetadataCaller = "caller"
+)
+
+// CreatedAt returns the time the operation was created from [OperationMetadataCreatedAt], if set and valid.
+func (i *OperationInfo) CreatedAt() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, i.Metadata[OperationMetadataCreatedAt])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Caller returns the identity of the caller that started the operation from [OperationMetadataCaller], if set.
+func (i *OperationInfo) Caller() string {
+	return i.Metadata[OperationMetadataCaller]
 }
 
 // OperationState represents the variable states of an operation.
@@ -111,6 +278,15 @@ func isMediaTypeOctetStream(contentType string) bool {
 	return err == nil && mediaType == "application/octet-stream"
 }
 
+// isMediaTypeEventStream returns true if the given content type's media type is text/event-stream.
+func isMediaTypeEventStream(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == contentTypeEventStream
+}
+
 // Header is a mapping of string to string.
 // It is used throughout the framework to transmit metadata.
 type Header map[string]string
@@ -134,18 +310,83 @@ func prefixStrippedHTTPHeaderToNexusHeader(httpHeader http.Header, prefix string
 
 func addContentHeaderToHTTPHeader(nexusHeader Header, httpHeader http.Header) http.Header {
 	for k, v := range nexusHeader {
-		httpHeader.Set("Content-"+k, v)
+		httpHeader.Set(HeaderPrefixContent+k, v)
 	}
 	return httpHeader
 }
 
 func addCallbackHeaderToHTTPHeader(nexusHeader Header, httpHeader http.Header) http.Header {
 	for k, v := range nexusHeader {
-		httpHeader.Set("Nexus-Callback-"+k, v)
+		httpHeader.Set(HeaderPrefixCallback+k, v)
 	}
 	return httpHeader
 }
 
+func addTagsToHTTPHeader(tags map[string]string, httpHeader http.Header) http.Header {
+	for k, v := range tags {
+		httpHeader.Set(HeaderPrefixTag+k, v)
+	}
+	return httpHeader
+}
+
+func addAdditionalCallbacksToHTTPHeader(callbacks []Callback, httpHeader http.Header) error {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(callbacks)
+	if err != nil {
+		return err
+	}
+	httpHeader.Set(HeaderAdditionalCallbacks, string(b))
+	return nil
+}
+
+func httpHeaderToAdditionalCallbacks(httpHeader http.Header) ([]Callback, error) {
+	value := httpHeader.Get(HeaderAdditionalCallbacks)
+	if value == "" {
+		return nil, nil
+	}
+	var callbacks []Callback
+	if err := json.Unmarshal([]byte(value), &callbacks); err != nil {
+		return nil, err
+	}
+	for _, callback := range callbacks {
+		if callback.URL == "" {
+			return nil, errors.New("callback URL is empty")
+		}
+	}
+	return callbacks, nil
+}
+
+func addLinksToHTTPHeader(links []Link, httpHeader http.Header) error {
+	if len(links) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(links)
+	if err != nil {
+		return err
+	}
+	httpHeader.Set(HeaderLinks, string(b))
+	return nil
+}
+
+func httpHeaderToLinks(httpHeader http.Header) ([]Link, error) {
+	value := httpHeader.Get(HeaderLinks)
+	if value == "" {
+		return nil, nil
+	}
+	var links []Link
+	if err := json.Unmarshal([]byte(value), &links); err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.URL == "" {
+			return nil, errors.New("link URL is empty")
+		}
+	}
+	return links, nil
+}
+
 func httpHeaderToNexusHeader(httpHeader http.Header, excludePrefixes ...string) Header {
 	header := Header{}
 headerLoop:
@@ -169,11 +410,28 @@ func addNexusHeaderToHTTPHeader(nexusHeader Header, httpHeader http.Header) http
 	return httpHeader
 }
 
+func addDeadlineToHTTPHeader(deadline time.Time, httpHeader http.Header) http.Header {
+	if !deadline.IsZero() {
+		httpHeader.Set(HeaderOperationDeadline, deadline.UTC().Format(time.RFC3339Nano))
+	}
+	return httpHeader
+}
+
+func addStartTimeToHTTPHeader(startTime time.Time, startDelay time.Duration, httpHeader http.Header) http.Header {
+	if !startTime.IsZero() {
+		httpHeader.Set(HeaderOperationStartTime, startTime.UTC().Format(time.RFC3339Nano))
+	}
+	if startDelay > 0 {
+		httpHeader.Set(HeaderOperationStartDelay, FormatDurationParam(startDelay))
+	}
+	return httpHeader
+}
+
 func addContextTimeoutToHTTPHeader(ctx context.Context, httpHeader http.Header) http.Header {
 	deadline, ok := ctx.Deadline()
 	if !ok {
 		return httpHeader
 	}
-	httpHeader.Set(headerRequestTimeout, time.Until(deadline).String())
+	httpHeader.Set(HeaderRequestTimeout, time.Until(deadline).String())
 	return httpHeader
 }
@@ -1,7 +1,9 @@
 package nexus
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -55,7 +57,7 @@ func TestFailure_JSONMarshalling(t *testing.T) {
 		t.Run(tc.message, func(t *testing.T) {
 			serializedDetails, err := json.MarshalIndent(tc.details, "", "\t")
 			require.NoError(t, err)
-			source, err := json.MarshalIndent(Failure{tc.message, tc.metadata, serializedDetails}, "", "\t")
+			source, err := json.MarshalIndent(Failure{Message: tc.message, Metadata: tc.metadata, Details: serializedDetails}, "", "\t")
 			require.NoError(t, err)
 			require.Equal(t, tc.serialized, string(source))
 
@@ -74,3 +76,79 @@ func TestFailure_JSONMarshalling(t *testing.T) {
 		})
 	}
 }
+
+// sentinelErrorHandler fails every request with a sentinel error.
+type sentinelErrorHandler struct {
+	UnimplementedHandler
+}
+
+func (h *sentinelErrorHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, ErrOperationAlreadyStarted
+}
+
+func (h *sentinelErrorHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return nil, ErrOperationResultGone
+}
+
+func (h *sentinelErrorHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return nil, ErrOperationNotFound
+}
+
+func (h *sentinelErrorHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	return HandlerErrorf(HandlerErrorTypeNotFound, "no such operation")
+}
+
+func TestSentinelErrors(t *testing.T) {
+	ctx, client, teardown := setup(t, &sentinelErrorHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, ErrOperationAlreadyStarted)
+	require.NotErrorIs(t, err, ErrOperationNotFound)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationResultGone)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, "operation not found", unexpectedResponseError.Failure.Message)
+	err = handle.Cancel(ctx, CancelOperationOptions{})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+
+	require.ErrorIs(t, HandlerErrorf(HandlerErrorTypeNotFound, "operation not found"), ErrOperationNotFound)
+	require.False(t, errors.Is(HandlerErrorf(HandlerErrorTypeBadRequest, "invalid"), ErrOperationNotFound))
+}
+
+// conflictingStore is an OperationStore in which every created record already exists.
+type conflictingStore struct {
+	*MemoryOperationStore
+}
+
+func (s conflictingStore) Create(ctx context.Context, record *OperationRecord) error {
+	return ErrOperationRecordExists
+}
+
+func TestAsyncHandler_SentinelErrors(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: conflictingStore{NewMemoryOperationStore()},
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.True(t, result.AlreadyStarted)
+	require.NotEmpty(t, result.Pending.ID)
+
+	handle, err := client.NewHandle("foo", "missing")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+}
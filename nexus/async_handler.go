@@ -0,0 +1,776 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// An AsyncExecutor executes operations started via an [AsyncHandler].
+//
+// The input is buffered in memory and may be consumed at any time during execution. The context is canceled when
+// cancelation of the operation is requested.
+//
+// Return a result to complete the operation successfully. Return an [UnsuccessfulOperationError] to complete the
+// operation as failed or canceled. Any other error is retried according to the operation's [RetryPolicy] and, once
+// retries are exhausted, completes the operation as failed with the error message as the failure message.
+type AsyncExecutor func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error)
+
+// AsyncHandlerOptions are options for [NewAsyncHandler].
+type AsyncHandlerOptions struct {
+	// Store for persisting operation state. Required.
+	Store OperationStore
+	// Executor for started operations. Required.
+	Executor AsyncExecutor
+	// A [Serializer] used to serialize executor results before storing them.
+	// Defaults to the SDK's default Serializer, which handles JSONables, byte slices and nils.
+	Serializer Serializer
+	// JSON implementation for the default serializer and for failures of delivered completions. Defaults to
+	// encoding/json.
+	JSON JSONEngine
+	// A stuctured logger.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+	// An optional [DedupeCache] for deduplicating start requests by request ID.
+	// When set, a retried start request with the same operation name and request ID returns the ID of the operation
+	// started by the original request instead of starting a new operation, see
+	// [HandlerStartOperationResultAsync.AlreadyStarted]. Entries of requests that fail to create their operation are
+	// released if the cache implements [DedupeCacheDeleter].
+	DedupeCache DedupeCache
+	// Reject start requests deduplicated via DedupeCache with an [OperationAlreadyStartedError] (409 Conflict) instead
+	// of responding as if the operation was started (201 Created). Clients get a handle to the existing operation
+	// either way.
+	RejectDuplicateStarts bool
+	// An optional [PayloadBackend] for storing results larger than PayloadSizeThreshold outside of the Store.
+	// Offloaded results are streamed from the backend when serving result requests.
+	PayloadBackend PayloadBackend
+	// Results with more than this many bytes of data are stored in the PayloadBackend, if set.
+	// Defaults to 256 KiB.
+	PayloadSizeThreshold int
+	// If non-zero and the PayloadBackend implements [PayloadPresigner], requests for offloaded results from clients
+	// that accept redirects are responded to with a redirect to a pre-signed URL valid for this duration, offloading
+	// the transfer from the handler process.
+	PayloadRedirectTTL time.Duration
+	// Tag whose values identify the subjects of quota accounting, e.g. "tenant". When set, the number of operations
+	// and the payload bytes of each subject are tracked in the UsageTracker and quotas are enforced for start requests.
+	// Operations without the tag are not accounted.
+	QuotaTag string
+	// Quotas enforced per subject. Subjects without an entry are subject to DefaultQuota.
+	Quotas map[string]Quota
+	// Quota enforced for subjects without an entry in Quotas. The zero value does not limit usage.
+	DefaultQuota Quota
+	// Tracks usage per subject when QuotaTag is set.
+	// Defaults to a [MemoryUsageTracker], which only accounts for operations started by this process.
+	UsageTracker UsageTracker
+	// Maximum number of times [AsyncHandler.RecoverStaleOperations] re-executes an operation that stopped recording
+	// heartbeats before failing it. When non-zero, operation inputs are retained in the Store for re-execution.
+	MaxStaleRetries int
+	// If non-zero, heartbeats are recorded at this interval for operations executing in this process and surfaced in
+	// [OperationInfo.LastHeartbeatTime]. Use [AsyncHandler.FailStaleOperations] to fail operations whose executing
+	// process has gone away.
+	HeartbeatInterval time.Duration
+	// Maximum amount of time to wait for executors to stop after graceful cancelation was requested, see
+	// [CancelModeGraceful], before their execution context is canceled. Defaults to 1 minute.
+	GracefulCancelTimeout time.Duration
+	// Retry policies for failed executions per operation name. Operations without an entry are retried according to
+	// DefaultRetryPolicy. Retried attempts are reported in [OperationInfo.AttemptHistory].
+	RetryPolicies map[string]RetryPolicy
+	// Retry policy for operations without an entry in RetryPolicies. The zero value disables retries.
+	DefaultRetryPolicy RetryPolicy
+	// How long the outcomes of completed operations are retained per operation name. Operations without an entry are
+	// retained for DefaultRetention. Expiry times are reported in [OperationInfo.ExpireTime], results of expired
+	// operations are rejected with [ErrOperationResultGone], and their records are deleted by
+	// [AsyncHandler.PurgeExpiredOperations].
+	Retentions map[string]time.Duration
+	// Retention for operations without an entry in Retentions. Zero retains outcomes indefinitely.
+	DefaultRetention time.Duration
+	// If non-zero, operations whose execution panics this many times are quarantined instead of being retried or
+	// failed. Quarantined operations remain running, are tagged with [QuarantineTag], report the failure that caused
+	// the quarantine in [OperationInfo.Quarantine], and are not executed until released or failed via
+	// [AsyncHandler.ResolveQuarantine]. Panics are recovered and otherwise treated as execution failures.
+	QuarantineAfterPanics int
+	// Quarantine operations whose execution fails with an error that is not retryable according to their
+	// [RetryPolicy] instead of failing them. [UnsuccessfulOperationError] is never quarantined.
+	QuarantineNonRetryable bool
+	// Optional callback invoked when an operation is quarantined, e.g. for alerting.
+	OnQuarantine func(summary *OperationSummary, failure Failure)
+	// When set, the completion of every operation is delivered to all callbacks provided when starting it, see
+	// [StartOperationOptions.Callbacks], and to callbacks added via [AsyncHandler.AddCallback]. Each callback is
+	// delivered to and retried independently, with the state of every delivery recorded in
+	// [OperationRecord.Callbacks].
+	//
+	// Deliveries are bound to the process that completed the operation and are not resumed after it exits.
+	CallbackDelivery *CallbackDeliveryOptions
+	// Optional function providing custom metadata for operations when they are started, surfaced in
+	// [OperationInfo.Metadata] and [OperationSummary.Metadata]. The context carries the start request's
+	// [HandlerInfo]. The creation time and the caller identity, if set by an [Authorizer], are always included.
+	Metadata func(ctx context.Context, operation string, options StartOperationOptions) map[string]string
+	// Optional function providing links to resources related to operations when they are started, e.g. a page for
+	// the operation in a user interface. Links are returned to the caller in [OperationHandle.Links] and surfaced in
+	// [OperationInfo.Links], followed by the links supplied by the caller in [StartOperationOptions.Links].
+	Links func(ctx context.Context, operation, operationID string, options StartOperationOptions) []Link
+	// Clock measuring the wait of delayed operations for their start time, attached to the context passed to the
+	// Executor, see [ClockFromContext]. Defaults to [SystemClock].
+	Clock Clock
+	// Optional notifier of completed operations. The handler notifies it whenever an operation completes, and its long
+	// polls and watch requests are woken up by its notifications in addition to [OperationStore.WaitForUpdate], e.g.
+	// to wake them up immediately when an operation completes in this process while the store polls for updates.
+	Notifier *Notifier
+	// Generator of operation IDs, e.g. [UUIDv7OperationIDs] for IDs ordered by creation time. Start requests for which
+	// the generator returns the ID of an existing operation fail with an [OperationAlreadyStartedError].
+	// Defaults to [UUIDv4OperationIDs].
+	IDGenerator OperationIDGenerator
+}
+
+// AsyncHandler is a turnkey [Handler] that starts every operation asynchronously, runs it in a background goroutine via
+// an [AsyncExecutor], and tracks its state in an [OperationStore].
+//
+// Long polls for operation results and watch requests are woken up via [OperationStore.WaitForUpdate] and the
+// [AsyncHandlerOptions.Notifier].
+//
+// Executions are bound to the process that started them. Operations that were running when the process exited remain
+// in the running state in the store.
+type AsyncHandler struct {
+	UnimplementedHandler
+
+	options AsyncHandlerOptions
+
+	mu         sync.Mutex
+	executions map[memoryStoreKey]*asyncExecution
+}
+
+// asyncExecution is an execution of an operation in this process.
+type asyncExecution struct {
+	cancel context.CancelFunc
+	// Closed when graceful cancelation was requested, see CancelRequested.
+	cancelRequested chan struct{}
+	// Guarded by the handler's mutex, set when graceful cancelation was requested.
+	cancelFailure *Failure
+	cancelTimer   *time.Timer
+}
+
+// NewAsyncHandler creates an [AsyncHandler] from the given options.
+func NewAsyncHandler(options AsyncHandlerOptions) (*AsyncHandler, error) {
+	if options.Store == nil {
+		return nil, errors.New("store is required")
+	}
+	if options.Executor == nil {
+		return nil, errors.New("executor is required")
+	}
+	if options.CallbackDelivery != nil && options.CallbackDelivery.CloudEvents != nil && options.CallbackDelivery.CloudEvents.Source == "" {
+		return nil, errors.New("CloudEvents source is required")
+	}
+	if options.Serializer == nil {
+		options.Serializer = NewJSONSerializer(options.JSON)
+	}
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	if options.PayloadSizeThreshold == 0 {
+		options.PayloadSizeThreshold = 256 * 1024
+	}
+	if options.GracefulCancelTimeout <= 0 {
+		options.GracefulCancelTimeout = defaultGracefulCancelTimeout
+	}
+	if options.Clock == nil {
+		options.Clock = SystemClock
+	}
+	if options.IDGenerator == nil {
+		options.IDGenerator = UUIDv4OperationIDs
+	}
+	options.QuotaTag = strings.ToLower(options.QuotaTag)
+	if options.QuotaTag != "" && options.UsageTracker == nil {
+		options.UsageTracker = NewMemoryUsageTracker()
+	}
+	return &AsyncHandler{
+		options:    options,
+		executions: make(map[memoryStoreKey]*asyncExecution),
+	}, nil
+}
+
+// StartOperation implements Handler.
+func (h *AsyncHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	data, err := io.ReadAll(input.Reader)
+	if err != nil {
+		return nil, requestBodyError(err, "failed to read input")
+	}
+	operationID, err := h.options.IDGenerator.NewOperationID(ctx, operation, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate operation ID: %w", err)
+	}
+	reserved, err := h.reserveQuota(ctx, options.Tags, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var dedupeKey string
+	if h.options.DedupeCache != nil && options.RequestID != "" {
+		dedupeKey = joinKey(operation, options.RequestID)
+		existing, loaded, err := h.options.DedupeCache.GetOrSet(ctx, dedupeKey, operationID)
+		if err != nil {
+			h.refundQuota(ctx, options.Tags, reserved)
+			return nil, fmt.Errorf("failed to dedupe start request: %w", err)
+		}
+		if loaded {
+			h.refundQuota(ctx, options.Tags, reserved)
+			if h.options.RejectDuplicateStarts {
+				return nil, &OperationAlreadyStartedError{OperationID: existing}
+			}
+			return &HandlerStartOperationResultAsync{OperationID: existing, AlreadyStarted: true}, nil
+		}
+	}
+	now := time.Now()
+	record := &OperationRecord{
+		Operation: operation,
+		ID:        operationID,
+		RequestID: options.RequestID,
+		Tags:      options.Tags,
+		Deadline:  options.Deadline,
+		StartTime: options.StartTime,
+		State:     OperationStateRunning,
+		Attempt:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	record.Metadata = h.startMetadata(ctx, operation, options)
+	if h.options.Links != nil {
+		record.Links = h.options.Links(ctx, operation, operationID, options)
+	}
+	record.Links = append(record.Links, options.Links...)
+	if h.options.HeartbeatInterval > 0 {
+		record.LastHeartbeat = now
+	}
+	if h.options.CallbackDelivery != nil {
+		record.Callbacks = newCallbackRecords(options.Callbacks())
+	}
+	content := &Content{Header: input.Reader.Header, Data: data}
+	if h.options.MaxStaleRetries > 0 || h.quarantineEnabled() {
+		record.Input = content
+	}
+	if err := h.options.Store.Create(ctx, record); err != nil {
+		h.refundQuota(ctx, options.Tags, reserved)
+		h.releaseDedupeKey(ctx, dedupeKey, operationID)
+		if errors.Is(err, ErrOperationRecordExists) {
+			return nil, &OperationAlreadyStartedError{OperationID: operationID}
+		}
+		return nil, fmt.Errorf("failed to create operation record: %w", err)
+	}
+
+	h.execute(record, content, options)
+
+	return &HandlerStartOperationResultAsync{OperationID: record.ID, Links: record.Links}, nil
+}
+
+// releaseDedupeKey deletes the dedupe cache entry of a start request that failed to create its operation, if the cache
+// supports it, so that retries of the request start the operation rather than being deduplicated against it.
+func (h *AsyncHandler) releaseDedupeKey(ctx context.Context, key, operationID string) {
+	deleter, ok := h.options.DedupeCache.(DedupeCacheDeleter)
+	if key == "" || !ok {
+		return
+	}
+	if err := deleter.Delete(ctx, key, operationID); err != nil {
+		h.options.Logger.Warn("failed to release dedupe cache entry", "key", key, "error", err)
+	}
+}
+
+// startMetadata returns the metadata recorded for an operation being started.
+func (h *AsyncHandler) startMetadata(ctx context.Context, operation string, options StartOperationOptions) map[string]string {
+	var metadata map[string]string
+	if h.options.Metadata != nil {
+		metadata = maps.Clone(h.options.Metadata(ctx, operation, options))
+	}
+	if info, ok := HandlerInfoFromContext(ctx); ok && info.Caller != "" {
+		if metadata == nil {
+			metadata = make(map[string]string, 1)
+		}
+		metadata[OperationMetadataCaller] = info.Caller
+	}
+	return metadata
+}
+
+func (h *AsyncHandler) execute(record *OperationRecord, content *Content, options StartOperationOptions) {
+	key := memoryStoreKey{record.Operation, record.ID}
+	ctx, cancel := context.WithCancel(ContextWithClock(context.Background(), h.options.Clock))
+	ctx = context.WithValue(ctx, heartbeaterContextKey{}, heartbeater(func(ctx context.Context, details any) error {
+		var data json.RawMessage
+		if details != nil {
+			var err error
+			if data, err = h.options.JSON.marshal(details); err != nil {
+				return fmt.Errorf("failed to marshal heartbeat details: %w", err)
+			}
+		}
+		_, err := h.recordHeartbeat(ctx, record.Operation, record.ID, data)
+		return err
+	}))
+	exec := &asyncExecution{cancel: cancel, cancelRequested: make(chan struct{})}
+	ctx = context.WithValue(ctx, cancelRequestedContextKey{}, exec.cancelRequested)
+	h.mu.Lock()
+	h.executions[key] = exec
+	h.mu.Unlock()
+
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			// The operation may have been re-executed after this execution was deemed stale.
+			if h.executions[key] == exec {
+				delete(h.executions, key)
+			}
+			if exec.cancelTimer != nil {
+				exec.cancelTimer.Stop()
+			}
+			h.mu.Unlock()
+			cancel()
+		}()
+		if h.options.HeartbeatInterval > 0 {
+			go h.heartbeat(ctx, record.Operation, record.ID)
+		}
+		if !record.Deadline.IsZero() {
+			timer := time.AfterFunc(time.Until(record.Deadline), func() {
+				if err := h.stop(context.Background(), record.Operation, record.ID, OperationStateFailed, deadlineExceededFailure()); err != nil {
+					h.options.Logger.Error("failed to fail operation past its deadline", "operation", record.Operation, "operationID", record.ID, "error", err)
+				}
+			})
+			defer timer.Stop()
+		}
+		var result any
+		// Delayed operations are canceled like running ones while waiting to start.
+		err := WaitForStartTime(ctx, record.StartTime)
+		if err == nil {
+			result, err = h.executeWithRetries(ctx, record, content, options)
+		}
+		h.mu.Lock()
+		superseded := h.executions[key] != exec
+		cancelFailure := exec.cancelFailure
+		h.mu.Unlock()
+		if superseded {
+			// The operation was re-executed, leave completing it to the new execution.
+			return
+		}
+		if err != nil && cancelFailure != nil {
+			// The executor stopped after graceful cancelation was requested.
+			err = &UnsuccessfulOperationError{State: OperationStateCanceled, Failure: *cancelFailure}
+		}
+		var quarantineErr *quarantineError
+		if errors.As(err, &quarantineErr) {
+			if err := h.quarantine(record.Operation, record.ID, quarantineErr.err); err != nil {
+				h.options.Logger.Error("failed to quarantine operation", "operation", record.Operation, "operationID", record.ID, "error", err)
+			}
+			return
+		}
+		if err := h.complete(record.Operation, record.ID, result, err); err != nil {
+			h.options.Logger.Error("failed to complete operation", "operation", record.Operation, "operationID", record.ID, "error", err)
+		}
+	}()
+}
+
+// complete transitions a running operation to a terminal state based on the outcome of its execution.
+func (h *AsyncHandler) complete(operation, operationID string, result any, executionErr error) error {
+	ctx := context.Background()
+	var content *Content
+	var failure *Failure
+	state := OperationStateSucceeded
+	if executionErr != nil {
+		var unsuccessfulError *UnsuccessfulOperationError
+		if errors.As(executionErr, &unsuccessfulError) {
+			state = unsuccessfulError.State
+			failure = &unsuccessfulError.Failure
+		} else {
+			state = OperationStateFailed
+			failure = &Failure{Message: executionErr.Error()}
+		}
+	} else {
+		var err error
+		if m, ok := result.(Multipart); ok {
+			content, err = m.Content(h.options.Serializer)
+		} else {
+			content, err = h.options.Serializer.Serialize(result)
+		}
+		if err != nil {
+			state = OperationStateFailed
+			failure = &Failure{Message: "failed to serialize operation result"}
+			h.options.Logger.Error("failed to serialize operation result", "operation", operation, "operationID", operationID, "error", err)
+		}
+	}
+	return h.store(ctx, operation, operationID, state, content, failure)
+}
+
+// store transitions a running operation to a terminal state with the given result or failure, offloading large results
+// to the payload backend.
+func (h *AsyncHandler) store(ctx context.Context, operation, operationID string, state OperationState, content *Content, failure *Failure) error {
+	var payloadKey string
+	var resultSize int64
+	if content != nil {
+		resultSize = int64(len(content.Data))
+	}
+	if content != nil && h.options.PayloadBackend != nil && len(content.Data) > h.options.PayloadSizeThreshold {
+		payloadKey = joinKey(operation, operationID)
+		header := maps.Clone(content.Header)
+		if header == nil {
+			header = Header{}
+		}
+		header["digest"] = ContentDigest(content.Data)
+		content = &Content{Header: header, Data: content.Data}
+		if err := h.options.PayloadBackend.Put(ctx, payloadKey, content); err != nil {
+			return fmt.Errorf("failed to store operation result payload: %w", err)
+		}
+		content = &Content{Header: content.Header}
+	}
+	return h.transition(ctx, operation, operationID, func(record *OperationRecord) {
+		record.State = state
+		record.Result = content
+		record.ResultPayloadKey = payloadKey
+		record.ResultSize = resultSize
+		record.Failure = failure
+	})
+}
+
+// transition applies the given update to a running operation, retrying on version conflicts.
+// Operations that have already reached a terminal state are left untouched. Operations transitioned to a terminal state
+// are set to expire according to their retention, their quota usage is released, their completion is delivered to
+// their callbacks and notified.
+func (h *AsyncHandler) transition(ctx context.Context, operation, operationID string, update func(*OperationRecord)) error {
+	for {
+		record, err := h.options.Store.Get(ctx, operation, operationID)
+		if err != nil {
+			return err
+		}
+		if record.State != OperationStateRunning {
+			return nil
+		}
+		update(record)
+		h.setExpireTime(record)
+		record.UpdatedAt = time.Now()
+		err = h.options.Store.Update(ctx, record)
+		if errors.Is(err, ErrOperationRecordVersionConflict) {
+			continue
+		}
+		if err == nil && record.State != OperationStateRunning {
+			h.releaseQuota(ctx, record)
+			h.deliverCallbacks(record)
+			if h.options.Notifier != nil {
+				h.options.Notifier.Notify(operationID)
+			}
+		}
+		return err
+	}
+}
+
+// getRecord gets the record of an operation, failing it first if it is running past its deadline, which may happen
+// when the process executing the operation has exited.
+func (h *AsyncHandler) getRecord(ctx context.Context, operation, operationID string) (*OperationRecord, error) {
+	record, err := h.options.Store.Get(ctx, operation, operationID)
+	if err != nil {
+		if errors.Is(err, ErrOperationRecordNotFound) {
+			return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation not found")
+		}
+		return nil, err
+	}
+	if record.State == OperationStateRunning && !record.Deadline.IsZero() && time.Now().After(record.Deadline) {
+		if err := h.stop(ctx, operation, operationID, OperationStateFailed, deadlineExceededFailure()); err != nil {
+			return nil, err
+		}
+		return h.getRecord(ctx, operation, operationID)
+	}
+	return record, nil
+}
+
+func deadlineExceededFailure() *Failure {
+	return &Failure{Message: "operation deadline exceeded"}
+}
+
+// GetOperationResult implements Handler.
+func (h *AsyncHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if record.State == OperationStateRunning && options.Wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, options.Wait)
+		defer cancel()
+		for record.State == OperationStateRunning {
+			if err := h.waitForUpdate(waitCtx, operation, operationID, record.Version); err != nil {
+				if waitCtx.Err() != nil {
+					return nil, ErrOperationStillRunning
+				}
+				return nil, err
+			}
+			if record, err = h.getRecord(ctx, operation, operationID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return h.resultFromRecord(ctx, record, options)
+}
+
+func (h *AsyncHandler) resultFromRecord(ctx context.Context, record *OperationRecord, options GetOperationResultOptions) (any, error) {
+	if record.IsExpired(time.Now()) {
+		return nil, ErrOperationResultGone
+	}
+	switch record.State {
+	case OperationStateRunning:
+		return nil, ErrOperationStillRunning
+	case OperationStateSucceeded:
+		if record.ResultPayloadKey != "" {
+			if h.options.PayloadBackend == nil {
+				return nil, errors.New("operation result stored in payload backend but no backend configured")
+			}
+			if presigner, ok := h.options.PayloadBackend.(PayloadPresigner); ok && options.AcceptRedirect && h.options.PayloadRedirectTTL > 0 {
+				url, err := presigner.PresignGet(ctx, record.ResultPayloadKey, h.options.PayloadRedirectTTL)
+				if err != nil {
+					return nil, fmt.Errorf("failed to presign operation result payload URL: %w", err)
+				}
+				return &ResultRedirect{URL: url, Header: record.Result.Header}, nil
+			}
+			reader, err := h.options.PayloadBackend.Get(ctx, record.ResultPayloadKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get operation result payload: %w", err)
+			}
+			return reader, nil
+		}
+		if record.Result == nil {
+			return nil, nil
+		}
+		return record.Result, nil
+	default:
+		var failure Failure
+		if record.Failure != nil {
+			failure = *record.Failure
+		}
+		return nil, &UnsuccessfulOperationError{State: record.State, Failure: failure}
+	}
+}
+
+// Lease granted to result claims that do not request a specific duration.
+const defaultResultClaimLease = 30 * time.Second
+
+// ClaimOperationResult implements Handler.
+func (h *AsyncHandler) ClaimOperationResult(ctx context.Context, operation, operationID string, options ClaimOperationResultOptions) (*HandlerResultClaim, error) {
+	lease := options.Lease
+	if lease <= 0 {
+		lease = defaultResultClaimLease
+	}
+	for {
+		record, err := h.getRecord(ctx, operation, operationID)
+		if err != nil {
+			return nil, err
+		}
+		if record.State == OperationStateRunning {
+			return nil, ErrOperationStillRunning
+		}
+		now := time.Now()
+		if record.IsExpired(now) {
+			// Gone responses are reserved for acknowledged outcomes in the claim API.
+			return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation result expired")
+		}
+		current := record.Claims[options.Group]
+		if current != nil && current.Acked {
+			return nil, ErrOperationResultAcked
+		}
+		if current != nil && now.Before(current.ExpiresAt) {
+			return nil, ErrOperationResultClaimed
+		}
+		if record.Claims == nil {
+			record.Claims = make(map[string]*OperationClaimRecord)
+		}
+		current = &OperationClaimRecord{Token: uuid.NewString(), ExpiresAt: now.Add(lease)}
+		record.Claims[options.Group] = current
+		record.UpdatedAt = now
+		if err := h.options.Store.Update(ctx, record); err != nil {
+			if errors.Is(err, ErrOperationRecordVersionConflict) {
+				continue
+			}
+			return nil, err
+		}
+		claim := &HandlerResultClaim{Token: current.Token, Lease: lease}
+		result, err := h.resultFromRecord(ctx, record, GetOperationResultOptions{Header: options.Header})
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		if errors.As(err, &unsuccessfulOperationError) {
+			claim.Unsuccessful = unsuccessfulOperationError
+		} else if err != nil {
+			return nil, err
+		}
+		claim.Result = result
+		return claim, nil
+	}
+}
+
+// AckOperationResult implements Handler.
+func (h *AsyncHandler) AckOperationResult(ctx context.Context, operation, operationID, token string, options AckOperationResultOptions) error {
+	for {
+		record, err := h.getRecord(ctx, operation, operationID)
+		if err != nil {
+			return err
+		}
+		current := record.Claims[options.Group]
+		if current == nil {
+			return ErrOperationResultClaimLost
+		}
+		if current.Token != token {
+			if current.Acked {
+				return ErrOperationResultAcked
+			}
+			return ErrOperationResultClaimLost
+		}
+		if current.Acked {
+			return nil
+		}
+		current.Acked = true
+		record.UpdatedAt = time.Now()
+		if err := h.options.Store.Update(ctx, record); err != nil {
+			if errors.Is(err, ErrOperationRecordVersionConflict) {
+				continue
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// ListOperations implements Handler. Requires the Store to implement [OperationLister].
+func (h *AsyncHandler) ListOperations(ctx context.Context, options ListOperationsOptions) (*OperationList, error) {
+	lister, ok := h.options.Store.(OperationLister)
+	if !ok {
+		return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "listing operations is not supported by the store")
+	}
+	pageSize := options.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	records, nextPageToken, err := lister.ListOperations(ctx, options.Filter, pageSize, options.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	list := &OperationList{
+		Operations:    make([]*OperationSummary, len(records)),
+		NextPageToken: nextPageToken,
+	}
+	for i, record := range records {
+		list.Operations[i] = record.Summary()
+	}
+	return list, nil
+}
+
+// GetOperationInfo implements Handler.
+func (h *AsyncHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	return record.Info(), nil
+}
+
+// CancelOperation implements Handler.
+//
+// Cancels the execution context of the operation if it is running in this process and marks the operation as
+// canceled. The reason of the cancelation, if any, is recorded as the cause of the operation's failure.
+//
+// With [CancelModeGraceful], executors running in this process are asked to stop via [CancelRequested] instead, and the
+// operation completes as canceled once the executor returns an error. Their execution context is canceled if they do
+// not return within [AsyncHandlerOptions.GracefulCancelTimeout]. Operations executing in other processes or waiting to
+// start are canceled immediately.
+func (h *AsyncHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return err
+	}
+	failure := canceledFailure(options.Reason)
+	if options.Mode == CancelModeGraceful && time.Now().After(record.StartTime) && h.requestGracefulCancel(operation, operationID, failure) {
+		return nil
+	}
+	return h.stop(ctx, operation, operationID, OperationStateCanceled, failure)
+}
+
+// requestGracefulCancel requests an operation executing in this process to stop gracefully, canceling it after the
+// graceful cancel timeout. Returns false if the operation is not executing in this process.
+func (h *AsyncHandler) requestGracefulCancel(operation, operationID string, failure *Failure) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	exec, ok := h.executions[memoryStoreKey{operation, operationID}]
+	if !ok {
+		return false
+	}
+	if exec.cancelFailure != nil {
+		// Already requested.
+		return true
+	}
+	exec.cancelFailure = failure
+	close(exec.cancelRequested)
+	exec.cancelTimer = time.AfterFunc(h.options.GracefulCancelTimeout, func() {
+		if err := h.stop(context.Background(), operation, operationID, OperationStateCanceled, failure); err != nil {
+			h.options.Logger.Error("failed to cancel operation after graceful cancel timeout", "operation", operation, "operationID", operationID, "error", err)
+		}
+	})
+	return true
+}
+
+// stop completes a running operation in the given state and cancels its execution context if it is running in this
+// process.
+func (h *AsyncHandler) stop(ctx context.Context, operation, operationID string, state OperationState, failure *Failure) error {
+	// Transition before canceling the execution context so the executor's outcome is ignored.
+	err := h.transition(ctx, operation, operationID, func(record *OperationRecord) {
+		record.State = state
+		record.Failure = failure
+	})
+	if err != nil {
+		return err
+	}
+	h.cancelExecution(operation, operationID)
+	return nil
+}
+
+// cancelExecution cancels the execution context of an operation if it is running in this process.
+func (h *AsyncHandler) cancelExecution(operation, operationID string) {
+	h.mu.Lock()
+	exec, ok := h.executions[memoryStoreKey{operation, operationID}]
+	h.mu.Unlock()
+	if ok {
+		exec.cancel()
+	}
+}
+
+// WatchOperation implements Handler.
+func (h *AsyncHandler) WatchOperation(ctx context.Context, operation, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error) {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *OperationInfo)
+	go func() {
+		defer close(ch)
+		var lastState OperationState
+		var lastQuarantined bool
+		for {
+			// Quarantining an operation is reported like a state transition to alert watchers.
+			if quarantined := record.Quarantine != nil; record.State != lastState || quarantined != lastQuarantined {
+				select {
+				case ch <- record.Info():
+				case <-ctx.Done():
+					return
+				}
+				lastState = record.State
+				lastQuarantined = quarantined
+			}
+			if record.State != OperationStateRunning {
+				return
+			}
+			if err := h.waitForUpdate(ctx, operation, operationID, record.Version); err != nil {
+				return
+			}
+			if record, err = h.options.Store.Get(ctx, operation, operationID); err != nil {
+				h.options.Logger.Error("failed to get operation record", "operation", operation, "operationID", operationID, "error", err)
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+var _ Handler = &AsyncHandler{}
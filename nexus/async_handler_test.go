@@ -0,0 +1,355 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupAsync(t *testing.T, executor AsyncExecutor) (ctx context.Context, client *Client, teardown func()) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:    NewMemoryOperationStore(),
+		Executor: executor,
+	})
+	require.NoError(t, err)
+	return setup(t, handler)
+}
+
+func TestAsyncHandler_Success(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		var s string
+		if err := input.Consume(&s); err != nil {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+		return operation + ": " + s, nil
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "echo", "hello", StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NotNil(t, handle)
+
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+
+	value, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, value.Consume(&output))
+	require.Equal(t, "echo: hello", output)
+
+	info, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateSucceeded, info.State)
+}
+
+func TestAsyncHandler_Failure(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, errors.New("boom")
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateFailed, unsuccessfulOperationError.State)
+	require.Equal(t, "boom", unsuccessfulOperationError.Failure.Message)
+}
+
+func TestAsyncHandler_Cancel(t *testing.T) {
+	canceled := make(chan struct{})
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	stream, err := handle.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+	info, err := stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	<-canceled
+
+	info, err = stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, OperationStateCanceled, info.State)
+	_, err = stream.Next()
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
+}
+
+func TestAsyncHandler_NotFound(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, nil
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "missing")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 404, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestAsyncHandler_DedupeStart(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:       NewMemoryOperationStore(),
+		DedupeCache: NewMemoryDedupeCache(time.Minute),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	first, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	second, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.Equal(t, first.Pending.ID, second.Pending.ID)
+	require.False(t, first.AlreadyStarted)
+	require.True(t, second.AlreadyStarted)
+	other, err := client.StartOperation(ctx, "bar", nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.NotEqual(t, first.Pending.ID, other.Pending.ID)
+}
+
+// flakyCreateStore is an OperationStore that fails to create the first records.
+type flakyCreateStore struct {
+	*MemoryOperationStore
+	failures atomic.Int32
+}
+
+func (s *flakyCreateStore) Create(ctx context.Context, record *OperationRecord) error {
+	if s.failures.Add(-1) >= 0 {
+		return errors.New("store unavailable")
+	}
+	return s.MemoryOperationStore.Create(ctx, record)
+}
+
+func TestAsyncHandler_PayloadBackendAmbiguousKeys(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:                NewMemoryOperationStore(),
+		PayloadBackend:       NewMemoryPayloadBackend(),
+		PayloadSizeThreshold: 1,
+		IDGenerator: OperationIDGeneratorFunc(func(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+			return options.RequestID, nil
+		}),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return []byte("result of " + operation), nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	// Both pairs joined with "/" are "a/b/c".
+	for _, operation := range []struct{ name, id string }{{"a/b", "c"}, {"a", "b/c"}} {
+		result, err := client.StartOperation(ctx, operation.name, nil, StartOperationOptions{RequestID: operation.id})
+		require.NoError(t, err)
+		require.Equal(t, operation.id, result.Pending.ID)
+		_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+		require.NoError(t, err)
+	}
+	for _, operation := range []struct{ name, id string }{{"a/b", "c"}, {"a", "b/c"}} {
+		handle, err := client.NewHandle(operation.name, operation.id)
+		require.NoError(t, err)
+		value, err := handle.GetResult(ctx, GetOperationResultOptions{})
+		require.NoError(t, err)
+		var output []byte
+		require.NoError(t, value.Consume(&output))
+		require.Equal(t, "result of "+operation.name, string(output))
+	}
+}
+
+func TestAsyncHandler_DedupeStartCreateFailure(t *testing.T) {
+	store := &flakyCreateStore{MemoryOperationStore: NewMemoryOperationStore()}
+	store.failures.Store(1)
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:       store,
+		DedupeCache: NewMemoryDedupeCache(time.Minute),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "request"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedResponseError.Response.StatusCode)
+
+	// The retry starts the operation instead of being deduplicated against the one that was never created.
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.False(t, result.AlreadyStarted)
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	duplicate, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "request"})
+	require.NoError(t, err)
+	require.True(t, duplicate.AlreadyStarted)
+	require.Equal(t, result.Pending.ID, duplicate.Pending.ID)
+}
+
+func TestAsyncHandler_PayloadBackend(t *testing.T) {
+	store := NewMemoryOperationStore()
+	payloads := NewMemoryPayloadBackend()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:                store,
+		PayloadBackend:       payloads,
+		PayloadSizeThreshold: 10,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			if operation == "small" {
+				return []byte("small"), nil
+			}
+			return []byte("a large result"), nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	for _, c := range []struct {
+		operation string
+		expected  string
+		offloaded bool
+	}{
+		{"small", "small", false},
+		{"large", "a large result", true},
+	} {
+		c := c
+		t.Run(c.operation, func(t *testing.T) {
+			result, err := client.StartOperation(ctx, c.operation, nil, StartOperationOptions{})
+			require.NoError(t, err)
+			value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+			require.NoError(t, err)
+			var output []byte
+			require.NoError(t, value.Consume(&output))
+			require.Equal(t, c.expected, string(output))
+
+			record, err := store.Get(ctx, c.operation, result.Pending.ID)
+			require.NoError(t, err)
+			if c.offloaded {
+				require.Equal(t, joinKey(c.operation, result.Pending.ID), record.ResultPayloadKey)
+				require.Empty(t, record.Result.Data)
+				require.Equal(t, "application/octet-stream", record.Result.Header.Get("type"))
+			} else {
+				require.Empty(t, record.ResultPayloadKey)
+				require.Equal(t, []byte("small"), record.Result.Data)
+			}
+		})
+	}
+}
+
+type presigningPayloadBackend struct {
+	*MemoryPayloadBackend
+	server *httptest.Server
+}
+
+func (b *presigningPayloadBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return b.server.URL + "/" + key, nil
+}
+
+func TestAsyncHandler_PayloadRedirect(t *testing.T) {
+	payloads := &presigningPayloadBackend{MemoryPayloadBackend: NewMemoryPayloadBackend()}
+	payloads.server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		reader, err := payloads.Get(request.Context(), strings.TrimPrefix(request.URL.Path, "/"))
+		if err != nil {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		defer reader.Close()
+		_, _ = io.Copy(writer, reader)
+	}))
+	defer payloads.server.Close()
+
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:                NewMemoryOperationStore(),
+		PayloadBackend:       payloads,
+		PayloadSizeThreshold: 1,
+		PayloadRedirectTTL:   time.Minute,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return "a large result", nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{FollowResultRedirects: true})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.NotEmpty(t, value.Reader.Header.Get("digest"))
+	var output string
+	require.NoError(t, value.Consume(&output))
+	require.Equal(t, "a large result", output)
+}
+
+func TestAsyncHandler_Metadata(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		Metadata: func(ctx context.Context, operation string, options StartOperationOptions) map[string]string {
+			return map[string]string{"team": options.Tags["team"]}
+		},
+	})
+	require.NoError(t, err)
+	authorizer := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+		request.Caller = "alice"
+		return nil
+	})
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler, Authorizer: authorizer}, ClientOptions{})
+	defer teardown()
+
+	before := time.Now()
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: map[string]string{"team": "payments"}})
+	require.NoError(t, err)
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "payments", info.Metadata["team"])
+	require.Equal(t, "alice", info.Caller())
+	createdAt, ok := info.CreatedAt()
+	require.True(t, ok)
+	require.WithinRange(t, createdAt, before.Add(-time.Second), time.Now())
+
+	list, err := client.ListOperations(ctx, ListOperationsOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 1)
+	require.Equal(t, info.Metadata, list.Operations[0].Metadata)
+}
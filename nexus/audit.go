@@ -0,0 +1,157 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// An AuditSink records an audit trail of the state-changing requests handled by the handlers returned from
+// [NewHTTPHandler], [NewCompletionHTTPHandler] and [NewCompletionMessageHandler], e.g. for compliance, see
+// [HandlerOptions.AuditSink] and [CompletionHandlerOptions.AuditSink].
+//
+// Audited requests are start, cancel, batch cancel, cancel matching, claim and ack result, heartbeat, resolve
+// quarantine and force complete requests as well as completions, whether they succeed, fail or are denied.
+type AuditSink interface {
+	// Audit is called once for every audited request after it has been handled and before the handler returns, so
+	// implementations should hand events off, e.g. to a buffered writer, rather than block. The context carries the
+	// request's values, e.g. its [HandlerInfo], and is not canceled when the caller disconnects.
+	Audit(ctx context.Context, event *AuditEvent)
+}
+
+// AuditSinkFunc is an [AuditSink] backed by a function.
+type AuditSinkFunc func(ctx context.Context, event *AuditEvent)
+
+// Audit implements AuditSink.
+func (f AuditSinkFunc) Audit(ctx context.Context, event *AuditEvent) {
+	f(ctx, event)
+}
+
+// HandlerMethodCompleteOperation identifies completion requests in [AuditEvent]s. Completions are handled by a
+// [CompletionHandler], not a [Handler].
+const HandlerMethodCompleteOperation HandlerMethod = "CompleteOperation"
+
+// AuditOutcome is the outcome of an audited request.
+type AuditOutcome string
+
+const (
+	// The request was handled successfully.
+	AuditOutcomeSucceeded AuditOutcome = "succeeded"
+	// The request was rejected with a 401 or 403 status code, e.g. by the [Authorizer] or for an invalid completion
+	// signature.
+	AuditOutcomeDenied AuditOutcome = "denied"
+	// The request failed with any other 4xx or 5xx status code. Start requests failing with an
+	// [UnsuccessfulOperationError] are failed too.
+	AuditOutcomeFailed AuditOutcome = "failed"
+)
+
+// AuditEvent describes an audited request, see [AuditSink].
+type AuditEvent struct {
+	// The endpoint the request was addressed to.
+	Method HandlerMethod
+	// Name of the service, see [HandlerOptions.Service]. Empty for completions.
+	Service string
+	// Name of the operation. Empty for completions and requests that apply to multiple operations.
+	Operation string
+	// ID of the operation. For start requests, the ID of the operation started asynchronously or of the operation the
+	// request was deduplicated against, if any. For completions, the ID provided by the sender, if any.
+	OperationID string
+	// Request ID, see [HeaderRequestID].
+	RequestID string
+	// Identity of the caller, as verified or set by the [Authorizer], see [AuthorizationRequest.Caller]. For requests
+	// denied before being authorized and for completions, the identity asserted in the [HeaderCallerIdentity] header,
+	// which is not authenticated.
+	Caller string
+	// Name and version of the caller's client library, sent in the [HeaderClientName] and [HeaderClientVersion]
+	// headers.
+	ClientName, ClientVersion string
+	// Network address of the caller, see [http.Request.RemoteAddr]. Empty for completions received as messages.
+	RemoteAddr string
+	// State of the operation reported by a completion. Empty for other requests.
+	State OperationState
+	// Outcome of the request.
+	Outcome AuditOutcome
+	// Status code the request was responded to with.
+	StatusCode int
+	// Error the request failed with, if any, e.g. the error returned by the handler.
+	Error error
+	// Time the request was received.
+	StartTime time.Time
+	// Time it took to handle the request.
+	Duration time.Duration
+}
+
+// auditedHandlerMethods are the [Handler] methods whose requests are audited.
+var auditedHandlerMethods = map[HandlerMethod]bool{
+	HandlerMethodStartOperation:           true,
+	HandlerMethodCancelOperation:          true,
+	HandlerMethodCancelOperations:         true,
+	HandlerMethodCancelMatchingOperations: true,
+	HandlerMethodClaimOperationResult:     true,
+	HandlerMethodAckOperationResult:       true,
+	HandlerMethodHeartbeatOperation:       true,
+	HandlerMethodResolveQuarantine:        true,
+	HandlerMethodForceCompleteOperation:   true,
+}
+
+// startAudit returns a function to be called once the request has been handled, which records an audit event for the
+// request if the method is audited. The writer must be the one returned from startRequestLog.
+func (h *baseHTTPHandler) startAudit(writer http.ResponseWriter, request *http.Request, method HandlerMethod) func() {
+	if h.auditSink == nil || (method != HandlerMethodCompleteOperation && !auditedHandlerMethods[method]) {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		recorder, _ := writer.(*statusRecorder)
+		event := &AuditEvent{
+			Method:        method,
+			RequestID:     request.Header.Get(HeaderRequestID),
+			Caller:        request.Header.Get(HeaderCallerIdentity),
+			ClientName:    request.Header.Get(HeaderClientName),
+			ClientVersion: request.Header.Get(HeaderClientVersion),
+			RemoteAddr:    request.RemoteAddr,
+			StatusCode:    http.StatusOK,
+			StartTime:     start,
+			Duration:      time.Since(start),
+		}
+		if recorder != nil {
+			if recorder.status != 0 {
+				event.StatusCode = recorder.status
+			}
+			event.Error = recorder.err
+			event.OperationID = recorder.operationID
+		}
+		if event.OperationID == "" {
+			// Set on start requests rejected for an operation that was already started.
+			event.OperationID = writer.Header().Get(HeaderOperationID)
+		}
+		if method == HandlerMethodCompleteOperation {
+			event.State = OperationState(request.Header.Get(HeaderOperationState))
+			event.OperationID = request.Header.Get(HeaderOperationID)
+		} else {
+			vars := routeVars(request)
+			if v, ok := vars["operation"]; ok {
+				event.Operation, _ = url.PathUnescape(v)
+			}
+			if v, ok := vars["operation_id"]; ok {
+				event.OperationID, _ = url.PathUnescape(v)
+			}
+			if info, ok := HandlerInfoFromContext(request.Context()); ok {
+				event.Service = info.Service
+				if info.Caller != "" {
+					event.Caller = info.Caller
+				}
+			}
+		}
+		switch {
+		case event.StatusCode == http.StatusUnauthorized || event.StatusCode == http.StatusForbidden:
+			event.Outcome = AuditOutcomeDenied
+		case event.StatusCode >= http.StatusBadRequest:
+			event.Outcome = AuditOutcomeFailed
+		default:
+			event.Outcome = AuditOutcomeSucceeded
+		}
+		h.auditSink.Audit(context.WithoutCancel(request.Context()), event)
+	}
+}
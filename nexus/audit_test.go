@@ -0,0 +1,112 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type auditedHandler struct {
+	UnimplementedHandler
+}
+
+func (h *auditedHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultAsync{OperationID: "op-1"}, nil
+}
+
+func (h *auditedHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	return nil
+}
+
+func (h *auditedHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func TestAuditSink(t *testing.T) {
+	events := make(chan *AuditEvent, 10)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &auditedHandler{},
+		Service: "billing",
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			if request.OperationID == "forbidden" {
+				return HandlerErrorf(HandlerErrorTypeUnauthorized, "forbidden")
+			}
+			request.Caller = "verified:" + request.Caller
+			return nil
+		}),
+		AuditSink: AuditSinkFunc(func(ctx context.Context, event *AuditEvent) {
+			events <- event
+		}),
+	}, ClientOptions{CallerIdentity: "checkout"})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "charge", nil, StartOperationOptions{RequestID: "request-1"})
+	require.NoError(t, err)
+	event := <-events
+	require.Equal(t, HandlerMethodStartOperation, event.Method)
+	require.Equal(t, "billing", event.Service)
+	require.Equal(t, "charge", event.Operation)
+	require.Equal(t, "op-1", event.OperationID)
+	require.Equal(t, "request-1", event.RequestID)
+	require.Equal(t, "verified:checkout", event.Caller)
+	require.NotEmpty(t, event.ClientName)
+	require.NotEmpty(t, event.RemoteAddr)
+	require.Equal(t, AuditOutcomeSucceeded, event.Outcome)
+	require.Equal(t, http.StatusCreated, event.StatusCode)
+	require.NoError(t, event.Error)
+	require.False(t, event.StartTime.IsZero())
+
+	// Reads are not audited.
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	event = <-events
+	require.Equal(t, HandlerMethodCancelOperation, event.Method)
+	require.Equal(t, "op-1", event.OperationID)
+	require.Equal(t, AuditOutcomeSucceeded, event.Outcome)
+
+	handle, err := client.NewHandle("charge", "forbidden")
+	require.NoError(t, err)
+	require.Error(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	event = <-events
+	require.Equal(t, HandlerMethodCancelOperation, event.Method)
+	require.Equal(t, "forbidden", event.OperationID)
+	require.Equal(t, "checkout", event.Caller)
+	require.Equal(t, AuditOutcomeDenied, event.Outcome)
+	require.Equal(t, http.StatusForbidden, event.StatusCode)
+	var handlerError *HandlerError
+	require.ErrorAs(t, event.Error, &handlerError)
+	require.Empty(t, events)
+}
+
+func TestAuditSink_Completion(t *testing.T) {
+	events := make(chan *AuditEvent, 1)
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, request *CompletionRequest) error {
+			return nil
+		}),
+		AuditSink: AuditSinkFunc(func(ctx context.Context, event *AuditEvent) {
+			events <- event
+		}),
+	}))
+	defer server.Close()
+
+	completion := &OperationCompletionUnsuccessful{OperationID: "op-1", State: OperationStateFailed, Failure: &Failure{Message: "boom"}}
+	request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+	require.NoError(t, err)
+	request.Header.Set(HeaderCallerIdentity, "billing")
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	event := <-events
+	require.Equal(t, HandlerMethodCompleteOperation, event.Method)
+	require.Equal(t, "op-1", event.OperationID)
+	require.Equal(t, OperationStateFailed, event.State)
+	require.Equal(t, "billing", event.Caller)
+	require.Equal(t, AuditOutcomeSucceeded, event.Outcome)
+}
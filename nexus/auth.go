@@ -0,0 +1,195 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const headerAuthorization = "Authorization"
+
+// An AuthProvider provides credentials for outgoing requests in the form of an Authorization header value.
+//
+// Set [ClientOptions.AuthProvider] to authorize all client requests or use [AuthorizeHTTPRequest] for requests built
+// outside of the client, such as completion requests.
+//
+// Implementations must be safe for concurrent use.
+type AuthProvider interface {
+	// AuthorizationHeader returns the value of the Authorization header to attach to a request, e.g. "Bearer <token>".
+	AuthorizationHeader(ctx context.Context) (string, error)
+}
+
+// AuthProviderFunc is an [AuthProvider] backed by a function, invoked for every request.
+// Use it to integrate with external token sources that handle their own caching and refresh.
+type AuthProviderFunc func(ctx context.Context) (string, error)
+
+// AuthorizationHeader implements AuthProvider.
+func (f AuthProviderFunc) AuthorizationHeader(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type staticTokenAuthProvider string
+
+// NewStaticTokenAuthProvider creates an [AuthProvider] that authorizes every request with the given bearer token.
+func NewStaticTokenAuthProvider(token string) AuthProvider {
+	return staticTokenAuthProvider("Bearer " + token)
+}
+
+// AuthorizationHeader implements AuthProvider.
+func (p staticTokenAuthProvider) AuthorizationHeader(context.Context) (string, error) {
+	return string(p), nil
+}
+
+// OAuth2ClientCredentialsOptions are options for [NewOAuth2ClientCredentialsAuthProvider].
+type OAuth2ClientCredentialsOptions struct {
+	// URL of the authorization server's token endpoint. Required.
+	TokenURL string
+	// Client ID. Required.
+	ClientID string
+	// Client secret.
+	ClientSecret string
+	// Optional scopes to request.
+	Scopes []string
+	// Additional parameters to send in token requests, e.g. "audience".
+	EndpointParams url.Values
+	// Tokens are refreshed this long before they expire to account for clock skew and request latency.
+	// Defaults to 30 seconds.
+	ExpiryDelta time.Duration
+	// A function for making HTTP requests to the token endpoint.
+	// Defaults to [http.DefaultClient.Do].
+	HTTPCaller func(*http.Request) (*http.Response, error)
+}
+
+type oauth2ClientCredentialsAuthProvider struct {
+	options OAuth2ClientCredentialsOptions
+
+	mu     sync.Mutex
+	header string
+	expiry time.Time
+}
+
+// NewOAuth2ClientCredentialsAuthProvider creates an [AuthProvider] that obtains bearer tokens using the OAuth2 client
+// credentials grant. Tokens are cached and refreshed shortly before they expire.
+func NewOAuth2ClientCredentialsAuthProvider(options OAuth2ClientCredentialsOptions) (AuthProvider, error) {
+	var es []error
+	if options.TokenURL == "" {
+		es = append(es, errors.New("empty token URL"))
+	}
+	if options.ClientID == "" {
+		es = append(es, errors.New("empty client ID"))
+	}
+	if len(es) > 0 {
+		return nil, errors.Join(es...)
+	}
+	if options.ExpiryDelta == 0 {
+		options.ExpiryDelta = 30 * time.Second
+	}
+	if options.HTTPCaller == nil {
+		options.HTTPCaller = http.DefaultClient.Do
+	}
+	return &oauth2ClientCredentialsAuthProvider{options: options}, nil
+}
+
+// AuthorizationHeader implements AuthProvider.
+func (p *oauth2ClientCredentialsAuthProvider) AuthorizationHeader(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.header != "" && (p.expiry.IsZero() || time.Now().Before(p.expiry.Add(-p.options.ExpiryDelta))) {
+		return p.header, nil
+	}
+	header, expiry, err := p.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.header = header
+	p.expiry = expiry
+	return header, nil
+}
+
+func (p *oauth2ClientCredentialsAuthProvider) fetchToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{}
+	for k, v := range p.options.EndpointParams {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(p.options.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.options.Scopes, " "))
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", p.options.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(url.QueryEscape(p.options.ClientID), url.QueryEscape(p.options.ClientSecret))
+
+	requestTime := time.Now()
+	response, err := p.options.HTTPCaller(request)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("token request failed with status %q: %s", response.Status, body)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", time.Time{}, errors.New("token response missing access_token")
+	}
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = requestTime.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return tokenType + " " + token.AccessToken, expiry, nil
+}
+
+// AuthorizeHTTPRequest sets the Authorization header on the given request using the provided [AuthProvider] unless the
+// header is already set.
+//
+// Use this to authorize requests built outside of the [Client], such as those created with
+// [NewCompletionHTTPRequest].
+func AuthorizeHTTPRequest(request *http.Request, provider AuthProvider) error {
+	if request.Header.Get(headerAuthorization) != "" {
+		return nil
+	}
+	header, err := provider.AuthorizationHeader(request.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get authorization header: %w", err)
+	}
+	request.Header.Set(headerAuthorization, header)
+	return nil
+}
+
+// authorizingHTTPCaller wraps caller to authorize every request with the given provider.
+func authorizingHTTPCaller(caller func(*http.Request) (*http.Response, error), provider AuthProvider) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		if err := AuthorizeHTTPRequest(request, provider); err != nil {
+			if request.Body != nil {
+				_, _ = io.Copy(io.Discard, request.Body)
+				request.Body.Close()
+			}
+			return nil, err
+		}
+		return caller(request)
+	}
+}
@@ -0,0 +1,98 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type authEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *authEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: options.Header.Get(headerAuthorization)}, nil
+}
+
+func startAuthEcho(t *testing.T, ctx context.Context, client *Client, options StartOperationOptions) string {
+	result, err := StartOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, options)
+	require.NoError(t, err)
+	return result.Successful
+}
+
+func TestAuthProvider_Static(t *testing.T) {
+	ctx, client, teardown := setup(t, &authEchoHandler{})
+	defer teardown()
+	client.options.HTTPCaller = authorizingHTTPCaller(client.options.HTTPCaller, NewStaticTokenAuthProvider("secret"))
+
+	require.Equal(t, "Bearer secret", startAuthEcho(t, ctx, client, StartOperationOptions{}))
+	require.Equal(t, "Basic override", startAuthEcho(t, ctx, client, StartOperationOptions{Header: Header{"Authorization": "Basic override"}}))
+}
+
+func TestAuthProvider_PerRequest(t *testing.T) {
+	ctx, client, teardown := setup(t, &authEchoHandler{})
+	defer teardown()
+	var calls atomic.Int32
+	client.options.HTTPCaller = authorizingHTTPCaller(client.options.HTTPCaller, AuthProviderFunc(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("Bearer token-%d", calls.Add(1)), nil
+	}))
+
+	require.Equal(t, "Bearer token-1", startAuthEcho(t, ctx, client, StartOperationOptions{}))
+	require.Equal(t, "Bearer token-2", startAuthEcho(t, ctx, client, StartOperationOptions{}))
+}
+
+func TestAuthProvider_OAuth2ClientCredentials(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "id" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "a b" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		n := tokenRequests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+	}))
+	defer tokenServer.Close()
+
+	provider, err := NewOAuth2ClientCredentialsAuthProvider(OAuth2ClientCredentialsOptions{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"a", "b"},
+	})
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, &authEchoHandler{})
+	defer teardown()
+	client.options.HTTPCaller = authorizingHTTPCaller(client.options.HTTPCaller, provider)
+
+	require.Equal(t, "Bearer token-1", startAuthEcho(t, ctx, client, StartOperationOptions{}))
+	require.Equal(t, "Bearer token-1", startAuthEcho(t, ctx, client, StartOperationOptions{}))
+	require.Equal(t, int32(1), tokenRequests.Load())
+}
+
+func TestAuthProvider_Error(t *testing.T) {
+	ctx, client, teardown := setup(t, &authEchoHandler{})
+	defer teardown()
+	client.options.HTTPCaller = authorizingHTTPCaller(client.options.HTTPCaller, AuthProviderFunc(func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("no credentials")
+	}))
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorContains(t, err, "no credentials")
+}
+
+func TestAuthorizeHTTPRequest_Completion(t *testing.T) {
+	completion, err := NewOperationCompletionSuccessful(nil, OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	require.NoError(t, AuthorizeHTTPRequest(request, NewStaticTokenAuthProvider("secret")))
+	require.Equal(t, "Bearer secret", request.Header.Get("Authorization"))
+}
@@ -0,0 +1,113 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// HandlerMethod identifies the Nexus service endpoint a request is addressed to.
+type HandlerMethod string
+
+const (
+	// Start operation requests.
+	HandlerMethodStartOperation HandlerMethod = "StartOperation"
+	// Get operation result requests.
+	HandlerMethodGetOperationResult HandlerMethod = "GetOperationResult"
+	// Get operation info requests.
+	HandlerMethodGetOperationInfo HandlerMethod = "GetOperationInfo"
+	// Cancel operation requests.
+	HandlerMethodCancelOperation HandlerMethod = "CancelOperation"
+	// Watch operation requests.
+	HandlerMethodWatchOperation HandlerMethod = "WatchOperation"
+	// Claim operation result requests.
+	HandlerMethodClaimOperationResult HandlerMethod = "ClaimOperationResult"
+	// Acknowledge operation result requests.
+	HandlerMethodAckOperationResult HandlerMethod = "AckOperationResult"
+	// List operations requests.
+	HandlerMethodListOperations HandlerMethod = "ListOperations"
+	// Administrative requests to cancel or terminate operations matching a filter.
+	HandlerMethodCancelMatchingOperations HandlerMethod = "CancelMatchingOperations"
+	// Administrative requests to get the quota usage of a subject.
+	HandlerMethodGetQuotaUsage HandlerMethod = "GetQuotaUsage"
+	// Heartbeat operation requests.
+	HandlerMethodHeartbeatOperation HandlerMethod = "HeartbeatOperation"
+	// Administrative requests to resolve a quarantined operation.
+	HandlerMethodResolveQuarantine HandlerMethod = "ResolveQuarantine"
+	// Administrative requests to force an operation into a terminal state.
+	HandlerMethodForceCompleteOperation HandlerMethod = "ForceCompleteOperation"
+	// Batch cancel requests. Every operation in the batch is additionally authorized as a cancel operation request.
+	HandlerMethodCancelOperations HandlerMethod = "CancelOperations"
+	// Connectivity and protocol compatibility checks, see [Client.Ping].
+	HandlerMethodPing HandlerMethod = "Ping"
+)
+
+// AuthorizationRequest is input for [Authorizer.Authorize].
+type AuthorizationRequest struct {
+	// The endpoint the request is addressed to.
+	Method HandlerMethod
+	// Name of the operation. Empty for requests that apply to multiple operations.
+	Operation string
+	// ID of the operation. Empty for start requests and requests that apply to multiple operations.
+	OperationID string
+	// Request header fields, excluding "content-" prefixed headers.
+	Header Header
+	// The original HTTP request. The body must not be read.
+	HTTPRequest *http.Request
+	// Identity of the caller. Initialized to the identity asserted by the client in the [HeaderCallerIdentity] header,
+	// if any, which is not authenticated. Authorizers may verify or replace it once the caller is authenticated to
+	// expose it to handler methods, see [HandlerInfo.Caller].
+	Caller string
+}
+
+// An Authorizer decides whether a request is allowed to be dispatched to the [Handler].
+//
+// Set [HandlerOptions.Authorizer] to authorize all requests handled by an HTTP handler.
+type Authorizer interface {
+	// Authorize returns nil to allow a request.
+	//
+	// Return a [HandlerError] of type [HandlerErrorTypeUnauthenticated] to respond with 401 or
+	// [HandlerErrorTypeUnauthorized] to respond with 403. Any other [HandlerError] is responded to as usual. Arbitrary
+	// errors deny the request with 403, their details are logged and hidden from the caller.
+	Authorize(ctx context.Context, request *AuthorizationRequest) error
+}
+
+// AuthorizerFunc is an [Authorizer] backed by a function.
+type AuthorizerFunc func(ctx context.Context, request *AuthorizationRequest) error
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, request *AuthorizationRequest) error {
+	return f(ctx, request)
+}
+
+// authorize invokes the configured Authorizer, if any. Writes a failure response and returns false if the request is
+// denied.
+func (h *httpHandler) authorize(ctx context.Context, writer http.ResponseWriter, request *AuthorizationRequest) bool {
+	if err := h.authorizationError(ctx, request); err != nil {
+		h.writeFailure(writer, err)
+		return false
+	}
+	setAuthorizedHandlerInfo(ctx, request)
+	return true
+}
+
+// authorizationError invokes the configured Authorizer, if any, and returns the [HandlerError] to deny the request
+// with or nil if the request is allowed.
+func (h *httpHandler) authorizationError(ctx context.Context, request *AuthorizationRequest) error {
+	if request.Caller == "" && request.HTTPRequest != nil {
+		request.Caller = request.HTTPRequest.Header.Get(HeaderCallerIdentity)
+	}
+	if h.options.Authorizer == nil {
+		return nil
+	}
+	err := h.options.Authorizer.Authorize(ctx, request)
+	if err == nil {
+		return nil
+	}
+	var handlerError *HandlerError
+	if !errors.As(err, &handlerError) {
+		h.logger.Warn("request denied by authorizer", "method", request.Method, "operation", request.Operation, "error", err)
+		err = HandlerErrorf(HandlerErrorTypeUnauthorized, "unauthorized")
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizer(t *testing.T) {
+	var requests []AuthorizationRequest
+	authorizer := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+		requests = append(requests, *request)
+		switch request.Header.Get("user") {
+		case "":
+			return HandlerErrorf(HandlerErrorTypeUnauthenticated, "missing user")
+		case "admin":
+			return nil
+		case "guest":
+			if request.Method == HandlerMethodCancelOperation {
+				return HandlerErrorf(HandlerErrorTypeUnauthorized, "guests may not cancel")
+			}
+			return nil
+		default:
+			return errors.New("unknown user")
+		}
+	})
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &asyncWithCancelHandler{}, Authorizer: authorizer}, ClientOptions{})
+	defer teardown()
+
+	requireStatus := func(t *testing.T, err error, status int) {
+		var unexpectedResponseError *UnexpectedResponseError
+		require.ErrorAs(t, err, &unexpectedResponseError)
+		require.Equal(t, status, unexpectedResponseError.Response.StatusCode)
+	}
+
+	_, err := client.StartOperation(ctx, "f/o/o", nil, StartOperationOptions{})
+	requireStatus(t, err, http.StatusUnauthorized)
+
+	result, err := client.StartOperation(ctx, "f/o/o", nil, StartOperationOptions{Header: Header{"user": "guest"}})
+	require.NoError(t, err)
+	handle := result.Pending
+	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{"user": "guest"}})
+	requireStatus(t, err, http.StatusForbidden)
+	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{"user": "mallory"}})
+	requireStatus(t, err, http.StatusForbidden)
+	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{"user": "admin"}})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 5)
+	require.Equal(t, HandlerMethodStartOperation, requests[1].Method)
+	require.Equal(t, "f/o/o", requests[1].Operation)
+	require.Equal(t, "", requests[1].OperationID)
+	require.Equal(t, HandlerMethodCancelOperation, requests[4].Method)
+	require.Equal(t, "f/o/o", requests[4].Operation)
+	require.Equal(t, "a/sync", requests[4].OperationID)
+}
@@ -0,0 +1,319 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Maximum number of operations in a single batch cancel request. [Client.CancelOperations] splits larger batches.
+const maxCancelBatchSize = 1000
+
+// Number of operations of a batch cancel request the handler cancels concurrently.
+const cancelBatchConcurrency = 16
+
+// Default number of concurrent cancel requests issued by [Client.CancelOperations].
+const defaultCancelOperationsConcurrency = 10
+
+// An OperationKey identifies an asynchronous operation by name and ID.
+type OperationKey struct {
+	// Name of the operation.
+	Operation string `json:"operation"`
+	// ID of the operation.
+	ID string `json:"id"`
+}
+
+// Key returns the [OperationKey] identifying this handle's operation.
+func (h *OperationHandle[T]) Key() OperationKey {
+	return OperationKey{Operation: h.Operation, ID: h.ID}
+}
+
+// CancelOperationsOptions are options for [Client.CancelOperations].
+type CancelOperationsOptions struct {
+	// Header contains the request header fields to be sent by the client.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Maximum number of concurrent cancel requests. Defaults to 10.
+	MaxConcurrency int
+	// Send the operations to the handler's batch cancel route, canceling up to 1000 operations per request, instead of
+	// issuing a cancel request per operation. Requires handler support, see [NewHTTPHandler].
+	UseBatchRoute bool
+}
+
+// CancelOperationFailure is an operation that could not be canceled by [Client.CancelOperations].
+type CancelOperationFailure struct {
+	// The operation that could not be canceled.
+	Operation OperationKey
+	// The error canceling the operation failed with. Failures reported by the handler's batch cancel route are
+	// [HandlerError]s; failed individual cancel requests fail as with [OperationHandle.Cancel].
+	Err error
+}
+
+// CancelOperationsError is returned by [Client.CancelOperations] when some operations could not be canceled.
+// It unwraps to the errors of the individual failures.
+type CancelOperationsError struct {
+	// Operations that could not be canceled.
+	Failures []CancelOperationFailure
+}
+
+// Error implements the error interface.
+func (e *CancelOperationsError) Error() string {
+	if len(e.Failures) == 1 {
+		f := e.Failures[0]
+		return fmt.Sprintf("failed to cancel operation %s/%s: %v", f.Operation.Operation, f.Operation.ID, f.Err)
+	}
+	return fmt.Sprintf("failed to cancel %d operations, first error: %v", len(e.Failures), e.Failures[0].Err)
+}
+
+// Unwrap returns the errors of the individual failures.
+func (e *CancelOperationsError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+type cancelOperationsRequest struct {
+	Operations []OperationKey `json:"operations"`
+}
+
+type cancelOperationsFailure struct {
+	OperationKey
+	Type    HandlerErrorType `json:"type"`
+	Failure *Failure         `json:"failure,omitempty"`
+}
+
+type cancelOperationsResponse struct {
+	Failures []cancelOperationsFailure `json:"failures"`
+}
+
+// CancelOperations requests cancelation of many operations at once, e.g. during incident response. Operations are
+// canceled with up to [CancelOperationsOptions.MaxConcurrency] concurrent requests, or via the handler's batch cancel
+// route if [CancelOperationsOptions.UseBatchRoute] is set.
+//
+// All operations are attempted. If some could not be canceled, a [CancelOperationsError] listing them is returned.
+func (c *Client) CancelOperations(ctx context.Context, operations []OperationKey, options CancelOperationsOptions, opts ...CallOption) error {
+	ctx, c = c.withCallOptions(ctx, opts)
+	concurrency := options.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultCancelOperationsConcurrency
+	}
+	var batches [][]OperationKey
+	if options.UseBatchRoute {
+		for start := 0; start < len(operations); start += maxCancelBatchSize {
+			batches = append(batches, operations[start:min(start+maxCancelBatchSize, len(operations))])
+		}
+	} else {
+		for i := range operations {
+			batches = append(batches, operations[i:i+1])
+		}
+	}
+
+	var mu sync.Mutex
+	var failures []CancelOperationFailure
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			for _, key := range batch {
+				failures = append(failures, CancelOperationFailure{Operation: key, Err: ctx.Err()})
+			}
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(batch []OperationKey) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var batchFailures []CancelOperationFailure
+			if options.UseBatchRoute {
+				batchFailures = c.cancelBatch(ctx, batch, options.Header)
+			} else if err := c.cancelOne(ctx, batch[0], options.Header); err != nil {
+				batchFailures = []CancelOperationFailure{{Operation: batch[0], Err: err}}
+			}
+			mu.Lock()
+			failures = append(failures, batchFailures...)
+			mu.Unlock()
+		}(batch)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &CancelOperationsError{Failures: failures}
+	}
+	return nil
+}
+
+func (c *Client) cancelOne(ctx context.Context, key OperationKey, header Header) error {
+	handle, err := c.NewHandle(key.Operation, key.ID)
+	if err != nil {
+		return err
+	}
+	return handle.Cancel(ctx, CancelOperationOptions{Header: header})
+}
+
+// cancelBatch sends a batch cancel request, returning the operations that could not be canceled.
+func (c *Client) cancelBatch(ctx context.Context, batch []OperationKey, header Header) []CancelOperationFailure {
+	failAll := func(err error) []CancelOperationFailure {
+		failures := make([]CancelOperationFailure, len(batch))
+		for i, key := range batch {
+			failures[i] = CancelOperationFailure{Operation: key, Err: err}
+		}
+		return failures
+	}
+
+	b, err := c.options.JSON.marshal(cancelOperationsRequest{Operations: batch})
+	if err != nil {
+		return failAll(err)
+	}
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_admin", "cancel", "batch")
+	request, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return failAll(err)
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	request.Header.Set("Content-Type", contentTypeJSON)
+	addNexusHeaderToHTTPHeader(header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return failAll(err)
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return failAll(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return failAll(newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON))
+	}
+	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
+		return failAll(newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, c.options.JSON))
+	}
+	var result cancelOperationsResponse
+	if err := c.options.JSON.unmarshal(body, &result); err != nil {
+		return failAll(err)
+	}
+	failures := make([]CancelOperationFailure, len(result.Failures))
+	for i, f := range result.Failures {
+		failures[i] = CancelOperationFailure{Operation: f.OperationKey, Err: &HandlerError{Type: f.Type, Failure: f.Failure}}
+	}
+	return failures
+}
+
+func (h *httpHandler) cancelOperations(writer http.ResponseWriter, request *http.Request) {
+	header := httpHeaderToNexusHeader(request.Header)
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodCancelOperations,
+		Header:      header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	if !isMediaTypeJSON(request.Header.Get("Content-Type")) {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request content type: %q", request.Header.Get("Content-Type")))
+		return
+	}
+	b, err := io.ReadAll(request.Body)
+	if err != nil {
+		h.writeFailure(writer, requestBodyError(err, "failed to read request body"))
+		return
+	}
+	var batch cancelOperationsRequest
+	if err := h.json.unmarshal(b, &batch); err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request body"))
+		return
+	}
+	if len(batch.Operations) > maxCancelBatchSize {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "batch exceeds %d operations", maxCancelBatchSize))
+		return
+	}
+
+	var mu sync.Mutex
+	response := cancelOperationsResponse{Failures: []cancelOperationsFailure{}}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cancelBatchConcurrency)
+	for _, key := range batch.Operations {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(key OperationKey) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := h.cancelBatchedOperation(ctx, request, key, header); err != nil {
+				failure := h.batchFailure(key, err)
+				mu.Lock()
+				response.Failures = append(response.Failures, failure)
+				mu.Unlock()
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	body, err := h.json.marshal(response)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal batch cancel response: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(body); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// cancelBatchedOperation authorizes and cancels a single operation of a batch cancel request.
+func (h *httpHandler) cancelBatchedOperation(ctx context.Context, request *http.Request, key OperationKey, header Header) error {
+	if key.Operation == "" || key.ID == "" {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid operation key")
+	}
+	if err := h.authorizationError(ctx, &AuthorizationRequest{
+		Method:      HandlerMethodCancelOperation,
+		Operation:   key.Operation,
+		OperationID: key.ID,
+		Header:      header,
+		HTTPRequest: request,
+	}); err != nil {
+		return err
+	}
+	return h.options.Handler.CancelOperation(ctx, key.Operation, key.ID, CancelOperationOptions{Header: header})
+}
+
+// batchFailure converts an error canceling an operation of a batch to its wire representation, hiding the details of
+// errors that are not [HandlerError]s as [baseHTTPHandler.writeFailure] does.
+func (h *httpHandler) batchFailure(key OperationKey, err error) cancelOperationsFailure {
+	var handlerError *HandlerError
+	if errors.As(err, &handlerError) {
+		typ := handlerError.Type
+		if typ == "" {
+			typ = HandlerErrorTypeInternal
+		}
+		return cancelOperationsFailure{OperationKey: key, Type: typ, Failure: handlerError.Failure}
+	}
+	h.logger.Error("handler failed", "operation", key.Operation, "operationID", key.ID, "error", err)
+	return cancelOperationsFailure{
+		OperationKey: key,
+		Type:         HandlerErrorTypeInternal,
+		Failure:      &Failure{Message: "internal server error"},
+	}
+}
@@ -0,0 +1,163 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func startBlockingOperations(ctx context.Context, t *testing.T, client *Client, n int) []OperationKey {
+	var keys []OperationKey
+	for i := 0; i < n; i++ {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+		require.NoError(t, err)
+		keys = append(keys, result.Pending.Key())
+	}
+	return keys
+}
+
+func blockingExecutor(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCancelOperations(t *testing.T) {
+	for _, useBatchRoute := range []bool{false, true} {
+		t.Run(map[bool]string{false: "Individual", true: "BatchRoute"}[useBatchRoute], func(t *testing.T) {
+			ctx, client, teardown := setupAsync(t, blockingExecutor)
+			defer teardown()
+
+			keys := startBlockingOperations(ctx, t, client, 5)
+			missing := OperationKey{Operation: "foo", ID: "missing"}
+			err := client.CancelOperations(ctx, append(keys, missing), CancelOperationsOptions{
+				MaxConcurrency: 2,
+				UseBatchRoute:  useBatchRoute,
+			})
+			var cancelErr *CancelOperationsError
+			require.ErrorAs(t, err, &cancelErr)
+			require.Len(t, cancelErr.Failures, 1)
+			require.Equal(t, missing, cancelErr.Failures[0].Operation)
+			if useBatchRoute {
+				var handlerError *HandlerError
+				require.ErrorAs(t, err, &handlerError)
+				require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+			} else {
+				var unexpectedResponseError *UnexpectedResponseError
+				require.ErrorAs(t, err, &unexpectedResponseError)
+				require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+			}
+
+			for _, key := range keys {
+				handle, err := client.NewHandle(key.Operation, key.ID)
+				require.NoError(t, err)
+				info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+				require.NoError(t, err)
+				require.Equal(t, OperationStateCanceled, info.State)
+			}
+
+			require.NoError(t, client.CancelOperations(ctx, keys, CancelOperationsOptions{UseBatchRoute: useBatchRoute}))
+		})
+	}
+}
+
+type concurrencyTrackingHandler struct {
+	UnimplementedHandler
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+func (h *concurrencyTrackingHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	current := h.current.Add(1)
+	defer h.current.Add(-1)
+	for {
+		max := h.max.Load()
+		if current <= max || h.max.CompareAndSwap(max, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestCancelOperations_MaxConcurrency(t *testing.T) {
+	handler := &concurrencyTrackingHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	var keys []OperationKey
+	for i := 0; i < 10; i++ {
+		keys = append(keys, OperationKey{Operation: "foo", ID: string(rune('a' + i))})
+	}
+	require.NoError(t, client.CancelOperations(ctx, keys, CancelOperationsOptions{MaxConcurrency: 3}))
+	require.LessOrEqual(t, handler.max.Load(), int32(3))
+	require.Greater(t, handler.max.Load(), int32(1))
+}
+
+func TestCancelOperations_BatchRouteAuthorization(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{Store: NewMemoryOperationStore(), Executor: blockingExecutor})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	denyBatch := false
+	var denied string
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: handler,
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if request.Method == HandlerMethodCancelOperations && denyBatch {
+				return HandlerErrorf(HandlerErrorTypeUnauthorized, "batch cancel not allowed")
+			}
+			if request.Method == HandlerMethodCancelOperation && request.OperationID == denied {
+				return HandlerErrorf(HandlerErrorTypeUnauthorized, "cancel not allowed")
+			}
+			return nil
+		}),
+	}, ClientOptions{})
+	defer teardown()
+
+	keys := startBlockingOperations(ctx, t, client, 3)
+	mu.Lock()
+	denied = keys[1].ID
+	mu.Unlock()
+	err = client.CancelOperations(ctx, keys, CancelOperationsOptions{UseBatchRoute: true})
+	var cancelErr *CancelOperationsError
+	require.ErrorAs(t, err, &cancelErr)
+	require.Len(t, cancelErr.Failures, 1)
+	require.Equal(t, keys[1], cancelErr.Failures[0].Operation)
+	var handlerError *HandlerError
+	require.ErrorAs(t, cancelErr.Failures[0].Err, &handlerError)
+	require.Equal(t, HandlerErrorTypeUnauthorized, handlerError.Type)
+	require.Equal(t, "cancel not allowed", handlerError.Failure.Message)
+
+	mu.Lock()
+	denyBatch = true
+	mu.Unlock()
+	err = client.CancelOperations(ctx, keys, CancelOperationsOptions{UseBatchRoute: true})
+	require.ErrorAs(t, err, &cancelErr)
+	require.Len(t, cancelErr.Failures, 3)
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, cancelErr.Failures[0].Err, &unexpectedResponseError)
+	require.Equal(t, http.StatusForbidden, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestCancelOperations_BatchRouteInvalidRequest(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, blockingExecutor)
+	defer teardown()
+
+	err := client.CancelOperations(ctx, []OperationKey{{Operation: "foo"}}, CancelOperationsOptions{UseBatchRoute: true})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerError.Type)
+
+	request, err := http.NewRequestWithContext(ctx, "POST", client.serviceBaseURL.JoinPath("_admin", "cancel", "batch").String(), nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
@@ -0,0 +1,101 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrResponseBodyTooLarge is returned by the client when a response body exceeds
+// [ClientOptions.MaxResponseBodySize].
+var ErrResponseBodyTooLarge = errors.New("response body too large")
+
+// limitRequestBody caps the request body at limit bytes. Requests declaring a larger body are failed with 413 without
+// reading their body and false is returned. Reading more than limit bytes from a body of unknown length fails with an
+// [http.MaxBytesError], which [baseHTTPHandler.writeFailure] translates to 413.
+func (h *baseHTTPHandler) limitRequestBody(writer http.ResponseWriter, request *http.Request, limit int64) bool {
+	if limit <= 0 {
+		return true
+	}
+	if request.ContentLength > limit {
+		// Don't bother reading the rest of the body.
+		writer.Header().Set("Connection", "close")
+		h.writeFailure(writer, &http.MaxBytesError{Limit: limit})
+		return false
+	}
+	request.Body = http.MaxBytesReader(writer, request.Body, limit)
+	return true
+}
+
+// requestBodyError returns the error to fail a request with when its body cannot be read, preserving the error of
+// bodies exceeding the handler's limit.
+func requestBodyError(err error, message string) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return maxBytesError
+	}
+	var integrityError *IntegrityError
+	if errors.As(err, &integrityError) {
+		return integrityError
+	}
+	return HandlerErrorf(HandlerErrorTypeBadRequest, message)
+}
+
+// limitingResponseHTTPCaller wraps caller to fail responses with bodies exceeding limit bytes with
+// [ErrResponseBodyTooLarge].
+func limitingResponseHTTPCaller(caller func(*http.Request) (*http.Response, error), limit int64) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		response, err := caller(request)
+		if err != nil {
+			return nil, err
+		}
+		if err := limitResponseBody(response, limit); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+}
+
+// limitResponseBody fails responses declaring a body larger than limit bytes before their body is read, closing the
+// body. Bodies of unknown length fail with [ErrResponseBodyTooLarge] once more than limit bytes are read.
+func limitResponseBody(response *http.Response, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	if response.ContentLength > limit {
+		response.Body.Close()
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrResponseBodyTooLarge, response.ContentLength, limit)
+	}
+	response.Body = &limitedReadCloser{ReadCloser: response.Body, limit: limit, remaining: limit}
+	return nil
+}
+
+// limitedReadCloser fails reads past a limit, unlike [io.LimitedReader] which silently truncates.
+type limitedReadCloser struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, r.err()
+	}
+	// Read one byte past the limit to detect bodies exceeding it.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) > r.remaining {
+		n = int(r.remaining)
+		r.remaining = -1
+		return n, r.err()
+	}
+	r.remaining -= int64(n)
+	return n, err
+}
+
+func (r *limitedReadCloser) err() error {
+	return fmt.Errorf("%w: exceeds the limit of %d bytes", ErrResponseBodyTooLarge, r.limit)
+}
@@ -0,0 +1,93 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type largeResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *largeResultHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: bytes.Repeat([]byte("x"), 1000)}, nil
+}
+
+func TestMaxRequestBodySize(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:            &echoHandler{},
+		MaxRequestBodySize: 100,
+	}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", []byte("small"), StartOperationOptions{Header: Header{"input-type": "content"}})
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Consume(new([]byte)))
+
+	_, err = client.StartOperation(ctx, "foo", bytes.Repeat([]byte("x"), 101), StartOperationOptions{Header: Header{"input-type": "content"}})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusRequestEntityTooLarge, unexpectedError.Response.StatusCode)
+	require.Equal(t, "request body too large, limit is 100 bytes", unexpectedError.Failure.Message)
+
+	// Bodies of unknown length are cut off while being read.
+	reader := &Reader{io.NopCloser(strings.NewReader(strings.Repeat("x", 101))), Header{"type": "application/octet-stream"}}
+	_, err = client.StartOperation(ctx, "foo", reader, StartOperationOptions{Header: Header{"input-type": "content"}})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusRequestEntityTooLarge, unexpectedError.Response.StatusCode)
+}
+
+func TestMaxResponseBodySize(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &largeResultHandler{},
+	}, ClientOptions{
+		MaxResponseBodySize: 100,
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, ErrResponseBodyTooLarge)
+}
+
+func TestLimitResponseBody_UnknownLength(t *testing.T) {
+	for _, size := range []int{99, 100, 101} {
+		response := &http.Response{
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader(strings.Repeat("x", size))),
+		}
+		require.NoError(t, limitResponseBody(response, 100))
+		data, err := io.ReadAll(response.Body)
+		if size > 100 {
+			require.ErrorIs(t, err, ErrResponseBodyTooLarge)
+			require.Len(t, data, 100)
+		} else {
+			require.NoError(t, err)
+			require.Len(t, data, size)
+		}
+	}
+}
+
+func TestCompletionMaxRequestBodySize(t *testing.T) {
+	handler := &recordingCompletionHandler{results: make(chan string, 1)}
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:            handler,
+		MaxRequestBodySize: 100,
+	}))
+	defer server.Close()
+
+	completion, err := NewOperationCompletionSuccessful(strings.Repeat("x", 200), OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+}
@@ -0,0 +1,75 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Buffers that grew beyond this size are not returned to the pool so that a few large values don't pin memory.
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool holds scratch buffers for serializing requests and responses and for reading bodies.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// encodeJSON appends the JSON encoding of v to buf, producing the same output as [json.Marshal] without copying it into
+// a newly allocated slice. Nothing is appended on error.
+func encodeJSON(buf *bytes.Buffer, v any) error {
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	// Strip the newline appended by Encode.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// readAll is like [io.ReadAll] but reads into a pooled buffer, allocating only the returned slice instead of every
+// slice io.ReadAll grows through while reading.
+func readAll(r io.Reader) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	_, err := buf.ReadFrom(r)
+	return append(make([]byte, 0, buf.Len()), buf.Bytes()...), err
+}
+
+var errBodyClosed = errors.New("read on closed body")
+
+// pooledBody is a request body reading a pooled buffer, which is returned to the pool once the body is closed. HTTP
+// transports may close request bodies concurrently with reading them, hence the mutex.
+type pooledBody struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (b *pooledBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf == nil {
+		return 0, errBodyClosed
+	}
+	return b.buf.Read(p)
+}
+
+func (b *pooledBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf != nil {
+		putBuffer(b.buf)
+		b.buf = nil
+	}
+	return nil
+}
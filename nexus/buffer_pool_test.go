@@ -0,0 +1,193 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	value := map[string]any{"html": "<a>", "n": 1}
+	expected, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	require.NoError(t, encodeJSON(buf, value))
+	require.Equal(t, expected, buf.Bytes())
+	require.Error(t, encodeJSON(buf, func() {}))
+	require.Equal(t, expected, buf.Bytes())
+}
+
+func TestDefaultSerializer_SerializeStream(t *testing.T) {
+	streaming := defaultSerializer.(StreamingSerializer)
+	for _, value := range []any{nil, []byte{1, 2}, "str", map[string]int{"a": 1}} {
+		content, err := defaultSerializer.Serialize(value)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		header, err := streaming.SerializeStream(&buf, value)
+		require.NoError(t, err)
+		require.Equal(t, content.Header, header)
+		require.Equal(t, len(content.Data), buf.Len())
+
+		// Writers other than buffers receive the same content.
+		var w struct{ bytes.Buffer }
+		_, err = streaming.SerializeStream(&w, value)
+		require.NoError(t, err)
+		require.Equal(t, buf.Bytes(), w.Bytes())
+	}
+}
+
+type jsonResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *jsonResultHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{map[string]string{"hello": "world"}}, nil
+}
+
+func TestWriteResult_PooledBuffer(t *testing.T) {
+	ctx, client, teardown := setup(t, &jsonResultHandler{})
+	defer teardown()
+
+	for i := 0; i < 3; i++ {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+		require.NoError(t, err)
+		reader := result.Successful.Reader
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, `{"hello":"world"}`, string(body))
+		require.Equal(t, strconv.Itoa(len(body)), reader.Header["length"])
+	}
+}
+
+func TestNewCompletionHTTPRequest_ContentLength(t *testing.T) {
+	ctx := context.Background()
+	completion, err := NewOperationCompletionSuccessful(map[string]string{"hello": "world"}, OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(ctx, "http://localhost/callback", completion)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(`{"hello":"world"}`)), request.ContentLength)
+
+	completion, err = NewOperationCompletionSuccessful(NewReader(io.MultiReader(), nil), OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err = NewCompletionHTTPRequest(ctx, "http://localhost/callback", completion)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), request.ContentLength)
+
+	request, err = NewCompletionHTTPRequest(ctx, "http://localhost/callback", &OperationCompletionUnsuccessful{
+		State:   OperationStateFailed,
+		Failure: &Failure{Message: "oops"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(`{"message":"oops"}`)), request.ContentLength)
+	require.Equal(t, strconv.FormatInt(request.ContentLength, 10), request.Header.Get("Content-Length"))
+}
+
+func TestReadAll(t *testing.T) {
+	data, err := readAll(strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, data)
+	require.Empty(t, data)
+
+	large := bytes.Repeat([]byte("a"), 2*maxPooledBufferSize)
+	data, err = readAll(bytes.NewReader(large))
+	require.NoError(t, err)
+	require.Equal(t, large, data)
+}
+
+func TestPooledBody(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("body")
+	body := &pooledBody{buf: buf}
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "body", string(data))
+	require.NoError(t, body.Close())
+	require.NoError(t, body.Close())
+	_, err = body.Read(make([]byte, 1))
+	require.ErrorIs(t, err, errBodyClosed)
+}
+
+type jsonEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *jsonEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var value map[string]any
+	if err := input.Consume(&value); err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{value}, nil
+}
+
+func BenchmarkStartOperationSync(b *testing.B) {
+	ctx, client, teardown := setup(b, &jsonEchoHandler{})
+	defer teardown()
+	input := map[string]any{"id": "order-1", "items": []string{"a", "b", "c"}, "total": 42.5}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result, err := client.StartOperation(ctx, "echo", input, StartOperationOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		var output map[string]any
+		if err := result.Successful.Consume(&output); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSerialize compares serializing into a new slice to serializing into a pooled buffer.
+func BenchmarkSerialize(b *testing.B) {
+	value := map[string]any{"id": "order-1", "items": bytes.Repeat([]byte("x"), 4096)}
+	b.Run("Serialize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := defaultSerializer.Serialize(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("SerializeStream", func(b *testing.B) {
+		streaming := defaultSerializer.(StreamingSerializer)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := getBuffer()
+			if _, err := streaming.SerializeStream(buf, value); err != nil {
+				b.Fatal(err)
+			}
+			putBuffer(buf)
+		}
+	})
+}
+
+// BenchmarkReadAll compares io.ReadAll to reading into a pooled buffer for bodies of unknown size.
+func BenchmarkReadAll(b *testing.B) {
+	data := bytes.Repeat([]byte("x"), 64*1024)
+	b.Run("io.ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadAll(io.LimitReader(bytes.NewReader(data), int64(len(data)))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("readAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := readAll(io.LimitReader(bytes.NewReader(data), int64(len(data)))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
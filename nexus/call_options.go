@@ -0,0 +1,206 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// A CallOption overrides settings of a [Client] for a single call, e.g. [WithHeader] or [WithTimeout]. Every method of
+// [Client] and [OperationHandle] that issues requests accepts call options after its method specific options, so
+// options that apply to all calls can be added without changing method signatures.
+//
+//	result, err := client.StartOperation(ctx, "charge", input, nexus.StartOperationOptions{},
+//		nexus.WithHeader(nexus.Header{"tenant": "acme"}),
+//		nexus.WithTimeout(5*time.Second),
+//	)
+type CallOption func(*callOptions)
+
+// callOptions are the settings of a call, carried in the context of its requests.
+type callOptions struct {
+	header      Header
+	timeout     time.Duration
+	retryPolicy *RetryPolicy
+	serializer  Serializer
+}
+
+// WithHeader adds header fields to every request of a call, taking precedence over [ClientOptions.Header]. Fields set
+// via the method specific options, e.g. [StartOperationOptions.Header], take precedence over the call's header. Header
+// fields of multiple WithHeader options are merged.
+func WithHeader(header Header) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(Header, len(header))
+		}
+		maps.Copy(o.header, header)
+	}
+}
+
+// WithTimeout bounds every request of a call, including reading its response body, to the given duration. The timeout
+// is propagated to the handler in the [HeaderRequestTimeout] header. Long polls, e.g. [OperationHandle.GetResult] with
+// a wait duration, are bounded too, set a timeout exceeding the wait duration for them.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetryPolicy retries requests of a call that fail with a transport error or are responded to with a 429 (Too
+// Many Requests) or a 5xx status other than 501 (Not Implemented), according to the given policy. A failed request is
+// represented as an [UnexpectedResponseError] when passed to [RetryPolicy.IsRetryable]. Retries of requests rejected
+// with a Retry-After header are delayed for at least the requested delay, measured by [ClientOptions.Clock].
+//
+// Start requests are retried with the same request ID, allowing handlers to dedupe them. Request bodies are buffered
+// in memory to be replayed.
+func WithRetryPolicy(policy RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+// WithSerializer overrides [ClientOptions.Serializer] for a call, including the results and handles returned by it.
+func WithSerializer(serializer Serializer) CallOption {
+	return func(o *callOptions) {
+		o.serializer = serializer
+	}
+}
+
+type callOptionsContextKey struct{}
+
+// withCallOptions returns a context carrying the given call options, merged with the call options already set on ctx,
+// and a client using the call's serializer.
+func (c *Client) withCallOptions(ctx context.Context, opts []CallOption) (context.Context, *Client) {
+	if len(opts) == 0 {
+		return ctx, c
+	}
+	var options callOptions
+	if existing, ok := ctx.Value(callOptionsContextKey{}).(*callOptions); ok {
+		options = *existing
+		options.header = maps.Clone(existing.header)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ctx = context.WithValue(ctx, callOptionsContextKey{}, &options)
+	if options.serializer == nil || options.serializer == c.options.Serializer {
+		return ctx, c
+	}
+	derived := *c
+	derived.options.Serializer = options.serializer
+	return ctx, &derived
+}
+
+// withCallOptions returns a context carrying the given call options and a handle using the call's serializer.
+func (h *OperationHandle[T]) withCallOptions(ctx context.Context, opts []CallOption) (context.Context, *OperationHandle[T]) {
+	ctx, client := h.client.withCallOptions(ctx, opts)
+	if client == h.client {
+		return ctx, h
+	}
+	derived := *h
+	derived.client = client
+	return ctx, &derived
+}
+
+// callOptionsHTTPCaller wraps caller to apply the call options set on the context of every request.
+func callOptionsHTTPCaller(caller func(*http.Request) (*http.Response, error), clock Clock, engine JSONEngine) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		options, ok := request.Context().Value(callOptionsContextKey{}).(*callOptions)
+		if !ok {
+			return caller(request)
+		}
+		for k, v := range options.header {
+			if request.Header.Get(k) == "" {
+				request.Header.Set(k, v)
+			}
+		}
+		if options.timeout > 0 {
+			caller = timeoutHTTPCaller(caller, options.timeout)
+		}
+		if options.retryPolicy != nil {
+			return retryHTTPRequest(caller, request, *options.retryPolicy, clock, engine)
+		}
+		return caller(request)
+	}
+}
+
+// timeoutHTTPCaller wraps caller to cancel every request after timeout, or once its response body is closed.
+func timeoutHTTPCaller(caller func(*http.Request) (*http.Response, error), timeout time.Duration) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		request = request.Clone(ctx)
+		addContextTimeoutToHTTPHeader(ctx, request.Header)
+		response, err := caller(request)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		response.Body = &cancelingReadCloser{response.Body, cancel}
+		return response, nil
+	}
+}
+
+// retryableStatus reports whether a request responded to with the given status may succeed when retried.
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode >= http.StatusInternalServerError && statusCode != http.StatusNotImplemented
+}
+
+// retryHTTPRequest sends a request via caller, retrying failed attempts according to policy. Returns the outcome of the
+// last attempt. Failures of attempts are decoded with engine.
+func retryHTTPRequest(caller func(*http.Request) (*http.Response, error), request *http.Request, policy RetryPolicy, clock Clock, engine JSONEngine) (*http.Response, error) {
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		body, err := io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		request.Body, _ = request.GetBody()
+	}
+	ctx := request.Context()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request = request.Clone(ctx)
+			request.Body = body
+		}
+		response, err := caller(request)
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return response, err
+		}
+		attemptErr := err
+		if err == nil {
+			if !retryableStatus(response.StatusCode) {
+				return response, nil
+			}
+			body, err := readAndReplaceBody(response)
+			if err != nil {
+				return nil, err
+			}
+			attemptErr = newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, engine)
+		}
+		if !policy.retryable(attemptErr) {
+			return response, err
+		}
+		delay := policy.interval(attempt)
+		if retryAfter, ok := RetryAfter(attemptErr); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+		timer := clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return response, err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStartHandler rejects the first failures start requests as unavailable and echoes the input of the others,
+// recording the request ID and input of every request.
+type flakyStartHandler struct {
+	UnimplementedHandler
+	failures int
+
+	mu         sync.Mutex
+	requestIDs []string
+	inputs     []string
+}
+
+func (h *flakyStartHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var s string
+	if err := input.Consume(&s); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requestIDs = append(h.requestIDs, options.RequestID)
+	h.inputs = append(h.inputs, s)
+	if len(h.requestIDs) <= h.failures {
+		return nil, HandlerErrorf(HandlerErrorTypeUnavailable, "overloaded")
+	}
+	return &HandlerStartOperationResultSync[any]{Value: s}, nil
+}
+
+func TestWithHeader(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: tenantHandler(t)}, ClientOptions{Header: Header{"tenant": "a"}})
+	defer teardown()
+	ref := NewOperationReference[NoValue, string]("foo")
+
+	result, err := StartOperation(ctx, client, ref, nil, StartOperationOptions{}, WithHeader(Header{"tenant": "b"}))
+	require.NoError(t, err)
+	tenant, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "b", tenant)
+
+	// The method specific header takes precedence.
+	result, err = StartOperation(ctx, client, ref, nil, StartOperationOptions{Header: Header{"tenant": "c"}}, WithHeader(Header{"tenant": "b"}))
+	require.NoError(t, err)
+	tenant, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "c", tenant)
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: tenantHandler(t)}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "block", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute}, WithTimeout(100*time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// The timeout does not apply to subsequent calls.
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	handler := &flakyStartHandler{failures: 2}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", "input", StartOperationOptions{},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, result.Successful.Consume(&s))
+	require.Equal(t, "input", s)
+	require.Equal(t, []string{"input", "input", "input"}, handler.inputs)
+	require.Len(t, handler.requestIDs, 3)
+	require.Equal(t, handler.requestIDs[0], handler.requestIDs[1])
+	require.Equal(t, handler.requestIDs[0], handler.requestIDs[2])
+}
+
+func TestWithRetryPolicy_Exhausted(t *testing.T) {
+	handler := &flakyStartHandler{failures: 3}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", "input", StartOperationOptions{},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond}))
+	var unexpectedErr *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusServiceUnavailable, unexpectedErr.Response.StatusCode)
+	require.Len(t, handler.requestIDs, 2)
+
+	handler = &flakyStartHandler{failures: 1}
+	ctx, client, teardown = setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+	_, err = client.StartOperation(ctx, "foo", "input", StartOperationOptions{},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, IsRetryable: func(err error) bool {
+			var unexpectedErr *UnexpectedResponseError
+			return !errors.As(err, &unexpectedErr)
+		}}))
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Len(t, handler.requestIDs, 1)
+}
+
+func TestWithSerializer(t *testing.T) {
+	registry := OperationRegistry{}
+	require.NoError(t, registry.Register(numberValidatorOperation, asyncNumberValidatorOperationInstance))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	c := &customSerializer{}
+	ctx, client, teardown := setupSerializer(t, handler, c)
+	defer teardown()
+
+	// The client's serializer is replaced by the call's serializer.
+	client, err = client.Clone(ClientOverrides{Serializer: defaultSerializer})
+	require.NoError(t, err)
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{}, WithSerializer(c))
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	// Handles returned by the call use the call's serializer.
+	start, err := StartOperation(ctx, client, asyncNumberValidatorOperationInstance, 3, StartOperationOptions{}, WithSerializer(c))
+	require.NoError(t, err)
+	result, err = start.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	// The handler shares the serializer, encoding and decoding once per operation as well.
+	require.Equal(t, 4, c.encoded)
+	require.Equal(t, 4, c.decoded)
+}
@@ -0,0 +1,316 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default maximum number of attempts to deliver a completion to a callback.
+const defaultCallbackDeliveryAttempts = 5
+
+// Default timeout of a single attempt to deliver a completion to a callback.
+const defaultCallbackDeliveryAttemptTimeout = 30 * time.Second
+
+// CallbackDeliveryOptions configure delivery of operation completions to callbacks, see
+// [AsyncHandlerOptions.CallbackDelivery].
+type CallbackDeliveryOptions struct {
+	// A function for making completion HTTP requests.
+	// Defaults to http.DefaultClient.Do.
+	HTTPCaller func(*http.Request) (*http.Response, error)
+	// Retry policy for failed deliveries, applied to every callback independently. A delivery attempt fails if the
+	// request fails or is responded to with a non 2xx status, in which case the error is an
+	// [UnexpectedResponseError]. MaxAttempts defaults to 5. Retries of deliveries rejected with a 429 or 503 status
+	// and a Retry-After header are delayed for at least the requested delay.
+	RetryPolicy RetryPolicy
+	// Timeout of a single delivery attempt. Defaults to 30 seconds.
+	AttemptTimeout time.Duration
+	// Optional signer for completion requests, see [SignCompletionHTTPRequest].
+	Signer CompletionSigner
+	// Identity of the handler sent with completion requests in the [HeaderCallerIdentity] header, see
+	// [ClientOptions.CallerIdentity].
+	CallerIdentity string
+	// Optional provider of the identity sent with completion requests. Takes precedence over CallerIdentity.
+	CallerIdentityProvider CallerIdentityProvider
+	// Optional converter for encoding failures of unsuccessful operations before they are delivered, see
+	// [FailureConverter].
+	FailureConverter FailureConverter
+	// Optional algorithm of checksums of delivered results, see [OperationCompletionSuccesfulOptions.Checksum].
+	Checksum ChecksumAlgorithm
+	// Optional encoding of delivered completions as CloudEvents, see [EncodeCompletionCloudEvent].
+	CloudEvents *CloudEventsOptions
+	// Optional transports for delivering completions other than over HTTP, keyed by the callback URL scheme they
+	// handle, e.g. "sqs" for callbacks such as "sqs://sqs.us-east-1.amazonaws.com/123456789012/completions".
+	// Completions are encoded as a [CompletionMessage] with the same header fields, including signatures, as the
+	// equivalent completion request. Callbacks with other schemes are delivered via HTTPCaller.
+	Transports map[string]CompletionTransport
+}
+
+// CallbackDeliveryState is the state of the delivery of an operation's completion to a callback.
+type CallbackDeliveryState string
+
+const (
+	// The completion has not been delivered yet, either because the operation is running or because delivery is in
+	// progress.
+	CallbackDeliveryStatePending = CallbackDeliveryState("pending")
+	// The completion was delivered.
+	CallbackDeliveryStateSucceeded = CallbackDeliveryState("succeeded")
+	// Delivery of the completion failed and retries are exhausted.
+	CallbackDeliveryStateFailed = CallbackDeliveryState("failed")
+)
+
+// CallbackRecord is the delivery state of a callback of an operation.
+type CallbackRecord struct {
+	Callback
+	// State of the delivery.
+	State CallbackDeliveryState `json:"state"`
+	// Number of delivery attempts made.
+	Attempts int `json:"attempts,omitempty"`
+	// Error of the last failed delivery attempt, if any.
+	LastFailure string `json:"lastFailure,omitempty"`
+}
+
+func newCallbackRecords(callbacks []Callback) []CallbackRecord {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	records := make([]CallbackRecord, len(callbacks))
+	for i, callback := range callbacks {
+		records[i] = CallbackRecord{Callback: callback, State: CallbackDeliveryStatePending}
+	}
+	return records
+}
+
+// AddCallback registers an additional callback the completion of an operation is delivered to, e.g. on behalf of a
+// downstream system that did not start the operation. If the operation has already completed, delivery starts
+// immediately. Requires [AsyncHandlerOptions.CallbackDelivery].
+func (h *AsyncHandler) AddCallback(ctx context.Context, operation, operationID string, callback Callback) error {
+	if h.options.CallbackDelivery == nil {
+		return errors.New("callback delivery is not enabled")
+	}
+	if callback.URL == "" {
+		return errors.New("callback URL is empty")
+	}
+	for {
+		record, err := h.getRecord(ctx, operation, operationID)
+		if err != nil {
+			return err
+		}
+		record.Callbacks = append(record.Callbacks, CallbackRecord{Callback: callback, State: CallbackDeliveryStatePending})
+		record.UpdatedAt = time.Now()
+		err = h.options.Store.Update(ctx, record)
+		if errors.Is(err, ErrOperationRecordVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if record.State != OperationStateRunning {
+			// The operation completed before the callback was added, deliver to it here.
+			go h.deliverCallback(record, len(record.Callbacks)-1)
+		}
+		return nil
+	}
+}
+
+// deliverCallbacks starts delivering the completion of an operation to each of its pending callbacks.
+func (h *AsyncHandler) deliverCallbacks(record *OperationRecord) {
+	if h.options.CallbackDelivery == nil {
+		return
+	}
+	for i, callback := range record.Callbacks {
+		if callback.State == CallbackDeliveryStatePending {
+			go h.deliverCallback(record, i)
+		}
+	}
+}
+
+// deliverCallback delivers the completion of an operation to one of its callbacks, retrying failed attempts
+// according to the delivery retry policy and recording the outcome of every attempt in the operation's record.
+func (h *AsyncHandler) deliverCallback(record *OperationRecord, index int) {
+	options := h.options.CallbackDelivery
+	policy := options.RetryPolicy
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = defaultCallbackDeliveryAttempts
+	}
+	callback := record.Callbacks[index]
+	for attempt := 1; ; attempt++ {
+		err := h.attemptCallbackDelivery(record, callback.Callback)
+		state := CallbackDeliveryStateSucceeded
+		if err != nil {
+			state = CallbackDeliveryStatePending
+			if attempt >= policy.MaxAttempts || !policy.retryable(err) {
+				state = CallbackDeliveryStateFailed
+			}
+		}
+		if updateErr := h.updateCallback(record.Operation, record.ID, index, func(c *CallbackRecord) {
+			c.State = state
+			c.Attempts = attempt
+			if err != nil {
+				c.LastFailure = err.Error()
+			}
+		}); updateErr != nil {
+			h.options.Logger.Error("failed to record callback delivery attempt", "operation", record.Operation, "operationID", record.ID, "error", updateErr)
+		}
+		switch state {
+		case CallbackDeliveryStateSucceeded:
+			return
+		case CallbackDeliveryStateFailed:
+			h.options.Logger.Error("failed to deliver operation completion", "operation", record.Operation, "operationID", record.ID, "url", callback.URL, "attempts", attempt, "error", err)
+			return
+		}
+		h.options.Logger.Warn("operation completion delivery failed, retrying", "operation", record.Operation, "operationID", record.ID, "url", callback.URL, "attempt", attempt, "error", err)
+		delay := policy.interval(attempt)
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+		time.Sleep(delay)
+	}
+}
+
+// attemptCallbackDelivery sends a single completion request for a completed operation to a callback, or publishes it
+// via the transport for the callback's URL scheme.
+func (h *AsyncHandler) attemptCallbackDelivery(record *OperationRecord, callback Callback) error {
+	timeout := h.options.CallbackDelivery.AttemptTimeout
+	if timeout <= 0 {
+		timeout = defaultCallbackDeliveryAttemptTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	completion, err := h.completionFromRecord(ctx, record)
+	if err != nil {
+		return err
+	}
+	request, err := NewCompletionHTTPRequest(ctx, callback.URL, completion)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(HeaderRequestID, callbackDeliveryRequestID(record, callback))
+	for k, v := range callback.Header {
+		request.Header.Set(k, v)
+	}
+	if cloudEvents := h.options.CallbackDelivery.CloudEvents; cloudEvents != nil {
+		if err := EncodeCompletionCloudEvent(request, *cloudEvents); err != nil {
+			return err
+		}
+	}
+	if provider := callerIdentityProvider(h.options.CallbackDelivery.CallerIdentity, h.options.CallbackDelivery.CallerIdentityProvider); provider != nil {
+		if err := setCallerIdentityHTTPHeader(request, provider); err != nil {
+			return err
+		}
+	}
+	transport, ok := h.options.CallbackDelivery.Transports[request.URL.Scheme]
+	if ok {
+		// Messages carry no URL, sign the request as it is reconstructed by the receiving CompletionMessageHandler.
+		request.URL = completionMessageURL()
+	}
+	if signer := h.options.CallbackDelivery.Signer; signer != nil {
+		if err := SignCompletionHTTPRequest(request, signer); err != nil {
+			return err
+		}
+	}
+	if ok {
+		message, err := completionMessageFromHTTPRequest(request)
+		if err != nil {
+			return err
+		}
+		return transport.DeliverCompletion(ctx, callback, message)
+	}
+	httpCaller := h.options.CallbackDelivery.HTTPCaller
+	if httpCaller == nil {
+		httpCaller = http.DefaultClient.Do
+	}
+	response, err := httpCaller(request)
+	if err != nil {
+		return err
+	}
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.options.JSON)
+	}
+	return nil
+}
+
+// callbackDeliveryRequestID returns the request ID of deliveries of an operation's completion to a callback, which is
+// identical for every attempt, allowing receivers to dedupe retried deliveries.
+func callbackDeliveryRequestID(record *OperationRecord, callback Callback) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(joinKey(record.Operation, record.ID, callback.URL))).String()
+}
+
+// completionFromRecord creates the completion of a completed operation. A new completion is created for every
+// delivery attempt since its body is consumed when sent.
+func (h *AsyncHandler) completionFromRecord(ctx context.Context, record *OperationRecord) (OperationCompletion, error) {
+	if record.State != OperationStateSucceeded {
+		return &OperationCompletionUnsuccessful{
+			OperationID:      record.ID,
+			State:            record.State,
+			Failure:          record.Failure,
+			FailureConverter: h.options.CallbackDelivery.FailureConverter,
+			JSON:             h.options.JSON,
+		}, nil
+	}
+	var result any
+	if record.ResultPayloadKey != "" {
+		if h.options.PayloadBackend == nil {
+			return nil, errors.New("operation result stored in payload backend but no backend configured")
+		}
+		reader, err := h.options.PayloadBackend.Get(ctx, record.ResultPayloadKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get operation result payload: %w", err)
+		}
+		result = reader
+	} else if record.Result != nil {
+		result = record.Result
+	}
+	completion, err := NewOperationCompletionSuccessful(result, OperationCompletionSuccesfulOptions{
+		Serializer: h.options.Serializer,
+		Checksum:   h.options.CallbackDelivery.Checksum,
+	})
+	if err != nil {
+		return nil, err
+	}
+	completion.OperationID = record.ID
+	return completion, nil
+}
+
+// updateCallback applies the given update to the record of an operation's callback, retrying on version conflicts.
+func (h *AsyncHandler) updateCallback(operation, operationID string, index int, update func(*CallbackRecord)) error {
+	ctx := context.Background()
+	for {
+		record, err := h.options.Store.Get(ctx, operation, operationID)
+		if err != nil {
+			return err
+		}
+		if index >= len(record.Callbacks) {
+			return fmt.Errorf("callback %d not found", index)
+		}
+		update(&record.Callbacks[index])
+		record.UpdatedAt = time.Now()
+		err = h.options.Store.Update(ctx, record)
+		if errors.Is(err, ErrOperationRecordVersionConflict) {
+			continue
+		}
+		return err
+	}
+}
+
+func cloneCallbackRecords(callbacks []CallbackRecord) []CallbackRecord {
+	if callbacks == nil {
+		return nil
+	}
+	c := make([]CallbackRecord, len(callbacks))
+	for i, callback := range callbacks {
+		callback.Header = maps.Clone(callback.Header)
+		c[i] = callback
+	}
+	return c
+}
@@ -0,0 +1,243 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type receivedCompletion struct {
+	state  OperationState
+	header http.Header
+	body   string
+}
+
+// callbackRecorder is a callback endpoint that records the completions delivered to it, failing the first failures
+// deliveries.
+type callbackRecorder struct {
+	mu          sync.Mutex
+	failures    int
+	attempts    int
+	completions []receivedCompletion
+}
+
+func (r *callbackRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.attempts <= r.failures {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	r.completions = append(r.completions, receivedCompletion{
+		state:  OperationState(request.Header.Get(HeaderOperationState)),
+		header: request.Header,
+		body:   string(body),
+	})
+}
+
+func (r *callbackRecorder) received() []receivedCompletion {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedCompletion(nil), r.completions...)
+}
+
+func newCallbackServer(t *testing.T, failures int) (*callbackRecorder, string) {
+	recorder := &callbackRecorder{failures: failures}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	return recorder, server.URL + "/callback"
+}
+
+func setupCallbackDelivery(t *testing.T, executor AsyncExecutor, policy RetryPolicy) (context.Context, *Client, *AsyncHandler, OperationStore) {
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:            store,
+		Executor:         executor,
+		CallbackDelivery: &CallbackDeliveryOptions{RetryPolicy: policy},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	t.Cleanup(teardown)
+	return ctx, client, handler, store
+}
+
+func waitForCallbacks(ctx context.Context, t *testing.T, store OperationStore, key OperationKey, states ...CallbackDeliveryState) []CallbackRecord {
+	var callbacks []CallbackRecord
+	require.Eventually(t, func() bool {
+		record, err := store.Get(ctx, key.Operation, key.ID)
+		require.NoError(t, err)
+		callbacks = record.Callbacks
+		if len(callbacks) != len(states) {
+			return false
+		}
+		for i, state := range states {
+			if callbacks[i].State != state {
+				return false
+			}
+		}
+		return true
+	}, testTimeout, 10*time.Millisecond)
+	return callbacks
+}
+
+func TestCallbackDelivery_FanOut(t *testing.T) {
+	ctx, client, _, store := setupCallbackDelivery(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return "done", nil
+	}, RetryPolicy{InitialInterval: time.Millisecond})
+
+	primary, primaryURL := newCallbackServer(t, 0)
+	flaky, flakyURL := newCallbackServer(t, 2)
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		CallbackURL:    primaryURL,
+		CallbackHeader: Header{"who": "primary"},
+		AdditionalCallbacks: []Callback{
+			{URL: flakyURL, Header: Header{"who": "flaky"}},
+		},
+	})
+	require.NoError(t, err)
+
+	callbacks := waitForCallbacks(ctx, t, store, result.Pending.Key(), CallbackDeliveryStateSucceeded, CallbackDeliveryStateSucceeded)
+	require.Equal(t, primaryURL, callbacks[0].URL)
+	require.Equal(t, 1, callbacks[0].Attempts)
+	require.Empty(t, callbacks[0].LastFailure)
+	require.Equal(t, Header{"who": "flaky"}, callbacks[1].Header)
+	require.Equal(t, 3, callbacks[1].Attempts)
+	require.Contains(t, callbacks[1].LastFailure, "503")
+	record, err := store.Get(ctx, "foo", result.Pending.ID)
+	require.NoError(t, err)
+
+	for recorder, who := range map[*callbackRecorder]string{primary: "primary", flaky: "flaky"} {
+		received := recorder.received()
+		require.Len(t, received, 1)
+		require.Equal(t, OperationStateSucceeded, received[0].state)
+		require.Equal(t, who, received[0].header.Get("who"))
+		require.Equal(t, `"done"`, received[0].body)
+		require.Equal(t, result.Pending.ID, received[0].header.Get(HeaderOperationID))
+	}
+	// Retried deliveries carry the same request ID, distinct per callback.
+	require.Equal(t, callbackDeliveryRequestID(record, callbacks[1].Callback), flaky.received()[0].header.Get(HeaderRequestID))
+	require.NotEqual(t, primary.received()[0].header.Get(HeaderRequestID), flaky.received()[0].header.Get(HeaderRequestID))
+}
+
+func TestCallbackDelivery_RetriesExhausted(t *testing.T) {
+	ctx, client, _, store := setupCallbackDelivery(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, &UnsuccessfulOperationError{State: OperationStateFailed, Failure: Failure{Message: "boom"}}
+	}, RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond})
+
+	down, downURL := newCallbackServer(t, 10)
+	up, upURL := newCallbackServer(t, 0)
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		AdditionalCallbacks: []Callback{{URL: downURL}, {URL: upURL}},
+	})
+	require.NoError(t, err)
+
+	callbacks := waitForCallbacks(ctx, t, store, result.Pending.Key(), CallbackDeliveryStateFailed, CallbackDeliveryStateSucceeded)
+	require.Equal(t, 2, callbacks[0].Attempts)
+	require.Empty(t, down.received())
+	received := up.received()
+	require.Len(t, received, 1)
+	require.Equal(t, OperationStateFailed, received[0].state)
+	require.JSONEq(t, `{"message":"boom"}`, received[0].body)
+}
+
+func TestCallbackDelivery_AddCallback(t *testing.T) {
+	ctx, client, handler, store := setupCallbackDelivery(t, blockingExecutor, RetryPolicy{})
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	key := result.Pending.Key()
+
+	before, beforeURL := newCallbackServer(t, 0)
+	require.NoError(t, handler.AddCallback(ctx, key.Operation, key.ID, Callback{URL: beforeURL}))
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	waitForCallbacks(ctx, t, store, key, CallbackDeliveryStateSucceeded)
+
+	// Callbacks added after completion are delivered to immediately.
+	after, afterURL := newCallbackServer(t, 0)
+	require.NoError(t, handler.AddCallback(ctx, key.Operation, key.ID, Callback{URL: afterURL}))
+	waitForCallbacks(ctx, t, store, key, CallbackDeliveryStateSucceeded, CallbackDeliveryStateSucceeded)
+
+	for _, recorder := range []*callbackRecorder{before, after} {
+		received := recorder.received()
+		require.Len(t, received, 1)
+		require.Equal(t, OperationStateCanceled, received[0].state)
+	}
+
+	err = handler.AddCallback(ctx, key.Operation, "missing", Callback{URL: afterURL})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+}
+
+func TestCallbackDelivery_Disabled(t *testing.T) {
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{Store: store, Executor: blockingExecutor})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{CallbackURL: "http://localhost/callback"})
+	require.NoError(t, err)
+	key := result.Pending.Key()
+	record, err := store.Get(ctx, key.Operation, key.ID)
+	require.NoError(t, err)
+	require.Empty(t, record.Callbacks)
+	require.Error(t, handler.AddCallback(ctx, key.Operation, key.ID, Callback{URL: "http://localhost/callback"}))
+}
+
+type callbacksHandler struct {
+	UnimplementedHandler
+	callbacks chan []Callback
+}
+
+func (h *callbacksHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.callbacks <- options.Callbacks()
+	if _, ok := options.Header["nexus-additional-callbacks"]; ok {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "additional callbacks leaked into header")
+	}
+	return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+}
+
+func TestStartOperation_AdditionalCallbacks(t *testing.T) {
+	handler := &callbacksHandler{callbacks: make(chan []Callback, 1)}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		CallbackURL:    "http://localhost/a",
+		CallbackHeader: Header{"k": "a"},
+		AdditionalCallbacks: []Callback{
+			{URL: "http://localhost/b", Header: Header{"k": "b"}},
+			{URL: "http://localhost/c"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Callback{
+		{URL: "http://localhost/a", Header: Header{"k": "a"}},
+		{URL: "http://localhost/b", Header: Header{"k": "b"}},
+		{URL: "http://localhost/c"},
+	}, <-handler.callbacks)
+
+	request, err := http.NewRequestWithContext(ctx, "POST", client.serviceBaseURL.JoinPath("foo").String(), nil)
+	require.NoError(t, err)
+	request.Header.Set(HeaderAdditionalCallbacks, `[{"header":{"k":"v"}}]`)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestCallbackDeliveryRequestID(t *testing.T) {
+	callback := Callback{URL: "http://localhost/callback"}
+	first := callbackDeliveryRequestID(&OperationRecord{Operation: "a/b", ID: "c"}, callback)
+	require.Equal(t, first, callbackDeliveryRequestID(&OperationRecord{Operation: "a/b", ID: "c"}, callback))
+	require.NotEqual(t, first, callbackDeliveryRequestID(&OperationRecord{Operation: "a", ID: "b/c"}, callback))
+}
@@ -0,0 +1,180 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A CallbackURLValidator validates the callback URLs of start requests before they are dispatched to the [Handler],
+// see [HandlerOptions.CallbackURLValidator].
+//
+// Implementations must be safe for concurrent use.
+type CallbackURLValidator interface {
+	// ValidateCallbackURL returns an error if the handler must not deliver completions to the given URL. The error
+	// message is returned to the caller.
+	ValidateCallbackURL(ctx context.Context, u *url.URL) error
+}
+
+// CallbackURLValidatorFunc is a [CallbackURLValidator] backed by a function.
+type CallbackURLValidatorFunc func(ctx context.Context, u *url.URL) error
+
+// ValidateCallbackURL implements CallbackURLValidator.
+func (f CallbackURLValidatorFunc) ValidateCallbackURL(ctx context.Context, u *url.URL) error {
+	return f(ctx, u)
+}
+
+// Hosts of cloud metadata endpoints that are not link-local addresses.
+var metadataHosts = []string{"metadata.google.internal", "metadata.goog"}
+
+// CallbackURLPolicy is a [CallbackURLValidator] that validates callback URLs against allowed schemes, hosts and ports.
+//
+// Hosts that are link-local addresses, which include the metadata endpoints of cloud providers such as
+// 169.254.169.254, and well-known metadata host names are always rejected.
+//
+// Host names are validated as given and not resolved, applications must also restrict the addresses completions are
+// delivered to, e.g. via the dialer of the HTTP client, to protect against host names resolving to internal addresses.
+type CallbackURLPolicy struct {
+	// Allowed URL schemes. Defaults to http and https.
+	AllowedSchemes []string
+	// Allowed hosts. A pattern with a leading "*." matches all subdomains of the domain that follows, e.g.
+	// "*.example.com" matches "api.example.com" but not "example.com". When empty, all hosts that are not denied are
+	// allowed.
+	AllowedHosts []string
+	// Denied hosts, in the same format as AllowedHosts. Denied hosts take precedence over allowed hosts.
+	DeniedHosts []string
+	// Allowed ports. When empty, all ports are allowed. URLs without a port are matched against the default port of
+	// their scheme.
+	AllowedPorts []int
+	// Allow hosts that are loopback, private or unspecified IP addresses and "localhost", which are rejected by
+	// default.
+	AllowPrivateAddresses bool
+}
+
+// ValidateCallbackURL implements CallbackURLValidator.
+func (p *CallbackURLPolicy) ValidateCallbackURL(ctx context.Context, u *url.URL) error {
+	schemes := p.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !slices.ContainsFunc(schemes, func(scheme string) bool { return strings.EqualFold(scheme, u.Scheme) }) {
+		return fmt.Errorf("callback URL scheme %q is not allowed", u.Scheme)
+	}
+	host := normalizeCallbackHost(u.Hostname())
+	if host == "" {
+		return errors.New("callback URL has no host")
+	}
+	if err := validateCallbackHostAddress(host); err != nil {
+		return err
+	}
+	if !p.AllowPrivateAddresses && isPrivateHost(host) {
+		return fmt.Errorf("callback URL host %q is a private address", host)
+	}
+	if matchesAnyHost(p.DeniedHosts, host) {
+		return fmt.Errorf("callback URL host %q is denied", host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchesAnyHost(p.AllowedHosts, host) {
+		return fmt.Errorf("callback URL host %q is not allowed", host)
+	}
+	if len(p.AllowedPorts) > 0 {
+		port, err := callbackURLPort(u)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(p.AllowedPorts, port) {
+			return fmt.Errorf("callback URL port %d is not allowed", port)
+		}
+	}
+	return nil
+}
+
+// defaultCallbackURLValidator rejects callback URLs whose host is a link-local address or metadata endpoint, see
+// [HandlerOptions.CallbackURLValidator].
+var defaultCallbackURLValidator = CallbackURLValidatorFunc(func(ctx context.Context, u *url.URL) error {
+	return validateCallbackHostAddress(normalizeCallbackHost(u.Hostname()))
+})
+
+// normalizeCallbackHost lower-cases a host and strips the trailing dot of fully qualified domain names, which resolve
+// to the same addresses as their relative forms, e.g. "localhost." and "localhost".
+func normalizeCallbackHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// validateCallbackHostAddress rejects hosts that are link-local addresses or well-known metadata endpoints. The host must
+// be normalized with normalizeCallbackHost.
+func validateCallbackHostAddress(host string) error {
+	if slices.Contains(metadataHosts, host) {
+		return fmt.Errorf("callback URL host %q is a metadata endpoint", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		// fd00:ec2::254 is the IPv6 metadata endpoint of EC2.
+		if addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr == netip.MustParseAddr("fd00:ec2::254") {
+			return fmt.Errorf("callback URL host %q is a link-local address", host)
+		}
+	}
+	return nil
+}
+
+func isPrivateHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified()
+}
+
+func matchesAnyHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = normalizeCallbackHost(pattern)
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if pattern == host {
+			return true
+		}
+	}
+	return false
+}
+
+func callbackURLPort(u *url.URL) (int, error) {
+	if port := u.Port(); port != "" {
+		return strconv.Atoi(port)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return 80, nil
+	case "https":
+		return 443, nil
+	}
+	return 0, fmt.Errorf("callback URL has no port and scheme %q has no default port", u.Scheme)
+}
+
+// validateCallbackURLs validates the callback URLs of a start request, returning a bad request [HandlerError] for
+// invalid URLs.
+func (h *httpHandler) validateCallbackURLs(ctx context.Context, callbacks []Callback) error {
+	validator := h.options.CallbackURLValidator
+	if validator == nil {
+		validator = defaultCallbackURLValidator
+	}
+	for _, callback := range callbacks {
+		u, err := url.Parse(callback.URL)
+		if err != nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid callback URL")
+		}
+		if err := validator.ValidateCallbackURL(ctx, u); err != nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid callback URL: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackURLPolicy(t *testing.T) {
+	policy := &CallbackURLPolicy{
+		AllowedHosts: []string{"*.example.com", "callbacks.test", "10.0.0.1"},
+		DeniedHosts:  []string{"internal.example.com"},
+		AllowedPorts: []int{443, 8443},
+	}
+	cases := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://api.example.com/callback", true},
+		{"https://API.Example.com:8443/callback", true},
+		{"https://callbacks.test", true},
+		{"https://api.example.com.", true},
+		{"https://callbacks.test.:443", true},
+		{"https://example.com", false},
+		{"https://internal.example.com", false},
+		{"https://internal.example.com.", false},
+		{"https://INTERNAL.example.com.:443", false},
+		{"https://api.example.com:8080", false},
+		{"http://api.example.com", false},
+		{"ftp://api.example.com:443", false},
+		{"https://10.0.0.1", false},
+		{"https://169.254.169.254", false},
+		{"https:///callback", false},
+		{"https://./callback", false},
+	}
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			u, err := url.Parse(c.url)
+			require.NoError(t, err)
+			err = policy.ValidateCallbackURL(context.Background(), u)
+			if c.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCallbackURLPolicy_PrivateAddresses(t *testing.T) {
+	for _, host := range []string{"localhost", "localhost.", "LocalHost.", "app.localhost.", "127.0.0.1", "[::1]", "10.1.2.3", "192.168.0.1", "0.0.0.0", "[::ffff:127.0.0.1]"} {
+		u, err := url.Parse("http://" + host + "/callback")
+		require.NoError(t, err)
+		require.Error(t, (&CallbackURLPolicy{}).ValidateCallbackURL(context.Background(), u), host)
+		require.NoError(t, (&CallbackURLPolicy{AllowPrivateAddresses: true}).ValidateCallbackURL(context.Background(), u), host)
+	}
+}
+
+func TestDefaultCallbackURLValidator(t *testing.T) {
+	for _, rawURL := range []string{"http://169.254.169.254/latest/meta-data", "http://[fe80::1]/", "http://[fd00:ec2::254]/", "http://metadata.google.internal/", "http://metadata.google.internal./", "http://Metadata.Goog./"} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		require.Error(t, defaultCallbackURLValidator.ValidateCallbackURL(context.Background(), u), rawURL)
+	}
+	for _, rawURL := range []string{"http://localhost:1234/callback", "https://example.com", "custom://system"} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		require.NoError(t, defaultCallbackURLValidator.ValidateCallbackURL(context.Background(), u), rawURL)
+	}
+}
+
+func TestStartOperation_CallbackURLValidation(t *testing.T) {
+	handler := &callbacksHandler{callbacks: make(chan []Callback, 1)}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:              handler,
+		CallbackURLValidator: &CallbackURLPolicy{AllowedHosts: []string{"callbacks.example.com"}},
+	}, ClientOptions{})
+	defer teardown()
+
+	cases := []StartOperationOptions{
+		{CallbackURL: "https://evil.example.com/callback"},
+		{CallbackURL: "https://callbacks.example.com/callback", AdditionalCallbacks: []Callback{{URL: "http://169.254.169.254"}}},
+		{CallbackURL: "https://callbacks.example.com/%zz"},
+	}
+	for _, options := range cases {
+		_, err := client.StartOperation(ctx, "foo", nil, options)
+		var unexpectedResponseError *UnexpectedResponseError
+		require.ErrorAs(t, err, &unexpectedResponseError)
+		require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	}
+	require.Empty(t, handler.callbacks)
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{CallbackURL: "https://callbacks.example.com/callback"})
+	require.NoError(t, err)
+	require.Len(t, <-handler.callbacks, 1)
+}
+
+func TestStartOperation_DefaultCallbackURLValidation(t *testing.T) {
+	handler := &callbacksHandler{callbacks: make(chan []Callback, 1)}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{CallbackURL: "http://169.254.169.254/latest/meta-data"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	require.Empty(t, handler.callbacks)
+}
@@ -0,0 +1,61 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// A CallerIdentityProvider provides the identity of the caller of an outgoing request, e.g. the name of the workload
+// or the end user on whose behalf the request is made, see [ClientOptions.CallerIdentityProvider].
+type CallerIdentityProvider func(ctx context.Context) (string, error)
+
+// staticCallerIdentity returns a [CallerIdentityProvider] that always provides the given identity.
+func staticCallerIdentity(identity string) CallerIdentityProvider {
+	return func(context.Context) (string, error) {
+		return identity, nil
+	}
+}
+
+// callerIdentityProvider returns the provider for the given static identity and provider options, or nil if neither is
+// set. The provider takes precedence.
+func callerIdentityProvider(identity string, provider CallerIdentityProvider) CallerIdentityProvider {
+	if provider != nil {
+		return provider
+	}
+	if identity != "" {
+		return staticCallerIdentity(identity)
+	}
+	return nil
+}
+
+// setCallerIdentityHTTPHeader sets the caller identity header of a request unless already set. Empty identities are
+// not sent.
+func setCallerIdentityHTTPHeader(request *http.Request, provider CallerIdentityProvider) error {
+	if request.Header.Get(HeaderCallerIdentity) != "" {
+		return nil
+	}
+	identity, err := provider(request.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	if identity != "" {
+		request.Header.Set(HeaderCallerIdentity, identity)
+	}
+	return nil
+}
+
+// callerIdentityHTTPCaller wraps caller to attach the caller identity to every request.
+func callerIdentityHTTPCaller(caller func(*http.Request) (*http.Response, error), provider CallerIdentityProvider) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		if err := setCallerIdentityHTTPHeader(request, provider); err != nil {
+			if request.Body != nil {
+				_, _ = io.Copy(io.Discard, request.Body)
+				request.Body.Close()
+			}
+			return nil, err
+		}
+		return caller(request)
+	}
+}
@@ -0,0 +1,112 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// callerEchoHandler responds to start requests with the caller identity from the request's [HandlerInfo].
+type callerEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *callerEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	info, _ := HandlerInfoFromContext(ctx)
+	return &HandlerStartOperationResultSync[any]{Value: info.Caller}, nil
+}
+
+func startCaller(ctx context.Context, t *testing.T, client *Client) string {
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var caller string
+	require.NoError(t, result.Successful.Consume(&caller))
+	return caller
+}
+
+type callerContextKey struct{}
+
+func TestCallerIdentity(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &callerEchoHandler{}}, ClientOptions{CallerIdentity: "billing"})
+	defer teardown()
+	require.Equal(t, "billing", startCaller(ctx, t, client))
+
+	clone, err := client.Clone(ClientOverrides{CallerIdentity: "shipping"})
+	require.NoError(t, err)
+	require.Equal(t, "shipping", startCaller(ctx, t, clone))
+	// Explicitly set headers take precedence.
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Header: Header{"nexus-caller-identity": "explicit"}})
+	require.NoError(t, err)
+	var caller string
+	require.NoError(t, result.Successful.Consume(&caller))
+	require.Equal(t, "explicit", caller)
+}
+
+func TestCallerIdentity_Provider(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &callerEchoHandler{}}, ClientOptions{
+		CallerIdentity: "ignored",
+		CallerIdentityProvider: func(ctx context.Context) (string, error) {
+			user, ok := ctx.Value(callerContextKey{}).(string)
+			if !ok {
+				return "", errors.New("no user")
+			}
+			return user, nil
+		},
+	})
+	defer teardown()
+	require.Equal(t, "alice", startCaller(context.WithValue(ctx, callerContextKey{}, "alice"), t, client))
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorContains(t, err, "failed to get caller identity: no user")
+}
+
+func TestCallerIdentity_Authorizer(t *testing.T) {
+	authorizer := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+		if request.Caller != "billing" {
+			return HandlerErrorf(HandlerErrorTypeUnauthorized, "unknown caller")
+		}
+		request.Caller = "verified:" + request.Caller
+		return nil
+	})
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &callerEchoHandler{}, Authorizer: authorizer}, ClientOptions{CallerIdentity: "billing"})
+	defer teardown()
+	require.Equal(t, "verified:billing", startCaller(ctx, t, client))
+}
+
+type callerRecordingCompletionHandler struct {
+	identities chan string
+}
+
+func (h *callerRecordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	h.identities <- completion.CallerIdentity
+	return nil
+}
+
+func TestCallerIdentity_CallbackDelivery(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return "done", nil
+		},
+		CallbackDelivery: &CallbackDeliveryOptions{CallerIdentity: "orders"},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	identities := make(chan string, 1)
+	_, callbackURL, teardownCallback := setupForCompletion(t, &callerRecordingCompletionHandler{identities}, nil)
+	defer teardownCallback()
+
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{CallbackURL: callbackURL})
+	require.NoError(t, err)
+	select {
+	case identity := <-identities:
+		require.Equal(t, "orders", identity)
+	case <-time.After(testTimeout):
+		t.Fatal("completion not delivered")
+	}
+}
@@ -0,0 +1,254 @@
+package nexus
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Name of the Server-Sent Events event type used to deliver batch progress.
+const batchEventProgress = "progress"
+
+// CancelMatchingOperationsOptions are options for the CancelMatchingOperations client and server APIs.
+type CancelMatchingOperationsOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Filter selecting the operations to cancel, e.g. by tag. Only running operations are canceled, Filter.States is
+	// ignored.
+	Filter OperationFilter
+	// Terminate matching operations, completing them as failed, instead of requesting cancelation.
+	Terminate bool
+	// Optional reason for canceling or terminating, set as the message of the resulting operation failure.
+	Reason string
+}
+
+// BatchProgress reports the progress of an operation applied to a batch of operations.
+type BatchProgress struct {
+	// Number of operations matched so far.
+	Matched int `json:"matched"`
+	// Number of matched operations processed successfully.
+	Succeeded int `json:"succeeded"`
+	// Number of matched operations that could not be processed.
+	Failed int `json:"failed"`
+	// Error message of the most recent failure, if any.
+	LastError string `json:"lastError,omitempty"`
+	// Set on the final progress report, once all matching operations have been processed.
+	Completed bool `json:"completed,omitempty"`
+}
+
+// A BatchProgressStream is a stream of progress reports of a batch operation delivered by a handler as Server-Sent
+// Events.
+//
+// Obtain one via [Client.CancelMatchingOperations].
+//
+// ⚠️ The stream must be closed to free up the underlying connection.
+type BatchProgressStream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	json     JSONEngine
+}
+
+// Next blocks until the next progress report is received.
+//
+// Returns [io.EOF] once the handler has closed the stream, which happens after the completed report has been
+// delivered.
+func (s *BatchProgressStream) Next() (*BatchProgress, error) {
+	data, err := readEvent(s.scanner, batchEventProgress)
+	if err != nil {
+		return nil, err
+	}
+	var progress BatchProgress
+	if err := s.json.unmarshal(data, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// Close closes the stream and frees up the underlying connection.
+func (s *BatchProgressStream) Close() error {
+	return s.response.Body.Close()
+}
+
+// CancelMatchingOperations implements Handler. Requires the Store to implement [OperationLister].
+//
+// Matching operations are canceled as with [AsyncHandler.CancelOperation]. Terminated operations complete as failed.
+// Progress is reported after every page of matching operations.
+func (h *AsyncHandler) CancelMatchingOperations(ctx context.Context, options CancelMatchingOperationsOptions) (<-chan *BatchProgress, error) {
+	lister, ok := h.options.Store.(OperationLister)
+	if !ok {
+		return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "listing operations is not supported by the store")
+	}
+	state := OperationStateCanceled
+	failure := &Failure{Message: "operation canceled"}
+	if options.Terminate {
+		state = OperationStateFailed
+		failure = &Failure{Message: "operation terminated"}
+	}
+	if options.Reason != "" {
+		failure.Message = options.Reason
+	}
+	filter := options.Filter
+	filter.States = []OperationState{OperationStateRunning}
+
+	ch := make(chan *BatchProgress)
+	go func() {
+		defer close(ch)
+		var progress BatchProgress
+		var pageToken string
+		for {
+			records, nextPageToken, err := lister.ListOperations(ctx, filter, DefaultListPageSize, pageToken)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				progress.LastError = err.Error()
+				nextPageToken = ""
+			}
+			for _, record := range records {
+				progress.Matched++
+				f := *failure
+				if err := h.stop(ctx, record.Operation, record.ID, state, &f); err != nil {
+					progress.Failed++
+					progress.LastError = err.Error()
+					continue
+				}
+				progress.Succeeded++
+			}
+			progress.Completed = nextPageToken == ""
+			report := progress
+			select {
+			case ch <- &report:
+			case <-ctx.Done():
+				return
+			}
+			if progress.Completed {
+				return
+			}
+			pageToken = nextPageToken
+		}
+	}()
+	return ch, nil
+}
+
+func (h *httpHandler) cancelMatchingOperations(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter, err := operationFilterFromQuery(query)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	options := CancelMatchingOperationsOptions{
+		Header: httpHeaderToNexusHeader(request.Header),
+		Filter: filter,
+		Reason: query.Get(QueryReason),
+	}
+	if v := query.Get(QueryTerminate); v != "" {
+		if options.Terminate, err = strconv.ParseBool(v); err != nil {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %s query parameter", QueryTerminate))
+			return
+		}
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodCancelMatchingOperations,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	reports, err := h.options.Handler.CancelMatchingOperations(ctx, options)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	controller := http.NewResponseController(writer)
+	writer.Header().Set("Content-Type", contentTypeEventStream)
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		h.logger.Error("failed to flush response", "error", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case progress, ok := <-reports:
+			if !ok {
+				return
+			}
+			if err := writeEvent(writer, h.json, batchEventProgress, progress); err != nil {
+				h.logger.Error("failed to write progress event", "error", err)
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				h.logger.Error("failed to flush response", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// CancelMatchingOperations cancels or terminates all running operations matching the filter in options, e.g. all
+// operations with a given tenant tag. This is an administrative capability; handlers should restrict access to it
+// via an [Authorizer].
+//
+// Progress is streamed back from the handler as the batch is processed. Read the stream until [io.EOF]; the last
+// report has [BatchProgress.Completed] set.
+//
+// ⚠️ If this method completes successfully, the returned stream must be closed to free up the underlying connection.
+func (c *Client) CancelMatchingOperations(ctx context.Context, options CancelMatchingOperationsOptions, opts ...CallOption) (*BatchProgressStream, error) {
+	ctx, c = c.withCallOptions(ctx, opts)
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_admin", "cancel")
+	q := addOperationFilterToQuery(options.Filter, url.Values{})
+	if options.Terminate {
+		q.Set(QueryTerminate, "true")
+	}
+	if options.Reason != "" {
+		q.Set(QueryReason, options.Reason)
+	}
+	u.RawQuery = q.Encode()
+	request, err := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	request.Header.Set("Accept", contentTypeEventStream)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusOK && isMediaTypeEventStream(response.Header.Get("Content-Type")) {
+		return &BatchProgressStream{response: response, scanner: newEventScanner(response.Body, c.options.MaxEventSize), json: c.options.JSON}, nil
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, c.options.JSON)
+}
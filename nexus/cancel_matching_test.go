@@ -0,0 +1,79 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func drainBatchProgress(t *testing.T, stream *BatchProgressStream) []*BatchProgress {
+	defer stream.Close()
+	var reports []*BatchProgress
+	for {
+		progress, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return reports
+		}
+		require.NoError(t, err)
+		reports = append(reports, progress)
+	}
+}
+
+func TestCancelMatchingOperations(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	defer teardown()
+
+	handles := map[string][]*OperationHandle[*LazyValue]{}
+	for _, tenant := range []string{"a", "b", "a"} {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: map[string]string{"tenant": tenant}})
+		require.NoError(t, err)
+		handles[tenant] = append(handles[tenant], result.Pending)
+	}
+
+	stream, err := client.CancelMatchingOperations(ctx, CancelMatchingOperationsOptions{
+		Filter: OperationFilter{Tags: map[string]string{"tenant": "a"}},
+		Reason: "tenant deleted",
+	})
+	require.NoError(t, err)
+	reports := drainBatchProgress(t, stream)
+	require.Equal(t, &BatchProgress{Matched: 2, Succeeded: 2, Completed: true}, reports[len(reports)-1])
+
+	for _, handle := range handles["a"] {
+		_, err := handle.GetResult(ctx, GetOperationResultOptions{})
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		require.ErrorAs(t, err, &unsuccessfulOperationError)
+		require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
+		require.Equal(t, "tenant deleted", unsuccessfulOperationError.Failure.Message)
+	}
+	info, err := handles["b"][0].GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+
+	stream, err = client.CancelMatchingOperations(ctx, CancelMatchingOperationsOptions{Terminate: true})
+	require.NoError(t, err)
+	reports = drainBatchProgress(t, stream)
+	require.Equal(t, &BatchProgress{Matched: 1, Succeeded: 1, Completed: true}, reports[len(reports)-1])
+
+	_, err = handles["b"][0].GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateFailed, unsuccessfulOperationError.State)
+	require.Equal(t, "operation terminated", unsuccessfulOperationError.Failure.Message)
+}
+
+func TestCancelMatchingOperations_NotImplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &unsuccessfulHandler{})
+	defer teardown()
+
+	_, err := client.CancelMatchingOperations(ctx, CancelMatchingOperationsOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 501, unexpectedResponseError.Response.StatusCode)
+}
@@ -0,0 +1,50 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Default of [AsyncHandlerOptions.GracefulCancelTimeout].
+const defaultGracefulCancelTimeout = time.Minute
+
+// CancelMode is the mode of a cancel request, sent in the [HeaderCancelMode] header.
+type CancelMode string
+
+const (
+	// Abort the operation immediately.
+	CancelModeForce CancelMode = "force"
+	// Let the operation finish its current step, e.g. persisting progress, and stop.
+	CancelModeGraceful CancelMode = "graceful"
+)
+
+func cancelModeFromHTTPRequest(request *http.Request) (CancelMode, error) {
+	mode := CancelMode(request.Header.Get(HeaderCancelMode))
+	switch mode {
+	case "", CancelModeForce, CancelModeGraceful:
+		return mode, nil
+	}
+	return "", HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %s header: %q", HeaderCancelMode, mode)
+}
+
+type cancelRequestedContextKey struct{}
+
+// CancelRequested returns a channel that is closed when graceful cancelation of the operation an [AsyncHandler]
+// executor is executing with ctx was requested, see [CancelModeGraceful]. Executors should finish their current step
+// and return an error, completing the operation as canceled. Their context is canceled if they do not return in time.
+//
+// Returns nil, which blocks forever when received from, when called outside of an executor.
+func CancelRequested(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(cancelRequestedContextKey{}).(chan struct{})
+	return ch
+}
+
+func isCancelRequested(ctx context.Context) bool {
+	select {
+	case <-CancelRequested(ctx):
+		return true
+	default:
+		return false
+	}
+}
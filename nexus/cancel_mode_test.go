@@ -0,0 +1,109 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type cancelModeRecordingHandler struct {
+	UnimplementedHandler
+	mode chan CancelMode
+}
+
+func (h *cancelModeRecordingHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	h.mode <- options.Mode
+	return nil
+}
+
+func TestCancel_Mode(t *testing.T) {
+	handler := &cancelModeRecordingHandler{mode: make(chan CancelMode, 1)}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	for _, mode := range []CancelMode{"", CancelModeForce, CancelModeGraceful} {
+		require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{Mode: mode}))
+		require.Equal(t, mode, <-handler.mode)
+	}
+
+	err = handle.Cancel(ctx, CancelOperationOptions{Mode: "later"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestAsyncHandler_GracefulCancel(t *testing.T) {
+	stopped := make(chan string, 1)
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			select {
+			case <-CancelRequested(ctx):
+				stopped <- "graceful"
+			case <-ctx.Done():
+				stopped <- "forced"
+			}
+			return nil, errors.New("stopped")
+		},
+		DefaultRetryPolicy: RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{
+		Mode:   CancelModeGraceful,
+		Reason: &Failure{Message: "maintenance"},
+	}))
+	require.Equal(t, "graceful", <-stopped)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+	require.Equal(t, "operation canceled: maintenance", unsuccessfulError.Failure.Message)
+	// Executions are not retried after graceful cancelation was requested.
+	require.Empty(t, stopped)
+}
+
+func TestAsyncHandler_GracefulCancelTimeout(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		GracefulCancelTimeout: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{Mode: CancelModeGraceful}))
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+	require.Equal(t, "operation canceled", unsuccessfulError.Failure.Message)
+}
+
+func TestCancelRequested_OutsideExecutor(t *testing.T) {
+	require.Nil(t, CancelRequested(context.Background()))
+	require.False(t, isCancelRequested(context.Background()))
+}
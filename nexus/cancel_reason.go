@@ -0,0 +1,37 @@
+package nexus
+
+import (
+	"net/http"
+)
+
+// cancelReasonFromHTTPRequest parses the optional reason of a cancel request from its JSON body.
+func cancelReasonFromHTTPRequest(request *http.Request, engine JSONEngine) (*Failure, error) {
+	if request.Body == nil || request.ContentLength == 0 {
+		return nil, nil
+	}
+	body, err := readAll(request.Body)
+	if err != nil {
+		return nil, requestBodyError(err, "failed to read cancel reason from request body")
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if !isMediaTypeJSON(request.Header.Get("Content-Type")) {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request content type: %q", request.Header.Get("Content-Type"))
+	}
+	var reason Failure
+	if err := engine.unmarshal(body, &reason); err != nil {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read cancel reason from request body")
+	}
+	return &reason, nil
+}
+
+// canceledFailure returns the failure of an operation canceled for the given optional reason, which becomes the
+// failure's cause.
+func canceledFailure(reason *Failure) *Failure {
+	failure := &Failure{Message: "operation canceled", Cause: reason}
+	if reason != nil && reason.Message != "" {
+		failure.Message += ": " + reason.Message
+	}
+	return failure
+}
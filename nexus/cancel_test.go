@@ -2,6 +2,9 @@ package nexus
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -103,7 +106,7 @@ func TestCancel_RequestTimeoutHeaderOverridesContextDeadline(t *testing.T) {
 
 	handle, err := client.NewHandle("foo", "timeout")
 	require.NoError(t, err)
-	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{headerRequestTimeout: timeout.String()}})
+	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{HeaderRequestTimeout: timeout.String()}})
 	require.NoError(t, err)
 }
 
@@ -116,3 +119,89 @@ func TestCancel_TimeoutNotPropagated(t *testing.T) {
 	err = handle.Cancel(context.Background(), CancelOperationOptions{})
 	require.NoError(t, err)
 }
+
+type requestIDRecordingCancelHandler struct {
+	UnimplementedHandler
+	requestIDs chan string
+}
+
+func (h *requestIDRecordingCancelHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	h.requestIDs <- options.RequestID
+	return nil
+}
+
+func TestCancel_RequestID(t *testing.T) {
+	handler := &requestIDRecordingCancelHandler{requestIDs: make(chan string, 1)}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{RequestID: "cancel-1"}))
+	require.Equal(t, "cancel-1", <-handler.requestIDs)
+
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	require.NotEmpty(t, <-handler.requestIDs)
+}
+
+func TestCancel_Reason(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	err = handle.Cancel(ctx, CancelOperationOptions{
+		Reason: &Failure{Message: "order withdrawn", Metadata: map[string]string{"by": "customer"}},
+	})
+	require.NoError(t, err)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+	require.Equal(t, "operation canceled: order withdrawn", unsuccessfulError.Failure.Message)
+	var failureError *FailureError
+	require.True(t, errors.As(unsuccessfulError.Failure.causeError(), &failureError))
+	require.Equal(t, "customer", failureError.Failure.Metadata["by"])
+
+	result, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, "operation canceled", unsuccessfulError.Failure.Message)
+	require.Nil(t, unsuccessfulError.Failure.Cause)
+}
+
+func TestCancel_InvalidReason(t *testing.T) {
+	_, client, teardown := setup(t, &asyncWithCancelHandler{})
+	defer teardown()
+
+	for _, tc := range []struct {
+		contentType, body string
+		status            int
+	}{
+		{contentTypeJSON, `{"message":"valid"}`, http.StatusAccepted},
+		{"text/plain", `{"message":"valid"}`, http.StatusBadRequest},
+		{contentTypeJSON, "not json", http.StatusBadRequest},
+	} {
+		url := client.serviceBaseURL.JoinPath("f%2Fo%2Fo", "a%2Fsync", "cancel").String()
+		request, err := http.NewRequest("POST", url, strings.NewReader(tc.body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", tc.contentType)
+		request.Header.Set(headerUserAgent, userAgent)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, tc.status, response.StatusCode)
+	}
+}
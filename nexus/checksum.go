@@ -0,0 +1,235 @@
+package nexus
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"strings"
+)
+
+// ErrContentDigestMismatch is matched by [IntegrityError]s, returned when reading content whose data does not match
+// the digest in its "digest" content header.
+var ErrContentDigestMismatch = errors.New("content digest mismatch")
+
+// A ChecksumAlgorithm is a digest algorithm for end-to-end checksums of content, named as in the HTTP Digest Algorithm
+// Values registry of RFC 9530. Checksums are sent in the "digest" content header, i.e. the HTTP Content-Digest header,
+// see [ClientOptions.Checksum], [HandlerOptions.Checksum] and [OperationCompletionSuccesfulOptions.Checksum].
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumSHA256 computes SHA-256 checksums.
+	ChecksumSHA256 ChecksumAlgorithm = "sha-256"
+	// ChecksumSHA512 computes SHA-512 checksums.
+	ChecksumSHA512 ChecksumAlgorithm = "sha-512"
+)
+
+// Digest computes the checksum of data in the format of the HTTP Content-Digest header, for use as the "digest"
+// content header. Returns an empty string for unsupported algorithms.
+func (a ChecksumAlgorithm) Digest(data []byte) string {
+	sum := a.sum(data)
+	if sum == nil {
+		return ""
+	}
+	return a.format(sum)
+}
+
+func (a ChecksumAlgorithm) newHash() hash.Hash {
+	switch a {
+	case ChecksumSHA256:
+		return sha256.New()
+	case ChecksumSHA512:
+		return sha512.New()
+	}
+	return nil
+}
+
+func (a ChecksumAlgorithm) sum(data []byte) []byte {
+	h := a.newHash()
+	if h == nil {
+		return nil
+	}
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func (a ChecksumAlgorithm) format(sum []byte) string {
+	return string(a) + "=:" + base64.StdEncoding.EncodeToString(sum) + ":"
+}
+
+// validate returns an error for algorithms other than the empty algorithm, disabling checksums, and the supported
+// ones.
+func (a ChecksumAlgorithm) validate() error {
+	if a != "" && a.newHash() == nil {
+		return fmt.Errorf("unsupported checksum algorithm: %q", a)
+	}
+	return nil
+}
+
+// IntegrityError is returned when reading content whose data does not match the digest in its "digest" content
+// header, e.g. because the content was corrupted in transit. It matches [ErrContentDigestMismatch].
+//
+// Handlers respond to requests whose content fails verification with a 400 (Bad Request) status code. The resulting
+// [UnexpectedResponseError] converts to an IntegrityError with errors.As.
+type IntegrityError struct {
+	// Algorithm of the mismatching digest.
+	Algorithm ChecksumAlgorithm
+	// Digest advertised by the sender.
+	Expected string
+	// Digest of the data read.
+	Actual string
+}
+
+// Error implements the error interface.
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("%v: expected %s, got %s", ErrContentDigestMismatch, e.Expected, e.Actual)
+}
+
+// Is matches [ErrContentDigestMismatch].
+func (e *IntegrityError) Is(target error) bool {
+	return target == ErrContentDigestMismatch
+}
+
+// Type of the failure handlers respond with to requests whose content failed verification.
+const integrityFailureType = "nexus.IntegrityError"
+
+func (e *IntegrityError) failure() *Failure {
+	return &Failure{
+		Message: e.Error(),
+		Type:    integrityFailureType,
+		Metadata: map[string]string{
+			"algorithm": string(e.Algorithm),
+			"expected":  e.Expected,
+			"actual":    e.Actual,
+		},
+	}
+}
+
+// integrityErrorFromFailure converts a failure responded with to a request whose content failed verification back
+// into an IntegrityError. Returns nil for other failures.
+func integrityErrorFromFailure(failure *Failure) *IntegrityError {
+	if failure == nil || failure.Type != integrityFailureType {
+		return nil
+	}
+	return &IntegrityError{
+		Algorithm: ChecksumAlgorithm(failure.Metadata["algorithm"]),
+		Expected:  failure.Metadata["expected"],
+		Actual:    failure.Metadata["actual"],
+	}
+}
+
+// contentDigest is a digest of a supported algorithm listed in a "digest" content header.
+type contentDigest struct {
+	algorithm ChecksumAlgorithm
+	// Value as listed in the header.
+	value string
+	// Decoded sum, nil if the value is malformed, which never matches.
+	sum []byte
+}
+
+func (d contentDigest) verify(sum []byte) error {
+	if subtle.ConstantTimeCompare(sum, d.sum) != 1 {
+		return &IntegrityError{Algorithm: d.algorithm, Expected: d.value, Actual: d.algorithm.format(sum)}
+	}
+	return nil
+}
+
+// parseContentDigest returns the digests of supported algorithms in a "digest" content header, which may list digests
+// of several algorithms.
+func parseContentDigest(header string) []contentDigest {
+	var digests []contentDigest
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		name, encoded, ok := strings.Cut(value, "=")
+		algorithm := ChecksumAlgorithm(strings.ToLower(name))
+		if !ok || algorithm.newHash() == nil {
+			continue
+		}
+		d := contentDigest{algorithm: algorithm, value: value}
+		if len(encoded) > 2 && strings.HasPrefix(encoded, ":") && strings.HasSuffix(encoded, ":") {
+			sum, err := base64.StdEncoding.DecodeString(encoded[1 : len(encoded)-1])
+			if err == nil && len(sum) == algorithm.newHash().Size() {
+				d.sum = sum
+			}
+		}
+		digests = append(digests, d)
+	}
+	return digests
+}
+
+// setChecksum sets the "digest" header of content to be sent to a checksum of data computed with the given algorithm,
+// unless checksums are disabled or the header already has a digest.
+func setChecksum(header Header, algorithm ChecksumAlgorithm, data []byte) error {
+	if algorithm == "" || header.Get("digest") != "" {
+		return nil
+	}
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	header["digest"] = algorithm.Digest(data)
+	return nil
+}
+
+// verifyingReader wraps the body of received content to verify it against the digests in its header, if any.
+func verifyingReader(body io.ReadCloser, header Header) io.ReadCloser {
+	if body == nil {
+		return nil
+	}
+	digests := parseContentDigest(header.Get("digest"))
+	if len(digests) == 0 {
+		return body
+	}
+	hashes := make([]hash.Hash, len(digests))
+	for i, d := range digests {
+		hashes[i] = d.algorithm.newHash()
+	}
+	return &digestVerifyingReader{ReadCloser: body, digests: digests, hashes: hashes}
+}
+
+// digestVerifyingReader returns an IntegrityError instead of io.EOF if the data read does not match the expected
+// digests.
+type digestVerifyingReader struct {
+	io.ReadCloser
+	digests []contentDigest
+	hashes  []hash.Hash
+}
+
+func (r *digestVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	for _, h := range r.hashes {
+		h.Write(p[:n])
+	}
+	if err == io.EOF {
+		for i, d := range r.digests {
+			if err := d.verify(r.hashes[i].Sum(nil)); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, err
+}
+
+// redigest updates the "digest" header of content whose data was transformed to match the transformed data, using the
+// algorithms of the original digest. Content without a digest is returned unchanged.
+func redigest(content *Content) *Content {
+	if content == nil || content.Header.Get("digest") == "" {
+		return content
+	}
+	header := maps.Clone(content.Header)
+	var digests []string
+	for _, d := range parseContentDigest(content.Header.Get("digest")) {
+		digests = append(digests, d.algorithm.Digest(content.Data))
+	}
+	if len(digests) == 0 {
+		// Digests of unsupported algorithms cannot be updated and no longer match.
+		delete(header, "digest")
+	} else {
+		header["digest"] = strings.Join(digests, ", ")
+	}
+	return &Content{Header: header, Data: content.Data}
+}
@@ -0,0 +1,140 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumAlgorithm(t *testing.T) {
+	data := []byte("payload")
+	require.Equal(t, ContentDigest(data), ChecksumSHA256.Digest(data))
+	digest := ChecksumSHA512.Digest(data)
+	require.True(t, strings.HasPrefix(digest, "sha-512=:"))
+	require.NoError(t, (&Content{Header: Header{"digest": digest}, Data: data}).VerifyDigest())
+	require.Equal(t, "", ChecksumAlgorithm("md5").Digest(data))
+
+	_, err := NewClient(ClientOptions{ServiceBaseURL: "http://localhost", Checksum: "md5"})
+	require.EqualError(t, err, `unsupported checksum algorithm: "md5"`)
+	_, err = NewOperationCompletionSuccessful("result", OperationCompletionSuccesfulOptions{Checksum: "md5"})
+	require.EqualError(t, err, `unsupported checksum algorithm: "md5"`)
+}
+
+func TestIntegrityError(t *testing.T) {
+	err := (&Content{Header: Header{"digest": ChecksumSHA512.Digest([]byte("sent"))}, Data: []byte("received")}).VerifyDigest()
+	var integrityError *IntegrityError
+	require.ErrorAs(t, err, &integrityError)
+	require.ErrorIs(t, err, ErrContentDigestMismatch)
+	require.Equal(t, ChecksumSHA512, integrityError.Algorithm)
+	require.Equal(t, ChecksumSHA512.Digest([]byte("sent")), integrityError.Expected)
+	require.Equal(t, ChecksumSHA512.Digest([]byte("received")), integrityError.Actual)
+}
+
+type checksumHandler struct {
+	UnimplementedHandler
+	digests chan string
+}
+
+func (h *checksumHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.digests <- input.Reader.Header.Get("digest")
+	var s string
+	if err := input.Consume(&s); err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{Value: strings.ToUpper(s)}, nil
+}
+
+// corruptingCaller flips the last byte of request or response bodies while corruption is enabled, like a faulty
+// middlebox.
+type corruptingCaller struct {
+	requests, responses atomic.Bool
+}
+
+func (c *corruptingCaller) do(request *http.Request) (*http.Response, error) {
+	if c.requests.Load() && request.Body != nil {
+		request.Body = io.NopCloser(bytes.NewReader(corrupt(request.Body)))
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err == nil && c.responses.Load() {
+		response.Body = io.NopCloser(bytes.NewReader(corrupt(response.Body)))
+	}
+	return response, err
+}
+
+func corrupt(body io.ReadCloser) []byte {
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if len(data) > 0 {
+		data[len(data)-1] ^= 1
+	}
+	return data
+}
+
+func TestChecksum_StartOperation(t *testing.T) {
+	handler := &checksumHandler{digests: make(chan string, 1)}
+	caller := &corruptingCaller{}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler, Checksum: ChecksumSHA512}, ClientOptions{
+		HTTPCaller: caller.do,
+		Checksum:   ChecksumSHA256,
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", "hello", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, ContentDigest([]byte(`"hello"`)), <-handler.digests)
+	require.Equal(t, ChecksumSHA512.Digest([]byte(`"HELLO"`)), result.Successful.Reader.Header.Get("digest"))
+	var s string
+	require.NoError(t, result.Successful.Consume(&s))
+	require.Equal(t, "HELLO", s)
+
+	caller.requests.Store(true)
+	_, err = client.StartOperation(ctx, "foo", "hello", StartOperationOptions{})
+	<-handler.digests
+	var integrityError *IntegrityError
+	require.ErrorAs(t, err, &integrityError)
+	require.ErrorIs(t, err, ErrContentDigestMismatch)
+	require.Equal(t, ChecksumSHA256, integrityError.Algorithm)
+	require.Equal(t, ContentDigest([]byte(`"hello"`)), integrityError.Expected)
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+
+	caller.requests.Store(false)
+	caller.responses.Store(true)
+	result, err = client.StartOperation(ctx, "foo", "hello", StartOperationOptions{})
+	require.NoError(t, err)
+	<-handler.digests
+	require.ErrorAs(t, result.Successful.Consume(&s), &integrityError)
+	require.Equal(t, ChecksumSHA512, integrityError.Algorithm)
+}
+
+func TestChecksum_Completion(t *testing.T) {
+	completion, err := NewOperationCompletionSuccessful("result", OperationCompletionSuccesfulOptions{Checksum: ChecksumSHA512})
+	require.NoError(t, err)
+	require.Equal(t, ChecksumSHA512.Digest([]byte(`"result"`)), completion.Header.Get("Content-Digest"))
+
+	received := make(chan error, 1)
+	ctx, callbackURL, teardown := setupForCompletion(t, completionHandlerFunc(func(ctx context.Context, request *CompletionRequest) error {
+		var s string
+		err := request.Result.Consume(&s)
+		received <- err
+		return err
+	}), nil)
+	defer teardown()
+
+	completion.Body = strings.NewReader(`"resulT"`)
+	request, err := NewCompletionHTTPRequest(ctx, callbackURL, completion)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	require.True(t, errors.Is(<-received, ErrContentDigestMismatch))
+}
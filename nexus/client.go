@@ -3,7 +3,7 @@ package nexus
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -13,20 +13,168 @@ import (
 	"net/url"
 	"strconv"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // ClientOptions are options for creating a Client.
 type ClientOptions struct {
 	// Base URL of the service.
 	ServiceBaseURL string
+	// Base URLs of the same service served at multiple endpoints, e.g. in different regions, as an alternative to
+	// ServiceBaseURL. Each request is sent to an endpoint picked by the EndpointSelector. Endpoints failing with a
+	// transport error or a 502, 503 or 504 response are excluded from selection for EndpointCooldown, and requests
+	// failing with a transport error are retried on another endpoint.
+	//
+	// Clients derived via [Client.Clone] with a ServiceBaseURL override send requests to that URL only.
+	ServiceEndpoints []ServiceEndpoint
+	// Picks the endpoint each request is sent to among the healthy ServiceEndpoints.
+	// Defaults to [NewRoundRobinEndpointSelector].
+	EndpointSelector EndpointSelector
+	// Duration failing ServiceEndpoints are excluded from selection for. Defaults to 30 seconds. Endpoints responding
+	// with 503 and a Retry-After header are excluded for the requested delay instead.
+	EndpointCooldown time.Duration
+	// Logical name of the service to discover the endpoints of via the Resolver, as an alternative to ServiceBaseURL
+	// and ServiceEndpoints.
+	ServiceName string
+	// Resolves ServiceName to the endpoints of the service, e.g. via DNS SRV records (see [NewDNSSRVResolver]).
+	// Resolved endpoints are refreshed once their TTL expires and are used like ServiceEndpoints. The service is
+	// resolved on the first request.
+	Resolver Resolver
 	// A function for making HTTP requests.
-	// Defaults to [http.DefaultClient.Do].
+	// Defaults to the Do method of a client using a transport shared by all clients created with [NewHTTPTransport], or
+	// of a client using a transport configured with the transport options below.
 	HTTPCaller func(*http.Request) (*http.Response, error)
 	// A [Serializer] to customize client serialization behavior.
 	// By default the client handles, JSONables, byte slices, and nil.
+	// The media types of a [NegotiatingSerializer] are sent in the Accept header of requests for results.
 	Serializer Serializer
+	// Transformers applied in order to the content of results returned by the client before they are deserialized,
+	// e.g. to decompress, decrypt or migrate them based on their content headers, see [ResultTransformer]. Results
+	// written with [LazyValue.WriteTo] or served with [OperationHandle.ServeResult] are not transformed.
+	ResultTransformers []ResultTransformer
+	// Optional registry of payload schemas. Inputs are validated before they are sent and results when they are
+	// consumed, failing with a [SchemaValidationError] if they do not conform to their schema.
+	SchemaRegistry *SchemaRegistry
+	// JSON implementation for the default serializer and for decoding operation info and failures. Defaults to
+	// encoding/json.
+	JSON JSONEngine
+	// Optional converter for decoding failures received from the handler, see [FailureConverter].
+	FailureConverter FailureConverter
+	// Maximum size of response bodies, e.g. operation results, in bytes. Responses declaring a larger Content-Length
+	// are failed with [ErrResponseBodyTooLarge] before their body is read, as are reads past the limit of bodies of
+	// unknown length. Zero means no limit.
+	MaxResponseBodySize int64
+	// Maximum size of a line of the Server-Sent Events received by [OperationHandle.Watch] and
+	// [Client.CancelMatchingOperations], in bytes. Handlers send each event's JSON encoded payload on a single line, so
+	// this bounds the size of an event. Streams with longer lines fail with [bufio.ErrTooLong]. Defaults to 1 MiB.
+	MaxEventSize int
+	// Optional algorithm of checksums sent with start operation inputs in the "digest" content header, i.e. the HTTP
+	// Content-Digest header, letting handlers detect inputs corrupted in transit. Inputs streamed from a [Reader] are
+	// sent without a checksum unless their header has a digest. Received results are verified against their digest,
+	// if any, regardless of this option. See [ChecksumAlgorithm] and [IntegrityError].
+	Checksum ChecksumAlgorithm
+	// Retain the serialized inputs of started asynchronous operations in their handles, allowing them to be rerun with
+	// the same input via [RetryOperation]. Inputs streamed from an [io.Reader] are not retained. Retained inputs are
+	// kept in memory for as long as the handle is referenced.
+	RetainInputs bool
+	// Layout of the URL paths requests are sent to relative to the service base URL, which must match the handler's
+	// [HandlerOptions.PathBuilder]. Defaults to the zero value layout, see [PathBuilder].
+	PathBuilder PathBuilder
+	// Faults injected into requests before they are sent, for resilience testing. Faults can also be injected into the
+	// requests of individual calls via [ContextWithFaults]. See [Fault].
+	Faults []Fault
+	// An optional [ResultCache] for serving results of already completed operations locally.
+	// When set, successful results fetched via [OperationHandle.GetResult] are read into memory and cached.
+	ResultCache ResultCache
+	// An optional [AuthProvider] for attaching an Authorization header to every outgoing request.
+	// An Authorization header explicitly set in the per-request options takes precedence.
+	AuthProvider AuthProvider
+	// Identity of the caller sent with every request in the [HeaderCallerIdentity] header, e.g. the name of the calling
+	// workload, for audit logging and authorization by handlers. Handlers cannot verify the asserted identity on their
+	// own; pair it with an AuthProvider whose credentials the handler's [Authorizer] checks against it.
+	CallerIdentity string
+	// Optional provider of the caller identity of every request, e.g. derived from the request context. Takes
+	// precedence over CallerIdentity. Requests are not sent if the provider fails.
+	CallerIdentityProvider CallerIdentityProvider
+	// Optional application identifier appended to the SDK's User-Agent header of every request, e.g.
+	// "billing-service/1.4.2", for attributing requests to callers in handler logs and metrics.
+	UserAgent string
+	// Name of the client library sent in the [HeaderClientName] header of every request, e.g. by libraries built on
+	// top of this SDK. Defaults to this SDK's name, along with its version.
+	ClientName string
+	// Version of the client library sent in the [HeaderClientVersion] header of every request. Only used when
+	// ClientName is set.
+	ClientVersion string
+	// Optional header fields sent with every request. Header fields set by the SDK or in the per-request options take
+	// precedence.
+	Header Header
+	// Indicates that the client accepts result redirects, allowing handlers to respond to result requests with a
+	// redirect to a URL the result is downloaded from, e.g. a pre-signed object store URL. Redirected results are
+	// verified against the content digest advertised by the handler, if any.
+	//
+	// A custom HTTPCaller must not follow redirects itself, see [http.Client.CheckRedirect] and
+	// [http.ErrUseLastResponse].
+	FollowResultRedirects bool
+	// TLS configuration for connecting to the service, e.g. client certificates for mutual TLS (see [NewMTLSConfig]).
+	//
+	// TLSConfig and the other transport options below configure the HTTP client created when HTTPCaller is not set,
+	// and cannot be combined with a custom HTTPCaller.
+	TLSConfig *tls.Config
+	// Custom function for establishing network connections.
+	DialContext DialContextFunc
+	// Maximum amount of time to wait for a connection to be established. Ignored if DialContext is set.
+	// See [HTTPTransportOptions] for the defaults of this and the other transport options.
+	DialTimeout time.Duration
+	// Interval of TCP keep-alive probes. Ignored if DialContext is set, negative values disable probes.
+	KeepAlive time.Duration
+	// Maximum amount of time to wait for a TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// Maximum amount of time an idle connection remains in the pool before closing itself.
+	IdleConnTimeout time.Duration
+	// Maximum number of idle connections to keep per host.
+	MaxIdleConnsPerHost int
+	// Maximum number of idle connections to keep across all hosts.
+	MaxIdleConns int
+	// Maximum number of connections per host, including connections in use. Requests exceeding the limit wait for a
+	// connection to become available. Zero means no limit.
+	MaxConnsPerHost int
+	// Function that returns the proxy to use for a given request, see [http.ProxyURL] and
+	// [http.ProxyFromEnvironment]. A nil URL indicates that no proxy should be used.
+	// Defaults to [http.ProxyFromEnvironment].
+	Proxy func(*http.Request) (*url.URL, error)
+	// Disable HTTP/2, which is otherwise negotiated with services supporting it over TLS.
+	DisableHTTP2 bool
+	// Long polls, i.e. [OperationHandle.GetResult] requests with a wait duration and [OperationHandle.Watch] streams,
+	// hold on to a connection for up to a minute. Set to issue them via a dedicated HTTP client with its own connection
+	// pool so they cannot starve other requests of connections. The dedicated transport inherits the transport options
+	// above, overridden by the LongPoll options below.
+	SeparateLongPollTransport bool
+	// Maximum number of idle connections to keep per host in the dedicated long poll pool.
+	// Defaults to MaxIdleConnsPerHost.
+	LongPollMaxIdleConnsPerHost int
+	// Maximum number of long poll connections per host, including connections in use. Defaults to MaxConnsPerHost.
+	LongPollMaxConnsPerHost int
+	// Maximum amount of time an idle connection remains in the dedicated long poll pool before closing itself.
+	// Defaults to IdleConnTimeout.
+	LongPollIdleConnTimeout time.Duration
+	// A function for making long poll HTTP requests, e.g. the Do method of a separately tuned [http.Client] when
+	// using a custom HTTPCaller. Cannot be combined with SeparateLongPollTransport.
+	// Defaults to HTTPCaller, or the Do method of a dedicated client if SeparateLongPollTransport is set.
+	LongPollHTTPCaller func(*http.Request) (*http.Response, error)
+	// Delay after which idempotent reads, i.e. [OperationHandle.GetInfo] and [OperationHandle.GetResult] without a wait
+	// duration, are hedged by issuing an identical request if no response was received yet, trading load on the
+	// handler for lower tail latency. The first successful response, i.e. a response with a status below 500, is
+	// returned and the other requests are canceled. Zero disables hedging.
+	HedgeDelay time.Duration
+	// Maximum number of hedged requests issued in addition to the original request, each after another HedgeDelay.
+	// Defaults to 1.
+	MaxHedgedRequests int
+	// Clock measuring the waits of [OperationHandle.GetResult], e.g. between polls of overloaded handlers, and the
+	// HedgeDelay. Defaults to [SystemClock]. Context deadlines are always measured by the system clock.
+	Clock Clock
+	// Strategy determining the wait duration of each long poll request of [OperationHandle.GetResult] within its wait
+	// period. Defaults to [NewAdaptiveWaitStrategy] with default options. Use [NewFixedWaitStrategy] to wait for the
+	// entire wait period in a single request.
+	WaitStrategy WaitStrategy
 }
 
 // User-Agent header set on HTTP requests.
@@ -42,9 +190,13 @@ var errInvalidURLScheme = errors.New("invalid URL scheme")
 
 var errEmptyOperationName = errors.New("empty operation name")
 
+var errReservedOperationName = errors.New("reserved operation name")
+
 var errEmptyOperationID = errors.New("empty operation ID")
 
-var errOperationWaitTimeout = errors.New("operation wait timeout")
+// ErrOperationWaitTimeout is returned by [OperationHandle.GetResult] with [GetOperationResultOptions.SinglePoll] when
+// the handler's long poll timed out before the operation completed. It wraps [ErrOperationStillRunning].
+var ErrOperationWaitTimeout = fmt.Errorf("operation wait timeout: %w", ErrOperationStillRunning)
 
 // Error that indicates a client encountered something unexpected in the server's response.
 type UnexpectedResponseError struct {
@@ -54,6 +206,9 @@ type UnexpectedResponseError struct {
 	Response *http.Response
 	// Optional failure that may have been emedded in the HTTP response body.
 	Failure *Failure
+	// Delay the handler asked the caller to wait for before retrying, parsed from the response's Retry-After header.
+	// Zero if the header is absent or invalid. See [RetryAfter].
+	RetryAfter time.Duration
 }
 
 // Error implements the error interface.
@@ -61,18 +216,66 @@ func (e *UnexpectedResponseError) Error() string {
 	return e.Message
 }
 
-func newUnexpectedResponseError(message string, response *http.Response, body []byte) error {
+// Unwrap returns the failure embedded in the response as a [FailureError], if any.
+func (e *UnexpectedResponseError) Unwrap() error {
+	if e.Failure == nil {
+		return nil
+	}
+	return &FailureError{Failure: *e.Failure}
+}
+
+// As converts the failure of a request whose content failed verification by the handler into an [IntegrityError], and
+// the failure of a request whose input failed schema validation into a [SchemaValidationError].
+func (e *UnexpectedResponseError) As(target any) bool {
+	switch t := target.(type) {
+	case **IntegrityError:
+		if integrityError := integrityErrorFromFailure(e.Failure); integrityError != nil {
+			*t = integrityError
+			return true
+		}
+	case **SchemaValidationError:
+		if validationError := schemaValidationErrorFromFailure(e.Failure); validationError != nil {
+			*t = validationError
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether the response's status code represents the target error, allowing clients to check for
+// [ErrOperationNotFound], [ErrOperationAlreadyStarted] and [ErrOperationResultGone] with errors.Is, and whether the
+// handler rejected the request's content for failing verification, see [ErrContentDigestMismatch].
+func (e *UnexpectedResponseError) Is(target error) bool {
+	if target == ErrContentDigestMismatch {
+		return integrityErrorFromFailure(e.Failure) != nil
+	}
+	if e.Response == nil {
+		return false
+	}
+	switch target {
+	case ErrOperationNotFound:
+		return e.Response.StatusCode == http.StatusNotFound
+	case ErrOperationAlreadyStarted:
+		return e.Response.StatusCode == http.StatusConflict
+	case ErrOperationResultGone:
+		return e.Response.StatusCode == http.StatusGone
+	}
+	return false
+}
+
+func newUnexpectedResponseError(message string, response *http.Response, body []byte, engine JSONEngine) error {
 	var failure *Failure
 	if isMediaTypeJSON(response.Header.Get("Content-Type")) {
-		if err := json.Unmarshal(body, &failure); err == nil && failure.Message != "" {
+		if err := engine.unmarshal(body, &failure); err == nil && failure != nil && failure.Message != "" {
 			message += ": " + failure.Message
 		}
 	}
 
 	return &UnexpectedResponseError{
-		Message:  message,
-		Response: response,
-		Failure:  failure,
+		Message:    message,
+		Response:   response,
+		Failure:    failure,
+		RetryAfter: retryAfterFromHTTPHeader(response.Header),
 	}
 }
 
@@ -85,39 +288,213 @@ func newUnexpectedResponseError(message string, response *http.Response, body []
 // OperationHandles can be obtained either by starting new operations or by calling [Client.NewHandle] for existing
 // operations.
 //
+// A Client is immutable once created and safe for concurrent use, as are the OperationHandles it returns. Use
+// [Client.Clone] to derive a client with different settings, e.g. for a single tenant, without affecting other users
+// of the original client.
+//
 // [Nexus HTTP API]: https://github.com/nexus-rpc/api
 type Client struct {
 	// The options this client was created with after applying defaults.
 	options        ClientOptions
 	serviceBaseURL *url.URL
+	// HTTPCaller without authorization, used for following result redirects.
+	redirectHTTPCaller func(*http.Request) (*http.Response, error)
+	// LongPollHTTPCaller without authorization and header fields from the options, used when cloning the client.
+	baseLongPollHTTPCaller func(*http.Request) (*http.Response, error)
+}
+
+var noRedirectHTTPClient = &http.Client{
+	Transport: defaultHTTPTransport,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
 }
 
 // NewClient creates a new [Client] from provided [ClientOptions].
 // Only BaseServiceURL is required.
+//
+// The options are copied; modifying them or the header passed in them after creating the client has no effect on the
+// client.
 func NewClient(options ClientOptions) (*Client, error) {
+	if err := options.Checksum.validate(); err != nil {
+		return nil, err
+	}
+	options.PathBuilder = options.PathBuilder.withDefaults(options.ServiceName)
+	if err := options.PathBuilder.validate(); err != nil {
+		return nil, err
+	}
 	if options.HTTPCaller == nil {
-		options.HTTPCaller = http.DefaultClient.Do
+		options.HTTPCaller = newHTTPClient(options).Do
+	} else if options.hasTransportOptions() {
+		return nil, errTransportOptionsWithHTTPCaller
 	}
-	if options.ServiceBaseURL == "" {
-		return nil, errEmptyServiceBaseURL
+	if options.LongPollHTTPCaller == nil {
+		options.LongPollHTTPCaller = options.HTTPCaller
+		if options.SeparateLongPollTransport {
+			options.LongPollHTTPCaller = newLongPollHTTPClient(options).Do
+		}
+	} else if options.SeparateLongPollTransport {
+		return nil, errSeparateLongPollTransportWithCaller
 	}
 	var serviceBaseURL *url.URL
-	var err error
-	serviceBaseURL, err = url.Parse(options.ServiceBaseURL)
+	if len(options.ServiceEndpoints) > 0 || options.Resolver != nil {
+		if err := validateEndpointOptions(options); err != nil {
+			return nil, err
+		}
+		endpoints, err := newEndpointSet(options)
+		if err != nil {
+			return nil, err
+		}
+		// Requests are built against the set's base URL and rewritten to target the selected endpoint.
+		serviceBaseURL = endpoints.base
+		options.ServiceBaseURL = serviceBaseURL.String()
+		options.HTTPCaller = endpointHTTPCaller(options.HTTPCaller, endpoints)
+		options.LongPollHTTPCaller = endpointHTTPCaller(options.LongPollHTTPCaller, endpoints)
+	} else {
+		var err error
+		if serviceBaseURL, err = parseServiceBaseURL(options.ServiceBaseURL); err != nil {
+			return nil, err
+		}
+	}
+	if options.Serializer == nil {
+		options.Serializer = NewJSONSerializer(options.JSON)
+	}
+	if options.MaxHedgedRequests == 0 {
+		options.MaxHedgedRequests = 1
+	}
+	if options.MaxEventSize == 0 {
+		options.MaxEventSize = defaultMaxEventSize
+	}
+	if options.Clock == nil {
+		options.Clock = SystemClock
+	}
+	if options.WaitStrategy == nil {
+		options.WaitStrategy = NewAdaptiveWaitStrategy(AdaptiveWaitStrategyOptions{})
+	}
+	return newClient(options, serviceBaseURL, options.HTTPCaller, options.LongPollHTTPCaller), nil
+}
+
+func parseServiceBaseURL(serviceBaseURL string) (*url.URL, error) {
+	if serviceBaseURL == "" {
+		return nil, errEmptyServiceBaseURL
+	}
+	u, err := url.Parse(serviceBaseURL)
 	if err != nil {
 		return nil, err
 	}
-	if serviceBaseURL.Scheme != "http" && serviceBaseURL.Scheme != "https" {
+	if u.Scheme != "http" && u.Scheme != "https" {
 		return nil, errInvalidURLScheme
 	}
-	if options.Serializer == nil {
-		options.Serializer = defaultSerializer
-	}
+	return u, nil
+}
 
+// newClient creates a client from defaulted options and the HTTP callers to wrap with the response body limit, header
+// fields, authorization and failure decoding the options call for.
+func newClient(options ClientOptions, serviceBaseURL *url.URL, httpCaller, longPollHTTPCaller func(*http.Request) (*http.Response, error)) *Client {
+	options.Header = maps.Clone(options.Header)
+	options.HTTPCaller, options.LongPollHTTPCaller = httpCaller, longPollHTTPCaller
+	options.HTTPCaller = faultInjectingHTTPCaller(options.HTTPCaller, options.Faults, options.JSON)
+	options.LongPollHTTPCaller = faultInjectingHTTPCaller(options.LongPollHTTPCaller, options.Faults, options.JSON)
+	if options.MaxResponseBodySize > 0 {
+		options.HTTPCaller = limitingResponseHTTPCaller(options.HTTPCaller, options.MaxResponseBodySize)
+		options.LongPollHTTPCaller = limitingResponseHTTPCaller(options.LongPollHTTPCaller, options.MaxResponseBodySize)
+	}
+	if len(options.Header) > 0 {
+		options.HTTPCaller = headerHTTPCaller(options.HTTPCaller, options.Header)
+		options.LongPollHTTPCaller = headerHTTPCaller(options.LongPollHTTPCaller, options.Header)
+	}
+	info := newClientInfo(options)
+	options.HTTPCaller = clientInfoHTTPCaller(options.HTTPCaller, info)
+	options.LongPollHTTPCaller = clientInfoHTTPCaller(options.LongPollHTTPCaller, info)
+	if provider := callerIdentityProvider(options.CallerIdentity, options.CallerIdentityProvider); provider != nil {
+		options.HTTPCaller = callerIdentityHTTPCaller(options.HTTPCaller, provider)
+		options.LongPollHTTPCaller = callerIdentityHTTPCaller(options.LongPollHTTPCaller, provider)
+	}
+	if options.AuthProvider != nil {
+		options.HTTPCaller = authorizingHTTPCaller(options.HTTPCaller, options.AuthProvider)
+		options.LongPollHTTPCaller = authorizingHTTPCaller(options.LongPollHTTPCaller, options.AuthProvider)
+	}
+	if options.FailureConverter != nil {
+		options.HTTPCaller = failureDecodingHTTPCaller(options.HTTPCaller, options.FailureConverter, options.JSON)
+		options.LongPollHTTPCaller = failureDecodingHTTPCaller(options.LongPollHTTPCaller, options.FailureConverter, options.JSON)
+	}
+	options.HTTPCaller = callOptionsHTTPCaller(options.HTTPCaller, options.Clock, options.JSON)
+	options.LongPollHTTPCaller = callOptionsHTTPCaller(options.LongPollHTTPCaller, options.Clock, options.JSON)
 	return &Client{
-		options:        options,
-		serviceBaseURL: serviceBaseURL,
-	}, nil
+		options:                options,
+		serviceBaseURL:         serviceBaseURL,
+		redirectHTTPCaller:     httpCaller,
+		baseLongPollHTTPCaller: longPollHTTPCaller,
+	}
+}
+
+// headerHTTPCaller wraps caller to add header fields not already set to every request.
+func headerHTTPCaller(caller func(*http.Request) (*http.Response, error), header Header) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		for k, v := range header {
+			if request.Header.Get(k) == "" {
+				request.Header.Set(k, v)
+			}
+		}
+		return caller(request)
+	}
+}
+
+// ClientOverrides are settings overridden in a client derived via [Client.Clone]. Zero valued fields inherit the
+// setting of the original client.
+type ClientOverrides struct {
+	// Base URL of the service.
+	ServiceBaseURL string
+	// Header fields sent with every request, merged with and taking precedence over the original client's
+	// [ClientOptions.Header].
+	Header Header
+	// [AuthProvider] for attaching an Authorization header to every outgoing request.
+	AuthProvider AuthProvider
+	// Identity of the caller sent with every request, replacing the original client's caller identity and provider.
+	CallerIdentity string
+	// [Serializer] to customize client serialization behavior.
+	Serializer Serializer
+	// [ResultCache] for serving results of already completed operations locally.
+	ResultCache ResultCache
+}
+
+// Clone returns a new client with the given settings overridden, sharing the original client's HTTP callers and
+// connection pools. The original client is not modified, making Clone suitable for per-call variation of settings,
+// such as the target service or a tenant header, without synchronization.
+//
+// Cache keys do not include header fields; override ResultCache when clones must not share cached results, e.g. across
+// tenants distinguished by a header field.
+func (c *Client) Clone(overrides ClientOverrides) (*Client, error) {
+	options := c.options
+	serviceBaseURL := c.serviceBaseURL
+	if overrides.ServiceBaseURL != "" {
+		var err error
+		if serviceBaseURL, err = parseServiceBaseURL(overrides.ServiceBaseURL); err != nil {
+			return nil, err
+		}
+		options.ServiceBaseURL = overrides.ServiceBaseURL
+	}
+	if len(overrides.Header) > 0 {
+		header := maps.Clone(options.Header)
+		if header == nil {
+			header = make(Header, len(overrides.Header))
+		}
+		maps.Copy(header, overrides.Header)
+		options.Header = header
+	}
+	if overrides.AuthProvider != nil {
+		options.AuthProvider = overrides.AuthProvider
+	}
+	if overrides.CallerIdentity != "" {
+		options.CallerIdentity, options.CallerIdentityProvider = overrides.CallerIdentity, nil
+	}
+	if overrides.Serializer != nil {
+		options.Serializer = overrides.Serializer
+	}
+	if overrides.ResultCache != nil {
+		options.ResultCache = overrides.ResultCache
+	}
+	return newClient(options, serviceBaseURL, c.redirectHTTPCaller, c.baseLongPollHTTPCaller), nil
 }
 
 // ClientStartOperationResult is the return type of [Client.StartOperation].
@@ -131,10 +508,16 @@ type ClientStartOperationResult[T any] struct {
 	// Set when the handler indicates that it started an asynchronous operation.
 	// The attached handle can be used to perform actions such as cancel the operation or get its result.
 	Pending *OperationHandle[T]
+	// Set along with Pending when the handler indicates that the operation was already started, e.g. by a previous
+	// request with the same request ID. Pending is a handle to the existing operation.
+	AlreadyStarted bool
 }
 
 // StartOperation calls the configured Nexus endpoint to start an operation.
 //
+// The input is serialized with the client's [Serializer], except for [Reader] and [Content] values, which are sent as
+// is, and other [io.Reader] values, which are streamed as application/octet-stream, see [NewReader].
+//
 // This method has the following possible outcomes:
 //
 //  1. The operation completes successfully. The result of this call will be set as a [LazyValue] in
@@ -142,19 +525,67 @@ type ClientStartOperationResult[T any] struct {
 //
 //  2. The operation was started and the handler has indicated that it will complete asynchronously. An
 //     [OperationHandle] will be returned as ClientStartOperationResult.Pending, which can be used to perform actions
-//     such as getting its result.
+//     such as getting its result. If the handler indicates that the operation was already started, either with a 201
+//     or a 409 response identifying the existing operation, Pending is a handle to the existing operation and
+//     ClientStartOperationResult.AlreadyStarted is set.
 //
 //  3. The operation was unsuccessful. The returned result will be nil and error will be an
 //     [UnsuccessfulOperationError].
 //
-//  4. Any other error.
-func (c *Client) StartOperation(ctx context.Context, operation string, input any, options StartOperationOptions) (*ClientStartOperationResult[*LazyValue], error) {
+//  4. The handler rejected the request for exceeding a quota. The returned result will be nil and error will be a
+//     [QuotaExceededError].
+//
+//  5. Any other error.
+func (c *Client) StartOperation(ctx context.Context, operation string, input any, options StartOperationOptions, opts ...CallOption) (*ClientStartOperationResult[*LazyValue], error) {
+	if err := checkOperationName(operation); err != nil {
+		return nil, err
+	}
+	ctx, c = c.withCallOptions(ctx, opts)
+	c, err := c.withServiceOverrides(options)
+	if err != nil {
+		return nil, err
+	}
+	input, err = multipartValue(input, c.options.Serializer)
+	if err != nil {
+		return nil, err
+	}
 	var reader *Reader
+	var retained *Content
+	if r, ok := input.(io.Reader); ok {
+		if _, ok := r.(*Reader); !ok {
+			input = NewReader(r, nil)
+		}
+	}
 	if r, ok := input.(*Reader); ok {
 		// Close the input reader in case we error before sending the HTTP request (which may double close but
 		// that's fine since we ignore the error).
 		defer r.Close()
 		reader = r
+	} else if streaming, ok := c.options.Serializer.(StreamingSerializer); ok && isStreamingSerializable(input) {
+		// Serialize into a pooled buffer, which is returned to the pool once the request body is closed.
+		buf := getBuffer()
+		header, err := streaming.SerializeStream(buf, input)
+		if err != nil {
+			putBuffer(buf)
+			return nil, err
+		}
+		header = maps.Clone(header)
+		if header == nil {
+			header = Header{}
+		}
+		header["length"] = strconv.Itoa(buf.Len())
+		if err := c.options.SchemaRegistry.Validate(operation, PayloadKindInput, &Content{Header: header, Data: buf.Bytes()}); err != nil {
+			putBuffer(buf)
+			return nil, err
+		}
+		if err := setChecksum(header, c.options.Checksum, buf.Bytes()); err != nil {
+			putBuffer(buf)
+			return nil, err
+		}
+		if c.options.RetainInputs {
+			retained = &Content{Header: maps.Clone(header), Data: bytes.Clone(buf.Bytes())}
+		}
+		reader = &Reader{&pooledBody{buf: buf}, header}
 	} else {
 		content, ok := input.(*Content)
 		if !ok {
@@ -165,7 +596,19 @@ func (c *Client) StartOperation(ctx context.Context, operation string, input any
 			}
 		}
 		header := maps.Clone(content.Header)
+		if header == nil {
+			header = Header{}
+		}
 		header["length"] = strconv.Itoa(len(content.Data))
+		if err := c.options.SchemaRegistry.Validate(operation, PayloadKindInput, &Content{Header: header, Data: content.Data}); err != nil {
+			return nil, err
+		}
+		if err := setChecksum(header, c.options.Checksum, content.Data); err != nil {
+			return nil, err
+		}
+		if c.options.RetainInputs {
+			retained = &Content{Header: maps.Clone(header), Data: content.Data}
+		}
 
 		reader = &Reader{
 			io.NopCloser(bytes.NewReader(content.Data)),
@@ -173,27 +616,11 @@ func (c *Client) StartOperation(ctx context.Context, operation string, input any
 		}
 	}
 
-	url := c.serviceBaseURL.JoinPath(url.PathEscape(operation))
-
-	if options.CallbackURL != "" {
-		q := url.Query()
-		q.Set(queryCallbackURL, options.CallbackURL)
-		url.RawQuery = q.Encode()
-	}
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), reader)
+	request, err := newStartOperationHTTPRequest(ctx, c.options.PathBuilder.operationURL(c.serviceBaseURL, operation), reader, options)
 	if err != nil {
 		return nil, err
 	}
-
-	if options.RequestID == "" {
-		options.RequestID = uuid.NewString()
-	}
-	request.Header.Set(headerRequestID, options.RequestID)
-	request.Header.Set(headerUserAgent, userAgent)
-	addContentHeaderToHTTPHeader(reader.Header, request.Header)
-	addCallbackHeaderToHTTPHeader(options.CallbackHeader, request.Header)
-	addContextTimeoutToHTTPHeader(ctx, request.Header)
-	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	setAcceptHTTPHeader(c.options.Serializer, request.Header)
 
 	response, err := c.options.HTTPCaller(request)
 	if err != nil {
@@ -203,11 +630,10 @@ func (c *Client) StartOperation(ctx context.Context, operation string, input any
 	if response.StatusCode == http.StatusOK {
 		return &ClientStartOperationResult[*LazyValue]{
 			Successful: &LazyValue{
-				serializer: c.options.Serializer,
-				Reader: &Reader{
-					response.Body,
-					prefixStrippedHTTPHeaderToNexusHeader(response.Header, "content-"),
-				},
+				serializer:   c.options.Serializer,
+				transformers: c.options.ResultTransformers,
+				validate:     c.resultValidator(operation),
+				Reader:       readerFromHTTPResponse(response),
 			},
 		}, nil
 	}
@@ -220,27 +646,37 @@ func (c *Client) StartOperation(ctx context.Context, operation string, input any
 
 	switch response.StatusCode {
 	case http.StatusCreated:
-		info, err := operationInfoFromResponse(response, body)
+		info, err := operationInfoFromResponse(response, body, c.options.JSON)
 		if err != nil {
 			return nil, err
 		}
 		if info.State != OperationStateRunning {
-			return nil, newUnexpectedResponseError(fmt.Sprintf("invalid operation state in response info: %q", info.State), response, body)
+			return nil, newUnexpectedResponseError(fmt.Sprintf("invalid operation state in response info: %q", info.State), response, body, c.options.JSON)
 		}
 		return &ClientStartOperationResult[*LazyValue]{
 			Pending: &OperationHandle[*LazyValue]{
 				Operation: operation,
 				ID:        info.ID,
+				Links:     info.Links,
 				client:    c,
+				input:     retained,
+				polls:     newLongPolls(),
 			},
+			AlreadyStarted: response.Header.Get(HeaderOperationAlreadyStarted) == "true",
 		}, nil
-	case statusOperationFailed:
-		state, err := getUnsuccessfulStateFromHeader(response, body)
+	case http.StatusConflict:
+		if result := c.pendingFromConflictResponse(operation, response); result != nil {
+			result.Pending.input = retained
+			return result, nil
+		}
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	case StatusOperationFailed:
+		state, err := getUnsuccessfulStateFromHeader(response, body, c.options.JSON)
 		if err != nil {
 			return nil, err
 		}
 
-		failure, err := failureFromResponse(response, body)
+		failure, err := failureFromResponse(response, body, c.options.JSON)
 		if err != nil {
 			return nil, err
 		}
@@ -249,11 +685,42 @@ func (c *Client) StartOperation(ctx context.Context, operation string, input any
 			State:   state,
 			Failure: failure,
 		}
+	case http.StatusTooManyRequests:
+		if response.Header.Get(HeaderQuotaSubject) == "" {
+			return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+		}
+		quotaExceededError, err := quotaExceededErrorFromHTTPHeader(response.Header)
+		if err != nil {
+			return nil, newUnexpectedResponseError(err.Error(), response, body, c.options.JSON)
+		}
+		return nil, quotaExceededError
 	default:
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
 	}
 }
 
+var errServiceOverrideWithoutVariable = errors.New("service override requires a PathBuilder layout with the {service} variable")
+
+// withServiceOverrides returns a client sending requests to the service and base path given in the start options, if
+// any.
+func (c *Client) withServiceOverrides(options StartOperationOptions) (*Client, error) {
+	if options.Service == "" && options.ServiceBasePath == "" {
+		return c, nil
+	}
+	derived := *c
+	if options.Service != "" {
+		if !c.options.PathBuilder.hasServiceVariable() {
+			return nil, errServiceOverrideWithoutVariable
+		}
+		derived.options.PathBuilder.Service = options.Service
+	}
+	if options.ServiceBasePath != "" {
+		derived.serviceBaseURL = c.serviceBaseURL.JoinPath(options.ServiceBasePath)
+		derived.options.ServiceBaseURL = derived.serviceBaseURL.String()
+	}
+	return &derived, nil
+}
+
 // ExecuteOperationOptions are options for [Client.ExecuteOperation].
 type ExecuteOperationOptions struct {
 	// Callback URL to provide to the handle for receiving async operation completions. Optional.
@@ -263,9 +730,24 @@ type ExecuteOperationOptions struct {
 	// Optional header fields set by a client that are required to be attached to the callback request when an
 	// asynchronous operation completes.
 	CallbackHeader Header
+	// Optional callbacks to deliver completions to in addition to CallbackURL.
+	// See [StartOperationOptions.AdditionalCallbacks].
+	AdditionalCallbacks []Callback
 	// Request ID that may be used by the server handler to dedupe this start request.
 	// By default a v4 UUID will be generated by the client.
 	RequestID string
+	// Optional key/value tags to attach to the operation. See [StartOperationOptions.Tags].
+	Tags map[string]string
+	// Optional time by which the operation must complete. See [StartOperationOptions.Deadline].
+	Deadline time.Time
+	// Optional time before which the operation should not begin executing. See [StartOperationOptions.StartTime].
+	StartTime time.Time
+	// Optional delay before the operation should begin executing. See [StartOperationOptions.StartDelay].
+	StartDelay time.Duration
+	// Optional service name override. See [StartOperationOptions.Service].
+	Service string
+	// Optional service base path override. See [StartOperationOptions.ServiceBasePath].
+	ServiceBasePath string
 	// Header to attach to start and get-result requests. Optional.
 	//
 	// Header values set here will overwrite any SDK-provided values for the same key.
@@ -288,17 +770,28 @@ type ExecuteOperationOptions struct {
 // The wait time is capped to the deadline of the provided context. Make sure to handle both context deadline errors and
 // [ErrOperationStillRunning].
 //
+// Result requests rejected with a Retry-After header are retried after the requested delay, see
+// [OperationHandle.GetResult].
+//
 // Note that the wait period is enforced by the server and may not be respected if the server is misbehaving. Set the
 // context deadline to the max allowed wait period to ensure this call returns in a timely fashion.
 //
 // ⚠️ If this method completes successfully, the returned response's body must be read in its entirety and closed to
 // free up the underlying connection.
-func (c *Client) ExecuteOperation(ctx context.Context, operation string, input any, options ExecuteOperationOptions) (*LazyValue, error) {
+func (c *Client) ExecuteOperation(ctx context.Context, operation string, input any, options ExecuteOperationOptions, opts ...CallOption) (*LazyValue, error) {
+	ctx, c = c.withCallOptions(ctx, opts)
 	so := StartOperationOptions{
-		CallbackURL:    options.CallbackURL,
-		CallbackHeader: options.CallbackHeader,
-		RequestID:      options.RequestID,
-		Header:         options.Header,
+		CallbackURL:         options.CallbackURL,
+		CallbackHeader:      options.CallbackHeader,
+		AdditionalCallbacks: options.AdditionalCallbacks,
+		RequestID:           options.RequestID,
+		Tags:                options.Tags,
+		Deadline:            options.Deadline,
+		StartTime:           options.StartTime,
+		StartDelay:          options.StartDelay,
+		Service:             options.Service,
+		ServiceBasePath:     options.ServiceBasePath,
+		Header:              options.Header,
 	}
 	result, err := c.StartOperation(ctx, operation, input, so)
 	if err != nil {
@@ -321,11 +814,13 @@ func (c *Client) ExecuteOperation(ctx context.Context, operation string, input a
 
 // NewHandle gets a handle to an asynchronous operation by name and ID.
 // Does not incur a trip to the server.
-// Fails if provided an empty operation or ID.
+// Fails if provided an empty or reserved operation or an empty ID.
 func (c *Client) NewHandle(operation string, operationID string) (*OperationHandle[*LazyValue], error) {
 	var es []error
 	if operation == "" {
 		es = append(es, errEmptyOperationName)
+	} else if err := checkOperationName(operation); err != nil {
+		es = append(es, err)
 	}
 	if operationID == "" {
 		es = append(es, errEmptyOperationID)
@@ -337,6 +832,7 @@ func (c *Client) NewHandle(operation string, operationID string) (*OperationHand
 		client:    c,
 		Operation: operation,
 		ID:        operationID,
+		polls:     newLongPolls(),
 	}, nil
 }
 
@@ -345,40 +841,40 @@ func (c *Client) NewHandle(operation string, operationID string) (*OperationHand
 // The body is replaced even when there was an error reading the entire body.
 func readAndReplaceBody(response *http.Response) ([]byte, error) {
 	responseBody := response.Body
-	body, err := io.ReadAll(responseBody)
+	body, err := readAll(responseBody)
 	responseBody.Close()
 	response.Body = io.NopCloser(bytes.NewReader(body))
 	return body, err
 }
 
-func operationInfoFromResponse(response *http.Response, body []byte) (*OperationInfo, error) {
+func operationInfoFromResponse(response *http.Response, body []byte, engine JSONEngine) (*OperationInfo, error) {
 	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
-		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body)
+		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, engine)
 	}
 	var info OperationInfo
-	if err := json.Unmarshal(body, &info); err != nil {
+	if err := engine.unmarshal(body, &info); err != nil {
 		return nil, err
 	}
 	return &info, nil
 }
 
-func failureFromResponse(response *http.Response, body []byte) (Failure, error) {
+func failureFromResponse(response *http.Response, body []byte, engine JSONEngine) (Failure, error) {
 	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
-		return Failure{}, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body)
+		return Failure{}, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, engine)
 	}
 	var failure Failure
-	err := json.Unmarshal(body, &failure)
+	err := engine.unmarshal(body, &failure)
 	return failure, err
 }
 
-func getUnsuccessfulStateFromHeader(response *http.Response, body []byte) (OperationState, error) {
-	state := OperationState(response.Header.Get(headerOperationState))
+func getUnsuccessfulStateFromHeader(response *http.Response, body []byte, engine JSONEngine) (OperationState, error) {
+	state := OperationState(response.Header.Get(HeaderOperationState))
 	switch state {
 	case OperationStateCanceled:
 		return state, nil
 	case OperationStateFailed:
 		return state, nil
 	default:
-		return state, newUnexpectedResponseError(fmt.Sprintf("invalid operation state header: %q", state), response, body)
+		return state, newUnexpectedResponseError(fmt.Sprintf("invalid operation state header: %q", state), response, body, engine)
 	}
 }
@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Name of this SDK sent in the [HeaderClientName] header.
+const clientName = "nexus-go-sdk"
+
+// clientInfo identifies a client in the headers of its requests.
+type clientInfo struct {
+	name        string
+	version     string
+	application string
+}
+
+func newClientInfo(options ClientOptions) clientInfo {
+	info := clientInfo{name: clientName, version: version, application: options.UserAgent}
+	if options.ClientName != "" {
+		info.name, info.version = options.ClientName, options.ClientVersion
+	}
+	return info
+}
+
+// setHTTPHeader sets the client name and version headers unless already set, and appends the application identifier to
+// the request's User-Agent.
+func (i clientInfo) setHTTPHeader(header http.Header) {
+	if header.Get(HeaderClientName) == "" {
+		header.Set(HeaderClientName, i.name)
+		if i.version != "" {
+			header.Set(HeaderClientVersion, i.version)
+		}
+	}
+	if i.application != "" {
+		agent := header.Get(headerUserAgent)
+		if agent == "" {
+			agent = userAgent
+		}
+		// Requests may be sent more than once, e.g. when retried.
+		if !strings.HasSuffix(agent, " "+i.application) {
+			header.Set(headerUserAgent, agent+" "+i.application)
+		}
+	}
+}
+
+// clientInfoHTTPCaller wraps caller to identify the client in the headers of every request.
+func clientInfoHTTPCaller(caller func(*http.Request) (*http.Response, error), info clientInfo) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		info.setHTTPHeader(request.Header)
+		return caller(request)
+	}
+}
@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clientInfoEchoHandler responds to start requests with the client info from the request's [HandlerInfo].
+type clientInfoEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *clientInfoEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	info, _ := HandlerInfoFromContext(ctx)
+	return &HandlerStartOperationResultSync[any]{Value: []string{info.UserAgent, info.ClientName, info.ClientVersion}}, nil
+}
+
+func startClientInfo(ctx context.Context, t *testing.T, client *Client) []string {
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var info []string
+	require.NoError(t, result.Successful.Consume(&info))
+	return info
+}
+
+func TestClientInfo(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &clientInfoEchoHandler{}}, ClientOptions{})
+	defer teardown()
+	require.Equal(t, []string{userAgent, clientName, version}, startClientInfo(ctx, t, client))
+}
+
+func TestClientInfo_Custom(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &clientInfoEchoHandler{}}, ClientOptions{
+		UserAgent:     "billing/1.4.2",
+		ClientName:    "wrapper-sdk",
+		ClientVersion: "2.0.0",
+	})
+	defer teardown()
+	require.Equal(t, []string{userAgent + " billing/1.4.2", "wrapper-sdk", "2.0.0"}, startClientInfo(ctx, t, client))
+
+	// Clones inherit the client info.
+	clone, err := client.Clone(ClientOverrides{})
+	require.NoError(t, err)
+	require.Equal(t, []string{userAgent + " billing/1.4.2", "wrapper-sdk", "2.0.0"}, startClientInfo(ctx, t, clone))
+}
+
+func TestClientInfo_SetHTTPHeader(t *testing.T) {
+	info := newClientInfo(ClientOptions{UserAgent: "billing/1.4.2"})
+	header := http.Header{}
+	info.setHTTPHeader(header)
+	// Headers are set once for requests sent more than once.
+	info.setHTTPHeader(header)
+	require.Equal(t, userAgent+" billing/1.4.2", header.Get(headerUserAgent))
+	require.Equal(t, clientName, header.Get(HeaderClientName))
+	require.Equal(t, version, header.Get(HeaderClientVersion))
+
+	// Explicitly set client headers take precedence.
+	header = http.Header{HeaderClientName: []string{"explicit"}}
+	info.setHTTPHeader(header)
+	require.Equal(t, "explicit", header.Get(HeaderClientName))
+	require.Empty(t, header.Get(HeaderClientVersion))
+}
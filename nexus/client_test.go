@@ -1,8 +1,15 @@
 package nexus
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -26,3 +33,216 @@ func TestServiceBaseURL(t *testing.T) {
 	_, err = NewClient(ClientOptions{ServiceBaseURL: "https://example.com"})
 	require.NoError(t, err)
 }
+
+// tenantHandler echoes the tenant header of start requests as the operation result.
+func tenantHandler(t *testing.T) Handler {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			if operation == "block" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return options.Header["tenant"], nil
+		},
+	})
+	require.NoError(t, err)
+	return handler
+}
+
+func TestClientHeader(t *testing.T) {
+	header := Header{"tenant": "a"}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: tenantHandler(t)}, ClientOptions{Header: header})
+	defer teardown()
+	// Modifying the header after creating the client has no effect.
+	header["tenant"] = "b"
+
+	result, err := StartOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, StartOperationOptions{})
+	require.NoError(t, err)
+	tenant, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "a", tenant)
+
+	// Per-request header takes precedence.
+	result, err = StartOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, StartOperationOptions{
+		Header: Header{"tenant": "c"},
+	})
+	require.NoError(t, err)
+	tenant, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "c", tenant)
+}
+
+func TestClientClone(t *testing.T) {
+	var authorizations []string
+	var mu sync.Mutex
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: tenantHandler(t),
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			authorizations = append(authorizations, request.HTTPRequest.Header.Get(headerAuthorization))
+			return nil
+		}),
+	}, ClientOptions{
+		Header:       Header{"tenant": "a", "region": "us"},
+		AuthProvider: NewStaticTokenAuthProvider("original"),
+	})
+	defer teardown()
+
+	clone, err := client.Clone(ClientOverrides{
+		Header:       Header{"tenant": "b"},
+		AuthProvider: NewStaticTokenAuthProvider("clone"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, Header{"tenant": "a", "region": "us"}, client.options.Header)
+	require.Equal(t, Header{"tenant": "b", "region": "us"}, clone.options.Header)
+
+	for _, tc := range []struct {
+		client *Client
+		tenant string
+		token  string
+	}{{client, "a", "Bearer original"}, {clone, "b", "Bearer clone"}} {
+		result, err := StartOperation(ctx, tc.client, NewOperationReference[NoValue, string]("foo"), nil, StartOperationOptions{})
+		require.NoError(t, err)
+		tenant, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+		require.NoError(t, err)
+		require.Equal(t, tc.tenant, tenant)
+		mu.Lock()
+		require.Equal(t, tc.token, authorizations[len(authorizations)-1])
+		mu.Unlock()
+	}
+
+	_, err = client.Clone(ClientOverrides{ServiceBaseURL: "smtp://example.com"})
+	require.ErrorIs(t, err, errInvalidURLScheme)
+
+	other, err := client.Clone(ClientOverrides{ServiceBaseURL: "http://example.com/other"})
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/other", other.serviceBaseURL.String())
+}
+
+// TestClient_ConcurrentUse exercises a shared client, its handles, and clones from many goroutines. Run with -race to
+// verify that they are safe for concurrent use.
+func TestClient_ConcurrentUse(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          tenantHandler(t),
+		GetResultTimeout: getResultMaxTimeout,
+	}, ClientOptions{
+		Header:      Header{"tenant": "default"},
+		ResultCache: NewLRUResultCache(10),
+	})
+	defer teardown()
+
+	blocked, err := client.StartOperation(ctx, "block", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	shared := blocked.Pending
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- func() error {
+				tenant := fmt.Sprintf("tenant-%d", i)
+				clone, err := client.Clone(ClientOverrides{Header: Header{"tenant": tenant}})
+				if err != nil {
+					return err
+				}
+				for j := 0; j < 5; j++ {
+					result, err := StartOperation(ctx, clone, NewOperationReference[NoValue, string]("foo"), nil, StartOperationOptions{})
+					if err != nil {
+						return err
+					}
+					got, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+					if err != nil {
+						return err
+					}
+					if got != tenant {
+						return fmt.Errorf("expected result %q, got %q", tenant, got)
+					}
+					if _, err := shared.GetInfo(ctx, GetOperationInfoOptions{}); err != nil {
+						return err
+					}
+					if _, err := shared.GetResult(ctx, GetOperationResultOptions{Wait: time.Millisecond}); !errors.Is(err, ErrOperationStillRunning) {
+						return fmt.Errorf("expected operation to be running, got: %w", err)
+					}
+				}
+				return nil
+			}()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, shared.Cancel(ctx, CancelOperationOptions{}))
+	_, err = shared.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+}
+
+// serviceNameHandler starts operations with an ID of the name of the service it serves.
+type serviceNameHandler struct {
+	UnimplementedHandler
+	service string
+}
+
+func (h *serviceNameHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultAsync{OperationID: h.service}, nil
+}
+
+func (h *serviceNameHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	if operationID != h.service {
+		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation not found")
+	}
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func TestClient_ServiceOverrides(t *testing.T) {
+	paths := PathBuilder{Root: "/services/{service}", Operation: "/services/{service}/operations/{operation}"}
+	mux := http.NewServeMux()
+	for _, service := range []string{"billing", "shipping"} {
+		mux.Handle("/services/"+service+"/", NewHTTPHandler(HandlerOptions{
+			Handler:     &serviceNameHandler{service: service},
+			Service:     service,
+			PathBuilder: paths,
+		}))
+	}
+	mux.Handle("/v2/", http.StripPrefix("/v2", NewHTTPHandler(HandlerOptions{Handler: &serviceNameHandler{service: "v2"}})))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	paths.Service = "billing"
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL, PathBuilder: paths})
+	require.NoError(t, err)
+	for _, service := range []string{"", "shipping"} {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Service: service})
+		require.NoError(t, err)
+		if service == "" {
+			service = "billing"
+		}
+		require.Equal(t, service, result.Pending.ID)
+		// The handle targets the same service.
+		_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+	}
+
+	client, err = NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{Service: "shipping"})
+	require.ErrorIs(t, err, errServiceOverrideWithoutVariable)
+	// The result request reaches the handler served under the base path, which does not implement it.
+	_, err = client.ExecuteOperation(ctx, "foo", nil, ExecuteOperationOptions{ServiceBasePath: "/v2"})
+	require.ErrorContains(t, err, "501 Not Implemented")
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{ServiceBasePath: "/v2"})
+	require.NoError(t, err)
+	require.Equal(t, "v2", result.Pending.ID)
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+}
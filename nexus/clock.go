@@ -0,0 +1,86 @@
+package nexus
+
+import (
+	"context"
+	"time"
+)
+
+// A Clock is a source of time. Clients and handlers measure waits, backoffs and timeouts with the clock set in
+// [ClientOptions.Clock] and [HandlerOptions.Clock], allowing tests to control time with a fake clock, e.g.
+// [nexustest.FakeClock], instead of sleeping.
+//
+// [nexustest.FakeClock]: https://pkg.go.dev/github.com/nexus-rpc/sdk-go/nexus/nexustest#FakeClock
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a timer that fires once after the given duration, see [time.NewTimer].
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// A ClockTimer is a timer created by a [Clock].
+type ClockTimer interface {
+	// C returns the channel the current time is delivered on when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, see [time.Timer.Stop].
+	Stop() bool
+	// Reset changes the timer to fire after the given duration, see [time.Timer.Reset].
+	Reset(d time.Duration) bool
+}
+
+// SystemClock is the [Clock] backed by the time package, used unless another clock is configured.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) ClockTimer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type clockContextKey struct{}
+
+// ContextWithClock returns a copy of ctx carrying the given clock, used by functions that take no options, such as
+// [WaitForStartTime]. The handler returned from [NewHTTPHandler] attaches [HandlerOptions.Clock] to the context passed
+// to [Handler] methods.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockContextKey{}, clock)
+}
+
+// ClockFromContext returns the clock attached to ctx via [ContextWithClock], or [SystemClock] if none is attached.
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockContextKey{}).(Clock); ok && clock != nil {
+		return clock
+	}
+	return SystemClock
+}
+
+// contextWithClockTimeout is [context.WithTimeout] with the timeout measured by the given clock. Contexts timed out by
+// a clock other than [SystemClock] are canceled with [context.DeadlineExceeded] as their cause and do not report a
+// deadline.
+func contextWithClockTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if clock == SystemClock {
+		return context.WithTimeout(ctx, timeout)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := clock.NewTimer(timeout)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
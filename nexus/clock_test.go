@@ -0,0 +1,192 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a minimal fake [Clock] whose timers fire when advanced past.
+type testClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*testTimer
+}
+
+type testTimer struct {
+	clock *testClock
+	ch    chan time.Time
+	at    time.Time
+	done  bool
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) NewTimer(d time.Duration) ClockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &testTimer{clock: c, ch: make(chan time.Time, 1), at: c.now.Add(d)}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *testClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if !t.done {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		if !t.done && !t.at.After(c.now) {
+			t.done = true
+			t.ch <- c.now
+		}
+	}
+}
+
+func (t *testTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *testTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	stopped := !t.done
+	t.done = true
+	return stopped
+}
+
+func (t *testTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := !t.done
+	t.at, t.done = t.clock.now.Add(d), false
+	return active
+}
+
+func (c *testClock) waitForTimer(t *testing.T) {
+	require.Eventually(t, func() bool { return c.pending() > 0 }, time.Second, time.Millisecond)
+}
+
+func TestContextWithClockTimeout(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	ctx, cancel := contextWithClockTimeout(context.Background(), clock, time.Minute)
+	defer cancel()
+	clock.waitForTimer(t)
+	clock.advance(59 * time.Second)
+	require.NoError(t, ctx.Err())
+	clock.advance(time.Second)
+	<-ctx.Done()
+	require.ErrorIs(t, context.Cause(ctx), context.DeadlineExceeded)
+
+	ctx, cancel = contextWithClockTimeout(context.Background(), SystemClock, time.Minute)
+	defer cancel()
+	_, ok := ctx.Deadline()
+	require.True(t, ok)
+}
+
+func TestWaitForStartTime_Clock(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	ctx := ContextWithClock(context.Background(), clock)
+	require.Equal(t, Clock(clock), ClockFromContext(ctx))
+	require.Equal(t, SystemClock, ClockFromContext(context.Background()))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WaitForStartTime(ctx, clock.Now().Add(time.Hour))
+	}()
+	clock.waitForTimer(t)
+	clock.advance(time.Hour)
+	require.NoError(t, <-done)
+}
+
+type blockingResultHandler struct {
+	UnimplementedHandler
+	clocks chan Clock
+}
+
+func (h *blockingResultHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	h.clocks <- ClockFromContext(ctx)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHandlerOptions_Clock(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	handler := &blockingResultHandler{clocks: make(chan Clock, 1)}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          handler,
+		GetResultTimeout: time.Hour,
+		Clock:            clock,
+	}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: 2 * time.Hour, SinglePoll: true})
+		done <- err
+	}()
+	clock.waitForTimer(t)
+	clock.advance(time.Hour)
+	require.ErrorIs(t, <-done, ErrOperationWaitTimeout)
+	require.Equal(t, Clock(clock), <-handler.clocks)
+}
+
+func TestHandlerOptions_Clock_StartDelay(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	request, err := http.NewRequest("POST", "http://localhost/nexus/op", nil)
+	require.NoError(t, err)
+	request.Header.Set(HeaderOperationStartDelay, "1m")
+	options, err := StartOperationOptionsFromHTTPRequest(request.WithContext(ContextWithClock(request.Context(), clock)))
+	require.NoError(t, err)
+	require.Equal(t, clock.now.Add(time.Minute), options.StartTime)
+}
+
+func TestClientOptions_Clock(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	handler := &overloadedResultHandler{}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: handler,
+		ResponseHeaderProvider: func(ctx context.Context, statusCode int, err error) Header {
+			if statusCode == http.StatusServiceUnavailable {
+				return Header{"Retry-After": "60"}
+			}
+			return nil
+		},
+	}, ClientOptions{Clock: clock})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Hour})
+		done <- err
+	}()
+	// The client waits for the requested delay on its clock before polling again.
+	clock.waitForTimer(t)
+	require.Equal(t, int32(1), handler.requests.Load())
+	clock.advance(time.Minute)
+	require.NoError(t, <-done)
+	require.Equal(t, int32(2), handler.requests.Load())
+}
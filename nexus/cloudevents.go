@@ -0,0 +1,257 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	// Media type of CloudEvents in structured mode.
+	mediaTypeCloudEventsJSON = "application/cloudevents+json"
+	// Prefix of CloudEvents attribute headers in binary mode.
+	headerPrefixCloudEvents = "Ce-"
+)
+
+// CloudEvents types of operation completions, see [EncodeCompletionCloudEvent].
+const (
+	CloudEventTypeOperationSucceeded = "io.nexusrpc.operation.succeeded"
+	CloudEventTypeOperationFailed    = "io.nexusrpc.operation.failed"
+	CloudEventTypeOperationCanceled  = "io.nexusrpc.operation.canceled"
+)
+
+// CloudEvents extension attributes of operation completions, see [EncodeCompletionCloudEvent].
+const (
+	// ID of the completed operation.
+	CloudEventAttributeOperationID = "nexusoperationid"
+	// State of the completed operation.
+	CloudEventAttributeOperationState = "nexusoperationstate"
+	// Content headers of the result other than its type, URL query encoded, e.g. "encoding=gzip". Structured mode
+	// only, in binary mode these are sent as HTTP headers.
+	CloudEventAttributeContentHeader = "nexuscontentheader"
+)
+
+var cloudEventTypes = map[OperationState]string{
+	OperationStateSucceeded: CloudEventTypeOperationSucceeded,
+	OperationStateFailed:    CloudEventTypeOperationFailed,
+	OperationStateCanceled:  CloudEventTypeOperationCanceled,
+}
+
+// CloudEventsMode is the content mode of the CloudEvents HTTP protocol binding completions are encoded in.
+type CloudEventsMode int
+
+const (
+	// Event attributes are sent as "ce-" prefixed headers and the completion body as is.
+	CloudEventsModeBinary CloudEventsMode = iota
+	// The event, including its data, is sent as an application/cloudevents+json body.
+	CloudEventsModeStructured
+)
+
+// CloudEventsOptions are options for [EncodeCompletionCloudEvent].
+type CloudEventsOptions struct {
+	// Content mode of the encoded event. Defaults to binary.
+	Mode CloudEventsMode
+	// Source of events, a URI reference identifying the sender, e.g. "https://orders.example.com/nexus". Required.
+	Source string
+}
+
+// cloudEvent is a completion encoded as a CloudEvent in structured mode.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	OperationID     string          `json:"nexusoperationid,omitempty"`
+	OperationState  string          `json:"nexusoperationstate,omitempty"`
+	ContentHeader   string          `json:"nexuscontentheader,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+}
+
+// EncodeCompletionCloudEvent encodes a completion request created with [NewCompletionHTTPRequest] as a CloudEvent,
+// so that completions can flow through CloudEvents-native eventing infrastructure.
+//
+// The event's ID is the request's [HeaderRequestID], identical for retried deliveries, its type one of the
+// CloudEventType constants for the operation's state, and its subject the operation ID. The operation ID and state are
+// also set as the [CloudEventAttributeOperationID] and [CloudEventAttributeOperationState] extension attributes. The
+// event's data is the completion's result or failure.
+//
+// Nexus headers of the request are retained in both modes. Encode the request after setting all of its headers and
+// before signing it with [SignCompletionHTTPRequest]. Handlers created with [NewCompletionHTTPHandler] decode
+// completions sent as CloudEvents in either mode.
+func EncodeCompletionCloudEvent(request *http.Request, options CloudEventsOptions) error {
+	if options.Source == "" {
+		return errors.New("event source is required")
+	}
+	state := OperationState(request.Header.Get(HeaderOperationState))
+	eventType, ok := cloudEventTypes[state]
+	if !ok {
+		return fmt.Errorf("invalid completion operation state: %q", state)
+	}
+	id := request.Header.Get(HeaderRequestID)
+	if id == "" {
+		return errors.New("completion request has no request ID")
+	}
+	event := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          options.Source,
+		Type:            eventType,
+		Subject:         request.Header.Get(HeaderOperationID),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: request.Header.Get("Content-Type"),
+		OperationID:     request.Header.Get(HeaderOperationID),
+		OperationState:  string(state),
+	}
+	if options.Mode == CloudEventsModeBinary {
+		setCloudEventHTTPHeader(request.Header, "specversion", event.SpecVersion)
+		setCloudEventHTTPHeader(request.Header, "id", event.ID)
+		setCloudEventHTTPHeader(request.Header, "source", event.Source)
+		setCloudEventHTTPHeader(request.Header, "type", event.Type)
+		setCloudEventHTTPHeader(request.Header, "subject", event.Subject)
+		setCloudEventHTTPHeader(request.Header, "time", event.Time)
+		setCloudEventHTTPHeader(request.Header, CloudEventAttributeOperationID, event.OperationID)
+		setCloudEventHTTPHeader(request.Header, CloudEventAttributeOperationState, event.OperationState)
+		return nil
+	}
+
+	var data []byte
+	if request.Body != nil {
+		var err error
+		data, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read completion request body: %w", err)
+		}
+	}
+	if len(data) > 0 {
+		if isMediaTypeJSON(event.DataContentType) && json.Valid(data) {
+			event.Data = data
+		} else {
+			event.DataBase64 = data
+		}
+	}
+	contentHeader := url.Values{}
+	for k, v := range prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-") {
+		if k != "type" && k != "length" {
+			contentHeader.Set(k, v)
+		}
+	}
+	event.ContentHeader = contentHeader.Encode()
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for k := range request.Header {
+		if strings.HasPrefix(k, HeaderPrefixContent) {
+			request.Header.Del(k)
+		}
+	}
+	request.Header.Set("Content-Type", mediaTypeCloudEventsJSON)
+	request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.ContentLength = int64(len(body))
+	return nil
+}
+
+func setCloudEventHTTPHeader(header http.Header, attribute, value string) {
+	if value != "" {
+		header.Set(headerPrefixCloudEvents+attribute, value)
+	}
+}
+
+// isCloudEventHTTPRequest reports whether a completion request was encoded as a CloudEvent in either mode.
+func isCloudEventHTTPRequest(request *http.Request) bool {
+	if request.Header.Get(headerPrefixCloudEvents+"specversion") != "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	return err == nil && mediaType == mediaTypeCloudEventsJSON
+}
+
+// decodeCloudEventHTTPRequest decodes a completion request encoded as a CloudEvent in place, setting the Nexus headers
+// and body of the equivalent completion request from the event unless already set.
+func decodeCloudEventHTTPRequest(request *http.Request) error {
+	header := request.Header
+	var event cloudEvent
+	if header.Get(headerPrefixCloudEvents+"specversion") != "" {
+		event = cloudEvent{
+			SpecVersion:    header.Get(headerPrefixCloudEvents + "specversion"),
+			ID:             header.Get(headerPrefixCloudEvents + "id"),
+			Type:           header.Get(headerPrefixCloudEvents + "type"),
+			Subject:        header.Get(headerPrefixCloudEvents + "subject"),
+			OperationID:    header.Get(headerPrefixCloudEvents + CloudEventAttributeOperationID),
+			OperationState: header.Get(headerPrefixCloudEvents + CloudEventAttributeOperationState),
+		}
+	} else {
+		b, err := io.ReadAll(request.Body)
+		if err != nil {
+			return requestBodyError(err, "failed to read CloudEvent from request body")
+		}
+		if err := json.Unmarshal(b, &event); err != nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid CloudEvent")
+		}
+		data := []byte(event.Data)
+		if event.DataBase64 != nil {
+			data = event.DataBase64
+		} else if len(data) > 0 && data[0] == '"' && !isMediaTypeJSON(event.DataContentType) {
+			// Non JSON data may be encoded as a JSON string.
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid CloudEvent data")
+			}
+			data = []byte(s)
+		}
+		contentHeader, err := url.ParseQuery(event.ContentHeader)
+		if err != nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid CloudEvent %s attribute", CloudEventAttributeContentHeader)
+		}
+		header.Del("Content-Type")
+		for k := range contentHeader {
+			header.Set(HeaderPrefixContent+k, contentHeader.Get(k))
+		}
+		if event.DataContentType != "" {
+			header.Set("Content-Type", event.DataContentType)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(data)))
+		request.Body = io.NopCloser(bytes.NewReader(data))
+		request.ContentLength = int64(len(data))
+	}
+	if event.SpecVersion != cloudEventsSpecVersion {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "unsupported CloudEvents spec version: %q", event.SpecVersion)
+	}
+	state := event.OperationState
+	if state == "" {
+		for s, t := range cloudEventTypes {
+			if t == event.Type {
+				state = string(s)
+			}
+		}
+	}
+	operationID := event.OperationID
+	if operationID == "" {
+		operationID = event.Subject
+	}
+	setHTTPHeaderIfUnset(header, HeaderOperationState, state)
+	setHTTPHeaderIfUnset(header, HeaderOperationID, operationID)
+	setHTTPHeaderIfUnset(header, HeaderRequestID, event.ID)
+	return nil
+}
+
+func setHTTPHeaderIfUnset(header http.Header, key, value string) {
+	if value != "" && header.Get(key) == "" {
+		header.Set(key, value)
+	}
+}
@@ -0,0 +1,142 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveCompletion serves a completion request with a completion handler recording the received completion and its
+// consumed result.
+func serveCompletion(t *testing.T, request *http.Request, options CompletionHandlerOptions) (*httptest.ResponseRecorder, *CompletionRequest, []byte) {
+	var received *CompletionRequest
+	var result []byte
+	options.Handler = completionHandlerFunc(func(ctx context.Context, completion *CompletionRequest) error {
+		received = completion
+		if completion.Result != nil {
+			return completion.Result.Consume(&result)
+		}
+		return nil
+	})
+	recorder := httptest.NewRecorder()
+	NewCompletionHTTPHandler(options).ServeHTTP(recorder, request)
+	return recorder, received, result
+}
+
+func TestCloudEvents_Binary(t *testing.T) {
+	ctx := context.Background()
+	completion, err := NewOperationCompletionSuccessful([]byte("hello"), OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	completion.OperationID = "op-1"
+	request, err := NewCompletionHTTPRequest(ctx, "http://localhost/callback", completion)
+	require.NoError(t, err)
+	require.NoError(t, EncodeCompletionCloudEvent(request, CloudEventsOptions{Source: "https://handler.example.com"}))
+
+	require.Equal(t, "1.0", request.Header.Get("Ce-Specversion"))
+	require.Equal(t, request.Header.Get(HeaderRequestID), request.Header.Get("Ce-Id"))
+	require.Equal(t, "https://handler.example.com", request.Header.Get("Ce-Source"))
+	require.Equal(t, CloudEventTypeOperationSucceeded, request.Header.Get("Ce-Type"))
+	require.Equal(t, "op-1", request.Header.Get("Ce-Subject"))
+	require.Equal(t, "op-1", request.Header.Get("Ce-Nexusoperationid"))
+	require.Equal(t, "succeeded", request.Header.Get("Ce-Nexusoperationstate"))
+	require.NotEmpty(t, request.Header.Get("Ce-Time"))
+
+	recorder, received, result := serveCompletion(t, request, CompletionHandlerOptions{})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, OperationStateSucceeded, received.State)
+	require.Equal(t, "op-1", received.OperationID)
+	require.Equal(t, []byte("hello"), result)
+}
+
+func TestCloudEvents_Structured(t *testing.T) {
+	ctx := context.Background()
+	transformers := []ContentTransformer{NewGzipTransformer(0)}
+	completion, err := NewOperationCompletionSuccessful([]byte("hello"), OperationCompletionSuccesfulOptions{
+		Transformers: transformers,
+	})
+	require.NoError(t, err)
+	completion.OperationID = "op-1"
+	request, err := NewCompletionHTTPRequest(ctx, "http://localhost/callback", completion)
+	require.NoError(t, err)
+	require.NoError(t, EncodeCompletionCloudEvent(request, CloudEventsOptions{
+		Mode:   CloudEventsModeStructured,
+		Source: "https://handler.example.com",
+	}))
+	signer := NewHMACCompletionSigner([]byte("secret"))
+	require.NoError(t, SignCompletionHTTPRequest(request, signer))
+
+	require.Equal(t, "application/cloudevents+json", request.Header.Get("Content-Type"))
+	require.Empty(t, request.Header.Get("Content-Encoding"))
+
+	recorder, received, result := serveCompletion(t, request, CompletionHandlerOptions{
+		Transformers: transformers,
+		Verifier:     NewHMACCompletionVerifier([]byte("secret")),
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, OperationStateSucceeded, received.State)
+	require.Equal(t, "op-1", received.OperationID)
+	require.Equal(t, []byte("hello"), result)
+}
+
+func TestCloudEvents_StructuredJSONData(t *testing.T) {
+	ctx := context.Background()
+	request, err := NewCompletionHTTPRequest(ctx, "http://localhost/callback", &OperationCompletionUnsuccessful{
+		OperationID: "op-1",
+		State:       OperationStateFailed,
+		Failure:     &Failure{Message: "oops"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, EncodeCompletionCloudEvent(request, CloudEventsOptions{
+		Mode:   CloudEventsModeStructured,
+		Source: "https://handler.example.com",
+	}))
+	var event map[string]any
+	require.NoError(t, json.NewDecoder(request.Body).Decode(&event))
+	require.Equal(t, CloudEventTypeOperationFailed, event["type"])
+	require.Equal(t, "application/json", event["datacontenttype"])
+	require.Equal(t, map[string]any{"message": "oops"}, event["data"])
+}
+
+func TestCloudEvents_ThirdPartyEvent(t *testing.T) {
+	request := httptest.NewRequest("POST", "/callback", strings.NewReader(`{
+		"specversion": "1.0",
+		"id": "event-1",
+		"source": "https://broker.example.com",
+		"type": "io.nexusrpc.operation.canceled",
+		"subject": "op-1",
+		"datacontenttype": "application/json",
+		"data": {"message": "canceled"}
+	}`))
+	request.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+	recorder, received, _ := serveCompletion(t, request, CompletionHandlerOptions{})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, OperationStateCanceled, received.State)
+	require.Equal(t, "op-1", received.OperationID)
+	require.Equal(t, "event-1", received.RequestID)
+	require.Equal(t, "canceled", received.Failure.Message)
+
+	request = httptest.NewRequest("POST", "/callback", strings.NewReader(`{"specversion": "0.3"}`))
+	request.Header.Set("Content-Type", "application/cloudevents+json")
+	recorder, _, _ = serveCompletion(t, request, CompletionHandlerOptions{})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestEncodeCompletionCloudEvent_Invalid(t *testing.T) {
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", &OperationCompletionUnsuccessful{State: OperationStateFailed})
+	require.NoError(t, err)
+	require.ErrorContains(t, EncodeCompletionCloudEvent(request, CloudEventsOptions{}), "source is required")
+	request.Header.Set(HeaderOperationState, "running")
+	require.ErrorContains(t, EncodeCompletionCloudEvent(request, CloudEventsOptions{Source: "x"}), "invalid completion operation state")
+
+	_, err = NewAsyncHandler(AsyncHandlerOptions{
+		Store:            NewMemoryOperationStore(),
+		Executor:         func(context.Context, string, *LazyValue, StartOperationOptions) (any, error) { return nil, nil },
+		CallbackDelivery: &CallbackDeliveryOptions{CloudEvents: &CloudEventsOptions{}},
+	})
+	require.ErrorContains(t, err, "CloudEvents source is required")
+}
@@ -0,0 +1,146 @@
+package nexus
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+)
+
+type payloadCodec struct {
+	serializer   Serializer
+	transformers []ContentTransformer
+}
+
+// NewPayloadCodec wraps a [Serializer] with [ContentTransformer]s, e.g. to compress or encrypt payloads. Transformers
+// are applied in order to serialized content and in reverse order to content before it is deserialized, recording
+// what they did in the content's [Header].
+//
+// Since the client, handler, completion and [AsyncHandler] paths all serialize values with their configured
+// serializer, setting the returned serializer on [ClientOptions], [HandlerOptions], [CompletionHandlerOptions],
+// [OperationCompletionSuccesfulOptions] and [AsyncHandlerOptions] applies the transformers symmetrically, both in
+// transit and to results at rest in an [OperationStore]. If serializer is nil, the SDK's default serializer is
+// wrapped.
+//
+// Raw [Reader] and [Content] values bypass serializers and are not transformed. Transformed content is decoded in
+// memory, so [LazyValue.ConsumeStream] falls back to buffering and [ConsumeElements] cannot decode it.
+func NewPayloadCodec(serializer Serializer, transformers ...ContentTransformer) Serializer {
+	if serializer == nil {
+		serializer = defaultSerializer
+	}
+	return payloadCodec{serializer: serializer, transformers: transformers}
+}
+
+// Serialize implements Serializer.
+func (c payloadCodec) Serialize(v any) (*Content, error) {
+	content, err := c.serializer.Serialize(v)
+	if err != nil {
+		return nil, err
+	}
+	return encodeContent(c.transformers, content)
+}
+
+// Deserialize implements Serializer.
+func (c payloadCodec) Deserialize(content *Content, v any) error {
+	content, err := decodeContent(c.transformers, content)
+	if err != nil {
+		return err
+	}
+	return c.serializer.Deserialize(content, v)
+}
+
+var _ Serializer = payloadCodec{}
+
+// Header value of the "encryption" content header marking content encrypted by an encryption transformer.
+const aesGCMEncryption = "aes-gcm"
+
+// EncryptionKeys is a key ring for [NewEncryptionTransformer].
+//
+// To rotate keys, add a new key and make it active. Keep retired keys in the ring for as long as content encrypted with
+// them may still be received or stored.
+type EncryptionKeys struct {
+	// ID of the key used to encrypt content. Required.
+	ActiveKeyID string
+	// AES keys by ID. Keys must be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+	Keys map[string][]byte
+}
+
+type encryptionTransformer struct {
+	activeKeyID string
+	ciphers     map[string]cipher.AEAD
+}
+
+// NewEncryptionTransformer creates a [ContentTransformer] that encrypts content with AES-GCM using the active key of
+// the given key ring, recording the key's ID in the "encryption-key" content header. Content is decrypted with the key
+// it was encrypted with, allowing keys to be rotated. Unencrypted content is passed through on decode.
+//
+// Use it with [NewPayloadCodec] to encrypt payloads in transit and at rest.
+func NewEncryptionTransformer(keys EncryptionKeys) (ContentTransformer, error) {
+	if _, ok := keys.Keys[keys.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("active encryption key not found: %q", keys.ActiveKeyID)
+	}
+	ciphers := make(map[string]cipher.AEAD, len(keys.Keys))
+	for id, key := range keys.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ciphers[id] = aead
+	}
+	return encryptionTransformer{activeKeyID: keys.ActiveKeyID, ciphers: ciphers}, nil
+}
+
+// Encode implements ContentTransformer.
+func (t encryptionTransformer) Encode(content *Content) (*Content, error) {
+	aead := t.ciphers[t.activeKeyID]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(content.Data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header := maps.Clone(content.Header)
+	if header == nil {
+		header = Header{}
+	}
+	delete(header, "length")
+	header["encryption"] = aesGCMEncryption
+	header["encryption-key"] = t.activeKeyID
+	// Bind the key ID to the ciphertext.
+	data := aead.Seal(nonce, nonce, content.Data, []byte(t.activeKeyID))
+	return &Content{Header: header, Data: data}, nil
+}
+
+// Decode implements ContentTransformer.
+func (t encryptionTransformer) Decode(content *Content) (*Content, error) {
+	switch content.Header["encryption"] {
+	case "":
+		return content, nil
+	case aesGCMEncryption:
+	default:
+		return nil, fmt.Errorf("unsupported content encryption: %q", content.Header["encryption"])
+	}
+	keyID := content.Header["encryption-key"]
+	aead, ok := t.ciphers[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key: %q", keyID)
+	}
+	if len(content.Data) < aead.NonceSize() {
+		return nil, errors.New("encrypted content too short")
+	}
+	nonce, ciphertext := content.Data[:aead.NonceSize()], content.Data[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	header := maps.Clone(content.Header)
+	delete(header, "length")
+	delete(header, "encryption")
+	delete(header, "encryption-key")
+	return &Content{Header: header, Data: data}, nil
+}
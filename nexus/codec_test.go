@@ -0,0 +1,92 @@
+package nexus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryptionTransformer(t *testing.T, activeKeyID string) ContentTransformer {
+	transformer, err := NewEncryptionTransformer(EncryptionKeys{
+		ActiveKeyID: activeKeyID,
+		Keys: map[string][]byte{
+			"old": bytes.Repeat([]byte{1}, 16),
+			"new": bytes.Repeat([]byte{2}, 32),
+		},
+	})
+	require.NoError(t, err)
+	return transformer
+}
+
+func TestPayloadCodec_RoundTrip(t *testing.T) {
+	registry := OperationRegistry{}
+	require.NoError(t, registry.Register(
+		numberValidatorOperation,
+		asyncNumberValidatorOperationInstance,
+	))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	codec := NewPayloadCodec(nil, xorTransformer{}, newTestEncryptionTransformer(t, "new"))
+	ctx, client, teardown := setupSerializer(t, handler, codec)
+	defer teardown()
+
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	result, err = ExecuteOperation(ctx, client, asyncNumberValidatorOperationInstance, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+}
+
+func TestPayloadCodec_Headers(t *testing.T) {
+	codec := NewPayloadCodec(nil, newTestEncryptionTransformer(t, "new"))
+	content, err := codec.Serialize(map[string]string{"secret": "value"})
+	require.NoError(t, err)
+	require.Equal(t, Header{"type": "application/json", "encryption": "aes-gcm", "encryption-key": "new"}, content.Header)
+	require.NotContains(t, string(content.Data), "secret")
+
+	var v map[string]string
+	require.NoError(t, codec.Deserialize(content, &v))
+	require.Equal(t, map[string]string{"secret": "value"}, v)
+
+	// Unencrypted content is passed through.
+	content, err = defaultSerializer.Serialize("plain")
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, codec.Deserialize(content, &s))
+	require.Equal(t, "plain", s)
+}
+
+func TestEncryptionTransformer_KeyRotation(t *testing.T) {
+	content, err := newTestEncryptionTransformer(t, "old").Encode(&Content{Header: Header{"type": "text/plain"}, Data: []byte("secret")})
+	require.NoError(t, err)
+	require.Equal(t, "old", content.Header["encryption-key"])
+
+	rotated := newTestEncryptionTransformer(t, "new")
+	decoded, err := rotated.Decode(content)
+	require.NoError(t, err)
+	require.Equal(t, &Content{Header: Header{"type": "text/plain"}, Data: []byte("secret")}, decoded)
+
+	retired, err := NewEncryptionTransformer(EncryptionKeys{
+		ActiveKeyID: "new",
+		Keys:        map[string][]byte{"new": bytes.Repeat([]byte{2}, 32)},
+	})
+	require.NoError(t, err)
+	_, err = retired.Decode(content)
+	require.ErrorContains(t, err, `unknown encryption key: "old"`)
+
+	content.Header["encryption-key"] = "new"
+	_, err = rotated.Decode(content)
+	require.ErrorContains(t, err, "failed to decrypt content")
+}
+
+func TestNewEncryptionTransformer_Validation(t *testing.T) {
+	_, err := NewEncryptionTransformer(EncryptionKeys{ActiveKeyID: "missing"})
+	require.ErrorContains(t, err, `active encryption key not found: "missing"`)
+
+	_, err = NewEncryptionTransformer(EncryptionKeys{ActiveKeyID: "a", Keys: map[string][]byte{"a": []byte("short")}})
+	require.ErrorContains(t, err, `invalid encryption key "a"`)
+}
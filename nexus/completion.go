@@ -3,14 +3,21 @@ package nexus
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"strconv"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // NewCompletionHTTPRequest creates an HTTP request deliver an operation completion to a given URL.
+//
+// Unless set in the completion's header, a v4 UUID is generated as the request's [HeaderRequestID], which receivers
+// may use to dedupe retried deliveries. Reuse the request, or set the same ID when retrying a delivery.
 func NewCompletionHTTPRequest(ctx context.Context, url string, completion OperationCompletion) (*http.Request, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
@@ -19,8 +26,15 @@ func NewCompletionHTTPRequest(ctx context.Context, url string, completion Operat
 	if err := completion.applyToHTTPRequest(httpReq); err != nil {
 		return nil, err
 	}
+	if httpReq.Header.Get(HeaderRequestID) == "" {
+		httpReq.Header.Set(HeaderRequestID, uuid.NewString())
+	}
 
 	httpReq.Header.Set(headerUserAgent, userAgent)
+	if httpReq.Header.Get(HeaderClientName) == "" {
+		httpReq.Header.Set(HeaderClientName, clientName)
+		httpReq.Header.Set(HeaderClientVersion, version)
+	}
 	return httpReq, nil
 }
 
@@ -34,6 +48,8 @@ type OperationCompletion interface {
 type OperationCompletionSuccessful struct {
 	// Header to send in the completion request.
 	Header http.Header
+	// Optional ID of the completed operation, for receivers to identify and dedupe completions.
+	OperationID string
 	// Body to send in the completion HTTP request.
 	// If it implements `io.Closer` it will automatically be closed by the client.
 	Body io.Reader
@@ -44,10 +60,33 @@ type OperationCompletionSuccesfulOptions struct {
 	// Optional serializer for the result. Defaults to the SDK's default Serializer, which handles JSONables, byte
 	// slices and nils.
 	Serializer Serializer
+	// Optional transformers applied in order to the serialized result, e.g. for compression or encryption.
+	// The receiving [CompletionHandlerOptions.Transformers] must be able to reverse them.
+	Transformers []ContentTransformer
+	// Optional algorithm of a checksum of the result sent in the "digest" content header, i.e. the HTTP
+	// Content-Digest header, letting the completion handler detect results corrupted in transit. Results given as a
+	// [Reader] are sent without a checksum unless their header has a digest or Transformers are set.
+	Checksum ChecksumAlgorithm
 }
 
 // NewOperationCompletionSuccessful constructs an [OperationCompletionSuccessful] from a given result.
 func NewOperationCompletionSuccessful(result any, options OperationCompletionSuccesfulOptions) (*OperationCompletionSuccessful, error) {
+	if err := options.Checksum.validate(); err != nil {
+		return nil, err
+	}
+	result, err := multipartValue(result, options.Serializer)
+	if err != nil {
+		return nil, err
+	}
+	if reader, ok := result.(*Reader); ok && len(options.Transformers) > 0 {
+		// Transformers operate on content in memory.
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		result = &Content{Header: reader.Header, Data: data}
+	}
 	if reader, ok := result.(*Reader); ok {
 		return &OperationCompletionSuccessful{
 			Header: addContentHeaderToHTTPHeader(reader.Header, make(http.Header)),
@@ -66,6 +105,20 @@ func NewOperationCompletionSuccessful(result any, options OperationCompletionSuc
 				return nil, err
 			}
 		}
+		content, err := encodeContent(options.Transformers, content)
+		if err != nil {
+			return nil, err
+		}
+		if options.Checksum != "" && content.Header.Get("digest") == "" {
+			header := maps.Clone(content.Header)
+			if header == nil {
+				header = Header{}
+			}
+			if err := setChecksum(header, options.Checksum, content.Data); err != nil {
+				return nil, err
+			}
+			content = &Content{Header: header, Data: content.Data}
+		}
 		header := http.Header{"Content-Length": []string{strconv.Itoa(len(content.Data))}}
 
 		return &OperationCompletionSuccessful{
@@ -79,12 +132,21 @@ func (c *OperationCompletionSuccessful) applyToHTTPRequest(request *http.Request
 	if c.Header != nil {
 		request.Header = c.Header.Clone()
 	}
-	request.Header.Set(headerOperationState, string(OperationStateSucceeded))
+	request.Header.Set(HeaderOperationState, string(OperationStateSucceeded))
+	if c.OperationID != "" {
+		request.Header.Set(HeaderOperationID, c.OperationID)
+	}
 	if closer, ok := c.Body.(io.ReadCloser); ok {
 		request.Body = closer
 	} else {
 		request.Body = io.NopCloser(c.Body)
 	}
+	// The Content-Length header is ignored by HTTP clients, bodies of unknown length are sent with chunked transfer
+	// encoding.
+	request.ContentLength = -1
+	if n, err := strconv.ParseInt(request.Header.Get("Content-Length"), 10, 64); err == nil && n >= 0 {
+		request.ContentLength = n
+	}
 	return nil
 }
 
@@ -93,25 +155,44 @@ func (c *OperationCompletionSuccessful) applyToHTTPRequest(request *http.Request
 type OperationCompletionUnsuccessful struct {
 	// Header to send in the completion request.
 	Header http.Header
+	// Optional ID of the completed operation, for receivers to identify and dedupe completions.
+	OperationID string
 	// State of the operation, should be failed or canceled.
 	State OperationState
 	// Failure object to send with the completion.
 	Failure *Failure
+	// Optional converter for encoding the failure before it is sent, see [FailureConverter].
+	FailureConverter FailureConverter
+	// Optional JSON implementation for encoding the failure. Defaults to encoding/json.
+	JSON JSONEngine
 }
 
 func (c *OperationCompletionUnsuccessful) applyToHTTPRequest(request *http.Request) error {
 	if c.Header != nil {
 		request.Header = c.Header.Clone()
 	}
-	request.Header.Set(headerOperationState, string(c.State))
+	request.Header.Set(HeaderOperationState, string(c.State))
+	if c.OperationID != "" {
+		request.Header.Set(HeaderOperationID, c.OperationID)
+	}
 	request.Header.Set("Content-Type", contentTypeJSON)
 
-	b, err := json.Marshal(c.Failure)
+	failure := c.Failure
+	if c.FailureConverter != nil && failure != nil {
+		encoded, err := c.FailureConverter.EncodeFailure(*failure)
+		if err != nil {
+			return fmt.Errorf("failed to encode failure: %w", err)
+		}
+		failure = &encoded
+	}
+	b, err := c.JSON.marshal(failure)
 	if err != nil {
 		return err
 	}
 
+	request.Header.Set("Content-Length", strconv.Itoa(len(b)))
 	request.Body = io.NopCloser(bytes.NewReader(b))
+	request.ContentLength = int64(len(b))
 	return nil
 }
 
@@ -119,6 +200,16 @@ func (c *OperationCompletionUnsuccessful) applyToHTTPRequest(request *http.Reque
 type CompletionRequest struct {
 	// The original HTTP request.
 	HTTPRequest *http.Request
+	// Identity of the sender asserted in the [HeaderCallerIdentity] header, if any. The identity is not authenticated.
+	CallerIdentity string
+	// User-Agent of the sender.
+	UserAgent string
+	// Name and version of the sender's client library, sent in the [HeaderClientName] and [HeaderClientVersion] headers.
+	ClientName, ClientVersion string
+	// Request ID of the completion, identical for retried deliveries of the same completion, for deduping them.
+	RequestID string
+	// ID of the completed operation, if provided by the sender.
+	OperationID string
 	// State of the operation.
 	State OperationState
 	// Parsed from request and set if State is failed or canceled.
@@ -139,22 +230,103 @@ type CompletionHandlerOptions struct {
 	Handler CompletionHandler
 	// A stuctured logging handler.
 	// Defaults to slog.Default().
+	//
+	// Log lines of a request carry request scoped attributes: the completion state and the remote address.
 	Logger *slog.Logger
+	// Optional provider of per request loggers, e.g. to attach correlation IDs extracted from request headers, see
+	// [NewHeaderLoggerProvider].
+	LoggerProvider LoggerProvider
+	// Determines which requests a summary line with the response status and latency is logged for.
+	// Defaults to failed requests only.
+	RequestLogVerbosity RequestLogVerbosity
 	// A [Serializer] to customize handler serialization behavior.
 	// By default the handler handles, JSONables, byte slices, and nil.
 	Serializer Serializer
+	// JSON implementation for the default serializer and for decoding failures. Defaults to encoding/json.
+	JSON JSONEngine
+	// Optional transformers reversed on received results in reverse order, matching the transformers used by the
+	// sender, see [OperationCompletionSuccesfulOptions.Transformers].
+	Transformers []ContentTransformer
+	// Optional verifier of completion request signatures. When set, completion requests without a valid signature,
+	// see [SignCompletionHTTPRequest], are rejected with 401 before the Handler is invoked.
+	Verifier CompletionVerifier
+	// Maximum age of accepted completion signatures, limiting the window for replaying signed requests.
+	// Defaults to 5 minutes.
+	MaxSignatureAge time.Duration
+	// Optional converter for decoding failures of received completions and encoding failures the handler responds
+	// with, see [FailureConverter].
+	FailureConverter FailureConverter
+	// Maximum size of completion request bodies in bytes. Larger requests are failed with 413 Request Entity Too Large.
+	// Zero means no limit.
+	MaxRequestBodySize int64
+	// Panics of the Handler are recovered, logged with their stack and fail the request with a 500 [HandlerError].
+	// OnPanic is an optional function called with the recovered value and stack, e.g. to record a metric.
+	OnPanic func(ctx context.Context, value any, stack []byte)
+	// Include the panic value, stripped of control characters and truncated, in the failure of requests failed due to a
+	// panic. By default the failure message is a generic "internal server error".
+	ExposePanicDetails bool
+	// Optional provider of header fields attached to every response, including failures. See
+	// [ResponseHeaderProvider].
+	ResponseHeaderProvider ResponseHeaderProvider
+	// Optional sink recording an audit event for every completion, with the sender's identity, the operation ID and
+	// state, the outcome and timing. See [AuditSink].
+	AuditSink AuditSink
+	// Optional store of processed completions for suppressing duplicate deliveries. When set, the Handler is invoked
+	// at most once per callback URL path and query, operation ID and state, or request ID for completions without an
+	// operation ID, as long as the store retains the completion. Since senders choose these IDs, set a Verifier so that
+	// only trusted senders can record completions. Duplicates are responded to with success without invoking the
+	// Handler. Completions are recorded once the Handler succeeds, so failed completions are processed again when
+	// retried.
+	DedupeStore CompletionDedupeStore
 }
 
 type completionHTTPHandler struct {
 	baseHTTPHandler
 	options CompletionHandlerOptions
+	deduper *completionDeduper
 }
 
 func (h *completionHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	rh := *h
+	writer, done := rh.startRequestLog(writer, request, "state", request.Header.Get(HeaderOperationState))
+	defer done()
+	defer rh.startAudit(writer, request, HandlerMethodCompleteOperation)()
+	defer rh.provideResponseHeaders(request.Context(), writer)()
+	if request.Method != "POST" {
+		writer.Header().Set("Allow", "POST")
+		rh.writeFailure(writer, errMethodNotAllowed)
+		return
+	}
+	if !rh.limitRequestBody(writer, request, h.options.MaxRequestBodySize) {
+		return
+	}
+	defer rh.recoverPanic(request.Context(), writer)
+	rh.serveHTTP(writer, request)
+}
+
+func (h *completionHTTPHandler) serveHTTP(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
+	if h.options.Verifier != nil {
+		if err := h.verifyCompletionSignature(request); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+	}
+	if isCloudEventHTTPRequest(request) {
+		if err := decodeCloudEventHTTPRequest(request); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+	}
 	completion := CompletionRequest{
-		State:       OperationState(request.Header.Get(headerOperationState)),
-		HTTPRequest: request,
+		State:          OperationState(request.Header.Get(HeaderOperationState)),
+		HTTPRequest:    request,
+		CallerIdentity: request.Header.Get(HeaderCallerIdentity),
+		UserAgent:      request.Header.Get(headerUserAgent),
+		ClientName:     request.Header.Get(HeaderClientName),
+		ClientVersion:  request.Header.Get(HeaderClientVersion),
+		RequestID:      request.Header.Get(HeaderRequestID),
+		OperationID:    request.Header.Get(HeaderOperationID),
 	}
 	switch completion.State {
 	case OperationStateFailed, OperationStateCanceled:
@@ -163,45 +335,93 @@ func (h *completionHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *h
 			return
 		}
 		var failure Failure
-		b, err := io.ReadAll(request.Body)
+		b, err := readAll(request.Body)
 		if err != nil {
-			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read Failure from request body"))
+			h.writeFailure(writer, requestBodyError(err, "failed to read Failure from request body"))
 			return
 		}
-		if err := json.Unmarshal(b, &failure); err != nil {
+		if err := h.json.unmarshal(b, &failure); err != nil {
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read Failure from request body"))
 			return
 		}
+		if h.options.FailureConverter != nil {
+			if failure, err = h.options.FailureConverter.DecodeFailure(failure); err != nil {
+				h.logger.Warn("failed to decode completion failure", "error", err)
+				h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to decode failure"))
+				return
+			}
+		}
 		completion.Failure = &failure
 	case OperationStateSucceeded:
+		reader := readerFromHTTPRequest(request)
+		if len(h.options.Transformers) > 0 {
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				h.writeFailure(writer, requestBodyError(err, "failed to read result from request body"))
+				return
+			}
+			content, err := decodeContent(h.options.Transformers, &Content{Header: reader.Header, Data: data})
+			if err != nil {
+				h.logger.Warn("failed to decode completion result", "error", err)
+				h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to decode result"))
+				return
+			}
+			reader = &Reader{io.NopCloser(bytes.NewReader(content.Data)), content.Header}
+		}
 		completion.Result = &LazyValue{
 			serializer: h.options.Serializer,
-			Reader: &Reader{
-				request.Body,
-				prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-"),
-			},
+			Reader:     reader,
 		}
 	default:
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request operation state: %q", completion.State))
 		return
 	}
+	if h.deduper != nil {
+		if key, ok := completionDedupeKey(request.URL.RequestURI(), &completion); ok {
+			duplicate, err := h.deduper.process(ctx, key, func() error {
+				return h.options.Handler.CompleteOperation(ctx, &completion)
+			})
+			if err != nil {
+				h.writeFailure(writer, err)
+			} else if duplicate {
+				h.logger.Info("suppressed duplicate completion", "operationID", completion.OperationID)
+			}
+			return
+		}
+	}
 	if err := h.options.Handler.CompleteOperation(ctx, &completion); err != nil {
 		h.writeFailure(writer, err)
 	}
 }
 
 // NewCompletionHTTPHandler constructs an [http.Handler] from given options for handling operation completion requests.
+//
+// Completions encoded as CloudEvents in binary or structured mode, see [EncodeCompletionCloudEvent], are decoded before
+// they are passed to the Handler.
 func NewCompletionHTTPHandler(options CompletionHandlerOptions) http.Handler {
 	if options.Logger == nil {
 		options.Logger = slog.Default()
 	}
 	if options.Serializer == nil {
-		options.Serializer = defaultSerializer
+		options.Serializer = NewJSONSerializer(options.JSON)
+	}
+	var deduper *completionDeduper
+	if options.DedupeStore != nil {
+		deduper = &completionDeduper{store: options.DedupeStore, inFlight: make(map[string]chan struct{})}
 	}
 	return &completionHTTPHandler{
 		options: options,
+		deduper: deduper,
 		baseHTTPHandler: baseHTTPHandler{
-			logger: options.Logger,
+			logger:                 options.Logger,
+			loggerProvider:         options.LoggerProvider,
+			requestLogVerbosity:    options.RequestLogVerbosity,
+			failureConverter:       options.FailureConverter,
+			onPanic:                options.OnPanic,
+			exposePanicDetails:     options.ExposePanicDetails,
+			responseHeaderProvider: options.ResponseHeaderProvider,
+			json:                   options.JSON,
+			auditSink:              options.AuditSink,
 		},
 	}
 }
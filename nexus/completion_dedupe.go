@@ -0,0 +1,143 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// A CompletionDedupeStore records processed completions, allowing completion handlers to suppress duplicate deliveries
+// of the same completion, see [CompletionHandlerOptions.DedupeStore].
+//
+// Implementations must be safe for concurrent use.
+type CompletionDedupeStore interface {
+	// Contains reports whether a completion with the given key was recorded as processed.
+	Contains(ctx context.Context, key string) (bool, error)
+	// Add records a completion with the given key as processed.
+	Add(ctx context.Context, key string) error
+}
+
+// MemoryCompletionDedupeStore is an in-memory [CompletionDedupeStore] with a fixed TTL per entry.
+type MemoryCompletionDedupeStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryCompletionDedupeStore creates a [MemoryCompletionDedupeStore] that retains entries for the given TTL, which
+// should exceed the period senders retry deliveries for. A zero TTL retains entries indefinitely.
+func NewMemoryCompletionDedupeStore(ttl time.Duration) *MemoryCompletionDedupeStore {
+	return &MemoryCompletionDedupeStore{
+		ttl:     ttl,
+		expires: make(map[string]time.Time),
+	}
+}
+
+// Contains implements CompletionDedupeStore.
+func (s *MemoryCompletionDedupeStore) Contains(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.expires[key]
+	return ok && (expires.IsZero() || time.Now().Before(expires)), nil
+}
+
+// Add implements CompletionDedupeStore.
+func (s *MemoryCompletionDedupeStore) Add(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.ttl > 0 && now.Sub(s.lastSweep) >= s.ttl {
+		s.lastSweep = now
+		for key, expires := range s.expires {
+			if !now.Before(expires) {
+				delete(s.expires, key)
+			}
+		}
+	}
+	var expires time.Time
+	if s.ttl > 0 {
+		expires = now.Add(s.ttl)
+	}
+	s.expires[key] = expires
+	return nil
+}
+
+var _ CompletionDedupeStore = &MemoryCompletionDedupeStore{}
+
+// completionDeduper suppresses duplicate completions using a [CompletionDedupeStore]. Completions with the same key
+// are processed one at a time within a process, so that concurrent duplicates are suppressed as well.
+type completionDeduper struct {
+	store CompletionDedupeStore
+
+	mu       sync.Mutex
+	inFlight map[string]chan struct{}
+}
+
+// completionDedupeKey returns the key identifying a completion sent to the callback with the given request URI, i.e.
+// its URL path and query: its operation ID and state if the sender provided an operation ID, otherwise its request
+// ID. Both are provided by the sender, scoping them to the callback keeps completions of different callbacks sharing
+// an operation ID apart, including callbacks distinguished only by their query, e.g. a token. Returns false if the
+// completion cannot be identified.
+func completionDedupeKey(callbackURI string, completion *CompletionRequest) (string, bool) {
+	callback := "callback/" + url.PathEscape(callbackURI) + "/"
+	if completion.OperationID != "" {
+		return callback + "operation/" + completion.OperationID + "/" + string(completion.State), true
+	}
+	if completion.RequestID != "" {
+		return callback + "request/" + completion.RequestID, true
+	}
+	return "", false
+}
+
+// process invokes complete unless the completion with the given key was already processed, recording it once complete
+// succeeds.
+func (d *completionDeduper) process(ctx context.Context, key string, complete func() error) (duplicate bool, err error) {
+	release, err := d.acquire(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	seen, err := d.store.Contains(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up completion: %w", err)
+	}
+	if seen {
+		return true, nil
+	}
+	if err := complete(); err != nil {
+		return false, err
+	}
+	if err := d.store.Add(ctx, key); err != nil {
+		return false, fmt.Errorf("failed to record completion: %w", err)
+	}
+	return false, nil
+}
+
+// acquire waits until no other completion with the given key is being processed in this process.
+func (d *completionDeduper) acquire(ctx context.Context, key string) (func(), error) {
+	for {
+		d.mu.Lock()
+		wait, busy := d.inFlight[key]
+		if !busy {
+			done := make(chan struct{})
+			d.inFlight[key] = done
+			d.mu.Unlock()
+			return func() {
+				d.mu.Lock()
+				delete(d.inFlight, key)
+				d.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		d.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingCompletionHandler counts invocations, failing the first failures of them.
+type countingCompletionHandler struct {
+	failures int32
+	calls    atomic.Int32
+}
+
+func (h *countingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	if h.calls.Add(1) <= h.failures {
+		return HandlerErrorf(HandlerErrorTypeInternal, "try again")
+	}
+	return nil
+}
+
+func setupCompletionDedupe(t *testing.T, handler CompletionHandler) func(completion OperationCompletion) int {
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:     handler,
+		DedupeStore: NewMemoryCompletionDedupeStore(time.Minute),
+	}))
+	t.Cleanup(server.Close)
+	return func(completion OperationCompletion) int {
+		request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+}
+
+func TestCompletionDedupe(t *testing.T) {
+	handler := &countingCompletionHandler{failures: 1}
+	deliver := setupCompletionDedupe(t, handler)
+	canceled := func(operationID string) OperationCompletion {
+		return &OperationCompletionUnsuccessful{OperationID: operationID, State: OperationStateCanceled, Failure: &Failure{}}
+	}
+
+	// Failed completions are processed again when retried.
+	require.Equal(t, http.StatusInternalServerError, deliver(canceled("op-1")))
+	require.Equal(t, http.StatusOK, deliver(canceled("op-1")))
+	require.Equal(t, int32(2), handler.calls.Load())
+	// Duplicates are acknowledged without invoking the handler.
+	require.Equal(t, http.StatusOK, deliver(canceled("op-1")))
+	require.Equal(t, int32(2), handler.calls.Load())
+
+	require.Equal(t, http.StatusOK, deliver(canceled("op-2")))
+	require.Equal(t, int32(3), handler.calls.Load())
+
+	// Completions without an operation ID are deduped by request ID.
+	header := http.Header{HeaderRequestID: []string{"req-1"}}
+	require.Equal(t, http.StatusOK, deliver(&OperationCompletionUnsuccessful{Header: header, State: OperationStateFailed, Failure: &Failure{}}))
+	require.Equal(t, http.StatusOK, deliver(&OperationCompletionUnsuccessful{Header: header, State: OperationStateFailed, Failure: &Failure{}}))
+	require.Equal(t, int32(4), handler.calls.Load())
+}
+
+func TestCompletionDedupe_CallbackScoped(t *testing.T) {
+	handler := &countingCompletionHandler{}
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:     handler,
+		DedupeStore: NewMemoryCompletionDedupeStore(time.Minute),
+	}))
+	defer server.Close()
+	deliver := func(path string) int {
+		request, err := NewCompletionHTTPRequest(context.Background(), server.URL+path, &OperationCompletionUnsuccessful{
+			OperationID: "op-1",
+			State:       OperationStateFailed,
+			Failure:     &Failure{},
+		})
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	// Operations of different callbacks sharing an ID are processed separately, including callbacks that differ only in
+	// their query.
+	require.Equal(t, http.StatusOK, deliver("/callbacks/a"))
+	require.Equal(t, http.StatusOK, deliver("/callbacks/b"))
+	require.Equal(t, int32(2), handler.calls.Load())
+	require.Equal(t, http.StatusOK, deliver("/callbacks/a"))
+	require.Equal(t, http.StatusOK, deliver("/callbacks/b?token=1"))
+	require.Equal(t, int32(3), handler.calls.Load())
+	require.Equal(t, http.StatusOK, deliver("/callbacks/b?token=2"))
+	require.Equal(t, http.StatusOK, deliver("/callbacks/b?token=1"))
+	require.Equal(t, int32(4), handler.calls.Load())
+}
+
+func TestCompletionDedupe_Concurrent(t *testing.T) {
+	handler := &countingCompletionHandler{}
+	deliver := setupCompletionDedupe(t, handler)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			completion, err := NewOperationCompletionSuccessful("done", OperationCompletionSuccesfulOptions{})
+			require.NoError(t, err)
+			completion.OperationID = "op-1"
+			require.Equal(t, http.StatusOK, deliver(completion))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), handler.calls.Load())
+}
+
+func TestMemoryCompletionDedupeStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCompletionDedupeStore(10 * time.Millisecond)
+	require.NoError(t, store.Add(ctx, "a"))
+	seen, err := store.Contains(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	time.Sleep(20 * time.Millisecond)
+	seen, err = store.Contains(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, seen)
+}
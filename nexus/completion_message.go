@@ -0,0 +1,161 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CompletionMessage is an operation completion encoded for delivery over a message queue instead of HTTP, e.g. when
+// callback receivers sit behind a broker rather than a public HTTP endpoint.
+//
+// A message carries the header fields and body of the equivalent completion request created by
+// [NewCompletionHTTPRequest], including request IDs, caller identities and signatures, and is handled by a
+// [CompletionMessageHandler] exactly like a completion request is handled by [NewCompletionHTTPHandler].
+type CompletionMessage struct {
+	// Header fields of the completion, keyed by their canonical HTTP header names, e.g. "Nexus-Operation-State".
+	Header map[string]string `json:"header"`
+	// Body of the completion, the serialized result of a successful operation or the failure of an unsuccessful one.
+	Body []byte `json:"body,omitempty"`
+}
+
+// Get returns the value of the given header field, matching its name case insensitively.
+func (m *CompletionMessage) Get(key string) string {
+	return m.Header[http.CanonicalHeaderKey(key)]
+}
+
+// NewCompletionMessage encodes a completion as a [CompletionMessage], reading its body into memory.
+//
+// Unless set in the completion's header, a v4 UUID is generated as the message's [HeaderRequestID], as done by
+// [NewCompletionHTTPRequest].
+func NewCompletionMessage(ctx context.Context, completion OperationCompletion) (*CompletionMessage, error) {
+	request, err := NewCompletionHTTPRequest(ctx, "", completion)
+	if err != nil {
+		return nil, err
+	}
+	return completionMessageFromHTTPRequest(request)
+}
+
+// completionMessageFromHTTPRequest encodes a completion request as a [CompletionMessage], consuming its body.
+func completionMessageFromHTTPRequest(request *http.Request) (*CompletionMessage, error) {
+	message := &CompletionMessage{Header: make(map[string]string, len(request.Header))}
+	for k, v := range request.Header {
+		// The length of the body is implied by the message.
+		if len(v) > 0 && k != "Content-Length" {
+			message.Header[k] = v[0]
+		}
+	}
+	if request.Body != nil {
+		body, err := io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		message.Body = body
+	}
+	return message, nil
+}
+
+// newHTTPRequest creates the completion request equivalent to m.
+func (m *CompletionMessage) newHTTPRequest(ctx context.Context) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", "", bytes.NewReader(m.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.URL = completionMessageURL()
+	for k, v := range m.Header {
+		request.Header.Set(k, v)
+	}
+	request.Header.Set("Content-Length", strconv.Itoa(len(m.Body)))
+	return request, nil
+}
+
+// completionMessageURL returns the URL of completion requests equivalent to messages, which carry no URL. Signatures of
+// completions delivered as messages cover this URL, see [SignCompletionHTTPRequest].
+func completionMessageURL() *url.URL {
+	return &url.URL{Path: "/"}
+}
+
+// A CompletionTransport delivers operation completions to callbacks over a transport other than HTTP, e.g. by
+// publishing them to a message queue, see [CallbackDeliveryOptions.Transports].
+//
+// Implementations must be safe for concurrent use.
+type CompletionTransport interface {
+	// DeliverCompletion delivers a completion to a callback. Returning an error fails the delivery attempt, which is
+	// retried according to [CallbackDeliveryOptions.RetryPolicy]. The message's [HeaderRequestID] is identical for
+	// every attempt, allowing receivers to dedupe retried deliveries.
+	DeliverCompletion(ctx context.Context, callback Callback, message *CompletionMessage) error
+}
+
+// CompletionTransportFunc is a [CompletionTransport] backed by a function.
+type CompletionTransportFunc func(ctx context.Context, callback Callback, message *CompletionMessage) error
+
+// DeliverCompletion implements CompletionTransport.
+func (f CompletionTransportFunc) DeliverCompletion(ctx context.Context, callback Callback, message *CompletionMessage) error {
+	return f(ctx, callback, message)
+}
+
+// CompletionMessageHandler feeds completion messages received from a message queue to a [CompletionHandler], for
+// consumers of the queue a [CompletionTransport] publishes to. Create one with [NewCompletionMessageHandler].
+type CompletionMessageHandler struct {
+	handler *completionHTTPHandler
+}
+
+// NewCompletionMessageHandler constructs a [CompletionMessageHandler] from the given options. Messages are verified,
+// decoded, deduped and logged as [NewCompletionHTTPHandler] does for completion requests. Options specific to HTTP
+// responses, such as ResponseHeaderProvider, are ignored.
+//
+// Note that messages may wait in a queue for longer than [CompletionHandlerOptions.MaxSignatureAge] allows when
+// verifying signatures.
+func NewCompletionMessageHandler(options CompletionHandlerOptions) *CompletionMessageHandler {
+	options.ResponseHeaderProvider = nil
+	return &CompletionMessageHandler{handler: NewCompletionHTTPHandler(options).(*completionHTTPHandler)}
+}
+
+// HandleMessage handles a completion message. A nil error means the completion was processed, or suppressed as a
+// duplicate, and the message may be acknowledged. Otherwise the error is the one the [CompletionHandler] failed with,
+// or a [HandlerError] if the message is invalid, and the message should be retried or dead-lettered depending on
+// the error.
+func (h *CompletionMessageHandler) HandleMessage(ctx context.Context, message *CompletionMessage) error {
+	request, err := message.newHTTPRequest(ctx)
+	if err != nil {
+		return err
+	}
+	rh := *h.handler
+	writer, done := rh.startRequestLog(&discardResponseWriter{header: make(http.Header)}, request, "state", request.Header.Get(HeaderOperationState))
+	defer done()
+	defer rh.startAudit(writer, request, HandlerMethodCompleteOperation)()
+	recorder := writer.(*statusRecorder)
+	func() {
+		if !rh.limitRequestBody(recorder, request, rh.options.MaxRequestBodySize) {
+			return
+		}
+		defer rh.recoverPanic(ctx, recorder)
+		rh.serveHTTP(recorder, request)
+	}()
+	if recorder.status < http.StatusBadRequest {
+		return nil
+	}
+	if recorder.err != nil {
+		return recorder.err
+	}
+	return &HandlerError{Type: HandlerErrorTypeFromHTTPStatus(recorder.status)}
+}
+
+// discardResponseWriter is an [http.ResponseWriter] discarding the response to a completion message.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
@@ -0,0 +1,143 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// completionHandlerFunc is a [CompletionHandler] backed by a function.
+type completionHandlerFunc func(ctx context.Context, completion *CompletionRequest) error
+
+func (f completionHandlerFunc) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	return f(ctx, completion)
+}
+
+func TestCompletionMessageHandler(t *testing.T) {
+	ctx := context.Background()
+	var received *CompletionRequest
+	var result string
+	handler := NewCompletionMessageHandler(CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, completion *CompletionRequest) error {
+			received = completion
+			if completion.Result != nil {
+				return completion.Result.Consume(&result)
+			}
+			return nil
+		}),
+	})
+
+	completion, err := NewOperationCompletionSuccessful("hello", OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	completion.OperationID = "op-1"
+	message, err := NewCompletionMessage(ctx, completion)
+	require.NoError(t, err)
+	require.Equal(t, string(OperationStateSucceeded), message.Get(HeaderOperationState))
+	require.NotEmpty(t, message.Get(HeaderRequestID))
+	require.Empty(t, message.Get("Content-Length"))
+
+	require.NoError(t, handler.HandleMessage(ctx, message))
+	require.Equal(t, OperationStateSucceeded, received.State)
+	require.Equal(t, "op-1", received.OperationID)
+	require.Equal(t, message.Get(HeaderRequestID), received.RequestID)
+	require.Equal(t, clientName, received.ClientName)
+	require.Equal(t, "hello", result)
+
+	message, err = NewCompletionMessage(ctx, &OperationCompletionUnsuccessful{
+		State:   OperationStateFailed,
+		Failure: &Failure{Message: "oops"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, handler.HandleMessage(ctx, message))
+	require.Equal(t, OperationStateFailed, received.State)
+	require.Equal(t, "oops", received.Failure.Message)
+
+	// Invalid messages fail with a handler error.
+	message.Header["Nexus-Operation-State"] = "invalid"
+	var handlerErr *HandlerError
+	require.ErrorAs(t, handler.HandleMessage(ctx, message), &handlerErr)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerErr.Type)
+}
+
+func TestCompletionMessageHandler_Errors(t *testing.T) {
+	ctx := context.Background()
+	handlerErr := errors.New("database unavailable")
+	panicking := false
+	handler := NewCompletionMessageHandler(CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, completion *CompletionRequest) error {
+			if panicking {
+				panic("boom")
+			}
+			return handlerErr
+		}),
+		MaxRequestBodySize: 10,
+	})
+
+	message, err := NewCompletionMessage(ctx, &OperationCompletionUnsuccessful{State: OperationStateCanceled})
+	require.NoError(t, err)
+	require.ErrorIs(t, handler.HandleMessage(ctx, message), handlerErr)
+
+	panicking = true
+	var internalErr *HandlerError
+	require.ErrorAs(t, handler.HandleMessage(ctx, message), &internalErr)
+	require.Equal(t, HandlerErrorTypeInternal, internalErr.Type)
+
+	message.Body = []byte(`{"message": "too large"}`)
+	require.ErrorContains(t, handler.HandleMessage(ctx, message), "too large")
+}
+
+func TestCallbackDelivery_Transport(t *testing.T) {
+	messages := make(chan *CompletionMessage, 1)
+	transport := CompletionTransportFunc(func(ctx context.Context, callback Callback, message *CompletionMessage) error {
+		if callback.URL != "queue://completions" {
+			return errors.New("unexpected callback")
+		}
+		messages <- message
+		return nil
+	})
+	store := NewMemoryOperationStore()
+	asyncHandler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: store,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return "done", nil
+		},
+		CallbackDelivery: &CallbackDeliveryOptions{
+			Signer:     NewHMACCompletionSigner([]byte("secret")),
+			Transports: map[string]CompletionTransport{"queue": transport},
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: asyncHandler}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		CallbackURL:    "queue://completions",
+		CallbackHeader: Header{"token": "abc"},
+	})
+	require.NoError(t, err)
+
+	var message *CompletionMessage
+	select {
+	case message = <-messages:
+	case <-time.After(testTimeout):
+		t.Fatal("completion not delivered")
+	}
+	require.Equal(t, "abc", message.Get("token"))
+	require.Equal(t, result.Pending.ID, message.Get(HeaderOperationID))
+	require.NotEmpty(t, message.Get(HeaderCompletionSignature))
+
+	var output string
+	handler := NewCompletionMessageHandler(CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, completion *CompletionRequest) error {
+			return completion.Result.Consume(&output)
+		}),
+		Verifier: NewHMACCompletionVerifier([]byte("secret")),
+	})
+	require.NoError(t, handler.HandleMessage(ctx, message))
+	require.Equal(t, "done", output)
+
+	waitForCallbacks(ctx, t, store, OperationKey{Operation: "foo", ID: result.Pending.ID}, CallbackDeliveryStateSucceeded)
+}
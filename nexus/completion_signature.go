@@ -0,0 +1,182 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default maximum age of a completion signature accepted by [NewCompletionHTTPHandler].
+const defaultCompletionSignatureMaxAge = 5 * time.Minute
+
+// ErrInvalidCompletionSignature is returned from [CompletionVerifier.Verify] when a signature does not match.
+var ErrInvalidCompletionSignature = errors.New("invalid completion signature")
+
+// A CompletionSigner signs completion requests, allowing the receiver to verify that they were sent by a trusted
+// handler. See [SignCompletionHTTPRequest].
+//
+// The signed message covers the request's timestamp, method, URL path and query, operation state, operation ID, request
+// ID, content headers, and body.
+//
+// Implementations must be safe for concurrent use.
+type CompletionSigner interface {
+	// Sign returns the signature of the given message.
+	Sign(ctx context.Context, message []byte) (string, error)
+}
+
+// A CompletionVerifier verifies signatures of completion requests created by a [CompletionSigner]. See
+// [CompletionHandlerOptions.Verifier].
+//
+// Implementations must be safe for concurrent use.
+type CompletionVerifier interface {
+	// Verify returns an error if signature is not a valid signature of the given message, typically
+	// [ErrInvalidCompletionSignature].
+	Verify(ctx context.Context, message []byte, signature string) error
+}
+
+type hmacCompletionSigner []byte
+
+// NewHMACCompletionSigner creates a [CompletionSigner] that signs completions with an HMAC-SHA256 of the given shared
+// secret.
+func NewHMACCompletionSigner(secret []byte) CompletionSigner {
+	return hmacCompletionSigner(secret)
+}
+
+// Sign implements CompletionSigner.
+func (s hmacCompletionSigner) Sign(ctx context.Context, message []byte) (string, error) {
+	return hex.EncodeToString(hmacSHA256(s, message)), nil
+}
+
+type hmacCompletionVerifier [][]byte
+
+// NewHMACCompletionVerifier creates a [CompletionVerifier] for signatures created by [NewHMACCompletionSigner].
+// Signatures of any of the given secrets are accepted, allowing secrets to be rotated without rejecting completions
+// signed with the previous secret.
+func NewHMACCompletionVerifier(secrets ...[]byte) CompletionVerifier {
+	return hmacCompletionVerifier(secrets)
+}
+
+// Verify implements CompletionVerifier.
+func (v hmacCompletionVerifier) Verify(ctx context.Context, message []byte, signature string) error {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidCompletionSignature
+	}
+	for _, secret := range v {
+		if hmac.Equal(decoded, hmacSHA256(secret, message)) {
+			return nil
+		}
+	}
+	return ErrInvalidCompletionSignature
+}
+
+func hmacSHA256(secret, message []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// completionSignatureMessage returns the message signed for a completion request. It covers the request's method,
+// target, operation state, operation ID, request ID and content headers, one per line in that order, followed by an
+// empty line and the body, so that a signed completion cannot be replayed to another callback or operation.
+func completionSignatureMessage(timestamp string, request *http.Request, body []byte) []byte {
+	var message bytes.Buffer
+	for _, field := range []string{
+		timestamp,
+		request.Method,
+		request.URL.RequestURI(),
+		request.Header.Get(HeaderOperationState),
+		request.Header.Get(HeaderOperationID),
+		request.Header.Get(HeaderRequestID),
+	} {
+		message.WriteString(field)
+		message.WriteByte('\n')
+	}
+	var names []string
+	for name := range request.Header {
+		// The length of the body is implied by the signed body.
+		if name != "Content-Length" && strings.HasPrefix(strings.ToLower(name), "content-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		message.WriteString(strings.ToLower(name))
+		message.WriteByte(':')
+		message.WriteString(strings.Join(request.Header[name], ","))
+		message.WriteByte('\n')
+	}
+	message.WriteByte('\n')
+	message.Write(body)
+	return message.Bytes()
+}
+
+// SignCompletionHTTPRequest signs a completion request created with [NewCompletionHTTPRequest] using the provided
+// [CompletionSigner], buffering its body in memory. The signature and signing time are attached as headers for
+// verification by the receiving [NewCompletionHTTPHandler], see [CompletionHandlerOptions.Verifier].
+//
+// Sign the request after setting all of its headers and before sending it. Receivers reject the request if any of the
+// signed fields change, including its URL, so sign it for the exact URL it is sent to.
+func SignCompletionHTTPRequest(request *http.Request, signer CompletionSigner) error {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read completion request body: %w", err)
+		}
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.ContentLength = int64(len(body))
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature, err := signer.Sign(request.Context(), completionSignatureMessage(timestamp, request, body))
+	if err != nil {
+		return fmt.Errorf("failed to sign completion request: %w", err)
+	}
+	request.Header.Set(HeaderCompletionTimestamp, timestamp)
+	request.Header.Set(HeaderCompletionSignature, signature)
+	return nil
+}
+
+// verifyCompletionSignature verifies the signature of a completion request, replacing its body with a buffered copy.
+func (h *completionHTTPHandler) verifyCompletionSignature(request *http.Request) error {
+	signature := request.Header.Get(HeaderCompletionSignature)
+	timestamp := request.Header.Get(HeaderCompletionTimestamp)
+	if signature == "" || timestamp == "" {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "missing completion signature")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "invalid completion signature timestamp")
+	}
+	maxAge := h.options.MaxSignatureAge
+	if maxAge == 0 {
+		maxAge = defaultCompletionSignatureMaxAge
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > maxAge || age < -maxAge {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "completion signature expired")
+	}
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return requestBodyError(err, "failed to read request body")
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	message := completionSignatureMessage(timestamp, request, body)
+	if err := h.options.Verifier.Verify(request.Context(), message, signature); err != nil {
+		h.logger.Warn("failed to verify completion signature", "error", err)
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "invalid completion signature")
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupSignedCompletion(t *testing.T, verifier CompletionVerifier) string {
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:  &successfulCompletionHandler{},
+		Verifier: verifier,
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/callback?a=b"
+}
+
+func newTestCompletionRequest(t *testing.T, callbackURL string) *http.Request {
+	completion, err := NewOperationCompletionSuccessful(666, OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	completion.Header.Add("foo", "bar")
+	request, err := NewCompletionHTTPRequest(context.Background(), callbackURL, completion)
+	require.NoError(t, err)
+	return request
+}
+
+func sendCompletionRequest(t *testing.T, request *http.Request) int {
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	_, err = readAndReplaceBody(response)
+	require.NoError(t, err)
+	return response.StatusCode
+}
+
+func TestSignedCompletion(t *testing.T) {
+	callbackURL := setupSignedCompletion(t, NewHMACCompletionVerifier([]byte("secret")))
+
+	request := newTestCompletionRequest(t, callbackURL)
+	require.NoError(t, SignCompletionHTTPRequest(request, NewHMACCompletionSigner([]byte("secret"))))
+	require.NotEmpty(t, request.Header.Get(HeaderCompletionSignature))
+	require.Equal(t, http.StatusOK, sendCompletionRequest(t, request))
+}
+
+func TestSignedCompletion_Rejected(t *testing.T) {
+	callbackURL := setupSignedCompletion(t, NewHMACCompletionVerifier([]byte("secret")))
+	signer := NewHMACCompletionSigner([]byte("secret"))
+
+	cases := []struct {
+		name    string
+		prepare func(*http.Request)
+	}{
+		{
+			name:    "Unsigned",
+			prepare: func(*http.Request) {},
+		},
+		{
+			name: "WrongSecret",
+			prepare: func(request *http.Request) {
+				require.NoError(t, SignCompletionHTTPRequest(request, NewHMACCompletionSigner([]byte("other"))))
+			},
+		},
+		{
+			name: "TamperedState",
+			prepare: func(request *http.Request) {
+				require.NoError(t, SignCompletionHTTPRequest(request, signer))
+				request.Header.Set(HeaderOperationState, string(OperationStateFailed))
+			},
+		},
+		{
+			name: "Expired",
+			prepare: func(request *http.Request) {
+				require.NoError(t, SignCompletionHTTPRequest(request, signer))
+				// Re-sign an old timestamp so that only the age check fails.
+				timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+				body, err := io.ReadAll(request.Body)
+				require.NoError(t, err)
+				request.Body = io.NopCloser(bytes.NewReader(body))
+				signature, err := signer.Sign(context.Background(), completionSignatureMessage(timestamp, request, body))
+				require.NoError(t, err)
+				request.Header.Set(HeaderCompletionTimestamp, timestamp)
+				request.Header.Set(HeaderCompletionSignature, signature)
+			},
+		},
+		{
+			name: "MalformedSignature",
+			prepare: func(request *http.Request) {
+				require.NoError(t, SignCompletionHTTPRequest(request, signer))
+				request.Header.Set(HeaderCompletionSignature, "not-hex")
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := newTestCompletionRequest(t, callbackURL)
+			c.prepare(request)
+			require.Equal(t, http.StatusUnauthorized, sendCompletionRequest(t, request))
+		})
+	}
+}
+
+func TestSignedCompletion_SignedFields(t *testing.T) {
+	handler := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:  &successfulCompletionHandler{},
+		Verifier: NewHMACCompletionVerifier([]byte("secret")),
+	}).(*completionHTTPHandler)
+
+	completion, err := NewOperationCompletionSuccessful([]byte("result"), OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	completion.OperationID = "op-1"
+	completion.Header.Set("Content-Encoding", "identity")
+	signed, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback/a?token=b", completion)
+	require.NoError(t, err)
+	require.NoError(t, SignCompletionHTTPRequest(signed, NewHMACCompletionSigner([]byte("secret"))))
+	body, err := io.ReadAll(signed.Body)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		header func(http.Header)
+	}{
+		{name: "Method", method: "PUT"},
+		{name: "Path", target: "/callback/c?token=b"},
+		{name: "Query", target: "/callback/a?token=c"},
+		{name: "OperationID", header: func(h http.Header) { h.Set(HeaderOperationID, "op-2") }},
+		{name: "RequestID", header: func(h http.Header) { h.Set(HeaderRequestID, "other") }},
+		{name: "ContentType", header: func(h http.Header) { h.Set("Content-Type", "text/plain") }},
+		{name: "ContentHeader", header: func(h http.Header) { h.Set("Content-Encoding", "gzip") }},
+		{name: "AddedContentHeader", header: func(h http.Header) { h.Set("Content-Language", "en") }},
+		{name: "RemovedContentHeader", header: func(h http.Header) { h.Del("Content-Encoding") }},
+	}
+	// newRequest returns the request as received by the handler.
+	newRequest := func(method, target string) *http.Request {
+		request := httptest.NewRequest(method, target, bytes.NewReader(body))
+		for k, v := range signed.Header {
+			request.Header[k] = v
+		}
+		return request
+	}
+	require.NoError(t, handler.verifyCompletionSignature(newRequest("POST", "/callback/a?token=b")))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			method, target := "POST", "/callback/a?token=b"
+			if c.method != "" {
+				method = c.method
+			}
+			if c.target != "" {
+				target = c.target
+			}
+			request := newRequest(method, target)
+			if c.header != nil {
+				c.header(request.Header)
+			}
+			var handlerError *HandlerError
+			require.ErrorAs(t, handler.verifyCompletionSignature(request), &handlerError)
+			require.Equal(t, HandlerErrorTypeUnauthenticated, handlerError.Type)
+		})
+	}
+}
+
+func TestSignedCompletion_SecretRotation(t *testing.T) {
+	callbackURL := setupSignedCompletion(t, NewHMACCompletionVerifier([]byte("new"), []byte("old")))
+	for _, secret := range []string{"new", "old"} {
+		request := newTestCompletionRequest(t, callbackURL)
+		require.NoError(t, SignCompletionHTTPRequest(request, NewHMACCompletionSigner([]byte(secret))))
+		require.Equal(t, http.StatusOK, sendCompletionRequest(t, request))
+	}
+}
+
+type resultRecordingCompletionHandler struct {
+	results chan string
+}
+
+func (h *resultRecordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	var result string
+	if err := completion.Result.Consume(&result); err != nil {
+		return err
+	}
+	h.results <- result
+	return nil
+}
+
+func TestCallbackDelivery_Signed(t *testing.T) {
+	completionHandler := &resultRecordingCompletionHandler{results: make(chan string, 1)}
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:  completionHandler,
+		Verifier: NewHMACCompletionVerifier([]byte("secret")),
+	}))
+	defer server.Close()
+
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: store,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return "done", nil
+		},
+		CallbackDelivery: &CallbackDeliveryOptions{
+			Signer:      NewHMACCompletionSigner([]byte("secret")),
+			RetryPolicy: RetryPolicy{MaxAttempts: 1},
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{CallbackURL: server.URL})
+	require.NoError(t, err)
+	waitForCallbacks(ctx, t, store, result.Pending.Key(), CallbackDeliveryStateSucceeded)
+	require.Equal(t, "done", <-completionHandler.results)
+}
@@ -26,6 +26,9 @@ func (h *successfulCompletionHandler) CompleteOperation(ctx context.Context, com
 	if completion.HTTPRequest.Header.Get("User-Agent") != userAgent {
 		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'User-Agent' header: %q", completion.HTTPRequest.Header.Get("User-Agent"))
 	}
+	if completion.UserAgent != userAgent || completion.ClientName != clientName || completion.ClientVersion != version {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid client info: %q %q %q", completion.UserAgent, completion.ClientName, completion.ClientVersion)
+	}
 	var result int
 	err := completion.Result.Consume(&result)
 	if err != nil {
@@ -136,3 +139,47 @@ func TestBadRequestCompletion(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, http.StatusBadRequest, response.StatusCode)
 }
+
+func TestCompletion_MethodNotAllowed(t *testing.T) {
+	ctx, callbackURL, teardown := setupForCompletion(t, &successfulCompletionHandler{}, nil)
+	defer teardown()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", callbackURL, nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	require.Equal(t, "POST", response.Header.Get("Allow"))
+	require.JSONEq(t, `{"message":"method not allowed"}`, string(body))
+}
+
+type requestIDRecordingCompletionHandler struct {
+	requestIDs chan string
+}
+
+func (h *requestIDRecordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	h.requestIDs <- completion.RequestID
+	return nil
+}
+
+func TestCompletion_RequestID(t *testing.T) {
+	handler := &requestIDRecordingCompletionHandler{requestIDs: make(chan string, 1)}
+	ctx, callbackURL, teardown := setupForCompletion(t, handler, nil)
+	defer teardown()
+
+	deliver := func(header http.Header) string {
+		completion := &OperationCompletionUnsuccessful{Header: header, State: OperationStateFailed, Failure: &Failure{Message: "oops"}}
+		request, err := NewCompletionHTTPRequest(ctx, callbackURL, completion)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		return <-handler.requestIDs
+	}
+	require.NotEmpty(t, deliver(nil))
+	require.Equal(t, "completion-1", deliver(http.Header{HeaderRequestID: []string{"completion-1"}}))
+}
@@ -0,0 +1,389 @@
+// Package conformance provides a reusable test suite verifying that a Nexus service conforms to the [Nexus HTTP API],
+// exercising required headers, status codes, long polls and failure envelopes over the wire. Run it against any
+// [nexus.Handler] served in-process, or against a remote endpoint, e.g. in CI:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, conformance.Options{
+//			Handler:          nexus.NewHTTPHandler(nexus.HandlerOptions{Handler: myHandler}),
+//			EchoOperation:    "echo",
+//			AsyncOperation:   "echo-async",
+//			PendingOperation: "wait-for-cancel",
+//		})
+//	}
+//
+// The suite relies on the service implementing operations with known behavior, see [Options]. Checks that require an
+// operation that is not configured are skipped.
+//
+// [Nexus HTTP API]: https://github.com/nexus-rpc/api
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Options configure the service under test and the operations the suite exercises.
+type Options struct {
+	// Base URL of a remote service under test. Either ServiceBaseURL or Handler is required.
+	ServiceBaseURL string
+	// Handler of the service under test, e.g. created with [nexus.NewHTTPHandler], served on a local test server for the
+	// duration of the suite.
+	Handler http.Handler
+	// A function for making HTTP requests. Defaults to [http.DefaultClient].
+	HTTPCaller func(*http.Request) (*http.Response, error)
+	// Optional header fields sent with every request, e.g. an Authorization header.
+	Header http.Header
+	// Name of an operation that completes synchronously, responding with its input and the input's Content-Type.
+	// Checks of synchronous operations are skipped if empty.
+	EchoOperation string
+	// Name of an operation that is started asynchronously and completes successfully with its JSON input as the result
+	// within CompletionTimeout. Checks of asynchronous operations are skipped if empty.
+	AsyncOperation string
+	// Maximum time for the AsyncOperation to complete. Defaults to 10 seconds.
+	CompletionTimeout time.Duration
+	// Name of an operation that is started asynchronously and keeps running until canceled, used to verify long poll
+	// timeouts and cancelation. Checks of pending operations are skipped if empty.
+	PendingOperation string
+}
+
+// suite runs the checks against a single service.
+type suite struct {
+	options Options
+	baseURL *url.URL
+}
+
+// Run runs the conformance suite as subtests of t, failing t if the service deviates from the Nexus HTTP API.
+func Run(t *testing.T, options Options) {
+	t.Helper()
+	if options.Handler != nil {
+		server := httptest.NewServer(options.Handler)
+		t.Cleanup(server.Close)
+		options.ServiceBaseURL = server.URL
+	}
+	if options.ServiceBaseURL == "" {
+		t.Fatal("conformance: either ServiceBaseURL or Handler is required")
+	}
+	if options.HTTPCaller == nil {
+		options.HTTPCaller = http.DefaultClient.Do
+	}
+	if options.CompletionTimeout == 0 {
+		options.CompletionTimeout = 10 * time.Second
+	}
+	baseURL, err := url.Parse(options.ServiceBaseURL)
+	if err != nil {
+		t.Fatalf("conformance: invalid service base URL: %v", err)
+	}
+	s := &suite{options: options, baseURL: baseURL}
+
+	t.Run("InvalidRequestTimeout", s.testInvalidRequestTimeout)
+	t.Run("EchoOperation", func(t *testing.T) {
+		if options.EchoOperation == "" {
+			t.Skip("EchoOperation not configured")
+		}
+		t.Run("Bytes", s.testEchoBytes)
+		t.Run("JSON", s.testEchoJSON)
+	})
+	t.Run("AsyncOperation", func(t *testing.T) {
+		if options.AsyncOperation == "" {
+			t.Skip("AsyncOperation not configured")
+		}
+		t.Run("Lifecycle", s.testAsyncLifecycle)
+		t.Run("InvalidWait", s.testInvalidWait)
+		t.Run("UnknownOperationID", s.testUnknownOperationID)
+		t.Run("Cancel", s.testCancel)
+	})
+	t.Run("PendingOperation", func(t *testing.T) {
+		if options.PendingOperation == "" {
+			t.Skip("PendingOperation not configured")
+		}
+		t.Run("LongPoll", s.testLongPoll)
+		t.Run("Cancel", s.testCancelPending)
+	})
+}
+
+// response is a response whose body was read into memory.
+type response struct {
+	*http.Response
+	body []byte
+}
+
+func (s *suite) do(t *testing.T, method string, pathSegments []string, query url.Values, header http.Header, body []byte) *response {
+	t.Helper()
+	u := s.baseURL.JoinPath(pathSegments...)
+	u.RawQuery = query.Encode()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	for k, v := range s.options.Header {
+		request.Header[k] = v
+	}
+	for k, v := range header {
+		request.Header[k] = v
+	}
+	httpResponse, err := s.options.HTTPCaller(request)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, u.Path, err)
+	}
+	defer httpResponse.Body.Close()
+	b, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body of %s %s: %v", method, u.Path, err)
+	}
+	return &response{httpResponse, b}
+}
+
+func (s *suite) start(t *testing.T, operation string, contentType string, body []byte) *response {
+	t.Helper()
+	header := http.Header{"Content-Type": {contentType}}
+	header.Set(nexus.HeaderRequestID, fmt.Sprintf("conformance-%d", time.Now().UnixNano()))
+	return s.do(t, "POST", []string{operation}, nil, header, body)
+}
+
+func requireStatus(t *testing.T, r *response, statusCodes ...int) {
+	t.Helper()
+	for _, statusCode := range statusCodes {
+		if r.StatusCode == statusCode {
+			return
+		}
+	}
+	t.Fatalf("%s %s: expected status %v, got %d: %s", r.Request.Method, r.Request.URL.Path, statusCodes, r.StatusCode, r.body)
+}
+
+func requireJSON(t *testing.T, r *response, v any) {
+	t.Helper()
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		t.Fatalf("%s %s: expected a JSON response, got Content-Type %q", r.Request.Method, r.Request.URL.Path, r.Header.Get("Content-Type"))
+	}
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("%s %s: invalid JSON response body: %v", r.Request.Method, r.Request.URL.Path, err)
+	}
+}
+
+// requireFailure verifies that a response has the given status and carries a failure envelope, a JSON object with a
+// message.
+func requireFailure(t *testing.T, r *response, statusCode int) {
+	t.Helper()
+	requireStatus(t, r, statusCode)
+	var envelope map[string]json.RawMessage
+	requireJSON(t, r, &envelope)
+	var message string
+	if err := json.Unmarshal(envelope["message"], &message); err != nil {
+		t.Fatalf("%s %s: failure envelope without a message: %s", r.Request.Method, r.Request.URL.Path, r.body)
+	}
+}
+
+func (s *suite) testInvalidRequestTimeout(t *testing.T) {
+	operation := s.options.AsyncOperation
+	if operation == "" {
+		operation = s.options.EchoOperation
+	}
+	if operation == "" {
+		operation = "conformance"
+	}
+	r := s.do(t, "GET", []string{operation, "conformance-id"}, nil, http.Header{nexus.HeaderRequestTimeout: {"soon"}}, nil)
+	requireFailure(t, r, http.StatusBadRequest)
+}
+
+func (s *suite) testEchoBytes(t *testing.T) {
+	input := []byte{0, 1, 2, 0xff}
+	r := s.start(t, s.options.EchoOperation, "application/octet-stream", input)
+	requireStatus(t, r, http.StatusOK)
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/octet-stream" {
+		t.Fatalf("expected the input's Content-Type, got %q", contentType)
+	}
+	if !bytes.Equal(r.body, input) {
+		t.Fatalf("expected the input as the result, got %q", r.body)
+	}
+	if length := r.Header.Get("Content-Length"); length != "" && length != strconv.Itoa(len(input)) {
+		t.Fatalf("invalid Content-Length: %s", length)
+	}
+}
+
+func (s *suite) testEchoJSON(t *testing.T) {
+	r := s.start(t, s.options.EchoOperation, "application/json", []byte(`{"message":"hello"}`))
+	requireStatus(t, r, http.StatusOK)
+	var result struct{ Message string }
+	requireJSON(t, r, &result)
+	if result.Message != "hello" {
+		t.Fatalf("expected the input as the result, got %s", r.body)
+	}
+}
+
+// startAsync starts an asynchronous operation and verifies the 201 response, returning the operation ID.
+func (s *suite) startAsync(t *testing.T, operation string, body []byte) string {
+	t.Helper()
+	r := s.start(t, operation, "application/json", body)
+	requireStatus(t, r, http.StatusCreated)
+	var info nexus.OperationInfo
+	requireJSON(t, r, &info)
+	if info.ID == "" {
+		t.Fatalf("start response without an operation ID: %s", r.body)
+	}
+	if info.State != nexus.OperationStateRunning {
+		t.Fatalf("expected state %q in start response, got %q", nexus.OperationStateRunning, info.State)
+	}
+	return info.ID
+}
+
+func (s *suite) testAsyncLifecycle(t *testing.T) {
+	input := []byte(`{"message":"hello"}`)
+	operationID := s.startAsync(t, s.options.AsyncOperation, input)
+	path := []string{s.options.AsyncOperation, operationID}
+
+	r := s.do(t, "GET", path, nil, nil, nil)
+	requireStatus(t, r, http.StatusOK)
+	var info nexus.OperationInfo
+	requireJSON(t, r, &info)
+	if info.ID != operationID {
+		t.Fatalf("expected info of operation %q, got %q", operationID, info.ID)
+	}
+	requireValidState(t, info.State)
+
+	deadline := time.Now().Add(s.options.CompletionTimeout)
+	for {
+		r = s.do(t, "GET", append(path, "result"), url.Values{nexus.QueryWait: {nexus.FormatDurationParam(time.Second)}}, nil, nil)
+		if r.StatusCode == http.StatusOK {
+			break
+		}
+		// Long polls time out with 408, handlers may also respond right away if the operation is still running.
+		requireStatus(t, r, http.StatusRequestTimeout, nexus.StatusOperationRunning)
+		if time.Now().After(deadline) {
+			t.Fatalf("operation did not complete within %v", s.options.CompletionTimeout)
+		}
+	}
+	requireEchoedJSON(t, r, input)
+
+	// Results of completed operations are returned without waiting.
+	r = s.do(t, "GET", append(path, "result"), nil, nil, nil)
+	requireStatus(t, r, http.StatusOK)
+	requireEchoedJSON(t, r, input)
+
+	r = s.do(t, "GET", path, nil, nil, nil)
+	requireStatus(t, r, http.StatusOK)
+	requireJSON(t, r, &info)
+	if info.State != nexus.OperationStateSucceeded {
+		t.Fatalf("expected state %q after completion, got %q", nexus.OperationStateSucceeded, info.State)
+	}
+}
+
+func requireValidState(t *testing.T, state nexus.OperationState) {
+	t.Helper()
+	switch state {
+	case nexus.OperationStateRunning, nexus.OperationStateSucceeded, nexus.OperationStateFailed, nexus.OperationStateCanceled:
+	default:
+		t.Fatalf("invalid operation state: %q", state)
+	}
+}
+
+func requireEchoedJSON(t *testing.T, r *response, input []byte) {
+	t.Helper()
+	var expected, actual any
+	if err := json.Unmarshal(input, &expected); err != nil {
+		t.Fatalf("invalid input: %v", err)
+	}
+	requireJSON(t, r, &actual)
+	a, _ := json.Marshal(actual)
+	e, _ := json.Marshal(expected)
+	if !bytes.Equal(a, e) {
+		t.Fatalf("expected the input as the result, got %s", r.body)
+	}
+}
+
+func (s *suite) testInvalidWait(t *testing.T) {
+	operationID := s.startAsync(t, s.options.AsyncOperation, []byte(`{}`))
+	r := s.do(t, "GET", []string{s.options.AsyncOperation, operationID, "result"}, url.Values{nexus.QueryWait: {"soon"}}, nil, nil)
+	requireFailure(t, r, http.StatusBadRequest)
+}
+
+func (s *suite) testUnknownOperationID(t *testing.T) {
+	operationID := "conformance-unknown-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	path := []string{s.options.AsyncOperation, operationID}
+	requireFailure(t, s.do(t, "GET", path, nil, nil, nil), http.StatusNotFound)
+	requireFailure(t, s.do(t, "GET", append(path, "result"), nil, nil, nil), http.StatusNotFound)
+	requireFailure(t, s.do(t, "POST", append(path, "cancel"), nil, nil, nil), http.StatusNotFound)
+}
+
+func (s *suite) testCancel(t *testing.T) {
+	operationID := s.startAsync(t, s.options.AsyncOperation, []byte(`{}`))
+	r := s.do(t, "POST", []string{s.options.AsyncOperation, operationID, "cancel"}, nil, nil, nil)
+	requireStatus(t, r, http.StatusAccepted)
+	if len(strings.TrimSpace(string(r.body))) != 0 {
+		t.Fatalf("expected an empty cancel response body, got %q", r.body)
+	}
+}
+
+func (s *suite) testLongPoll(t *testing.T) {
+	operationID := s.startAsync(t, s.options.PendingOperation, []byte(`{}`))
+	path := []string{s.options.PendingOperation, operationID}
+	t.Cleanup(func() {
+		s.do(t, "POST", append(path, "cancel"), nil, nil, nil)
+	})
+
+	r := s.do(t, "GET", append(path, "result"), nil, nil, nil)
+	requireStatus(t, r, nexus.StatusOperationRunning)
+
+	// Long polls are held for the wait duration and answered with either 408 or the operation running status.
+	wait := 200 * time.Millisecond
+	start := time.Now()
+	r = s.do(t, "GET", append(path, "result"), url.Values{nexus.QueryWait: {nexus.FormatDurationParam(wait)}}, nil, nil)
+	requireStatus(t, r, http.StatusRequestTimeout, nexus.StatusOperationRunning)
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Fatalf("long poll returned after %v, before the wait of %v elapsed", elapsed, wait)
+	}
+
+	// The Request-Timeout header bounds long polls with a longer wait.
+	start = time.Now()
+	r = s.do(t, "GET", append(path, "result"), url.Values{nexus.QueryWait: {nexus.FormatDurationParam(time.Minute)}}, http.Header{nexus.HeaderRequestTimeout: {wait.String()}}, nil)
+	requireStatus(t, r, http.StatusRequestTimeout, nexus.StatusOperationRunning)
+	if elapsed := time.Since(start); elapsed > s.options.CompletionTimeout {
+		t.Fatalf("long poll was not bounded by the Request-Timeout header, returned after %v", elapsed)
+	}
+}
+
+func (s *suite) testCancelPending(t *testing.T) {
+	operationID := s.startAsync(t, s.options.PendingOperation, []byte(`{}`))
+	path := []string{s.options.PendingOperation, operationID}
+	requireStatus(t, s.do(t, "POST", append(path, "cancel"), nil, nil, nil), http.StatusAccepted)
+
+	deadline := time.Now().Add(s.options.CompletionTimeout)
+	for {
+		r := s.do(t, "GET", append(path, "result"), url.Values{nexus.QueryWait: {nexus.FormatDurationParam(time.Second)}}, nil, nil)
+		if r.StatusCode == nexus.StatusOperationFailed {
+			if state := r.Header.Get(nexus.HeaderOperationState); state != string(nexus.OperationStateCanceled) {
+				t.Fatalf("expected %s header %q, got %q", nexus.HeaderOperationState, nexus.OperationStateCanceled, state)
+			}
+			requireFailure(t, r, nexus.StatusOperationFailed)
+			break
+		}
+		requireStatus(t, r, http.StatusRequestTimeout, nexus.StatusOperationRunning)
+		if time.Now().After(deadline) {
+			t.Fatalf("operation was not canceled within %v", s.options.CompletionTimeout)
+		}
+	}
+
+	r := s.do(t, "GET", path, nil, nil, nil)
+	requireStatus(t, r, http.StatusOK)
+	var info nexus.OperationInfo
+	requireJSON(t, r, &info)
+	if info.State != nexus.OperationStateCanceled {
+		t.Fatalf("expected state %q after cancelation, got %q", nexus.OperationStateCanceled, info.State)
+	}
+}
@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+// echoHandler completes the "echo" operation synchronously and all other operations asynchronously, echoing their
+// input, except for the "pending" operation, which runs until canceled.
+type echoHandler struct {
+	*nexus.AsyncHandler
+}
+
+func (h *echoHandler) StartOperation(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error) {
+	if operation != "echo" {
+		return h.AsyncHandler.StartOperation(ctx, operation, input, options)
+	}
+	data, err := io.ReadAll(input.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &nexus.HandlerStartOperationResultSync[any]{Value: &nexus.Content{Header: input.Reader.Header, Data: data}}, nil
+}
+
+func newEchoHandler(t *testing.T) nexus.Handler {
+	handler, err := nexus.NewAsyncHandler(nexus.AsyncHandlerOptions{
+		Store: nexus.NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (any, error) {
+			if operation == "pending" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			var v any
+			err := input.Consume(&v)
+			return v, err
+		},
+	})
+	require.NoError(t, err)
+	return &echoHandler{handler}
+}
+
+func TestRun_Handler(t *testing.T) {
+	Run(t, Options{
+		Handler:          nexus.NewHTTPHandler(nexus.HandlerOptions{Handler: newEchoHandler(t)}),
+		EchoOperation:    "echo",
+		AsyncOperation:   "echo-async",
+		PendingOperation: "pending",
+	})
+}
+
+func TestRun_ServiceBaseURL(t *testing.T) {
+	server := httptest.NewServer(nexus.NewHTTPHandler(nexus.HandlerOptions{
+		Handler:          newEchoHandler(t),
+		StrictValidation: true,
+	}))
+	defer server.Close()
+
+	Run(t, Options{
+		ServiceBaseURL:   server.URL + "/",
+		EchoOperation:    "echo",
+		AsyncOperation:   "echo-async",
+		PendingOperation: "pending",
+	})
+}
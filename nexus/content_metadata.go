@@ -0,0 +1,69 @@
+package nexus
+
+import (
+	"mime"
+	"net/http"
+)
+
+// ContentDigest computes a SHA-256 digest of data in the format of the HTTP Content-Digest header (RFC 9530), for use
+// as the "digest" content header.
+//
+// Content received by clients and handlers with a digest is verified as it is read: the start input, results, result
+// pages and parts, claimed results and completions. Reading content whose data does not match its digest fails with an
+// [IntegrityError] once all data is read. See [ChecksumAlgorithm] for the supported algorithms, digests of other
+// algorithms are not verified.
+func ContentDigest(data []byte) string {
+	return ChecksumSHA256.Digest(data)
+}
+
+// SetDigest sets the "digest" content header to the [ContentDigest] of the content's data, allowing receivers to
+// detect corrupted content.
+func (c *Content) SetDigest() {
+	if c.Header == nil {
+		c.Header = Header{}
+	}
+	c.Header["digest"] = ContentDigest(c.Data)
+}
+
+// VerifyDigest verifies the content's data against the digest in its "digest" content header, returning an
+// [IntegrityError] if it does not match. Content without a digest of a supported algorithm is not verified.
+func (c *Content) VerifyDigest() error {
+	for _, d := range parseContentDigest(c.Header.Get("digest")) {
+		if err := d.verify(d.algorithm.sum(c.Data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filename returns the name of the file content was attached as, taken from the "disposition" content header, which
+// is transmitted as the Content-Disposition HTTP header. Returns an empty string if the content has no filename.
+func (h Header) Filename() string {
+	_, params, err := mime.ParseMediaType(h.Get("disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
+
+// SetFilename sets the "disposition" content header to attach content as a file with the given name, e.g. to preserve
+// the name of a file artifact passed as operation input or result. Non ASCII names are encoded as defined in RFC 2231.
+func (h Header) SetFilename(filename string) {
+	h["disposition"] = mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// readerFromHTTPResponse creates a [Reader] for the content of a response, verifying its digest as it is read. The
+// digest is dropped if the HTTP transport transparently decoded the content, since it covers the encoded data.
+func readerFromHTTPResponse(response *http.Response) *Reader {
+	header := prefixStrippedHTTPHeaderToNexusHeader(response.Header, "content-")
+	if response.Uncompressed {
+		delete(header, "digest")
+	}
+	return &Reader{verifyingReader(response.Body, header), header}
+}
+
+// readerFromHTTPRequest creates a [Reader] for the content of a request, verifying its digest as it is read.
+func readerFromHTTPRequest(request *http.Request) *Reader {
+	header := prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-")
+	return &Reader{verifyingReader(request.Body, header), header}
+}
@@ -0,0 +1,131 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderFilename(t *testing.T) {
+	header := Header{}
+	require.Equal(t, "", header.Filename())
+	header.SetFilename("report.pdf")
+	require.Equal(t, `attachment; filename=report.pdf`, header["disposition"])
+	require.Equal(t, "report.pdf", header.Filename())
+	header.SetFilename("relevé.pdf")
+	require.Equal(t, "relevé.pdf", header.Filename())
+	header["disposition"] = "invalid;"
+	require.Equal(t, "", header.Filename())
+}
+
+func TestContentDigest(t *testing.T) {
+	content := &Content{Data: []byte("artifact")}
+	require.NoError(t, content.VerifyDigest())
+	content.SetDigest()
+	require.Equal(t, ContentDigest([]byte("artifact")), content.Header["digest"])
+	require.NoError(t, content.VerifyDigest())
+
+	content.Data = []byte("corrupted")
+	require.ErrorIs(t, content.VerifyDigest(), ErrContentDigestMismatch)
+	// Digests of other algorithms are not verified.
+	content.Header["digest"] = "md5=:AAAA:"
+	require.NoError(t, content.VerifyDigest())
+	content.Header["digest"] = "md5=:AAAA:, " + ContentDigest([]byte("corrupted"))
+	require.NoError(t, content.VerifyDigest())
+	content.Header["digest"] = "sha-256=:not base64:"
+	require.ErrorIs(t, content.VerifyDigest(), ErrContentDigestMismatch)
+}
+
+func TestContentMetadata_StartAndResult(t *testing.T) {
+	ctx, client, teardown := setup(t, &echoHandler{})
+	defer teardown()
+
+	input := &Content{Header: Header{"type": "application/pdf"}, Data: []byte("%PDF-1.7")}
+	input.Header.SetFilename("report.pdf")
+	input.SetDigest()
+	result, err := client.StartOperation(ctx, "foo", input, StartOperationOptions{Header: Header{"input-type": "content"}})
+	require.NoError(t, err)
+	value := result.Successful
+	data, err := io.ReadAll(value.Reader)
+	require.NoError(t, err)
+	require.Equal(t, input.Data, data)
+	require.Equal(t, "report.pdf", value.Reader.Header.Filename())
+	require.Equal(t, input.Header["digest"], value.Reader.Header.Get("digest"))
+
+	input.Data = []byte("%PDF-corrupted")
+	_, err = client.StartOperation(ctx, "foo", input, StartOperationOptions{Header: Header{"input-type": "content"}})
+	require.Error(t, err)
+}
+
+func TestContentMetadata_ResultDigestMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/octet-stream")
+		writer.Header().Set("Content-Digest", ContentDigest([]byte("artifact")))
+		_, _ = writer.Write([]byte("corrupted"))
+	}))
+	defer server.Close()
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+
+	result, err := client.StartOperation(context.Background(), "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var data []byte
+	require.ErrorIs(t, result.Successful.Consume(&data), ErrContentDigestMismatch)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	value, err := handle.GetResult(context.Background(), GetOperationResultOptions{})
+	require.NoError(t, err)
+	_, err = io.ReadAll(value.Reader)
+	require.ErrorIs(t, err, ErrContentDigestMismatch)
+}
+
+type contentRecordingCompletionHandler struct {
+	contents chan *Content
+}
+
+func (h *contentRecordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	data, err := io.ReadAll(completion.Result.Reader)
+	if err != nil {
+		return err
+	}
+	h.contents <- &Content{Header: completion.Result.Reader.Header, Data: data}
+	return nil
+}
+
+func TestContentMetadata_Completion(t *testing.T) {
+	transformers := []ContentTransformer{NewGzipTransformer(10)}
+	handler := &contentRecordingCompletionHandler{contents: make(chan *Content, 1)}
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{Handler: handler, Transformers: transformers}))
+	defer server.Close()
+
+	complete := func(content *Content, transformers []ContentTransformer) int {
+		completion, err := NewOperationCompletionSuccessful(content, OperationCompletionSuccesfulOptions{Transformers: transformers})
+		require.NoError(t, err)
+		request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	content := &Content{Header: Header{"type": "text/plain"}, Data: []byte(strings.Repeat("compressible", 100))}
+	content.Header.SetFilename("log.txt")
+	content.SetDigest()
+	// The digest of compressed content is updated on the wire and restored once decompressed.
+	require.Equal(t, http.StatusOK, complete(content, transformers))
+	received := <-handler.contents
+	require.Equal(t, content.Data, received.Data)
+	require.Equal(t, "log.txt", received.Header.Filename())
+	require.Equal(t, content.Header["digest"], received.Header["digest"])
+	require.NoError(t, received.VerifyDigest())
+
+	content.Header["digest"] = ContentDigest([]byte("other"))
+	require.Equal(t, http.StatusBadRequest, complete(content, nil))
+}
@@ -0,0 +1,145 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Deadline(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		deadlines <- options.Deadline
+		// Ignore cancelation to verify that the deadline is enforced regardless.
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	defer teardown()
+
+	deadline := time.Now().Add(100 * time.Millisecond).Truncate(time.Millisecond)
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Deadline: deadline})
+	require.NoError(t, err)
+	require.True(t, deadline.Equal(<-deadlines))
+
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	require.True(t, deadline.Equal(*info.Deadline))
+
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: 200 * time.Millisecond})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateFailed, unsuccessfulOperationError.State)
+	require.Equal(t, "operation deadline exceeded", unsuccessfulOperationError.Failure.Message)
+}
+
+func TestAsyncHandler_DeadlineEnforcedOnRead(t *testing.T) {
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: store,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	// Simulate an operation started by a process that has since exited.
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "orphan", State: OperationStateRunning, Deadline: time.Now().Add(-time.Second)}))
+
+	handle, err := client.NewHandle("foo", "orphan")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateFailed, info.State)
+}
+
+func TestStartOperation_InvalidDeadlineHeader(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, nil
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Header: Header{HeaderOperationDeadline: "soon"}})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+}
+
+type deadlineRecordingHandler struct {
+	UnimplementedHandler
+	timeouts chan time.Duration
+}
+
+func (h *deadlineRecordingHandler) recordTimeout(ctx context.Context) {
+	if deadline, ok := ctx.Deadline(); ok {
+		h.timeouts <- time.Until(deadline)
+	} else {
+		h.timeouts <- 0
+	}
+}
+
+func (h *deadlineRecordingHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.recordTimeout(ctx)
+	return &HandlerStartOperationResultSync[any]{}, nil
+}
+
+func (h *deadlineRecordingHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	h.recordTimeout(ctx)
+	return nil
+}
+
+func TestRequestTimeout_Clamped(t *testing.T) {
+	handler := &deadlineRecordingHandler{timeouts: make(chan time.Duration, 1)}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:           handler,
+		MinRequestTimeout: time.Second,
+		MaxRequestTimeout: 2 * time.Second,
+	}, ClientOptions{})
+	defer teardown()
+
+	for _, tc := range []struct {
+		name     string
+		timeout  time.Duration
+		min, max time.Duration
+	}{
+		{name: "within bounds", timeout: 1500 * time.Millisecond, min: time.Second, max: 1500 * time.Millisecond},
+		{name: "below min", timeout: 100 * time.Millisecond, min: 900 * time.Millisecond, max: time.Second},
+		{name: "above max", timeout: time.Minute, min: time.Second, max: 2 * time.Second},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requestCtx, cancel := context.WithTimeout(ctx, tc.timeout)
+			defer cancel()
+			_, err := client.StartOperation(requestCtx, "foo", nil, StartOperationOptions{})
+			require.NoError(t, err)
+			timeout := <-handler.timeouts
+			require.Greater(t, timeout, tc.min)
+			require.LessOrEqual(t, timeout, tc.max)
+		})
+	}
+
+	// Requests without a timeout are not bounded.
+	_, err := client.StartOperation(context.Background(), "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), <-handler.timeouts)
+}
+
+func TestRequestTimeout_Cancel(t *testing.T) {
+	handler := &deadlineRecordingHandler{timeouts: make(chan time.Duration, 1)}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:           handler,
+		MaxRequestTimeout: time.Second,
+	}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	timeout := <-handler.timeouts
+	require.Greater(t, timeout, time.Duration(0))
+	require.LessOrEqual(t, timeout, time.Second)
+}
@@ -0,0 +1,93 @@
+package nexus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A DedupeCache maps start request IDs to the IDs of the operations they started, allowing handlers to deduplicate
+// retried start requests.
+//
+// Implementations must be safe for concurrent use.
+type DedupeCache interface {
+	// GetOrSet atomically associates value with key unless the key is already set, in which case the existing value is
+	// returned and loaded is true.
+	GetOrSet(ctx context.Context, key, value string) (existing string, loaded bool, err error)
+}
+
+// A DedupeCacheDeleter is a [DedupeCache] that supports deleting entries, allowing the [AsyncHandler] to release the
+// request ID of a start request that failed to create its operation, so that retries of the request are not
+// deduplicated against an operation that does not exist. Caches that do not implement it retain such entries until
+// they expire.
+type DedupeCacheDeleter interface {
+	// Delete deletes the entry for key if it is still associated with value. Deleting an entry that does not exist or
+	// is associated with another value is not an error.
+	Delete(ctx context.Context, key, value string) error
+}
+
+type memoryDedupeCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// MemoryDedupeCache is an in-memory [DedupeCache] with a fixed TTL per entry.
+type MemoryDedupeCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]memoryDedupeCacheEntry
+	lastSweep time.Time
+}
+
+// NewMemoryDedupeCache creates a [MemoryDedupeCache] that retains entries for the given TTL.
+// A zero TTL retains entries indefinitely.
+func NewMemoryDedupeCache(ttl time.Duration) *MemoryDedupeCache {
+	return &MemoryDedupeCache{
+		ttl:     ttl,
+		entries: make(map[string]memoryDedupeCacheEntry),
+	}
+}
+
+// GetOrSet implements DedupeCache.
+func (c *MemoryDedupeCache) GetOrSet(ctx context.Context, key, value string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.sweepLocked(now)
+	if entry, ok := c.entries[key]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		return entry.value, true, nil
+	}
+	entry := memoryDedupeCacheEntry{value: value}
+	if c.ttl > 0 {
+		entry.expires = now.Add(c.ttl)
+	}
+	c.entries[key] = entry
+	return value, false, nil
+}
+
+// Delete implements DedupeCacheDeleter.
+func (c *MemoryDedupeCache) Delete(ctx context.Context, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok && entry.value == value {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// sweepLocked removes expired entries at most once per TTL period.
+func (c *MemoryDedupeCache) sweepLocked(now time.Time) {
+	if c.ttl <= 0 || now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+var _ DedupeCache = &MemoryDedupeCache{}
+var _ DedupeCacheDeleter = &MemoryDedupeCache{}
@@ -0,0 +1,51 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDedupeCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryDedupeCache(50 * time.Millisecond)
+
+	value, loaded, err := cache.GetOrSet(ctx, "key", "a")
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, "a", value)
+
+	value, loaded, err = cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, "a", value)
+
+	time.Sleep(60 * time.Millisecond)
+	value, loaded, err = cache.GetOrSet(ctx, "key", "c")
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, "c", value)
+}
+
+func TestMemoryDedupeCache_Delete(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryDedupeCache(time.Minute)
+
+	_, _, err := cache.GetOrSet(ctx, "key", "a")
+	require.NoError(t, err)
+	// Entries associated with another value are retained.
+	require.NoError(t, cache.Delete(ctx, "key", "b"))
+	value, loaded, err := cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, "a", value)
+
+	require.NoError(t, cache.Delete(ctx, "key", "a"))
+	require.NoError(t, cache.Delete(ctx, "missing", "a"))
+	value, loaded, err = cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, "b", value)
+}
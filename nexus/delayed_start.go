@@ -0,0 +1,38 @@
+package nexus
+
+import (
+	"context"
+	"time"
+)
+
+// WaitForStartTime blocks until the given start time, typically [StartOperationOptions.StartTime], for handlers
+// implementing delayed starts by deferring execution in-process. Returns immediately if the start time is zero or has
+// passed, and with the context's error if the context is done first. The wait is measured by the clock attached to
+// the context, see [ClockFromContext].
+//
+// Handlers that must survive restarts while an operation is waiting to start should persist the start time, e.g. in
+// [OperationRecord.StartTime], and wait again when resuming the operation. The [AsyncHandler] does so.
+func WaitForStartTime(ctx context.Context, startTime time.Time) error {
+	if startTime.IsZero() {
+		return ctx.Err()
+	}
+	clock := ClockFromContext(ctx)
+	delay := startTime.Sub(clock.Now())
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// Delayed reports whether the operation should not begin executing until a later time, see
+// [StartOperationOptions.StartTime].
+func (o StartOperationOptions) Delayed() bool {
+	return !o.StartTime.IsZero() && time.Now().Before(o.StartTime)
+}
@@ -0,0 +1,98 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForStartTime(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, WaitForStartTime(ctx, time.Time{}))
+	require.NoError(t, WaitForStartTime(ctx, time.Now().Add(-time.Second)))
+
+	start := time.Now()
+	require.NoError(t, WaitForStartTime(ctx, start.Add(50*time.Millisecond)))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, WaitForStartTime(canceled, time.Now().Add(time.Hour)), context.Canceled)
+}
+
+func TestStartOperationOptionsFromHTTPRequest_StartTime(t *testing.T) {
+	startTime := time.Now().Add(time.Hour).UTC()
+	request, err := NewStartOperationHTTPRequest(context.Background(), "http://localhost/nexus", "op", &Reader{}, StartOperationOptions{
+		StartTime: startTime,
+	})
+	require.NoError(t, err)
+	options, err := StartOperationOptionsFromHTTPRequest(request)
+	require.NoError(t, err)
+	require.True(t, startTime.Equal(options.StartTime))
+	require.True(t, options.Delayed())
+
+	// The later of the start time and delay wins.
+	request.Header.Set(HeaderOperationStartDelay, FormatDurationParam(2*time.Hour))
+	options, err = StartOperationOptionsFromHTTPRequest(request)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, options.StartDelay)
+	require.WithinDuration(t, time.Now().Add(2*time.Hour), options.StartTime, time.Minute)
+
+	request.Header.Set(HeaderOperationStartDelay, "-1s")
+	_, err = StartOperationOptionsFromHTTPRequest(request)
+	require.ErrorContains(t, err, HeaderOperationStartDelay)
+
+	request, err = http.NewRequest("POST", "http://localhost/nexus/op", nil)
+	require.NoError(t, err)
+	request.Header.Set(HeaderOperationStartTime, "later")
+	_, err = StartOperationOptionsFromHTTPRequest(request)
+	require.ErrorContains(t, err, HeaderOperationStartTime)
+}
+
+func TestAsyncHandler_DelayedStart(t *testing.T) {
+	started := make(chan time.Time, 1)
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		started <- time.Now()
+		return "done", nil
+	})
+	defer teardown()
+
+	start := time.Now()
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{StartDelay: 200 * time.Millisecond})
+	require.NoError(t, err)
+	handle := result.Pending
+
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	require.NotNil(t, info.StartTime)
+	require.WithinDuration(t, start.Add(200*time.Millisecond), *info.StartTime, 100*time.Millisecond)
+
+	value, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, value.Consume(&output))
+	require.Equal(t, "done", output)
+	require.GreaterOrEqual(t, (<-started).Sub(start), 200*time.Millisecond)
+}
+
+func TestAsyncHandler_CancelDelayedStart(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		t.Error("delayed operation executed after cancelation")
+		return nil, nil
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{StartTime: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
+}
@@ -0,0 +1,351 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default duration failing endpoints are excluded from selection, see [ClientOptions.EndpointCooldown].
+const defaultEndpointCooldown = 30 * time.Second
+
+var errServiceBaseURLWithEndpoints = errors.New("ServiceBaseURL cannot be combined with ServiceEndpoints or Resolver")
+
+var errResolverWithEndpoints = errors.New("Resolver cannot be combined with ServiceEndpoints")
+
+var errEmptyServiceName = errors.New("empty ServiceName")
+
+func validateEndpointOptions(options ClientOptions) error {
+	if options.ServiceBaseURL != "" {
+		return errServiceBaseURLWithEndpoints
+	}
+	if options.Resolver != nil {
+		if len(options.ServiceEndpoints) > 0 {
+			return errResolverWithEndpoints
+		}
+		if options.ServiceName == "" {
+			return errEmptyServiceName
+		}
+	}
+	return nil
+}
+
+// A ServiceEndpoint is one of multiple base URLs serving the same service, e.g. in different regions, see
+// [ClientOptions.ServiceEndpoints].
+type ServiceEndpoint struct {
+	// Base URL of the service at this endpoint.
+	URL string
+	// Relative share of requests sent to this endpoint by [NewWeightedEndpointSelector]. Defaults to 1.
+	Weight int
+}
+
+// An EndpointSelector picks the endpoint each request of a multi-endpoint client is sent to, see
+// [ClientOptions.EndpointSelector].
+//
+// Implementations must be safe for concurrent use.
+type EndpointSelector interface {
+	// Select returns the index of the endpoint to send a request to. Endpoints are the currently healthy endpoints in
+	// the order they were configured in, and are never empty.
+	Select(endpoints []ServiceEndpoint) int
+}
+
+type roundRobinEndpointSelector struct {
+	next atomic.Uint64
+}
+
+// NewRoundRobinEndpointSelector creates an [EndpointSelector] that distributes requests evenly across endpoints.
+func NewRoundRobinEndpointSelector() EndpointSelector {
+	return &roundRobinEndpointSelector{}
+}
+
+// Select implements EndpointSelector.
+func (s *roundRobinEndpointSelector) Select(endpoints []ServiceEndpoint) int {
+	return int((s.next.Add(1) - 1) % uint64(len(endpoints)))
+}
+
+type priorityEndpointSelector struct{}
+
+// NewPriorityEndpointSelector creates an [EndpointSelector] that sends all requests to the first healthy endpoint in
+// the configured order, failing over to the next endpoint while it is excluded.
+func NewPriorityEndpointSelector() EndpointSelector {
+	return priorityEndpointSelector{}
+}
+
+// Select implements EndpointSelector.
+func (priorityEndpointSelector) Select(endpoints []ServiceEndpoint) int {
+	return 0
+}
+
+type weightedEndpointSelector struct{}
+
+// NewWeightedEndpointSelector creates an [EndpointSelector] that picks endpoints at random, proportionally to their
+// [ServiceEndpoint.Weight].
+func NewWeightedEndpointSelector() EndpointSelector {
+	return weightedEndpointSelector{}
+}
+
+// Select implements EndpointSelector.
+func (weightedEndpointSelector) Select(endpoints []ServiceEndpoint) int {
+	total := 0
+	for _, endpoint := range endpoints {
+		total += endpointWeight(endpoint)
+	}
+	n := rand.Intn(total)
+	for i, endpoint := range endpoints {
+		if n -= endpointWeight(endpoint); n < 0 {
+			return i
+		}
+	}
+	return len(endpoints) - 1
+}
+
+func endpointWeight(endpoint ServiceEndpoint) int {
+	if endpoint.Weight <= 0 {
+		return 1
+	}
+	return endpoint.Weight
+}
+
+// resolvedEndpoint is a [ServiceEndpoint] with its parsed URL.
+type resolvedEndpoint struct {
+	ServiceEndpoint
+	url *url.URL
+}
+
+// endpointSet tracks the endpoints of a multi-endpoint client and their health. Endpoints are either static or
+// periodically resolved via a [Resolver].
+type endpointSet struct {
+	// Base URL requests are built against, rewritten to the selected endpoint.
+	base     *url.URL
+	selector EndpointSelector
+	cooldown time.Duration
+	resolver Resolver
+	service  string
+
+	// Serializes resolutions.
+	resolveMu sync.Mutex
+
+	mu        sync.Mutex
+	endpoints []resolvedEndpoint
+	expiresAt time.Time
+	// Keyed by endpoint URL, retained across resolutions.
+	unhealthyUntil map[string]time.Time
+}
+
+func newEndpointSet(options ClientOptions) (*endpointSet, error) {
+	s := &endpointSet{
+		selector:       options.EndpointSelector,
+		cooldown:       options.EndpointCooldown,
+		resolver:       options.Resolver,
+		service:        options.ServiceName,
+		unhealthyUntil: make(map[string]time.Time),
+	}
+	if s.resolver != nil {
+		s.base = &url.URL{Scheme: resolvedBaseURLScheme, Host: options.ServiceName, Path: "/"}
+	} else {
+		endpoints, err := parseServiceEndpoints(options.ServiceEndpoints)
+		if err != nil {
+			return nil, err
+		}
+		s.endpoints = endpoints
+		s.base = endpoints[0].url
+	}
+	if s.selector == nil {
+		s.selector = NewRoundRobinEndpointSelector()
+	}
+	if s.cooldown == 0 {
+		s.cooldown = defaultEndpointCooldown
+	}
+	return s, nil
+}
+
+func parseServiceEndpoints(endpoints []ServiceEndpoint) ([]resolvedEndpoint, error) {
+	parsed := make([]resolvedEndpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		u, err := parseServiceBaseURL(endpoint.URL)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = resolvedEndpoint{endpoint, u}
+	}
+	return parsed, nil
+}
+
+// current returns the current endpoints, resolving them if they expired. Previously resolved endpoints are used
+// while resolution fails.
+func (s *endpointSet) current(ctx context.Context) ([]resolvedEndpoint, error) {
+	s.mu.Lock()
+	endpoints, expired := s.endpoints, s.resolver != nil && !time.Now().Before(s.expiresAt)
+	s.mu.Unlock()
+	if !expired {
+		return endpoints, nil
+	}
+
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	s.mu.Lock()
+	endpoints, expired = s.endpoints, !time.Now().Before(s.expiresAt)
+	s.mu.Unlock()
+	if !expired {
+		// Resolved concurrently.
+		return endpoints, nil
+	}
+	resolved, ttl, err := s.resolver.Resolve(ctx, s.service)
+	if err == nil && len(resolved) == 0 {
+		err = fmt.Errorf("no endpoints resolved for service %q", s.service)
+	}
+	if err == nil {
+		endpoints, err = parseServiceEndpoints(resolved)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if len(s.endpoints) == 0 {
+			return nil, fmt.Errorf("failed to resolve service %q: %w", s.service, err)
+		}
+		s.expiresAt = time.Now().Add(resolveRetryInterval)
+		return s.endpoints, nil
+	}
+	if ttl <= 0 {
+		ttl = defaultResolveTTL
+	}
+	s.endpoints, s.expiresAt = endpoints, time.Now().Add(ttl)
+	return endpoints, nil
+}
+
+// selectEndpoint returns the endpoint to send a request to, skipping endpoints already tried. Unhealthy endpoints are
+// only considered when all untried endpoints are unhealthy. Returns false if all endpoints were tried.
+func (s *endpointSet) selectEndpoint(ctx context.Context, tried map[string]bool) (resolvedEndpoint, bool, error) {
+	endpoints, err := s.current(ctx)
+	if err != nil {
+		return resolvedEndpoint{}, false, err
+	}
+	now := time.Now()
+	var healthy, untried []resolvedEndpoint
+	s.mu.Lock()
+	for _, endpoint := range endpoints {
+		if tried[endpoint.URL] {
+			continue
+		}
+		untried = append(untried, endpoint)
+		if now.After(s.unhealthyUntil[endpoint.URL]) {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	s.mu.Unlock()
+	if len(healthy) == 0 {
+		healthy = untried
+	}
+	if len(healthy) == 0 {
+		return resolvedEndpoint{}, false, nil
+	}
+	candidates := make([]ServiceEndpoint, len(healthy))
+	for i, endpoint := range healthy {
+		candidates[i] = endpoint.ServiceEndpoint
+	}
+	return healthy[s.selector.Select(candidates)], true, nil
+}
+
+func (s *endpointSet) report(endpoint resolvedEndpoint, healthy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if healthy {
+		delete(s.unhealthyUntil, endpoint.URL)
+	} else {
+		s.unhealthyUntil[endpoint.URL] = time.Now().Add(s.cooldown)
+	}
+}
+
+// exclude excludes an endpoint from selection for the given duration, e.g. as requested via Retry-After.
+func (s *endpointSet) exclude(endpoint resolvedEndpoint, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unhealthyUntil[endpoint.URL] = time.Now().Add(duration)
+}
+
+// rewrite returns a copy of u targeting the given endpoint instead of the base URL requests are built against. URLs
+// outside of the base URL are returned unchanged.
+func (s *endpointSet) rewrite(u *url.URL, target *url.URL) *url.URL {
+	basePath := strings.TrimSuffix(s.base.Path, "/")
+	if u.Scheme != s.base.Scheme || u.Host != s.base.Host || !strings.HasPrefix(u.Path, basePath) {
+		return u
+	}
+	rewritten := *u
+	rewritten.Scheme, rewritten.Host = target.Scheme, target.Host
+	rewritten.Path = strings.TrimSuffix(target.Path, "/") + strings.TrimPrefix(u.Path, basePath)
+	rewritten.RawPath = ""
+	if u.RawPath != "" {
+		rewritten.RawPath = strings.TrimSuffix(target.EscapedPath(), "/") + strings.TrimPrefix(u.RawPath, strings.TrimSuffix(s.base.EscapedPath(), "/"))
+	}
+	return &rewritten
+}
+
+// endpointHTTPCaller wraps caller to send requests to the endpoints selected by the set. Endpoints failing with a
+// transport error or a 502, 503 or 504 response are excluded from selection for the set's cooldown, or for the delay
+// of the Retry-After header of 503 responses. Requests failing
+// with a transport error are retried on another endpoint if their body can be replayed.
+func endpointHTTPCaller(caller func(*http.Request) (*http.Response, error), s *endpointSet) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		tried := make(map[string]bool)
+		var lastErr error
+		for {
+			endpoint, ok, err := s.selectEndpoint(request.Context(), tried)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, lastErr
+			}
+			tried[endpoint.URL] = true
+			attempt := request.Clone(request.Context())
+			attempt.URL = s.rewrite(request.URL, endpoint.url)
+			attempt.Host = ""
+			response, err := caller(attempt)
+			if err != nil {
+				s.report(endpoint, false)
+				if request.Context().Err() != nil || !rewindBody(request) {
+					return nil, err
+				}
+				lastErr = err
+				continue
+			}
+			switch response.StatusCode {
+			case http.StatusServiceUnavailable:
+				if retryAfter := retryAfterFromHTTPHeader(response.Header); retryAfter > 0 {
+					s.exclude(endpoint, retryAfter)
+				} else {
+					s.report(endpoint, false)
+				}
+			case http.StatusBadGateway, http.StatusGatewayTimeout:
+				s.report(endpoint, false)
+			default:
+				s.report(endpoint, true)
+			}
+			return response, nil
+		}
+	}
+}
+
+// rewindBody resets the body of a request for sending it again, returning false if the body cannot be replayed.
+func rewindBody(request *http.Request) bool {
+	if request.Body == nil || request.Body == http.NoBody {
+		return true
+	}
+	if request.GetBody == nil {
+		return false
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return false
+	}
+	request.Body = body
+	return true
+}
@@ -0,0 +1,150 @@
+package nexus
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// namedInfoHandler responds to info requests with its name as the operation ID, identifying the endpoint a request
+// was served by.
+type namedInfoHandler struct {
+	UnimplementedHandler
+	name string
+}
+
+func (h *namedInfoHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return &OperationInfo{ID: h.name, State: OperationStateRunning}, nil
+}
+
+func newNamedServer(t *testing.T, name, path string) *httptest.Server {
+	handler := http.StripPrefix(path, NewHTTPHandler(HandlerOptions{Handler: &namedInfoHandler{name: name}}))
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func servedBy(t *testing.T, client *Client) string {
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(context.Background(), GetOperationInfoOptions{})
+	require.NoError(t, err)
+	return info.ID
+}
+
+func TestServiceEndpoints_RoundRobin(t *testing.T) {
+	a := newNamedServer(t, "a", "/a")
+	b := newNamedServer(t, "b", "/b")
+	client, err := NewClient(ClientOptions{ServiceEndpoints: []ServiceEndpoint{{URL: a.URL + "/a"}, {URL: b.URL + "/b/"}}})
+	require.NoError(t, err)
+
+	require.Equal(t, "a", servedBy(t, client))
+	require.Equal(t, "b", servedBy(t, client))
+	require.Equal(t, "a", servedBy(t, client))
+}
+
+func TestServiceEndpoints_Failover(t *testing.T) {
+	// Reserve an address nothing listens on.
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	down := "http://" + listener.Addr().String()
+	listener.Close()
+	b := newNamedServer(t, "b", "")
+
+	client, err := NewClient(ClientOptions{
+		ServiceEndpoints: []ServiceEndpoint{{URL: down}, {URL: b.URL}},
+		EndpointSelector: NewPriorityEndpointSelector(),
+	})
+	require.NoError(t, err)
+
+	// The request is retried on the next endpoint and the failing endpoint is excluded afterwards.
+	require.Equal(t, "b", servedBy(t, client))
+	require.Equal(t, "b", servedBy(t, client))
+}
+
+func TestServiceEndpoints_ExcludeUnavailable(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	b := newNamedServer(t, "b", "")
+
+	client, err := NewClient(ClientOptions{
+		ServiceEndpoints: []ServiceEndpoint{{URL: unavailable.URL}, {URL: b.URL}},
+		EndpointSelector: NewPriorityEndpointSelector(),
+	})
+	require.NoError(t, err)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(context.Background(), GetOperationInfoOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusServiceUnavailable, unexpectedError.Response.StatusCode)
+	require.Equal(t, "b", servedBy(t, client))
+}
+
+func TestWeightedEndpointSelector(t *testing.T) {
+	selector := NewWeightedEndpointSelector()
+	endpoints := []ServiceEndpoint{{URL: "a", Weight: 9}, {URL: "b"}}
+	counts := make([]int, 2)
+	for i := 0; i < 1000; i++ {
+		counts[selector.Select(endpoints)]++
+	}
+	require.Greater(t, counts[0], 800)
+	require.Greater(t, counts[1], 0)
+}
+
+func TestEndpointSet_Rewrite(t *testing.T) {
+	s, err := newEndpointSet(ClientOptions{ServiceEndpoints: []ServiceEndpoint{
+		{URL: "http://a.example.com/svc/"},
+		{URL: "https://b.example.com/other"},
+	}})
+	require.NoError(t, err)
+
+	u, err := url.Parse("http://a.example.com/svc/op%2Fname/id")
+	require.NoError(t, err)
+	require.Equal(t, "https://b.example.com/other/op%2Fname/id", s.rewrite(u, s.endpoints[1].url).String())
+	require.Equal(t, u.String(), s.rewrite(u, s.endpoints[0].url).String())
+
+	u, err = url.Parse("https://results.example.com/blob")
+	require.NoError(t, err)
+	require.Same(t, u, s.rewrite(u, s.endpoints[1].url))
+}
+
+func TestNewClient_ServiceBaseURLWithEndpoints(t *testing.T) {
+	_, err := NewClient(ClientOptions{ServiceBaseURL: "http://a", ServiceEndpoints: []ServiceEndpoint{{URL: "http://b"}}})
+	require.ErrorIs(t, err, errServiceBaseURLWithEndpoints)
+	_, err = NewClient(ClientOptions{ServiceEndpoints: []ServiceEndpoint{{URL: "ftp://b"}}})
+	require.ErrorIs(t, err, errInvalidURLScheme)
+}
+
+func TestServiceEndpoints_ExcludeForRetryAfter(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Retry-After", "3600")
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	b := newNamedServer(t, "b", "")
+
+	client, err := NewClient(ClientOptions{
+		ServiceEndpoints: []ServiceEndpoint{{URL: unavailable.URL}, {URL: b.URL}},
+		EndpointSelector: NewPriorityEndpointSelector(),
+		EndpointCooldown: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(context.Background(), GetOperationInfoOptions{})
+	require.Error(t, err)
+	// The endpoint is excluded for the requested delay rather than the cooldown.
+	time.Sleep(time.Millisecond)
+	require.Equal(t, "b", servedBy(t, client))
+}
@@ -0,0 +1,114 @@
+package nexus
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Name of the Server-Sent Events event type used to deliver operation state changes.
+const operationEventState = "state"
+
+// Default maximum size of a line of received Server-Sent Events, see [ClientOptions.MaxEventSize].
+const defaultMaxEventSize = 1 << 20
+
+// newEventScanner creates a scanner of the lines of a Server-Sent Events stream, accepting lines of up to maxSize
+// bytes.
+func newEventScanner(reader io.Reader, maxSize int) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, min(maxSize, bufio.MaxScanTokenSize)), maxSize)
+	return scanner
+}
+
+// writeEvent writes a single value, encoded with the given JSON engine, as a Server-Sent Event of the given type.
+func writeEvent(writer io.Writer, engine JSONEngine, event string, v any) error {
+	data, err := engine.marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// readEvent reads the data of the next Server-Sent Event of the given type, skipping events of other types.
+// Events without a type are treated as events of the given type.
+//
+// Returns [io.EOF] once the stream has ended.
+func readEvent(scanner *bufio.Scanner, eventType string) ([]byte, error) {
+	var event string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// An empty line dispatches the event.
+			if data.Len() == 0 || (event != "" && event != eventType) {
+				event = ""
+				data.Reset()
+				continue
+			}
+			return data.Bytes(), nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment, may be used to keep the connection alive.
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// An OperationEventStream is a stream of operation state changes delivered by a handler as Server-Sent Events.
+//
+// Obtain one via [OperationHandle.Watch].
+//
+// ⚠️ The stream must be closed to free up the underlying connection.
+type OperationEventStream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	json     JSONEngine
+}
+
+func newOperationEventStream(response *http.Response, engine JSONEngine, maxEventSize int) *OperationEventStream {
+	return &OperationEventStream{
+		response: response,
+		scanner:  newEventScanner(response.Body, maxEventSize),
+		json:     engine,
+	}
+}
+
+// Next blocks until the next operation state change is received.
+//
+// Returns [io.EOF] once the handler has closed the stream, which typically happens when the operation reaches a
+// terminal state.
+func (s *OperationEventStream) Next() (*OperationInfo, error) {
+	data, err := readEvent(s.scanner, operationEventState)
+	if err != nil {
+		return nil, err
+	}
+	var info OperationInfo
+	if err := s.json.unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Close closes the stream and frees up the underlying connection.
+func (s *OperationEventStream) Close() error {
+	return s.response.Body.Close()
+}
@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FailureError is the error representation of a [Failure] in a chain of failures. It unwraps to the failure's cause,
+// allowing failure chains round-tripped through [UnsuccessfulOperationError], [HandlerError] and
+// [UnexpectedResponseError] to be inspected with [errors.As]:
+//
+//	var failureErr *nexus.FailureError
+//	if errors.As(err, &failureErr) && failureErr.Failure.Type == "InsufficientFunds" {
+//		// ...
+//	}
+type FailureError struct {
+	Failure Failure
+}
+
+// Error implements the error interface.
+func (e *FailureError) Error() string {
+	return e.Failure.Message
+}
+
+// Unwrap returns the cause of the failure as a FailureError, if any.
+func (e *FailureError) Unwrap() error {
+	return e.Failure.causeError()
+}
+
+func (f *Failure) causeError() error {
+	if f.Cause == nil {
+		return nil
+	}
+	return &FailureError{Failure: *f.Cause}
+}
+
+// EncodeDetails sets the failure's details to the encoding/json encoding of v. Failures carry no [JSONEngine]; to
+// encode details with another engine, marshal them with it and set [Failure.Details] directly.
+func (f *Failure) EncodeDetails(v any) error {
+	details, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode failure details: %w", err)
+	}
+	f.Details = details
+	return nil
+}
+
+// DecodeDetails decodes the failure's JSON encoded details into v with encoding/json, see [Failure.EncodeDetails].
+func (f *Failure) DecodeDetails(v any) error {
+	if len(f.Details) == 0 {
+		return errors.New("failure has no details")
+	}
+	return json.Unmarshal(f.Details, v)
+}
+
+// FailureFromError converts an error and the errors it wraps, see [errors.Unwrap], into a chain of failures linked via
+// [Failure.Cause]. Each failure's message is the error's message and its type is the error's Go type, unless the
+// error is a [FailureError], which is converted back into its failure including its causes. Errors wrapping multiple
+// errors are not followed.
+func FailureFromError(err error) Failure {
+	if failureError, ok := err.(*FailureError); ok {
+		return failureError.Failure
+	}
+	failure := Failure{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	if cause := errors.Unwrap(err); cause != nil {
+		c := FailureFromError(cause)
+		failure.Cause = &c
+	}
+	return failure
+}
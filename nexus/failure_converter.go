@@ -0,0 +1,111 @@
+package nexus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// A FailureConverter transforms failures before they are sent over the wire and after they are received, e.g. to
+// redact internal error messages, encrypt details, or map internal failure types to public ones. It is the failure
+// counterpart of a [Serializer].
+//
+// Set it on [HandlerOptions] and [CallbackDeliveryOptions] to encode outgoing failures, and on [ClientOptions] and
+// [CompletionHandlerOptions] to decode incoming failures.
+//
+// Implementations must be safe for concurrent use.
+type FailureConverter interface {
+	// EncodeFailure transforms a failure before it is sent.
+	EncodeFailure(failure Failure) (Failure, error)
+	// DecodeFailure reverses EncodeFailure on a received failure.
+	DecodeFailure(failure Failure) (Failure, error)
+}
+
+type redactingFailureConverter struct {
+	message string
+}
+
+// NewRedactingFailureConverter creates a [FailureConverter] that replaces the message of outgoing failures with the
+// given message and drops their metadata, details, stack traces and causes, keeping only their type and
+// retryability. Received failures are passed through unchanged.
+//
+// Use it on handlers serving external callers to avoid leaking internal error messages.
+func NewRedactingFailureConverter(message string) FailureConverter {
+	return redactingFailureConverter{message: message}
+}
+
+// EncodeFailure implements FailureConverter.
+func (c redactingFailureConverter) EncodeFailure(failure Failure) (Failure, error) {
+	return Failure{Message: c.message, Type: failure.Type, NonRetryable: failure.NonRetryable}, nil
+}
+
+// DecodeFailure implements FailureConverter.
+func (c redactingFailureConverter) DecodeFailure(failure Failure) (Failure, error) {
+	return failure, nil
+}
+
+// encodeFailure encodes failure with the handler's converter, if any. Failures that cannot be encoded are replaced
+// with a generic failure to avoid leaking them.
+func (h *baseHTTPHandler) encodeFailure(failure *Failure) *Failure {
+	if h.failureConverter == nil || failure == nil {
+		return failure
+	}
+	encoded, err := h.failureConverter.EncodeFailure(*failure)
+	if err != nil {
+		h.logger.Error("failed to encode failure", "error", err)
+		return &Failure{Message: "internal server error"}
+	}
+	return &encoded
+}
+
+// encodeInfoFailures returns a copy of info with the failures it carries encoded with the handler's converter.
+func (h *baseHTTPHandler) encodeInfoFailures(info *OperationInfo) *OperationInfo {
+	if h.failureConverter == nil || info == nil || (len(info.AttemptHistory) == 0 && info.Quarantine == nil) {
+		return info
+	}
+	c := *info
+	if len(info.AttemptHistory) > 0 {
+		c.AttemptHistory = make([]OperationAttempt, len(info.AttemptHistory))
+		for i, attempt := range info.AttemptHistory {
+			attempt.Failure = *h.encodeFailure(&attempt.Failure)
+			c.AttemptHistory[i] = attempt
+		}
+	}
+	if info.Quarantine != nil {
+		q := *info.Quarantine
+		q.Failure = *h.encodeFailure(&q.Failure)
+		c.Quarantine = &q
+	}
+	return &c
+}
+
+// failureDecodingHTTPCaller wraps caller to decode the failures in the bodies of failed responses, using the given JSON
+// engine to read and rewrite the bodies.
+func failureDecodingHTTPCaller(caller func(*http.Request) (*http.Response, error), converter FailureConverter, engine JSONEngine) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		response, err := caller(request)
+		if err != nil || response.StatusCode < http.StatusBadRequest || !isMediaTypeJSON(response.Header.Get("Content-Type")) {
+			return response, err
+		}
+		body, err := readAndReplaceBody(response)
+		if err != nil {
+			return nil, err
+		}
+		var failure Failure
+		if err := engine.unmarshal(body, &failure); err != nil {
+			// Not a failure, leave it to the caller to handle the response.
+			return response, nil
+		}
+		decoded, err := converter.DecodeFailure(failure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode failure: %w", err)
+		}
+		if body, err = engine.marshal(decoded); err != nil {
+			return nil, err
+		}
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		response.ContentLength = int64(len(body))
+		return response, nil
+	}
+}
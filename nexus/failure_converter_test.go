@@ -0,0 +1,137 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefixingFailureConverter prefixes outgoing failure messages and strips the prefix from incoming ones, failing on
+// failures that don't carry it.
+type prefixingFailureConverter struct{}
+
+func (prefixingFailureConverter) EncodeFailure(failure Failure) (Failure, error) {
+	failure.Message = "encoded:" + failure.Message
+	return failure, nil
+}
+
+func (prefixingFailureConverter) DecodeFailure(failure Failure) (Failure, error) {
+	message, ok := strings.CutPrefix(failure.Message, "encoded:")
+	if !ok {
+		return failure, fmt.Errorf("failure not encoded: %q", failure.Message)
+	}
+	failure.Message = message
+	return failure, nil
+}
+
+func TestFailureConverter_RoundTrip(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          &failureChainHandler{},
+		FailureConverter: prefixingFailureConverter{},
+	}, ClientOptions{
+		FailureConverter: prefixingFailureConverter{},
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, "payment failed", unsuccessfulError.Failure.Message)
+	require.Equal(t, "PaymentFailed", unsuccessfulError.Failure.Type)
+
+	_, err = client.StartOperation(ctx, "handler-error", nil, StartOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+	require.Equal(t, "payment failed", unexpectedError.Failure.Message)
+}
+
+func TestFailureConverter_DecodeError(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &failureChainHandler{},
+	}, ClientOptions{
+		FailureConverter: prefixingFailureConverter{},
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorContains(t, err, "failed to decode failure: failure not encoded")
+}
+
+func TestFailureConverter_Redacting(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          &failureChainHandler{},
+		FailureConverter: NewRedactingFailureConverter("operation failed"),
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, Failure{Message: "operation failed", Type: "PaymentFailed"}, unsuccessfulError.Failure)
+	require.NotContains(t, err.Error(), "payment failed")
+}
+
+type failingFailureConverter struct{}
+
+func (failingFailureConverter) EncodeFailure(failure Failure) (Failure, error) {
+	return failure, errors.New("cannot encode")
+}
+
+func (failingFailureConverter) DecodeFailure(failure Failure) (Failure, error) {
+	return failure, errors.New("cannot decode")
+}
+
+func TestFailureConverter_EncodeErrorHidesFailure(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          &failureChainHandler{},
+		FailureConverter: failingFailureConverter{},
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "handler-error", nil, StartOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, &Failure{Message: "internal server error"}, unexpectedError.Failure)
+}
+
+func TestFailureConverter_Completion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		// Ignore for test purposes
+		_ = http.Serve(listener, NewCompletionHTTPHandler(CompletionHandlerOptions{
+			Handler:          &failureExpectingCompletionHandler{},
+			FailureConverter: prefixingFailureConverter{},
+		}))
+	}()
+	callbackURL := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	for _, converter := range []FailureConverter{prefixingFailureConverter{}, nil} {
+		request, err := NewCompletionHTTPRequest(ctx, callbackURL, &OperationCompletionUnsuccessful{
+			Header:           http.Header{"foo": []string{"bar"}},
+			State:            OperationStateCanceled,
+			Failure:          &Failure{Message: "expected message"},
+			FailureConverter: converter,
+		})
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		if converter != nil {
+			require.Equal(t, http.StatusOK, response.StatusCode)
+		} else {
+			require.Equal(t, http.StatusBadRequest, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failureChainHandler struct {
+	UnimplementedHandler
+}
+
+func (h *failureChainHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	cause := &Failure{Message: "insufficient funds", Type: "InsufficientFunds", NonRetryable: true}
+	if err := cause.EncodeDetails(map[string]int{"balance": 5}); err != nil {
+		return nil, err
+	}
+	failure := Failure{Message: "payment failed", Type: "PaymentFailed", StackTrace: "main.pay()", Cause: cause}
+	if operation == "handler-error" {
+		return nil, &HandlerError{Type: HandlerErrorTypeBadRequest, Failure: &failure}
+	}
+	return nil, &UnsuccessfulOperationError{State: OperationStateFailed, Failure: failure}
+}
+
+func TestFailureChain_UnsuccessfulOperationError(t *testing.T) {
+	ctx, client, teardown := setup(t, &failureChainHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, "PaymentFailed", unsuccessfulError.Failure.Type)
+	require.Equal(t, "main.pay()", unsuccessfulError.Failure.StackTrace)
+
+	var failureError *FailureError
+	require.ErrorAs(t, err, &failureError)
+	require.Equal(t, "InsufficientFunds", failureError.Failure.Type)
+	require.True(t, failureError.Failure.NonRetryable)
+	var details map[string]int
+	require.NoError(t, failureError.Failure.DecodeDetails(&details))
+	require.Equal(t, 5, details["balance"])
+}
+
+func TestFailureChain_HandlerError(t *testing.T) {
+	ctx, client, teardown := setup(t, &failureChainHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "handler-error", nil, StartOperationOptions{})
+	var failureError *FailureError
+	require.ErrorAs(t, err, &failureError)
+	require.Equal(t, "PaymentFailed", failureError.Failure.Type)
+	require.ErrorAs(t, failureError.Unwrap(), &failureError)
+	require.Equal(t, "InsufficientFunds", failureError.Failure.Type)
+
+	handlerError := &HandlerError{Failure: &Failure{Message: "outer", Cause: &Failure{Message: "inner"}}}
+	require.ErrorAs(t, handlerError, &failureError)
+	require.Equal(t, "inner", failureError.Failure.Message)
+}
+
+type testFailureError struct{}
+
+func (testFailureError) Error() string { return "boom" }
+
+func TestFailureFromError(t *testing.T) {
+	err := fmt.Errorf("outer: %w", &FailureError{Failure: Failure{Message: "middle", Cause: &Failure{Message: "inner"}}})
+	failure := FailureFromError(err)
+	require.Equal(t, "outer: middle", failure.Message)
+	require.Equal(t, "*fmt.wrapError", failure.Type)
+	require.Equal(t, "middle", failure.Cause.Message)
+	require.Equal(t, "inner", failure.Cause.Cause.Message)
+
+	failure = FailureFromError(testFailureError{})
+	require.Equal(t, Failure{Message: "boom", Type: "nexus.testFailureError"}, failure)
+}
+
+func TestFailure_DecodeDetailsWithoutDetails(t *testing.T) {
+	var v any
+	require.Error(t, (&Failure{}).DecodeDetails(&v))
+}
+
+func TestRetryPolicy_NonRetryableFailure(t *testing.T) {
+	policy := RetryPolicy{}
+	require.True(t, policy.retryable(errors.New("transient")))
+	require.True(t, policy.retryable(&FailureError{Failure: Failure{Message: "transient"}}))
+	require.False(t, policy.retryable(fmt.Errorf("call failed: %w", &FailureError{Failure: Failure{NonRetryable: true}})))
+}
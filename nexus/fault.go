@@ -0,0 +1,209 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrFaultInjected is returned by clients for requests dropped by an injected [Fault].
+var ErrFaultInjected = errors.New("request dropped by injected fault")
+
+// Value that headers corrupted by an injected [Fault] are set to.
+const corruptedHeaderValue = "corrupted-by-fault-injection"
+
+// A Fault is injected into requests for resilience testing, see [ClientOptions.Faults], [HandlerOptions.Faults] and
+// [ContextWithFaults]. Faults are meant for testing and staging environments and must not be enabled in production.
+//
+// Clients inject faults before sending requests, handlers before dispatching requests to their Handler. When multiple
+// faults are injected into a request, latencies add up, and the first status code takes effect.
+type Fault struct {
+	// Probability of the fault being injected into a request, between 0 and 1. Zero injects it into every request.
+	Probability float64
+	// Delay added to the request.
+	Latency time.Duration
+	// Drop the request. Clients fail it with [ErrFaultInjected] without sending it, handlers abort it without a
+	// response, which callers observe as a dropped connection.
+	Drop bool
+	// Status code to fail the request with instead of sending it to the server or dispatching it to the Handler.
+	StatusCode int
+	// Names of header fields to corrupt. Clients corrupt request headers, handlers corrupt response headers.
+	CorruptHeaders []string
+}
+
+type faultsContextKey struct{}
+
+// ContextWithFaults returns a context whose requests have the given faults injected, in addition to the faults
+// configured in [ClientOptions.Faults] when passed to client methods, or in [HandlerOptions.Faults] when set on the
+// context of requests to a handler, e.g. by an HTTP middleware.
+func ContextWithFaults(ctx context.Context, faults ...Fault) context.Context {
+	existing, _ := ctx.Value(faultsContextKey{}).([]Fault)
+	combined := make([]Fault, 0, len(existing)+len(faults))
+	combined = append(append(combined, existing...), faults...)
+	return context.WithValue(ctx, faultsContextKey{}, combined)
+}
+
+// injectedFault is the combination of faults injected into a single request.
+type injectedFault struct {
+	latency        time.Duration
+	drop           bool
+	statusCode     int
+	corruptHeaders []string
+}
+
+// selectFaults rolls the configured faults and those set on ctx, returning the combination injected into a request and
+// whether any fault was injected.
+func selectFaults(ctx context.Context, configured []Fault) (injectedFault, bool) {
+	fromContext, _ := ctx.Value(faultsContextKey{}).([]Fault)
+	var injected injectedFault
+	ok := false
+	for _, faults := range [][]Fault{configured, fromContext} {
+		for _, fault := range faults {
+			if fault.Probability > 0 && rand.Float64() >= fault.Probability {
+				continue
+			}
+			ok = true
+			injected.latency += fault.Latency
+			injected.drop = injected.drop || fault.Drop
+			if injected.statusCode == 0 {
+				injected.statusCode = fault.StatusCode
+			}
+			injected.corruptHeaders = append(injected.corruptHeaders, fault.CorruptHeaders...)
+		}
+	}
+	return injected, ok
+}
+
+// delay waits for the fault's latency, returning early with an error if ctx is done.
+func (f injectedFault) delay(ctx context.Context) error {
+	if f.latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(f.latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f injectedFault) corrupt(header http.Header) {
+	for _, name := range f.corruptHeaders {
+		header.Set(name, corruptedHeaderValue)
+	}
+}
+
+// faultInjectingHTTPCaller wraps caller to inject the configured faults and those set on the request context, encoding
+// injected failures with the given JSON engine.
+func faultInjectingHTTPCaller(caller func(*http.Request) (*http.Response, error), faults []Fault, engine JSONEngine) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		fault, ok := selectFaults(request.Context(), faults)
+		if !ok {
+			return caller(request)
+		}
+		if err := fault.delay(request.Context()); err != nil {
+			return nil, err
+		}
+		if fault.drop {
+			return nil, ErrFaultInjected
+		}
+		if fault.statusCode != 0 {
+			if request.Body != nil {
+				request.Body.Close()
+			}
+			return injectedFaultResponse(request, fault.statusCode, engine), nil
+		}
+		fault.corrupt(request.Header)
+		return caller(request)
+	}
+}
+
+// injectedFaultResponse creates a response as a server would fail a request with the given status code.
+func injectedFaultResponse(request *http.Request, statusCode int, engine JSONEngine) *http.Response {
+	body, _ := engine.marshal(&Failure{Message: "injected fault"})
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentTypeJSON}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
+
+// injectFaults injects the given faults and those set on the request context into a request, returning false if the
+// request was failed or dropped and must not be handled further.
+func (h *baseHTTPHandler) injectFaults(writer http.ResponseWriter, request *http.Request, faults []Fault) bool {
+	fault, ok := selectFaults(request.Context(), faults)
+	if !ok {
+		return true
+	}
+	if err := fault.delay(request.Context()); err != nil {
+		return false
+	}
+	if fault.drop {
+		panic(http.ErrAbortHandler)
+	}
+	if len(fault.corruptHeaders) > 0 {
+		if recorder, ok := writer.(*statusRecorder); ok {
+			recorder.ResponseWriter = &headerCorruptingResponseWriter{ResponseWriter: recorder.ResponseWriter, fault: fault}
+		}
+	}
+	if fault.statusCode != 0 {
+		failure := h.encodeFailure(&Failure{Message: "injected fault"})
+		if body, err := h.json.marshal(failure); err == nil {
+			writer.Header().Set("Content-Type", contentTypeJSON)
+			writer.WriteHeader(fault.statusCode)
+			_, _ = writer.Write(body)
+		} else {
+			writer.WriteHeader(fault.statusCode)
+		}
+		return false
+	}
+	return true
+}
+
+// headerCorruptingResponseWriter corrupts the response headers of an injected fault before they are written.
+type headerCorruptingResponseWriter struct {
+	http.ResponseWriter
+	fault   injectedFault
+	written bool
+}
+
+func (w *headerCorruptingResponseWriter) writeHeader() {
+	if !w.written {
+		w.written = true
+		w.fault.corrupt(w.Header())
+	}
+}
+
+func (w *headerCorruptingResponseWriter) WriteHeader(statusCode int) {
+	w.writeHeader()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *headerCorruptingResponseWriter) Write(b []byte) (int, error) {
+	w.writeHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, corrupting headers before they are flushed.
+func (w *headerCorruptingResponseWriter) Flush() {
+	w.writeHeader()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to [http.ResponseController].
+func (w *headerCorruptingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
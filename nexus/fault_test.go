@@ -0,0 +1,106 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// headerEchoHandler responds to start requests with the value of the "x-probe" header.
+type headerEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *headerEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: options.Header.Get("x-probe")}, nil
+}
+
+func startProbe(ctx context.Context, client *Client) (string, error) {
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	if err != nil {
+		return "", err
+	}
+	var s string
+	err = result.Successful.Consume(&s)
+	return s, err
+}
+
+func TestClientFaults(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &headerEchoHandler{}}, ClientOptions{
+		Header: Header{"x-probe": "ok"},
+		Faults: []Fault{{CorruptHeaders: []string{"x-probe"}}},
+	})
+	defer teardown()
+
+	s, err := startProbe(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, corruptedHeaderValue, s)
+
+	_, err = startProbe(ContextWithFaults(ctx, Fault{StatusCode: http.StatusServiceUnavailable}), client)
+	var unexpectedErr *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusServiceUnavailable, unexpectedErr.Response.StatusCode)
+	require.Equal(t, "injected fault", unexpectedErr.Failure.Message)
+
+	_, err = startProbe(ContextWithFaults(ctx, Fault{Drop: true}), client)
+	require.ErrorIs(t, err, ErrFaultInjected)
+
+	latencyCtx, cancel := context.WithTimeout(ContextWithFaults(ctx, Fault{Latency: time.Second}), 50*time.Millisecond)
+	defer cancel()
+	_, err = startProbe(latencyCtx, client)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHandlerFaults_StatusCode(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &headerEchoHandler{},
+		Faults:  []Fault{{StatusCode: http.StatusTooManyRequests}},
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := startProbe(ctx, client)
+	var unexpectedErr *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusTooManyRequests, unexpectedErr.Response.StatusCode)
+}
+
+func TestHandlerFaults_Drop(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &headerEchoHandler{},
+		Faults:  []Fault{{Drop: true}},
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := startProbe(ctx, client)
+	require.Error(t, err)
+	var unexpectedErr *UnexpectedResponseError
+	require.False(t, errors.As(err, &unexpectedErr))
+}
+
+func TestHandlerFaults_CorruptHeaders(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &headerEchoHandler{},
+		Faults:  []Fault{{CorruptHeaders: []string{"Content-Type"}}},
+	}, ClientOptions{Header: Header{"x-probe": "ok"}})
+	defer teardown()
+
+	_, err := startProbe(ctx, client)
+	require.Error(t, err)
+}
+
+func TestSelectFaults(t *testing.T) {
+	ctx := ContextWithFaults(context.Background(), Fault{Latency: time.Second, StatusCode: http.StatusBadGateway})
+	fault, ok := selectFaults(ctx, []Fault{
+		{Latency: time.Second, StatusCode: http.StatusServiceUnavailable},
+		{Probability: 1e-12, Drop: true},
+	})
+	require.True(t, ok)
+	require.Equal(t, injectedFault{latency: 2 * time.Second, statusCode: http.StatusServiceUnavailable}, fault)
+
+	_, ok = selectFaults(context.Background(), []Fault{{Probability: 1e-12, Drop: true}})
+	require.False(t, ok)
+}
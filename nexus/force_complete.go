@@ -0,0 +1,202 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ForceCompleteOperationOptions are options for the ForceCompleteOperation and ForceFailOperation client APIs and the
+// ForceCompleteOperation server API.
+type ForceCompleteOperationOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Terminal state to force the operation into. In server methods, one of succeeded, failed or canceled. In the client
+	// API, the state [Client.ForceFailOperation] forces the operation into, failed or canceled, defaulting to failed.
+	State OperationState
+	// Result supplied by the operator when forcing the operation to succeed. Only set in server methods.
+	Result *LazyValue
+	// Failure supplied by the operator when forcing the operation to fail or to be canceled. Only set in server
+	// methods.
+	Failure *Failure
+}
+
+// forcedFailure is the failure of operations forced to fail without a failure message.
+func forcedFailure(failure *Failure) *Failure {
+	if failure == nil || failure.Message == "" {
+		return &Failure{Message: "operation forcibly completed"}
+	}
+	return failure
+}
+
+// ForceCompleteOperation implements Handler. Running operations are completed with the result or failure supplied
+// by the operator and their execution context is canceled if they are executing in this process, as with
+// [AsyncHandler.CancelOperation]. Operations that already completed are rejected with a bad request error.
+func (h *AsyncHandler) ForceCompleteOperation(ctx context.Context, operation, operationID string, options ForceCompleteOperationOptions) error {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return err
+	}
+	if record.State != OperationStateRunning {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "operation is not running")
+	}
+	switch options.State {
+	case OperationStateSucceeded:
+		var content *Content
+		if options.Result != nil {
+			data, err := readAll(options.Result.Reader)
+			if err != nil {
+				return requestBodyError(err, "failed to read result from request body")
+			}
+			content = &Content{Header: options.Result.Reader.Header, Data: data}
+			delete(content.Header, "length")
+		}
+		// Store before canceling the execution context so the executor's outcome is ignored.
+		if err := h.store(ctx, operation, operationID, OperationStateSucceeded, content, nil); err != nil {
+			return err
+		}
+		h.cancelExecution(operation, operationID)
+		return nil
+	case OperationStateFailed, OperationStateCanceled:
+		return h.stop(ctx, operation, operationID, options.State, forcedFailure(options.Failure))
+	default:
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid operation state: %q", options.State)
+	}
+}
+
+func (h *httpHandler) forceCompleteOperation(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	options := ForceCompleteOperationOptions{
+		Header: httpHeaderToNexusHeader(request.Header, "content-"),
+		State:  OperationState(request.Header.Get(HeaderOperationState)),
+	}
+	switch options.State {
+	case OperationStateSucceeded:
+		options.Result = &LazyValue{
+			serializer: h.options.Serializer,
+			Reader:     readerFromHTTPRequest(request),
+		}
+	case OperationStateFailed, OperationStateCanceled:
+		if !isMediaTypeJSON(request.Header.Get("Content-Type")) {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request content type: %q", request.Header.Get("Content-Type")))
+			return
+		}
+		b, err := readAll(request.Body)
+		if err != nil {
+			h.writeFailure(writer, requestBodyError(err, "failed to read Failure from request body"))
+			return
+		}
+		var failure Failure
+		if err := h.json.unmarshal(b, &failure); err != nil {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read Failure from request body"))
+			return
+		}
+		if h.failureConverter != nil {
+			if failure, err = h.failureConverter.DecodeFailure(failure); err != nil {
+				h.logger.Warn("failed to decode forced failure", "error", err)
+				h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to decode failure"))
+				return
+			}
+		}
+		options.Failure = &failure
+	default:
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request operation state: %q", options.State))
+		return
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodForceCompleteOperation,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	if err := h.options.Handler.ForceCompleteOperation(ctx, operation, operationID, options); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+// ForceCompleteOperation forces an operation to succeed with the given result, e.g. to clean up an operation whose
+// execution is wedged. The result is serialized with the client's serializer. Requires the handler to authorize
+// [HandlerMethodForceCompleteOperation] requests, which should be restricted to privileged callers.
+func (c *Client) ForceCompleteOperation(ctx context.Context, operation, operationID string, result any, options ForceCompleteOperationOptions, opts ...CallOption) error {
+	ctx, c = c.withCallOptions(ctx, opts)
+	completion, err := NewOperationCompletionSuccessful(result, OperationCompletionSuccesfulOptions{
+		Serializer: c.options.Serializer,
+		Checksum:   c.options.Checksum,
+	})
+	if err != nil {
+		return err
+	}
+	return c.forceCompleteOperation(ctx, operation, operationID, completion, options)
+}
+
+// ForceFailOperation forces an operation to fail with the given failure, or to be canceled if
+// [ForceCompleteOperationOptions.State] is canceled, e.g. to clean up an operation whose execution is wedged.
+// Requires the handler to authorize [HandlerMethodForceCompleteOperation] requests, which should be restricted to
+// privileged callers.
+func (c *Client) ForceFailOperation(ctx context.Context, operation, operationID string, failure Failure, options ForceCompleteOperationOptions, opts ...CallOption) error {
+	ctx, c = c.withCallOptions(ctx, opts)
+	state := options.State
+	if state == "" {
+		state = OperationStateFailed
+	}
+	if state != OperationStateFailed && state != OperationStateCanceled {
+		return fmt.Errorf("invalid operation state: %q", state)
+	}
+	completion := &OperationCompletionUnsuccessful{
+		State:            state,
+		Failure:          &failure,
+		FailureConverter: c.options.FailureConverter,
+		JSON:             c.options.JSON,
+	}
+	return c.forceCompleteOperation(ctx, operation, operationID, completion, options)
+}
+
+func (c *Client) forceCompleteOperation(ctx context.Context, operation, operationID string, completion OperationCompletion, options ForceCompleteOperationOptions) error {
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_admin", "complete", url.PathEscape(operation), url.PathEscape(operationID))
+	request, err := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err := completion.applyToHTTPRequest(request); err != nil {
+		return err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_ForceCompleteOperation(t *testing.T) {
+	canceled := make(chan struct{}, 3)
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			canceled <- struct{}{}
+			return "wedged", nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: handler,
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			if request.Method == HandlerMethodForceCompleteOperation && request.Header.Get("role") != "admin" {
+				return HandlerErrorf(HandlerErrorTypeUnauthorized, "admin role required")
+			}
+			return nil
+		}),
+	}, ClientOptions{})
+	defer teardown()
+	admin := ForceCompleteOperationOptions{Header: Header{"role": "admin"}}
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	err = client.ForceCompleteOperation(ctx, "foo", result.Pending.ID, "forced", ForceCompleteOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusForbidden, unexpectedResponseError.Response.StatusCode)
+
+	require.NoError(t, client.ForceCompleteOperation(ctx, "foo", result.Pending.ID, "forced", admin))
+	<-canceled
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, value.Consume(&s))
+	require.Equal(t, "forced", s)
+
+	err = client.ForceFailOperation(ctx, "foo", result.Pending.ID, Failure{Message: "too late"}, admin)
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+
+	result, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.ForceFailOperation(ctx, "foo", result.Pending.ID, Failure{Message: "wedged"}, admin))
+	<-canceled
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateFailed, info.State)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, "wedged", unsuccessfulOperationError.Failure.Message)
+
+	result, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.ForceFailOperation(ctx, "foo", result.Pending.ID, Failure{}, ForceCompleteOperationOptions{
+		Header: admin.Header,
+		State:  OperationStateCanceled,
+	}))
+	<-canceled
+	info, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateCanceled, info.State)
+
+	err = client.ForceFailOperation(ctx, "foo", result.Pending.ID, Failure{}, ForceCompleteOperationOptions{State: OperationStateSucceeded})
+	require.EqualError(t, err, `invalid operation state: "succeeded"`)
+	err = client.ForceCompleteOperation(ctx, "foo", "missing", "forced", admin)
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+}
@@ -2,6 +2,8 @@ package nexus
 
 import (
 	"context"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,7 +22,17 @@ type asyncWithResultHandler struct {
 	timesToBlock     int
 	resultError      error
 	expectTestHeader bool
-	requests         []request
+
+	mu       sync.Mutex
+	requests []request
+}
+
+// getRequests returns the get result requests received so far. Requests may still be in flight when the client gives
+// up waiting, so they must be accessed under the lock.
+func (h *asyncWithResultHandler) getRequests() []request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.requests)
 }
 
 func (h *asyncWithResultHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
@@ -46,7 +58,10 @@ func (h *asyncWithResultHandler) GetOperationResult(ctx context.Context, operati
 	if set {
 		req.deadline = deadline
 	}
+	h.mu.Lock()
 	h.requests = append(h.requests, req)
+	received := len(h.requests)
+	h.mu.Unlock()
 
 	if h.expectTestHeader && options.Header.Get("test") != "ok" {
 		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'test' header: %q", options.Header.Get("test"))
@@ -71,7 +86,7 @@ func (h *asyncWithResultHandler) GetOperationResult(ctx context.Context, operati
 			return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "context deadline invalid, timeout: %v", timeout)
 		}
 	}
-	if len(h.requests) <= h.timesToBlock {
+	if received <= h.timesToBlock {
 		ctx, cancel := context.WithTimeout(ctx, options.Wait)
 		defer cancel()
 		<-ctx.Done()
@@ -82,7 +97,10 @@ func (h *asyncWithResultHandler) GetOperationResult(ctx context.Context, operati
 
 func TestWaitResult(t *testing.T) {
 	handler := asyncWithResultHandler{timesToBlock: 1, expectTestHeader: true}
-	ctx, client, teardown := setup(t, &handler)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		GetResultTimeout: getResultMaxTimeout,
+		Handler:          &handler,
+	}, ClientOptions{WaitStrategy: NewFixedWaitStrategy()})
 	defer teardown()
 
 	response, err := client.ExecuteOperation(ctx, "f/o/o", nil, ExecuteOperationOptions{
@@ -94,11 +112,11 @@ func TestWaitResult(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("body"), body)
 
-	require.Equal(t, 2, len(handler.requests))
-	require.InDelta(t, testTimeout+getResultContextPadding, handler.requests[0].options.Wait, float64(time.Millisecond*50))
-	require.InDelta(t, testTimeout+getResultContextPadding-getResultMaxTimeout, handler.requests[1].options.Wait, float64(time.Millisecond*50))
-	require.Equal(t, "f/o/o", handler.requests[0].operation)
-	require.Equal(t, "a/sync", handler.requests[0].operationID)
+	require.Equal(t, 2, len(handler.getRequests()))
+	require.InDelta(t, testTimeout+getResultContextPadding, handler.getRequests()[0].options.Wait, float64(time.Millisecond*50))
+	require.InDelta(t, testTimeout+getResultContextPadding-getResultMaxTimeout, handler.getRequests()[1].options.Wait, float64(time.Millisecond*50))
+	require.Equal(t, "f/o/o", handler.getRequests()[0].operation)
+	require.Equal(t, "a/sync", handler.getRequests()[0].operationID)
 }
 
 func TestWaitResult_StillRunning(t *testing.T) {
@@ -130,7 +148,7 @@ func TestWaitResult_DeadlineExceeded(t *testing.T) {
 	deadline, _ := ctx.Deadline()
 	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
 	require.ErrorIs(t, err, context.DeadlineExceeded)
-	require.WithinDuration(t, deadline, handler.requests[0].deadline, 1*time.Millisecond)
+	require.WithinDuration(t, deadline, handler.getRequests()[0].deadline, 1*time.Millisecond)
 }
 
 func TestWaitResult_RequestTimeout(t *testing.T) {
@@ -145,9 +163,42 @@ func TestWaitResult_RequestTimeout(t *testing.T) {
 
 	timeout := 200 * time.Millisecond
 	deadline := time.Now().Add(200 * time.Millisecond)
-	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second, Header: Header{headerRequestTimeout: timeout.String()}})
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second, Header: Header{HeaderRequestTimeout: timeout.String()}})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.WithinDuration(t, deadline, handler.getRequests()[0].deadline, 1*time.Millisecond)
+}
+
+func TestWaitResult_SinglePoll(t *testing.T) {
+	handler := &asyncWithResultHandler{timesToBlock: 1}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second, SinglePoll: true})
+	require.ErrorIs(t, err, ErrOperationWaitTimeout)
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Equal(t, 1, len(handler.getRequests()))
+
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second, SinglePoll: true})
+	require.NoError(t, err)
+	var body []byte
+	require.NoError(t, response.Consume(&body))
+	require.Equal(t, []byte("body"), body)
+	require.Equal(t, 2, len(handler.getRequests()))
+}
+
+func TestWaitResult_SinglePollWithoutWait(t *testing.T) {
+	handler := &asyncWithResultHandler{resultError: ErrOperationStillRunning}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{SinglePoll: true})
 	require.ErrorIs(t, err, ErrOperationStillRunning)
-	require.WithinDuration(t, deadline, handler.requests[0].deadline, 1*time.Millisecond)
+	require.NotErrorIs(t, err, ErrOperationWaitTimeout)
+	require.Equal(t, 1, len(handler.getRequests()))
 }
 
 func TestPeekResult_StillRunning(t *testing.T) {
@@ -160,8 +211,8 @@ func TestPeekResult_StillRunning(t *testing.T) {
 	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
 	require.ErrorIs(t, err, ErrOperationStillRunning)
 	require.Nil(t, response)
-	require.Equal(t, 1, len(handler.requests))
-	require.Equal(t, time.Duration(0), handler.requests[0].options.Wait)
+	require.Equal(t, 1, len(handler.getRequests()))
+	require.Equal(t, time.Duration(0), handler.getRequests()[0].options.Wait)
 }
 
 func TestPeekResult_Success(t *testing.T) {
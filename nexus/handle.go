@@ -1,28 +1,45 @@
 package nexus
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
-	"net/url"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const getResultContextPadding = time.Second * 5
 
 // An OperationHandle is used to cancel operations and get their result and status.
+//
+// An OperationHandle is safe for concurrent use as long as its fields are not modified. To issue requests for the same
+// operation with different client settings, get a handle from a client derived via [Client.Clone] using
+// [Client.NewHandle].
 type OperationHandle[T any] struct {
 	// Name of the Operation this handle represents.
 	Operation string
 	// Handler generated ID for this handle's operation.
-	ID     string
+	ID string
+	// Links to resources related to the operation reported by the handler when starting it. Empty for handles
+	// obtained via [Client.NewHandle], use [OperationHandle.GetInfo] to get the links of an existing operation.
+	Links  []Link
 	client *Client
+	// Serialized input the operation was started with, if retained, see [ClientOptions.RetainInputs].
+	input *Content
+	// In-flight long polls for the operation's result, aborted by canceling the operation, see
+	// [CancelOperationOptions.AbortLongPolls]. Nil disables tracking.
+	polls *longPolls
 }
 
 // GetInfo gets operation information, issuing a network request to the service handler.
-func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationInfoOptions) (*OperationInfo, error) {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID))
+func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationInfoOptions, opts ...CallOption) (*OperationInfo, error) {
+	ctx, h = h.withCallOptions(ctx, opts)
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID)
 	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
 	if err != nil {
 		return nil, err
@@ -31,7 +48,7 @@ func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationIn
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
 
 	request.Header.Set(headerUserAgent, userAgent)
-	response, err := h.client.options.HTTPCaller(request)
+	response, err := h.client.callHedged(request)
 	if err != nil {
 		return nil, err
 	}
@@ -43,10 +60,49 @@ func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationIn
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
 	}
 
-	return operationInfoFromResponse(response, body)
+	return operationInfoFromResponse(response, body, h.client.options.JSON)
+}
+
+// Watch subscribes to state changes of an operation, issuing a network request to the service handler that is kept
+// open for the lifetime of the returned stream.
+//
+// The handler delivers the operation's current info followed by an info for every state transition, and ends the
+// stream once the operation reaches a terminal state. Use this instead of polling GetInfo for long running operations.
+//
+// ⚠️ If this method completes successfully, the returned stream must be closed to free up the underlying connection.
+func (h *OperationHandle[T]) Watch(ctx context.Context, options WatchOperationOptions, opts ...CallOption) (*OperationEventStream, error) {
+	ctx, h = h.withCallOptions(ctx, opts)
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "events")
+	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	request.Header.Set("Accept", contentTypeEventStream)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+
+	response, err := h.client.options.LongPollHTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusOK && isMediaTypeEventStream(response.Header.Get("Content-Type")) {
+		return newOperationEventStream(response, h.client.options.JSON, h.client.options.MaxEventSize), nil
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
+	}
+	return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, h.client.options.JSON)
 }
 
 // GetResult gets the result of an operation, issuing a network request to the service handler.
@@ -56,72 +112,216 @@ func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationIn
 //
 // Callers may set GetOperationResultOptions.Wait to a value greater than 0 to alter this behavior, causing the client
 // to long poll for the result issuing one or more requests until the provided wait period exceeds, in which case (nil,
-// [ErrOperationStillRunning]) is returned.
+// [ErrOperationStillRunning]) is returned. The wait duration of each request is determined by the client's
+// [WaitStrategy], which by default starts with short waits growing toward a minute, see [ClientOptions.WaitStrategy].
 //
 // The wait time is capped to the deadline of the provided context. Make sure to handle both context deadline errors and
 // [ErrOperationStillRunning].
 //
+// [ErrOperationResultGone] is returned if the handler no longer retains the result of the completed operation.
+//
+// While long polling, requests rejected with a 429 or 503 status and a Retry-After header are retried after the
+// requested delay if it ends within the wait period. Otherwise the error is returned, see [RetryAfter].
+//
+// Set GetOperationResultOptions.SinglePoll to issue exactly one request, in which case (nil, [ErrOperationWaitTimeout])
+// is returned if the handler's long poll times out before the operation completes.
+//
 // Note that the wait period is enforced by the server and may not be respected if the server is misbehaving. Set the
 // context deadline to the max allowed wait period to ensure this call returns in a timely fashion.
 //
 // ⚠️ If a [LazyValue] is returned (as indicated by T), it must be consumed to free up the underlying connection.
-func (h *OperationHandle[T]) GetResult(ctx context.Context, options GetOperationResultOptions) (T, error) {
+func (h *OperationHandle[T]) GetResult(ctx context.Context, options GetOperationResultOptions, opts ...CallOption) (T, error) {
+	ctx, h = h.withCallOptions(ctx, opts)
 	var result T
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID), "result")
-	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	value, err := h.getResultValue(ctx, options)
 	if err != nil {
 		return result, err
 	}
+	if _, ok := any(result).(*LazyValue); ok {
+		return any(value).(T), nil
+	}
+	return result, value.Consume(&result)
+}
+
+// getResultValue gets the result of the operation as a [LazyValue], regardless of the handle's result type.
+func (h *OperationHandle[T]) getResultValue(ctx context.Context, options GetOperationResultOptions) (*LazyValue, error) {
+	value, _, err := h.getResultPage(ctx, options)
+	return value, err
+}
+
+// getResultPage gets the result of the operation, or the page of it given by options.PageToken when
+// options.AcceptPages is set. Returns the token of the next page, empty if there are no more pages.
+//
+// Long polls are tracked until a response is received, so they can be aborted by [OperationHandle.Cancel].
+func (h *OperationHandle[T]) getResultPage(ctx context.Context, options GetOperationResultOptions) (*LazyValue, string, error) {
+	if options.Wait <= 0 || h.polls == nil {
+		return h.pollResultPage(ctx, options)
+	}
+	pollCtx, untrack, release := h.polls.track(ctx)
+	value, nextPageToken, err := h.pollResultPage(pollCtx, options)
+	untrack()
+	if err != nil {
+		release()
+		if errors.Is(context.Cause(pollCtx), ErrLongPollAborted) {
+			return nil, "", ErrLongPollAborted
+		}
+		return nil, "", err
+	}
+	value.Reader.ReadCloser = &cancelingReadCloser{value.Reader.ReadCloser, release}
+	return value, nextPageToken, nil
+}
+
+// pollResultPage implements getResultPage, polling until the result is available or the wait period elapses.
+func (h *OperationHandle[T]) pollResultPage(ctx context.Context, options GetOperationResultOptions) (*LazyValue, string, error) {
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "result")
+	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
 	addContextTimeoutToHTTPHeader(ctx, request.Header)
 	request.Header.Set(headerUserAgent, userAgent)
+	if h.client.options.FollowResultRedirects {
+		request.Header.Set(HeaderAcceptResultRedirect, "true")
+	}
+	if options.AcceptPages {
+		request.Header.Set(HeaderAcceptResultPages, "true")
+	}
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	setAcceptHTTPHeader(h.client.options.Serializer, request.Header)
+
+	baseQuery := url.Query()
+	if options.PageToken != "" {
+		baseQuery.Set(QueryPageToken, options.PageToken)
+	}
 
-	startTime := time.Now()
-	wait := options.Wait
-	for {
-		if wait > 0 {
+	cache := h.client.options.ResultCache
+	if options.AcceptPages {
+		// Pages are not cached, the cache key does not identify them.
+		cache = nil
+	}
+	cacheKey := url.String()
+	if cache != nil {
+		if content, ok := cache.Get(cacheKey); ok {
+			return h.valueFromContent(content), "", nil
+		}
+	}
+
+	clock := h.client.options.Clock
+	startTime := clock.Now()
+	remaining := options.Wait
+	for poll := 1; ; poll++ {
+		// We may reuse the request object multiple times and will need to reset the query when wait becomes 0 or
+		// negative.
+		q := maps.Clone(baseQuery)
+		wait := remaining
+		if remaining > 0 {
 			if deadline, set := ctx.Deadline(); set {
 				// Ensure we don't wait longer than the deadline but give some buffer prevent racing between wait and
 				// context deadline.
-				wait = min(wait, time.Until(deadline)+getResultContextPadding)
+				remaining = min(remaining, time.Until(deadline)+getResultContextPadding)
 			}
-
-			q := request.URL.Query()
-			q.Set(queryWait, fmt.Sprintf("%dms", wait.Milliseconds()))
-			request.URL.RawQuery = q.Encode()
-		} else {
-			// We may reuse the request object multiple times and will need to reset the query when wait becomes 0 or
-			// negative.
-			request.URL.RawQuery = ""
+			wait = remaining
+			if !options.SinglePoll {
+				wait = min(h.client.options.WaitStrategy.Wait(poll, remaining), remaining)
+			}
+			q.Set(QueryWait, FormatDurationParam(wait))
 		}
+		request.URL.RawQuery = q.Encode()
 
 		response, err := h.sendGetOperationRequest(ctx, request)
 		if err != nil {
-			if wait > 0 && errors.Is(err, errOperationWaitTimeout) {
+			// Handlers respond to expired long polls with either a timeout or a still running status. Poll again if the
+			// wait strategy shortened the wait, or if the handler timed out, in case it capped the wait.
+			expired := errors.Is(err, ErrOperationWaitTimeout) || wait < remaining && errors.Is(err, ErrOperationStillRunning)
+			if wait > 0 && !options.SinglePoll && expired {
 				// TODO: Backoff a bit in case the server is continually returning timeouts due to some LB configuration
 				// issue to avoid blowing it up with repeated calls.
-				wait = options.Wait - time.Since(startTime)
+				remaining = options.Wait - clock.Now().Sub(startTime)
 				continue
 			}
-			return result, err
-		}
-		s := &LazyValue{
-			serializer: h.client.options.Serializer,
-			Reader: &Reader{
-				response.Body,
-				prefixStrippedHTTPHeaderToNexusHeader(response.Header, "content-"),
-			},
+			if retryAfter, ok := RetryAfter(err); ok && wait > 0 && !options.SinglePoll && retryAfter < options.Wait-clock.Now().Sub(startTime) {
+				// The handler is overloaded, poll again once it asks to be retried if still within the wait period.
+				timer := clock.NewTimer(retryAfter)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, "", ctx.Err()
+				case <-timer.C():
+				}
+				remaining = options.Wait - clock.Now().Sub(startTime)
+				continue
+			}
+			return nil, "", err
 		}
-		if _, ok := any(result).(*LazyValue); ok {
-			return any(s).(T), nil
-		} else {
-			return result, s.Consume(&result)
+		nextPageToken := response.Header.Get(HeaderResultNextPageToken)
+		reader := readerFromHTTPResponse(response)
+		if cache != nil {
+			body, err := readAll(reader)
+			reader.Close()
+			if err != nil {
+				return nil, "", err
+			}
+			content := &Content{Header: reader.Header, Data: body}
+			cache.Add(cacheKey, content)
+			return h.valueFromContent(content), "", nil
 		}
+		return &LazyValue{
+			serializer:   h.client.options.Serializer,
+			transformers: h.client.options.ResultTransformers,
+			validate:     h.client.resultValidator(h.Operation),
+			Reader:       reader,
+		}, nextPageToken, nil
+	}
+}
+
+// valueFromContent wraps cached content in a [LazyValue].
+func (h *OperationHandle[T]) valueFromContent(content *Content) *LazyValue {
+	return &LazyValue{
+		serializer:   h.client.options.Serializer,
+		transformers: h.client.options.ResultTransformers,
+		validate:     h.client.resultValidator(h.Operation),
+		Reader: &Reader{
+			io.NopCloser(bytes.NewReader(content.Data)),
+			maps.Clone(content.Header),
+		},
+	}
+}
+
+// WriteResultTo gets the result of the operation as [OperationHandle.GetResult] does and streams its serialized
+// content into w without buffering it, e.g. into a file. Returns the number of bytes written.
+func (h *OperationHandle[T]) WriteResultTo(ctx context.Context, w io.Writer, options GetOperationResultOptions, opts ...CallOption) (int64, error) {
+	ctx, h = h.withCallOptions(ctx, opts)
+	value, err := h.getResultValue(ctx, options)
+	if err != nil {
+		return 0, err
 	}
+	return value.WriteTo(w)
+}
+
+// ServeResult gets the result of the operation as [OperationHandle.GetResult] does and streams it into an HTTP
+// response, setting the response's Content-Type and other content headers from the result, e.g. to proxy results to
+// end users.
+//
+// Errors getting the result are returned before anything is written to the response, allowing the caller to respond
+// with an error of its own.
+func (h *OperationHandle[T]) ServeResult(ctx context.Context, writer http.ResponseWriter, options GetOperationResultOptions, opts ...CallOption) error {
+	ctx, h = h.withCallOptions(ctx, opts)
+	value, err := h.getResultValue(ctx, options)
+	if err != nil {
+		return err
+	}
+	addContentHeaderToHTTPHeader(value.Reader.Header, writer.Header())
+	writer.WriteHeader(http.StatusOK)
+	_, err = value.WriteTo(writer)
+	return err
 }
 
 func (h *OperationHandle[T]) sendGetOperationRequest(ctx context.Context, request *http.Request) (*http.Response, error) {
-	response, err := h.client.options.HTTPCaller(request)
+	caller := h.client.callHedged
+	if request.URL.Query().Has(QueryWait) {
+		caller = h.client.options.LongPollHTTPCaller
+	}
+	response, err := caller(request)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +329,9 @@ func (h *OperationHandle[T]) sendGetOperationRequest(ctx context.Context, reques
 	if response.StatusCode == http.StatusOK {
 		return response, nil
 	}
+	if response.StatusCode == http.StatusTemporaryRedirect && h.client.options.FollowResultRedirects {
+		return h.client.followResultRedirect(response)
+	}
 
 	// Do this once here and make sure it doesn't leak.
 	body, err := readAndReplaceBody(response)
@@ -138,15 +341,17 @@ func (h *OperationHandle[T]) sendGetOperationRequest(ctx context.Context, reques
 
 	switch response.StatusCode {
 	case http.StatusRequestTimeout:
-		return nil, errOperationWaitTimeout
-	case statusOperationRunning:
+		return nil, ErrOperationWaitTimeout
+	case StatusOperationRunning:
 		return nil, ErrOperationStillRunning
-	case statusOperationFailed:
-		state, err := getUnsuccessfulStateFromHeader(response, body)
+	case http.StatusGone:
+		return nil, ErrOperationResultGone
+	case StatusOperationFailed:
+		state, err := getUnsuccessfulStateFromHeader(response, body, h.client.options.JSON)
 		if err != nil {
 			return nil, err
 		}
-		failure, err := failureFromResponse(response, body)
+		failure, err := failureFromResponse(response, body, h.client.options.JSON)
 		if err != nil {
 			return nil, err
 		}
@@ -155,19 +360,38 @@ func (h *OperationHandle[T]) sendGetOperationRequest(ctx context.Context, reques
 			Failure: failure,
 		}
 	default:
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
 	}
 }
 
 // Cancel requests to cancel an asynchronous operation.
 //
 // Cancelation is asynchronous and may be not be respected by the operation's implementation.
-func (h *OperationHandle[T]) Cancel(ctx context.Context, options CancelOperationOptions) error {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID), "cancel")
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), nil)
+func (h *OperationHandle[T]) Cancel(ctx context.Context, options CancelOperationOptions, opts ...CallOption) error {
+	ctx, h = h.withCallOptions(ctx, opts)
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "cancel")
+	var reasonBody io.Reader
+	if options.Reason != nil {
+		reason, err := h.client.options.JSON.marshal(options.Reason)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cancel reason: %w", err)
+		}
+		reasonBody = bytes.NewReader(reason)
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), reasonBody)
 	if err != nil {
 		return err
 	}
+	if reasonBody != nil {
+		request.Header.Set("Content-Type", contentTypeJSON)
+	}
+	if options.RequestID == "" {
+		options.RequestID = uuid.NewString()
+	}
+	request.Header.Set(HeaderRequestID, options.RequestID)
+	if options.Mode != "" {
+		request.Header.Set(HeaderCancelMode, string(options.Mode))
+	}
 	addContextTimeoutToHTTPHeader(ctx, request.Header)
 	request.Header.Set(headerUserAgent, userAgent)
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
@@ -183,7 +407,44 @@ func (h *OperationHandle[T]) Cancel(ctx context.Context, options CancelOperation
 	}
 
 	if response.StatusCode != http.StatusAccepted {
-		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
+	}
+	if options.AbortLongPolls && h.polls != nil {
+		h.polls.abort()
 	}
 	return nil
 }
+
+// Heartbeat proves that an asynchronous operation is still being executed, optionally reporting progress details, and
+// returns the operation's current info. Intended for executors running outside of the handler process; executors of
+// an [AsyncHandler] may call [Heartbeat] instead.
+func (h *OperationHandle[T]) Heartbeat(ctx context.Context, options HeartbeatOperationOptions, opts ...CallOption) (*OperationInfo, error) {
+	ctx, h = h.withCallOptions(ctx, opts)
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "heartbeat")
+	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), bytes.NewReader(options.Details))
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	if len(options.Details) > 0 {
+		request.Header.Set("Content-Type", contentTypeJSON)
+	}
+	response, err := h.client.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
+	}
+
+	return operationInfoFromResponse(response, body, h.client.options.JSON)
+}
@@ -1,6 +1,9 @@
 package nexus
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,4 +19,46 @@ func TestNewHandleFailureConditions(t *testing.T) {
 	_, err = client.NewHandle("", "")
 	require.ErrorIs(t, err, errEmptyOperationName)
 	require.ErrorIs(t, err, errEmptyOperationID)
+	_, err = client.NewHandle("_admin", "id")
+	require.ErrorIs(t, err, errReservedOperationName)
+}
+
+func TestOperationHandle_WriteResultTo(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	handle, err := NewHandle(client, NewOperationReference[NoValue, string]("foo"), "a/sync")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	n, err := handle.WriteResultTo(ctx, &buf, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+	require.Equal(t, "body", buf.String())
+
+	_, err = handle.WriteResultTo(ctx, &buf, GetOperationResultOptions{Header: Header{"User-Agent": "invalid"}})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+}
+
+func TestOperationHandle_ServeResult(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	require.NoError(t, handle.ServeResult(ctx, recorder, GetOperationResultOptions{}))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/octet-stream", recorder.Header().Get("Content-Type"))
+	require.Equal(t, "4", recorder.Header().Get("Content-Length"))
+	require.Equal(t, "body", recorder.Body.String())
+
+	_, client, teardown = setup(t, &asyncWithResultHandler{resultError: ErrOperationStillRunning})
+	defer teardown()
+	handle, err = client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	require.ErrorIs(t, handle.ServeResult(ctx, recorder, GetOperationResultOptions{}), ErrOperationStillRunning)
+	require.Empty(t, recorder.Header())
+	require.Zero(t, recorder.Body.Len())
 }
@@ -0,0 +1,61 @@
+package nexus
+
+import "context"
+
+// HandlerInfo describes the request a [Handler] method is invoked for. Obtain it from the method's context via
+// [HandlerInfoFromContext], e.g. in business logic called from the method, instead of threading the request options
+// through every call.
+type HandlerInfo struct {
+	// The endpoint the request is addressed to.
+	Method HandlerMethod
+	// Name of the service, see [HandlerOptions.Service].
+	Service string
+	// Name of the operation. Empty for requests that apply to multiple operations.
+	Operation string
+	// ID of the operation. Empty for start requests and requests that apply to multiple operations.
+	OperationID string
+	// Request ID, set for start and cancel requests.
+	RequestID string
+	// Identity of the caller, asserted by the client via [HeaderCallerIdentity] unless set by the [Authorizer] via
+	// [AuthorizationRequest.Caller].
+	Caller string
+	// User-Agent of the client, including the application identifier set in [ClientOptions.UserAgent].
+	UserAgent string
+	// Name of the client library, sent in the [HeaderClientName] header.
+	ClientName string
+	// Version of the client library, sent in the [HeaderClientVersion] header.
+	ClientVersion string
+	// Request header fields, excluding "content-" prefixed headers.
+	Header Header
+}
+
+type handlerInfoContextKey struct{}
+
+// HandlerInfoFromContext returns the [HandlerInfo] of the request a context was created for by the handler returned
+// from [NewHTTPHandler]. The info is available once the request is authorized, i.e. in [Handler] methods and any code
+// they call with their context.
+func HandlerInfoFromContext(ctx context.Context) (HandlerInfo, bool) {
+	info, ok := ctx.Value(handlerInfoContextKey{}).(*HandlerInfo)
+	if !ok {
+		return HandlerInfo{}, false
+	}
+	return *info, true
+}
+
+// ContextWithHandlerInfo returns a copy of ctx carrying the given info, e.g. for testing code that relies on
+// [HandlerInfoFromContext] or for dispatching to a [Handler] without going through HTTP.
+func ContextWithHandlerInfo(ctx context.Context, info HandlerInfo) context.Context {
+	return context.WithValue(ctx, handlerInfoContextKey{}, &info)
+}
+
+// setAuthorizedHandlerInfo completes the info of an authorized request attached to ctx in [httpHandler.route].
+func setAuthorizedHandlerInfo(ctx context.Context, request *AuthorizationRequest) {
+	info, ok := ctx.Value(handlerInfoContextKey{}).(*HandlerInfo)
+	if !ok {
+		return
+	}
+	info.Operation = request.Operation
+	info.OperationID = request.OperationID
+	info.Caller = request.Caller
+	info.Header = request.Header
+}
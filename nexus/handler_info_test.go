@@ -0,0 +1,76 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type handlerInfoRecordingHandler struct {
+	UnimplementedHandler
+	infos chan HandlerInfo
+}
+
+func (h *handlerInfoRecordingHandler) record(ctx context.Context) {
+	info, ok := HandlerInfoFromContext(ctx)
+	if ok {
+		h.infos <- info
+	}
+}
+
+func (h *handlerInfoRecordingHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.record(ctx)
+	return &HandlerStartOperationResultAsync{OperationID: "a/b"}, nil
+}
+
+func (h *handlerInfoRecordingHandler) CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error {
+	h.record(ctx)
+	return nil
+}
+
+func TestHandlerInfoFromContext(t *testing.T) {
+	handler := &handlerInfoRecordingHandler{infos: make(chan HandlerInfo, 1)}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: handler,
+		Service: "my-service",
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			request.Caller = request.Header.Get("caller")
+			return nil
+		}),
+	}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		RequestID: "req-1",
+		Header:    Header{"caller": "alice"},
+	})
+	require.NoError(t, err)
+	info := <-handler.infos
+	require.Equal(t, HandlerMethodStartOperation, info.Method)
+	require.Equal(t, "my-service", info.Service)
+	require.Equal(t, "foo", info.Operation)
+	require.Empty(t, info.OperationID)
+	require.Equal(t, "req-1", info.RequestID)
+	require.Equal(t, "alice", info.Caller)
+	require.Equal(t, "alice", info.Header.Get("caller"))
+
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{Header: Header{"caller": "bob"}, RequestID: "req-2"}))
+	info = <-handler.infos
+	require.Equal(t, HandlerMethodCancelOperation, info.Method)
+	require.Equal(t, "foo", info.Operation)
+	require.Equal(t, "a/b", info.OperationID)
+	require.Equal(t, "req-2", info.RequestID)
+	require.Equal(t, "bob", info.Caller)
+}
+
+func TestContextWithHandlerInfo(t *testing.T) {
+	_, ok := HandlerInfoFromContext(context.Background())
+	require.False(t, ok)
+
+	ctx := ContextWithHandlerInfo(context.Background(), HandlerInfo{Operation: "foo", Caller: "alice"})
+	info, ok := HandlerInfoFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "foo", info.Operation)
+	require.Equal(t, "alice", info.Caller)
+}
@@ -0,0 +1,62 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Default timeout of readiness checks.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// A HealthChecker reports whether a handler is ready to serve requests, see [HandlerOptions.HealthChecker].
+type HealthChecker interface {
+	// CheckHealth returns nil if the handler is ready to serve requests, e.g. after probing its dependencies.
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckerFunc is a [HealthChecker] backed by a function.
+type HealthCheckerFunc func(ctx context.Context) error
+
+// CheckHealth implements HealthChecker.
+func (f HealthCheckerFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// healthChecker returns the configured HealthChecker, falling back to the Handler if it implements HealthChecker.
+func (h *httpHandler) healthChecker() HealthChecker {
+	if h.options.HealthChecker != nil {
+		return h.options.HealthChecker
+	}
+	if checker, ok := h.options.Handler.(HealthChecker); ok {
+		return checker
+	}
+	return nil
+}
+
+// healthz handles liveness probes, which succeed as long as the handler is serving requests.
+func (h *httpHandler) healthz(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain")
+	writer.WriteHeader(http.StatusOK)
+	if _, err := writer.Write([]byte("ok\n")); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// readyz handles readiness probes, which fail while the handler is shutting down or its HealthChecker fails.
+func (h *httpHandler) readyz(writer http.ResponseWriter, request *http.Request) {
+	if h.drainer.draining() {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeUnavailable, "server is shutting down"))
+		return
+	}
+	if checker := h.healthChecker(); checker != nil {
+		ctx, cancel := context.WithTimeout(request.Context(), h.options.HealthCheckTimeout)
+		defer cancel()
+		if err := checker.CheckHealth(ctx); err != nil {
+			h.logger.Warn("health check failed", "error", err)
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeUnavailable, "not ready"))
+			return
+		}
+	}
+	h.healthz(writer, request)
+}
@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type healthCheckingHandler struct {
+	UnimplementedHandler
+	err error
+}
+
+func (h *healthCheckingHandler) CheckHealth(ctx context.Context) error {
+	return h.err
+}
+
+func probe(t *testing.T, handler http.Handler, path string) int {
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", path, nil))
+	return writer.Code
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	handler := &healthCheckingHandler{}
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:         handler,
+		HealthEndpoints: true,
+		Authorizer: AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+			return HandlerErrorf(HandlerErrorTypeUnauthenticated, "unauthenticated")
+		}),
+	})
+
+	require.Equal(t, http.StatusOK, probe(t, httpHandler, "/healthz"))
+	require.Equal(t, http.StatusOK, probe(t, httpHandler, "/readyz"))
+
+	handler.err = errors.New("store unreachable")
+	require.Equal(t, http.StatusOK, probe(t, httpHandler, "/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, probe(t, httpHandler, "/readyz"))
+
+	handler.err = nil
+	require.NoError(t, httpHandler.Shutdown(context.Background()))
+	require.Equal(t, http.StatusOK, probe(t, httpHandler, "/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, probe(t, httpHandler, "/readyz"))
+}
+
+func TestHealthEndpoints_HealthChecker(t *testing.T) {
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:         &healthCheckingHandler{},
+		HealthEndpoints: true,
+		HealthChecker: HealthCheckerFunc(func(ctx context.Context) error {
+			return errors.New("not ready")
+		}),
+	})
+	require.Equal(t, http.StatusServiceUnavailable, probe(t, httpHandler, "/readyz"))
+}
+
+func TestHealthEndpoints_Disabled(t *testing.T) {
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: &healthCheckingHandler{}})
+	require.Equal(t, http.StatusMethodNotAllowed, probe(t, httpHandler, "/healthz"))
+}
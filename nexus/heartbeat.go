@@ -0,0 +1,270 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// IsStale reports whether the record is of a running operation that has recorded heartbeats but has not recorded one
+// within timeout of now. Operations that never recorded a heartbeat and quarantined operations are never considered
+// stale.
+func (r *OperationRecord) IsStale(now time.Time, timeout time.Duration) bool {
+	return r.State == OperationStateRunning && r.Quarantine == nil && !r.LastHeartbeat.IsZero() && now.Sub(r.LastHeartbeat) > timeout
+}
+
+// heartbeat records heartbeats for an operation executing in this process until ctx is done.
+func (h *AsyncHandler) heartbeat(ctx context.Context, operation, operationID string) {
+	ticker := time.NewTicker(h.options.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.RecordHeartbeat(ctx, operation, operationID); err != nil && ctx.Err() == nil {
+				h.options.Logger.Warn("failed to record operation heartbeat", "operation", operation, "operationID", operationID, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// heartbeater records a heartbeat for the operation executing with a context, see [Heartbeat]. It marshals details
+// with the JSON engine of the handler executing the operation.
+type heartbeater func(ctx context.Context, details any) error
+
+type heartbeaterContextKey struct{}
+
+// Heartbeat records a heartbeat for the operation executed with the given context, proving that it is still being
+// executed and optionally reporting progress details, which must be JSON serializable and are reported in
+// [OperationInfo.HeartbeatDetails]. Pass nil details to retain the previously reported details.
+//
+// Must be called with the context passed to an [AsyncExecutor]. Executors running outside of the handler process may
+// use [OperationHandle.Heartbeat] instead.
+func Heartbeat(ctx context.Context, details any) error {
+	h, ok := ctx.Value(heartbeaterContextKey{}).(heartbeater)
+	if !ok {
+		return errors.New("context is not an operation execution context")
+	}
+	return h(ctx, details)
+}
+
+// RecordHeartbeat records a heartbeat for a running operation, proving that it is still being executed.
+//
+// Heartbeats are recorded automatically for operations executing in this process when
+// [AsyncHandlerOptions.HeartbeatInterval] is set. Heartbeats for operations that have completed are ignored.
+func (h *AsyncHandler) RecordHeartbeat(ctx context.Context, operation, operationID string) error {
+	_, err := h.recordHeartbeat(ctx, operation, operationID, nil)
+	return err
+}
+
+// HeartbeatOperation implements Handler.
+func (h *AsyncHandler) HeartbeatOperation(ctx context.Context, operation, operationID string, options HeartbeatOperationOptions) (*OperationInfo, error) {
+	record, err := h.recordHeartbeat(ctx, operation, operationID, options.Details)
+	if err != nil {
+		return nil, err
+	}
+	return record.Info(), nil
+}
+
+// recordHeartbeat records a heartbeat with optional details and returns the updated record.
+func (h *AsyncHandler) recordHeartbeat(ctx context.Context, operation, operationID string, details json.RawMessage) (*OperationRecord, error) {
+	if _, err := h.getRecord(ctx, operation, operationID); err != nil {
+		return nil, err
+	}
+	err := h.transition(ctx, operation, operationID, func(record *OperationRecord) {
+		record.LastHeartbeat = time.Now()
+		if details != nil {
+			record.HeartbeatDetails = details
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.getRecord(ctx, operation, operationID)
+}
+
+// FindStaleOperations returns summaries of running operations that have not recorded a heartbeat within timeout,
+// ordered by creation time, newest first. Requires the Store to implement [OperationLister].
+func (h *AsyncHandler) FindStaleOperations(ctx context.Context, timeout time.Duration) ([]*OperationSummary, error) {
+	var stale []*OperationSummary
+	err := h.forEachStaleRecord(ctx, timeout, func(record *OperationRecord) error {
+		stale = append(stale, record.Summary())
+		return nil
+	})
+	return stale, err
+}
+
+// FailStaleOperations transitions running operations that have not recorded a heartbeat within timeout to failed and
+// returns summaries of the failed operations. Requires the Store to implement [OperationLister].
+//
+// Run this periodically, with a timeout comfortably larger than [AsyncHandlerOptions.HeartbeatInterval], to fail
+// operations whose executing process has exited.
+func (h *AsyncHandler) FailStaleOperations(ctx context.Context, timeout time.Duration) ([]*OperationSummary, error) {
+	var failed []*OperationSummary
+	err := h.forEachStaleRecord(ctx, timeout, func(record *OperationRecord) error {
+		record, err := h.failStale(ctx, record, timeout)
+		if record != nil {
+			failed = append(failed, record.Summary())
+		}
+		return err
+	})
+	return failed, err
+}
+
+// RecoverStaleOperations is like [AsyncHandler.FailStaleOperations] but re-executes stale operations in this process
+// until they have been retried [AsyncHandlerOptions.MaxStaleRetries] times, only failing them afterwards. Returns
+// summaries of the re-executed and failed operations. Requires the Store to implement [OperationLister].
+func (h *AsyncHandler) RecoverStaleOperations(ctx context.Context, timeout time.Duration) ([]*OperationSummary, error) {
+	var recovered []*OperationSummary
+	err := h.forEachStaleRecord(ctx, timeout, func(record *OperationRecord) error {
+		if record.Input == nil || record.Attempt > h.options.MaxStaleRetries {
+			record, err := h.failStale(ctx, record, timeout)
+			if record != nil {
+				recovered = append(recovered, record.Summary())
+			}
+			return err
+		}
+		record, err := h.updateIfStale(ctx, record, timeout, func(record *OperationRecord) {
+			record.Attempt++
+			record.LastHeartbeat = time.Now()
+		})
+		if record == nil {
+			return err
+		}
+		// Ensure an execution stuck in this process does not linger.
+		h.cancelExecution(record.Operation, record.ID)
+		h.execute(record, record.Input, StartOperationOptions{
+			RequestID: record.RequestID,
+			Tags:      record.Tags,
+			Deadline:  record.Deadline,
+			StartTime: record.StartTime,
+		})
+		recovered = append(recovered, record.Summary())
+		return nil
+	})
+	return recovered, err
+}
+
+// failStale fails a stale operation, returning the updated record, or nil if the operation is no longer stale.
+func (h *AsyncHandler) failStale(ctx context.Context, record *OperationRecord, timeout time.Duration) (*OperationRecord, error) {
+	record, err := h.updateIfStale(ctx, record, timeout, func(record *OperationRecord) {
+		record.State = OperationStateFailed
+		record.Failure = &Failure{Message: "operation heartbeat timed out"}
+		h.setExpireTime(record)
+	})
+	if record == nil {
+		return nil, err
+	}
+	h.releaseQuota(ctx, record)
+	h.cancelExecution(record.Operation, record.ID)
+	return record, nil
+}
+
+// updateIfStale applies the given update to a stale operation, retrying on version conflicts. Returns the updated
+// record, or nil if the operation is no longer stale.
+func (h *AsyncHandler) updateIfStale(ctx context.Context, record *OperationRecord, timeout time.Duration, update func(*OperationRecord)) (*OperationRecord, error) {
+	for {
+		update(record)
+		record.UpdatedAt = time.Now()
+		err := h.options.Store.Update(ctx, record)
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, ErrOperationRecordVersionConflict) {
+			return nil, err
+		}
+		// Re-check staleness, a heartbeat may have been recorded concurrently.
+		if record, err = h.options.Store.Get(ctx, record.Operation, record.ID); err != nil {
+			return nil, err
+		}
+		if !record.IsStale(time.Now(), timeout) {
+			return nil, nil
+		}
+	}
+}
+
+func (h *AsyncHandler) forEachStaleRecord(ctx context.Context, timeout time.Duration, fn func(*OperationRecord) error) error {
+	lister, ok := h.options.Store.(OperationLister)
+	if !ok {
+		return errors.New("listing operations is not supported by the store")
+	}
+	filter := OperationFilter{States: []OperationState{OperationStateRunning}}
+	var pageToken string
+	for {
+		records, nextPageToken, err := lister.ListOperations(ctx, filter, DefaultListPageSize, pageToken)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, record := range records {
+			if !record.IsStale(now, timeout) {
+				continue
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+func (h *httpHandler) heartbeatOperation(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	options := HeartbeatOperationOptions{Header: httpHeaderToNexusHeader(request.Header)}
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		h.writeFailure(writer, requestBodyError(err, "failed to read request body"))
+		return
+	}
+	if len(body) > 0 {
+		if !json.Valid(body) {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid heartbeat details"))
+			return
+		}
+		options.Details = body
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodHeartbeatOperation,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	info, err := h.options.Handler.HeartbeatOperation(ctx, operation, operationID, options)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	bytes, err := h.json.marshal(info)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal operation info: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
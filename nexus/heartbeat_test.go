@@ -0,0 +1,187 @@
+package nexus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Heartbeat(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		HeartbeatInterval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, info.LastHeartbeatTime)
+	first := *info.LastHeartbeatTime
+
+	require.Eventually(t, func() bool {
+		info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		return info.LastHeartbeatTime.After(first)
+	}, time.Second, 10*time.Millisecond)
+
+	stale, err := handler.FindStaleOperations(ctx, time.Second)
+	require.NoError(t, err)
+	require.Empty(t, stale)
+}
+
+func TestAsyncHandler_FailStaleOperations(t *testing.T) {
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: store,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	// Simulate operations started by a process that has since exited.
+	now := time.Now()
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "dead", State: OperationStateRunning, CreatedAt: now, LastHeartbeat: now.Add(-time.Minute)}))
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "alive", State: OperationStateRunning, CreatedAt: now, LastHeartbeat: now}))
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "untracked", State: OperationStateRunning, CreatedAt: now}))
+
+	stale, err := handler.FindStaleOperations(ctx, 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	require.Equal(t, "dead", stale[0].ID)
+
+	failed, err := handler.FailStaleOperations(ctx, 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, OperationStateFailed, failed[0].State)
+
+	handle, err := client.NewHandle("foo", "dead")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, "operation heartbeat timed out", unsuccessfulOperationError.Failure.Message)
+
+	handle, err = client.NewHandle("foo", "alive")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+}
+
+func TestHeartbeat_Details(t *testing.T) {
+	release := make(chan struct{})
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		if err := Heartbeat(ctx, map[string]int{"progress": 50}); err != nil {
+			return nil, err
+		}
+		<-release
+		return nil, nil
+	})
+	defer teardown()
+	defer close(release)
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		return string(info.HeartbeatDetails) == `{"progress":50}`
+	}, time.Second, 10*time.Millisecond)
+
+	require.ErrorContains(t, Heartbeat(ctx, nil), "not an operation execution context")
+}
+
+func TestOperationHandle_Heartbeat(t *testing.T) {
+	release := make(chan struct{})
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		<-release
+		return nil, nil
+	})
+	defer teardown()
+	defer close(release)
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	info, err := result.Pending.Heartbeat(ctx, HeartbeatOperationOptions{Details: []byte(`"halfway"`)})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	require.NotNil(t, info.LastHeartbeatTime)
+	require.Equal(t, `"halfway"`, string(info.HeartbeatDetails))
+
+	// Omitting details retains the previously reported details.
+	info, err = result.Pending.Heartbeat(ctx, HeartbeatOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, `"halfway"`, string(info.HeartbeatDetails))
+
+	_, err = result.Pending.Heartbeat(ctx, HeartbeatOperationOptions{Details: []byte("{")})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 400, unexpectedResponseError.Response.StatusCode)
+
+	handle, err := client.NewHandle("foo", "missing")
+	require.NoError(t, err)
+	_, err = handle.Heartbeat(ctx, HeartbeatOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 404, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestAsyncHandler_RecoverStaleOperations(t *testing.T) {
+	attempts := make(chan int32, 3)
+	var attempt atomic.Int32
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			var in string
+			if err := input.Consume(&in); err != nil {
+				return nil, err
+			}
+			if err := Heartbeat(ctx, nil); err != nil {
+				return nil, err
+			}
+			attempts <- attempt.Add(1)
+			// Simulate an execution that gets stuck and stops recording heartbeats.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		MaxStaleRetries: 1,
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", "input", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), <-attempts)
+
+	time.Sleep(20 * time.Millisecond)
+	recovered, err := handler.RecoverStaleOperations(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, recovered, 1)
+	require.Equal(t, OperationStateRunning, recovered[0].State)
+	require.Equal(t, int32(2), <-attempts)
+
+	time.Sleep(20 * time.Millisecond)
+	recovered, err = handler.RecoverStaleOperations(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, recovered, 1)
+	require.Equal(t, OperationStateFailed, recovered[0].State)
+
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateFailed, info.State)
+	require.Empty(t, attempts)
+}
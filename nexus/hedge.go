@@ -0,0 +1,108 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// hedgeResult is the outcome of a single request issued by [Client.callHedged].
+type hedgeResult struct {
+	index    int
+	response *http.Response
+	err      error
+	cancel   context.CancelFunc
+}
+
+// succeeded reports whether the result is final, i.e. a response that hedging cannot improve on.
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.response.StatusCode < http.StatusInternalServerError
+}
+
+// release cancels the result's request and frees up its connection.
+func (r hedgeResult) release() {
+	if r.response != nil {
+		r.response.Body.Close()
+	}
+	r.cancel()
+}
+
+// returned returns the result's response, canceling its request once the response body is closed.
+func (r hedgeResult) returned() (*http.Response, error) {
+	if r.err != nil {
+		r.cancel()
+		return nil, r.err
+	}
+	r.response.Body = &cancelingReadCloser{r.response.Body, r.cancel}
+	return r.response, nil
+}
+
+// cancelingReadCloser cancels the context of the request a response body belongs to when the body is closed.
+type cancelingReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// callHedged issues an idempotent request via the HTTP caller, hedging it as configured by [ClientOptions.HedgeDelay].
+// Returns the first successful response, or the last failure if all requests failed. The request must not have a body.
+func (c *Client) callHedged(request *http.Request) (*http.Response, error) {
+	if c.options.HedgeDelay <= 0 {
+		return c.options.HTTPCaller(request)
+	}
+	maxRequests := max(c.options.MaxHedgedRequests, 0) + 1
+	results := make(chan hedgeResult, maxRequests)
+	var cancels []context.CancelFunc
+	issue := func() {
+		ctx, cancel := context.WithCancel(request.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			response, err := c.options.HTTPCaller(request.Clone(ctx))
+			results <- hedgeResult{index, response, err, cancel}
+		}()
+	}
+
+	issue()
+	timer := c.options.Clock.NewTimer(c.options.HedgeDelay)
+	defer timer.Stop()
+	var last *hedgeResult
+	for received := 0; received < len(cancels); {
+		select {
+		case result := <-results:
+			received++
+			if result.succeeded() {
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				// Free up the connections of the remaining requests once they return.
+				go func(remaining int) {
+					for ; remaining > 0; remaining-- {
+						(<-results).release()
+					}
+				}(len(cancels) - received)
+				if last != nil {
+					last.release()
+				}
+				return result.returned()
+			}
+			if last != nil {
+				last.release()
+			}
+			last = &result
+		case <-timer.C():
+			if len(cancels) < maxRequests {
+				issue()
+				timer.Reset(c.options.HedgeDelay)
+			}
+		}
+	}
+	return last.returned()
+}
@@ -0,0 +1,93 @@
+package nexus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowFirstHandler blocks the first call of each method until its context is canceled and responds to subsequent calls
+// immediately.
+type slowFirstHandler struct {
+	UnimplementedHandler
+	calls    atomic.Int32
+	canceled chan struct{}
+}
+
+func (h *slowFirstHandler) block(ctx context.Context) {
+	if h.calls.Add(1) == 1 {
+		<-ctx.Done()
+		close(h.canceled)
+	}
+}
+
+func (h *slowFirstHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	h.block(ctx)
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func (h *slowFirstHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	h.block(ctx)
+	return "result", nil
+}
+
+func TestHedging_GetInfo(t *testing.T) {
+	handler := &slowFirstHandler{canceled: make(chan struct{})}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: 10 * time.Millisecond})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "id", info.ID)
+	// The slow request is canceled.
+	<-handler.canceled
+	require.Equal(t, int32(2), handler.calls.Load())
+}
+
+func TestHedging_GetResult(t *testing.T) {
+	handler := &slowFirstHandler{canceled: make(chan struct{})}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: 10 * time.Millisecond})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	result, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, result.Consume(&s))
+	require.Equal(t, "result", s)
+	<-handler.canceled
+}
+
+func TestHedging_NotForLongPolls(t *testing.T) {
+	handler := &slowFirstHandler{canceled: make(chan struct{})}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: 10 * time.Millisecond})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = handle.GetResult(waitCtx, GetOperationResultOptions{Wait: time.Second})
+	require.Error(t, err)
+	require.Equal(t, int32(1), handler.calls.Load())
+}
+
+func TestHedging_Disabled(t *testing.T) {
+	handler := &slowFirstHandler{canceled: make(chan struct{})}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	infoCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = handle.GetInfo(infoCtx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int32(1), handler.calls.Load())
+}
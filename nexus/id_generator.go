@@ -0,0 +1,107 @@
+package nexus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/google/uuid"
+)
+
+// An OperationIDGenerator generates the IDs of operations started via an [AsyncHandler], see
+// [AsyncHandlerOptions.IDGenerator]. IDs must be unique per operation name.
+//
+// The context carries the start request's [HandlerInfo] and the handler's [Clock], see [ClockFromContext].
+type OperationIDGenerator interface {
+	// NewOperationID returns the ID for a new operation. Errors fail the start request.
+	NewOperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error)
+}
+
+// OperationIDGeneratorFunc is an [OperationIDGenerator] backed by a function, e.g. for IDs derived deterministically
+// from the start request.
+type OperationIDGeneratorFunc func(ctx context.Context, operation string, options StartOperationOptions) (string, error)
+
+// NewOperationID implements [OperationIDGenerator].
+func (f OperationIDGeneratorFunc) NewOperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+	return f(ctx, operation, options)
+}
+
+var (
+	// UUIDv4OperationIDs generates random version 4 UUIDs. This is the default generator.
+	UUIDv4OperationIDs OperationIDGenerator = OperationIDGeneratorFunc(newUUIDv4OperationID)
+	// UUIDv7OperationIDs generates version 7 UUIDs, which sort by creation time in milliseconds, improving locality
+	// of operation records in ordered stores.
+	UUIDv7OperationIDs OperationIDGenerator = OperationIDGeneratorFunc(newUUIDv7OperationID)
+	// KSUIDOperationIDs generates 27 character K-Sortable Unique IDs, which sort by creation time in seconds.
+	KSUIDOperationIDs OperationIDGenerator = OperationIDGeneratorFunc(newKSUIDOperationID)
+	// RequestIDOperationIDs derives version 5 UUIDs from an unambiguous encoding of the operation name and
+	// [StartOperationOptions.RequestID], so retried start requests map to the same operation ID. Requests without a
+	// request ID get a version 7 UUID.
+	RequestIDOperationIDs OperationIDGenerator = OperationIDGeneratorFunc(newRequestIDOperationID)
+)
+
+func newUUIDv4OperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func newUUIDv7OperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+	var id uuid.UUID
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+	ms := uint64(ClockFromContext(ctx).Now().UnixMilli())
+	id[0], id[1], id[2], id[3], id[4], id[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	id[6] = 0x70 | id[6]&0x0f // Version 7.
+	id[8] = 0x80 | id[8]&0x3f // RFC 4122 variant.
+	return id.String(), nil
+}
+
+// ksuidEpoch is the KSUID epoch in Unix seconds, 2014-05-13T16:53:20Z.
+const ksuidEpoch = 1400000000
+
+// ksuidAlphabet is ordered by ASCII value so that encoded KSUIDs sort like their binary form.
+const ksuidAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+func newKSUIDOperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+	var raw [20]byte
+	if _, err := rand.Read(raw[4:]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint32(raw[:4], uint32(ClockFromContext(ctx).Now().Unix()-ksuidEpoch))
+	return encodeBase62(raw[:], 27), nil
+}
+
+// encodeBase62 encodes a big-endian number in base 62, left-padded with zeros to the given width.
+func encodeBase62(number []byte, width int) string {
+	number = append([]byte(nil), number...)
+	out := make([]byte, width)
+	for i := range out {
+		out[i] = ksuidAlphabet[0]
+	}
+	for i := width - 1; i >= 0; i-- {
+		var remainder int
+		nonZero := false
+		for j, b := range number {
+			value := remainder<<8 | int(b)
+			number[j] = byte(value / 62)
+			remainder = value % 62
+			nonZero = nonZero || number[j] != 0
+		}
+		out[i] = ksuidAlphabet[remainder]
+		if !nonZero {
+			break
+		}
+	}
+	return string(out)
+}
+
+func newRequestIDOperationID(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+	if options.RequestID == "" {
+		return newUUIDv7OperationID(ctx, operation, options)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(joinKey(operation, options.RequestID))).String(), nil
+}
@@ -0,0 +1,108 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUIDv7OperationIDs(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ctx := ContextWithClock(context.Background(), clock)
+
+	first, err := UUIDv7OperationIDs.NewOperationID(ctx, "op", StartOperationOptions{})
+	require.NoError(t, err)
+	clock.advance(time.Millisecond)
+	second, err := UUIDv7OperationIDs.NewOperationID(ctx, "op", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Less(t, first, second)
+
+	id, err := uuid.Parse(first)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), id.Version())
+	require.Equal(t, uuid.RFC4122, id.Variant())
+	require.True(t, strings.HasPrefix(first, "018cc251-f400-7"), first)
+}
+
+func TestKSUIDOperationIDs(t *testing.T) {
+	clock := &testClock{now: time.Unix(ksuidEpoch, 0)}
+	ctx := ContextWithClock(context.Background(), clock)
+
+	first, err := KSUIDOperationIDs.NewOperationID(ctx, "op", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Len(t, first, 27)
+	clock.advance(time.Second)
+	second, err := KSUIDOperationIDs.NewOperationID(ctx, "op", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Len(t, second, 27)
+	require.Less(t, first, second)
+
+	require.Equal(t, strings.Repeat("0", 27), encodeBase62(make([]byte, 20), 27))
+	max := make([]byte, 20)
+	for i := range max {
+		max[i] = 0xff
+	}
+	require.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", encodeBase62(max, 27))
+}
+
+func TestRequestIDOperationIDs(t *testing.T) {
+	ctx := context.Background()
+	first, err := RequestIDOperationIDs.NewOperationID(ctx, "op", StartOperationOptions{RequestID: "req"})
+	require.NoError(t, err)
+	second, err := RequestIDOperationIDs.NewOperationID(ctx, "op", StartOperationOptions{RequestID: "req"})
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	other, err := RequestIDOperationIDs.NewOperationID(ctx, "other", StartOperationOptions{RequestID: "req"})
+	require.NoError(t, err)
+	require.NotEqual(t, first, other)
+
+	// Names and request IDs containing the separator don't collide.
+	first, err = RequestIDOperationIDs.NewOperationID(ctx, "a/b", StartOperationOptions{RequestID: "c"})
+	require.NoError(t, err)
+	second, err = RequestIDOperationIDs.NewOperationID(ctx, "a", StartOperationOptions{RequestID: "b/c"})
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	random, err := RequestIDOperationIDs.NewOperationID(ctx, "op", StartOperationOptions{})
+	require.NoError(t, err)
+	id, err := uuid.Parse(random)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), id.Version())
+}
+
+func TestAsyncHandler_IDGenerator(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		IDGenerator: OperationIDGeneratorFunc(func(ctx context.Context, operation string, options StartOperationOptions) (string, error) {
+			if options.RequestID == "" {
+				return "", errors.New("request ID is required")
+			}
+			return "order-" + options.RequestID, nil
+		}),
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "charge", nil, StartOperationOptions{RequestID: "42"})
+	require.NoError(t, err)
+	require.Equal(t, "order-42", result.Pending.ID)
+	defer func() { _ = result.Pending.Cancel(ctx, CancelOperationOptions{}) }()
+
+	// Starting an operation with the same ID again is rejected as a conflict, pointing to the existing operation.
+	result, err = client.StartOperation(ctx, "charge", nil, StartOperationOptions{RequestID: "42"})
+	require.NoError(t, err)
+	require.Equal(t, "order-42", result.Pending.ID)
+
+	_, err = handler.StartOperation(ctx, "charge", &LazyValue{Reader: NewReader(strings.NewReader(""), nil)}, StartOperationOptions{})
+	require.ErrorContains(t, err, "failed to generate operation ID: request ID is required")
+}
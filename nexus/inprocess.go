@@ -0,0 +1,125 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// InProcessServiceBaseURL is the default service base URL of clients created with [NewInProcessClient].
+const InProcessServiceBaseURL = "http://in-process/"
+
+// NewInProcessHTTPCaller returns an HTTPCaller for [ClientOptions] that dispatches requests directly to the given
+// handler, typically created with [NewHTTPHandler], without going through the network.
+//
+// Requests and responses go through the same header and serialization code paths as over HTTP. Response bodies are
+// streamed, so long polls and watches behave as they would over the network.
+func NewInProcessHTTPCaller(handler http.Handler) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		ctx := request.Context()
+		serverRequest := request.Clone(ctx)
+		if serverRequest.Body == nil {
+			serverRequest.Body = http.NoBody
+		}
+		serverRequest.RequestURI = request.URL.RequestURI()
+		serverRequest.RemoteAddr = "in-process"
+		if serverRequest.Host == "" {
+			serverRequest.Host = request.URL.Host
+		}
+
+		reader, writer := io.Pipe()
+		rw := &inProcessResponseWriter{
+			header:   http.Header{},
+			request:  request,
+			body:     reader,
+			pipe:     writer,
+			response: make(chan *http.Response, 1),
+		}
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					writer.CloseWithError(fmt.Errorf("handler panic: %v", r))
+					rw.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				rw.WriteHeader(http.StatusOK)
+				writer.Close()
+			}()
+			handler.ServeHTTP(rw, serverRequest)
+		}()
+
+		select {
+		case response := <-rw.response:
+			return response, nil
+		case <-ctx.Done():
+			reader.CloseWithError(ctx.Err())
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// NewInProcessClient creates a [Client] wired directly to an HTTP handler constructed from handlerOptions, for testing
+// and for embedding a Nexus handler in the caller's process.
+//
+// The client's HTTPCaller is replaced, ServiceBaseURL defaults to [InProcessServiceBaseURL].
+func NewInProcessClient(handlerOptions HandlerOptions, clientOptions ClientOptions) (*Client, error) {
+	if handlerOptions.Handler == nil {
+		return nil, errors.New("handler is required")
+	}
+	if clientOptions.ServiceBaseURL == "" {
+		clientOptions.ServiceBaseURL = InProcessServiceBaseURL
+	}
+	clientOptions.HTTPCaller = NewInProcessHTTPCaller(NewHTTPHandler(handlerOptions))
+	return NewClient(clientOptions)
+}
+
+// inProcessResponseWriter streams a handler's response through a pipe. The response is delivered once the header is
+// written, either explicitly or by the first write, flush, or when the handler returns.
+type inProcessResponseWriter struct {
+	header   http.Header
+	request  *http.Request
+	body     *io.PipeReader
+	pipe     *io.PipeWriter
+	once     sync.Once
+	response chan *http.Response
+}
+
+func (w *inProcessResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *inProcessResponseWriter) WriteHeader(statusCode int) {
+	w.once.Do(func() {
+		header := w.header.Clone()
+		contentLength := int64(-1)
+		if v := header.Get("Content-Length"); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				contentLength = n
+			}
+		}
+		w.response <- &http.Response{
+			Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			StatusCode:    statusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          w.body,
+			ContentLength: contentLength,
+			Request:       w.request,
+		}
+	})
+}
+
+func (w *inProcessResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.pipe.Write(p)
+}
+
+// Flush implements http.Flusher. Writes are unbuffered, so this only ensures the header is sent.
+func (w *inProcessResponseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,92 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInProcess_Start(t *testing.T) {
+	client, err := NewInProcessClient(HandlerOptions{Handler: &successHandler{}}, ClientOptions{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	requestBody := []byte{0x00, 0x01}
+	response, err := client.ExecuteOperation(ctx, "i need to/be escaped", requestBody, ExecuteOperationOptions{
+		CallbackURL:    "http://test/callback",
+		CallbackHeader: Header{"callback-test": "ok"},
+		Header:         Header{"test": "ok"},
+	})
+	require.NoError(t, err)
+	var responseBody []byte
+	require.NoError(t, response.Consume(&responseBody))
+	require.Equal(t, requestBody, responseBody)
+
+	client, err = NewInProcessClient(HandlerOptions{Handler: &unsuccessfulHandler{}}, ClientOptions{})
+	require.NoError(t, err)
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{RequestID: "failed"})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateFailed, unsuccessfulError.State)
+}
+
+func TestInProcess_AsyncAndWatch(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "done", nil
+		},
+	})
+	require.NoError(t, err)
+	client, err := NewInProcessClient(HandlerOptions{Handler: handler}, ClientOptions{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	stream, err := result.Pending.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+	info, err := stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	info, err = stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, OperationStateSucceeded, info.State)
+	_, err = stream.Next()
+	require.ErrorIs(t, err, io.EOF)
+
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, value.Consume(&output))
+	require.Equal(t, "done", output)
+}
+
+func TestInProcess_ContextCanceled(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	client, err := NewInProcessClient(HandlerOptions{Handler: handler}, ClientOptions{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	_, err = result.Pending.GetResult(waitCtx, GetOperationResultOptions{Wait: time.Minute})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,61 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSONEngine is the JSON implementation used to encode and decode values, operation info and failures, e.g. a faster
+// replacement of encoding/json with a compatible API. Nil functions default to encoding/json.
+//
+// Set the same engine in [ClientOptions], [HandlerOptions], [CompletionHandlerOptions] and [AsyncHandlerOptions] to
+// take encoding/json off the hot path of both ends. [ConsumeElements] always decodes with encoding/json since it
+// decodes arrays token by token. [Failure.EncodeDetails] and [Failure.DecodeDetails], header values such as callbacks
+// and links, list page tokens, CloudEvents and generated OpenAPI documents are small or off the hot path and also
+// always use encoding/json.
+type JSONEngine struct {
+	// Marshal returns the JSON encoding of v, compatible with [json.Marshal].
+	Marshal func(v any) ([]byte, error)
+	// Unmarshal parses JSON encoded data into the value pointed to by v, compatible with [json.Unmarshal].
+	Unmarshal func(data []byte, v any) error
+}
+
+func (e JSONEngine) isDefault() bool {
+	return e.Marshal == nil && e.Unmarshal == nil
+}
+
+func (e JSONEngine) marshal(v any) ([]byte, error) {
+	if e.Marshal == nil {
+		return json.Marshal(v)
+	}
+	return e.Marshal(v)
+}
+
+func (e JSONEngine) unmarshal(data []byte, v any) error {
+	if e.Unmarshal == nil {
+		return json.Unmarshal(data, v)
+	}
+	return e.Unmarshal(data, v)
+}
+
+// encode appends the JSON encoding of v to buf. Nothing is appended on error.
+func (e JSONEngine) encode(buf *bytes.Buffer, v any) error {
+	if e.Marshal == nil {
+		return encodeJSON(buf, v)
+	}
+	b, err := e.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// NewJSONSerializer creates a [Serializer] that handles JSONables, byte slices and nils like the default serializer,
+// encoding and decoding JSON with the given engine.
+func NewJSONSerializer(engine JSONEngine) Serializer {
+	if engine.isDefault() {
+		return defaultSerializer
+	}
+	return newCompositeSerializer(engine)
+}
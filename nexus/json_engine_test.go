@@ -0,0 +1,225 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingJSONEngine returns a JSON engine wrapping encoding/json that counts its calls.
+func countingJSONEngine() (JSONEngine, *atomic.Int32, *atomic.Int32) {
+	var marshals, unmarshals atomic.Int32
+	return JSONEngine{
+		Marshal: func(v any) ([]byte, error) {
+			marshals.Add(1)
+			return json.Marshal(v)
+		},
+		Unmarshal: func(data []byte, v any) error {
+			unmarshals.Add(1)
+			return json.Unmarshal(data, v)
+		},
+	}, &marshals, &unmarshals
+}
+
+// recordingJSONEngine returns a JSON engine wrapping encoding/json that records the types of the values it marshals and
+// unmarshals.
+func recordingJSONEngine() (JSONEngine, func() []string) {
+	var mu sync.Mutex
+	var types []string
+	record := func(v any) {
+		mu.Lock()
+		defer mu.Unlock()
+		types = append(types, reflect.TypeOf(v).String())
+	}
+	return JSONEngine{
+		Marshal: func(v any) ([]byte, error) {
+			record(v)
+			return json.Marshal(v)
+		},
+		Unmarshal: func(data []byte, v any) error {
+			record(v)
+			return json.Unmarshal(data, v)
+		},
+	}, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), types...)
+	}
+}
+
+func TestNewJSONSerializer(t *testing.T) {
+	require.Equal(t, defaultSerializer, NewJSONSerializer(JSONEngine{}))
+
+	engine, marshals, unmarshals := countingJSONEngine()
+	serializer := NewJSONSerializer(engine)
+	content, err := serializer.Serialize(map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(content.Data))
+	var v map[string]int
+	require.NoError(t, serializer.Deserialize(content, &v))
+	require.Equal(t, map[string]int{"a": 1}, v)
+
+	// Byte slices and nils bypass the engine.
+	_, err = serializer.Serialize([]byte{1})
+	require.NoError(t, err)
+	_, err = serializer.Serialize(nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), marshals.Load())
+	require.Equal(t, int32(1), unmarshals.Load())
+
+	header, err := serializer.(StreamingSerializer).SerializeStream(httptest.NewRecorder(), "str")
+	require.NoError(t, err)
+	require.Equal(t, "application/json", header["type"])
+	require.Equal(t, int32(2), marshals.Load())
+}
+
+func TestJSONEngine_ClientAndHandler(t *testing.T) {
+	clientEngine, clientMarshals, clientUnmarshals := countingJSONEngine()
+	handlerEngine, handlerMarshals, handlerUnmarshals := countingJSONEngine()
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &asyncWithInfoHandler{},
+		JSON:    handlerEngine,
+	}, ClientOptions{JSON: clientEngine})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "escape/me", map[string]string{"input": "value"}, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), clientMarshals.Load())
+	require.Equal(t, int32(1), clientUnmarshals.Load())
+	require.Equal(t, int32(1), handlerMarshals.Load())
+
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateCanceled, info.State)
+	require.Equal(t, int32(2), clientUnmarshals.Load())
+	require.Equal(t, int32(2), handlerMarshals.Load())
+
+	// Failures are encoded and decoded with the engines too.
+	handle, err := client.NewHandle("unknown", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, "expected operation to be 'escape me', got: unknown", unexpectedResponseError.Failure.Message)
+	require.Equal(t, int32(3), handlerMarshals.Load())
+	require.Equal(t, int32(0), handlerUnmarshals.Load())
+	require.Equal(t, int32(3), clientUnmarshals.Load())
+}
+
+func TestJSONEngine_ClientFailures(t *testing.T) {
+	engine := JSONEngine{
+		Unmarshal: func(data []byte, v any) error {
+			if err := json.Unmarshal(data, v); err != nil {
+				return err
+			}
+			if failure, ok := v.(**Failure); ok && *failure != nil {
+				(*failure).Message = "decoded by engine: " + (*failure).Message
+			}
+			return nil
+		},
+	}
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://localhost",
+		JSON:           engine,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			recorder := httptest.NewRecorder()
+			recorder.Header().Set("Content-Type", contentTypeJSON)
+			recorder.WriteHeader(http.StatusBadRequest)
+			recorder.WriteString(`{"message": "boom"}`)
+			return recorder.Result(), nil
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.StartOperation(context.Background(), "foo", nil, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, "decoded by engine: boom", unexpectedResponseError.Failure.Message)
+	require.EqualError(t, err, `unexpected response status: "400 Bad Request": decoded by engine: boom`)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	err = handle.Cancel(context.Background(), CancelOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, "decoded by engine: boom", unexpectedResponseError.Failure.Message)
+}
+
+func TestJSONEngine_Completion(t *testing.T) {
+	senderEngine, senderMarshals, _ := countingJSONEngine()
+	receiverEngine, _, receiverUnmarshals := countingJSONEngine()
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", &OperationCompletionUnsuccessful{
+		Header:  http.Header{"Foo": []string{"bar"}},
+		State:   OperationStateCanceled,
+		Failure: &Failure{Message: "expected message"},
+		JSON:    senderEngine,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), senderMarshals.Load())
+
+	recorder := httptest.NewRecorder()
+	NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler: &failureExpectingCompletionHandler{},
+		JSON:    receiverEngine,
+	}).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, int32(1), receiverUnmarshals.Load())
+}
+
+func TestJSONEngine_AdminAndHeartbeat(t *testing.T) {
+	clientEngine, clientTypes := recordingJSONEngine()
+	handlerEngine, handlerTypes := recordingJSONEngine()
+	asyncEngine, asyncTypes := recordingJSONEngine()
+	release := make(chan struct{})
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			if err := Heartbeat(ctx, map[string]int{"progress": 50}); err != nil {
+				return nil, err
+			}
+			<-release
+			return nil, nil
+		},
+		QuotaTag: "Tenant",
+		JSON:     asyncEngine,
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler, JSON: handlerEngine}, ClientOptions{JSON: clientEngine})
+	defer teardown()
+	defer close(release)
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: map[string]string{"tenant": "t"}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		return string(info.HeartbeatDetails) == `{"progress":50}`
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, asyncTypes(), "map[string]int")
+
+	_, err = result.Pending.Heartbeat(ctx, HeartbeatOperationOptions{})
+	require.NoError(t, err)
+	_, err = client.ListOperations(ctx, ListOperationsOptions{})
+	require.NoError(t, err)
+	_, err = client.GetQuotaUsage(ctx, "t", GetQuotaUsageOptions{})
+	require.NoError(t, err)
+	_, err = client.Ping(ctx, PingOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.CancelOperations(ctx, []OperationKey{{Operation: "foo", ID: result.Pending.ID}}, CancelOperationsOptions{UseBatchRoute: true}))
+
+	require.Subset(t, clientTypes(), []string{
+		"*nexus.OperationList", "*nexus.QuotaStatus", "*nexus.ServiceInfo",
+		"nexus.cancelOperationsRequest", "*nexus.cancelOperationsResponse",
+	})
+	require.Subset(t, handlerTypes(), []string{
+		"*nexus.OperationInfo", "*nexus.OperationList", "*nexus.QuotaStatus", "nexus.ServiceInfo",
+		"*nexus.cancelOperationsRequest", "nexus.cancelOperationsResponse",
+	})
+}
@@ -0,0 +1,395 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is a compiled JSON Schema, see [NewJSONSchemaValidator].
+type jsonSchema struct {
+	// Set for the false schema, which no value conforms to.
+	never    bool
+	types    []string
+	enum     []any
+	hasConst bool
+	constant any
+
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	items                *jsonSchema
+	minItems, maxItems   *int
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	minimum, maximum, exclusiveMinimum, exclusiveMaximum *float64
+
+	allOf, anyOf, oneOf []*jsonSchema
+	not                 *jsonSchema
+}
+
+var jsonSchemaTypes = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// NewJSONSchemaValidator compiles a JSON Schema into a [SchemaValidator] for JSON payloads.
+//
+// The validation keywords type, enum, const, properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf and not are
+// supported. Other keywords, including $ref, are ignored. Use a [SchemaValidatorFunc] to plug in a complete
+// implementation.
+func NewJSONSchemaValidator(schema []byte) (SchemaValidator, error) {
+	var raw any
+	if err := json.Unmarshal(schema, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	compiled, err := compileJSONSchema(raw, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return SchemaValidatorFunc(func(content *Content) error {
+		var value any
+		if err := json.Unmarshal(content.Data, &value); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		return compiled.validate(value, "")
+	}), nil
+}
+
+func compileJSONSchema(raw any, pointer string) (*jsonSchema, error) {
+	switch raw := raw.(type) {
+	case bool:
+		return &jsonSchema{never: !raw}, nil
+	case map[string]any:
+		c := jsonSchemaCompiler{raw: raw, pointer: pointer}
+		return c.compile()
+	}
+	return nil, fmt.Errorf("%s: schema must be an object or a boolean", jsonPointerLocation(pointer))
+}
+
+// jsonSchemaCompiler compiles a schema object, recording the first error.
+type jsonSchemaCompiler struct {
+	raw     map[string]any
+	pointer string
+	err     error
+}
+
+func (c *jsonSchemaCompiler) compile() (*jsonSchema, error) {
+	s := &jsonSchema{}
+	switch t := c.raw["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, e := range t {
+			name, ok := e.(string)
+			if !ok {
+				c.fail("type", "must be a string or an array of strings")
+				break
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		c.fail("type", "must be a string or an array of strings")
+	}
+	for _, t := range s.types {
+		if i := sort.SearchStrings(jsonSchemaTypes, t); i == len(jsonSchemaTypes) || jsonSchemaTypes[i] != t {
+			c.fail("type", fmt.Sprintf("unknown type %q", t))
+		}
+	}
+	if enum, ok := c.raw["enum"]; ok {
+		if s.enum, ok = enum.([]any); !ok {
+			c.fail("enum", "must be an array")
+		}
+	}
+	s.constant, s.hasConst = c.raw["const"]
+
+	if properties, ok := c.raw["properties"]; ok {
+		m, ok := properties.(map[string]any)
+		if !ok {
+			c.fail("properties", "must be an object")
+		}
+		s.properties = make(map[string]*jsonSchema, len(m))
+		for name, property := range m {
+			s.properties[name] = c.schema(property, "properties", name)
+		}
+	}
+	if required, ok := c.raw["required"]; ok {
+		list, ok := required.([]any)
+		if !ok {
+			c.fail("required", "must be an array of strings")
+		}
+		for _, e := range list {
+			name, ok := e.(string)
+			if !ok {
+				c.fail("required", "must be an array of strings")
+				break
+			}
+			s.required = append(s.required, name)
+		}
+	}
+	if additional, ok := c.raw["additionalProperties"]; ok {
+		s.additionalProperties = c.schema(additional, "additionalProperties")
+	}
+	if items, ok := c.raw["items"]; ok {
+		s.items = c.schema(items, "items")
+	}
+	s.minItems, s.maxItems = c.count("minItems"), c.count("maxItems")
+	s.minLength, s.maxLength = c.count("minLength"), c.count("maxLength")
+	if pattern, ok := c.raw["pattern"]; ok {
+		expr, ok := pattern.(string)
+		if !ok {
+			c.fail("pattern", "must be a string")
+		} else if compiled, err := regexp.Compile(expr); err != nil {
+			c.fail("pattern", err.Error())
+		} else {
+			s.pattern = compiled
+		}
+	}
+	s.minimum, s.maximum = c.number("minimum"), c.number("maximum")
+	s.exclusiveMinimum, s.exclusiveMaximum = c.number("exclusiveMinimum"), c.number("exclusiveMaximum")
+	s.allOf, s.anyOf, s.oneOf = c.schemas("allOf"), c.schemas("anyOf"), c.schemas("oneOf")
+	if not, ok := c.raw["not"]; ok {
+		s.not = c.schema(not, "not")
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return s, nil
+}
+
+func (c *jsonSchemaCompiler) fail(keyword, message string) {
+	if c.err == nil {
+		c.err = fmt.Errorf("%s: %s", jsonPointerLocation(c.pointer+"/"+keyword), message)
+	}
+}
+
+func (c *jsonSchemaCompiler) schema(raw any, path ...string) *jsonSchema {
+	pointer := c.pointer
+	for _, p := range path {
+		pointer += "/" + escapeJSONPointer(p)
+	}
+	s, err := compileJSONSchema(raw, pointer)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return s
+}
+
+func (c *jsonSchemaCompiler) schemas(keyword string) []*jsonSchema {
+	raw, ok := c.raw[keyword]
+	if !ok {
+		return nil
+	}
+	list, ok := raw.([]any)
+	if !ok || len(list) == 0 {
+		c.fail(keyword, "must be a non-empty array of schemas")
+		return nil
+	}
+	schemas := make([]*jsonSchema, len(list))
+	for i, e := range list {
+		schemas[i] = c.schema(e, keyword, strconv.Itoa(i))
+	}
+	return schemas
+}
+
+func (c *jsonSchemaCompiler) number(keyword string) *float64 {
+	raw, ok := c.raw[keyword]
+	if !ok {
+		return nil
+	}
+	n, ok := raw.(float64)
+	if !ok {
+		c.fail(keyword, "must be a number")
+		return nil
+	}
+	return &n
+}
+
+func (c *jsonSchemaCompiler) count(keyword string) *int {
+	n := c.number(keyword)
+	if n == nil {
+		return nil
+	}
+	if *n < 0 || *n != math.Trunc(*n) {
+		c.fail(keyword, "must be a non-negative integer")
+		return nil
+	}
+	i := int(*n)
+	return &i
+}
+
+// validate returns an error describing the first violation of the schema by a value decoded with encoding/json.
+func (s *jsonSchema) validate(value any, pointer string) error {
+	if s.never {
+		return jsonSchemaErrorf(pointer, "no value is allowed")
+	}
+	actual := jsonTypeOf(value)
+	if len(s.types) > 0 && !s.allowsType(actual) {
+		return jsonSchemaErrorf(pointer, "expected %s, got %s", strings.Join(s.types, " or "), actual)
+	}
+	if s.enum != nil && !containsJSONValue(s.enum, value) {
+		return jsonSchemaErrorf(pointer, "value is not one of the allowed values")
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constant, value) {
+		return jsonSchemaErrorf(pointer, "value does not match the constant")
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := value[name]; !ok {
+				return jsonSchemaErrorf(pointer, "missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.properties[name]
+			if !ok {
+				property = s.additionalProperties
+				if property != nil && property.never {
+					return jsonSchemaErrorf(pointer, "unexpected property %q", name)
+				}
+			}
+			if property == nil {
+				continue
+			}
+			if err := property.validate(value[name], pointer+"/"+escapeJSONPointer(name)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.minItems != nil && len(value) < *s.minItems {
+			return jsonSchemaErrorf(pointer, "expected at least %d items, got %d", *s.minItems, len(value))
+		}
+		if s.maxItems != nil && len(value) > *s.maxItems {
+			return jsonSchemaErrorf(pointer, "expected at most %d items, got %d", *s.maxItems, len(value))
+		}
+		if s.items != nil {
+			for i, item := range value {
+				if err := s.items.validate(item, pointer+"/"+strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.minLength != nil && length < *s.minLength {
+			return jsonSchemaErrorf(pointer, "expected at least %d characters, got %d", *s.minLength, length)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			return jsonSchemaErrorf(pointer, "expected at most %d characters, got %d", *s.maxLength, length)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			return jsonSchemaErrorf(pointer, "value does not match pattern %q", s.pattern.String())
+		}
+	case float64:
+		if s.minimum != nil && value < *s.minimum {
+			return jsonSchemaErrorf(pointer, "expected at least %v, got %v", *s.minimum, value)
+		}
+		if s.maximum != nil && value > *s.maximum {
+			return jsonSchemaErrorf(pointer, "expected at most %v, got %v", *s.maximum, value)
+		}
+		if s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum {
+			return jsonSchemaErrorf(pointer, "expected more than %v, got %v", *s.exclusiveMinimum, value)
+		}
+		if s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum {
+			return jsonSchemaErrorf(pointer, "expected less than %v, got %v", *s.exclusiveMaximum, value)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		if err := sub.validate(value, pointer); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil && matchingJSONSchemas(s.anyOf, value, pointer) == 0 {
+		return jsonSchemaErrorf(pointer, "value does not match any of the allowed schemas")
+	}
+	if s.oneOf != nil {
+		if n := matchingJSONSchemas(s.oneOf, value, pointer); n != 1 {
+			return jsonSchemaErrorf(pointer, "expected value to match exactly one schema, matched %d", n)
+		}
+	}
+	if s.not != nil && s.not.validate(value, pointer) == nil {
+		return jsonSchemaErrorf(pointer, "value matches a disallowed schema")
+	}
+	return nil
+}
+
+func jsonSchemaErrorf(pointer, format string, args ...any) error {
+	return errors.New(jsonPointerLocation(pointer) + ": " + fmt.Sprintf(format, args...))
+}
+
+func (s *jsonSchema) allowsType(actual string) bool {
+	for _, t := range s.types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingJSONSchemas returns the number of schemas value conforms to.
+func matchingJSONSchemas(schemas []*jsonSchema, value any, pointer string) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub.validate(value, pointer) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// jsonTypeOf returns the JSON Schema type of a value decoded with encoding/json. Integral numbers are integers.
+func jsonTypeOf(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsJSONValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// jsonPointerLocation describes the location a JSON pointer refers to in error messages.
+func jsonPointerLocation(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
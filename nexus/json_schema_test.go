@@ -0,0 +1,82 @@
+package nexus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaValidator(t *testing.T) {
+	validator, err := NewJSONSchemaValidator([]byte(`{
+		"type": "object",
+		"required": ["id", "items"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "pattern": "^ord-[0-9]+$"},
+			"priority": {"enum": ["low", "high"]},
+			"note": {"type": ["string", "null"], "maxLength": 3},
+			"items": {
+				"type": "array",
+				"minItems": 1,
+				"items": {
+					"type": "object",
+					"required": ["sku", "quantity"],
+					"properties": {
+						"sku": {"type": "string", "minLength": 1},
+						"quantity": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100}
+					}
+				}
+			},
+			"total": {"oneOf": [{"type": "integer"}, {"type": "string"}]},
+			"discount": {"anyOf": [{"const": 0}, {"minimum": 5, "maximum": 50}]},
+			"tag": {"allOf": [{"type": "string"}, {"not": {"const": "internal"}}]}
+		}
+	}`))
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		data, err string
+	}{
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 2}]}`, ""},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 2}], "priority": "high", "note": null, "total": "9.99", "discount": 10, "tag": "gift"}`, ""},
+		{`[]`, "/: expected object, got array"},
+		{`{"id": "ord-1"}`, `/: missing required property "items"`},
+		{`{"id": "ord-1", "items": [], "extra": 1}`, `/: unexpected property "extra"`},
+		{`{"id": "1", "items": []}`, `/id: value does not match pattern "^ord-[0-9]+$"`},
+		{`{"id": "ord-1", "items": []}`, "/items: expected at least 1 items, got 0"},
+		{`{"id": "ord-1", "items": [{"sku": "", "quantity": 1}]}`, "/items/0/sku: expected at least 1 characters, got 0"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1.5}]}`, "/items/0/quantity: expected integer, got number"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 0}]}`, "/items/0/quantity: expected at least 1, got 0"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 100}]}`, "/items/0/quantity: expected less than 100, got 100"},
+		{`{"id": "ord-1", "items": [{"sku": "a"}]}`, `/items/0: missing required property "quantity"`},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1}], "priority": "urgent"}`, "/priority: value is not one of the allowed values"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1}], "note": "long"}`, "/note: expected at most 3 characters, got 4"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1}], "total": true}`, "/total: expected value to match exactly one schema, matched 0"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1}], "discount": 3}`, "/discount: value does not match any of the allowed schemas"},
+		{`{"id": "ord-1", "items": [{"sku": "a", "quantity": 1}], "tag": "internal"}`, "/tag: value matches a disallowed schema"},
+		{`{`, "invalid JSON: unexpected end of JSON input"},
+	} {
+		err := validator.Validate(&Content{Data: []byte(c.data)})
+		if c.err == "" {
+			require.NoError(t, err, c.data)
+		} else {
+			require.EqualError(t, err, c.err, c.data)
+		}
+	}
+}
+
+func TestJSONSchemaValidator_InvalidSchema(t *testing.T) {
+	for schema, expected := range map[string]string{
+		`[]`:                                  "invalid JSON schema: /: schema must be an object or a boolean",
+		`{"type": "text"}`:                    `invalid JSON schema: /type: unknown type "text"`,
+		`{"properties": {"a/b": 1}}`:          "invalid JSON schema: /properties/a~1b: schema must be an object or a boolean",
+		`{"minLength": -1}`:                   "invalid JSON schema: /minLength: must be a non-negative integer",
+		`{"pattern": "("}`:                    "invalid JSON schema: /pattern: error parsing regexp: missing closing ): `(`",
+		`{"anyOf": []}`:                       "invalid JSON schema: /anyOf: must be a non-empty array of schemas",
+		`{"items": {"required": "id"}}`:       "invalid JSON schema: /items/required: must be an array of strings",
+		`{"oneOf": [true, {"maximum": "1"}]}`: "invalid JSON schema: /oneOf/1/maximum: must be a number",
+	} {
+		_, err := NewJSONSchemaValidator([]byte(schema))
+		require.EqualError(t, err, expected, schema)
+	}
+}
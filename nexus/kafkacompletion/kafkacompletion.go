@@ -0,0 +1,114 @@
+// Package kafkacompletion delivers operation completions over Kafka, for callback receivers that consume a topic
+// instead of exposing an HTTP endpoint.
+//
+// The package does not depend on a specific Kafka client library. Adapt your client of choice (e.g. franz-go or
+// confluent-kafka-go) to the minimal [Client] interface.
+//
+// Completions are produced as records whose headers are the header fields of the [nexus.CompletionMessage] and whose
+// value is its body, keyed by operation ID so that completions of an operation land on the same partition.
+package kafkacompletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Scheme is the callback URL scheme to register a [Transport] for in [nexus.CallbackDeliveryOptions.Transports].
+const Scheme = "kafka"
+
+// Record is a Kafka record.
+type Record struct {
+	// Topic of the record.
+	Topic string
+	// Key of the record, the ID of the completed operation, or the request ID of the completion if the operation ID
+	// is unknown.
+	Key []byte
+	// Headers of the record.
+	Headers map[string]string
+	// Value of the record.
+	Value []byte
+}
+
+// Client is the subset of Kafka functionality required by this package.
+type Client interface {
+	// Produce produces a record, returning once it was acknowledged by the broker.
+	Produce(ctx context.Context, record Record) error
+}
+
+// Options are options for [New].
+type Options struct {
+	// Kafka client. Required.
+	Client Client
+	// Optional function returning the topic to deliver the completion for a callback to.
+	// Defaults to the host of the callback URL, e.g. the topic for the callback "kafka://completions" is
+	// "completions".
+	Topic func(callback nexus.Callback) (string, error)
+}
+
+// Transport is a [nexus.CompletionTransport] producing completions to Kafka topics.
+type Transport struct {
+	options Options
+}
+
+// New creates a new [Transport] from the given options.
+func New(options Options) (*Transport, error) {
+	if options.Client == nil {
+		return nil, errors.New("client is required")
+	}
+	if options.Topic == nil {
+		options.Topic = defaultTopic
+	}
+	return &Transport{options: options}, nil
+}
+
+func defaultTopic(callback nexus.Callback) (string, error) {
+	u, err := url.Parse(callback.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("callback URL has no topic: %q", callback.URL)
+	}
+	return u.Host, nil
+}
+
+// DeliverCompletion implements [nexus.CompletionTransport].
+func (t *Transport) DeliverCompletion(ctx context.Context, callback nexus.Callback, message *nexus.CompletionMessage) error {
+	topic, err := t.options.Topic(callback)
+	if err != nil {
+		return err
+	}
+	return t.options.Client.Produce(ctx, NewRecord(topic, message))
+}
+
+// NewRecord encodes a completion message as a record of the given topic.
+func NewRecord(topic string, message *nexus.CompletionMessage) Record {
+	key := message.Get(nexus.HeaderOperationID)
+	if key == "" {
+		key = message.Get(nexus.HeaderRequestID)
+	}
+	return Record{
+		Topic:   topic,
+		Key:     []byte(key),
+		Headers: maps.Clone(message.Header),
+		Value:   message.Body,
+	}
+}
+
+// Message decodes a record produced by a [Transport], for handling it with a [nexus.CompletionMessageHandler].
+func Message(record Record) (*nexus.CompletionMessage, error) {
+	message := &nexus.CompletionMessage{Header: make(map[string]string, len(record.Headers)), Body: record.Value}
+	for k, v := range record.Headers {
+		message.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if message.Get(nexus.HeaderOperationState) == "" {
+		return nil, errors.New("invalid completion record: missing operation state header")
+	}
+	return message, nil
+}
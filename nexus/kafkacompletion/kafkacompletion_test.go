@@ -0,0 +1,95 @@
+package kafkacompletion
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient sends produced records to a channel.
+type fakeClient struct {
+	records chan Record
+}
+
+func (c *fakeClient) Produce(ctx context.Context, record Record) error {
+	c.records <- record
+	return nil
+}
+
+func TestTransport(t *testing.T) {
+	client := &fakeClient{records: make(chan Record, 1)}
+	transport, err := New(Options{Client: client})
+	require.NoError(t, err)
+	handler, err := nexus.NewAsyncHandler(nexus.AsyncHandlerOptions{
+		Store: nexus.NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (any, error) {
+			return nil, &nexus.UnsuccessfulOperationError{State: nexus.OperationStateFailed, Failure: nexus.Failure{Message: "oops"}}
+		},
+		CallbackDelivery: &nexus.CallbackDeliveryOptions{
+			Transports: map[string]nexus.CompletionTransport{Scheme: transport},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	result, err := handler.StartOperation(ctx, "foo", &nexus.LazyValue{Reader: nexus.NewReader(strings.NewReader(""), nil)}, nexus.StartOperationOptions{
+		CallbackURL: "kafka://completions",
+	})
+	require.NoError(t, err)
+	operationID := result.(*nexus.HandlerStartOperationResultAsync).OperationID
+
+	var record Record
+	select {
+	case record = <-client.records:
+	case <-time.After(5 * time.Second):
+		t.Fatal("completion not produced")
+	}
+	require.Equal(t, "completions", record.Topic)
+	require.Equal(t, operationID, string(record.Key))
+
+	// Brokers and clients may not preserve the case of header keys.
+	headers := make(map[string]string, len(record.Headers))
+	for k, v := range record.Headers {
+		headers[strings.ToLower(k)] = v
+	}
+	record.Headers = headers
+	message, err := Message(record)
+	require.NoError(t, err)
+
+	var received *nexus.CompletionRequest
+	messageHandler := nexus.NewCompletionMessageHandler(nexus.CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, completion *nexus.CompletionRequest) error {
+			received = completion
+			return nil
+		}),
+	})
+	require.NoError(t, messageHandler.HandleMessage(ctx, message))
+	require.Equal(t, nexus.OperationStateFailed, received.State)
+	require.Equal(t, operationID, received.OperationID)
+	require.Equal(t, "oops", received.Failure.Message)
+}
+
+func TestMessage_Invalid(t *testing.T) {
+	_, err := Message(Record{Value: []byte("data")})
+	require.ErrorContains(t, err, "missing operation state")
+}
+
+func TestNew_InvalidCallbackURL(t *testing.T) {
+	_, err := New(Options{})
+	require.Error(t, err)
+
+	transport, err := New(Options{Client: &fakeClient{}})
+	require.NoError(t, err)
+	err = transport.DeliverCompletion(context.Background(), nexus.Callback{URL: "kafka:///"}, &nexus.CompletionMessage{})
+	require.ErrorContains(t, err, "no topic")
+}
+
+type completionHandlerFunc func(ctx context.Context, completion *nexus.CompletionRequest) error
+
+func (f completionHandlerFunc) CompleteOperation(ctx context.Context, completion *nexus.CompletionRequest) error {
+	return f(ctx, completion)
+}
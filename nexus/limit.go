@@ -0,0 +1,79 @@
+package nexus
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// concurrencyLimiter sheds requests exceeding a global and per method limit on concurrently handled requests.
+type concurrencyLimiter struct {
+	global     chan struct{}
+	perMethod  map[HandlerMethod]chan struct{}
+	retryAfter string
+}
+
+func newConcurrencyLimiter(options HandlerOptions) *concurrencyLimiter {
+	if options.MaxConcurrentRequests <= 0 && len(options.MaxConcurrentRequestsPerMethod) == 0 {
+		return nil
+	}
+	l := &concurrencyLimiter{perMethod: make(map[HandlerMethod]chan struct{})}
+	if options.MaxConcurrentRequests > 0 {
+		l.global = make(chan struct{}, options.MaxConcurrentRequests)
+	}
+	for method, limit := range options.MaxConcurrentRequestsPerMethod {
+		if limit > 0 {
+			l.perMethod[method] = make(chan struct{}, limit)
+		}
+	}
+	retryAfter := options.LoadSheddingRetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	l.retryAfter = strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return l
+}
+
+// acquire reserves a slot for a request to the given method, returning a function that releases it, or false if
+// either limit is reached.
+func (l *concurrencyLimiter) acquire(method HandlerMethod) (func(), bool) {
+	slots := make([]chan struct{}, 0, 2)
+	if l.global != nil {
+		slots = append(slots, l.global)
+	}
+	if s, ok := l.perMethod[method]; ok {
+		slots = append(slots, s)
+	}
+	release := func(n int) {
+		for _, s := range slots[:n] {
+			<-s
+		}
+	}
+	for i, s := range slots {
+		select {
+		case s <- struct{}{}:
+		default:
+			release(i)
+			return nil, false
+		}
+	}
+	return func() { release(len(slots)) }, true
+}
+
+// limit reserves a slot for a request to the given method, returning a function that releases it. Requests exceeding
+// the configured concurrency limits are rejected with 503 Service Unavailable and a Retry-After header, in which case
+// false is returned.
+func (h *httpHandler) limit(method HandlerMethod, writer http.ResponseWriter) (func(), bool) {
+	if h.limiter == nil {
+		return func() {}, true
+	}
+	release, ok := h.limiter.acquire(method)
+	if !ok {
+		h.logger.Warn("shedding request, too many concurrent requests")
+		writer.Header().Set(headerRetryAfter, h.limiter.retryAfter)
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeUnavailable, "too many concurrent requests"))
+		return nil, false
+	}
+	return release, true
+}
@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_MaxConcurrentRequestsPerMethod(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:                        handler,
+		GetResultTimeout:               time.Second,
+		MaxConcurrentRequestsPerMethod: map[HandlerMethod]int{HandlerMethodGetOperationResult: 1},
+		LoadSheddingRetryAfter:         1500 * time.Millisecond,
+	}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	go func() {
+		_, _ = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: 10 * time.Millisecond})
+		var unexpectedResponseError *UnexpectedResponseError
+		if !errors.As(err, &unexpectedResponseError) {
+			return false
+		}
+		require.Equal(t, http.StatusServiceUnavailable, unexpectedResponseError.Response.StatusCode)
+		require.Equal(t, "2", unexpectedResponseError.Response.Header.Get("Retry-After"))
+		require.Equal(t, "too many concurrent requests", unexpectedResponseError.Failure.Message)
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	// Other methods are not limited.
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := newConcurrencyLimiter(HandlerOptions{
+		MaxConcurrentRequests:          2,
+		MaxConcurrentRequestsPerMethod: map[HandlerMethod]int{HandlerMethodStartOperation: 1},
+	})
+	release, ok := limiter.acquire(HandlerMethodStartOperation)
+	require.True(t, ok)
+	_, ok = limiter.acquire(HandlerMethodStartOperation)
+	require.False(t, ok)
+	// A rejected per method acquisition does not hold on to a global slot.
+	releaseInfo, ok := limiter.acquire(HandlerMethodGetOperationInfo)
+	require.True(t, ok)
+	_, ok = limiter.acquire(HandlerMethodCancelOperation)
+	require.False(t, ok)
+
+	release()
+	releaseInfo()
+	_, ok = limiter.acquire(HandlerMethodStartOperation)
+	require.True(t, ok)
+
+	require.Nil(t, newConcurrencyLimiter(HandlerOptions{}))
+}
@@ -0,0 +1,136 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// ErrUnknownLinkType is returned when parsing or formatting a link of a type that is not registered in a
+// [LinkRegistry].
+var ErrUnknownLinkType = errors.New("unknown link type")
+
+// A LinkType describes how links of a given [Link.Type] map to values of type T, e.g. references to workflow events.
+type LinkType[T any] struct {
+	// Name of the type, matched against [Link.Type]. Required.
+	Name string
+	// Parses the URL of a link of this type. Required.
+	Parse func(u *url.URL) (T, error)
+	// Formats a value as the URL of a link of this type. Required.
+	Format func(v T) (*url.URL, error)
+}
+
+// registeredLinkType is a type erased [LinkType].
+type registeredLinkType struct {
+	name   string
+	parse  func(u *url.URL) (any, error)
+	format func(v any) (*url.URL, error)
+}
+
+// A LinkRegistry registers link types, allowing applications to work with typed values instead of parsing link URLs
+// themselves. The zero value is an empty registry ready to use.
+//
+// Register all link types before using the registry, it is safe for concurrent use afterwards.
+type LinkRegistry struct {
+	byName   map[string]*registeredLinkType
+	byGoType map[reflect.Type]*registeredLinkType
+}
+
+// RegisterLinkType registers a link type. Returns an error if a type with the same name or Go type was already
+// registered.
+//
+// Not thread safe.
+func RegisterLinkType[T any](r *LinkRegistry, typ LinkType[T]) error {
+	if typ.Name == "" || typ.Parse == nil || typ.Format == nil {
+		return errors.New("link type name, parse and format functions are required")
+	}
+	goType := reflect.TypeOf((*T)(nil)).Elem()
+	if r.byName == nil {
+		r.byName = make(map[string]*registeredLinkType)
+		r.byGoType = make(map[reflect.Type]*registeredLinkType)
+	}
+	if _, ok := r.byName[typ.Name]; ok {
+		return fmt.Errorf("duplicate link type: %s", typ.Name)
+	}
+	if _, ok := r.byGoType[goType]; ok {
+		return fmt.Errorf("duplicate link type for %s", goType)
+	}
+	registered := &registeredLinkType{
+		name: typ.Name,
+		parse: func(u *url.URL) (any, error) {
+			return typ.Parse(u)
+		},
+		format: func(v any) (*url.URL, error) {
+			return typ.Format(v.(T))
+		},
+	}
+	r.byName[typ.Name] = registered
+	r.byGoType[goType] = registered
+	return nil
+}
+
+// Parse parses a link into a value of its registered type. Returns an error wrapping [ErrUnknownLinkType] if the
+// link's type is not registered.
+func (r *LinkRegistry) Parse(link Link) (any, error) {
+	typ, ok := r.byName[link.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLinkType, link.Type)
+	}
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link URL: %w", err)
+	}
+	return typ.parse(u)
+}
+
+// ParseLink parses a link into a value of type T. Returns an error wrapping [ErrUnknownLinkType] if the link's type is
+// not registered, and an error if it is registered for a different Go type.
+func ParseLink[T any](r *LinkRegistry, link Link) (T, error) {
+	var t T
+	v, err := r.Parse(link)
+	if err != nil {
+		return t, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return t, fmt.Errorf("link type %q does not parse into %T", link.Type, t)
+	}
+	return t, nil
+}
+
+// ParseLinks parses the links of the type registered for T, e.g. the links of an [OperationHandle], skipping links of
+// other types.
+func ParseLinks[T any](r *LinkRegistry, links []Link) ([]T, error) {
+	typ, ok := r.byGoType[reflect.TypeOf((*T)(nil)).Elem()]
+	if !ok {
+		var t T
+		return nil, fmt.Errorf("%w: %T", ErrUnknownLinkType, t)
+	}
+	var values []T
+	for _, link := range links {
+		if link.Type != typ.name {
+			continue
+		}
+		v, err := ParseLink[T](r, link)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// FormatLink formats a value as a link with the given relation using the link type registered for T. Returns an error
+// wrapping [ErrUnknownLinkType] if no link type is registered for T.
+func FormatLink[T any](r *LinkRegistry, rel string, v T) (Link, error) {
+	typ, ok := r.byGoType[reflect.TypeOf((*T)(nil)).Elem()]
+	if !ok {
+		return Link{}, fmt.Errorf("%w: %T", ErrUnknownLinkType, v)
+	}
+	u, err := typ.format(v)
+	if err != nil {
+		return Link{}, err
+	}
+	return Link{URL: u.String(), Rel: rel, Type: typ.name}, nil
+}
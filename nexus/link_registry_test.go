@@ -0,0 +1,66 @@
+package nexus
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// workflowEvent references an event in a workflow's history.
+type workflowEvent struct {
+	WorkflowID string
+	EventID    int64
+}
+
+var workflowEventLinkType = LinkType[workflowEvent]{
+	Name: "example.WorkflowEvent",
+	Parse: func(u *url.URL) (workflowEvent, error) {
+		eventID, err := strconv.ParseInt(u.Query().Get("eventID"), 10, 64)
+		if err != nil {
+			return workflowEvent{}, fmt.Errorf("invalid event ID: %w", err)
+		}
+		return workflowEvent{WorkflowID: u.Opaque, EventID: eventID}, nil
+	},
+	Format: func(v workflowEvent) (*url.URL, error) {
+		return &url.URL{Scheme: "workflow", Opaque: v.WorkflowID, RawQuery: "eventID=" + strconv.FormatInt(v.EventID, 10)}, nil
+	},
+}
+
+func TestLinkRegistry(t *testing.T) {
+	var registry LinkRegistry
+	require.NoError(t, RegisterLinkType(&registry, workflowEventLinkType))
+	require.ErrorContains(t, RegisterLinkType(&registry, workflowEventLinkType), "duplicate link type")
+	require.Error(t, RegisterLinkType(&registry, LinkType[string]{Name: "string"}))
+
+	event := workflowEvent{WorkflowID: "order-1", EventID: 5}
+	link, err := FormatLink(&registry, "source", event)
+	require.NoError(t, err)
+	require.Equal(t, Link{URL: "workflow:order-1?eventID=5", Rel: "source", Type: "example.WorkflowEvent"}, link)
+
+	parsed, err := ParseLink[workflowEvent](&registry, link)
+	require.NoError(t, err)
+	require.Equal(t, event, parsed)
+	v, err := registry.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, event, v)
+
+	values, err := ParseLinks[workflowEvent](&registry, []Link{{URL: "https://example.com", Type: "text/html"}, link})
+	require.NoError(t, err)
+	require.Equal(t, []workflowEvent{event}, values)
+
+	_, err = ParseLink[string](&registry, link)
+	require.ErrorContains(t, err, "does not parse into string")
+	_, err = registry.Parse(Link{URL: "workflow:order-1?eventID=x", Type: "example.WorkflowEvent"})
+	require.ErrorContains(t, err, "invalid event ID")
+	_, err = registry.Parse(Link{URL: "https://example.com", Type: "text/html"})
+	require.ErrorIs(t, err, ErrUnknownLinkType)
+	_, err = FormatLink(&registry, "source", "not registered")
+	require.ErrorIs(t, err, ErrUnknownLinkType)
+
+	var empty LinkRegistry
+	_, err = empty.Parse(link)
+	require.ErrorIs(t, err, ErrUnknownLinkType)
+}
@@ -0,0 +1,53 @@
+package nexus
+
+import "net/url"
+
+// Registered link relations, see [Link.Rel].
+const (
+	// A page for viewing the operation or its backing resource in a user interface.
+	LinkRelationUI = "ui"
+	// A page for viewing the logs of the operation's execution.
+	LinkRelationLogs = "logs"
+	// The operation this operation reruns, see [RetryOperation].
+	LinkRelationRetryOf = "retry-of"
+)
+
+// A Link points from an operation to a related resource, e.g. the backing resource's page in a user interface.
+//
+// Handlers attach links to operations via [HandlerStartOperationResultAsync.Links]; they are reported to callers in
+// [OperationHandle.Links] and [OperationInfo.Links].
+type Link struct {
+	// URL of the linked resource.
+	URL string `json:"url"`
+	// Relation of the linked resource to the operation, one of the LinkRelation constants or an application defined
+	// relation, preferably a URI.
+	Rel string `json:"rel"`
+	// Optional type of the linked resource, e.g. a media type such as "text/html", or the name of a type registered in
+	// a [LinkRegistry] for parsing the link into a typed value.
+	Type string `json:"type,omitempty"`
+}
+
+// FindLink returns the first link with the given relation.
+func FindLink(links []Link, rel string) (Link, bool) {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// Link returns the URL of the first link of the operation with the given relation, resolved against the client's
+// service base URL if relative. Returns false if the handler did not report a link with the relation or its URL is
+// invalid.
+func (h *OperationHandle[T]) Link(rel string) (*url.URL, bool) {
+	link, ok := FindLink(h.Links, rel)
+	if !ok {
+		return nil, false
+	}
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return nil, false
+	}
+	return h.client.serviceBaseURL.ResolveReference(u), true
+}
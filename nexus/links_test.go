@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Links(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		Links: func(ctx context.Context, operation, operationID string, options StartOperationOptions) []Link {
+			return []Link{
+				{URL: "https://console.example.com/operations/" + operationID, Rel: LinkRelationUI, Type: "text/html"},
+				{URL: "/logs/" + operationID, Rel: LinkRelationLogs},
+			}
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := StartOperation(ctx, client, NewOperationReference[NoValue, NoValue]("foo"), nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.Len(t, handle.Links, 2)
+
+	ui, ok := handle.Link(LinkRelationUI)
+	require.True(t, ok)
+	require.Equal(t, "https://console.example.com/operations/"+handle.ID, ui.String())
+	logs, ok := handle.Link(LinkRelationLogs)
+	require.True(t, ok)
+	require.Equal(t, client.serviceBaseURL.Host, logs.Host)
+	require.Equal(t, "/logs/"+handle.ID, logs.Path)
+	_, ok = handle.Link("missing")
+	require.False(t, ok)
+
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, handle.Links, info.Links)
+	link, ok := FindLink(info.Links, LinkRelationUI)
+	require.True(t, ok)
+	require.Equal(t, "text/html", link.Type)
+
+	existing, err := client.NewHandle("foo", handle.ID)
+	require.NoError(t, err)
+	require.Empty(t, existing.Links)
+}
@@ -0,0 +1,415 @@
+package nexus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultListPageSize is the page size used by [AsyncHandler.ListOperations] when none is requested.
+const DefaultListPageSize = 100
+
+// An OperationFilter selects operations in [Client.ListOperations]. Zero valued fields match all operations.
+type OperationFilter struct {
+	// Only match operations with this name.
+	Operation string
+	// Only match operations in one of these states.
+	States []OperationState
+	// Only match operations that have all of these tags.
+	Tags map[string]string
+	// Only match operations created at or after this time.
+	CreatedAfter time.Time
+	// Only match operations created before this time.
+	CreatedBefore time.Time
+	// Only match completed operations whose outcome expired at or before this time, see
+	// [AsyncHandlerOptions.Retentions].
+	ExpiredBefore time.Time
+}
+
+// Matches reports whether the given record matches the filter. Provided for [OperationLister] implementations.
+func (f *OperationFilter) Matches(record *OperationRecord) bool {
+	if f.Operation != "" && record.Operation != f.Operation {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, record.State) {
+		return false
+	}
+	for k, v := range f.Tags {
+		if record.Tags[strings.ToLower(k)] != v {
+			return false
+		}
+	}
+	if !f.CreatedAfter.IsZero() && record.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !record.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.ExpiredBefore.IsZero() && !record.IsExpired(f.ExpiredBefore) {
+		return false
+	}
+	return true
+}
+
+// ListOperationsOptions are options for the ListOperations client and server APIs.
+type ListOperationsOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Filter selecting the operations to list.
+	Filter OperationFilter
+	// Maximum number of operations to return. If zero, the handler picks a default.
+	PageSize int
+	// Token returned in [OperationList.NextPageToken] by a previous call with the same filter, used to fetch the next
+	// page.
+	PageToken string
+}
+
+// OperationSummary describes an operation in an [OperationList].
+type OperationSummary struct {
+	// Name of the operation.
+	Operation string `json:"operation"`
+	// ID of the operation.
+	ID string `json:"id"`
+	// State of the operation.
+	State OperationState `json:"state"`
+	// Tags attached to the operation when it was started.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Time the operation was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// Time the operation was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Time the handler last recorded a heartbeat for the operation, if it records heartbeats.
+	LastHeartbeatTime *time.Time `json:"lastHeartbeatTime,omitempty"`
+	// Metadata set by the handler, see [OperationInfo.Metadata].
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// OperationList is a page of operations returned by [Client.ListOperations], ordered by creation time, newest first.
+type OperationList struct {
+	// Operations on this page.
+	Operations []*OperationSummary `json:"operations"`
+	// Token for fetching the next page. Empty if this is the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// An OperationLister is an [OperationStore] that supports listing records, enabling [AsyncHandler.ListOperations].
+type OperationLister interface {
+	// ListOperations returns up to pageSize records matching filter ordered by creation time, newest first, and a
+	// token for fetching the next page, which is empty when there are no more records.
+	ListOperations(ctx context.Context, filter OperationFilter, pageSize int, pageToken string) ([]*OperationRecord, string, error)
+}
+
+// Summary returns the [OperationSummary] representation of this record.
+func (r *OperationRecord) Summary() *OperationSummary {
+	info := r.Info()
+	return &OperationSummary{
+		Operation:         r.Operation,
+		ID:                r.ID,
+		State:             r.State,
+		Tags:              r.Tags,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+		LastHeartbeatTime: info.LastHeartbeatTime,
+		Metadata:          info.Metadata,
+	}
+}
+
+// memoryPageToken identifies the last record of a page returned by MemoryOperationStore.ListOperations.
+type memoryPageToken struct {
+	CreatedAt   int64  `json:"c"`
+	Operation   string `json:"o"`
+	OperationID string `json:"i"`
+}
+
+// ListOperations implements OperationLister.
+func (s *MemoryOperationStore) ListOperations(ctx context.Context, filter OperationFilter, pageSize int, pageToken string) ([]*OperationRecord, string, error) {
+	var after *memoryPageToken
+	if pageToken != "" {
+		b, err := base64.RawURLEncoding.DecodeString(pageToken)
+		if err != nil {
+			return nil, "", HandlerErrorf(HandlerErrorTypeBadRequest, "invalid page token")
+		}
+		after = &memoryPageToken{}
+		if err := json.Unmarshal(b, after); err != nil {
+			return nil, "", HandlerErrorf(HandlerErrorTypeBadRequest, "invalid page token")
+		}
+	}
+
+	s.mu.Lock()
+	var matches []*OperationRecord
+	for _, record := range s.records {
+		if filter.Matches(record) {
+			matches = append(matches, record.Clone())
+		}
+	}
+	s.mu.Unlock()
+
+	// Order newest first, breaking ties by operation name and ID.
+	compare := func(a *OperationRecord, b memoryPageToken) int {
+		if c := a.CreatedAt.UnixNano(); c != b.CreatedAt {
+			if c > b.CreatedAt {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(b.Operation, a.Operation); c != 0 {
+			return c
+		}
+		return strings.Compare(b.OperationID, a.ID)
+	}
+	slices.SortFunc(matches, func(a, b *OperationRecord) int {
+		return compare(a, memoryPageToken{b.CreatedAt.UnixNano(), b.Operation, b.ID})
+	})
+	if after != nil {
+		i := sort.Search(len(matches), func(i int) bool {
+			return compare(matches[i], *after) > 0
+		})
+		matches = matches[i:]
+	}
+	if len(matches) <= pageSize {
+		return matches, "", nil
+	}
+	matches = matches[:pageSize]
+	last := matches[pageSize-1]
+	b, err := json.Marshal(memoryPageToken{last.CreatedAt.UnixNano(), last.Operation, last.ID})
+	if err != nil {
+		return nil, "", err
+	}
+	return matches, base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+var _ OperationLister = &MemoryOperationStore{}
+
+// operationFilterFromQuery parses an OperationFilter encoded with addOperationFilterToQuery.
+func operationFilterFromQuery(query url.Values) (OperationFilter, error) {
+	filter := OperationFilter{
+		Operation: query.Get(QueryOperation),
+	}
+	for _, state := range query[QueryState] {
+		filter.States = append(filter.States, OperationState(state))
+	}
+	for _, tag := range query[QueryTag] {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok {
+			return filter, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid tag query parameter: %q", tag)
+		}
+		if filter.Tags == nil {
+			filter.Tags = make(map[string]string)
+		}
+		filter.Tags[strings.ToLower(k)] = v
+	}
+	for param, t := range map[string]*time.Time{
+		QueryCreatedAfter:  &filter.CreatedAfter,
+		QueryCreatedBefore: &filter.CreatedBefore,
+		QueryExpiredBefore: &filter.ExpiredBefore,
+	} {
+		if v := query.Get(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return filter, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %s query parameter", param)
+			}
+			*t = parsed
+		}
+	}
+	return filter, nil
+}
+
+// addOperationFilterToQuery encodes the filter as query parameters.
+func addOperationFilterToQuery(filter OperationFilter, q url.Values) url.Values {
+	if filter.Operation != "" {
+		q.Set(QueryOperation, filter.Operation)
+	}
+	for _, state := range filter.States {
+		q.Add(QueryState, string(state))
+	}
+	for k, v := range filter.Tags {
+		q.Add(QueryTag, k+":"+v)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		q.Set(QueryCreatedAfter, filter.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		q.Set(QueryCreatedBefore, filter.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	if !filter.ExpiredBefore.IsZero() {
+		q.Set(QueryExpiredBefore, filter.ExpiredBefore.Format(time.RFC3339Nano))
+	}
+	return q
+}
+
+func (h *httpHandler) listOperations(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter, err := operationFilterFromQuery(query)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	options := ListOperationsOptions{
+		Header:    httpHeaderToNexusHeader(request.Header),
+		PageToken: query.Get(QueryPageToken),
+		Filter:    filter,
+	}
+	if v := query.Get(QueryPageSize); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize < 0 {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %s query parameter", QueryPageSize))
+			return
+		}
+		options.PageSize = pageSize
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodListOperations,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	list, err := h.options.Handler.ListOperations(ctx, options)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	if list.Operations == nil {
+		list.Operations = []*OperationSummary{}
+	}
+	bytes, err := h.json.marshal(list)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal operation list: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// ListOperations lists operations matching the filter in options, newest first. Use the returned
+// [OperationList.NextPageToken] to fetch subsequent pages.
+func (c *Client) ListOperations(ctx context.Context, options ListOperationsOptions, opts ...CallOption) (*OperationList, error) {
+	ctx, c = c.withCallOptions(ctx, opts)
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL)
+	q := addOperationFilterToQuery(options.Filter, url.Values{})
+	if options.PageSize > 0 {
+		q.Set(QueryPageSize, strconv.Itoa(options.PageSize))
+	}
+	if options.PageToken != "" {
+		q.Set(QueryPageToken, options.PageToken)
+	}
+	u.RawQuery = q.Encode()
+	request, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, c.options.JSON)
+	}
+	var list OperationList
+	if err := c.options.JSON.unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// OperationIterator iterates over all operations matching a filter, fetching pages from the handler as needed.
+// Create one with [Client.IterateOperations].
+//
+//	it := client.IterateOperations(nexus.ListOperationsOptions{Filter: filter})
+//	for it.Next(ctx) {
+//		summary := it.Operation()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// An OperationIterator is not safe for concurrent use.
+type OperationIterator struct {
+	client  *Client
+	options ListOperationsOptions
+	opts    []CallOption
+	page    []*OperationSummary
+	current *OperationSummary
+	done    bool
+	err     error
+}
+
+// IterateOperations returns an [OperationIterator] over all operations matching the filter in options, newest first.
+// Pages of [ListOperationsOptions.PageSize] operations are fetched via [Client.ListOperations] as the iterator
+// advances, starting at [ListOperationsOptions.PageToken] if set.
+func (c *Client) IterateOperations(options ListOperationsOptions, opts ...CallOption) *OperationIterator {
+	return &OperationIterator{client: c, options: options, opts: opts}
+}
+
+// Next advances the iterator to the next operation, fetching the next page if the current one is exhausted. It returns
+// false when there are no more operations or fetching a page failed, see [OperationIterator.Err].
+func (it *OperationIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+		list, err := it.client.ListOperations(ctx, it.options, it.opts...)
+		if err != nil {
+			it.err = err
+			continue
+		}
+		it.page = list.Operations
+		it.options.PageToken = list.NextPageToken
+		it.done = list.NextPageToken == ""
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Operation returns the operation the iterator is positioned at by the last successful call to
+// [OperationIterator.Next].
+func (it *OperationIterator) Operation() *OperationSummary {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *OperationIterator) Err() error {
+	return it.err
+}
+
+// PageToken returns a token for resuming the iteration after the current page via
+// [ListOperationsOptions.PageToken]. Empty once the last page was fetched.
+func (it *OperationIterator) PageToken() string {
+	return it.options.PageToken
+}
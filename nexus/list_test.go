@@ -0,0 +1,162 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOperations(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		if operation == "fail" {
+			return nil, &HandlerError{HandlerErrorTypeInternal, &Failure{Message: "boom"}}
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	defer teardown()
+
+	var ids []string
+	for _, env := range []string{"prod", "staging", "prod"} {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: map[string]string{"Env": env}})
+		require.NoError(t, err)
+		ids = append(ids, result.Pending.ID)
+		// Ensure distinct creation times for deterministic ordering.
+		time.Sleep(time.Millisecond)
+	}
+	result, err := client.StartOperation(ctx, "fail", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.Error(t, err)
+
+	list, err := client.ListOperations(ctx, ListOperationsOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 4)
+	require.Equal(t, "fail", list.Operations[0].Operation)
+	require.Empty(t, list.NextPageToken)
+
+	list, err = client.ListOperations(ctx, ListOperationsOptions{
+		Filter: OperationFilter{Tags: map[string]string{"env": "prod"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 2)
+	require.Equal(t, ids[2], list.Operations[0].ID)
+	require.Equal(t, ids[0], list.Operations[1].ID)
+	require.Equal(t, map[string]string{"env": "prod"}, list.Operations[0].Tags)
+
+	list, err = client.ListOperations(ctx, ListOperationsOptions{
+		Filter: OperationFilter{States: []OperationState{OperationStateFailed}},
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 1)
+	require.Equal(t, "fail", list.Operations[0].Operation)
+
+	list, err = client.ListOperations(ctx, ListOperationsOptions{
+		Filter: OperationFilter{Operation: "foo", CreatedAfter: time.Now().Add(time.Minute)},
+	})
+	require.NoError(t, err)
+	require.Empty(t, list.Operations)
+}
+
+func TestListOperations_Pagination(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return "done", nil
+	})
+	defer teardown()
+
+	for i := 0; i < 5; i++ {
+		_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+		require.NoError(t, err)
+	}
+
+	seen := map[string]bool{}
+	options := ListOperationsOptions{PageSize: 2}
+	pages := 0
+	for {
+		list, err := client.ListOperations(ctx, options)
+		require.NoError(t, err)
+		pages++
+		for _, op := range list.Operations {
+			require.False(t, seen[op.ID])
+			seen[op.ID] = true
+		}
+		if list.NextPageToken == "" {
+			break
+		}
+		options.PageToken = list.NextPageToken
+	}
+	require.Equal(t, 3, pages)
+	require.Len(t, seen, 5)
+}
+
+func TestListOperations_NotImplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &unsuccessfulHandler{})
+	defer teardown()
+
+	_, err := client.ListOperations(ctx, ListOperationsOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 501, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestListOperations_InvalidQuery(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, nil
+	})
+	defer teardown()
+
+	_, err := client.ListOperations(ctx, ListOperationsOptions{PageToken: "not-a-token"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+}
+
+func TestIterateOperations(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		if operation == "bar" {
+			return "done", nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	defer teardown()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+		require.NoError(t, err)
+		ids = append(ids, result.Pending.ID)
+		// Ensure distinct creation times for deterministic ordering.
+		time.Sleep(time.Millisecond)
+	}
+	result, err := client.StartOperation(ctx, "bar", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+
+	it := client.IterateOperations(ListOperationsOptions{
+		PageSize: 2,
+		Filter:   OperationFilter{States: []OperationState{OperationStateRunning}},
+	})
+	var listed []string
+	for it.Next(ctx) {
+		require.Equal(t, OperationStateRunning, it.Operation().State)
+		listed = append(listed, it.Operation().ID)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []string{ids[4], ids[3], ids[2], ids[1], ids[0]}, listed)
+	require.Empty(t, it.PageToken())
+	require.False(t, it.Next(ctx))
+	require.Nil(t, it.Operation())
+}
+
+func TestIterateOperations_Error(t *testing.T) {
+	ctx, client, teardown := setup(t, &unsuccessfulHandler{})
+	defer teardown()
+
+	it := client.IterateOperations(ListOperationsOptions{})
+	require.False(t, it.Next(ctx))
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, it.Err(), &unexpectedResponseError)
+}
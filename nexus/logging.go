@@ -0,0 +1,144 @@
+package nexus
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A LoggerProvider provides the logger for a single request handled by the handlers returned from [NewHTTPHandler]
+// and [NewCompletionHTTPHandler], e.g. to attach correlation IDs extracted from request headers to every log line of
+// the request.
+type LoggerProvider interface {
+	// RequestLogger returns the logger for the given request. The given logger already carries the request scoped
+	// attributes attached by the handler, such as the operation and request ID.
+	RequestLogger(request *http.Request, logger *slog.Logger) *slog.Logger
+}
+
+// LoggerProviderFunc is a [LoggerProvider] backed by a function.
+type LoggerProviderFunc func(request *http.Request, logger *slog.Logger) *slog.Logger
+
+// RequestLogger implements LoggerProvider.
+func (f LoggerProviderFunc) RequestLogger(request *http.Request, logger *slog.Logger) *slog.Logger {
+	return f(request, logger)
+}
+
+// NewHeaderLoggerProvider returns a [LoggerProvider] that attaches the values of the given request headers, e.g.
+// "X-Correlation-Id" or "Traceparent", to the request logger. Each header is attached as an attribute named after
+// the lower cased header name. Headers missing from a request are omitted.
+func NewHeaderLoggerProvider(headers ...string) LoggerProvider {
+	return LoggerProviderFunc(func(request *http.Request, logger *slog.Logger) *slog.Logger {
+		var attrs []any
+		for _, header := range headers {
+			if v := request.Header.Get(header); v != "" {
+				attrs = append(attrs, strings.ToLower(header), v)
+			}
+		}
+		if len(attrs) == 0 {
+			return logger
+		}
+		return logger.With(attrs...)
+	})
+}
+
+// RequestLogVerbosity determines which requests a handler logs a summary line for, see
+// [HandlerOptions.RequestLogVerbosity].
+type RequestLogVerbosity int
+
+const (
+	// Log a summary line for failed requests only, i.e. requests responded to with a 4xx or 5xx status. The default.
+	RequestLogVerbosityFailures RequestLogVerbosity = iota
+	// Log a summary line for every request.
+	RequestLogVerbosityAll
+	// Don't log request summaries.
+	RequestLogVerbosityNone
+)
+
+// statusRecorder records the status code of a response for the request summary.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	// Error the request failed with, recorded by writeFailure.
+	err error
+	// ID of the operation started by the request, recorded for audit events.
+	operationID string
+}
+
+func (w *statusRecorder) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to [http.ResponseController].
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// startRequestLog replaces h's logger with a logger carrying the given request scoped attributes as well as the
+// request ID and remote address. It returns a writer recording the response status and a function to be called once
+// the request has been handled, which logs the request summary.
+func (h *baseHTTPHandler) startRequestLog(writer http.ResponseWriter, request *http.Request, attrs ...any) (http.ResponseWriter, func()) {
+	start := time.Now()
+	if requestID := request.Header.Get(HeaderRequestID); requestID != "" {
+		attrs = append(attrs, "requestID", requestID)
+	}
+	if caller := request.Header.Get(HeaderCallerIdentity); caller != "" {
+		attrs = append(attrs, "caller", caller)
+	}
+	if client := request.Header.Get(HeaderClientName); client != "" {
+		attrs = append(attrs, "clientName", client, "clientVersion", request.Header.Get(HeaderClientVersion))
+	}
+	attrs = append(attrs, "remoteAddr", request.RemoteAddr)
+	h.logger = h.logger.With(attrs...)
+	if h.loggerProvider != nil {
+		h.logger = h.loggerProvider.RequestLogger(request, h.logger)
+	}
+	recorder := &statusRecorder{ResponseWriter: writer}
+	return recorder, func() {
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelWarn
+		}
+		switch h.requestLogVerbosity {
+		case RequestLogVerbosityNone:
+			return
+		case RequestLogVerbosityFailures:
+			if status < http.StatusBadRequest {
+				return
+			}
+		}
+		h.logger.Log(request.Context(), level, "request handled", "status", status, "latency", time.Since(start))
+	}
+}
+
+// routeLogAttrs returns the request scoped log attributes of a request routed to the given method.
+func routeLogAttrs(method HandlerMethod, request *http.Request) []any {
+	attrs := []any{"method", method}
+	vars := routeVars(request)
+	if v, ok := vars["operation"]; ok {
+		if operation, err := url.PathUnescape(v); err == nil {
+			attrs = append(attrs, "operation", operation)
+		}
+	}
+	if v, ok := vars["operation_id"]; ok {
+		if operationID, err := url.PathUnescape(v); err == nil {
+			attrs = append(attrs, "operationID", operationID)
+		}
+	}
+	return attrs
+}
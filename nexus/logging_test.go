@@ -0,0 +1,104 @@
+package nexus
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// logLineWriter delivers the lines written by a JSON slog handler to a channel.
+type logLineWriter chan map[string]any
+
+func (w logLineWriter) Write(b []byte) (int, error) {
+	var line map[string]any
+	if err := json.Unmarshal(b, &line); err != nil {
+		return 0, err
+	}
+	w <- line
+	return len(b), nil
+}
+
+// nextRequestSummary returns the next request summary line written to w, skipping other lines.
+func nextRequestSummary(t *testing.T, w logLineWriter) map[string]any {
+	for {
+		select {
+		case line := <-w:
+			if line["msg"] == "request handled" {
+				return line
+			}
+		case <-time.After(testTimeout):
+			require.FailNow(t, "timed out waiting for request summary")
+		}
+	}
+}
+
+func TestRequestLogging_Summary(t *testing.T) {
+	lines := make(logLineWriter, 10)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:             &asyncHandler{},
+		Logger:              slog.New(slog.NewJSONHandler(lines, nil)),
+		LoggerProvider:      NewHeaderLoggerProvider("X-Correlation-Id"),
+		RequestLogVerbosity: RequestLogVerbosityAll,
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		RequestID: "req-1",
+		Header:    Header{"x-correlation-id": "abc"},
+	})
+	require.NoError(t, err)
+
+	line := nextRequestSummary(t, lines)
+	require.Equal(t, string(HandlerMethodStartOperation), line["method"])
+	require.Equal(t, "foo", line["operation"])
+	require.Equal(t, "req-1", line["requestID"])
+	require.Equal(t, "abc", line["x-correlation-id"])
+	require.Equal(t, float64(http.StatusCreated), line["status"])
+	require.Equal(t, "INFO", line["level"])
+	require.NotEmpty(t, line["remoteAddr"])
+	require.Contains(t, line, "latency")
+}
+
+func TestRequestLogging_FailuresOnly(t *testing.T) {
+	lines := make(logLineWriter, 10)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &asyncHandler{},
+		Logger:  slog.New(slog.NewJSONHandler(lines, nil)),
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle, err := client.NewHandle("foo", "a/b")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+
+	line := nextRequestSummary(t, lines)
+	require.Equal(t, string(HandlerMethodGetOperationInfo), line["method"])
+	require.Equal(t, "a/b", line["operationID"])
+	require.Equal(t, float64(http.StatusNotImplemented), line["status"])
+	require.Equal(t, "WARN", line["level"])
+}
+
+func TestRequestLogging_CompletionHandler(t *testing.T) {
+	lines := make(logLineWriter, 10)
+	h := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler: &successfulCompletionHandler{},
+		Logger:  slog.New(slog.NewJSONHandler(lines, nil)),
+	})
+	request := httptest.NewRequest("POST", "http://localhost/callback", nil)
+	request.Header.Set(HeaderOperationState, "invalid")
+	writer := httptest.NewRecorder()
+	h.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	line := nextRequestSummary(t, lines)
+	require.Equal(t, "invalid", line["state"])
+	require.Equal(t, float64(http.StatusBadRequest), line["status"])
+}
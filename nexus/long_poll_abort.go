@@ -0,0 +1,50 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrLongPollAborted is returned by [OperationHandle.GetResult] when its long poll was aborted by a cancel request on
+// the same handle, see [CancelOperationOptions.AbortLongPolls].
+var ErrLongPollAborted = errors.New("long poll aborted by cancel request")
+
+// longPolls tracks the in-flight long polls for the result of an operation, shared by a handle and the handles derived
+// from it, so that they can be aborted when the operation is canceled.
+type longPolls struct {
+	mu      sync.Mutex
+	next    int
+	cancels map[int]context.CancelCauseFunc
+}
+
+func newLongPolls() *longPolls {
+	return &longPolls{cancels: make(map[int]context.CancelCauseFunc)}
+}
+
+// track returns a context for a long poll that is canceled with [ErrLongPollAborted] when the polls are aborted, a
+// function to stop tracking it, and a function to release the context once the poll's response is consumed.
+func (p *longPolls) track(ctx context.Context) (context.Context, func(), context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p.mu.Lock()
+	id := p.next
+	p.next++
+	p.cancels[id] = cancel
+	p.mu.Unlock()
+	untrack := func() {
+		p.mu.Lock()
+		delete(p.cancels, id)
+		p.mu.Unlock()
+	}
+	return ctx, untrack, func() { cancel(nil) }
+}
+
+// abort aborts all tracked long polls.
+func (p *longPolls) abort() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, cancel := range p.cancels {
+		cancel(ErrLongPollAborted)
+		delete(p.cancels, id)
+	}
+}
@@ -0,0 +1,63 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pollingResultHandler struct {
+	asyncWithCancelHandler
+	polling chan struct{}
+}
+
+func (h *pollingResultHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	h.polling <- struct{}{}
+	<-ctx.Done()
+	return nil, ErrOperationStillRunning
+}
+
+func TestCancel_AbortLongPolls(t *testing.T) {
+	handler := &pollingResultHandler{polling: make(chan struct{}, 1)}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "f/o/o", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+
+	getResult := func() chan error {
+		errs := make(chan error, 1)
+		go func() {
+			// Derived handles share the tracked polls.
+			_, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute}, WithHeader(Header{"poll": "true"}))
+			errs <- err
+		}()
+		<-handler.polling
+		return errs
+	}
+
+	// Polls are not aborted by default.
+	errs := getResult()
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	select {
+	case err := <-errs:
+		t.Fatalf("long poll returned: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{AbortLongPolls: true}))
+	require.ErrorIs(t, <-errs, ErrLongPollAborted)
+
+	// Only in-flight polls are aborted.
+	errs = getResult()
+	select {
+	case err := <-errs:
+		t.Fatalf("long poll returned: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{AbortLongPolls: true}))
+	require.ErrorIs(t, <-errs, ErrLongPollAborted)
+}
@@ -0,0 +1,192 @@
+package nexus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const mediaTypeMultipartRelated = "multipart/related"
+
+// A Part is a named payload of a [Multipart] value.
+type Part struct {
+	// Name identifying the part, sent as its Content-ID.
+	Name string
+	// Value of the part, serialized with the sender's [Serializer]. [Reader] values are streamed and [Content] values
+	// are sent as is.
+	Value any
+}
+
+// Multipart is a value consisting of multiple named payloads, e.g. metadata and a large binary attachment, sent as a
+// single multipart/related body. It can be used as the input of start requests and as the result of operations.
+//
+// Parts are serialized individually with the sender's [Serializer]. Parts with [Reader] values are streamed without
+// buffering them in memory, except when the value is stored, e.g. as the result of an [AsyncHandler] operation.
+// Receivers read parts in order via [LazyValue.Parts].
+type Multipart []Part
+
+// Reader serializes the parts of m with the given serializer, returning a [Reader] streaming the multipart body.
+// Readers of parts are closed once they were streamed or when the returned Reader is closed.
+func (m Multipart) Reader(serializer Serializer) (*Reader, error) {
+	if serializer == nil {
+		serializer = defaultSerializer
+	}
+	// Serialize eagerly to surface serialization errors to the caller.
+	readers := make([]*Reader, len(m))
+	closeAll := func() {
+		for _, reader := range readers {
+			if reader != nil && reader.ReadCloser != nil {
+				reader.Close()
+			}
+		}
+	}
+	for i, part := range m {
+		if part.Name == "" {
+			closeAll()
+			return nil, fmt.Errorf("part %d has an empty name", i)
+		}
+		reader, err := partReader(part.Value, serializer)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to serialize part %q: %w", part.Name, err)
+		}
+		readers[i] = reader
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		defer closeAll()
+		for i, reader := range readers {
+			header := textproto.MIMEHeader(addContentHeaderToHTTPHeader(reader.Header, make(http.Header)))
+			header.Set("Content-Id", "<"+m[i].Name+">")
+			w, err := writer.CreatePart(header)
+			if err == nil && reader.ReadCloser != nil {
+				_, err = io.Copy(w, reader)
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(writer.Close())
+	}()
+	return &Reader{
+		&multipartBodyReader{pr},
+		Header{"type": mime.FormatMediaType(mediaTypeMultipartRelated, map[string]string{"boundary": writer.Boundary()})},
+	}, nil
+}
+
+// Content serializes the parts of m with the given serializer into memory.
+func (m Multipart) Content(serializer Serializer) (*Content, error) {
+	reader, err := m.Reader(serializer)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := readAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{Header: reader.Header, Data: data}, nil
+}
+
+// multipartBodyReader closes the pipe a multipart body is written to when closed, aborting the writer.
+type multipartBodyReader struct {
+	*io.PipeReader
+}
+
+func (r *multipartBodyReader) Close() error {
+	return r.PipeReader.CloseWithError(errors.New("multipart body closed"))
+}
+
+// partReader returns a [Reader] for the value of a part.
+func partReader(value any, serializer Serializer) (*Reader, error) {
+	if reader, ok := value.(*Reader); ok {
+		return reader, nil
+	}
+	content, ok := value.(*Content)
+	if !ok {
+		var err error
+		if content, err = serializer.Serialize(value); err != nil {
+			return nil, err
+		}
+	}
+	header := maps.Clone(content.Header)
+	if header == nil {
+		header = Header{}
+	}
+	header["length"] = strconv.Itoa(len(content.Data))
+	return &Reader{io.NopCloser(bytes.NewReader(content.Data)), header}, nil
+}
+
+// multipartValue converts [Multipart] values to a [Reader] streaming their body, returning other values unchanged.
+func multipartValue(value any, serializer Serializer) (any, error) {
+	if m, ok := value.(Multipart); ok {
+		return m.Reader(serializer)
+	}
+	return value, nil
+}
+
+// A PartReader reads the parts of a multipart value, see [Multipart].
+type PartReader struct {
+	reader     *Reader
+	multipart  *multipart.Reader
+	serializer Serializer
+}
+
+// NewPartReader creates a [PartReader] reading the parts of a multipart/related [Reader], deserializing part values
+// with the given serializer.
+func NewPartReader(reader *Reader, serializer Serializer) (*PartReader, error) {
+	mediaType, params, err := mime.ParseMediaType(reader.Header["type"])
+	if err != nil || mediaType != mediaTypeMultipartRelated || params["boundary"] == "" {
+		return nil, fmt.Errorf("cannot read parts of non multipart content: %q", reader.Header["type"])
+	}
+	if serializer == nil {
+		serializer = defaultSerializer
+	}
+	return &PartReader{
+		reader:     reader,
+		multipart:  multipart.NewReader(reader, params["boundary"]),
+		serializer: serializer,
+	}, nil
+}
+
+// Parts returns a [PartReader] for reading the parts of a lazy value holding a [Multipart] value. Closing the part
+// reader closes the lazy value's underlying [Reader].
+func (l *LazyValue) Parts() (*PartReader, error) {
+	return NewPartReader(l.Reader, l.serializer)
+}
+
+// Next returns the name and value of the next part, or [io.EOF] when there are no more parts. The value must be
+// consumed, or read from, before calling Next again, it is invalid afterwards.
+func (r *PartReader) Next() (string, *LazyValue, error) {
+	part, err := r.multipart.NextRawPart()
+	if err != nil {
+		return "", nil, err
+	}
+	header := prefixStrippedHTTPHeaderToNexusHeader(http.Header(part.Header), "content-")
+	delete(header, "id")
+	name := strings.TrimSuffix(strings.TrimPrefix(part.Header.Get("Content-Id"), "<"), ">")
+	return name, &LazyValue{
+		serializer: r.serializer,
+		// Closing a part must not close the underlying reader.
+		Reader: &Reader{verifyingReader(io.NopCloser(part), header), header},
+	}, nil
+}
+
+// Close closes the underlying [Reader].
+func (r *PartReader) Close() error {
+	if r.reader.ReadCloser == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
@@ -0,0 +1,127 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type attachmentMetadata struct {
+	FileName string
+}
+
+// multipartEchoHandler reads the parts of its input and responds with them in reverse order.
+type multipartEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *multipartEchoHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	parts, err := input.Parts()
+	if err != nil {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "%v", err)
+	}
+	defer parts.Close()
+	var result Multipart
+	for {
+		name, value, err := parts.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "metadata":
+			var metadata attachmentMetadata
+			if err := value.Consume(&metadata); err != nil {
+				return nil, err
+			}
+			result = append(Multipart{{Name: name, Value: metadata}}, result...)
+		default:
+			var data []byte
+			if err := value.Consume(&data); err != nil {
+				return nil, err
+			}
+			result = append(Multipart{{Name: name, Value: data}}, result...)
+		}
+	}
+	return &HandlerStartOperationResultSync[any]{Value: result}, nil
+}
+
+func TestMultipart(t *testing.T) {
+	ctx, client, teardown := setup(t, &multipartEchoHandler{})
+	defer teardown()
+
+	attachment := &Reader{
+		io.NopCloser(strings.NewReader("large binary attachment")),
+		Header{"type": "application/octet-stream"},
+	}
+	result, err := client.StartOperation(ctx, "upload", Multipart{
+		{Name: "metadata", Value: attachmentMetadata{FileName: "a.bin"}},
+		{Name: "attachment", Value: attachment},
+	}, StartOperationOptions{})
+	require.NoError(t, err)
+
+	parts, err := result.Successful.Parts()
+	require.NoError(t, err)
+	defer parts.Close()
+
+	name, value, err := parts.Next()
+	require.NoError(t, err)
+	require.Equal(t, "attachment", name)
+	require.Equal(t, "application/octet-stream", value.Reader.Header["type"])
+	var data []byte
+	require.NoError(t, value.Consume(&data))
+	require.Equal(t, "large binary attachment", string(data))
+
+	name, value, err = parts.Next()
+	require.NoError(t, err)
+	require.Equal(t, "metadata", name)
+	var metadata attachmentMetadata
+	require.NoError(t, value.Consume(&metadata))
+	require.Equal(t, attachmentMetadata{FileName: "a.bin"}, metadata)
+
+	_, _, err = parts.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestMultipart_AsyncResult(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return Multipart{{Name: "report", Value: "done"}, {Name: "raw", Value: []byte{1, 2, 3}}}, nil
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	parts, err := value.Parts()
+	require.NoError(t, err)
+	defer parts.Close()
+
+	name, part, err := parts.Next()
+	require.NoError(t, err)
+	require.Equal(t, "report", name)
+	var report string
+	require.NoError(t, part.Consume(&report))
+	require.Equal(t, "done", report)
+
+	name, part, err = parts.Next()
+	require.NoError(t, err)
+	require.Equal(t, "raw", name)
+	var raw []byte
+	require.NoError(t, part.Consume(&raw))
+	require.Equal(t, []byte{1, 2, 3}, raw)
+}
+
+func TestMultipart_Invalid(t *testing.T) {
+	_, err := Multipart{{Value: "unnamed"}}.Reader(nil)
+	require.ErrorContains(t, err, "part 0 has an empty name")
+
+	_, err = NewPartReader(&Reader{Header: Header{"type": "application/json"}}, nil)
+	require.ErrorContains(t, err, "cannot read parts of non multipart content")
+}
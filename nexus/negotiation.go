@@ -0,0 +1,247 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errNotAcceptable is returned by handlers when the content type of a result does not match the Accept header of the
+// request, failing the request with a 406 status code.
+var errNotAcceptable = errors.New("not acceptable")
+
+// A NegotiatingSerializer is a [Serializer] supporting multiple media types, used to negotiate the content type of
+// results between clients and handlers.
+//
+// Clients configured with a NegotiatingSerializer send its media types in the Accept header of start operation and get
+// result requests. Handlers configured with one serialize results as the media type most preferred by the caller. This
+// allows migrating a fleet from one serialization format to another, e.g. from JSON to protobuf, with callers and
+// handlers upgraded independently.
+//
+// Regardless of their serializer, handlers fail requests whose Accept header does not match the content type of the
+// result with a 406 (Not Acceptable) status code. Requests without an Accept header accept any content type.
+type NegotiatingSerializer interface {
+	Serializer
+	// MediaTypes returns the media types the serializer supports, in order of preference.
+	MediaTypes() []string
+	// SerializeAs encodes a value into a [Content] of the given media type, one of those returned by MediaTypes.
+	SerializeAs(v any, mediaType string) (*Content, error)
+}
+
+// MediaTypeSerializer pairs a media type with the [Serializer] used for it, see [NewNegotiatingSerializer].
+type MediaTypeSerializer struct {
+	// Media type, e.g. "application/json".
+	MediaType string
+	// Serializer producing and consuming content of the media type. Defaults to the SDK's default serializer.
+	Serializer Serializer
+}
+
+type negotiatingSerializer []MediaTypeSerializer
+
+// NewNegotiatingSerializer creates a [NegotiatingSerializer] from serializers for individual media types, given in
+// order of preference.
+//
+// Values are serialized with the most preferred serializer that succeeds unless a media type was negotiated. Content
+// is deserialized with the serializer for its media type, falling back to the default serializer. Nil values are
+// always serialized as empty content.
+func NewNegotiatingSerializer(serializers ...MediaTypeSerializer) NegotiatingSerializer {
+	s := make(negotiatingSerializer, len(serializers))
+	for i, serializer := range serializers {
+		if serializer.Serializer == nil {
+			serializer.Serializer = defaultSerializer
+		}
+		s[i] = serializer
+	}
+	return s
+}
+
+func (s negotiatingSerializer) MediaTypes() []string {
+	mediaTypes := make([]string, len(s))
+	for i, serializer := range s {
+		mediaTypes[i] = serializer.MediaType
+	}
+	return mediaTypes
+}
+
+func (s negotiatingSerializer) Serialize(v any) (*Content, error) {
+	if content, err := (nilSerializer{}).Serialize(v); err == nil {
+		return content, nil
+	}
+	var firstErr error
+	for _, serializer := range s {
+		content, err := serializer.Serializer.Serialize(v)
+		if err == nil {
+			return content, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return defaultSerializer.Serialize(v)
+	}
+	return nil, firstErr
+}
+
+func (s negotiatingSerializer) SerializeAs(v any, mediaType string) (*Content, error) {
+	if content, err := (nilSerializer{}).Serialize(v); err == nil {
+		return content, nil
+	}
+	for _, serializer := range s {
+		if !mediaTypeEqual(serializer.MediaType, mediaType) {
+			continue
+		}
+		content, err := serializer.Serializer.Serialize(v)
+		if err != nil {
+			return nil, err
+		}
+		if !mediaTypeEqual(content.Header["type"], mediaType) {
+			return nil, fmt.Errorf("serializer for %q produced content of type %q", mediaType, content.Header["type"])
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("unsupported media type: %q", mediaType)
+}
+
+func (s negotiatingSerializer) Deserialize(content *Content, v any) error {
+	for _, serializer := range s {
+		if mediaTypeEqual(serializer.MediaType, content.Header["type"]) {
+			return serializer.Serializer.Deserialize(content, v)
+		}
+	}
+	return defaultSerializer.Deserialize(content, v)
+}
+
+var _ NegotiatingSerializer = negotiatingSerializer{}
+
+// mediaTypeEqual returns true if the media types of the given content types are equal, ignoring parameters.
+func mediaTypeEqual(a, b string) bool {
+	a, _, errA := mime.ParseMediaType(a)
+	b, _, errB := mime.ParseMediaType(b)
+	return errA == nil && errB == nil && a == b
+}
+
+// acceptHeaderValue formats the media types of a [NegotiatingSerializer] as an Accept header value, with quality
+// values decreasing in order of preference. Returns an empty string for other serializers.
+func acceptHeaderValue(serializer Serializer) string {
+	negotiating, ok := serializer.(NegotiatingSerializer)
+	if !ok {
+		return ""
+	}
+	var ranges []string
+	for i, mediaType := range negotiating.MediaTypes() {
+		q := 10 - i
+		if q < 1 {
+			q = 1
+		}
+		if q == 10 {
+			ranges = append(ranges, mediaType)
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%s;q=0.%d", mediaType, q))
+		}
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// setAcceptHTTPHeader sets the Accept header of a request from the given serializer unless already set.
+func setAcceptHTTPHeader(serializer Serializer, header http.Header) {
+	if header.Get("Accept") != "" {
+		return
+	}
+	if accept := acceptHeaderValue(serializer); accept != "" {
+		header.Set("Accept", accept)
+	}
+}
+
+// acceptRange is a media range of an Accept header, e.g. "application/*".
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func (r acceptRange) matches(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if r.mediaType == "*/*" || r.mediaType == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(r.mediaType, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// parseAccept parses an Accept header value into its media ranges, most preferred first. Ranges with a quality value
+// of zero, which are explicitly not acceptable, and invalid ranges are omitted.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || !strings.Contains(mediaType, "/") {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType, q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// acceptable returns true if content of the given type may be sent in response to a request with the given Accept
+// header. Empty and untyped content is always acceptable.
+func acceptable(accept, contentType string) bool {
+	if accept == "" || contentType == "" {
+		return true
+	}
+	for _, r := range parseAccept(accept) {
+		if r.matches(contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// serializeAcceptable serializes v as the media type of a [NegotiatingSerializer] most preferred by the given Accept
+// header, trying less preferred media types if serialization fails. Other serializers, requests without an Accept
+// header, and requests accepting none of the serializer's media types serialize v as usual.
+func serializeAcceptable(serializer Serializer, v any, accept string) (*Content, error) {
+	negotiating, ok := serializer.(NegotiatingSerializer)
+	if !ok || accept == "" {
+		return serializer.Serialize(v)
+	}
+	mediaTypes := negotiating.MediaTypes()
+	var firstErr error
+	for _, r := range parseAccept(accept) {
+		for _, mediaType := range mediaTypes {
+			if !r.matches(mediaType) {
+				continue
+			}
+			content, err := negotiating.SerializeAs(v, mediaType)
+			if err == nil {
+				return content, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// None of the serializer's media types are accepted, the caller decides whether the content is acceptable.
+	return serializer.Serialize(v)
+}
@@ -0,0 +1,154 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// textSerializer serializes strings as text/plain.
+type textSerializer struct{}
+
+func (textSerializer) Serialize(v any) (*Content, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errSerializerIncompatible
+	}
+	return &Content{Header: Header{"type": "text/plain"}, Data: []byte(s)}, nil
+}
+
+func (textSerializer) Deserialize(c *Content, v any) error {
+	s, ok := v.(*string)
+	if !ok {
+		return errSerializerIncompatible
+	}
+	*s = string(c.Data)
+	return nil
+}
+
+// valueHandler responds to start requests with its value.
+type valueHandler struct {
+	UnimplementedHandler
+	value any
+}
+
+func (h *valueHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: h.value}, nil
+}
+
+var textAndJSONSerializer = NewNegotiatingSerializer(
+	MediaTypeSerializer{MediaType: "text/plain", Serializer: textSerializer{}},
+	MediaTypeSerializer{MediaType: "application/json"},
+)
+
+func TestNegotiation(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:    &valueHandler{value: "hello"},
+		Serializer: textAndJSONSerializer,
+	}, ClientOptions{
+		Serializer: NewNegotiatingSerializer(MediaTypeSerializer{MediaType: "application/json"}),
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "application/json", result.Successful.Reader.Header["type"])
+	var value string
+	require.NoError(t, result.Successful.Consume(&value))
+	require.Equal(t, "hello", value)
+
+	clone, err := client.Clone(ClientOverrides{Serializer: textAndJSONSerializer})
+	require.NoError(t, err)
+	result, err = clone.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", result.Successful.Reader.Header["type"])
+	require.NoError(t, result.Successful.Consume(&value))
+	require.Equal(t, "hello", value)
+
+	// Explicitly set headers take precedence.
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{Header: Header{"accept": "application/xml"}})
+	var unexpectedResponseErr *UnexpectedResponseError
+	require.True(t, errors.As(err, &unexpectedResponseErr))
+	require.Equal(t, http.StatusNotAcceptable, unexpectedResponseErr.Response.StatusCode)
+}
+
+func TestNegotiation_NonNegotiatingHandler(t *testing.T) {
+	handler := &valueHandler{value: "hello"}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{
+		Serializer: NewNegotiatingSerializer(MediaTypeSerializer{MediaType: "text/plain", Serializer: textSerializer{}}),
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unexpectedResponseErr *UnexpectedResponseError
+	require.True(t, errors.As(err, &unexpectedResponseErr))
+	require.Equal(t, http.StatusNotAcceptable, unexpectedResponseErr.Response.StatusCode)
+
+	// Empty results are always acceptable.
+	handler.value = nil
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var value any
+	require.NoError(t, result.Successful.Consume(&value))
+	require.Nil(t, value)
+}
+
+func TestNegotiatingSerializer(t *testing.T) {
+	require.Equal(t, []string{"text/plain", "application/json"}, textAndJSONSerializer.MediaTypes())
+
+	c, err := textAndJSONSerializer.Serialize("hello")
+	require.NoError(t, err)
+	require.Equal(t, Header{"type": "text/plain"}, c.Header)
+	c, err = textAndJSONSerializer.Serialize(1)
+	require.NoError(t, err)
+	require.Equal(t, Header{"type": "application/json"}, c.Header)
+	c, err = textAndJSONSerializer.Serialize(nil)
+	require.NoError(t, err)
+	require.Equal(t, Header{}, c.Header)
+
+	c, err = textAndJSONSerializer.SerializeAs("hello", "application/json")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`"hello"`), c.Data)
+	_, err = textAndJSONSerializer.SerializeAs(1, "text/plain")
+	require.ErrorIs(t, err, errSerializerIncompatible)
+	_, err = textAndJSONSerializer.SerializeAs("hello", "application/xml")
+	require.ErrorContains(t, err, `unsupported media type: "application/xml"`)
+
+	var s string
+	require.NoError(t, textAndJSONSerializer.Deserialize(&Content{Header: Header{"type": "text/plain; charset=utf-8"}, Data: []byte("hi")}, &s))
+	require.Equal(t, "hi", s)
+}
+
+func TestAcceptHeaderValue(t *testing.T) {
+	require.Equal(t, "", acceptHeaderValue(defaultSerializer))
+	require.Equal(t, "text/plain, application/json;q=0.9", acceptHeaderValue(textAndJSONSerializer))
+}
+
+func TestParseAccept(t *testing.T) {
+	require.Equal(t, []acceptRange{
+		{"application/json", 1},
+		{"text/*", 0.5},
+		{"*/*", 0.1},
+	}, parseAccept("*/*;q=0.1, text/*;q=0.5, application/json, application/xml;q=0, invalid"))
+
+	require.True(t, acceptable("", "application/json"))
+	require.True(t, acceptable("application/json", ""))
+	require.True(t, acceptable("text/*", "text/plain; charset=utf-8"))
+	require.True(t, acceptable("*/*", "application/json"))
+	require.False(t, acceptable("text/*, application/json;q=0", "application/json"))
+}
+
+func TestSerializeAcceptable(t *testing.T) {
+	content, err := serializeAcceptable(textAndJSONSerializer, 1, "text/plain, application/json;q=0.5")
+	require.NoError(t, err)
+	require.Equal(t, Header{"type": "application/json"}, content.Header)
+	content, err = serializeAcceptable(textAndJSONSerializer, "hello", "*/*")
+	require.NoError(t, err)
+	require.Equal(t, Header{"type": "text/plain"}, content.Header)
+	content, err = serializeAcceptable(textAndJSONSerializer, "hello", "application/xml")
+	require.NoError(t, err)
+	require.False(t, acceptable("application/xml", content.Header["type"]))
+}
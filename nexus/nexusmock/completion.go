@@ -0,0 +1,42 @@
+package nexusmock
+
+import (
+	"context"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// CompletionHandler is a mock [nexus.CompletionHandler].
+type CompletionHandler struct {
+	m *mock
+}
+
+// NewCompletionHandler creates a [CompletionHandler] mock without expectations, reporting unexpected calls and unmet
+// expectations to t.
+func NewCompletionHandler(t TestingT) *CompletionHandler {
+	return &CompletionHandler{m: newMock(t, "CompletionHandler")}
+}
+
+var _ nexus.CompletionHandler = &CompletionHandler{}
+
+// CompleteOperationFunc is the signature of [nexus.CompletionHandler.CompleteOperation].
+type CompleteOperationFunc func(ctx context.Context, request *nexus.CompletionRequest) error
+
+// ExpectCompleteOperation expects a call to CompleteOperation for the given operation ID. An empty ID matches any
+// completion.
+func (h *CompletionHandler) ExpectCompleteOperation(operationID string) *ErrCall[CompleteOperationFunc] {
+	return &ErrCall[CompleteOperationFunc]{h.m, h.m.expect("CompleteOperation", operationID)}
+}
+
+// CompleteOperation implements nexus.CompletionHandler.
+func (h *CompletionHandler) CompleteOperation(ctx context.Context, request *nexus.CompletionRequest) error {
+	e, err := h.m.call("CompleteOperation", request.OperationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](h.m, e)
+	if fn, ok := do.(CompleteOperationFunc); ok {
+		return fn(ctx, request)
+	}
+	return err
+}
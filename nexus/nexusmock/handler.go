@@ -0,0 +1,293 @@
+package nexusmock
+
+import (
+	"context"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Handler is a mock [nexus.Handler].
+type Handler struct {
+	nexus.UnimplementedHandler
+	m *mock
+}
+
+// NewHandler creates a [Handler] mock without expectations, reporting unexpected calls and unmet expectations to t.
+func NewHandler(t TestingT) *Handler {
+	return &Handler{m: newMock(t, "Handler")}
+}
+
+var _ nexus.Handler = &Handler{}
+
+// StartOperationFunc is the signature of [nexus.Handler.StartOperation].
+type StartOperationFunc func(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error)
+
+// GetOperationResultFunc is the signature of [nexus.Handler.GetOperationResult].
+type GetOperationResultFunc func(ctx context.Context, operation string, operationID string, options nexus.GetOperationResultOptions) (any, error)
+
+// GetOperationInfoFunc is the signature of [nexus.Handler.GetOperationInfo].
+type GetOperationInfoFunc func(ctx context.Context, operation string, operationID string, options nexus.GetOperationInfoOptions) (*nexus.OperationInfo, error)
+
+// CancelOperationFunc is the signature of [nexus.Handler.CancelOperation].
+type CancelOperationFunc func(ctx context.Context, operation string, operationID string, options nexus.CancelOperationOptions) error
+
+// WatchOperationFunc is the signature of [nexus.Handler.WatchOperation].
+type WatchOperationFunc func(ctx context.Context, operation string, operationID string, options nexus.WatchOperationOptions) (<-chan *nexus.OperationInfo, error)
+
+// ClaimOperationResultFunc is the signature of [nexus.Handler.ClaimOperationResult].
+type ClaimOperationResultFunc func(ctx context.Context, operation string, operationID string, options nexus.ClaimOperationResultOptions) (*nexus.HandlerResultClaim, error)
+
+// AckOperationResultFunc is the signature of [nexus.Handler.AckOperationResult].
+type AckOperationResultFunc func(ctx context.Context, operation string, operationID string, token string, options nexus.AckOperationResultOptions) error
+
+// ListOperationsFunc is the signature of [nexus.Handler.ListOperations].
+type ListOperationsFunc func(ctx context.Context, options nexus.ListOperationsOptions) (*nexus.OperationList, error)
+
+// CancelMatchingOperationsFunc is the signature of [nexus.Handler.CancelMatchingOperations].
+type CancelMatchingOperationsFunc func(ctx context.Context, options nexus.CancelMatchingOperationsOptions) (<-chan *nexus.BatchProgress, error)
+
+// GetQuotaUsageFunc is the signature of [nexus.Handler.GetQuotaUsage].
+type GetQuotaUsageFunc func(ctx context.Context, subject string, options nexus.GetQuotaUsageOptions) (*nexus.QuotaStatus, error)
+
+// HeartbeatOperationFunc is the signature of [nexus.Handler.HeartbeatOperation].
+type HeartbeatOperationFunc func(ctx context.Context, operation string, operationID string, options nexus.HeartbeatOperationOptions) (*nexus.OperationInfo, error)
+
+// ResolveQuarantineFunc is the signature of [nexus.Handler.ResolveQuarantine].
+type ResolveQuarantineFunc func(ctx context.Context, operation string, operationID string, options nexus.ResolveQuarantineOptions) error
+
+// ForceCompleteOperationFunc is the signature of [nexus.Handler.ForceCompleteOperation].
+type ForceCompleteOperationFunc func(ctx context.Context, operation string, operationID string, options nexus.ForceCompleteOperationOptions) error
+
+// ExpectStartOperation expects a call to StartOperation. Empty arguments match any value.
+func (h *Handler) ExpectStartOperation(operation string) *Call[StartOperationFunc, nexus.HandlerStartOperationResult[any]] {
+	return &Call[StartOperationFunc, nexus.HandlerStartOperationResult[any]]{h.m, h.m.expect("StartOperation", operation)}
+}
+
+// StartOperation implements nexus.Handler.
+func (h *Handler) StartOperation(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error) {
+	e, err := h.m.call("StartOperation", operation)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[nexus.HandlerStartOperationResult[any]](h.m, e)
+	if fn, ok := do.(StartOperationFunc); ok {
+		return fn(ctx, operation, input, options)
+	}
+	return result, err
+}
+
+// ExpectGetOperationResult expects a call to GetOperationResult. Empty arguments match any value.
+func (h *Handler) ExpectGetOperationResult(operation, operationID string) *Call[GetOperationResultFunc, any] {
+	return &Call[GetOperationResultFunc, any]{h.m, h.m.expect("GetOperationResult", operation, operationID)}
+}
+
+// GetOperationResult implements nexus.Handler.
+func (h *Handler) GetOperationResult(ctx context.Context, operation string, operationID string, options nexus.GetOperationResultOptions) (any, error) {
+	e, err := h.m.call("GetOperationResult", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[any](h.m, e)
+	if fn, ok := do.(GetOperationResultFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return result, err
+}
+
+// ExpectGetOperationInfo expects a call to GetOperationInfo. Empty arguments match any value.
+func (h *Handler) ExpectGetOperationInfo(operation, operationID string) *Call[GetOperationInfoFunc, *nexus.OperationInfo] {
+	return &Call[GetOperationInfoFunc, *nexus.OperationInfo]{h.m, h.m.expect("GetOperationInfo", operation, operationID)}
+}
+
+// GetOperationInfo implements nexus.Handler.
+func (h *Handler) GetOperationInfo(ctx context.Context, operation string, operationID string, options nexus.GetOperationInfoOptions) (*nexus.OperationInfo, error) {
+	e, err := h.m.call("GetOperationInfo", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.OperationInfo](h.m, e)
+	if fn, ok := do.(GetOperationInfoFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return result, err
+}
+
+// ExpectCancelOperation expects a call to CancelOperation. Empty arguments match any value.
+func (h *Handler) ExpectCancelOperation(operation, operationID string) *ErrCall[CancelOperationFunc] {
+	return &ErrCall[CancelOperationFunc]{h.m, h.m.expect("CancelOperation", operation, operationID)}
+}
+
+// CancelOperation implements nexus.Handler.
+func (h *Handler) CancelOperation(ctx context.Context, operation string, operationID string, options nexus.CancelOperationOptions) error {
+	e, err := h.m.call("CancelOperation", operation, operationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](h.m, e)
+	if fn, ok := do.(CancelOperationFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return err
+}
+
+// ExpectWatchOperation expects a call to WatchOperation. Empty arguments match any value.
+func (h *Handler) ExpectWatchOperation(operation, operationID string) *Call[WatchOperationFunc, <-chan *nexus.OperationInfo] {
+	return &Call[WatchOperationFunc, <-chan *nexus.OperationInfo]{h.m, h.m.expect("WatchOperation", operation, operationID)}
+}
+
+// WatchOperation implements nexus.Handler.
+func (h *Handler) WatchOperation(ctx context.Context, operation string, operationID string, options nexus.WatchOperationOptions) (<-chan *nexus.OperationInfo, error) {
+	e, err := h.m.call("WatchOperation", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[<-chan *nexus.OperationInfo](h.m, e)
+	if fn, ok := do.(WatchOperationFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return result, err
+}
+
+// ExpectClaimOperationResult expects a call to ClaimOperationResult. Empty arguments match any value.
+func (h *Handler) ExpectClaimOperationResult(operation, operationID string) *Call[ClaimOperationResultFunc, *nexus.HandlerResultClaim] {
+	return &Call[ClaimOperationResultFunc, *nexus.HandlerResultClaim]{h.m, h.m.expect("ClaimOperationResult", operation, operationID)}
+}
+
+// ClaimOperationResult implements nexus.Handler.
+func (h *Handler) ClaimOperationResult(ctx context.Context, operation string, operationID string, options nexus.ClaimOperationResultOptions) (*nexus.HandlerResultClaim, error) {
+	e, err := h.m.call("ClaimOperationResult", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.HandlerResultClaim](h.m, e)
+	if fn, ok := do.(ClaimOperationResultFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return result, err
+}
+
+// ExpectAckOperationResult expects a call to AckOperationResult. Empty arguments match any value.
+func (h *Handler) ExpectAckOperationResult(operation, operationID string) *ErrCall[AckOperationResultFunc] {
+	return &ErrCall[AckOperationResultFunc]{h.m, h.m.expect("AckOperationResult", operation, operationID)}
+}
+
+// AckOperationResult implements nexus.Handler.
+func (h *Handler) AckOperationResult(ctx context.Context, operation string, operationID string, token string, options nexus.AckOperationResultOptions) error {
+	e, err := h.m.call("AckOperationResult", operation, operationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](h.m, e)
+	if fn, ok := do.(AckOperationResultFunc); ok {
+		return fn(ctx, operation, operationID, token, options)
+	}
+	return err
+}
+
+// ExpectListOperations expects a call to ListOperations.
+func (h *Handler) ExpectListOperations() *Call[ListOperationsFunc, *nexus.OperationList] {
+	return &Call[ListOperationsFunc, *nexus.OperationList]{h.m, h.m.expect("ListOperations")}
+}
+
+// ListOperations implements nexus.Handler.
+func (h *Handler) ListOperations(ctx context.Context, options nexus.ListOperationsOptions) (*nexus.OperationList, error) {
+	e, err := h.m.call("ListOperations")
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.OperationList](h.m, e)
+	if fn, ok := do.(ListOperationsFunc); ok {
+		return fn(ctx, options)
+	}
+	return result, err
+}
+
+// ExpectCancelMatchingOperations expects a call to CancelMatchingOperations.
+func (h *Handler) ExpectCancelMatchingOperations() *Call[CancelMatchingOperationsFunc, <-chan *nexus.BatchProgress] {
+	return &Call[CancelMatchingOperationsFunc, <-chan *nexus.BatchProgress]{h.m, h.m.expect("CancelMatchingOperations")}
+}
+
+// CancelMatchingOperations implements nexus.Handler.
+func (h *Handler) CancelMatchingOperations(ctx context.Context, options nexus.CancelMatchingOperationsOptions) (<-chan *nexus.BatchProgress, error) {
+	e, err := h.m.call("CancelMatchingOperations")
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[<-chan *nexus.BatchProgress](h.m, e)
+	if fn, ok := do.(CancelMatchingOperationsFunc); ok {
+		return fn(ctx, options)
+	}
+	return result, err
+}
+
+// ExpectGetQuotaUsage expects a call to GetQuotaUsage. Empty arguments match any value.
+func (h *Handler) ExpectGetQuotaUsage(subject string) *Call[GetQuotaUsageFunc, *nexus.QuotaStatus] {
+	return &Call[GetQuotaUsageFunc, *nexus.QuotaStatus]{h.m, h.m.expect("GetQuotaUsage", subject)}
+}
+
+// GetQuotaUsage implements nexus.Handler.
+func (h *Handler) GetQuotaUsage(ctx context.Context, subject string, options nexus.GetQuotaUsageOptions) (*nexus.QuotaStatus, error) {
+	e, err := h.m.call("GetQuotaUsage", subject)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.QuotaStatus](h.m, e)
+	if fn, ok := do.(GetQuotaUsageFunc); ok {
+		return fn(ctx, subject, options)
+	}
+	return result, err
+}
+
+// ExpectHeartbeatOperation expects a call to HeartbeatOperation. Empty arguments match any value.
+func (h *Handler) ExpectHeartbeatOperation(operation, operationID string) *Call[HeartbeatOperationFunc, *nexus.OperationInfo] {
+	return &Call[HeartbeatOperationFunc, *nexus.OperationInfo]{h.m, h.m.expect("HeartbeatOperation", operation, operationID)}
+}
+
+// HeartbeatOperation implements nexus.Handler.
+func (h *Handler) HeartbeatOperation(ctx context.Context, operation string, operationID string, options nexus.HeartbeatOperationOptions) (*nexus.OperationInfo, error) {
+	e, err := h.m.call("HeartbeatOperation", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.OperationInfo](h.m, e)
+	if fn, ok := do.(HeartbeatOperationFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return result, err
+}
+
+// ExpectResolveQuarantine expects a call to ResolveQuarantine. Empty arguments match any value.
+func (h *Handler) ExpectResolveQuarantine(operation, operationID string) *ErrCall[ResolveQuarantineFunc] {
+	return &ErrCall[ResolveQuarantineFunc]{h.m, h.m.expect("ResolveQuarantine", operation, operationID)}
+}
+
+// ResolveQuarantine implements nexus.Handler.
+func (h *Handler) ResolveQuarantine(ctx context.Context, operation string, operationID string, options nexus.ResolveQuarantineOptions) error {
+	e, err := h.m.call("ResolveQuarantine", operation, operationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](h.m, e)
+	if fn, ok := do.(ResolveQuarantineFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return err
+}
+
+// ExpectForceCompleteOperation expects a call to ForceCompleteOperation. Empty arguments match any value.
+func (h *Handler) ExpectForceCompleteOperation(operation, operationID string) *ErrCall[ForceCompleteOperationFunc] {
+	return &ErrCall[ForceCompleteOperationFunc]{h.m, h.m.expect("ForceCompleteOperation", operation, operationID)}
+}
+
+// ForceCompleteOperation implements nexus.Handler.
+func (h *Handler) ForceCompleteOperation(ctx context.Context, operation string, operationID string, options nexus.ForceCompleteOperationOptions) error {
+	e, err := h.m.call("ForceCompleteOperation", operation, operationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](h.m, e)
+	if fn, ok := do.(ForceCompleteOperationFunc); ok {
+		return fn(ctx, operation, operationID, options)
+	}
+	return err
+}
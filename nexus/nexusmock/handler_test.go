@@ -0,0 +1,91 @@
+package nexusmock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(t)
+	handler.ExpectStartOperation("charge").Return(&nexus.HandlerStartOperationResultSync[any]{Value: "receipt"}, nil)
+	handler.ExpectStartOperation("").Do(func(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error) {
+		return &nexus.HandlerStartOperationResultAsync{OperationID: options.RequestID}, nil
+	})
+	handler.ExpectCancelOperation("ship", "").Return(nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "gone")).Times(2)
+
+	client, err := nexus.NewInProcessClient(nexus.HandlerOptions{Handler: handler}, nexus.ClientOptions{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, err := client.StartOperation(ctx, "charge", nil, nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	var receipt string
+	require.NoError(t, result.Successful.Consume(&receipt))
+	require.Equal(t, "receipt", receipt)
+
+	result, err = client.StartOperation(ctx, "ship", nil, nexus.StartOperationOptions{RequestID: "ship-1"})
+	require.NoError(t, err)
+	require.Equal(t, "ship-1", result.Pending.ID)
+
+	for i := 0; i < 2; i++ {
+		var unexpectedErr *nexus.UnexpectedResponseError
+		require.ErrorAs(t, result.Pending.Cancel(ctx, nexus.CancelOperationOptions{}), &unexpectedErr)
+		require.Equal(t, http.StatusNotFound, unexpectedErr.Response.StatusCode)
+	}
+}
+
+func TestHandler_UnexpectedCall(t *testing.T) {
+	rt := &recordingT{}
+	handler := NewHandler(rt)
+	handler.ExpectGetOperationInfo("charge", "id").AnyTimes()
+
+	_, err := handler.GetOperationInfo(context.Background(), "refund", "id", nexus.GetOperationInfoOptions{})
+	require.ErrorIs(t, err, ErrUnexpectedCall)
+	require.Len(t, rt.errors, 1)
+}
+
+func TestCompletionHandler(t *testing.T) {
+	handler := NewCompletionHandler(t)
+	handler.ExpectCompleteOperation("id").Return(errors.New("rejected"))
+
+	err := handler.CompleteOperation(context.Background(), &nexus.CompletionRequest{OperationID: "id"})
+	require.EqualError(t, err, "rejected")
+}
+
+func TestSerializer(t *testing.T) {
+	serializer := NewSerializer(t)
+	serializer.ExpectSerialize().Return(&nexus.Content{Data: []byte("encoded")}, nil)
+	serializer.ExpectDeserialize().Do(func(content *nexus.Content, v any) error {
+		*v.(*string) = string(content.Data)
+		return nil
+	})
+
+	content, err := serializer.Serialize("value")
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, serializer.Deserialize(content, &s))
+	require.Equal(t, "encoded", s)
+}
+
+func TestOperationStore(t *testing.T) {
+	store := NewOperationStore(t)
+	store.ExpectCreate("charge", "").Return(nexus.ErrOperationRecordExists)
+	store.ExpectGet("charge", "id").Return(&nexus.OperationRecord{Operation: "charge", ID: "id", Version: 3}, nil)
+	store.ExpectUpdate("", "").Return(nexus.ErrOperationRecordVersionConflict)
+	store.ExpectWaitForUpdate("charge", "id").Do(func(ctx context.Context, operation, operationID string, version int64) error {
+		require.Equal(t, int64(3), version)
+		return nil
+	})
+
+	ctx := context.Background()
+	require.ErrorIs(t, store.Create(ctx, &nexus.OperationRecord{Operation: "charge", ID: "id"}), nexus.ErrOperationRecordExists)
+	record, err := store.Get(ctx, "charge", "id")
+	require.NoError(t, err)
+	require.ErrorIs(t, store.Update(ctx, record), nexus.ErrOperationRecordVersionConflict)
+	require.NoError(t, store.WaitForUpdate(ctx, "charge", "id", record.Version))
+}
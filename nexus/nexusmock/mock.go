@@ -0,0 +1,224 @@
+// Package nexusmock provides mocks of the interfaces of the nexus package, e.g. [nexus.Handler] and
+// [nexus.OperationStore], with helpers for setting expectations, so tests need not maintain hand-written fakes that
+// drift when the interfaces change.
+//
+//	handler := nexusmock.NewHandler(t)
+//	handler.ExpectStartOperation("charge").Return(&nexus.HandlerStartOperationResultSync[any]{Value: "receipt"}, nil)
+//	handler.ExpectCancelOperation("charge", "").Return(nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "gone")).Times(2)
+//	client, _ := nexus.NewInProcessClient(nexus.HandlerOptions{Handler: handler}, nexus.ClientOptions{})
+//
+// Calls are matched against expectations in the order they were set; empty string arguments of Expect methods match
+// any value. Calls without a matching expectation fail the test and return [ErrUnexpectedCall]. Expectations that were
+// not met fail the test when it completes.
+package nexusmock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnexpectedCall is returned from mocked methods called without a matching expectation.
+var ErrUnexpectedCall = errors.New("nexusmock: unexpected call")
+
+// TestingT is the subset of [testing.T] used by mocks.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// expectation is an expected call of a mocked method.
+type expectation struct {
+	method string
+	// Expected values of the call's key arguments, e.g. the operation name and ID. Empty values match any value.
+	args []string
+	// Typed function to invoke instead of returning result and err.
+	do     any
+	result any
+	err    error
+	// Exact number of expected calls, or -1 for at least one call and -2 for any number of calls.
+	times int
+	calls int
+}
+
+const (
+	atLeastOnce = -1
+	anyTimes    = -2
+)
+
+func (e *expectation) matches(method string, args []string) bool {
+	if e.method != method {
+		return false
+	}
+	for i, arg := range e.args {
+		if arg != "" && arg != args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *expectation) saturated() bool {
+	return e.times >= 0 && e.calls >= e.times
+}
+
+func (e *expectation) met() bool {
+	switch e.times {
+	case atLeastOnce:
+		return e.calls > 0
+	case anyTimes:
+		return true
+	}
+	return e.calls == e.times
+}
+
+func (e *expectation) String() string {
+	return fmt.Sprintf("%s(%s)", e.method, formatArgs(e.args))
+}
+
+func formatArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" {
+			quoted[i] = "*"
+		} else {
+			quoted[i] = fmt.Sprintf("%q", arg)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// mock records expectations and matches calls against them.
+type mock struct {
+	t    TestingT
+	name string
+
+	mu           sync.Mutex
+	expectations []*expectation
+}
+
+func newMock(t TestingT, name string) *mock {
+	m := &mock{t: t, name: name}
+	t.Cleanup(m.assertExpectations)
+	return m
+}
+
+func (m *mock) expect(method string, args ...string) *expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &expectation{method: method, args: args, times: atLeastOnce}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// call returns the first expectation matching a call that is not saturated yet. Fails the test and returns
+// ErrUnexpectedCall if there is none.
+func (m *mock) call(method string, args ...string) (*expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.matches(method, args) && !e.saturated() {
+			e.calls++
+			return e, nil
+		}
+	}
+	m.t.Helper()
+	m.t.Errorf("nexusmock: unexpected call to %s.%s(%s)", m.name, method, formatArgs(args))
+	return nil, fmt.Errorf("%w to %s.%s", ErrUnexpectedCall, m.name, method)
+}
+
+func (m *mock) assertExpectations() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.met() {
+			continue
+		}
+		switch e.times {
+		case atLeastOnce:
+			m.t.Errorf("nexusmock: expected call to %s.%s was not made", m.name, e)
+		default:
+			m.t.Errorf("nexusmock: expected %d calls to %s.%s, got %d", e.times, m.name, e, e.calls)
+		}
+	}
+}
+
+func (m *mock) set(f func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f()
+}
+
+// Call is an expected call of a mocked method of type F returning a value of type R and an error. By default the call
+// must be made at least once and returns zero values.
+type Call[F any, R any] struct {
+	m *mock
+	e *expectation
+}
+
+// Return sets the values returned by the call.
+func (c *Call[F, R]) Return(result R, err error) *Call[F, R] {
+	c.m.set(func() { c.e.result, c.e.err = result, err })
+	return c
+}
+
+// Do sets a function invoked with the call's arguments to produce its results, taking precedence over Return.
+func (c *Call[F, R]) Do(fn F) *Call[F, R] {
+	c.m.set(func() { c.e.do = fn })
+	return c
+}
+
+// Times sets the exact number of times the call is expected. Further calls are matched against subsequent
+// expectations.
+func (c *Call[F, R]) Times(n int) *Call[F, R] {
+	c.m.set(func() { c.e.times = n })
+	return c
+}
+
+// AnyTimes allows the call to be made any number of times, including not at all.
+func (c *Call[F, R]) AnyTimes() *Call[F, R] {
+	c.m.set(func() { c.e.times = anyTimes })
+	return c
+}
+
+// ErrCall is an expected call of a mocked method of type F returning only an error. By default the call must be made at
+// least once and returns nil.
+type ErrCall[F any] struct {
+	m *mock
+	e *expectation
+}
+
+// Return sets the error returned by the call.
+func (c *ErrCall[F]) Return(err error) *ErrCall[F] {
+	c.m.set(func() { c.e.err = err })
+	return c
+}
+
+// Do sets a function invoked with the call's arguments to produce its error, taking precedence over Return.
+func (c *ErrCall[F]) Do(fn F) *ErrCall[F] {
+	c.m.set(func() { c.e.do = fn })
+	return c
+}
+
+// Times sets the exact number of times the call is expected. Further calls are matched against subsequent
+// expectations.
+func (c *ErrCall[F]) Times(n int) *ErrCall[F] {
+	c.m.set(func() { c.e.times = n })
+	return c
+}
+
+// AnyTimes allows the call to be made any number of times, including not at all.
+func (c *ErrCall[F]) AnyTimes() *ErrCall[F] {
+	c.m.set(func() { c.e.times = anyTimes })
+	return c
+}
+
+// outcome returns the function set via Do, if any, and the results set via Return of an expectation of a call.
+func outcome[R any](m *mock, e *expectation) (any, R, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, _ := e.result.(R)
+	return e.do, result, e.err
+}
@@ -0,0 +1,80 @@
+package nexusmock
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records failures and cleanups instead of failing the test.
+type recordingT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingT) finish() {
+	for _, f := range t.cleanups {
+		f()
+	}
+}
+
+func TestMock_MatchesInOrder(t *testing.T) {
+	rt := &recordingT{}
+	m := newMock(rt, "Mock")
+	first := m.expect("Get", "foo", "")
+	first.times = 1
+	second := m.expect("Get", "", "")
+	second.times = anyTimes
+
+	e, err := m.call("Get", "foo", "id")
+	require.NoError(t, err)
+	require.Same(t, first, e)
+	e, err = m.call("Get", "foo", "id")
+	require.NoError(t, err)
+	require.Same(t, second, e)
+	e, err = m.call("Get", "bar", "id")
+	require.NoError(t, err)
+	require.Same(t, second, e)
+
+	rt.finish()
+	require.Empty(t, rt.errors)
+}
+
+func TestMock_UnexpectedCall(t *testing.T) {
+	rt := &recordingT{}
+	m := newMock(rt, "Mock")
+	m.expect("Get", "foo", "id")
+
+	_, err := m.call("Get", "foo", "other")
+	require.ErrorIs(t, err, ErrUnexpectedCall)
+	require.Equal(t, []string{`nexusmock: unexpected call to Mock.Get("foo", "other")`}, rt.errors)
+}
+
+func TestMock_UnmetExpectations(t *testing.T) {
+	rt := &recordingT{}
+	m := newMock(rt, "Mock")
+	m.expect("Get", "foo", "")
+	twice := m.expect("Cancel", "foo", "id")
+	twice.times = 2
+	_, err := m.call("Cancel", "foo", "id")
+	require.NoError(t, err)
+	optional := m.expect("Update")
+	optional.times = anyTimes
+
+	rt.finish()
+	require.Equal(t, []string{
+		`nexusmock: expected call to Mock.Get("foo", *) was not made`,
+		`nexusmock: expected 2 calls to Mock.Cancel("foo", "id"), got 1`,
+	}, rt.errors)
+}
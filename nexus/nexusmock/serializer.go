@@ -0,0 +1,60 @@
+package nexusmock
+
+import (
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Serializer is a mock [nexus.Serializer].
+type Serializer struct {
+	m *mock
+}
+
+// NewSerializer creates a [Serializer] mock without expectations, reporting unexpected calls and unmet expectations to
+// t.
+func NewSerializer(t TestingT) *Serializer {
+	return &Serializer{m: newMock(t, "Serializer")}
+}
+
+var _ nexus.Serializer = &Serializer{}
+
+// SerializeFunc is the signature of [nexus.Serializer.Serialize].
+type SerializeFunc func(v any) (*nexus.Content, error)
+
+// DeserializeFunc is the signature of [nexus.Serializer.Deserialize].
+type DeserializeFunc func(content *nexus.Content, v any) error
+
+// ExpectSerialize expects a call to Serialize.
+func (s *Serializer) ExpectSerialize() *Call[SerializeFunc, *nexus.Content] {
+	return &Call[SerializeFunc, *nexus.Content]{s.m, s.m.expect("Serialize")}
+}
+
+// Serialize implements nexus.Serializer.
+func (s *Serializer) Serialize(v any) (*nexus.Content, error) {
+	e, err := s.m.call("Serialize")
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.Content](s.m, e)
+	if fn, ok := do.(SerializeFunc); ok {
+		return fn(v)
+	}
+	return result, err
+}
+
+// ExpectDeserialize expects a call to Deserialize.
+func (s *Serializer) ExpectDeserialize() *ErrCall[DeserializeFunc] {
+	return &ErrCall[DeserializeFunc]{s.m, s.m.expect("Deserialize")}
+}
+
+// Deserialize implements nexus.Serializer.
+func (s *Serializer) Deserialize(content *nexus.Content, v any) error {
+	e, err := s.m.call("Deserialize")
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](s.m, e)
+	if fn, ok := do.(DeserializeFunc); ok {
+		return fn(content, v)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package nexusmock
+
+import (
+	"context"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// OperationStore is a mock [nexus.OperationStore]. Expectations are keyed by operation name and ID, taken from the
+// record for Create and Update.
+type OperationStore struct {
+	m *mock
+}
+
+// NewOperationStore creates an [OperationStore] mock without expectations, reporting unexpected calls and unmet
+// expectations to t.
+func NewOperationStore(t TestingT) *OperationStore {
+	return &OperationStore{m: newMock(t, "OperationStore")}
+}
+
+var _ nexus.OperationStore = &OperationStore{}
+
+// CreateFunc is the signature of [nexus.OperationStore.Create].
+type CreateFunc func(ctx context.Context, record *nexus.OperationRecord) error
+
+// GetFunc is the signature of [nexus.OperationStore.Get].
+type GetFunc func(ctx context.Context, operation, operationID string) (*nexus.OperationRecord, error)
+
+// UpdateFunc is the signature of [nexus.OperationStore.Update].
+type UpdateFunc func(ctx context.Context, record *nexus.OperationRecord) error
+
+// WaitForUpdateFunc is the signature of [nexus.OperationStore.WaitForUpdate].
+type WaitForUpdateFunc func(ctx context.Context, operation, operationID string, version int64) error
+
+// ExpectCreate expects a call to Create. Empty arguments match any value.
+func (s *OperationStore) ExpectCreate(operation, operationID string) *ErrCall[CreateFunc] {
+	return &ErrCall[CreateFunc]{s.m, s.m.expect("Create", operation, operationID)}
+}
+
+// Create implements nexus.OperationStore.
+func (s *OperationStore) Create(ctx context.Context, record *nexus.OperationRecord) error {
+	e, err := s.m.call("Create", record.Operation, record.ID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](s.m, e)
+	if fn, ok := do.(CreateFunc); ok {
+		return fn(ctx, record)
+	}
+	return err
+}
+
+// ExpectGet expects a call to Get. Empty arguments match any value.
+func (s *OperationStore) ExpectGet(operation, operationID string) *Call[GetFunc, *nexus.OperationRecord] {
+	return &Call[GetFunc, *nexus.OperationRecord]{s.m, s.m.expect("Get", operation, operationID)}
+}
+
+// Get implements nexus.OperationStore.
+func (s *OperationStore) Get(ctx context.Context, operation, operationID string) (*nexus.OperationRecord, error) {
+	e, err := s.m.call("Get", operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	do, result, err := outcome[*nexus.OperationRecord](s.m, e)
+	if fn, ok := do.(GetFunc); ok {
+		return fn(ctx, operation, operationID)
+	}
+	return result, err
+}
+
+// ExpectUpdate expects a call to Update. Empty arguments match any value.
+func (s *OperationStore) ExpectUpdate(operation, operationID string) *ErrCall[UpdateFunc] {
+	return &ErrCall[UpdateFunc]{s.m, s.m.expect("Update", operation, operationID)}
+}
+
+// Update implements nexus.OperationStore.
+func (s *OperationStore) Update(ctx context.Context, record *nexus.OperationRecord) error {
+	e, err := s.m.call("Update", record.Operation, record.ID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](s.m, e)
+	if fn, ok := do.(UpdateFunc); ok {
+		return fn(ctx, record)
+	}
+	return err
+}
+
+// ExpectWaitForUpdate expects a call to WaitForUpdate. Empty arguments match any value.
+func (s *OperationStore) ExpectWaitForUpdate(operation, operationID string) *ErrCall[WaitForUpdateFunc] {
+	return &ErrCall[WaitForUpdateFunc]{s.m, s.m.expect("WaitForUpdate", operation, operationID)}
+}
+
+// WaitForUpdate implements nexus.OperationStore.
+func (s *OperationStore) WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	e, err := s.m.call("WaitForUpdate", operation, operationID)
+	if err != nil {
+		return err
+	}
+	do, _, err := outcome[any](s.m, e)
+	if fn, ok := do.(WaitForUpdateFunc); ok {
+		return fn(ctx, operation, operationID, version)
+	}
+	return err
+}
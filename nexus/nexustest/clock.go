@@ -0,0 +1,102 @@
+package nexustest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// FakeClock is a [nexus.Clock] whose time only moves when advanced, for testing waits and timeouts without sleeping,
+// e.g. via [nexus.ClientOptions.Clock] and [nexus.HandlerOptions.Clock].
+//
+//	clock := nexustest.NewFakeClock(time.Now())
+//	// Start code waiting on the clock in another goroutine, then wait for it to create its timer.
+//	for clock.PendingTimers() == 0 {
+//		runtime.Gosched()
+//	}
+//	clock.Advance(time.Minute)
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+}
+
+// NewFakeClock creates a [FakeClock] set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, timers: make(map[*fakeTimer]struct{})}
+}
+
+// Now implements nexus.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements nexus.Clock. The timer fires once the clock is advanced by at least d.
+func (c *FakeClock) NewTimer(d time.Duration) nexus.ClockTimer {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.at.After(c.now) {
+			c.fireLocked(t)
+		}
+	}
+}
+
+// PendingTimers returns the number of timers that have not fired or been stopped yet, e.g. to wait for the code under
+// test to start waiting on the clock before advancing it.
+func (c *FakeClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) fireLocked(t *fakeTimer) {
+	delete(c.timers, t)
+	select {
+	case t.ch <- c.now:
+	default:
+	}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	ch    chan time.Time
+	// Guarded by the clock's mutex.
+	at time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	_, pending := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return pending
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, pending := c.timers[t]
+	t.at = c.now.Add(d)
+	c.timers[t] = struct{}{}
+	if d <= 0 {
+		c.fireLocked(t)
+	}
+	return pending
+}
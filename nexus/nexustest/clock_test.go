@@ -0,0 +1,48 @@
+package nexustest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	require.Equal(t, 2, clock.PendingTimers())
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+
+	clock.Advance(30 * time.Second)
+	require.Equal(t, start.Add(30*time.Second), clock.Now())
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	clock.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), <-timer.C())
+	require.Equal(t, 0, clock.PendingTimers())
+
+	require.False(t, timer.Reset(time.Second))
+	require.Equal(t, 1, clock.PendingTimers())
+	clock.Advance(time.Second)
+	<-timer.C()
+}
+
+func TestFakeClock_WaitForStartTime(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ctx := nexus.ContextWithClock(context.Background(), clock)
+	done := make(chan error, 1)
+	go func() {
+		done <- nexus.WaitForStartTime(ctx, clock.Now().Add(time.Hour))
+	}()
+	require.Eventually(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(time.Hour)
+	require.NoError(t, <-done)
+}
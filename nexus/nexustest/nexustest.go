@@ -0,0 +1,194 @@
+// Package nexustest provides an in-memory Nexus service with programmable behaviors per operation, for testing code
+// that calls Nexus services via a [nexus.Client] without writing test doubles.
+//
+//	service, err := nexustest.NewService(map[string]nexustest.Behavior{
+//		"charge": {Result: "receipt-1"},
+//		"ship":   {Async: true, CompleteAfter: 100 * time.Millisecond, Result: "shipped"},
+//		"refund": {Error: nexus.HandlerErrorf(nexus.HandlerErrorTypeUnavailable, "try again")},
+//	})
+//	client := service.Client()
+//
+// Requests are served in-process; async operations are tracked in memory by a [nexus.AsyncHandler], supporting
+// result, info, watch and cancel requests.
+package nexustest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// CancelBehavior determines how a [Service] handles requests to cancel an operation.
+type CancelBehavior int
+
+const (
+	// CancelCompletes completes the operation as canceled.
+	CancelCompletes CancelBehavior = iota
+	// CancelIgnored accepts cancelation requests but lets the operation run to completion.
+	CancelIgnored
+	// CancelRejected fails cancelation requests with a bad request handler error.
+	CancelRejected
+)
+
+// Behavior programs how a [Service] responds to requests to start an operation.
+type Behavior struct {
+	// Delay before responding to start requests. Requests whose context is done before are failed.
+	Latency time.Duration
+	// Error to fail start requests with, e.g. a [nexus.HandlerError] or an [nexus.UnsuccessfulOperationError] for an
+	// operation that failed synchronously.
+	Error error
+	// Result of the operation, returned synchronously unless Async is set.
+	Result any
+	// Start the operation asynchronously and complete it after CompleteAfter.
+	Async bool
+	// Duration after which asynchronous operations complete.
+	CompleteAfter time.Duration
+	// Failure to complete asynchronous operations with instead of succeeding with Result.
+	Failure *nexus.Failure
+	// How requests to cancel asynchronous operations are handled. Defaults to CancelCompletes.
+	Cancel CancelBehavior
+}
+
+// Service is an in-memory Nexus service responding to requests according to the [Behavior] of each operation.
+// Requests for operations without a behavior fail with a not found handler error.
+type Service struct {
+	handler *handler
+	client  *nexus.Client
+
+	mu        sync.Mutex
+	behaviors map[string]Behavior
+	starts    map[string]int
+}
+
+// NewService creates a [Service] with the given behaviors per operation name.
+func NewService(behaviors map[string]Behavior) (*Service, error) {
+	s := &Service{
+		behaviors: make(map[string]Behavior, len(behaviors)),
+		starts:    make(map[string]int),
+	}
+	for operation, behavior := range behaviors {
+		s.behaviors[operation] = behavior
+	}
+	async, err := nexus.NewAsyncHandler(nexus.AsyncHandlerOptions{
+		Store:    nexus.NewMemoryOperationStore(),
+		Executor: s.execute,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.handler = &handler{AsyncHandler: async, service: s}
+	if s.client, err = s.NewClient(nexus.ClientOptions{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Client returns a client connected to the service in-process.
+func (s *Service) Client() *nexus.Client {
+	return s.client
+}
+
+// NewClient creates a client connected to the service in-process with the given options, e.g. to test a custom
+// serializer. The client's HTTPCaller and ServiceBaseURL are replaced.
+func (s *Service) NewClient(options nexus.ClientOptions) (*nexus.Client, error) {
+	options.ServiceBaseURL = ""
+	return nexus.NewInProcessClient(nexus.HandlerOptions{Handler: s.handler}, options)
+}
+
+// SetBehavior sets the behavior of an operation for subsequent start requests.
+func (s *Service) SetBehavior(operation string, behavior Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.behaviors[operation] = behavior
+}
+
+// Starts returns the number of requests to start the given operation the service received.
+func (s *Service) Starts(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.starts[operation]
+}
+
+func (s *Service) behavior(operation string) (Behavior, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	behavior, ok := s.behaviors[operation]
+	if !ok {
+		return Behavior{}, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "unknown operation: %q", operation)
+	}
+	return behavior, nil
+}
+
+// execute completes asynchronous operations according to their behavior.
+func (s *Service) execute(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (any, error) {
+	behavior, err := s.behavior(operation)
+	if err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(behavior.CompleteAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		// The operation was canceled, the outcome is ignored.
+		return nil, ctx.Err()
+	}
+	if behavior.Failure != nil {
+		return nil, &nexus.UnsuccessfulOperationError{State: nexus.OperationStateFailed, Failure: *behavior.Failure}
+	}
+	return behavior.Result, nil
+}
+
+// handler serves synchronous behaviors directly and delegates asynchronous ones to an [nexus.AsyncHandler].
+type handler struct {
+	*nexus.AsyncHandler
+	service *Service
+}
+
+// StartOperation implements nexus.Handler.
+func (h *handler) StartOperation(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error) {
+	behavior, err := h.service.behavior(operation)
+	if err != nil {
+		return nil, err
+	}
+	h.service.mu.Lock()
+	h.service.starts[operation]++
+	h.service.mu.Unlock()
+
+	if behavior.Latency > 0 {
+		timer := time.NewTimer(behavior.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if behavior.Error != nil {
+		return nil, behavior.Error
+	}
+	if behavior.Async {
+		return h.AsyncHandler.StartOperation(ctx, operation, input, options)
+	}
+	return &nexus.HandlerStartOperationResultSync[any]{Value: behavior.Result}, nil
+}
+
+// CancelOperation implements nexus.Handler.
+func (h *handler) CancelOperation(ctx context.Context, operation, operationID string, options nexus.CancelOperationOptions) error {
+	behavior, err := h.service.behavior(operation)
+	if err != nil {
+		return err
+	}
+	switch behavior.Cancel {
+	case CancelIgnored:
+		// Ensure the operation exists.
+		_, err := h.AsyncHandler.GetOperationInfo(ctx, operation, operationID, nexus.GetOperationInfoOptions{})
+		return err
+	case CancelRejected:
+		return nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "cancelation rejected")
+	default:
+		return h.AsyncHandler.CancelOperation(ctx, operation, operationID, options)
+	}
+}
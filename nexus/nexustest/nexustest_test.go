@@ -0,0 +1,126 @@
+package nexustest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Sync(t *testing.T) {
+	service, err := NewService(map[string]Behavior{"charge": {Result: "receipt"}})
+	require.NoError(t, err)
+
+	result, err := service.Client().StartOperation(context.Background(), "charge", nil, nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, result.Successful.Consume(&s))
+	require.Equal(t, "receipt", s)
+	require.Equal(t, 1, service.Starts("charge"))
+}
+
+func TestService_Error(t *testing.T) {
+	service, err := NewService(map[string]Behavior{
+		"refund": {Error: nexus.HandlerErrorf(nexus.HandlerErrorTypeUnavailable, "try again")},
+	})
+	require.NoError(t, err)
+
+	_, err = service.Client().StartOperation(context.Background(), "refund", nil, nexus.StartOperationOptions{})
+	var unexpectedErr *nexus.UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusServiceUnavailable, unexpectedErr.Response.StatusCode)
+
+	_, err = service.Client().StartOperation(context.Background(), "unknown", nil, nexus.StartOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusNotFound, unexpectedErr.Response.StatusCode)
+}
+
+func TestService_Latency(t *testing.T) {
+	service, err := NewService(map[string]Behavior{"charge": {Latency: time.Second}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = service.Client().StartOperation(ctx, "charge", nil, nexus.StartOperationOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestService_AsyncCompletion(t *testing.T) {
+	service, err := NewService(map[string]Behavior{
+		"ship":   {Async: true, CompleteAfter: 10 * time.Millisecond, Result: "shipped"},
+		"return": {Async: true, Failure: &nexus.Failure{Message: "out of stock"}},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, err := service.Client().StartOperation(ctx, "ship", nil, nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	value, err := result.Pending.GetResult(ctx, nexus.GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, value.Consume(&s))
+	require.Equal(t, "shipped", s)
+
+	result, err = service.Client().StartOperation(ctx, "return", nil, nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, nexus.GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulErr *nexus.UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulErr)
+	require.Equal(t, nexus.OperationStateFailed, unsuccessfulErr.State)
+	require.Equal(t, "out of stock", unsuccessfulErr.Failure.Message)
+}
+
+func TestService_Cancel(t *testing.T) {
+	service, err := NewService(map[string]Behavior{
+		"completes": {Async: true, CompleteAfter: time.Hour},
+		"ignored":   {Async: true, CompleteAfter: 50 * time.Millisecond, Result: "done", Cancel: CancelIgnored},
+		"rejected":  {Async: true, CompleteAfter: time.Hour, Cancel: CancelRejected},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	start := func(operation string) *nexus.OperationHandle[*nexus.LazyValue] {
+		result, err := service.Client().StartOperation(ctx, operation, nil, nexus.StartOperationOptions{})
+		require.NoError(t, err)
+		return result.Pending
+	}
+
+	handle := start("completes")
+	require.NoError(t, handle.Cancel(ctx, nexus.CancelOperationOptions{}))
+	info, err := handle.GetInfo(ctx, nexus.GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateCanceled, info.State)
+
+	handle = start("ignored")
+	require.NoError(t, handle.Cancel(ctx, nexus.CancelOperationOptions{}))
+	value, err := handle.GetResult(ctx, nexus.GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, value.Consume(&s))
+	require.Equal(t, "done", s)
+
+	handle = start("rejected")
+	err = handle.Cancel(ctx, nexus.CancelOperationOptions{})
+	var unexpectedErr *nexus.UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusBadRequest, unexpectedErr.Response.StatusCode)
+}
+
+func TestService_SetBehavior(t *testing.T) {
+	service, err := NewService(nil)
+	require.NoError(t, err)
+	service.SetBehavior("charge", Behavior{Error: &nexus.UnsuccessfulOperationError{
+		State:   nexus.OperationStateFailed,
+		Failure: nexus.Failure{Message: "declined"},
+	}})
+
+	_, err = service.Client().StartOperation(context.Background(), "charge", nil, nexus.StartOperationOptions{})
+	var unsuccessfulErr *nexus.UnsuccessfulOperationError
+	require.True(t, errors.As(err, &unsuccessfulErr))
+	require.Equal(t, "declined", unsuccessfulErr.Failure.Message)
+	require.Equal(t, 1, service.Starts("charge"))
+}
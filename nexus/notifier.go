@@ -0,0 +1,140 @@
+package nexus
+
+import (
+	"context"
+	"sync"
+)
+
+// A Notifier wakes up requests waiting in process for operations to complete, e.g. long polls of
+// [Handler.GetOperationResult], as soon as an operation completes, instead of having them poll for completion or wait
+// out their timeout. Notify the notifier whenever an operation completes, whether it completed locally or a
+// completion was received via [CompletionHandler], see [Notifier.CompletionHandler].
+//
+// Waiters are keyed by operation ID only, since completion requests do not identify the operation by name. Waiters
+// must check the state of the operation when notified; a notification for an operation with the same ID but a
+// different name is spurious.
+//
+// A Notifier only wakes up waiters in the same process. A Notifier is safe for concurrent use.
+type Notifier struct {
+	mu      sync.Mutex
+	waiters map[string]*notifierWaiters
+}
+
+// notifierWaiters are the waiters registered for an operation ID, sharing a channel closed on notification.
+type notifierWaiters struct {
+	ch    chan struct{}
+	count int
+}
+
+// NewNotifier creates a [Notifier] without waiters.
+func NewNotifier() *Notifier {
+	return &Notifier{waiters: make(map[string]*notifierWaiters)}
+}
+
+// Register registers a waiter for the given operation, returning a channel closed by the next [Notifier.Notify] call
+// for the operation and a function unregistering the waiter, which must be called once the waiter stops waiting.
+//
+// Register before checking whether the operation completed to avoid missing a notification sent between the check and
+// the wait.
+func (n *Notifier) Register(operationID string) (<-chan struct{}, func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	w, ok := n.waiters[operationID]
+	if !ok {
+		w = &notifierWaiters{ch: make(chan struct{})}
+		n.waiters[operationID] = w
+	}
+	w.count++
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			w.count--
+			// The waiters are replaced once notified.
+			if w.count == 0 && n.waiters[operationID] == w {
+				delete(n.waiters, operationID)
+			}
+		})
+	}
+}
+
+// Notify wakes up all waiters registered for the given operation.
+func (n *Notifier) Notify(operationID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if w, ok := n.waiters[operationID]; ok {
+		close(w.ch)
+		delete(n.waiters, operationID)
+	}
+}
+
+// Wait blocks until completed reports that the given operation completed, checking it initially and whenever the
+// operation is notified. Returns the context error if ctx is done first, or the error returned by completed.
+func (n *Notifier) Wait(ctx context.Context, operationID string, completed func() (bool, error)) error {
+	for {
+		notified, unregister := n.Register(operationID)
+		done, err := completed()
+		if err != nil || done {
+			unregister()
+			return err
+		}
+		select {
+		case <-notified:
+			unregister()
+		case <-ctx.Done():
+			unregister()
+			return ctx.Err()
+		}
+	}
+}
+
+// CompletionHandler wraps a [CompletionHandler] to notify the completed operation once handler handled its completion
+// successfully. Completions without an operation ID are not notified.
+func (n *Notifier) CompletionHandler(handler CompletionHandler) CompletionHandler {
+	return &notifyingCompletionHandler{handler, n}
+}
+
+type notifyingCompletionHandler struct {
+	CompletionHandler
+	notifier *Notifier
+}
+
+// CompleteOperation implements CompletionHandler.
+func (h *notifyingCompletionHandler) CompleteOperation(ctx context.Context, request *CompletionRequest) error {
+	if err := h.CompletionHandler.CompleteOperation(ctx, request); err != nil {
+		return err
+	}
+	if request.OperationID != "" {
+		h.notifier.Notify(request.OperationID)
+	}
+	return nil
+}
+
+// waitForUpdate waits for an update of the given record in the store as [OperationStore.WaitForUpdate] does, returning
+// early when the operation is notified by the handler's [AsyncHandlerOptions.Notifier].
+func (h *AsyncHandler) waitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	if h.options.Notifier == nil {
+		return h.options.Store.WaitForUpdate(ctx, operation, operationID, version)
+	}
+	notified, unregister := h.options.Notifier.Register(operationID)
+	defer unregister()
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-notified:
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+	err := h.options.Store.WaitForUpdate(waitCtx, operation, operationID, version)
+	if err != nil && ctx.Err() == nil {
+		select {
+		case <-notified:
+			return nil
+		default:
+		}
+	}
+	return err
+}
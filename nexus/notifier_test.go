@@ -0,0 +1,122 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_Register(t *testing.T) {
+	n := NewNotifier()
+	a, unregisterA := n.Register("id")
+	b, unregisterB := n.Register("id")
+	other, unregisterOther := n.Register("other")
+
+	n.Notify("id")
+	<-a
+	<-b
+	select {
+	case <-other:
+		t.Fatal("unexpected notification")
+	default:
+	}
+	// Waiters registered after a notification wait for the next one.
+	c, unregisterC := n.Register("id")
+	select {
+	case <-c:
+		t.Fatal("unexpected notification")
+	default:
+	}
+
+	unregisterA()
+	unregisterB()
+	unregisterC()
+	unregisterC()
+	unregisterOther()
+	require.Empty(t, n.waiters)
+}
+
+func TestNotifier_Wait(t *testing.T) {
+	n := NewNotifier()
+	var completed atomic.Bool
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		completed.Store(true)
+		n.Notify("id")
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	require.NoError(t, n.Wait(ctx, "id", func() (bool, error) { return completed.Load(), nil }))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, n.Wait(ctx, "other", func() (bool, error) { return false, nil }), context.DeadlineExceeded)
+	require.EqualError(t, n.Wait(ctx, "other", func() (bool, error) { return false, errors.New("store unavailable") }), "store unavailable")
+	require.Empty(t, n.waiters)
+}
+
+func TestNotifier_CompletionHandler(t *testing.T) {
+	n := NewNotifier()
+	var fail atomic.Bool
+	handler := n.CompletionHandler(completionHandlerFunc(func(ctx context.Context, request *CompletionRequest) error {
+		if fail.Load() {
+			return errors.New("rejected")
+		}
+		return nil
+	}))
+
+	notified, unregister := n.Register("id")
+	defer unregister()
+	fail.Store(true)
+	require.Error(t, handler.CompleteOperation(context.Background(), &CompletionRequest{OperationID: "id"}))
+	select {
+	case <-notified:
+		t.Fatal("unexpected notification")
+	default:
+	}
+	fail.Store(false)
+	require.NoError(t, handler.CompleteOperation(context.Background(), &CompletionRequest{OperationID: "id"}))
+	<-notified
+}
+
+// pollingStore is an [OperationStore] that does not wake up waiters on updates, like stores that poll for updates.
+type pollingStore struct {
+	*MemoryOperationStore
+}
+
+func (s pollingStore) WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestAsyncHandler_Notifier(t *testing.T) {
+	release := make(chan struct{})
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store:    pollingStore{NewMemoryOperationStore()},
+		Notifier: NewNotifier(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-release
+			return "done", nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := StartOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, StartOperationOptions{})
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	start := time.Now()
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, "done", value)
+	// The long poll was woken up by the notification rather than timing out.
+	require.Less(t, time.Since(start), getResultMaxTimeout)
+}
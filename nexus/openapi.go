@@ -0,0 +1,400 @@
+package nexus
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const openAPIVersion = "3.0.3"
+
+var (
+	noValueType         = reflect.TypeOf(NoValue(nil))
+	byteSliceType       = reflect.TypeOf([]byte(nil))
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	rawMessageType      = reflect.TypeOf(json.RawMessage(nil))
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	operationStateType  = reflect.TypeOf(OperationState(""))
+	operationStateEnums = []OperationState{OperationStateRunning, OperationStateSucceeded, OperationStateFailed, OperationStateCanceled}
+)
+
+// OpenAPIOptions are options for [OperationRegistry.OpenAPI].
+type OpenAPIOptions struct {
+	// Title of the API. Defaults to "Nexus Service".
+	Title string
+	// Version of the API. Defaults to "1.0.0".
+	Version string
+	// Optional description of the API.
+	Description string
+	// URLs the handler is mounted at, e.g. "https://example.com/nexus/my-service".
+	ServerURLs []string
+}
+
+// OpenAPI returns a JSON encoded OpenAPI 3 document describing the routes served by an HTTP handler created for the
+// registry's handler via [NewHTTPHandler].
+//
+// The document describes the start, info, result, and cancel routes of every registered operation along with their
+// error responses. Input and output schemas are derived from the operations' type parameters using the default
+// serializer's rules: byte slices are octet streams, [NoValue] is an empty body, and any other type is JSON, described
+// according to its encoding/json struct tags. Types implementing [json.Marshaler] are described as arbitrary JSON.
+func (r OperationRegistry) OpenAPI(options OpenAPIOptions) ([]byte, error) {
+	if len(r.operations) == 0 {
+		return nil, fmt.Errorf("must register at least one operation")
+	}
+	if options.Title == "" {
+		options.Title = "Nexus Service"
+	}
+	if options.Version == "" {
+		options.Version = "1.0.0"
+	}
+
+	g := &openAPIGenerator{schemas: make(map[string]any), names: make(map[reflect.Type]string)}
+	failureSchema := g.schema(reflect.TypeOf(Failure{}))
+	infoSchema := g.schema(reflect.TypeOf(OperationInfo{}))
+
+	names := make([]string, 0, len(r.operations))
+	for name := range r.operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make(map[string]any)
+	for _, name := range names {
+		op := reflect.TypeOf(r.operations[name])
+		start, ok := op.MethodByName("Start")
+		if !ok {
+			return nil, fmt.Errorf("operation %q does not implement Start", name)
+		}
+		getResult, ok := op.MethodByName("GetResult")
+		if !ok {
+			return nil, fmt.Errorf("operation %q does not implement GetResult", name)
+		}
+		input := g.content(start.Type.In(2))
+		output := g.content(getResult.Type.Out(0))
+
+		base := "/" + name
+		startOp := map[string]any{
+			"operationId": "start-" + name,
+			"summary":     fmt.Sprintf("Start the %q operation", name),
+			"tags":        []string{name},
+			"parameters": []any{
+				openAPIParameter("query", QueryCallbackURL, "URL to deliver the operation's completion to.", map[string]any{"type": "string", "format": "uri"}),
+				openAPIParameter("header", HeaderAdditionalCallbacks, "JSON encoded list of additional callbacks to deliver the operation's completion to, e.g. [{\"url\": \"https://example.com/callback\", \"header\": {\"key\": \"value\"}}].", map[string]any{"type": "string"}),
+				openAPIParameter("header", HeaderLinks, "JSON encoded list of links to resources related to the operation, e.g. [{\"url\": \"https://example.com/service/operation/id\", \"rel\": \"retry-of\"}].", map[string]any{"type": "string"}),
+				openAPIParameter("header", HeaderRequestID, "Request ID used for deduplicating start requests.", map[string]any{"type": "string"}),
+				openAPIParameter("header", HeaderOperationDeadline, "Time by which the operation must complete.", map[string]any{"type": "string", "format": "date-time"}),
+				openAPIParameter("header", HeaderOperationStartTime, "Time before which the operation should not begin executing.", map[string]any{"type": "string", "format": "date-time"}),
+				openAPIParameter("header", HeaderOperationStartDelay, "Delay before the operation should begin executing, e.g. \"60000ms\".", map[string]any{"type": "string"}),
+				openAPIParameter("header", HeaderRequestTimeout, "Request timeout, e.g. \"10s\".", map[string]any{"type": "string"}),
+			},
+			"responses": g.responses(failureSchema, map[int]any{
+				http.StatusOK:      openAPIResponse("Operation completed synchronously.", output),
+				http.StatusCreated: openAPIResponse("Operation started asynchronously.", openAPIJSON(infoSchema)),
+				http.StatusConflict: map[string]any{
+					"description": "Operation already started.",
+					"headers": map[string]any{
+						HeaderOperationID: map[string]any{"description": "ID of the existing operation.", "schema": map[string]any{"type": "string"}},
+					},
+				},
+			}, StatusOperationFailed, http.StatusTooManyRequests),
+		}
+		if input != nil {
+			startOp["requestBody"] = map[string]any{"required": true, "content": input}
+		}
+		paths[base] = map[string]any{"post": startOp}
+
+		idParam := map[string]any{
+			"name":     "operation_id",
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		}
+		paths[base+"/{operation_id}"] = map[string]any{
+			"parameters": []any{idParam},
+			"get": map[string]any{
+				"operationId": "get-info-" + name,
+				"summary":     fmt.Sprintf("Get information about a %q operation", name),
+				"tags":        []string{name},
+				"responses": g.responses(failureSchema, map[int]any{
+					http.StatusOK: openAPIResponse("Operation information.", openAPIJSON(infoSchema)),
+				}),
+			},
+		}
+		paths[base+"/{operation_id}/result"] = map[string]any{
+			"parameters": []any{idParam},
+			"get": map[string]any{
+				"operationId": "get-result-" + name,
+				"summary":     fmt.Sprintf("Get the result of a %q operation", name),
+				"tags":        []string{name},
+				"parameters": []any{
+					openAPIParameter("query", QueryWait, "Duration to wait for the operation to complete, e.g. \"10s\".", map[string]any{"type": "string"}),
+					openAPIParameter("query", QueryPageToken, "Token of the page of a paged result to get.", map[string]any{"type": "string"}),
+				},
+				"responses": g.responses(failureSchema, map[int]any{
+					http.StatusOK:             openAPIResponse("Operation succeeded.", output),
+					http.StatusRequestTimeout: map[string]any{"description": "Operation did not complete within the wait duration."},
+					StatusOperationRunning:    map[string]any{"description": "Operation is still running."},
+					http.StatusGone:           map[string]any{"description": "Operation result is no longer retained."},
+				}, StatusOperationFailed),
+			},
+		}
+		paths[base+"/{operation_id}/cancel"] = map[string]any{
+			"parameters": []any{idParam},
+			"post": map[string]any{
+				"operationId": "cancel-" + name,
+				"summary":     fmt.Sprintf("Cancel a %q operation", name),
+				"tags":        []string{name},
+				"parameters": []any{
+					openAPIParameter("header", HeaderRequestID, "Request ID used for deduplicating cancel requests.", map[string]any{"type": "string"}),
+					openAPIParameter("header", HeaderCancelMode, "Whether to abort the operation immediately or let it stop gracefully.", map[string]any{
+						"type": "string",
+						"enum": []CancelMode{CancelModeForce, CancelModeGraceful},
+					}),
+				},
+				"requestBody": map[string]any{
+					"description": "Optional reason for canceling the operation.",
+					"content":     openAPIJSON(failureSchema),
+				},
+				"responses": g.responses(failureSchema, map[int]any{
+					http.StatusAccepted: map[string]any{"description": "Cancelation requested."},
+				}),
+			},
+		}
+	}
+
+	info := map[string]any{
+		"title":   options.Title,
+		"version": options.Version,
+	}
+	if options.Description != "" {
+		info["description"] = options.Description
+	}
+	doc := map[string]any{
+		"openapi":    openAPIVersion,
+		"info":       info,
+		"paths":      paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}
+	if len(options.ServerURLs) > 0 {
+		servers := make([]any, len(options.ServerURLs))
+		for i, url := range options.ServerURLs {
+			servers[i] = map[string]any{"url": url}
+		}
+		doc["servers"] = servers
+	}
+	return json.Marshal(doc)
+}
+
+// openAPIGenerator derives OpenAPI schemas from Go types, collecting named struct types as component schemas.
+type openAPIGenerator struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+// content returns the media type map for values of the given type as encoded by the default serializer, or nil for
+// empty bodies.
+func (g *openAPIGenerator) content(t reflect.Type) map[string]any {
+	switch {
+	case t == noValueType:
+		return nil
+	case t == byteSliceType:
+		return map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+	case t.Kind() == reflect.Interface:
+		return map[string]any{
+			contentTypeJSON:            map[string]any{"schema": map[string]any{}},
+			"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}
+	}
+	return openAPIJSON(g.schema(t))
+}
+
+// responses returns the given success responses along with the error responses written by the HTTP handler.
+// Additional status codes that respond with a [Failure] may be specified.
+func (g *openAPIGenerator) responses(failureSchema any, success map[int]any, failureStatuses ...int) map[string]any {
+	responses := make(map[string]any, len(success)+len(failureStatuses)+4)
+	for status, response := range success {
+		responses[strconv.Itoa(status)] = response
+	}
+	failure := openAPIJSON(failureSchema)
+	for _, status := range failureStatuses {
+		switch status {
+		case StatusOperationFailed:
+			responses[strconv.Itoa(status)] = map[string]any{
+				"description": "Operation completed as failed or canceled.",
+				"headers": map[string]any{
+					HeaderOperationState: map[string]any{"schema": g.schema(operationStateType)},
+				},
+				"content": failure,
+			}
+		case http.StatusTooManyRequests:
+			responses[strconv.Itoa(status)] = openAPIResponse("Quota or resource exhausted.", failure)
+		}
+	}
+	responses["400"] = openAPIResponse("Bad request.", failure)
+	responses["401"] = openAPIResponse("Unauthenticated.", failure)
+	responses["403"] = openAPIResponse("Unauthorized.", failure)
+	responses["404"] = openAPIResponse("Operation not found.", failure)
+	responses["default"] = openAPIResponse("Handler error.", failure)
+	return responses
+}
+
+// schema returns the schema for the given type, registering component schemas for named struct types.
+func (g *openAPIGenerator) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "format": "int64", "description": "Duration in nanoseconds."}
+	case rawMessageType:
+		return map[string]any{}
+	case operationStateType:
+		return map[string]any{"type": "string", "enum": operationStateEnums}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return map[string]any{}
+	}
+	if t.Kind() != reflect.String && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if _, ok := s["$ref"]; ok {
+			// Siblings of $ref are ignored in OpenAPI 3.0.
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			g.names[t] = name
+			// Register a placeholder first to support recursive types.
+			g.schemas[name] = map[string]any{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	// Interfaces and any other kinds may hold arbitrary JSON.
+	return map[string]any{}
+}
+
+// componentName returns a unique component schema name for a named type.
+func (g *openAPIGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		// Generic type instantiations include their type arguments, which are not valid in component names.
+		name = name[:i]
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[candidate]; !taken {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
+
+// structSchema returns an object schema for a struct type following the encoding/json field rules.
+func (g *openAPIGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required)
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *openAPIGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(fieldType, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := properties[name]; exists {
+			continue
+		}
+		s := g.schema(fieldType)
+		if strings.Contains(opts, "string") {
+			s = map[string]any{"type": "string"}
+		}
+		properties[name] = s
+		if !strings.Contains(opts, "omitempty") && fieldType.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func openAPIJSON(schema any) map[string]any {
+	return map[string]any{contentTypeJSON: map[string]any{"schema": schema}}
+}
+
+func openAPIResponse(description string, content map[string]any) map[string]any {
+	response := map[string]any{"description": description}
+	if content != nil {
+		response["content"] = content
+	}
+	return response
+}
+
+func openAPIParameter(in, name, description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"schema":      schema,
+	}
+}
@@ -0,0 +1,95 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type openAPITestInput struct {
+	Name     string            `json:"name"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Parent   *openAPITestInput `json:"parent,omitempty"`
+	Created  time.Time         `json:"created"`
+	internal int               //nolint:unused
+	Ignored  string            `json:"-"`
+}
+
+type openAPITestOutput struct {
+	Greetings []string `json:"greetings"`
+}
+
+func TestOperationRegistry_OpenAPI(t *testing.T) {
+	registry := OperationRegistry{}
+	require.NoError(t, registry.Register(
+		NewSyncOperation("greet", func(ctx context.Context, input openAPITestInput, options StartOperationOptions) (openAPITestOutput, error) {
+			return openAPITestOutput{}, nil
+		}),
+		NewSyncOperation("upload", func(ctx context.Context, input []byte, options StartOperationOptions) (NoValue, error) {
+			return nil, nil
+		}),
+	))
+
+	data, err := registry.OpenAPI(OpenAPIOptions{Title: "Greeter", ServerURLs: []string{"https://example.com/greeter"}})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	require.Equal(t, "3.0.3", doc["openapi"])
+	require.Equal(t, map[string]any{"title": "Greeter", "version": "1.0.0"}, doc["info"])
+	require.Equal(t, []any{map[string]any{"url": "https://example.com/greeter"}}, doc["servers"])
+
+	paths := doc["paths"].(map[string]any)
+	require.ElementsMatch(t, []string{
+		"/greet", "/greet/{operation_id}", "/greet/{operation_id}/result", "/greet/{operation_id}/cancel",
+		"/upload", "/upload/{operation_id}", "/upload/{operation_id}/result", "/upload/{operation_id}/cancel",
+	}, keys(paths))
+
+	start := paths["/greet"].(map[string]any)["post"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/openAPITestInput"}},
+	}, start["requestBody"].(map[string]any)["content"])
+	responses := start["responses"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/openAPITestOutput"}},
+	}, responses["200"].(map[string]any)["content"])
+	require.Contains(t, responses, "201")
+	require.Contains(t, responses, "424")
+	require.Contains(t, responses, "429")
+	require.Contains(t, responses, "default")
+
+	upload := paths["/upload"].(map[string]any)["post"].(map[string]any)
+	require.Contains(t, upload["requestBody"].(map[string]any)["content"], "application/octet-stream")
+	require.NotContains(t, upload["responses"].(map[string]any)["200"], "content")
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	require.ElementsMatch(t, []string{"Failure", "Link", "OperationAttempt", "OperationInfo", "OperationQuarantine", "openAPITestInput", "openAPITestOutput"}, keys(schemas))
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string"},
+			"tags":    map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"parent":  map[string]any{"allOf": []any{map[string]any{"$ref": "#/components/schemas/openAPITestInput"}}, "nullable": true},
+			"created": map[string]any{"type": "string", "format": "date-time"},
+		},
+		"required": []any{"created", "name"},
+	}, schemas["openAPITestInput"])
+	require.Equal(t, []any{"running", "succeeded", "failed", "canceled"},
+		schemas["OperationInfo"].(map[string]any)["properties"].(map[string]any)["state"].(map[string]any)["enum"])
+}
+
+func TestOperationRegistry_OpenAPI_Empty(t *testing.T) {
+	_, err := OperationRegistry{}.OpenAPI(OpenAPIOptions{})
+	require.ErrorContains(t, err, "must register at least one operation")
+}
+
+func keys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
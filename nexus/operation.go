@@ -83,6 +83,10 @@ type Operation[I, O any] interface {
 	//  ignored by the underlying operation implemention.
 	//  2. idempotent - implementors should ignore duplicate cancelations for the same operation.
 	Cancel(context.Context, string, CancelOperationOptions) error
+	// Watch handles requests to stream state changes of an asynchronous operation.
+	// The returned channel should emit the operation's current info followed by an info for every state transition,
+	// and be closed once the operation reaches a terminal state or the context is done.
+	Watch(context.Context, string, WatchOperationOptions) (<-chan *OperationInfo, error)
 }
 
 type syncOperation[I, O any] struct {
@@ -100,6 +104,24 @@ func NewSyncOperation[I, O any](name string, handler func(context.Context, I, St
 	}
 }
 
+// NewSyncOperationFromReference creates a synchronous-only [Operation] implementing the given reference.
+//
+// Define references in a package shared by callers and handlers to ensure at compile time that the operation name and
+// its input and output types do not drift between them:
+//
+//	var MyOperation = nexus.NewOperationReference[MyInput, MyOutput]("my-operation")
+//
+//	// Handler
+//	op := nexus.NewSyncOperationFromReference(MyOperation, func(ctx context.Context, input MyInput, options nexus.StartOperationOptions) (MyOutput, error) {
+//		return MyOutput{}, nil
+//	})
+//
+//	// Caller
+//	result, err := nexus.StartOperation(ctx, client, MyOperation, MyInput{}, options)
+func NewSyncOperationFromReference[I, O any](ref OperationReference[I, O], handler func(context.Context, I, StartOperationOptions) (O, error)) Operation[I, O] {
+	return NewSyncOperation(ref.Name(), handler)
+}
+
 // Name implements Operation.
 func (h *syncOperation[I, O]) Name() string {
 	return h.name
@@ -205,6 +227,22 @@ func (r *registryHandler) GetOperationResult(ctx context.Context, operation stri
 	return ret, nil
 }
 
+// WatchOperation implements Handler.
+func (r *registryHandler) WatchOperation(ctx context.Context, operation string, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error) {
+	h, ok := r.operations[operation]
+	if !ok {
+		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
+	}
+
+	m, _ := reflect.TypeOf(h).MethodByName("Watch")
+	values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), reflect.ValueOf(operationID), reflect.ValueOf(options)})
+	if !values[1].IsNil() {
+		return nil, values[1].Interface().(error)
+	}
+	ret := values[0].Interface()
+	return ret.(<-chan *OperationInfo), nil
+}
+
 // StartOperation implements Handler.
 func (r *registryHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
 	h, ok := r.operations[operation]
@@ -237,9 +275,9 @@ var _ Handler = &registryHandler{}
 //
 //	ref := NewOperationReference[MyInput, MyOutput]("my-operation")
 //	out, err := ExecuteOperation(ctx, client, ref, MyInput{}, options) // returns MyOutput, error
-func ExecuteOperation[I, O any](ctx context.Context, client *Client, operation OperationReference[I, O], input I, request ExecuteOperationOptions) (O, error) {
+func ExecuteOperation[I, O any](ctx context.Context, client *Client, operation OperationReference[I, O], input I, request ExecuteOperationOptions, opts ...CallOption) (O, error) {
 	var o O
-	value, err := client.ExecuteOperation(ctx, operation.Name(), input, request)
+	value, err := client.ExecuteOperation(ctx, operation.Name(), input, request, opts...)
 	if err != nil {
 		return o, err
 	}
@@ -249,8 +287,8 @@ func ExecuteOperation[I, O any](ctx context.Context, client *Client, operation O
 // StartOperation is the type safe version of [Client.StartOperation].
 // It accepts input of type I and returns a [ClientStartOperationResult] of type O, removing the need to consume the
 // [LazyValue] returned by the client method.
-func StartOperation[I, O any](ctx context.Context, client *Client, operation OperationReference[I, O], input I, request StartOperationOptions) (*ClientStartOperationResult[O], error) {
-	result, err := client.StartOperation(ctx, operation.Name(), input, request)
+func StartOperation[I, O any](ctx context.Context, client *Client, operation OperationReference[I, O], input I, request StartOperationOptions, opts ...CallOption) (*ClientStartOperationResult[O], error) {
+	result, err := client.StartOperation(ctx, operation.Name(), input, request, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -261,8 +299,8 @@ func StartOperation[I, O any](ctx context.Context, client *Client, operation Ope
 		}
 		return &ClientStartOperationResult[O]{Successful: o}, nil
 	}
-	handle := OperationHandle[O]{client: client, Operation: operation.Name(), ID: result.Pending.ID}
-	return &ClientStartOperationResult[O]{Pending: &handle}, nil
+	handle := OperationHandle[O]{client: result.Pending.client, Operation: operation.Name(), ID: result.Pending.ID, Links: result.Pending.Links, input: result.Pending.input, polls: result.Pending.polls}
+	return &ClientStartOperationResult[O]{Pending: &handle, AlreadyStarted: result.AlreadyStarted}, nil
 }
 
 // NewHandle is the type safe version of [Client.NewHandle].
@@ -271,5 +309,5 @@ func NewHandle[I, O any](client *Client, operation OperationReference[I, O], ope
 	if operationID == "" {
 		return nil, errEmptyOperationID
 	}
-	return &OperationHandle[O]{client: client, Operation: operation.Name(), ID: operationID}, nil
+	return &OperationHandle[O]{client: client, Operation: operation.Name(), ID: operationID, polls: newLongPolls()}, nil
 }
@@ -169,3 +169,21 @@ func TestGetOperationInfo(t *testing.T) {
 	var unexpectedError *UnexpectedResponseError
 	require.ErrorAs(t, err, &unexpectedError)
 }
+
+func TestSyncOperationFromReference(t *testing.T) {
+	ref := NewOperationReference[string, int]("length")
+	registry := OperationRegistry{}
+	require.NoError(t, registry.Register(NewSyncOperationFromReference(ref, func(ctx context.Context, input string, options StartOperationOptions) (int, error) {
+		return len(input), nil
+	})))
+
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := StartOperation(ctx, client, ref, "hello", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Successful)
+}
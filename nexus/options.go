@@ -1,6 +1,7 @@
 package nexus
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -23,9 +24,61 @@ type StartOperationOptions struct {
 	// Optional header fields set by a client that are required to be attached to the callback request when an
 	// asynchronous operation completes.
 	CallbackHeader Header
+	// Optional callbacks to deliver the completion of an async operation to in addition to CallbackURL, for when
+	// several downstream systems need to observe the same completion. Handlers that support completion delivery,
+	// such as an [AsyncHandler] with [AsyncHandlerOptions.CallbackDelivery], deliver to each callback independently.
+	AdditionalCallbacks []Callback
 	// Request ID that may be used by the server handler to dedupe a start request.
 	// By default a v4 UUID will be generated by the client.
 	RequestID string
+	// Optional links to resources related to the operation supplied by the caller, e.g. the operation it reruns, see
+	// [RetryOperation]. Handlers that support links, such as the [AsyncHandler], report them in [OperationInfo.Links].
+	// Links are transmitted in the [HeaderLinks] header.
+	Links []Link
+	// Optional key/value tags to attach to the operation, e.g. for filtering in [Client.ListOperations].
+	// Tags are transmitted as "Nexus-Tag-" prefixed headers; keys are case-insensitive and normalized to lower case.
+	Tags map[string]string
+	// Optional time by which the operation must complete, after which handlers should fail it. Unlike the context
+	// deadline, which bounds a single request, the operation deadline bounds the operation's entire execution.
+	Deadline time.Time
+	// Optional time before which the operation should not begin executing, e.g. to run it at a scheduled time without
+	// an external scheduler. Handlers that do not support delayed starts may ignore it, see [WaitForStartTime] for
+	// implementing it.
+	//
+	// In server methods, StartTime is the later of the requested start time and the time the request was received
+	// plus StartDelay.
+	StartTime time.Time
+	// Optional delay before the operation should begin executing, e.g. to run it in an hour. Unlike StartTime, the
+	// delay is relative to the time the handler receives the request and is unaffected by clock skew between the
+	// caller and the handler. See StartTime for how handlers apply it.
+	StartDelay time.Duration
+	// Optional name of the service to start the operation in, overriding [PathBuilder.Service] for a call, so a
+	// single client can start operations in the services served at the same endpoint with a [ClientOptions.PathBuilder]
+	// layout containing the {service} variable. The handle of the started operation sends its requests to the same
+	// service. Only used in the client API.
+	Service string
+	// Optional escaped path appended to the client's service base URL for a call, e.g. "/billing", so a single client
+	// can start operations in services served under different base paths of the same endpoint. The handle of the
+	// started operation sends its requests to the same base path. Only used in the client API.
+	ServiceBasePath string
+}
+
+// A Callback is a URL an operation's completion is delivered to, see [NewCompletionHTTPRequest].
+type Callback struct {
+	// URL to deliver the completion to.
+	URL string `json:"url"`
+	// Optional header fields required to be attached to the completion request.
+	Header Header `json:"header,omitempty"`
+}
+
+// Callbacks returns all callbacks the completion of the started operation should be delivered to: the callback
+// specified by CallbackURL and CallbackHeader, if set, followed by AdditionalCallbacks.
+func (o StartOperationOptions) Callbacks() []Callback {
+	var callbacks []Callback
+	if o.CallbackURL != "" {
+		callbacks = append(callbacks, Callback{URL: o.CallbackURL, Header: o.CallbackHeader})
+	}
+	return append(callbacks, o.AdditionalCallbacks...)
 }
 
 // GetOperationResultOptions are options for the GetOperationResult client and server APIs.
@@ -39,6 +92,49 @@ type GetOperationResultOptions struct {
 	// If non-zero, reflects the duration the caller has indicated that it wants to wait for operation completion,
 	// turning the request into a long poll.
 	Wait time.Duration
+	// Reflects whether the caller accepts a [ResultRedirect] in response.
+	// Only populated in server methods, see [ClientOptions.FollowResultRedirects] for the client counterpart.
+	AcceptRedirect bool
+	// Reflects whether the caller accepts a [ResultPage] in response.
+	// Only populated in server methods, see [OperationHandle.GetResultPages] for the client counterpart.
+	AcceptPages bool
+	// Token of the page of a paged result to get, empty for the first page. In the client, sets the page
+	// [OperationHandle.GetResultPages] starts from, e.g. to resume iteration.
+	PageToken string
+	// Issue exactly one request instead of long polling until Wait elapses, for callers that schedule polls
+	// themselves. The handler may end the long poll before Wait elapses, in which case [ErrOperationWaitTimeout] is
+	// returned, while [ErrOperationStillRunning] is returned if Wait is zero and the operation is running.
+	// Only used by the client.
+	SinglePoll bool
+}
+
+// ClaimOperationResultOptions are options for the ClaimOperationResult client and server APIs.
+type ClaimOperationResultOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Requested duration of the claim. A claimed result that is not acknowledged before the lease expires becomes
+	// available to be claimed again. If zero, the handler picks a default.
+	Lease time.Duration
+	// Name of the consumer group to claim the result for. Each group receives the result once, allowing multiple
+	// independent consumers to process the same result. Defaults to the empty string.
+	Group string
+}
+
+// AckOperationResultOptions are options for the AckOperationResult client and server APIs.
+type AckOperationResultOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Name of the consumer group the claim was made for.
+	// The client sets this automatically from the acknowledged [ResultClaim].
+	Group string
 }
 
 // GetOperationInfoOptions are options for the GetOperationInfo client and server APIs.
@@ -59,4 +155,48 @@ type CancelOperationOptions struct {
 	//
 	// Header values set here will overwrite any SDK-provided values for the same key.
 	Header Header
+	// Request ID that may be used by the server handler to dedupe a retried cancel request.
+	// By default a v4 UUID will be generated by the client.
+	RequestID string
+	// Optional reason for canceling the operation, e.g. &Failure{Message: "order withdrawn by customer"}, sent as the
+	// JSON body of the cancel request. The [AsyncHandler] records it as the cause of the operation's canceled failure.
+	Reason *Failure
+	// Whether the operation should be aborted immediately or stop gracefully, see [CancelMode]. Handlers receive the
+	// empty mode if the client did not specify one, which implementations should treat like [CancelModeForce].
+	Mode CancelMode
+	// Abort in-flight long polls for the result of the operation on the same handle, and handles derived from it, once
+	// the cancel request is accepted. Aborted polls fail with [ErrLongPollAborted]. Only used by the client.
+	AbortLongPolls bool
+}
+
+// WatchOperationOptions are options for the WatchOperation client and server APIs.
+type WatchOperationOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+}
+
+// GetQuotaUsageOptions are options for the GetQuotaUsage client and server APIs.
+type GetQuotaUsageOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+}
+
+// HeartbeatOperationOptions are options for the HeartbeatOperation client and server APIs.
+type HeartbeatOperationOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Optional JSON encoded progress details, reported in [OperationInfo.HeartbeatDetails].
+	Details json.RawMessage
 }
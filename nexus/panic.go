@@ -0,0 +1,56 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"unicode"
+)
+
+// Maximum length of a panic value exposed in failures, see [HandlerOptions.ExposePanicDetails].
+const maxPanicDetailsLength = 256
+
+// recoverPanic recovers a panic of the handler invocation it is deferred around. The panic is logged with its stack and
+// reported to the configured OnPanic function, then the request is failed with a 500 [HandlerError]. If the response was
+// already started, e.g. by a watch stream, the connection is aborted instead.
+//
+// Panics with [http.ErrAbortHandler] are propagated, since they are meant to abort the request.
+func (h *baseHTTPHandler) recoverPanic(ctx context.Context, writer http.ResponseWriter) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		panic(r)
+	}
+	stack := debug.Stack()
+	h.logger.Error("handler panicked", "panic", r, "stack", string(stack))
+	if h.onPanic != nil {
+		h.onPanic(ctx, r, stack)
+	}
+	if recorder, ok := writer.(*statusRecorder); ok && recorder.status != 0 {
+		panic(http.ErrAbortHandler)
+	}
+	message := "internal server error"
+	if h.exposePanicDetails {
+		message = "handler panicked: " + sanitizePanicValue(r)
+	}
+	h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeInternal, "%s", message))
+}
+
+// sanitizePanicValue formats a panic value for a failure message, replacing control characters and truncating it.
+func sanitizePanicValue(r any) string {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, strings.ToValidUTF8(fmt.Sprint(r), ""))
+	if len(s) > maxPanicDetailsLength {
+		s = strings.ToValidUTF8(s[:maxPanicDetailsLength], "") + "..."
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type panickingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *panickingHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	panic("boom\nsecret")
+}
+
+type recordedPanic struct {
+	info  HandlerInfo
+	value any
+	stack []byte
+}
+
+func TestPanicRecovery(t *testing.T) {
+	panics := make(chan recordedPanic, 1)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &panickingHandler{},
+		OnPanic: func(ctx context.Context, value any, stack []byte) {
+			info, _ := HandlerInfoFromContext(ctx)
+			panics <- recordedPanic{info, value, stack}
+		},
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedError.Response.StatusCode)
+	require.Equal(t, "internal server error", unexpectedError.Failure.Message)
+
+	recorded := <-panics
+	require.Equal(t, "boom\nsecret", recorded.value)
+	require.Contains(t, string(recorded.stack), "panickingHandler")
+	require.Equal(t, HandlerMethodStartOperation, recorded.info.Method)
+
+	// The server keeps serving requests.
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedError)
+}
+
+func TestPanicRecovery_ExposeDetails(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:            &panickingHandler{},
+		ExposePanicDetails: true,
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, "handler panicked: boom secret", unexpectedError.Failure.Message)
+}
+
+type panickingCompletionHandler struct{}
+
+func (h *panickingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	panic("boom")
+}
+
+func TestPanicRecovery_Completion(t *testing.T) {
+	panics := make(chan any, 1)
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler: &panickingCompletionHandler{},
+		OnPanic: func(ctx context.Context, value any, stack []byte) {
+			panics <- value
+		},
+	}))
+	defer server.Close()
+
+	completion, err := NewOperationCompletionSuccessful("result", OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	require.Equal(t, "boom", <-panics)
+}
+
+func TestSanitizePanicValue(t *testing.T) {
+	require.Equal(t, "a b", sanitizePanicValue("a\tb"))
+	require.Equal(t, strings.Repeat("x", maxPanicDetailsLength)+"...", sanitizePanicValue(strings.Repeat("x", 1000)))
+}
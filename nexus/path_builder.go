@@ -0,0 +1,180 @@
+package nexus
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A PathBuilder lays out the URL paths of a service's endpoints relative to the service base URL, letting handlers and
+// clients sit behind gateways with existing path conventions. Set the same PathBuilder in
+// [HandlerOptions.PathBuilder] and [ClientOptions.PathBuilder]. The zero value is the default layout:
+//
+//	/{operation}                            start operation
+//	/{operation}/{operation_id}             get operation info
+//	/{operation}/{operation_id}/result      get operation result, claim and ack under result/claim and result/ack
+//	/{operation}/{operation_id}/cancel      cancel operation
+//	/{operation}/{operation_id}/events      watch operation
+//	/{operation}/{operation_id}/heartbeat   heartbeat operation
+//	/                                       list operations
+//	/_nexus                                 ping
+//	/_admin/...                             administrative requests
+//
+// The operation names "_admin" and "_nexus" are reserved so that operations can't be shadowed by these routes.
+//
+// Templates may contain the {service} variable, which is substituted with the Service name, e.g.
+//
+//	nexus.PathBuilder{
+//		Prefix:    "/gateway",
+//		Root:      "/services/{service}",
+//		Operation: "/services/{service}/operations/{operation}",
+//	}
+type PathBuilder struct {
+	// Optional prefix of all paths, e.g. "/api/v1".
+	Prefix string
+	// Template of the service root path relative to Prefix, where operations are listed and under which
+	// administrative requests are served at "_admin". Defaults to "/".
+	Root string
+	// Template of operation paths relative to Prefix, containing the {operation} variable. Defaults to "/{operation}".
+	Operation string
+	// Template of operation instance paths relative to Prefix, containing the {operation} and {operation_id}
+	// variables. The paths of the result, cancel, events and heartbeat endpoints are appended to it. Defaults to
+	// Operation followed by "/{operation_id}".
+	OperationInstance string
+	// Name of the service substituted for the {service} variable. Defaults to [HandlerOptions.Service] in handlers
+	// and [ClientOptions.ServiceName] in clients.
+	Service string
+}
+
+var pathTemplateVariable = regexp.MustCompile(`\{[^}]*\}`)
+
+// reservedOperationNames are the root path segments under which requests other than operation requests are served.
+var reservedOperationNames = []string{"_admin", "_nexus"}
+
+// checkOperationName fails if the given operation name is reserved, see [PathBuilder].
+func checkOperationName(operation string) error {
+	if slices.Contains(reservedOperationNames, operation) {
+		return fmt.Errorf("%w: %q", errReservedOperationName, operation)
+	}
+	return nil
+}
+
+// withDefaults returns the builder with defaults applied and its prefix normalized.
+func (b PathBuilder) withDefaults(service string) PathBuilder {
+	b.Prefix = strings.TrimSuffix(b.Prefix, "/")
+	if b.Prefix != "" && !strings.HasPrefix(b.Prefix, "/") {
+		b.Prefix = "/" + b.Prefix
+	}
+	if b.Root == "" {
+		b.Root = "/"
+	}
+	if b.Operation == "" {
+		b.Operation = "/{operation}"
+	}
+	if b.OperationInstance == "" {
+		b.OperationInstance = strings.TrimSuffix(b.Operation, "/") + "/{operation_id}"
+	}
+	if b.Service == "" {
+		b.Service = service
+	}
+	return b
+}
+
+// validate checks that every template contains exactly the variables required for it.
+func (b PathBuilder) validate() error {
+	for _, t := range []struct {
+		name, template string
+		variables      []string
+	}{
+		{"Root", b.Root, nil},
+		{"Operation", b.Operation, []string{"{operation}"}},
+		{"OperationInstance", b.OperationInstance, []string{"{operation}", "{operation_id}"}},
+	} {
+		for _, variable := range pathTemplateVariable.FindAllString(t.template, -1) {
+			if variable == "{service}" {
+				if b.Service == "" {
+					return fmt.Errorf("invalid %s path template %q: service name is empty", t.name, t.template)
+				}
+				continue
+			}
+			if !slices.Contains(t.variables, variable) {
+				return fmt.Errorf("invalid %s path template %q: unexpected variable %s", t.name, t.template, variable)
+			}
+		}
+		for _, variable := range t.variables {
+			if strings.Count(t.template, variable) != 1 {
+				return fmt.Errorf("invalid %s path template %q: must contain %s once", t.name, t.template, variable)
+			}
+		}
+	}
+	return nil
+}
+
+// hasServiceVariable reports whether any template contains the {service} variable.
+func (b PathBuilder) hasServiceVariable() bool {
+	return strings.Contains(b.Root+b.Operation+b.OperationInstance, "{service}")
+}
+
+// route returns the router path template of the given template followed by the given path elements.
+func (b PathBuilder) route(template string, elem ...string) string {
+	template = strings.ReplaceAll(template, "{service}", url.PathEscape(b.Service))
+	return joinEscapedPath(b.Prefix+template, elem...)
+}
+
+// path returns the escaped path of the given template followed by the given path elements, substituting the given
+// operation and operation ID.
+func (b PathBuilder) path(template, operation, operationID string, elem ...string) string {
+	template = strings.NewReplacer(
+		"{service}", url.PathEscape(b.Service),
+		"{operation}", url.PathEscape(operation),
+		"{operation_id}", url.PathEscape(operationID),
+	).Replace(template)
+	return joinEscapedPath(b.Prefix+template, elem...)
+}
+
+// rootURL returns the URL of the service root under the given base URL followed by the given path elements.
+func (b PathBuilder) rootURL(base *url.URL, elem ...string) *url.URL {
+	return base.JoinPath(b.path(b.Root, "", "", elem...))
+}
+
+// operationURL returns the URL of an operation under the given base URL.
+func (b PathBuilder) operationURL(base *url.URL, operation string) *url.URL {
+	return base.JoinPath(b.path(b.Operation, operation, ""))
+}
+
+// operationInstanceURL returns the URL of an operation instance under the given base URL followed by the given path
+// elements.
+func (b PathBuilder) operationInstanceURL(base *url.URL, operation, operationID string, elem ...string) *url.URL {
+	return base.JoinPath(b.path(b.OperationInstance, operation, operationID, elem...))
+}
+
+// joinEscapedPath joins an escaped path and path elements with slashes, preserving a trailing slash of the path if
+// there are no elements.
+func joinEscapedPath(p string, elem ...string) string {
+	if p == "" {
+		p = "/"
+	}
+	if len(elem) == 0 {
+		return p
+	}
+	return strings.TrimSuffix(p, "/") + "/" + strings.Join(elem, "/")
+}
+
+// pathVar returns the unescaped value of a variable of the route a request was matched to.
+func pathVar(request *http.Request, name string) (string, error) {
+	return url.PathUnescape(routeVars(request)[name])
+}
+
+// operationPathVars returns the unescaped operation and operation ID of the route a request was matched to.
+func operationPathVars(request *http.Request) (operation, operationID string, err error) {
+	if operation, err = pathVar(request, "operation"); err != nil {
+		return "", "", err
+	}
+	if operationID, err = pathVar(request, "operation_id"); err != nil {
+		return "", "", err
+	}
+	return operation, operationID, nil
+}
@@ -0,0 +1,92 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathBuilder_CustomLayout(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	paths := PathBuilder{
+		Prefix:    "/gateway/",
+		Root:      "/services/{service}",
+		Operation: "/services/{service}/operations/{operation}",
+	}
+	var mu sync.Mutex
+	var requested []string
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          handler,
+		Service:          "billing",
+		PathBuilder:      paths,
+		StrictValidation: true,
+	}, ClientOptions{
+		PathBuilder: PathBuilder{
+			Prefix:    paths.Prefix,
+			Root:      paths.Root,
+			Operation: paths.Operation,
+			Service:   "billing",
+		},
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			mu.Lock()
+			requested = append(requested, request.Method+" "+request.URL.EscapedPath())
+			mu.Unlock()
+			return http.DefaultClient.Do(request)
+		},
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "charge/card", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	list, err := client.ListOperations(ctx, ListOperationsOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 1)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+
+	instance := "/gateway/services/billing/operations/charge%2Fcard/" + handle.ID
+	require.Equal(t, []string{
+		"POST /gateway/services/billing/operations/charge%2Fcard",
+		"GET " + instance,
+		"GET /gateway/services/billing",
+		"POST " + instance + "/cancel",
+		"GET " + instance + "/result",
+	}, requested)
+
+	// Clients with the default layout do not reach the handler.
+	other, err := NewClient(ClientOptions{ServiceBaseURL: client.options.ServiceBaseURL})
+	require.NoError(t, err)
+	_, err = other.StartOperation(ctx, "charge", nil, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestPathBuilder_Validation(t *testing.T) {
+	_, err := NewClient(ClientOptions{ServiceBaseURL: "http://localhost", PathBuilder: PathBuilder{Operation: "/operations"}})
+	require.EqualError(t, err, `invalid Operation path template "/operations": must contain {operation} once`)
+	_, err = NewClient(ClientOptions{ServiceBaseURL: "http://localhost", PathBuilder: PathBuilder{Root: "/services/{service}"}})
+	require.EqualError(t, err, `invalid Root path template "/services/{service}": service name is empty`)
+	_, err = NewClient(ClientOptions{ServiceBaseURL: "http://localhost", PathBuilder: PathBuilder{OperationInstance: "/{operation}/{id}"}})
+	require.EqualError(t, err, `invalid OperationInstance path template "/{operation}/{id}": unexpected variable {id}`)
+	require.Panics(t, func() {
+		NewHTTPHandler(HandlerOptions{Handler: UnimplementedHandler{}, PathBuilder: PathBuilder{Root: "/{service}"}})
+	})
+}
@@ -0,0 +1,80 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrPayloadNotFound is returned from [PayloadBackend.Get] when no payload is stored under the given key.
+var ErrPayloadNotFound = errors.New("payload not found")
+
+// A PayloadBackend stores large payloads outside of an [OperationStore], e.g. in an object store such as S3.
+//
+// Set [AsyncHandlerOptions.PayloadBackend] to offload results larger than a threshold, keeping the metadata store
+// small. See the s3payload package for an S3 compatible implementation.
+//
+// Implementations must be safe for concurrent use.
+type PayloadBackend interface {
+	// Put stores the given content under key, replacing any existing payload.
+	Put(ctx context.Context, key string, content *Content) error
+	// Get returns a reader for the payload stored under key. The returned reader must be closed by the caller.
+	// Returns [ErrPayloadNotFound] if no payload is stored under key.
+	Get(ctx context.Context, key string) (*Reader, error)
+}
+
+// A PayloadPresigner is a [PayloadBackend] that can create pre-signed URLs for downloading payloads without further
+// credentials. See [AsyncHandlerOptions.PayloadRedirectTTL].
+type PayloadPresigner interface {
+	PayloadBackend
+	// PresignGet returns a URL for downloading the payload stored under key, valid for the given duration.
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// MemoryPayloadBackend is an in-memory [PayloadBackend], useful for testing.
+type MemoryPayloadBackend struct {
+	mu       sync.Mutex
+	payloads map[string]*Content
+}
+
+// NewMemoryPayloadBackend creates an empty [MemoryPayloadBackend].
+func NewMemoryPayloadBackend() *MemoryPayloadBackend {
+	return &MemoryPayloadBackend{payloads: make(map[string]*Content)}
+}
+
+// Put implements PayloadBackend.
+func (b *MemoryPayloadBackend) Put(ctx context.Context, key string, content *Content) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.payloads[key] = &Content{
+		Header: maps.Clone(content.Header),
+		Data:   append([]byte(nil), content.Data...),
+	}
+	return nil
+}
+
+// Get implements PayloadBackend.
+func (b *MemoryPayloadBackend) Get(ctx context.Context, key string) (*Reader, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	content, ok := b.payloads[key]
+	if !ok {
+		return nil, ErrPayloadNotFound
+	}
+	header := maps.Clone(content.Header)
+	if header == nil {
+		header = Header{}
+	}
+	header["length"] = strconv.Itoa(len(content.Data))
+	return &Reader{
+		io.NopCloser(bytes.NewReader(content.Data)),
+		header,
+	}, nil
+}
+
+var _ PayloadBackend = &MemoryPayloadBackend{}
@@ -0,0 +1,143 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ProtocolVersion is the version of the Nexus HTTP protocol implemented by this SDK. Clients and handlers are
+// compatible if their major versions match, see [Client.Ping].
+const ProtocolVersion = "1.0"
+
+// PingOptions are options for the Ping client and server APIs.
+type PingOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+}
+
+// ServiceInfo describes a service handler, as reported in response to [Client.Ping].
+type ServiceInfo struct {
+	// Name of the service, see [HandlerOptions.Service].
+	Service string `json:"service,omitempty"`
+	// Version of the Nexus HTTP protocol implemented by the handler, see [ProtocolVersion].
+	ProtocolVersion string `json:"protocolVersion"`
+	// Name of the SDK serving the handler.
+	ServerName string `json:"serverName,omitempty"`
+	// Version of the SDK serving the handler.
+	ServerVersion string `json:"serverVersion,omitempty"`
+}
+
+// An IncompatibleServiceError is returned by [Client.Ping] when the target does not serve a Nexus service the client
+// is compatible with, e.g. because the service base URL or [ClientOptions.PathBuilder] is wrong or the handler
+// implements an incompatible protocol version.
+type IncompatibleServiceError struct {
+	// Reason the service is incompatible.
+	Message string
+	// Protocol version reported by the service. Empty if the target did not respond like a Nexus service.
+	ProtocolVersion string
+	// The HTTP response. The response body will have already been read into memory and does not need to be closed.
+	Response *http.Response
+}
+
+// Error implements the error interface.
+func (e *IncompatibleServiceError) Error() string {
+	return "incompatible service: " + e.Message
+}
+
+// protocolMajorVersion returns the major version of a protocol version, e.g. "1" for "1.0".
+func protocolMajorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+func (h *httpHandler) ping(writer http.ResponseWriter, request *http.Request) {
+	options := PingOptions{Header: httpHeaderToNexusHeader(request.Header)}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodPing,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	bytes, err := h.json.marshal(ServiceInfo{
+		Service:         h.options.Service,
+		ProtocolVersion: ProtocolVersion,
+		ServerName:      clientName,
+		ServerVersion:   version,
+	})
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal service info: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// Ping verifies connectivity to and protocol compatibility with the service without starting an operation, e.g. for
+// deploy-time smoke tests. The request is authorized like any other, so Ping also verifies the client's credentials.
+//
+// Returns an [IncompatibleServiceError] if the target does not serve a Nexus service at the client's service base URL
+// and path layout or the service implements an incompatible protocol version. Requests rejected by the handler, e.g.
+// by its [Authorizer], fail with an [UnexpectedResponseError].
+func (c *Client) Ping(ctx context.Context, options PingOptions, opts ...CallOption) (*ServiceInfo, error) {
+	ctx, c = c.withCallOptions(ctx, opts)
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_nexus")
+	request, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil, &IncompatibleServiceError{
+			Message:  fmt.Sprintf("no Nexus service found at %s: %q", u, response.Status),
+			Response: response,
+		}
+	default:
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	var info ServiceInfo
+	if !isMediaTypeJSON(response.Header.Get("Content-Type")) || c.options.JSON.unmarshal(body, &info) != nil || info.ProtocolVersion == "" {
+		return nil, &IncompatibleServiceError{
+			Message:  fmt.Sprintf("invalid response from %s", u),
+			Response: response,
+		}
+	}
+	if protocolMajorVersion(info.ProtocolVersion) != protocolMajorVersion(ProtocolVersion) {
+		return nil, &IncompatibleServiceError{
+			Message:         fmt.Sprintf("service implements protocol version %s, client implements %s", info.ProtocolVersion, ProtocolVersion),
+			ProtocolVersion: info.ProtocolVersion,
+			Response:        response,
+		}
+	}
+	return &info, nil
+}
@@ -0,0 +1,89 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	authorizer := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+		if request.Header.Get("user") == "" {
+			return HandlerErrorf(HandlerErrorTypeUnauthenticated, "missing user")
+		}
+		return nil
+	})
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler:          UnimplementedHandler{},
+		Service:          "billing",
+		Authorizer:       authorizer,
+		StrictValidation: true,
+	}, ClientOptions{})
+	defer teardown()
+
+	info, err := client.Ping(ctx, PingOptions{Header: Header{"user": "admin"}})
+	require.NoError(t, err)
+	require.Equal(t, &ServiceInfo{
+		Service:         "billing",
+		ProtocolVersion: ProtocolVersion,
+		ServerName:      clientName,
+		ServerVersion:   version,
+	}, info)
+
+	_, err = client.Ping(ctx, PingOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusUnauthorized, unexpectedResponseError.Response.StatusCode)
+
+	// Clients with a mismatched path layout do not reach the service.
+	other, err := NewClient(ClientOptions{
+		ServiceBaseURL: client.options.ServiceBaseURL,
+		PathBuilder:    PathBuilder{Prefix: "/api/v2"},
+	})
+	require.NoError(t, err)
+	_, err = other.Ping(ctx, PingOptions{})
+	var incompatibleServiceError *IncompatibleServiceError
+	require.ErrorAs(t, err, &incompatibleServiceError)
+	require.Equal(t, http.StatusNotFound, incompatibleServiceError.Response.StatusCode)
+	require.Empty(t, incompatibleServiceError.ProtocolVersion)
+}
+
+func TestPing_Incompatible(t *testing.T) {
+	for _, c := range []struct {
+		name, contentType, body, protocolVersion string
+	}{
+		{"not a Nexus service", "text/html", "<html></html>", ""},
+		{"missing protocol version", contentTypeJSON, `{}`, ""},
+		{"incompatible major version", contentTypeJSON, `{"protocolVersion":"2.0"}`, "2.0"},
+	} {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Type", c.contentType)
+				_, _ = writer.Write([]byte(c.body))
+			}))
+			defer server.Close()
+			client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+			require.NoError(t, err)
+
+			_, err = client.Ping(context.Background(), PingOptions{})
+			var incompatibleServiceError *IncompatibleServiceError
+			require.ErrorAs(t, err, &incompatibleServiceError)
+			require.Equal(t, c.protocolVersion, incompatibleServiceError.ProtocolVersion)
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = writer.Write([]byte(`{"protocolVersion":"1.7"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+	info, err := client.Ping(context.Background(), PingOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "1.7", info.ProtocolVersion)
+}
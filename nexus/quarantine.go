@@ -0,0 +1,225 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// QuarantineTag is the tag set on quarantined operations, allowing them to be listed via [Client.ListOperations].
+const QuarantineTag = "nexus-quarantined"
+
+// OperationQuarantine describes why and when an operation was quarantined. See
+// [AsyncHandlerOptions.QuarantineAfterPanics].
+type OperationQuarantine struct {
+	// Time the operation was quarantined.
+	Time time.Time `json:"time"`
+	// Failure of the execution attempt that caused the quarantine.
+	Failure Failure `json:"failure"`
+}
+
+// QuarantineAction is an action for resolving a quarantined operation.
+type QuarantineAction string
+
+const (
+	// Release the operation from quarantine and execute it again.
+	QuarantineActionRelease QuarantineAction = "release"
+	// Fail the operation.
+	QuarantineActionFail QuarantineAction = "fail"
+)
+
+// ResolveQuarantineOptions are options for the ResolveQuarantine client and server APIs.
+type ResolveQuarantineOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// Action to resolve the quarantine with. Required.
+	Action QuarantineAction
+	// Optional failure message when failing the operation. Defaults to the message of the failure that caused the
+	// quarantine.
+	Reason string
+}
+
+// executionPanicError is the error an execution attempt fails with when the executor panics.
+type executionPanicError struct {
+	value any
+}
+
+func (e *executionPanicError) Error() string {
+	return fmt.Sprintf("operation execution panicked: %v", e.value)
+}
+
+// quarantineError wraps the error of the execution attempt that caused an operation to be quarantined.
+type quarantineError struct {
+	err error
+}
+
+func (e *quarantineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *quarantineError) Unwrap() error {
+	return e.err
+}
+
+// shouldQuarantine reports whether an operation should be quarantined after a failed execution attempt.
+func (h *AsyncHandler) shouldQuarantine(err error, panics int, retryable bool) bool {
+	if h.options.QuarantineAfterPanics > 0 && panics >= h.options.QuarantineAfterPanics {
+		return true
+	}
+	var unsuccessfulError *UnsuccessfulOperationError
+	return h.options.QuarantineNonRetryable && !retryable && !errors.As(err, &unsuccessfulError)
+}
+
+// quarantineEnabled reports whether operations may be quarantined, in which case their inputs are retained.
+func (h *AsyncHandler) quarantineEnabled() bool {
+	return h.options.QuarantineAfterPanics > 0 || h.options.QuarantineNonRetryable
+}
+
+// quarantine parks a running operation until it is resolved via [AsyncHandler.ResolveQuarantine].
+func (h *AsyncHandler) quarantine(operation, operationID string, executionErr error) error {
+	ctx := context.Background()
+	q := &OperationQuarantine{Time: time.Now(), Failure: Failure{Message: executionErr.Error()}}
+	var quarantined *OperationRecord
+	err := h.transition(ctx, operation, operationID, func(record *OperationRecord) {
+		record.Quarantine = q
+		if record.Tags == nil {
+			record.Tags = make(map[string]string)
+		}
+		record.Tags[QuarantineTag] = "true"
+		quarantined = record
+	})
+	if err != nil || quarantined == nil {
+		return err
+	}
+	h.options.Logger.Error("operation quarantined", "operation", operation, "operationID", operationID, "error", executionErr)
+	if h.options.OnQuarantine != nil {
+		h.options.OnQuarantine(quarantined.Summary(), q.Failure)
+	}
+	return nil
+}
+
+// ResolveQuarantine implements Handler.
+func (h *AsyncHandler) ResolveQuarantine(ctx context.Context, operation, operationID string, options ResolveQuarantineOptions) error {
+	record, err := h.getRecord(ctx, operation, operationID)
+	if err != nil {
+		return err
+	}
+	if record.State != OperationStateRunning || record.Quarantine == nil {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "operation is not quarantined")
+	}
+	switch options.Action {
+	case QuarantineActionFail:
+		reason := options.Reason
+		if reason == "" {
+			reason = record.Quarantine.Failure.Message
+		}
+		return h.stop(ctx, operation, operationID, OperationStateFailed, &Failure{Message: reason})
+	case QuarantineActionRelease:
+		if record.Input == nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "operation input was not retained")
+		}
+		var released *OperationRecord
+		err := h.transition(ctx, operation, operationID, func(record *OperationRecord) {
+			if record.Quarantine == nil {
+				return
+			}
+			record.Quarantine = nil
+			delete(record.Tags, QuarantineTag)
+			record.Attempt++
+			if !record.LastHeartbeat.IsZero() {
+				record.LastHeartbeat = time.Now()
+			}
+			released = record
+		})
+		if err != nil || released == nil {
+			return err
+		}
+		h.execute(released, released.Input, StartOperationOptions{
+			RequestID: released.RequestID,
+			Tags:      released.Tags,
+			Deadline:  released.Deadline,
+			StartTime: released.StartTime,
+		})
+		return nil
+	default:
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid quarantine action: %q", options.Action)
+	}
+}
+
+func (h *httpHandler) resolveQuarantine(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	query := request.URL.Query()
+	options := ResolveQuarantineOptions{
+		Header: httpHeaderToNexusHeader(request.Header),
+		Action: QuarantineAction(strings.ToLower(query.Get(QueryAction))),
+		Reason: query.Get(QueryReason),
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodResolveQuarantine,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	if err := h.options.Handler.ResolveQuarantine(ctx, operation, operationID, options); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+// ResolveQuarantine releases a quarantined operation for another execution attempt or fails it, depending on
+// [ResolveQuarantineOptions.Action]. Quarantined operations are tagged with [QuarantineTag].
+func (c *Client) ResolveQuarantine(ctx context.Context, operation, operationID string, options ResolveQuarantineOptions, opts ...CallOption) error {
+	ctx, c = c.withCallOptions(ctx, opts)
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_admin", "quarantine", url.PathEscape(operation), url.PathEscape(operationID))
+	q := u.Query()
+	q.Set(QueryAction, string(options.Action))
+	if options.Reason != "" {
+		q.Set(QueryReason, options.Reason)
+	}
+	u.RawQuery = q.Encode()
+	request, err := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	return nil
+}
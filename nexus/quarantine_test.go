@@ -0,0 +1,113 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_QuarantinePanics(t *testing.T) {
+	var healthy atomic.Bool
+	var attempts atomic.Int32
+	quarantined := make(chan *OperationSummary, 1)
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			attempts.Add(1)
+			if !healthy.Load() {
+				panic("poison")
+			}
+			return "ok", nil
+		},
+		DefaultRetryPolicy:    RetryPolicy{MaxAttempts: 10, InitialInterval: time.Millisecond},
+		QuarantineAfterPanics: 2,
+		OnQuarantine: func(summary *OperationSummary, failure Failure) {
+			require.Equal(t, "operation execution panicked: poison", failure.Message)
+			quarantined <- summary
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	stream, err := result.Pending.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	summary := <-quarantined
+	require.Equal(t, result.Pending.ID, summary.ID)
+	require.Equal(t, int32(2), attempts.Load())
+
+	info, err := stream.Next()
+	require.NoError(t, err)
+	if info.Quarantine == nil {
+		info, err = stream.Next()
+		require.NoError(t, err)
+	}
+	require.Equal(t, OperationStateRunning, info.State)
+	require.Equal(t, "operation execution panicked: poison", info.Quarantine.Failure.Message)
+	require.Len(t, info.AttemptHistory, 1)
+
+	list, err := client.ListOperations(ctx, ListOperationsOptions{Filter: OperationFilter{Tags: map[string]string{QuarantineTag: "true"}}})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 1)
+
+	healthy.Store(true)
+	require.NoError(t, client.ResolveQuarantine(ctx, "foo", result.Pending.ID, ResolveQuarantineOptions{Action: QuarantineActionRelease}))
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var out string
+	require.NoError(t, value.Consume(&out))
+	require.Equal(t, "ok", out)
+
+	info, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Nil(t, info.Quarantine)
+}
+
+func TestAsyncHandler_QuarantineNonRetryable(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, errors.New("corrupt input")
+		},
+		DefaultRetryPolicy: RetryPolicy{
+			MaxAttempts: 3,
+			IsRetryable: func(err error) bool { return false },
+		},
+		QuarantineNonRetryable: true,
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		return info.Quarantine != nil
+	}, time.Second, 10*time.Millisecond)
+
+	err = client.ResolveQuarantine(ctx, "foo", result.Pending.ID, ResolveQuarantineOptions{Action: "bogus"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+
+	require.NoError(t, client.ResolveQuarantine(ctx, "foo", result.Pending.ID, ResolveQuarantineOptions{Action: QuarantineActionFail, Reason: "bad data"}))
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, "bad data", unsuccessfulOperationError.Failure.Message)
+
+	err = client.ResolveQuarantine(ctx, "foo", result.Pending.ID, ResolveQuarantineOptions{Action: QuarantineActionRelease})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+}
@@ -0,0 +1,294 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A Quota limits the usage of a subject, e.g. a tenant. Zero valued fields do not limit usage.
+type Quota struct {
+	// Maximum number of running operations.
+	MaxRunningOperations int64 `json:"maxRunningOperations,omitempty"`
+	// Maximum total number of input and result bytes of all operations.
+	MaxPayloadBytes int64 `json:"maxPayloadBytes,omitempty"`
+}
+
+// QuotaUsage is the accounted usage of a subject.
+type QuotaUsage struct {
+	// Number of running operations.
+	RunningOperations int64 `json:"runningOperations"`
+	// Number of operations started.
+	TotalOperations int64 `json:"totalOperations"`
+	// Total number of input and result bytes of all operations.
+	PayloadBytes int64 `json:"payloadBytes"`
+}
+
+func (u QuotaUsage) add(delta QuotaUsage) QuotaUsage {
+	return QuotaUsage{
+		RunningOperations: u.RunningOperations + delta.RunningOperations,
+		TotalOperations:   u.TotalOperations + delta.TotalOperations,
+		PayloadBytes:      u.PayloadBytes + delta.PayloadBytes,
+	}
+}
+
+// Allows reports whether the given usage is within the quota.
+func (q Quota) Allows(usage QuotaUsage) bool {
+	if q.MaxRunningOperations > 0 && usage.RunningOperations > q.MaxRunningOperations {
+		return false
+	}
+	if q.MaxPayloadBytes > 0 && usage.PayloadBytes > q.MaxPayloadBytes {
+		return false
+	}
+	return true
+}
+
+// QuotaStatus is the usage and quota of a subject returned by [Client.GetQuotaUsage].
+type QuotaStatus struct {
+	// The subject, a value of the handler's quota tag.
+	Subject string `json:"subject"`
+	// Quota enforced for the subject.
+	Quota Quota `json:"quota"`
+	// Current usage of the subject.
+	Usage QuotaUsage `json:"usage"`
+}
+
+// QuotaExceededError is returned by handlers to reject start requests that would exceed a subject's quota. It is
+// transmitted as a 429 response with quota headers and returned from [Client.StartOperation] in that case.
+type QuotaExceededError struct {
+	// The subject whose quota would be exceeded.
+	Subject string
+	// Quota enforced for the subject.
+	Quota Quota
+	// Usage of the subject at the time the request was rejected.
+	Usage QuotaUsage
+	// Optional delay after which the caller may retry, transmitted in the Retry-After header. See [RetryAfter].
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded for %q", e.Subject)
+}
+
+func addQuotaExceededErrorToHTTPHeader(err *QuotaExceededError, httpHeader http.Header) http.Header {
+	httpHeader.Set(HeaderQuotaSubject, err.Subject)
+	if err.Quota.MaxRunningOperations > 0 {
+		httpHeader.Set(HeaderQuotaLimitOperations, strconv.FormatInt(err.Quota.MaxRunningOperations, 10))
+	}
+	if err.Quota.MaxPayloadBytes > 0 {
+		httpHeader.Set(HeaderQuotaLimitBytes, strconv.FormatInt(err.Quota.MaxPayloadBytes, 10))
+	}
+	httpHeader.Set(HeaderQuotaUsageOperations, strconv.FormatInt(err.Usage.RunningOperations, 10))
+	httpHeader.Set(HeaderQuotaUsageBytes, strconv.FormatInt(err.Usage.PayloadBytes, 10))
+	if err.RetryAfter > 0 {
+		httpHeader.Set(headerRetryAfter, formatRetryAfter(err.RetryAfter))
+	}
+	return httpHeader
+}
+
+func quotaExceededErrorFromHTTPHeader(httpHeader http.Header) (*QuotaExceededError, error) {
+	err := &QuotaExceededError{Subject: httpHeader.Get(HeaderQuotaSubject), RetryAfter: retryAfterFromHTTPHeader(httpHeader)}
+	for h, v := range map[string]*int64{
+		HeaderQuotaLimitOperations: &err.Quota.MaxRunningOperations,
+		HeaderQuotaLimitBytes:      &err.Quota.MaxPayloadBytes,
+		HeaderQuotaUsageOperations: &err.Usage.RunningOperations,
+		HeaderQuotaUsageBytes:      &err.Usage.PayloadBytes,
+	} {
+		if s := httpHeader.Get(h); s != "" {
+			parsed, parseErr := strconv.ParseInt(s, 10, 64)
+			if parseErr != nil {
+				return nil, fmt.Errorf("invalid %q header: %w", h, parseErr)
+			}
+			*v = parsed
+		}
+	}
+	return err, nil
+}
+
+// A UsageTracker tracks the quota usage of subjects for an [AsyncHandler].
+//
+// Implementations must be safe for concurrent use.
+type UsageTracker interface {
+	// Add atomically adds delta to the usage of subject unless the resulting usage is not allowed by quota, in which case
+	// the usage is left unchanged. Returns the resulting usage, or the current usage if the quota was exceeded, and
+	// whether delta was added.
+	Add(ctx context.Context, subject string, delta QuotaUsage, quota Quota) (QuotaUsage, bool, error)
+	// Usage returns the current usage of subject.
+	Usage(ctx context.Context, subject string) (QuotaUsage, error)
+}
+
+// MemoryUsageTracker is an in-memory [UsageTracker].
+type MemoryUsageTracker struct {
+	mu    sync.Mutex
+	usage map[string]QuotaUsage
+}
+
+// NewMemoryUsageTracker creates an empty [MemoryUsageTracker].
+func NewMemoryUsageTracker() *MemoryUsageTracker {
+	return &MemoryUsageTracker{usage: make(map[string]QuotaUsage)}
+}
+
+// Add implements UsageTracker.
+func (t *MemoryUsageTracker) Add(ctx context.Context, subject string, delta QuotaUsage, quota Quota) (QuotaUsage, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current := t.usage[subject]
+	updated := current.add(delta)
+	if !quota.Allows(updated) {
+		return current, false, nil
+	}
+	t.usage[subject] = updated
+	return updated, true, nil
+}
+
+// Usage implements UsageTracker.
+func (t *MemoryUsageTracker) Usage(ctx context.Context, subject string) (QuotaUsage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage[subject], nil
+}
+
+var _ UsageTracker = &MemoryUsageTracker{}
+
+func (h *AsyncHandler) quotaFor(subject string) Quota {
+	if quota, ok := h.options.Quotas[subject]; ok {
+		return quota
+	}
+	return h.options.DefaultQuota
+}
+
+// reserveQuota accounts for a new operation with the given tags and input size, returning the reserved usage, which is
+// zero if the operation is not subject to quota accounting.
+func (h *AsyncHandler) reserveQuota(ctx context.Context, tags map[string]string, inputSize int64) (QuotaUsage, error) {
+	subject, ok := tags[h.options.QuotaTag]
+	if h.options.QuotaTag == "" || !ok {
+		return QuotaUsage{}, nil
+	}
+	delta := QuotaUsage{RunningOperations: 1, TotalOperations: 1, PayloadBytes: inputSize}
+	quota := h.quotaFor(subject)
+	usage, ok, err := h.options.UsageTracker.Add(ctx, subject, delta, quota)
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("failed to account quota usage: %w", err)
+	}
+	if !ok {
+		return QuotaUsage{}, &QuotaExceededError{Subject: subject, Quota: quota, Usage: usage}
+	}
+	return delta, nil
+}
+
+// refundQuota reverts usage reserved for an operation that was not created.
+func (h *AsyncHandler) refundQuota(ctx context.Context, tags map[string]string, reserved QuotaUsage) {
+	if reserved == (QuotaUsage{}) {
+		return
+	}
+	subject := tags[h.options.QuotaTag]
+	delta := QuotaUsage{
+		RunningOperations: -reserved.RunningOperations,
+		TotalOperations:   -reserved.TotalOperations,
+		PayloadBytes:      -reserved.PayloadBytes,
+	}
+	if _, _, err := h.options.UsageTracker.Add(ctx, subject, delta, Quota{}); err != nil {
+		h.options.Logger.Error("failed to refund quota usage", "subject", subject, "error", err)
+	}
+}
+
+// releaseQuota accounts for an operation that reached a terminal state.
+func (h *AsyncHandler) releaseQuota(ctx context.Context, record *OperationRecord) {
+	subject, ok := record.Tags[h.options.QuotaTag]
+	if h.options.QuotaTag == "" || !ok {
+		return
+	}
+	delta := QuotaUsage{RunningOperations: -1, PayloadBytes: record.ResultSize}
+	if _, _, err := h.options.UsageTracker.Add(ctx, subject, delta, Quota{}); err != nil {
+		h.options.Logger.Error("failed to release quota usage", "operation", record.Operation, "operationID", record.ID, "subject", subject, "error", err)
+	}
+}
+
+// GetQuotaUsage implements Handler. Requires quota accounting to be enabled via [AsyncHandlerOptions.QuotaTag].
+func (h *AsyncHandler) GetQuotaUsage(ctx context.Context, subject string, options GetQuotaUsageOptions) (*QuotaStatus, error) {
+	if h.options.QuotaTag == "" {
+		return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "quota accounting is not enabled")
+	}
+	usage, err := h.options.UsageTracker.Usage(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &QuotaStatus{Subject: subject, Quota: h.quotaFor(subject), Usage: usage}, nil
+}
+
+func (h *httpHandler) getQuotaUsage(writer http.ResponseWriter, request *http.Request) {
+	subject, err := pathVar(request, "subject")
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	options := GetQuotaUsageOptions{Header: httpHeaderToNexusHeader(request.Header)}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodGetQuotaUsage,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	status, err := h.options.Handler.GetQuotaUsage(ctx, subject, options)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	bytes, err := h.json.marshal(status)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal quota status: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// GetQuotaUsage gets the quota and current usage of a subject, a value of the handler's quota tag, e.g. a tenant.
+func (c *Client) GetQuotaUsage(ctx context.Context, subject string, options GetQuotaUsageOptions, opts ...CallOption) (*QuotaStatus, error) {
+	ctx, c = c.withCallOptions(ctx, opts)
+	u := c.options.PathBuilder.rootURL(c.serviceBaseURL, "_admin", "usage", url.PathEscape(subject))
+	request, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, c.options.JSON)
+	}
+	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body, c.options.JSON)
+	}
+	var status QuotaStatus
+	if err := c.options.JSON.unmarshal(body, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,72 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Quota(t *testing.T) {
+	release := make(chan struct{})
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-release
+			return []byte("result"), nil
+		},
+		QuotaTag:     "Tenant",
+		Quotas:       map[string]Quota{"small": {MaxRunningOperations: 1}},
+		DefaultQuota: Quota{MaxRunningOperations: 2},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	tags := map[string]string{"tenant": "small"}
+	result, err := client.StartOperation(ctx, "foo", []byte("input"), StartOperationOptions{Tags: tags})
+	require.NoError(t, err)
+
+	_, err = client.StartOperation(ctx, "foo", []byte("input"), StartOperationOptions{Tags: tags})
+	var quotaExceededError *QuotaExceededError
+	require.ErrorAs(t, err, &quotaExceededError)
+	require.Equal(t, &QuotaExceededError{
+		Subject: "small",
+		Quota:   Quota{MaxRunningOperations: 1},
+		Usage:   QuotaUsage{RunningOperations: 1, PayloadBytes: 5},
+	}, quotaExceededError)
+
+	// Other subjects and untagged operations are accounted separately.
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: map[string]string{"tenant": "large"}})
+	require.NoError(t, err)
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+
+	close(release)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+
+	status, err := client.GetQuotaUsage(ctx, "small", GetQuotaUsageOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &QuotaStatus{
+		Subject: "small",
+		Quota:   Quota{MaxRunningOperations: 1},
+		Usage:   QuotaUsage{RunningOperations: 0, TotalOperations: 1, PayloadBytes: 11},
+	}, status)
+
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{Tags: tags})
+	require.NoError(t, err)
+}
+
+func TestGetQuotaUsage_NotEnabled(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return nil, nil
+	})
+	defer teardown()
+
+	_, err := client.GetQuotaUsage(ctx, "foo", GetQuotaUsageOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 501, unexpectedResponseError.Response.StatusCode)
+}
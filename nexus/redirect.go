@@ -0,0 +1,93 @@
+package nexus
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResultRedirect may be returned from [Handler.GetOperationResult] to respond with a 307 redirect to a URL the result
+// can be downloaded from, e.g. a pre-signed object store URL, offloading large transfers from the handler process.
+//
+// Only return a redirect when [GetOperationResultOptions.AcceptRedirect] is set.
+type ResultRedirect struct {
+	// URL to download the result data from. Must be accessible without Nexus request headers.
+	URL string
+	// Header of the result content, used by the client to deserialize the downloaded data.
+	// Set a "digest" header with [ContentDigest] to have clients verify the downloaded data.
+	Header Header
+}
+
+func (h *httpHandler) writeRedirect(writer http.ResponseWriter, redirect *ResultRedirect) {
+	header := writer.Header()
+	for k, v := range redirect.Header {
+		// The length is taken from the redirect target's response.
+		if strings.EqualFold(k, "length") {
+			continue
+		}
+		header.Set(HeaderPrefixRedirectContent+k, v)
+	}
+	header.Set("Location", redirect.URL)
+	writer.WriteHeader(http.StatusTemporaryRedirect)
+}
+
+// followResultRedirect downloads a redirected result. The returned response has its header replaced with the content
+// header advertised in the redirect response, including its digest, which is verified as the body is read.
+func (c *Client) followResultRedirect(redirect *http.Response) (*http.Response, error) {
+	body, err := readAndReplaceBody(redirect)
+	if err != nil {
+		return nil, err
+	}
+	location, err := redirect.Location()
+	if err != nil {
+		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid redirect location: %v", err), redirect, body, c.options.JSON)
+	}
+	request, err := http.NewRequestWithContext(redirect.Request.Context(), "GET", location.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(headerUserAgent, userAgent)
+	// Use the caller without authorization, the redirect URL is expected to carry its own credentials.
+	response, err := c.redirectHTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := limitResponseBody(response, c.options.MaxResponseBodySize); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		body, err := readAndReplaceBody(response)
+		if err != nil {
+			return nil, err
+		}
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status from result redirect: %q", response.Status), response, body, c.options.JSON)
+	}
+
+	contentHeader := prefixStrippedHTTPHeaderToNexusHeader(redirect.Header, strings.ToLower(HeaderPrefixRedirectContent))
+	if response.ContentLength >= 0 {
+		contentHeader["length"] = strconv.FormatInt(response.ContentLength, 10)
+	}
+	header := http.Header{}
+	addContentHeaderToHTTPHeader(contentHeader, header)
+	if token := redirect.Header.Get(HeaderResultNextPageToken); token != "" {
+		// A page of a paged result may be redirected too.
+		header.Set(HeaderResultNextPageToken, token)
+	}
+	response.Header = header
+	if digest := contentHeader.Get("digest"); digest != "" {
+		// The digest is verified when the result is read, as for results that are not redirected.
+		digests := parseContentDigest(digest)
+		if len(digests) == 0 {
+			response.Body.Close()
+			return nil, newUnexpectedResponseError(fmt.Sprintf("unsupported content digest: %q", digest), redirect, body, c.options.JSON)
+		}
+		for _, d := range digests {
+			if d.sum == nil {
+				response.Body.Close()
+				return nil, newUnexpectedResponseError(fmt.Sprintf("invalid content digest: %q", digest), redirect, body, c.options.JSON)
+			}
+		}
+	}
+	return response, nil
+}
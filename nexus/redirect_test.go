@@ -0,0 +1,89 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type redirectHandler struct {
+	UnimplementedHandler
+	url    string
+	digest string
+}
+
+func (h *redirectHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if !options.AcceptRedirect {
+		return []byte("inline"), nil
+	}
+	return &ResultRedirect{
+		URL:    h.url,
+		Header: Header{"type": "application/octet-stream", "digest": h.digest},
+	}, nil
+}
+
+func TestResultRedirect(t *testing.T) {
+	data := []byte("redirected")
+	objects := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if request.URL.Path != "/object" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write(data)
+	}))
+	defer objects.Close()
+
+	cases := []struct {
+		name          string
+		follow        bool
+		path          string
+		digest        string
+		expected      string
+		expectedError error
+	}{
+		{name: "not accepted", follow: false, path: "/object", digest: ContentDigest(data), expected: "inline"},
+		{name: "followed", follow: true, path: "/object", digest: ContentDigest(data), expected: "redirected"},
+		{name: "no digest", follow: true, path: "/object", expected: "redirected"},
+		{name: "digest mismatch", follow: true, path: "/object", digest: ContentDigest([]byte("other")), expectedError: ErrContentDigestMismatch},
+		{name: "not found", follow: true, path: "/missing", digest: ContentDigest(data)},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			handler := &redirectHandler{url: objects.URL + c.path, digest: c.digest}
+			ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{
+				FollowResultRedirects: c.follow,
+				AuthProvider:          NewStaticTokenAuthProvider("token"),
+			})
+			defer teardown()
+
+			handle, err := client.NewHandle("foo", "id")
+			require.NoError(t, err)
+			value, err := handle.GetResult(ctx, GetOperationResultOptions{})
+			if c.path == "/missing" {
+				var unexpectedResponseError *UnexpectedResponseError
+				require.ErrorAs(t, err, &unexpectedResponseError)
+				require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			defer value.Reader.Close()
+			body, err := io.ReadAll(value.Reader)
+			if c.expectedError != nil {
+				require.ErrorIs(t, err, c.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, string(body))
+			require.Equal(t, "application/octet-stream", value.Reader.Header.Get("type"))
+		})
+	}
+}
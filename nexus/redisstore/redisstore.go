@@ -0,0 +1,309 @@
+// Package redisstore provides Redis-backed implementations of [nexus.OperationStore] and [nexus.DedupeCache].
+//
+// The package does not depend on a specific Redis client library. Adapt your client of choice (e.g. go-redis or
+// rueidis) to the minimal [Client] interface.
+//
+// State transitions are performed atomically with Lua scripts and long polls are woken up via pub/sub.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Client is the subset of Redis functionality required by this package.
+type Client interface {
+	// Eval runs a Lua script with the given keys and arguments and returns its reply.
+	// Integer replies must be returned as int64, bulk string replies as string, array replies as []any, and nil
+	// replies as nil with a nil error.
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+	// Subscribe subscribes to the given pub/sub channel and returns a channel of message payloads.
+	// The subscription must be ready to receive messages when this method returns, and must be torn down and the
+	// returned channel closed when the context is done.
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+}
+
+// Options are options for [New].
+type Options struct {
+	// Redis client. Required.
+	Client Client
+	// Prefix for all keys and channels used by the store.
+	// Defaults to "nexus:".
+	KeyPrefix string
+	// Optional TTL for operation records. Records expire after not being updated for this duration.
+	// Defaults to zero, which retains records indefinitely.
+	TTL time.Duration
+}
+
+// Store is a Redis-backed [nexus.OperationStore].
+//
+// Records are stored as hashes holding a version and a JSON encoded record.
+type Store struct {
+	options Options
+}
+
+// New creates a new [Store] from the given options.
+func New(options Options) (*Store, error) {
+	if options.Client == nil {
+		return nil, errors.New("client is required")
+	}
+	if options.KeyPrefix == "" {
+		options.KeyPrefix = "nexus:"
+	}
+	return &Store{options: options}, nil
+}
+
+func (s *Store) recordKey(operation, operationID string) string {
+	return s.options.KeyPrefix + "operation:" + operationKey(operation, operationID)
+}
+
+func (s *Store) updatesChannel(operation, operationID string) string {
+	return s.options.KeyPrefix + "updates:" + operationKey(operation, operationID)
+}
+
+// operationKey identifies an operation in keys and channel names. Both parts are escaped since they may contain "/".
+// Names and IDs without reserved characters, such as UUIDs, are unchanged by escaping.
+func operationKey(operation, operationID string) string {
+	return url.PathEscape(operation) + "/" + url.PathEscape(operationID)
+}
+
+// KEYS[1]: record key
+// ARGV[1]: encoded record, ARGV[2]: TTL in milliseconds, ARGV[3]: updates channel
+const createScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'version', 1, 'record', ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+redis.call('PUBLISH', ARGV[3], 1)
+return 1
+`
+
+// KEYS[1]: record key
+// ARGV[1]: encoded record, ARGV[2]: TTL in milliseconds, ARGV[3]: updates channel, ARGV[4]: expected version
+const updateScript = `
+local version = redis.call('HGET', KEYS[1], 'version')
+if not version then
+	return -1
+end
+if tonumber(version) ~= tonumber(ARGV[4]) then
+	return 0
+end
+local next = tonumber(version) + 1
+redis.call('HSET', KEYS[1], 'version', next, 'record', ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+redis.call('PUBLISH', ARGV[3], next)
+return 1
+`
+
+// KEYS[1]: record key
+const getScript = `
+return redis.call('HMGET', KEYS[1], 'version', 'record')
+`
+
+// Create implements nexus.OperationStore.
+func (s *Store) Create(ctx context.Context, record *nexus.OperationRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	reply, err := s.options.Client.Eval(ctx, createScript, []string{s.recordKey(record.Operation, record.ID)}, string(encoded), s.options.TTL.Milliseconds(), s.updatesChannel(record.Operation, record.ID))
+	if err != nil {
+		return err
+	}
+	if reply != int64(1) {
+		return nexus.ErrOperationRecordExists
+	}
+	record.Version = 1
+	return nil
+}
+
+// Get implements nexus.OperationStore.
+func (s *Store) Get(ctx context.Context, operation, operationID string) (*nexus.OperationRecord, error) {
+	version, encoded, err := s.get(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	var record nexus.OperationRecord
+	if err := json.Unmarshal([]byte(encoded), &record); err != nil {
+		return nil, err
+	}
+	record.Version = version
+	return &record, nil
+}
+
+func (s *Store) get(ctx context.Context, operation, operationID string) (int64, string, error) {
+	reply, err := s.options.Client.Eval(ctx, getScript, []string{s.recordKey(operation, operationID)})
+	if err != nil {
+		return 0, "", err
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return 0, "", fmt.Errorf("unexpected reply: %v", reply)
+	}
+	if values[0] == nil || values[1] == nil {
+		return 0, "", nexus.ErrOperationRecordNotFound
+	}
+	version, err := parseVersion(values[0])
+	if err != nil {
+		return 0, "", err
+	}
+	encoded, ok := values[1].(string)
+	if !ok {
+		return 0, "", fmt.Errorf("unexpected record value: %v", values[1])
+	}
+	return version, encoded, nil
+}
+
+func parseVersion(v any) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case string:
+		var version int64
+		_, err := fmt.Sscan(v, &version)
+		return version, err
+	default:
+		return 0, fmt.Errorf("unexpected version value: %v", v)
+	}
+}
+
+// Update implements nexus.OperationStore.
+func (s *Store) Update(ctx context.Context, record *nexus.OperationRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	reply, err := s.options.Client.Eval(ctx, updateScript, []string{s.recordKey(record.Operation, record.ID)}, string(encoded), s.options.TTL.Milliseconds(), s.updatesChannel(record.Operation, record.ID), record.Version)
+	if err != nil {
+		return err
+	}
+	switch reply {
+	case int64(1):
+		record.Version++
+		return nil
+	case int64(0):
+		return nexus.ErrOperationRecordVersionConflict
+	default:
+		return nexus.ErrOperationRecordNotFound
+	}
+}
+
+// WaitForUpdate implements nexus.OperationStore.
+func (s *Store) WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Subscribe before checking to avoid missing an update published between the check and the wait.
+	updates, err := s.options.Client.Subscribe(ctx, s.updatesChannel(operation, operationID))
+	if err != nil {
+		return err
+	}
+	for {
+		current, _, err := s.get(ctx, operation, operationID)
+		if err != nil && !errors.Is(err, nexus.ErrOperationRecordNotFound) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if current > version {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-updates:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return errors.New("subscription closed")
+			}
+		}
+	}
+}
+
+var _ nexus.OperationStore = &Store{}
+
+// KEYS[1]: dedupe key
+// ARGV[1]: value, ARGV[2]: TTL in milliseconds
+const getOrSetScript = `
+local existing = redis.call('GET', KEYS[1])
+if existing then
+	return {existing, 1}
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return {ARGV[1], 0}
+`
+
+// KEYS[1]: dedupe key
+// ARGV[1]: value
+const deleteIfEqualScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// DedupeCache is a Redis-backed [nexus.DedupeCache].
+type DedupeCache struct {
+	client    Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewDedupeCache creates a [DedupeCache] that retains entries for the given TTL, sharing the store's key prefix.
+// A zero TTL retains entries indefinitely.
+func NewDedupeCache(options Options, ttl time.Duration) (*DedupeCache, error) {
+	if options.Client == nil {
+		return nil, errors.New("client is required")
+	}
+	if options.KeyPrefix == "" {
+		options.KeyPrefix = "nexus:"
+	}
+	return &DedupeCache{
+		client:    options.Client,
+		keyPrefix: options.KeyPrefix + "dedupe:",
+		ttl:       ttl,
+	}, nil
+}
+
+// GetOrSet implements nexus.DedupeCache.
+func (c *DedupeCache) GetOrSet(ctx context.Context, key, value string) (string, bool, error) {
+	reply, err := c.client.Eval(ctx, getOrSetScript, []string{c.keyPrefix + key}, value, c.ttl.Milliseconds())
+	if err != nil {
+		return "", false, err
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return "", false, fmt.Errorf("unexpected reply: %v", reply)
+	}
+	existing, ok := values[0].(string)
+	if !ok {
+		return "", false, fmt.Errorf("unexpected value: %v", values[0])
+	}
+	return existing, values[1] == int64(1), nil
+}
+
+// Delete implements nexus.DedupeCacheDeleter.
+func (c *DedupeCache) Delete(ctx context.Context, key, value string) error {
+	_, err := c.client.Eval(ctx, deleteIfEqualScript, []string{c.keyPrefix + key}, value)
+	return err
+}
+
+var _ nexus.DedupeCache = &DedupeCache{}
+var _ nexus.DedupeCacheDeleter = &DedupeCache{}
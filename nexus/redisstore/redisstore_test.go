@@ -0,0 +1,173 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient emulates the package's Lua scripts in memory.
+type fakeClient struct {
+	mu          sync.Mutex
+	hashes      map[string]map[string]string
+	strings     map[string]string
+	subscribers map[string][]chan string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		hashes:      make(map[string]map[string]string),
+		strings:     make(map[string]string),
+		subscribers: make(map[string][]chan string),
+	}
+}
+
+func (c *fakeClient) publishLocked(channel string) {
+	for _, ch := range c.subscribers[channel] {
+		select {
+		case ch <- "":
+		default:
+		}
+	}
+}
+
+func (c *fakeClient) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := keys[0]
+	switch script {
+	case createScript:
+		if _, ok := c.hashes[key]; ok {
+			return int64(0), nil
+		}
+		c.hashes[key] = map[string]string{"version": "1", "record": args[0].(string)}
+		c.publishLocked(args[2].(string))
+		return int64(1), nil
+	case updateScript:
+		h, ok := c.hashes[key]
+		if !ok {
+			return int64(-1), nil
+		}
+		if h["version"] != fmt.Sprint(args[3]) {
+			return int64(0), nil
+		}
+		version, _ := parseVersion(h["version"])
+		h["version"] = fmt.Sprint(version + 1)
+		h["record"] = args[0].(string)
+		c.publishLocked(args[2].(string))
+		return int64(1), nil
+	case getScript:
+		h, ok := c.hashes[key]
+		if !ok {
+			return []any{nil, nil}, nil
+		}
+		return []any{h["version"], h["record"]}, nil
+	case getOrSetScript:
+		if existing, ok := c.strings[key]; ok {
+			return []any{existing, int64(1)}, nil
+		}
+		c.strings[key] = args[0].(string)
+		return []any{args[0], int64(0)}, nil
+	case deleteIfEqualScript:
+		if c.strings[key] == args[0].(string) {
+			delete(c.strings, key)
+		}
+		return int64(0), nil
+	}
+	return nil, fmt.Errorf("unknown script")
+}
+
+func (c *fakeClient) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan string, 1)
+	c.subscribers[channel] = append(c.subscribers[channel], ch)
+	return ch, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(Options{Client: newFakeClient()})
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "foo", "id")
+	require.ErrorIs(t, err, nexus.ErrOperationRecordNotFound)
+
+	record := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+	require.ErrorIs(t, store.Create(ctx, record), nexus.ErrOperationRecordExists)
+
+	done := make(chan error)
+	go func() {
+		done <- store.WaitForUpdate(ctx, "foo", "id", 1)
+	}()
+
+	record.State = nexus.OperationStateSucceeded
+	record.Result = &nexus.Content{Header: nexus.Header{"type": "application/json"}, Data: []byte(`"ok"`)}
+	require.NoError(t, store.Update(ctx, record))
+	require.Equal(t, int64(2), record.Version)
+	require.NoError(t, <-done)
+
+	record.Version = 1
+	require.ErrorIs(t, store.Update(ctx, record), nexus.ErrOperationRecordVersionConflict)
+
+	stored, err := store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stored.Version)
+	require.Equal(t, nexus.OperationStateSucceeded, stored.State)
+	require.Equal(t, []byte(`"ok"`), stored.Result.Data)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, store.WaitForUpdate(timeoutCtx, "foo", "id", 2), context.DeadlineExceeded)
+}
+
+func TestStore_AmbiguousKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(Options{Client: newFakeClient()})
+	require.NoError(t, err)
+
+	require.NotEqual(t, store.recordKey("a/b", "c"), store.recordKey("a", "b/c"))
+	require.NotEqual(t, store.updatesChannel("a/b", "c"), store.updatesChannel("a", "b/c"))
+	require.Equal(t, "nexus:operation:foo/1234-abcd", store.recordKey("foo", "1234-abcd"))
+
+	require.NoError(t, store.Create(ctx, &nexus.OperationRecord{Operation: "a/b", ID: "c", State: nexus.OperationStateRunning}))
+	require.NoError(t, store.Create(ctx, &nexus.OperationRecord{Operation: "a", ID: "b/c", State: nexus.OperationStateSucceeded}))
+	stored, err := store.Get(ctx, "a/b", "c")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateRunning, stored.State)
+	stored, err = store.Get(ctx, "a", "b/c")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateSucceeded, stored.State)
+}
+
+func TestDedupeCache(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewDedupeCache(Options{Client: newFakeClient()}, time.Minute)
+	require.NoError(t, err)
+
+	value, loaded, err := cache.GetOrSet(ctx, "key", "a")
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, "a", value)
+
+	value, loaded, err = cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, "a", value)
+
+	require.NoError(t, cache.Delete(ctx, "key", "b"))
+	_, loaded, err = cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.NoError(t, cache.Delete(ctx, "key", "a"))
+	value, loaded, err = cache.GetOrSet(ctx, "key", "b")
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, "b", value)
+}
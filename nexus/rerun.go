@@ -0,0 +1,69 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// ErrInputNotRetained is returned by [RetryOperation] when no input was given and the handle did not retain the
+// input the operation was started with, see [ClientOptions.RetainInputs].
+var ErrInputNotRetained = errors.New("operation input was not retained")
+
+// RetryOperationOptions are options for [RetryOperation].
+type RetryOperationOptions struct {
+	// Input to start the new operation with. Defaults to the input retained by the handle, see
+	// [ClientOptions.RetainInputs]. Required for handles obtained via [Client.NewHandle].
+	Input any
+	// Options of the start request of the new operation. A link to the rerun operation with the
+	// [LinkRelationRetryOf] relation is appended to Links. RequestID defaults to a new request ID; reusing the request
+	// ID of the rerun operation would deduplicate the start request against it.
+	StartOperationOptions StartOperationOptions
+}
+
+// RetryOperation starts a new operation with the same name and input as the operation of the given handle, e.g. for
+// operator-driven reruns after the operation failed. The new operation is linked to the rerun operation via a link
+// with the [LinkRelationRetryOf] relation pointing to the rerun operation's URL, which handlers that support links,
+// such as the [AsyncHandler], report in [OperationInfo.Links]. The state of the rerun operation is not checked.
+//
+// The input is either given in [RetryOperationOptions.Input] or retained by the handle if it was returned from a start
+// request of a client with [ClientOptions.RetainInputs] set. Returns [ErrInputNotRetained] otherwise.
+//
+// See [Client.StartOperation] for the possible outcomes.
+func RetryOperation[T any](ctx context.Context, handle *OperationHandle[T], options RetryOperationOptions, opts ...CallOption) (*ClientStartOperationResult[T], error) {
+	input := options.Input
+	if input == nil {
+		if handle.input == nil {
+			return nil, ErrInputNotRetained
+		}
+		input = handle.input
+	}
+	so := options.StartOperationOptions
+	so.Links = append(slices.Clip(so.Links), Link{
+		URL: handle.client.options.PathBuilder.operationInstanceURL(handle.client.serviceBaseURL, handle.Operation, handle.ID).String(),
+		Rel: LinkRelationRetryOf,
+	})
+	result, err := handle.client.StartOperation(ctx, handle.Operation, input, so, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Successful != nil {
+		if value, ok := any(result.Successful).(T); ok {
+			return &ClientStartOperationResult[T]{Successful: value}, nil
+		}
+		var o T
+		if err := result.Successful.Consume(&o); err != nil {
+			return nil, err
+		}
+		return &ClientStartOperationResult[T]{Successful: o}, nil
+	}
+	pending := &OperationHandle[T]{
+		Operation: handle.Operation,
+		ID:        result.Pending.ID,
+		Links:     result.Pending.Links,
+		client:    result.Pending.client,
+		input:     result.Pending.input,
+		polls:     result.Pending.polls,
+	}
+	return &ClientStartOperationResult[T]{Pending: pending, AlreadyStarted: result.AlreadyStarted}, nil
+}
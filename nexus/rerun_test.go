@@ -0,0 +1,80 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOperation(t *testing.T) {
+	var attempts atomic.Int32
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			var s string
+			if err := input.Consume(&s); err != nil {
+				return nil, err
+			}
+			if attempts.Add(1) == 1 {
+				return nil, errors.New("transient")
+			}
+			return s + "!", nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{RetainInputs: true})
+	defer teardown()
+
+	ref := NewOperationReference[string, string]("shout")
+	result, err := StartOperation(ctx, client, ref, "hello", StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+
+	rerun, err := RetryOperation(ctx, result.Pending, RetryOperationOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, result.Pending.ID, rerun.Pending.ID)
+	link, ok := FindLink(rerun.Pending.Links, LinkRelationRetryOf)
+	require.True(t, ok)
+	u, ok := rerun.Pending.Link(LinkRelationRetryOf)
+	require.True(t, ok)
+	require.Equal(t, link.URL, u.String())
+	require.Equal(t, client.serviceBaseURL.JoinPath("shout", result.Pending.ID).String(), link.URL)
+	output, err := rerun.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "hello!", output)
+	info, err := rerun.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []Link{link}, info.Links)
+
+	handle, err := client.NewHandle("shout", result.Pending.ID)
+	require.NoError(t, err)
+	_, err = RetryOperation(ctx, handle, RetryOperationOptions{})
+	require.ErrorIs(t, err, ErrInputNotRetained)
+	untyped, err := RetryOperation(ctx, handle, RetryOperationOptions{Input: "again"})
+	require.NoError(t, err)
+	value, err := untyped.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, value.Consume(&s))
+	require.Equal(t, "again!", s)
+}
+
+func TestStartOperation_Links(t *testing.T) {
+	links := []Link{{URL: "https://example.com/parent", Rel: "parent"}}
+	request, err := NewStartOperationHTTPRequest(context.Background(), "http://localhost/service", "foo", &Reader{}, StartOperationOptions{Links: links})
+	require.NoError(t, err)
+	options, err := StartOperationOptionsFromHTTPRequest(request)
+	require.NoError(t, err)
+	require.Equal(t, links, options.Links)
+	require.NotContains(t, options.Header, "nexus-links")
+
+	request.Header.Set(HeaderLinks, `[{"rel":"parent"}]`)
+	_, err = StartOperationOptionsFromHTTPRequest(request)
+	require.EqualError(t, err, `invalid "Nexus-Links" header`)
+}
@@ -0,0 +1,102 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme of the base URL requests of clients with a [Resolver] are built against before being rewritten to target a
+// resolved endpoint.
+const resolvedBaseURLScheme = "nexus"
+
+// Default duration resolved endpoints are used for when the resolver does not specify a TTL.
+const defaultResolveTTL = 30 * time.Second
+
+// Interval for retrying failed resolutions while using previously resolved endpoints.
+const resolveRetryInterval = time.Second
+
+// A Resolver maps a logical service name to the endpoints serving it, integrating clients with service discovery,
+// e.g. DNS, Consul or Kubernetes, see [ClientOptions.Resolver].
+//
+// Implementations must be safe for concurrent use.
+type Resolver interface {
+	// Resolve returns the endpoints serving the given service and for how long they may be used before resolving them
+	// again. A non-positive TTL defaults to 30 seconds.
+	Resolve(ctx context.Context, service string) (endpoints []ServiceEndpoint, ttl time.Duration, err error)
+}
+
+type staticResolver struct {
+	services map[string][]ServiceEndpoint
+}
+
+// NewStaticResolver creates a [Resolver] that resolves services to fixed endpoints, e.g. from configuration.
+func NewStaticResolver(services map[string][]ServiceEndpoint) Resolver {
+	return staticResolver{services: services}
+}
+
+// Resolve implements Resolver.
+func (r staticResolver) Resolve(ctx context.Context, service string) ([]ServiceEndpoint, time.Duration, error) {
+	endpoints, ok := r.services[service]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown service: %q", service)
+	}
+	return endpoints, 0, nil
+}
+
+// DNSSRVResolverOptions are options for [NewDNSSRVResolver].
+type DNSSRVResolverOptions struct {
+	// Scheme of the resolved endpoint URLs. Defaults to "https".
+	Scheme string
+	// Base path of the service at the resolved endpoints. Defaults to "/".
+	Path string
+	// Duration resolved endpoints are used for. DNS TTLs are not exposed by the Go resolver. Defaults to 30 seconds.
+	TTL time.Duration
+	// Function for looking up SRV records. Defaults to the LookupSRV method of [net.DefaultResolver].
+	LookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type dnsSRVResolver struct {
+	options DNSSRVResolverOptions
+}
+
+// NewDNSSRVResolver creates a [Resolver] that resolves services via DNS SRV records, e.g. the records Kubernetes and
+// Consul publish for named ports. The service name is the full record name, e.g.
+// "_https._tcp.orders.default.svc.cluster.local".
+//
+// Endpoints are ordered by the records' priority, for use with [NewPriorityEndpointSelector], and weighted by their
+// weight, for use with [NewWeightedEndpointSelector].
+func NewDNSSRVResolver(options DNSSRVResolverOptions) Resolver {
+	if options.Scheme == "" {
+		options.Scheme = "https"
+	}
+	if options.Path == "" {
+		options.Path = "/"
+	}
+	if options.LookupSRV == nil {
+		options.LookupSRV = net.DefaultResolver.LookupSRV
+	}
+	return dnsSRVResolver{options: options}
+}
+
+// Resolve implements Resolver.
+func (r dnsSRVResolver) Resolve(ctx context.Context, service string) ([]ServiceEndpoint, time.Duration, error) {
+	_, records, err := r.options.LookupSRV(ctx, "", "", service)
+	if err != nil {
+		return nil, 0, err
+	}
+	endpoints := make([]ServiceEndpoint, len(records))
+	for i, record := range records {
+		u := url.URL{
+			Scheme: r.options.Scheme,
+			Host:   net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))),
+			Path:   r.options.Path,
+		}
+		endpoints[i] = ServiceEndpoint{URL: u.String(), Weight: int(record.Weight)}
+	}
+	return endpoints, r.options.TTL, nil
+}
@@ -0,0 +1,107 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticResolver(t *testing.T) {
+	a := newNamedServer(t, "a", "")
+	client, err := NewClient(ClientOptions{
+		ServiceName: "orders",
+		Resolver:    NewStaticResolver(map[string][]ServiceEndpoint{"orders": {{URL: a.URL}}}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "a", servedBy(t, client))
+
+	client, err = NewClient(ClientOptions{
+		ServiceName: "payments",
+		Resolver:    NewStaticResolver(map[string][]ServiceEndpoint{"orders": {{URL: a.URL}}}),
+	})
+	require.NoError(t, err)
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(context.Background(), GetOperationInfoOptions{})
+	require.ErrorContains(t, err, `failed to resolve service "payments": unknown service: "payments"`)
+}
+
+// changingResolver resolves to the endpoints or fails with the error it was last updated with.
+type changingResolver struct {
+	mu        sync.Mutex
+	endpoints []ServiceEndpoint
+	err       error
+	calls     int
+}
+
+func (r *changingResolver) set(endpoints []ServiceEndpoint, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints, r.err = endpoints, err
+}
+
+func (r *changingResolver) Resolve(ctx context.Context, service string) ([]ServiceEndpoint, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	return r.endpoints, time.Nanosecond, r.err
+}
+
+func TestResolver_Refresh(t *testing.T) {
+	a := newNamedServer(t, "a", "")
+	b := newNamedServer(t, "b", "")
+	resolver := &changingResolver{endpoints: []ServiceEndpoint{{URL: a.URL}}}
+	client, err := NewClient(ClientOptions{ServiceName: "orders", Resolver: resolver})
+	require.NoError(t, err)
+	require.Equal(t, "a", servedBy(t, client))
+
+	resolver.set([]ServiceEndpoint{{URL: b.URL}}, nil)
+	require.Equal(t, "b", servedBy(t, client))
+
+	// Previously resolved endpoints are used while resolution fails.
+	resolver.set(nil, errors.New("discovery unavailable"))
+	require.Equal(t, "b", servedBy(t, client))
+	require.Equal(t, "b", servedBy(t, client))
+	// Failed resolutions are retried after an interval rather than on every request.
+	require.Equal(t, 3, resolver.calls)
+}
+
+func TestDNSSRVResolver(t *testing.T) {
+	resolver := NewDNSSRVResolver(DNSSRVResolverOptions{
+		Path: "/nexus",
+		LookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			require.Equal(t, "_https._tcp.orders.svc", name)
+			return name, []*net.SRV{
+				{Target: "a.orders.svc.", Port: 8443, Priority: 1, Weight: 10},
+				{Target: "b.orders.svc.", Port: 8443, Priority: 2},
+			}, nil
+		},
+	})
+	endpoints, ttl, err := resolver.Resolve(context.Background(), "_https._tcp.orders.svc")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), ttl)
+	require.Equal(t, []ServiceEndpoint{
+		{URL: "https://a.orders.svc:8443/nexus", Weight: 10},
+		{URL: "https://b.orders.svc:8443/nexus"},
+	}, endpoints)
+}
+
+func TestNewClient_ResolverValidation(t *testing.T) {
+	resolver := NewStaticResolver(nil)
+	_, err := NewClient(ClientOptions{Resolver: resolver})
+	require.ErrorIs(t, err, errEmptyServiceName)
+	_, err = NewClient(ClientOptions{ServiceName: "orders", Resolver: resolver, ServiceBaseURL: "http://a"})
+	require.ErrorIs(t, err, errServiceBaseURLWithEndpoints)
+	_, err = NewClient(ClientOptions{ServiceName: "orders", Resolver: resolver, ServiceEndpoints: []ServiceEndpoint{{URL: "http://a"}}})
+	require.ErrorIs(t, err, errResolverWithEndpoints)
+
+	client, err := NewClient(ClientOptions{ServiceName: "orders", Resolver: resolver})
+	require.NoError(t, err)
+	require.Equal(t, &url.URL{Scheme: "nexus", Host: "orders", Path: "/"}, client.serviceBaseURL)
+}
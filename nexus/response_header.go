@@ -0,0 +1,73 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+)
+
+// A ResponseHeaderProvider provides header fields attached to every response of a handler, including asynchronous
+// start responses and failures, e.g. Retry-After hints, rate-limit headers or cache directives, see
+// [HandlerOptions.ResponseHeaderProvider].
+//
+// It is called once per request before the response header is written, with the response status code and the error
+// the request failed with, or nil if it did not fail. For handlers returned from [NewHTTPHandler], the context carries
+// the request's [HandlerInfo]. Header fields set by the handler itself take precedence.
+type ResponseHeaderProvider func(ctx context.Context, statusCode int, err error) Header
+
+// provideResponseHeaders attaches the headers of h's response header provider to responses written to the writer
+// returned by startRequestLog. It returns a function to be deferred until the request is handled, which attaches the
+// headers to responses whose header was not explicitly written.
+func (h *baseHTTPHandler) provideResponseHeaders(ctx context.Context, writer http.ResponseWriter) func() {
+	recorder, ok := writer.(*statusRecorder)
+	if h.responseHeaderProvider == nil || !ok {
+		return func() {}
+	}
+	w := &responseHeaderWriter{ResponseWriter: recorder.ResponseWriter, ctx: ctx, recorder: recorder, provider: h.responseHeaderProvider}
+	recorder.ResponseWriter = w
+	return func() {
+		w.writeHeader(http.StatusOK)
+	}
+}
+
+// responseHeaderWriter attaches the headers of a [ResponseHeaderProvider] before the response header is written.
+type responseHeaderWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	recorder *statusRecorder
+	provider ResponseHeaderProvider
+	written  bool
+}
+
+func (w *responseHeaderWriter) writeHeader(statusCode int) {
+	if w.written {
+		return
+	}
+	w.written = true
+	header := w.Header()
+	for k, v := range w.provider(w.ctx, statusCode, w.recorder.err) {
+		if header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+}
+
+func (w *responseHeaderWriter) WriteHeader(statusCode int) {
+	w.writeHeader(statusCode)
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseHeaderWriter) Write(b []byte) (int, error) {
+	w.writeHeader(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, attaching headers before they are flushed.
+func (w *responseHeaderWriter) Flush() {
+	w.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to [http.ResponseController].
+func (w *responseHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
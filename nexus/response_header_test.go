@@ -0,0 +1,69 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// operationOutcomeHandler completes "sync" operations synchronously, starts "async" operations asynchronously and
+// fails other operations for exhausting a resource.
+type operationOutcomeHandler struct {
+	UnimplementedHandler
+}
+
+func (h *operationOutcomeHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	switch operation {
+	case "sync":
+		return &HandlerStartOperationResultSync[any]{}, nil
+	case "async":
+		return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+	}
+	return nil, HandlerErrorf(HandlerErrorTypeResourceExhausted, "slow down")
+}
+
+func TestResponseHeaderProvider(t *testing.T) {
+	var header http.Header
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: &operationOutcomeHandler{},
+		ResponseHeaderProvider: func(ctx context.Context, statusCode int, err error) Header {
+			info, _ := HandlerInfoFromContext(ctx)
+			header := Header{"x-status": strconv.Itoa(statusCode), "x-operation": info.Operation, "content-type": "text/plain"}
+			var handlerErr *HandlerError
+			if errors.As(err, &handlerErr) && handlerErr.Type == HandlerErrorTypeResourceExhausted {
+				header["retry-after"] = "5"
+			}
+			return header
+		},
+	}, ClientOptions{
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(request)
+			if err == nil {
+				header = response.Header
+			}
+			return response, err
+		},
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "sync", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "200", header.Get("x-status"))
+	require.Equal(t, "sync", header.Get("x-operation"))
+
+	_, err = client.StartOperation(ctx, "async", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "201", header.Get("x-status"))
+	require.Equal(t, "async", header.Get("x-operation"))
+
+	_, err = client.StartOperation(ctx, "other", []byte("data"), StartOperationOptions{})
+	require.Error(t, err)
+	require.Equal(t, "429", header.Get("x-status"))
+	require.Equal(t, "5", header.Get("retry-after"))
+	// Header fields set by the handler take precedence.
+	require.Equal(t, "application/json", header.Get("content-type"))
+}
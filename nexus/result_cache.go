@@ -0,0 +1,77 @@
+package nexus
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A ResultCache stores results of successfully completed operations on the client, allowing repeated calls to
+// [OperationHandle.GetResult] for the same operation to be served locally.
+//
+// Keys are opaque strings derived by the client from the service base URL, operation name, and operation ID.
+//
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns the cached content for the given key, if present.
+	Get(key string) (*Content, bool)
+	// Add stores the given content under the given key.
+	Add(key string, content *Content)
+}
+
+type lruResultCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  *list.List
+	index    map[string]*list.Element
+}
+
+type lruResultCacheEntry struct {
+	key     string
+	content *Content
+}
+
+// NewLRUResultCache creates an in-memory [ResultCache] that holds up to capacity results, evicting the least recently
+// used result when full.
+//
+// Panics if capacity is not positive.
+func NewLRUResultCache(capacity int) ResultCache {
+	if capacity <= 0 {
+		panic("invalid result cache capacity")
+	}
+	return &lruResultCache{
+		capacity: capacity,
+		entries:  list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements ResultCache.
+func (c *lruResultCache) Get(key string) (*Content, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	return element.Value.(*lruResultCacheEntry).content, true
+}
+
+// Add implements ResultCache.
+func (c *lruResultCache) Add(key string, content *Content) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.index[key]; ok {
+		element.Value.(*lruResultCacheEntry).content = content
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.index[key] = c.entries.PushFront(&lruResultCacheEntry{key: key, content: content})
+	if c.entries.Len() > c.capacity {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*lruResultCacheEntry).key)
+	}
+}
+
+var _ ResultCache = &lruResultCache{}
@@ -0,0 +1,79 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUResultCache_Eviction(t *testing.T) {
+	cache := NewLRUResultCache(2)
+	cache.Add("a", &Content{Data: []byte("a")})
+	cache.Add("b", &Content{Data: []byte("b")})
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+	cache.Add("c", &Content{Data: []byte("c")})
+
+	_, ok = cache.Get("b")
+	require.False(t, ok)
+	content, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, []byte("a"), content.Data)
+	content, ok = cache.Get("c")
+	require.True(t, ok)
+	require.Equal(t, []byte("c"), content.Data)
+}
+
+type countingResultHandler struct {
+	UnimplementedHandler
+	requests int
+}
+
+func (h *countingResultHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	h.requests++
+	if operationID == "running" {
+		return nil, ErrOperationStillRunning
+	}
+	return operationID, nil
+}
+
+func TestGetResult_Cached(t *testing.T) {
+	handler := &countingResultHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+	client.options.ResultCache = NewLRUResultCache(10)
+
+	handle, err := NewHandle(client, NewOperationReference[NoValue, string]("foo"), "done")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		result, err := handle.GetResult(ctx, GetOperationResultOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "done", result)
+	}
+	require.Equal(t, 1, handler.requests)
+
+	lazyHandle, err := client.NewHandle("foo", "done")
+	require.NoError(t, err)
+	value, err := lazyHandle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, value.Consume(&result))
+	require.Equal(t, "done", result)
+	require.Equal(t, 1, handler.requests)
+}
+
+func TestGetResult_StillRunningNotCached(t *testing.T) {
+	handler := &countingResultHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+	client.options.ResultCache = NewLRUResultCache(10)
+
+	handle, err := client.NewHandle("foo", "running")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+		require.ErrorIs(t, err, ErrOperationStillRunning)
+	}
+	require.Equal(t, 2, handler.requests)
+}
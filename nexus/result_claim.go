@@ -0,0 +1,311 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrOperationResultClaimed indicates that the outcome of an operation is claimed by another consumer of the same group
+// whose lease has not yet expired.
+var ErrOperationResultClaimed = errors.New("operation result claimed")
+
+// ErrOperationResultAcked indicates that the outcome of an operation has already been acknowledged by the consumer group
+// and can no longer be claimed by it.
+var ErrOperationResultAcked = errors.New("operation result already acknowledged")
+
+// ErrOperationResultClaimLost indicates that a claim could not be acknowledged because it is no longer the current
+// claim, typically because its lease expired and the outcome was claimed by another consumer.
+var ErrOperationResultClaimLost = errors.New("operation result claim lost")
+
+// HandlerResultClaim is the return type of [Handler.ClaimOperationResult].
+type HandlerResultClaim struct {
+	// Token identifying the claim, used to acknowledge it.
+	Token string
+	// Duration of the granted lease.
+	Lease time.Duration
+	// The claimed result, see [Handler.GetOperationResult] for supported values.
+	Result any
+	// Set instead of Result if the operation completed unsuccessfully.
+	Unsuccessful *UnsuccessfulOperationError
+}
+
+func (h *httpHandler) claimOperationResult(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	options := ClaimOperationResultOptions{
+		Header: httpHeaderToNexusHeader(request.Header),
+		Group:  request.URL.Query().Get(QueryGroup),
+	}
+	if leaseStr := request.URL.Query().Get(QueryLease); leaseStr != "" {
+		lease, err := time.ParseDuration(leaseStr)
+		if err != nil || lease < 0 {
+			h.logger.Warn("invalid lease duration query parameter", "lease", leaseStr)
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid lease query parameter"))
+			return
+		}
+		options.Lease = lease
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodClaimOperationResult,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	claim, err := h.options.Handler.ClaimOperationResult(ctx, operation, operationID, options)
+	if err != nil {
+		if errors.Is(err, ErrOperationStillRunning) {
+			writer.WriteHeader(StatusOperationRunning)
+		} else if errors.Is(err, ErrOperationResultClaimed) {
+			writer.WriteHeader(http.StatusConflict)
+		} else if errors.Is(err, ErrOperationResultAcked) {
+			writer.WriteHeader(http.StatusGone)
+		} else {
+			h.writeFailure(writer, err)
+		}
+		return
+	}
+	writer.Header().Set(HeaderClaimToken, claim.Token)
+	if claim.Lease > 0 {
+		writer.Header().Set(HeaderClaimLease, fmt.Sprintf("%dms", claim.Lease.Milliseconds()))
+	}
+	if claim.Unsuccessful != nil {
+		h.writeFailure(writer, claim.Unsuccessful)
+		return
+	}
+	h.writeResult(writer, operation, claim.Result)
+}
+
+func (h *httpHandler) ackOperationResult(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	token := request.Header.Get(HeaderClaimToken)
+	if token == "" {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "missing %q header", HeaderClaimToken))
+		return
+	}
+	options := AckOperationResultOptions{
+		Header: httpHeaderToNexusHeader(request.Header),
+		Group:  request.URL.Query().Get(QueryGroup),
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodAckOperationResult,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	if err := h.options.Handler.AckOperationResult(ctx, operation, operationID, token, options); err != nil {
+		if errors.Is(err, ErrOperationResultClaimLost) {
+			writer.WriteHeader(http.StatusConflict)
+		} else if errors.Is(err, ErrOperationResultAcked) {
+			writer.WriteHeader(http.StatusGone)
+		} else {
+			h.writeFailure(writer, err)
+		}
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+// ResultClaim is a claim on the outcome of an operation, obtained via [OperationHandle.ClaimResult].
+type ResultClaim[T any] struct {
+	// Token identifying the claim.
+	Token string
+	// Consumer group the claim was made for.
+	Group string
+	// Duration of the lease granted by the handler. Zero if not reported.
+	Lease time.Duration
+	// The claimed result. Zero if the operation completed unsuccessfully.
+	//
+	// If T is a [LazyValue], ensure that your consume it or read the underlying content in its entirety and close it to
+	// free up the underlying connection.
+	Result T
+
+	handle *OperationHandle[T]
+}
+
+// ClaimResult claims the outcome of a completed operation for exclusive processing, supporting pools of consumers
+// that must each process an outcome exactly once. Call [ResultClaim.Ack] once the outcome has been processed. Outcomes
+// that are not acknowledged before the claim's lease expires become available to be claimed again.
+//
+// If the operation completed unsuccessfully, a claim is returned along with an [UnsuccessfulOperationError]; the
+// failure must be acknowledged like a successful result.
+//
+// Returns [ErrOperationStillRunning] if the operation is still running, [ErrOperationResultClaimed] if the outcome is
+// claimed by another consumer, and [ErrOperationResultAcked] if the outcome was already acknowledged.
+func (h *OperationHandle[T]) ClaimResult(ctx context.Context, options ClaimOperationResultOptions, opts ...CallOption) (*ResultClaim[T], error) {
+	ctx, h = h.withCallOptions(ctx, opts)
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "result", "claim")
+	q := url.Query()
+	if options.Lease > 0 {
+		q.Set(QueryLease, FormatDurationParam(options.Lease))
+	}
+	if options.Group != "" {
+		q.Set(QueryGroup, options.Group)
+	}
+	url.RawQuery = q.Encode()
+	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := h.client.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &ResultClaim[T]{
+		Token:  response.Header.Get(HeaderClaimToken),
+		Group:  options.Group,
+		handle: h,
+	}
+	if leaseStr := response.Header.Get(HeaderClaimLease); leaseStr != "" {
+		// Tolerate invalid values, the lease is informational.
+		claim.Lease, _ = time.ParseDuration(leaseStr)
+	}
+
+	if response.StatusCode == http.StatusOK {
+		if claim.Token == "" {
+			response.Body.Close()
+			return nil, newUnexpectedResponseError(fmt.Sprintf("missing %q header", HeaderClaimToken), response, nil, h.client.options.JSON)
+		}
+		s := &LazyValue{
+			serializer:   h.client.options.Serializer,
+			transformers: h.client.options.ResultTransformers,
+			validate:     h.client.resultValidator(h.Operation),
+			Reader:       readerFromHTTPResponse(response),
+		}
+		if _, ok := any(claim.Result).(*LazyValue); ok {
+			claim.Result = any(s).(T)
+			return claim, nil
+		}
+		return claim, s.Consume(&claim.Result)
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case StatusOperationRunning:
+		return nil, ErrOperationStillRunning
+	case http.StatusConflict:
+		return nil, ErrOperationResultClaimed
+	case http.StatusGone:
+		return nil, ErrOperationResultAcked
+	case StatusOperationFailed:
+		if claim.Token == "" {
+			return nil, newUnexpectedResponseError(fmt.Sprintf("missing %q header", HeaderClaimToken), response, body, h.client.options.JSON)
+		}
+		state, err := getUnsuccessfulStateFromHeader(response, body, h.client.options.JSON)
+		if err != nil {
+			return nil, err
+		}
+		failure, err := failureFromResponse(response, body, h.client.options.JSON)
+		if err != nil {
+			return nil, err
+		}
+		return claim, &UnsuccessfulOperationError{
+			State:   state,
+			Failure: failure,
+		}
+	default:
+		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
+	}
+}
+
+// ProcessResult claims the outcome of a completed operation, invokes process with the claimed result or the
+// operation's [UnsuccessfulOperationError], and acknowledges the claim if process returns nil.
+//
+// Use distinct consumer groups in options to have multiple independent processors each handle the outcome once.
+// Claim errors such as [ErrOperationStillRunning], [ErrOperationResultClaimed], and [ErrOperationResultAcked] are
+// returned without invoking process.
+func (h *OperationHandle[T]) ProcessResult(ctx context.Context, options ClaimOperationResultOptions, process func(result T, err error) error, opts ...CallOption) error {
+	ctx, h = h.withCallOptions(ctx, opts)
+	claim, err := h.ClaimResult(ctx, options)
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	if err != nil && !errors.As(err, &unsuccessfulOperationError) {
+		return err
+	}
+	if err := process(claim.Result, err); err != nil {
+		return err
+	}
+	return claim.Ack(ctx, AckOperationResultOptions{Header: options.Header})
+}
+
+// Ack acknowledges that the claimed outcome has been processed, preventing it from being claimed again.
+// Acknowledging a claim more than once succeeds.
+//
+// Returns [ErrOperationResultClaimLost] if the claim's lease expired and the outcome was claimed by another consumer.
+func (c *ResultClaim[T]) Ack(ctx context.Context, options AckOperationResultOptions) error {
+	h := c.handle
+	url := h.client.options.PathBuilder.operationInstanceURL(h.client.serviceBaseURL, h.Operation, h.ID, "result", "ack")
+	if c.Group != "" {
+		q := url.Query()
+		q.Set(QueryGroup, c.Group)
+		url.RawQuery = q.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), nil)
+	if err != nil {
+		return err
+	}
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	request.Header.Set(HeaderClaimToken, c.Token)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := h.client.options.HTTPCaller(request)
+	if err != nil {
+		return err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		return ErrOperationResultClaimLost
+	case http.StatusGone:
+		return ErrOperationResultAcked
+	default:
+		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body, h.client.options.JSON)
+	}
+}
@@ -0,0 +1,129 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimResult_AsyncHandler(t *testing.T) {
+	release := make(chan struct{})
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		<-release
+		if operation == "fail" {
+			return nil, errors.New("boom")
+		}
+		return "result", nil
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	close(release)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+
+	first, err := handle.ClaimResult(ctx, ClaimOperationResultOptions{Lease: 100 * time.Millisecond})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Token)
+	require.Equal(t, 100*time.Millisecond, first.Lease)
+	var output string
+	require.NoError(t, first.Result.Consume(&output))
+	require.Equal(t, "result", output)
+
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationResultClaimed)
+
+	// The unacknowledged claim becomes available again after the lease expires.
+	time.Sleep(150 * time.Millisecond)
+	second, err := handle.ClaimResult(ctx, ClaimOperationResultOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, first.Token, second.Token)
+	require.NoError(t, second.Result.Consume(&output))
+	require.ErrorIs(t, first.Ack(ctx, AckOperationResultOptions{}), ErrOperationResultClaimLost)
+
+	require.NoError(t, second.Ack(ctx, AckOperationResultOptions{}))
+	require.NoError(t, second.Ack(ctx, AckOperationResultOptions{}))
+	require.ErrorIs(t, first.Ack(ctx, AckOperationResultOptions{}), ErrOperationResultAcked)
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationResultAcked)
+
+	// Unsuccessful outcomes are claimed along with the failure.
+	result, err = client.StartOperation(ctx, "fail", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.Error(t, err)
+	claim, err := result.Pending.ClaimResult(ctx, ClaimOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, "boom", unsuccessfulOperationError.Failure.Message)
+	require.NotNil(t, claim)
+	require.NoError(t, claim.Ack(ctx, AckOperationResultOptions{}))
+}
+
+func TestClaimResult_Unimplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &UnimplementedHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, 501, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestClaimResult_ConsumerGroups(t *testing.T) {
+	ctx, client, teardown := setupAsync(t, func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+		return "result", nil
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+
+	billing, err := handle.ClaimResult(ctx, ClaimOperationResultOptions{Group: "billing"})
+	require.NoError(t, err)
+	require.Equal(t, "billing", billing.Group)
+	require.NoError(t, billing.Result.Consume(new(string)))
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{Group: "billing"})
+	require.ErrorIs(t, err, ErrOperationResultClaimed)
+
+	// Other groups receive the result independently.
+	var processed []string
+	for _, group := range []string{"audit", "notifications"} {
+		err := handle.ProcessResult(ctx, ClaimOperationResultOptions{Group: group}, func(result *LazyValue, err error) error {
+			require.NoError(t, err)
+			var output string
+			require.NoError(t, result.Consume(&output))
+			processed = append(processed, group+": "+output)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"audit: result", "notifications: result"}, processed)
+	_, err = handle.ClaimResult(ctx, ClaimOperationResultOptions{Group: "audit"})
+	require.ErrorIs(t, err, ErrOperationResultAcked)
+
+	// A failed processor leaves the claim unacknowledged.
+	err = handle.ProcessResult(ctx, ClaimOperationResultOptions{Lease: 50 * time.Millisecond}, func(result *LazyValue, err error) error {
+		return errors.New("processing failed")
+	})
+	require.ErrorContains(t, err, "processing failed")
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, handle.ProcessResult(ctx, ClaimOperationResultOptions{}, func(result *LazyValue, err error) error {
+		return result.Consume(new(string))
+	}))
+
+	require.NoError(t, billing.Ack(ctx, AckOperationResultOptions{}))
+}
@@ -0,0 +1,97 @@
+package nexus
+
+import (
+	"context"
+)
+
+// ResultPage may be returned from [Handler.GetOperationResult] to respond with one page of a large result instead of
+// the entire result in a single response. Clients fetch the remaining pages by passing NextPageToken as
+// [GetOperationResultOptions.PageToken], see [OperationHandle.GetResultPages].
+//
+// Only return a page when [GetOperationResultOptions.AcceptPages] is set, otherwise respond with the entire result.
+type ResultPage struct {
+	// Value of the page, handled like any other result value, e.g. a [Reader] is streamed and a [ResultRedirect] is
+	// sent as a redirect.
+	Value any
+	// Token of the next page, empty on the last page.
+	NextPageToken string
+}
+
+// ResultPageIterator iterates over the pages of an operation's result, fetching them from the handler one at a time.
+// Create one with [OperationHandle.GetResultPages].
+//
+//	it := handle.GetResultPages(nexus.GetOperationResultOptions{Wait: time.Minute})
+//	for it.Next(ctx) {
+//		var chunk []Record
+//		if err := it.Page().Consume(&chunk); err != nil {
+//			// ...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// A ResultPageIterator is not safe for concurrent use.
+type ResultPageIterator struct {
+	get     func(context.Context, GetOperationResultOptions) (*LazyValue, string, error)
+	options GetOperationResultOptions
+	current *LazyValue
+	done    bool
+	err     error
+}
+
+// GetResultPages returns a [ResultPageIterator] over the pages of the operation's result, starting at
+// [GetOperationResultOptions.PageToken] if set. Handlers that do not page their results respond with the entire result
+// as a single page.
+//
+// Wait applies to the first page only, the operation has completed once it is returned. Errors getting the first page
+// are the same as those returned by [OperationHandle.GetResult]. The [ClientOptions.ResultCache] is not used.
+func (h *OperationHandle[T]) GetResultPages(options GetOperationResultOptions, opts ...CallOption) *ResultPageIterator {
+	options.AcceptPages = true
+	return &ResultPageIterator{
+		get: func(ctx context.Context, options GetOperationResultOptions) (*LazyValue, string, error) {
+			ctx, h := h.withCallOptions(ctx, opts)
+			return h.getResultPage(ctx, options)
+		},
+		options: options,
+	}
+}
+
+// Next advances the iterator to the next page, closing the previous page if it was not fully read. It returns false
+// when there are no more pages or fetching a page failed, see [ResultPageIterator.Err].
+func (it *ResultPageIterator) Next(ctx context.Context) bool {
+	if it.current != nil {
+		it.current.Reader.Close()
+		it.current = nil
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+	page, nextPageToken, err := it.get(ctx, it.options)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.current = page
+	it.options.PageToken = nextPageToken
+	it.options.Wait = 0
+	it.done = nextPageToken == ""
+	return true
+}
+
+// Page returns the page the iterator is positioned at by the last successful call to [ResultPageIterator.Next]. The
+// page must be consumed, or read from, before calling Next again, it is invalid afterwards.
+func (it *ResultPageIterator) Page() *LazyValue {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ResultPageIterator) Err() error {
+	return it.err
+}
+
+// PageToken returns a token for resuming the iteration after the current page via
+// [GetOperationResultOptions.PageToken]. Empty once the last page was fetched.
+func (it *ResultPageIterator) PageToken() string {
+	return it.options.PageToken
+}
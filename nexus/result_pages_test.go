@@ -0,0 +1,110 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pagingHandler returns its values one page at a time, using the index of the page as its token.
+type pagingHandler struct {
+	UnimplementedHandler
+	values []string
+}
+
+func (h *pagingHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if !options.AcceptPages {
+		return h.values, nil
+	}
+	i := 0
+	if options.PageToken != "" {
+		var err error
+		if i, err = strconv.Atoi(options.PageToken); err != nil || i < 0 || i >= len(h.values) {
+			return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid page token")
+		}
+	}
+	page := &ResultPage{Value: h.values[i]}
+	if i+1 < len(h.values) {
+		page.NextPageToken = strconv.Itoa(i + 1)
+	}
+	return page, nil
+}
+
+func TestGetResultPages(t *testing.T) {
+	handler := &pagingHandler{values: []string{"a", "b", "c"}}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+
+	it := handle.GetResultPages(GetOperationResultOptions{})
+	var values, tokens []string
+	for it.Next(ctx) {
+		var value string
+		require.NoError(t, it.Page().Consume(&value))
+		values = append(values, value)
+		tokens = append(tokens, it.PageToken())
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []string{"a", "b", "c"}, values)
+	require.Equal(t, []string{"1", "2", ""}, tokens)
+
+	// Resume from a token.
+	it = handle.GetResultPages(GetOperationResultOptions{PageToken: "2"})
+	require.True(t, it.Next(ctx))
+	var value string
+	require.NoError(t, it.Page().Consume(&value))
+	require.Equal(t, "c", value)
+	require.False(t, it.Next(ctx))
+	require.NoError(t, it.Err())
+
+	// Unread pages are closed when advancing.
+	it = handle.GetResultPages(GetOperationResultOptions{})
+	require.True(t, it.Next(ctx))
+	require.True(t, it.Next(ctx))
+	require.NoError(t, it.Page().Consume(&value))
+	require.Equal(t, "b", value)
+
+	// Clients not asking for pages get the entire result.
+	result, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var all []string
+	require.NoError(t, result.Consume(&all))
+	require.Equal(t, []string{"a", "b", "c"}, all)
+}
+
+func TestGetResultPages_Error(t *testing.T) {
+	ctx, client, teardown := setup(t, &pagingHandler{values: []string{"a"}})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+
+	it := handle.GetResultPages(GetOperationResultOptions{PageToken: "invalid"})
+	require.False(t, it.Next(ctx))
+	var unexpectedResponseErr *UnexpectedResponseError
+	require.ErrorAs(t, it.Err(), &unexpectedResponseErr)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseErr.Response.StatusCode)
+	require.Nil(t, it.Page())
+}
+
+func TestGetResultPages_NonPagingHandler(t *testing.T) {
+	ctx, client, teardown := setup(t, &redirectHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+
+	it := handle.GetResultPages(GetOperationResultOptions{})
+	require.True(t, it.Next(ctx))
+	var value []byte
+	require.NoError(t, it.Page().Consume(&value))
+	require.Equal(t, []byte("inline"), value)
+	require.Equal(t, "", it.PageToken())
+	require.False(t, it.Next(ctx))
+	require.NoError(t, it.Err())
+}
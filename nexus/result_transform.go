@@ -0,0 +1,54 @@
+package nexus
+
+// A ResultTransformer transforms the content of results received by a client before they are deserialized, e.g. to
+// decompress or decrypt them, or to migrate them from an older schema, see [ClientOptions.ResultTransformers].
+//
+// Transformers decide whether to apply based on the content's [Header] and must return content they do not apply to
+// unchanged.
+type ResultTransformer interface {
+	// TransformResult transforms received result content.
+	TransformResult(*Content) (*Content, error)
+}
+
+// ResultTransformerFunc is a [ResultTransformer] backed by a function.
+type ResultTransformerFunc func(*Content) (*Content, error)
+
+// TransformResult implements [ResultTransformer].
+func (f ResultTransformerFunc) TransformResult(content *Content) (*Content, error) {
+	return f(content)
+}
+
+// NewHeaderResultTransformer creates a [ResultTransformer] that applies transform to content whose header has the
+// given value for key, e.g. to migrate results with a "schema" content header of "v1". Keys are lower case and
+// without the "Content-" prefix of their HTTP header, see [Header].
+func NewHeaderResultTransformer(key, value string, transform func(*Content) (*Content, error)) ResultTransformer {
+	return ResultTransformerFunc(func(content *Content) (*Content, error) {
+		if content.Header.Get(key) != value {
+			return content, nil
+		}
+		return transform(content)
+	})
+}
+
+// DecodeResults adapts a [ContentTransformer], e.g. one created with [NewGzipTransformer] or
+// [NewEncryptionTransformer], to a [ResultTransformer] decoding received results only, for services that encode their
+// results while the client's serializer does not.
+func DecodeResults(transformer ContentTransformer) ResultTransformer {
+	return ResultTransformerFunc(transformer.Decode)
+}
+
+// transformResult applies transformers to content in order.
+func transformResult(transformers []ResultTransformer, content *Content) (*Content, error) {
+	original := content
+	for _, t := range transformers {
+		var err error
+		if content, err = t.TransformResult(content); err != nil {
+			return nil, err
+		}
+	}
+	if content != original {
+		// Keep the digest of transformed content valid for the data it is deserialized with.
+		content = redigest(content)
+	}
+	return content, nil
+}
@@ -0,0 +1,108 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testResultKeys = EncryptionKeys{ActiveKeyID: "k1", Keys: map[string][]byte{"k1": make([]byte, 32)}}
+
+type versionedResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *versionedResultHandler) result() (*Content, error) {
+	encryption, err := NewEncryptionTransformer(testResultKeys)
+	if err != nil {
+		return nil, err
+	}
+	// Results of the "v1" schema name the customer "name" rather than "fullName".
+	return encryption.Encode(&Content{
+		Header: Header{"type": contentTypeJSON, "schema": "v1"},
+		Data:   []byte(`{"name":"Ada"}`),
+	})
+}
+
+func (h *versionedResultHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	if operation == "async" {
+		return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+	}
+	content, err := h.result()
+	if err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{Value: content}, nil
+}
+
+func (h *versionedResultHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return h.result()
+}
+
+func TestResultTransformers(t *testing.T) {
+	migrate := NewHeaderResultTransformer("schema", "v1", func(content *Content) (*Content, error) {
+		var v1 struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(content.Data, &v1); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(map[string]string{"fullName": v1.Name})
+		if err != nil {
+			return nil, err
+		}
+		header := maps.Clone(content.Header)
+		header["schema"] = "v2"
+		return &Content{Header: header, Data: data}, nil
+	})
+	encryption, err := NewEncryptionTransformer(testResultKeys)
+	require.NoError(t, err)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &versionedResultHandler{}}, ClientOptions{
+		ResultTransformers: []ResultTransformer{DecodeResults(encryption), migrate},
+	})
+	defer teardown()
+
+	type customer struct {
+		FullName string `json:"fullName"`
+	}
+
+	result, err := client.StartOperation(ctx, "sync", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var c customer
+	require.NoError(t, result.Successful.Consume(&c))
+	require.Equal(t, "Ada", c.FullName)
+
+	result, err = client.StartOperation(ctx, "async", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	for _, wait := range []time.Duration{0, time.Second} {
+		value, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: wait})
+		require.NoError(t, err)
+		c = customer{}
+		require.NoError(t, value.ConsumeStream(&c))
+		require.Equal(t, "Ada", c.FullName)
+	}
+
+	// The raw content of results is not transformed.
+	value, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "aes-gcm", value.Reader.Header["encryption"])
+	require.NoError(t, value.Reader.Close())
+
+	// Transformation errors fail deserialization.
+	failing, err := NewClient(ClientOptions{
+		ServiceBaseURL: client.options.ServiceBaseURL,
+		ResultTransformers: []ResultTransformer{ResultTransformerFunc(func(content *Content) (*Content, error) {
+			return nil, errors.New("unsupported schema")
+		})},
+	})
+	require.NoError(t, err)
+	result, err = failing.StartOperation(ctx, "sync", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.EqualError(t, result.Successful.Consume(&c), "unsupported schema")
+}
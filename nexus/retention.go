@@ -0,0 +1,113 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// An OperationDeleter is an [OperationStore] that supports deleting records, enabling
+// [AsyncHandler.PurgeExpiredOperations].
+type OperationDeleter interface {
+	// Delete deletes the record for the given operation name and ID. Deleting a record that does not exist is not an
+	// error.
+	Delete(ctx context.Context, operation, operationID string) error
+}
+
+// Delete implements OperationDeleter.
+func (s *MemoryOperationStore) Delete(ctx context.Context, operation, operationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryStoreKey{operation, operationID}
+	delete(s.records, key)
+	s.notifyLocked(key)
+	return nil
+}
+
+var _ OperationDeleter = &MemoryOperationStore{}
+
+// IsExpired reports whether the record is of a completed operation whose outcome is no longer retained at now.
+func (r *OperationRecord) IsExpired(now time.Time) bool {
+	return r.State != OperationStateRunning && !r.ExpireTime.IsZero() && !now.Before(r.ExpireTime)
+}
+
+// retention returns how long the outcome of the given operation is retained after it completes.
+func (h *AsyncHandler) retention(operation string) time.Duration {
+	if retention, ok := h.options.Retentions[operation]; ok {
+		return retention
+	}
+	return h.options.DefaultRetention
+}
+
+// setExpireTime sets the expiry of a record that is transitioned to a terminal state according to its operation's
+// retention.
+func (h *AsyncHandler) setExpireTime(record *OperationRecord) {
+	if record.State == OperationStateRunning {
+		return
+	}
+	if retention := h.retention(record.Operation); retention > 0 {
+		record.ExpireTime = time.Now().Add(retention)
+	}
+}
+
+// PurgeExpiredOperations deletes the records of completed operations whose outcome has expired according to
+// [AsyncHandlerOptions.Retentions] and returns summaries of the purged operations. Requires the Store to implement
+// [OperationLister] and [OperationDeleter].
+//
+// Results stored in a [PayloadBackend] are not deleted, use the backend's own expiry mechanism, e.g. an S3 lifecycle
+// rule, with a period at least as long as the longest retention.
+//
+// Run this periodically, or use [AsyncHandler.RunReaper].
+func (h *AsyncHandler) PurgeExpiredOperations(ctx context.Context) ([]*OperationSummary, error) {
+	lister, ok := h.options.Store.(OperationLister)
+	if !ok {
+		return nil, errors.New("listing operations is not supported by the store")
+	}
+	deleter, ok := h.options.Store.(OperationDeleter)
+	if !ok {
+		return nil, errors.New("deleting operations is not supported by the store")
+	}
+	filter := OperationFilter{
+		States:        []OperationState{OperationStateSucceeded, OperationStateFailed, OperationStateCanceled},
+		ExpiredBefore: time.Now(),
+	}
+	var purged []*OperationSummary
+	var pageToken string
+	for {
+		records, nextPageToken, err := lister.ListOperations(ctx, filter, DefaultListPageSize, pageToken)
+		if err != nil {
+			return purged, err
+		}
+		for _, record := range records {
+			if err := deleter.Delete(ctx, record.Operation, record.ID); err != nil {
+				return purged, err
+			}
+			purged = append(purged, record.Summary())
+		}
+		if nextPageToken == "" {
+			return purged, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// RunReaper purges expired operations via [AsyncHandler.PurgeExpiredOperations] every interval until ctx is done.
+// Failures are logged and retried at the next interval.
+func (h *AsyncHandler) RunReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			purged, err := h.PurgeExpiredOperations(ctx)
+			if err != nil && ctx.Err() == nil {
+				h.options.Logger.Warn("failed to purge expired operations", "error", err)
+			}
+			if len(purged) > 0 {
+				h.options.Logger.Debug("purged expired operations", "count", len(purged))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
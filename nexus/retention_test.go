@@ -0,0 +1,110 @@
+package nexus
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Retention(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return []byte("ok"), nil
+		},
+		Retentions: map[string]time.Duration{"foo": 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	expiring, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	retained, err := client.StartOperation(ctx, "bar", nil, StartOperationOptions{})
+	require.NoError(t, err)
+
+	var result []byte
+	value, err := expiring.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, value.Consume(&result))
+	require.Equal(t, []byte("ok"), result)
+	info, err := expiring.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, info.ExpireTime)
+	require.WithinDuration(t, time.Now().Add(50*time.Millisecond), *info.ExpireTime, time.Second)
+
+	value, err = retained.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, value.Consume(&result))
+	info, err = retained.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Nil(t, info.ExpireTime)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = expiring.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationResultGone)
+	_, err = handler.ClaimOperationResult(ctx, "foo", expiring.Pending.ID, ClaimOperationResultOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+
+	purged, err := handler.PurgeExpiredOperations(ctx)
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	require.Equal(t, expiring.Pending.ID, purged[0].ID)
+
+	_, err = expiring.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorContains(t, err, "404 Not Found")
+	_, err = retained.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+}
+
+func TestAsyncHandler_RunReaper(t *testing.T) {
+	store := NewMemoryOperationStore()
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: store,
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := time.Now()
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "expired", State: OperationStateSucceeded, CreatedAt: now, ExpireTime: now.Add(-time.Second)}))
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "retained", State: OperationStateSucceeded, CreatedAt: now}))
+	require.NoError(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "running", State: OperationStateRunning, CreatedAt: now, ExpireTime: now.Add(-time.Second)}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.RunReaper(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		_, err := store.Get(ctx, "foo", "expired")
+		return err == ErrOperationRecordNotFound
+	}, time.Second, 10*time.Millisecond)
+	_, err = store.Get(ctx, "foo", "retained")
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "foo", "running")
+	require.NoError(t, err)
+
+	cancel()
+	<-done
+}
+
+func TestOperationFilter_ExpiredBefore(t *testing.T) {
+	now := time.Now()
+	filter := OperationFilter{ExpiredBefore: now}
+	require.True(t, filter.Matches(&OperationRecord{State: OperationStateFailed, ExpireTime: now.Add(-time.Second)}))
+	require.False(t, filter.Matches(&OperationRecord{State: OperationStateFailed, ExpireTime: now.Add(time.Second)}))
+	require.False(t, filter.Matches(&OperationRecord{State: OperationStateFailed}))
+
+	parsed, err := operationFilterFromQuery(addOperationFilterToQuery(filter, url.Values{}))
+	require.NoError(t, err)
+	require.True(t, now.Equal(parsed.ExpiredBefore))
+}
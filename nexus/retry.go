@@ -0,0 +1,149 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"runtime/debug"
+	"time"
+)
+
+// RetryPolicy configures retries of failed executions of operations started via an [AsyncHandler].
+type RetryPolicy struct {
+	// Maximum number of execution attempts, including the first. Zero or one disables retries.
+	MaxAttempts int
+	// Delay before the first retry. Defaults to one second.
+	InitialInterval time.Duration
+	// Factor by which the delay grows with every retry. Defaults to 2.
+	BackoffCoefficient float64
+	// Maximum delay between retries. Defaults to one minute.
+	MaxInterval time.Duration
+	// Reports whether an execution error is transient and the execution should be retried.
+	// Defaults to retrying all errors except [UnsuccessfulOperationError], which always completes the operation, and
+	// errors wrapping a [FailureError] whose failure is marked NonRetryable.
+	IsRetryable func(error) bool
+}
+
+// interval returns the delay before the given retry, starting at 1.
+func (p RetryPolicy) interval(retry int) time.Duration {
+	initial := p.InitialInterval
+	if initial <= 0 {
+		initial = time.Second
+	}
+	coefficient := p.BackoffCoefficient
+	if coefficient < 1 {
+		coefficient = 2
+	}
+	max := p.MaxInterval
+	if max <= 0 {
+		max = time.Minute
+	}
+	interval := float64(initial) * math.Pow(coefficient, float64(retry-1))
+	if interval > float64(max) {
+		return max
+	}
+	return time.Duration(interval)
+}
+
+// retryable reports whether an execution failing with err should be retried under this policy.
+func (p RetryPolicy) retryable(err error) bool {
+	var unsuccessfulError *UnsuccessfulOperationError
+	if errors.As(err, &unsuccessfulError) {
+		return false
+	}
+	if p.IsRetryable != nil {
+		return p.IsRetryable(err)
+	}
+	var failureError *FailureError
+	return !errors.As(err, &failureError) || !failureError.Failure.NonRetryable
+}
+
+// OperationAttempt is a failed execution attempt of an operation that was retried.
+type OperationAttempt struct {
+	// Attempt number, starting at 1.
+	Attempt int `json:"attempt"`
+	// Time the attempt started.
+	StartTime time.Time `json:"startTime"`
+	// Time the attempt failed.
+	EndTime time.Time `json:"endTime"`
+	// Failure the attempt failed with.
+	Failure Failure `json:"failure"`
+}
+
+// retryPolicy returns the retry policy for the given operation.
+func (h *AsyncHandler) retryPolicy(operation string) RetryPolicy {
+	if policy, ok := h.options.RetryPolicies[operation]; ok {
+		return policy
+	}
+	return h.options.DefaultRetryPolicy
+}
+
+// executeWithRetries invokes the executor, retrying transient failures according to the operation's retry policy and
+// recording every retried attempt in the operation's attempt history.
+func (h *AsyncHandler) executeWithRetries(ctx context.Context, record *OperationRecord, content *Content, options StartOperationOptions) (any, error) {
+	policy := h.retryPolicy(record.Operation)
+	var panics int
+	for attempt := 1; ; attempt++ {
+		input := &LazyValue{
+			serializer: h.options.Serializer,
+			Reader: &Reader{
+				io.NopCloser(bytes.NewReader(content.Data)),
+				content.Header,
+			},
+		}
+		startTime := time.Now()
+		result, err := h.executeAttempt(ctx, record, input, options)
+		if err == nil || ctx.Err() != nil || isCancelRequested(ctx) {
+			return result, err
+		}
+		var panicErr *executionPanicError
+		if errors.As(err, &panicErr) {
+			panics++
+		}
+		retryable := policy.retryable(err)
+		if h.shouldQuarantine(err, panics, retryable) {
+			return nil, &quarantineError{err}
+		}
+		if attempt >= policy.MaxAttempts || !retryable {
+			return result, err
+		}
+
+		h.options.Logger.Warn("operation execution failed, retrying", "operation", record.Operation, "operationID", record.ID, "attempt", attempt, "error", err)
+		failed := OperationAttempt{
+			Attempt:   attempt,
+			StartTime: startTime,
+			EndTime:   time.Now(),
+			Failure:   Failure{Message: err.Error()},
+		}
+		err = h.transition(ctx, record.Operation, record.ID, func(record *OperationRecord) {
+			record.AttemptHistory = append(record.AttemptHistory, failed)
+		})
+		if err != nil {
+			h.options.Logger.Error("failed to record operation attempt", "operation", record.Operation, "operationID", record.ID, "error", err)
+		}
+
+		timer := time.NewTimer(policy.interval(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-CancelRequested(ctx):
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// executeAttempt invokes the executor, recovering panics as [executionPanicError]s.
+func (h *AsyncHandler) executeAttempt(ctx context.Context, record *OperationRecord, input *LazyValue, options StartOperationOptions) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.options.Logger.Error("operation execution panicked", "operation", record.Operation, "operationID", record.ID, "panic", r, "stack", string(debug.Stack()))
+			result, err = nil, &executionPanicError{r}
+		}
+	}()
+	return h.options.Executor(ctx, record.Operation, input, options)
+}
@@ -0,0 +1,64 @@
+package nexus
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const headerRetryAfter = "Retry-After"
+
+// parseRetryAfter parses a Retry-After header value, either a number of seconds or an HTTP date, into the delay
+// relative to now. Dates in the past yield a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// retryAfterFromHTTPHeader returns the delay of the Retry-After header field, or zero if absent or invalid.
+func retryAfterFromHTTPHeader(header http.Header) time.Duration {
+	delay, _ := parseRetryAfter(header.Get(headerRetryAfter), time.Now())
+	return delay
+}
+
+// formatRetryAfter formats a delay as a Retry-After header value, rounding up to whole seconds.
+func formatRetryAfter(delay time.Duration) string {
+	return strconv.FormatInt(int64((delay+time.Second-1)/time.Second), 10)
+}
+
+// RetryAfter returns the delay a handler asked the caller to wait for before retrying a request that failed with err,
+// via the Retry-After header of a 429 (Too Many Requests) or 503 (Service Unavailable) response. Returns false if err
+// carries no such delay.
+//
+// Use it to schedule retries of requests the client does not retry itself.
+func RetryAfter(err error) (time.Duration, bool) {
+	var quotaExceededError *QuotaExceededError
+	if errors.As(err, &quotaExceededError) {
+		return quotaExceededError.RetryAfter, quotaExceededError.RetryAfter > 0
+	}
+	var unexpectedResponseError *UnexpectedResponseError
+	if errors.As(err, &unexpectedResponseError) && unexpectedResponseError.Response != nil {
+		switch unexpectedResponseError.Response.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return unexpectedResponseError.RetryAfter, unexpectedResponseError.RetryAfter > 0
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	delay, ok := parseRetryAfter("120", now)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, delay)
+	delay, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, delay)
+	delay, ok = parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), delay)
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok = parseRetryAfter(value, now)
+		require.False(t, ok, value)
+	}
+	require.Equal(t, "2", formatRetryAfter(1500*time.Millisecond))
+}
+
+func TestRetryAfter(t *testing.T) {
+	response := func(statusCode int) *http.Response {
+		return &http.Response{StatusCode: statusCode, Header: http.Header{"Retry-After": []string{"3"}}}
+	}
+	delay, ok := RetryAfter(newUnexpectedResponseError("unavailable", response(http.StatusServiceUnavailable), nil, JSONEngine{}))
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, delay)
+	_, ok = RetryAfter(newUnexpectedResponseError("internal", response(http.StatusInternalServerError), nil, JSONEngine{}))
+	require.False(t, ok)
+
+	header := addQuotaExceededErrorToHTTPHeader(&QuotaExceededError{Subject: "a", RetryAfter: time.Minute}, http.Header{})
+	quotaExceededError, err := quotaExceededErrorFromHTTPHeader(header)
+	require.NoError(t, err)
+	delay, ok = RetryAfter(quotaExceededError)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, delay)
+}
+
+// overloadedResultHandler rejects the first get result request as unavailable.
+type overloadedResultHandler struct {
+	UnimplementedHandler
+	requests atomic.Int32
+}
+
+func (h *overloadedResultHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if h.requests.Add(1) == 1 {
+		return nil, HandlerErrorf(HandlerErrorTypeUnavailable, "overloaded")
+	}
+	return "done", nil
+}
+
+func TestGetResult_RetryAfter(t *testing.T) {
+	handler := &overloadedResultHandler{}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: handler,
+		ResponseHeaderProvider: func(ctx context.Context, statusCode int, err error) Header {
+			if statusCode == http.StatusServiceUnavailable {
+				return Header{"Retry-After": "1"}
+			}
+			return nil
+		},
+	}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	// Requests without a wait period are not retried.
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	delay, ok := RetryAfter(err)
+	require.True(t, ok)
+	require.Equal(t, time.Second, delay)
+
+	handler.requests.Store(0)
+	start := time.Now()
+	value, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: 5 * time.Second})
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, value.Consume(&result))
+	require.Equal(t, "done", result)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+	require.Equal(t, int32(2), handler.requests.Load())
+}
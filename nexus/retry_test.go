@@ -0,0 +1,111 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler_Retries(t *testing.T) {
+	var attempts int
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			var in string
+			if err := input.Consume(&in); err != nil {
+				return nil, err
+			}
+			attempts++
+			if operation == "flaky" && attempts < 3 {
+				return nil, errors.New("transient")
+			}
+			if operation == "broken" {
+				return nil, errors.New("broken")
+			}
+			return in, nil
+		},
+		RetryPolicies: map[string]RetryPolicy{
+			"flaky": {MaxAttempts: 5, InitialInterval: time.Millisecond},
+		},
+		DefaultRetryPolicy: RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "flaky", "input", StartOperationOptions{})
+	require.NoError(t, err)
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var out string
+	require.NoError(t, value.Consume(&out))
+	require.Equal(t, "input", out)
+
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Len(t, info.AttemptHistory, 2)
+	for i, attempt := range info.AttemptHistory {
+		require.Equal(t, i+1, attempt.Attempt)
+		require.Equal(t, "transient", attempt.Failure.Message)
+		require.False(t, attempt.EndTime.Before(attempt.StartTime))
+	}
+
+	// Retries are exhausted before the operation fails.
+	attempts = 0
+	result, err = client.StartOperation(ctx, "broken", "input", StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, "broken", unsuccessfulOperationError.Failure.Message)
+	require.Equal(t, 2, attempts)
+	info, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Len(t, info.AttemptHistory, 1)
+}
+
+func TestAsyncHandler_RetriesNonRetryable(t *testing.T) {
+	var attempts int
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			attempts++
+			if operation == "unsuccessful" {
+				return nil, &UnsuccessfulOperationError{State: OperationStateFailed, Failure: Failure{Message: "invalid"}}
+			}
+			return nil, errors.New("permanent")
+		},
+		DefaultRetryPolicy: RetryPolicy{
+			MaxAttempts:     5,
+			InitialInterval: time.Millisecond,
+			IsRetryable: func(err error) bool {
+				return err.Error() != "permanent"
+			},
+		},
+	})
+	require.NoError(t, err)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	for _, operation := range []string{"unsuccessful", "permanent"} {
+		attempts = 0
+		result, err := client.StartOperation(ctx, operation, nil, StartOperationOptions{})
+		require.NoError(t, err)
+		_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		require.ErrorAs(t, err, &unsuccessfulOperationError)
+		require.Equal(t, 1, attempts)
+	}
+}
+
+func TestRetryPolicy_Interval(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second}
+	require.Equal(t, 100*time.Millisecond, policy.interval(1))
+	require.Equal(t, 200*time.Millisecond, policy.interval(2))
+	require.Equal(t, 800*time.Millisecond, policy.interval(4))
+	require.Equal(t, time.Second, policy.interval(5))
+	require.Equal(t, time.Second, RetryPolicy{}.interval(1))
+}
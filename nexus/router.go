@@ -0,0 +1,95 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var errMethodNotAllowed = errors.New("method not allowed")
+
+// router dispatches requests to handlers by method and escaped URL path. Path templates consist of literal escaped
+// segments and {name} variables, each matching a single non-empty escaped segment, so encoded slashes in variables are
+// preserved. Routes are matched in the order they were added.
+type router struct {
+	routes []routerRoute
+	// Called for requests whose path matches no route.
+	notFound http.HandlerFunc
+	// Called for requests whose path matches a route but whose method does not, after setting the Allow header.
+	methodNotAllowed http.HandlerFunc
+}
+
+type routerRoute struct {
+	method   string
+	segments []string
+	handler  http.HandlerFunc
+}
+
+type routeVarsKey struct{}
+
+// handle adds a route for requests with the given method and a path matching the given template.
+func (r *router) handle(method, template string, handler http.HandlerFunc) {
+	r.routes = append(r.routes, routerRoute{method: method, segments: splitPath(template), handler: handler})
+}
+
+// ServeHTTP implements http.Handler.
+func (r *router) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	segments := splitPath(request.URL.EscapedPath())
+	var allowed []string
+	for _, route := range r.routes {
+		vars, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+		if route.method != request.Method {
+			allowed = append(allowed, route.method)
+			continue
+		}
+		route.handler(writer, request.WithContext(context.WithValue(request.Context(), routeVarsKey{}, vars)))
+		return
+	}
+	if len(allowed) == 0 {
+		r.notFound(writer, request)
+		return
+	}
+	// Several routes with the same method may match, e.g. templates with variables in different segments.
+	slices.Sort(allowed)
+	writer.Header().Set("Allow", strings.Join(slices.Compact(allowed), ", "))
+	r.methodNotAllowed(writer, request)
+}
+
+// match returns the variables of the route if it matches the given path segments.
+func (r routerRoute) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	var vars map[string]string
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if vars == nil {
+				vars = make(map[string]string, 2)
+			}
+			vars[segment[1:len(segment)-1]] = segments[i]
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+// splitPath splits an escaped path into its segments, ignoring the leading slash. The root path has a single empty
+// segment.
+func splitPath(p string) []string {
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
+
+// routeVars returns the escaped variables of the route a request was matched to.
+func routeVars(request *http.Request) map[string]string {
+	vars, _ := request.Context().Value(routeVarsKey{}).(map[string]string)
+	return vars
+}
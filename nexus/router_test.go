@@ -0,0 +1,115 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Match(t *testing.T) {
+	var matched string
+	var vars map[string]string
+	r := &router{
+		notFound: func(writer http.ResponseWriter, request *http.Request) { writer.WriteHeader(http.StatusNotFound) },
+		methodNotAllowed: func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		},
+	}
+	for _, template := range []string{"/", "/_admin/cancel", "/{operation}", "/{operation}/{operation_id}", "/{operation}/{operation_id}/result"} {
+		template := template
+		r.handle("POST", template, func(writer http.ResponseWriter, request *http.Request) {
+			matched, vars = template, routeVars(request)
+		})
+	}
+
+	for _, c := range []struct {
+		path, template string
+		vars           map[string]string
+	}{
+		{"/", "/", nil},
+		{"/_admin/cancel", "/_admin/cancel", nil},
+		{"/a%2Fb", "/{operation}", map[string]string{"operation": "a%2Fb"}},
+		{"/op/id%20x/result", "/{operation}/{operation_id}/result", map[string]string{"operation": "op", "operation_id": "id%20x"}},
+	} {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest("POST", c.path, nil))
+		require.Equal(t, c.template, matched, c.path)
+		require.Equal(t, c.vars, vars, c.path)
+	}
+
+	for _, path := range []string{"/op/", "//result", "/op/id/result/extra"} {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest("POST", path, nil))
+		require.Equal(t, http.StatusNotFound, recorder.Code, path)
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/op", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, "POST", recorder.Header().Get("Allow"))
+
+	// Methods of several matching routes are listed once.
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/_admin/cancel", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, "POST", recorder.Header().Get("Allow"))
+}
+
+func TestHTTPHandler_UnmatchedRequests(t *testing.T) {
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: UnimplementedHandler{}})
+
+	recorder := httptest.NewRecorder()
+	httpHandler.ServeHTTP(recorder, httptest.NewRequest("GET", "/op/id/unknown", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	require.Equal(t, contentTypeJSON, recorder.Header().Get("Content-Type"))
+	var failure Failure
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &failure))
+	require.Equal(t, "not found", failure.Message)
+
+	recorder = httptest.NewRecorder()
+	httpHandler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/op/id", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, "GET", recorder.Header().Get("Allow"))
+	require.Equal(t, contentTypeJSON, recorder.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &failure))
+	require.Equal(t, "method not allowed", failure.Message)
+}
+
+func TestHTTPHandler_ReservedOperationNames(t *testing.T) {
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: UnimplementedHandler{}})
+	for _, operation := range reservedOperationNames {
+		recorder := httptest.NewRecorder()
+		httpHandler.ServeHTTP(recorder, httptest.NewRequest("POST", "/"+operation, nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code, operation)
+		var failure Failure
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &failure))
+		require.Equal(t, `reserved operation name: "`+operation+`"`, failure.Message)
+	}
+
+	client, err := NewClient(ClientOptions{ServiceBaseURL: "http://localhost"})
+	require.NoError(t, err)
+	_, err = client.StartOperation(context.Background(), "_nexus", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, errReservedOperationName)
+}
+
+func TestHTTPHandler_UnmatchedRequests_Client(t *testing.T) {
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		Handler: UnimplementedHandler{},
+		ResponseHeaderProvider: func(ctx context.Context, statusCode int, err error) Header {
+			return Header{"x-status": strconv.Itoa(statusCode)}
+		},
+	}, ClientOptions{PathBuilder: PathBuilder{Prefix: "/api/v2"}})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "404", unexpectedResponseError.Response.Header.Get("x-status"))
+	require.Equal(t, &Failure{Message: "not found"}, unexpectedResponseError.Failure)
+	require.EqualError(t, err, `unexpected response status: "404 Not Found": not found`)
+}
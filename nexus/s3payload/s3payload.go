@@ -0,0 +1,297 @@
+// Package s3payload provides an S3 compatible implementation of [nexus.PayloadBackend].
+//
+// The package does not depend on the AWS SDK. Requests are signed with AWS Signature Version 4 and work with Amazon S3
+// as well as S3 compatible object stores such as MinIO, Cloudflare R2, and Google Cloud Storage's XML API.
+//
+// Nexus content headers are stored as user defined object metadata and restored when payloads are read.
+package s3payload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+const (
+	metadataPrefix = "X-Amz-Meta-Nexus-"
+	// Used for streaming downloads where the body is not known upfront.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+// Options are options for [New].
+type Options struct {
+	// Base URL of the S3 endpoint, e.g. "https://s3.us-east-1.amazonaws.com". Required.
+	Endpoint string
+	// Region used for signing requests. Required.
+	Region string
+	// Bucket to store payloads in. Required.
+	Bucket string
+	// Access key ID used for signing requests. Required.
+	AccessKeyID string
+	// Secret access key used for signing requests. Required.
+	SecretAccessKey string
+	// Optional session token for temporary credentials.
+	SessionToken string
+	// Optional prefix prepended to all object keys.
+	KeyPrefix string
+	// Address buckets as a path segment ("https://endpoint/bucket/key") instead of a subdomain
+	// ("https://bucket.endpoint/key"). Most S3 compatible object stores require path style addressing.
+	UsePathStyle bool
+	// A function for making HTTP requests.
+	// Defaults to [http.DefaultClient.Do].
+	HTTPCaller func(*http.Request) (*http.Response, error)
+}
+
+// Backend is an S3 compatible [nexus.PayloadBackend].
+type Backend struct {
+	options  Options
+	endpoint *url.URL
+	// Used in tests.
+	now func() time.Time
+}
+
+// New creates a new [Backend] from the given options.
+func New(options Options) (*Backend, error) {
+	var es []error
+	if options.Endpoint == "" {
+		es = append(es, errors.New("empty endpoint"))
+	}
+	if options.Region == "" {
+		es = append(es, errors.New("empty region"))
+	}
+	if options.Bucket == "" {
+		es = append(es, errors.New("empty bucket"))
+	}
+	if options.AccessKeyID == "" || options.SecretAccessKey == "" {
+		es = append(es, errors.New("empty credentials"))
+	}
+	if len(es) > 0 {
+		return nil, errors.Join(es...)
+	}
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint scheme: %q", endpoint.Scheme)
+	}
+	if options.HTTPCaller == nil {
+		options.HTTPCaller = http.DefaultClient.Do
+	}
+	return &Backend{options: options, endpoint: endpoint, now: time.Now}, nil
+}
+
+// objectURL returns the URL of the object stored under the given key.
+func (b *Backend) objectURL(key string) *url.URL {
+	u := *b.endpoint
+	key = b.options.KeyPrefix + key
+	if b.options.UsePathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + b.options.Bucket + "/" + key
+	} else {
+		u.Host = b.options.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// Put implements nexus.PayloadBackend.
+func (b *Backend) Put(ctx context.Context, key string, content *nexus.Content) error {
+	request, err := http.NewRequestWithContext(ctx, "PUT", b.objectURL(key).String(), bytes.NewReader(content.Data))
+	if err != nil {
+		return err
+	}
+	for k, v := range content.Header {
+		// Length is restored from the object size.
+		if k == "length" {
+			continue
+		}
+		request.Header.Set(metadataPrefix+k, v)
+	}
+	sum := sha256.Sum256(content.Data)
+	b.sign(request, hex.EncodeToString(sum[:]))
+
+	response, err := b.options.HTTPCaller(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return newResponseError(response)
+	}
+	_, err = io.Copy(io.Discard, response.Body)
+	return err
+}
+
+// Get implements nexus.PayloadBackend.
+func (b *Backend) Get(ctx context.Context, key string) (*nexus.Reader, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", b.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	b.sign(request, unsignedPayload)
+
+	response, err := b.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		if response.StatusCode == http.StatusNotFound {
+			return nil, nexus.ErrPayloadNotFound
+		}
+		return nil, newResponseError(response)
+	}
+	header := nexus.Header{}
+	for k, v := range response.Header {
+		if len(v) > 0 && strings.HasPrefix(k, metadataPrefix) {
+			header[strings.ToLower(k[len(metadataPrefix):])] = v[0]
+		}
+	}
+	if response.ContentLength >= 0 {
+		header["length"] = strconv.FormatInt(response.ContentLength, 10)
+	}
+	return &nexus.Reader{ReadCloser: response.Body, Header: header}, nil
+}
+
+func newResponseError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("unexpected response status from object store: %q: %s", response.Status, body)
+}
+
+// sign signs the request in place with AWS Signature Version 4 using the given hex encoded payload hash.
+func (b *Backend) sign(request *http.Request, payloadHash string) {
+	now := b.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.options.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", b.options.SessionToken)
+	}
+
+	// Sign the host and all x-amz-* headers.
+	headers := map[string]string{"host": request.URL.Host}
+	for k, v := range request.Header {
+		lower := strings.ToLower(k)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	scope := now.Format("20060102") + "/" + b.options.Region + "/s3/aws4_request"
+	signature := b.signature(now, scope, canonicalRequest(request.Method, request.URL, canonicalHeaders.String(), signedHeaders, payloadHash))
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.options.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// PresignGet implements nexus.PayloadPresigner. The returned URL is signed with AWS Signature Version 4 query
+// parameters and valid for the given duration, up to the 7 day maximum supported by S3.
+func (b *Backend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > 7*24*time.Hour {
+		return "", fmt.Errorf("invalid expiry: %v", expiry)
+	}
+	now := b.now().UTC()
+	scope := now.Format("20060102") + "/" + b.options.Region + "/s3/aws4_request"
+	u := b.objectURL(key)
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", b.options.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if b.options.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", b.options.SessionToken)
+	}
+	u.RawQuery = canonicalQuery(query)
+	signature := b.signature(now, scope, canonicalRequest("GET", u, "host:"+u.Host+"\n", "host", unsignedPayload))
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+func canonicalRequest(method string, u *url.URL, canonicalHeaders, signedHeaders, payloadHash string) string {
+	return strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery(u.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+func (b *Backend) signature(now time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format(amzDateFormat) + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := hmacSHA256([]byte("AWS4"+b.options.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, b.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes s as specified by AWS Signature Version 4: every byte except unreserved characters is percent
+// encoded, slashes are only encoded if encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+var _ nexus.PayloadPresigner = &Backend{}
@@ -0,0 +1,198 @@
+package nexus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"sync"
+)
+
+// PayloadKind identifies a payload of an operation, see [SchemaRegistry].
+type PayloadKind string
+
+const (
+	// The input an operation is started with.
+	PayloadKindInput PayloadKind = "input"
+	// The result of an operation.
+	PayloadKindResult PayloadKind = "result"
+)
+
+// A SchemaValidator validates serialized payloads against a schema, see [SchemaRegistry].
+type SchemaValidator interface {
+	// Validate returns an error describing why content does not conform to the schema, or nil if it does.
+	Validate(content *Content) error
+}
+
+// SchemaValidatorFunc is a [SchemaValidator] backed by a function, e.g. to plug in a schema library.
+type SchemaValidatorFunc func(content *Content) error
+
+// Validate implements [SchemaValidator].
+func (f SchemaValidatorFunc) Validate(content *Content) error {
+	return f(content)
+}
+
+type schemaKey struct {
+	operation string
+	kind      PayloadKind
+	mediaType string
+}
+
+// SchemaRegistry holds the schemas of operation payloads, keyed by operation name, payload kind and media type, to
+// enforce contracts between the owners of clients and handlers.
+//
+// Set the registry on [ClientOptions.SchemaRegistry] and [HandlerOptions.SchemaRegistry] to validate payloads on both
+// send and receive:
+//   - Clients validate inputs before sending them and results when they are consumed, see [LazyValue.Consume].
+//   - Handlers validate inputs before passing them to the [Handler], rejecting invalid inputs with a 400 (Bad Request)
+//     status code, and results before sending them, failing the request with a 500 (Internal Server Error) status code.
+//
+// Payloads without a registered schema are not validated. Raw [Reader] payloads are streamed and not validated.
+type SchemaRegistry struct {
+	mu         sync.RWMutex
+	validators map[schemaKey]SchemaValidator
+}
+
+// NewSchemaRegistry creates an empty [SchemaRegistry].
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{validators: make(map[schemaKey]SchemaValidator)}
+}
+
+// Register registers a validator for payloads of the given kind of an operation with the given media type, e.g.
+// "application/json". The empty media type matches payloads of any media type without a more specific validator.
+func (r *SchemaRegistry) Register(operation string, kind PayloadKind, mediaType string, validator SchemaValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[schemaKey{operation, kind, mediaType}] = validator
+}
+
+// RegisterJSONSchema compiles a JSON Schema with [NewJSONSchemaValidator] and registers it for JSON payloads of the
+// given kind of an operation.
+func (r *SchemaRegistry) RegisterJSONSchema(operation string, kind PayloadKind, schema []byte) error {
+	validator, err := NewJSONSchemaValidator(schema)
+	if err != nil {
+		return err
+	}
+	r.Register(operation, kind, contentTypeJSON, validator)
+	return nil
+}
+
+// validator returns the validator for payloads of the given kind of an operation with the given media type, if any.
+// Safe to call on a nil registry.
+func (r *SchemaRegistry) validator(operation string, kind PayloadKind, mediaType string) SchemaValidator {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if validator, ok := r.validators[schemaKey{operation, kind, mediaType}]; ok {
+		return validator
+	}
+	return r.validators[schemaKey{operation, kind, ""}]
+}
+
+// Validate validates a payload of the given kind of an operation against the schema registered for its media type.
+// Returns a [SchemaValidationError] if the payload does not conform to the schema. Safe to call on a nil registry.
+func (r *SchemaRegistry) Validate(operation string, kind PayloadKind, content *Content) error {
+	mediaType := contentMediaType(content)
+	validator := r.validator(operation, kind, mediaType)
+	if validator == nil {
+		return nil
+	}
+	if err := validator.Validate(content); err != nil {
+		return &SchemaValidationError{Operation: operation, Kind: kind, MediaType: mediaType, Reason: err.Error()}
+	}
+	return nil
+}
+
+// resultValidator returns a function validating results of an operation against the client's schema registry, or nil
+// if the client has none.
+func (c *Client) resultValidator(operation string) func(*Content) error {
+	registry := c.options.SchemaRegistry
+	if registry == nil {
+		return nil
+	}
+	return func(content *Content) error {
+		return registry.Validate(operation, PayloadKindResult, content)
+	}
+}
+
+// validateInput validates the input of a start request against the handler's schema registry. Inputs with a schema are
+// buffered in memory to be validated before they are passed to the handler.
+func (h *httpHandler) validateInput(operation string, value *LazyValue) error {
+	content := &Content{Header: value.Reader.Header}
+	if h.options.SchemaRegistry.validator(operation, PayloadKindInput, contentMediaType(content)) == nil {
+		return nil
+	}
+	data, err := readAll(value.Reader)
+	value.Reader.Close()
+	if err != nil {
+		return requestBodyError(err, "failed to read input")
+	}
+	content.Data = data
+	if err := h.options.SchemaRegistry.Validate(operation, PayloadKindInput, content); err != nil {
+		return err
+	}
+	value.Reader.ReadCloser = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// contentMediaType returns the media type of content without parameters.
+func contentMediaType(content *Content) string {
+	contentType := content.Header.Get("type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
+
+// SchemaValidationError is returned when a payload does not conform to the schema registered for it in a
+// [SchemaRegistry].
+//
+// Handlers respond to requests whose input fails validation with a 400 (Bad Request) status code. The resulting
+// [UnexpectedResponseError] converts to a SchemaValidationError with errors.As.
+type SchemaValidationError struct {
+	// Name of the operation the payload belongs to.
+	Operation string
+	// Kind of the payload.
+	Kind PayloadKind
+	// Media type of the payload.
+	MediaType string
+	// Why the payload does not conform to the schema.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("invalid %s of operation %q: %s", e.Kind, e.Operation, e.Reason)
+}
+
+// Type of the failure handlers respond with to requests whose input failed schema validation.
+const schemaValidationFailureType = "nexus.SchemaValidationError"
+
+func (e *SchemaValidationError) failure() *Failure {
+	return &Failure{
+		Message: e.Error(),
+		Type:    schemaValidationFailureType,
+		Metadata: map[string]string{
+			"operation": e.Operation,
+			"kind":      string(e.Kind),
+			"mediaType": e.MediaType,
+			"reason":    e.Reason,
+		},
+	}
+}
+
+// schemaValidationErrorFromFailure converts a failure responded with to a request whose input failed schema
+// validation back into a SchemaValidationError. Returns nil for other failures.
+func schemaValidationErrorFromFailure(failure *Failure) *SchemaValidationError {
+	if failure == nil || failure.Type != schemaValidationFailureType {
+		return nil
+	}
+	return &SchemaValidationError{
+		Operation: failure.Metadata["operation"],
+		Kind:      PayloadKind(failure.Metadata["kind"]),
+		MediaType: failure.Metadata["mediaType"],
+		Reason:    failure.Metadata["reason"],
+	}
+}
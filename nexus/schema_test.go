@@ -0,0 +1,115 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type schemaHandler struct {
+	UnimplementedHandler
+}
+
+func (h *schemaHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var order map[string]any
+	if err := input.Consume(&order); err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{Value: order["result"]}, nil
+}
+
+func newOrderSchemaRegistry(t *testing.T) *SchemaRegistry {
+	registry := NewSchemaRegistry()
+	require.NoError(t, registry.RegisterJSONSchema("order", PayloadKindInput, []byte(`{"type": "object", "required": ["id"]}`)))
+	require.NoError(t, registry.RegisterJSONSchema("order", PayloadKindResult, []byte(`{"type": "object", "required": ["status"]}`)))
+	return registry
+}
+
+func TestSchemaRegistry_Handler(t *testing.T) {
+	registry := newOrderSchemaRegistry(t)
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &schemaHandler{}, SchemaRegistry: registry}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "order", map[string]any{"id": "1", "result": map[string]any{"status": "ok"}}, StartOperationOptions{})
+	require.NoError(t, err)
+	var output map[string]any
+	require.NoError(t, result.Successful.Consume(&output))
+	require.Equal(t, map[string]any{"status": "ok"}, output)
+
+	// Invalid inputs are rejected before they reach the handler.
+	_, err = client.StartOperation(ctx, "order", map[string]any{"result": map[string]any{"status": "ok"}}, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	var validationError *SchemaValidationError
+	require.ErrorAs(t, err, &validationError)
+	require.Equal(t, &SchemaValidationError{
+		Operation: "order",
+		Kind:      PayloadKindInput,
+		MediaType: contentTypeJSON,
+		Reason:    `/: missing required property "id"`,
+	}, validationError)
+
+	// Invalid results are not sent.
+	_, err = client.StartOperation(ctx, "order", map[string]any{"id": "1", "result": map[string]any{}}, StartOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedResponseError.Response.StatusCode)
+	require.False(t, errors.As(err, &validationError))
+
+	// Operations without a schema are not validated.
+	_, err = client.StartOperation(ctx, "other", map[string]any{"result": map[string]any{}}, StartOperationOptions{})
+	require.NoError(t, err)
+}
+
+func TestSchemaRegistry_Client(t *testing.T) {
+	var requests int
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: &schemaHandler{}}, ClientOptions{
+		SchemaRegistry: newOrderSchemaRegistry(t),
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			requests++
+			return http.DefaultClient.Do(request)
+		},
+	})
+	defer teardown()
+
+	// Invalid inputs are not sent.
+	_, err := client.StartOperation(ctx, "order", map[string]any{}, StartOperationOptions{})
+	var validationError *SchemaValidationError
+	require.ErrorAs(t, err, &validationError)
+	require.Equal(t, PayloadKindInput, validationError.Kind)
+	require.EqualError(t, err, `invalid input of operation "order": /: missing required property "id"`)
+	require.Equal(t, 0, requests)
+
+	// Invalid results fail when they are consumed.
+	result, err := client.StartOperation(ctx, "order", map[string]any{"id": "1", "result": map[string]any{}}, StartOperationOptions{})
+	require.NoError(t, err)
+	var output map[string]any
+	err = result.Successful.ConsumeStream(&output)
+	require.ErrorAs(t, err, &validationError)
+	require.Equal(t, PayloadKindResult, validationError.Kind)
+	require.Nil(t, output)
+}
+
+func TestSchemaRegistry_MediaTypes(t *testing.T) {
+	registry := NewSchemaRegistry()
+	registry.Register("upload", PayloadKindInput, "", SchemaValidatorFunc(func(content *Content) error {
+		if len(content.Data) == 0 {
+			return errors.New("empty upload")
+		}
+		return nil
+	}))
+	registry.Register("upload", PayloadKindInput, "text/plain", SchemaValidatorFunc(func(content *Content) error {
+		return nil
+	}))
+
+	require.NoError(t, registry.Validate("upload", PayloadKindInput, &Content{Header: Header{"type": "text/plain; charset=utf-8"}}))
+	err := registry.Validate("upload", PayloadKindInput, &Content{Header: Header{"type": "application/octet-stream"}})
+	require.EqualError(t, err, `invalid input of operation "upload": empty upload`)
+	require.NoError(t, registry.Validate("upload", PayloadKindResult, &Content{}))
+
+	var nilRegistry *SchemaRegistry
+	require.NoError(t, nilRegistry.Validate("upload", PayloadKindInput, &Content{}))
+}
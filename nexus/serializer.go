@@ -1,11 +1,16 @@
 package nexus
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // A Reader is a container for a [Header] and an [io.Reader].
@@ -19,6 +24,37 @@ type Reader struct {
 	Header Header
 }
 
+// NewReader creates a [Reader] streaming r with the given content header, closing r when the Reader is closed if r is
+// an [io.Closer]. The length header is set for in-memory readers, i.e. [bytes.Buffer], [bytes.Reader] and
+// [strings.Reader], unless already present, and the type header defaults to application/octet-stream.
+//
+// Readers of unknown length are sent with chunked transfer encoding, set the length header to send a Content-Length
+// instead.
+func NewReader(r io.Reader, header Header) *Reader {
+	header = maps.Clone(header)
+	if header == nil {
+		header = Header{}
+	}
+	if _, ok := header["type"]; !ok {
+		header["type"] = "application/octet-stream"
+	}
+	if _, ok := header["length"]; !ok {
+		switch v := r.(type) {
+		case *bytes.Buffer:
+			header["length"] = strconv.Itoa(v.Len())
+		case *bytes.Reader:
+			header["length"] = strconv.Itoa(v.Len())
+		case *strings.Reader:
+			header["length"] = strconv.Itoa(v.Len())
+		}
+	}
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		rc = io.NopCloser(r)
+	}
+	return &Reader{rc, header}
+}
+
 // A Content is a container for a [Header] and a byte slice.
 // It is used by the SDK's [Serializer] interface implementations.
 type Content struct {
@@ -39,7 +75,11 @@ type Content struct {
 // ⚠️ When a LazyValue is passed to a server handler, it must not be used after the returning from the handler method.
 type LazyValue struct {
 	serializer Serializer
-	Reader     *Reader
+	// Applied to the content before it is deserialized, see [ClientOptions.ResultTransformers].
+	transformers []ResultTransformer
+	// Validates the transformed content before it is deserialized, see [ClientOptions.SchemaRegistry].
+	validate func(*Content) error
+	Reader   *Reader
 }
 
 // Consume consumes the lazy value, decodes it from the underlying [Reader], and stores the result in the value pointed
@@ -47,16 +87,126 @@ type LazyValue struct {
 //
 //	var v int
 //	err := lazyValue.Consume(&v)
+//
+// The underlying content is read into memory before it is decoded. Use [LazyValue.ConsumeStream] or [ConsumeElements]
+// to decode very large values without buffering them.
 func (l *LazyValue) Consume(v any) error {
 	defer l.Reader.Close()
-	data, err := io.ReadAll(l.Reader)
+	data, err := readAll(l.Reader)
 	if err != nil {
 		return err
 	}
-	return l.serializer.Deserialize(&Content{
-		Header: l.Reader.Header,
-		Data:   data,
-	}, v)
+	content := &Content{Header: l.Reader.Header, Data: data}
+	if len(l.transformers) > 0 {
+		if content, err = transformResult(l.transformers, content); err != nil {
+			return err
+		}
+	}
+	if l.validate != nil {
+		if err := l.validate(content); err != nil {
+			return err
+		}
+	}
+	return l.serializer.Deserialize(content, v)
+}
+
+// WriteTo implements [io.WriterTo], streaming the serialized content of the lazy value into w without decoding it.
+// The underlying [Reader] is consumed and closed.
+func (l *LazyValue) WriteTo(w io.Writer) (int64, error) {
+	if l.Reader.ReadCloser == nil {
+		return 0, nil
+	}
+	defer l.Reader.Close()
+	return io.Copy(w, l.Reader.ReadCloser)
+}
+
+// ConsumeStream is like [LazyValue.Consume] but decodes the value while reading it from the underlying [Reader] if
+// the serializer implements [StreamingDeserializer], avoiding buffering the entire content in memory. The default
+// serializer streams JSON content. Falls back to Consume for other serializers and for results transformed by
+// [ClientOptions.ResultTransformers] or validated against a [ClientOptions.SchemaRegistry].
+func (l *LazyValue) ConsumeStream(v any) error {
+	streaming, ok := l.serializer.(StreamingDeserializer)
+	if !ok || len(l.transformers) > 0 || l.validate != nil {
+		return l.Consume(v)
+	}
+	defer l.Reader.Close()
+	return streaming.DeserializeStream(l.Reader, v)
+}
+
+// ConsumeElements consumes a lazy value holding a JSON array, decoding its elements one at a time as they are read
+// from the underlying [Reader] and sending them to elements. Only a single element is held in memory at a time,
+// making it suitable for very large arrays.
+//
+// The elements channel is closed when ConsumeElements returns. Returns the context's error if it is done before all
+// elements were sent.
+//
+//	elements := make(chan Item)
+//	go func() {
+//		errCh <- nexus.ConsumeElements(ctx, lazyValue, elements)
+//	}()
+//	for item := range elements {
+//		// ...
+//	}
+func ConsumeElements[T any](ctx context.Context, l *LazyValue, elements chan<- T) error {
+	defer close(elements)
+	defer l.Reader.Close()
+	if !isMediaTypeJSON(l.Reader.Header["type"]) {
+		return fmt.Errorf("cannot decode elements of non JSON content: %q", l.Reader.Header["type"])
+	}
+	decoder := json.NewDecoder(l.Reader)
+	if err := expectJSONDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		var element T
+		if err := decoder.Decode(&element); err != nil {
+			return err
+		}
+		select {
+		case elements <- element:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := expectJSONDelim(decoder, ']'); err != nil {
+		return err
+	}
+	return expectJSONEnd(decoder)
+}
+
+func expectJSONDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected JSON %q, got: %v", delim, token)
+	}
+	return nil
+}
+
+// expectJSONEnd ensures there is no data after the decoded JSON value, as [json.Unmarshal] does.
+func expectJSONEnd(decoder *json.Decoder) error {
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid data after top-level JSON value")
+	}
+	return nil
+}
+
+// A StreamingDeserializer is a [Serializer] that can decode values while reading them, used by
+// [LazyValue.ConsumeStream].
+type StreamingDeserializer interface {
+	// DeserializeStream decodes the content of a [Reader] into a given reference. The reader must not be closed.
+	DeserializeStream(*Reader, any) error
+}
+
+// A StreamingSerializer is a [Serializer] that can encode values directly into a writer, used by handlers to
+// serialize results into pooled buffers instead of allocating a new byte slice for every result. The default
+// serializer implements it.
+type StreamingSerializer interface {
+	// SerializeStream encodes a value into w and returns the header of the encoded content. Content written to w
+	// before an error is returned is discarded.
+	SerializeStream(w io.Writer, v any) (Header, error)
 }
 
 // Serializer is used by the framework to serialize/deserialize input and output.
@@ -107,23 +257,25 @@ func (c serializerChain) Deserialize(content *Content, v any) error {
 
 var _ Serializer = serializerChain{}
 
-type jsonSerializer struct{}
+type jsonSerializer struct {
+	json JSONEngine
+}
 
-func (jsonSerializer) Deserialize(c *Content, v any) error {
+func (s jsonSerializer) Deserialize(c *Content, v any) error {
 	if !isMediaTypeJSON(c.Header["type"]) {
 		return errSerializerIncompatible
 	}
-	return json.Unmarshal(c.Data, &v)
+	return s.json.unmarshal(c.Data, &v)
 }
 
-func (jsonSerializer) Serialize(v any) (*Content, error) {
-	data, err := json.Marshal(v)
+func (s jsonSerializer) Serialize(v any) (*Content, error) {
+	data, err := s.json.marshal(v)
 	if err != nil {
 		return nil, err
 	}
 	return &Content{
 		Header: Header{
-			"type":   "application/json",
+			"type": "application/json",
 		},
 		Data: data,
 	}, nil
@@ -201,7 +353,7 @@ func (byteSliceSerializer) Serialize(v any) (*Content, error) {
 	if b, ok := v.([]byte); ok {
 		return &Content{
 			Header: Header{
-				"type":   "application/octet-stream",
+				"type": "application/octet-stream",
 			},
 			Data: b,
 		}, nil
@@ -213,8 +365,61 @@ var _ Serializer = byteSliceSerializer{}
 
 type compositeSerializer struct {
 	serializerChain
+	json JSONEngine
+}
+
+func newCompositeSerializer(engine JSONEngine) compositeSerializer {
+	return compositeSerializer{
+		serializerChain: serializerChain([]Serializer{nilSerializer{}, byteSliceSerializer{}, jsonSerializer{engine}}),
+		json:            engine,
+	}
+}
+
+// DeserializeStream implements StreamingDeserializer, decoding JSON content while reading it and buffering other
+// content. JSON content is buffered too with a custom JSON engine.
+func (c compositeSerializer) DeserializeStream(reader *Reader, v any) error {
+	if isMediaTypeJSON(reader.Header["type"]) && c.json.Unmarshal == nil {
+		decoder := json.NewDecoder(reader)
+		if err := decoder.Decode(&v); err != nil {
+			return err
+		}
+		return expectJSONEnd(decoder)
+	}
+	data, err := readAll(reader)
+	if err != nil {
+		return err
+	}
+	return c.Deserialize(&Content{Header: reader.Header, Data: data}, v)
 }
 
-var defaultSerializer Serializer = compositeSerializer{
-	serializerChain([]Serializer{nilSerializer{}, byteSliceSerializer{}, jsonSerializer{}}),
+// SerializeStream implements StreamingSerializer, encoding values like Serialize.
+func (c compositeSerializer) SerializeStream(w io.Writer, v any) (Header, error) {
+	if content, err := (nilSerializer{}).Serialize(v); err == nil {
+		return content.Header, nil
+	}
+	if b, ok := v.([]byte); ok {
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		return Header{"type": "application/octet-stream"}, nil
+	}
+	buf, ok := w.(*bytes.Buffer)
+	if !ok {
+		buf = getBuffer()
+		defer putBuffer(buf)
+	}
+	if err := c.json.encode(buf, v); err != nil {
+		return nil, err
+	}
+	if !ok {
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return Header{"type": "application/json"}, nil
 }
+
+var _ StreamingDeserializer = compositeSerializer{}
+var _ StreamingSerializer = compositeSerializer{}
+
+var defaultSerializer Serializer = newCompositeSerializer(JSONEngine{})
@@ -1,8 +1,14 @@
 package nexus
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -157,3 +163,109 @@ func TestCustomSerializer(t *testing.T) {
 	require.Equal(t, 4, c.decoded)
 	require.Equal(t, 4, c.encoded)
 }
+
+func newTestLazyValue(contentType, data string) *LazyValue {
+	return &LazyValue{
+		serializer: defaultSerializer,
+		Reader:     &Reader{io.NopCloser(strings.NewReader(data)), Header{"type": contentType}},
+	}
+}
+
+func TestLazyValue_ConsumeStream(t *testing.T) {
+	var v struct{ A []int }
+	require.NoError(t, newTestLazyValue("application/json", `{"A": [1, 2, 3]}`).ConsumeStream(&v))
+	require.Equal(t, []int{1, 2, 3}, v.A)
+
+	var b []byte
+	require.NoError(t, newTestLazyValue("application/octet-stream", "raw").ConsumeStream(&b))
+	require.Equal(t, []byte("raw"), b)
+
+	require.ErrorContains(t, newTestLazyValue("application/json", `{} {}`).ConsumeStream(&v), "invalid data after top-level JSON value")
+}
+
+func TestLazyValue_ConsumeStream_CustomSerializer(t *testing.T) {
+	lv := newTestLazyValue("application/json", `"foo"`)
+	lv.serializer = jsonSerializer{}
+	var s string
+	require.NoError(t, lv.ConsumeStream(&s))
+	require.Equal(t, "foo", s)
+}
+
+func TestConsumeElements(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"n": %d}`, i)
+	}
+	sb.WriteString("]")
+
+	type element struct{ N int }
+	elements := make(chan element)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ConsumeElements(context.Background(), newTestLazyValue("application/json", sb.String()), elements)
+	}()
+	n := 0
+	for e := range elements {
+		require.Equal(t, n, e.N)
+		n++
+	}
+	require.NoError(t, <-errCh)
+	require.Equal(t, 1000, n)
+}
+
+func TestConsumeElements_Errors(t *testing.T) {
+	ctx := context.Background()
+	err := ConsumeElements(ctx, newTestLazyValue("application/json", `{"a": 1}`), make(chan int))
+	require.ErrorContains(t, err, "expected JSON")
+
+	err = ConsumeElements(ctx, newTestLazyValue("application/octet-stream", `[1]`), make(chan int))
+	require.ErrorContains(t, err, "non JSON content")
+
+	err = ConsumeElements(ctx, newTestLazyValue("application/json", `[1, "two"]`), make(chan int, 2))
+	require.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	elements := make(chan int)
+	err = ConsumeElements(ctx, newTestLazyValue("application/json", `[1, 2]`), elements)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, open := <-elements
+	require.False(t, open)
+}
+
+func TestLazyValue_WriteTo(t *testing.T) {
+	var sb strings.Builder
+	n, err := newTestLazyValue("application/json", `{"a": 1}`).WriteTo(&sb)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), n)
+	require.Equal(t, `{"a": 1}`, sb.String())
+
+	n, err = (&LazyValue{Reader: &Reader{}}).WriteTo(&sb)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewReader(t *testing.T) {
+	reader := NewReader(bytes.NewBufferString("abc"), nil)
+	require.Equal(t, Header{"type": "application/octet-stream", "length": "3"}, reader.Header)
+
+	recorder := &closeRecorder{Reader: strings.NewReader("abc")}
+	reader = NewReader(recorder, Header{"type": "text/plain"})
+	require.Equal(t, Header{"type": "text/plain"}, reader.Header)
+	require.NoError(t, reader.Close())
+	require.True(t, recorder.closed)
+}
@@ -3,25 +3,20 @@ package nexus
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
 	"net/http"
-	"net/url"
-	"path"
 	"strconv"
 	"time"
-
-	"github.com/gorilla/mux"
 )
 
 // An HandlerStartOperationResult is the return type from the [Handler] StartOperation and [Operation] Start methods. It
 // has two implementations: [HandlerStartOperationResultSync] and [HandlerStartOperationResultAsync].
 type HandlerStartOperationResult[T any] interface {
-	applyToHTTPResponse(http.ResponseWriter, *httpHandler)
+	applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler, operation string)
 }
 
 // HandlerStartOperationResultSync indicates that an operation completed successfully.
@@ -29,31 +24,43 @@ type HandlerStartOperationResultSync[T any] struct {
 	Value T
 }
 
-func (r *HandlerStartOperationResultSync[T]) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
-	handler.writeResult(writer, r.Value)
+func (r *HandlerStartOperationResultSync[T]) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler, operation string) {
+	handler.writeResult(writer, operation, r.Value)
 }
 
 // HandlerStartOperationResultAsync indicates that an operation has been accepted and will complete asynchronously.
 type HandlerStartOperationResultAsync struct {
 	OperationID string
+	// Set when the start request was deduplicated against an operation that was already started, reported to clients
+	// in [ClientStartOperationResult.AlreadyStarted]. Return an [OperationAlreadyStartedError] instead to reject such
+	// requests with 409 Conflict.
+	AlreadyStarted bool
+	// Optional links to resources related to the operation, surfaced in [OperationHandle.Links].
+	Links []Link
 }
 
-func (r *HandlerStartOperationResultAsync) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
+func (r *HandlerStartOperationResultAsync) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler, operation string) {
 	info := OperationInfo{
 		ID:    r.OperationID,
 		State: OperationStateRunning,
+		Links: r.Links,
 	}
-	bytes, err := json.Marshal(info)
-	if err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := handler.json.encode(buf, info); err != nil {
 		handler.logger.Error("failed to serialize operation info", "error", err)
 		writer.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	writer.Header().Set("Content-Type", contentTypeJSON)
+	writer.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if r.AlreadyStarted {
+		writer.Header().Set(HeaderOperationAlreadyStarted, "true")
+	}
 	writer.WriteHeader(http.StatusCreated)
 
-	if _, err := writer.Write(bytes); err != nil {
+	if _, err := writer.Write(buf.Bytes()); err != nil {
 		handler.logger.Error("failed to write response body", "error", err)
 	}
 }
@@ -71,12 +78,13 @@ type Handler interface {
 	// StartOperation handles requests for starting an operation. Return [HandlerStartOperationResultSync] to
 	// respond successfully - inline, or [HandlerStartOperationResultAsync] to indicate that an asynchronous
 	// operation was started. Return an [UnsuccessfulOperationError] to indicate that an operation completed as
-	// failed or canceled.
+	// failed or canceled, and an [OperationAlreadyStartedError] to reject the start of an operation that already
+	// exists.
 	StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error)
 	// GetOperationResult handles requests to get the result of an asynchronous operation. Return non error result
 	// to respond successfully - inline, or error with [ErrOperationStillRunning] to indicate that an asynchronous
 	// operation is still running. Return an [UnsuccessfulOperationError] to indicate that an operation completed as
-	// failed or canceled.
+	// failed or canceled. Return [ErrOperationResultGone] if the operation's result is no longer retained.
 	//
 	// When [GetOperationResultOptions.Wait] is greater than zero, this request should be treated as a long poll.
 	// Long poll requests have a server side timeout, configurable via [HandlerOptions.GetResultTimeout], and exposed
@@ -85,8 +93,15 @@ type Handler interface {
 	// It is the implementor's responsiblity to respect the client's wait duration and return in a timely fashion.
 	// Consider using a derived context that enforces the wait timeout when implementing this method and return
 	// [ErrOperationStillRunning] when that context expires as shown in the example.
+	//
+	// When [GetOperationResultOptions.AcceptRedirect] is set, a [ResultRedirect] may be returned to have the client
+	// download the result from another URL.
+	//
+	// When [GetOperationResultOptions.AcceptPages] is set, a [ResultPage] may be returned to split a large result into
+	// multiple responses.
 	GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error)
-	// GetOperationInfo handles requests to get information about an asynchronous operation.
+	// GetOperationInfo handles requests to get information about an asynchronous operation. Return
+	// [ErrOperationNotFound] if the operation does not exist.
 	GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error)
 	// CancelOperation handles requests to cancel an asynchronous operation.
 	// Cancelation in Nexus is:
@@ -94,6 +109,43 @@ type Handler interface {
 	//  ignored by the underlying operation implemention.
 	//  2. idempotent - implementors should ignore duplicate cancelations for the same operation.
 	CancelOperation(ctx context.Context, operation, operationID string, options CancelOperationOptions) error
+	// WatchOperation handles requests to stream state changes of an asynchronous operation as Server-Sent Events.
+	// The returned channel should emit the operation's current info followed by an info for every state transition.
+	// Implementations must close the channel once the operation reaches a terminal state or when the context is done.
+	WatchOperation(ctx context.Context, operation, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error)
+	// ClaimOperationResult handles requests to claim the outcome of a completed operation for exclusive processing.
+	// A claim is held for a lease and must be acknowledged via AckOperationResult before it expires, otherwise the
+	// outcome becomes available to be claimed again.
+	//
+	// Return [ErrOperationStillRunning] if the operation is still running, [ErrOperationResultClaimed] if the outcome
+	// is claimed by another consumer, and [ErrOperationResultAcked] if the outcome was already acknowledged.
+	ClaimOperationResult(ctx context.Context, operation, operationID string, options ClaimOperationResultOptions) (*HandlerResultClaim, error)
+	// AckOperationResult handles requests to acknowledge the processing of a claimed operation outcome.
+	// Acknowledging the same claim more than once should succeed.
+	//
+	// Return [ErrOperationResultClaimLost] if the given claim token does not identify the current claim, and
+	// [ErrOperationResultAcked] if the outcome was acknowledged under a different claim.
+	AckOperationResult(ctx context.Context, operation, operationID, token string, options AckOperationResultOptions) error
+	// ListOperations handles requests to list operations matching a filter, e.g. for building operation dashboards.
+	// Operations should be ordered by creation time, newest first.
+	ListOperations(ctx context.Context, options ListOperationsOptions) (*OperationList, error)
+	// CancelMatchingOperations handles administrative requests to cancel or terminate all running operations matching a
+	// filter, streaming progress reports as Server-Sent Events.
+	// Implementations must close the returned channel after delivering a report with Completed set or when the context
+	// is done.
+	CancelMatchingOperations(ctx context.Context, options CancelMatchingOperationsOptions) (<-chan *BatchProgress, error)
+	// GetQuotaUsage handles administrative requests to get the quota and usage of a subject, e.g. a tenant.
+	GetQuotaUsage(ctx context.Context, subject string, options GetQuotaUsageOptions) (*QuotaStatus, error)
+	// HeartbeatOperation handles requests from executors of an asynchronous operation proving that the operation is
+	// still being executed, optionally reporting progress details. Return the operation's current info, allowing
+	// executors to learn about cancelation.
+	HeartbeatOperation(ctx context.Context, operation, operationID string, options HeartbeatOperationOptions) (*OperationInfo, error)
+	// ResolveQuarantine handles administrative requests to release a quarantined operation for another execution
+	// attempt or to fail it.
+	ResolveQuarantine(ctx context.Context, operation, operationID string, options ResolveQuarantineOptions) error
+	// ForceCompleteOperation handles administrative requests to force an operation into a terminal state with a result
+	// or failure supplied by the operator, e.g. to clean up operations whose execution is wedged.
+	ForceCompleteOperation(ctx context.Context, operation, operationID string, options ForceCompleteOperationOptions) error
 	mustEmbedUnimplementedHandler()
 }
 
@@ -144,6 +196,19 @@ func (e *HandlerError) Error() string {
 	return fmt.Sprintf("handler error (%s)", typ)
 }
 
+// Unwrap returns the cause of the handler error's failure as a [FailureError], if any.
+func (e *HandlerError) Unwrap() error {
+	if e.Failure == nil {
+		return nil
+	}
+	return e.Failure.causeError()
+}
+
+// Is reports whether the handler error is of type [HandlerErrorTypeNotFound] when target is [ErrOperationNotFound].
+func (e *HandlerError) Is(target error) bool {
+	return target == ErrOperationNotFound && e.Type == HandlerErrorTypeNotFound
+}
+
 // HandlerErrorf creates a [HandlerError] with the given type and a formatted failure message.
 func HandlerErrorf(typ HandlerErrorType, format string, args ...any) *HandlerError {
 	return &HandlerError{
@@ -155,33 +220,86 @@ func HandlerErrorf(typ HandlerErrorType, format string, args ...any) *HandlerErr
 }
 
 type baseHTTPHandler struct {
-	logger *slog.Logger
+	logger                 *slog.Logger
+	loggerProvider         LoggerProvider
+	requestLogVerbosity    RequestLogVerbosity
+	failureConverter       FailureConverter
+	onPanic                func(ctx context.Context, value any, stack []byte)
+	exposePanicDetails     bool
+	responseHeaderProvider ResponseHeaderProvider
+	json                   JSONEngine
+	auditSink              AuditSink
 }
 
 type httpHandler struct {
 	baseHTTPHandler
 	options HandlerOptions
+	limiter *concurrencyLimiter
+	drainer *drainer
+	router  http.Handler
+	// Accept header of the request handled by a per-request copy of the handler, see route.
+	accept string
 }
 
-func (h *httpHandler) writeResult(writer http.ResponseWriter, result any) {
+func (h *httpHandler) writeResult(writer http.ResponseWriter, operation string, result any) {
+	result, err := multipartValue(result, h.options.Serializer)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to serialize handler result: %w", err))
+		return
+	}
 	var reader *Reader
 	if r, ok := result.(*Reader); ok {
 		// Close the request body in case we error before sending the HTTP request (which may double close but
 		// that's fine since we ignore the error).
 		defer r.Close()
 		reader = r
+	} else if streaming, ok := h.options.Serializer.(StreamingSerializer); ok && isStreamingSerializable(result) {
+		// Serialize into a pooled buffer, which is written as is, avoiding allocating and copying the serialized
+		// result.
+		buf := getBuffer()
+		defer putBuffer(buf)
+		header, err := streaming.SerializeStream(buf, result)
+		if err != nil {
+			h.writeFailure(writer, fmt.Errorf("failed to serialize handler result: %w", err))
+			return
+		}
+		header = maps.Clone(header)
+		if header == nil {
+			header = Header{}
+		}
+		header["length"] = strconv.Itoa(buf.Len())
+		if err := h.options.SchemaRegistry.Validate(operation, PayloadKindResult, &Content{Header: header, Data: buf.Bytes()}); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+		if err := setChecksum(header, h.options.Checksum, buf.Bytes()); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+		reader = &Reader{io.NopCloser(buf), header}
 	} else {
 		content, ok := result.(*Content)
 		if !ok {
 			var err error
-			content, err = h.options.Serializer.Serialize(result)
+			content, err = serializeAcceptable(h.options.Serializer, result, h.accept)
 			if err != nil {
 				h.writeFailure(writer, fmt.Errorf("failed to serialize handler result: %w", err))
 				return
 			}
 		}
 		header := maps.Clone(content.Header)
+		if header == nil {
+			header = Header{}
+		}
 		header["length"] = strconv.Itoa(len(content.Data))
+		if err := h.options.SchemaRegistry.Validate(operation, PayloadKindResult, &Content{Header: header, Data: content.Data}); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+		if err := setChecksum(header, h.options.Checksum, content.Data); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
 
 		reader = &Reader{
 			io.NopCloser(bytes.NewReader(content.Data)),
@@ -189,6 +307,11 @@ func (h *httpHandler) writeResult(writer http.ResponseWriter, result any) {
 		}
 	}
 
+	if !acceptable(h.accept, reader.Header["type"]) {
+		h.writeFailure(writer, fmt.Errorf("%w: result content type %q does not match accepted media types %q", errNotAcceptable, reader.Header["type"], h.accept))
+		return
+	}
+
 	header := writer.Header()
 	addContentHeaderToHTTPHeader(reader.Header, header)
 	if reader.ReadCloser == nil {
@@ -199,20 +322,57 @@ func (h *httpHandler) writeResult(writer http.ResponseWriter, result any) {
 	}
 }
 
+// isStreamingSerializable reports whether a result should be serialized with a [StreamingSerializer]. Byte slices and
+// [Content] are written as is, without copying them into a buffer.
+func isStreamingSerializable(result any) bool {
+	switch result.(type) {
+	case []byte, *Content:
+		return false
+	}
+	return true
+}
+
 func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
+	if recorder, ok := writer.(*statusRecorder); ok {
+		recorder.err = err
+	}
 	var failure *Failure
 	var unsuccessfulError *UnsuccessfulOperationError
 	var handlerError *HandlerError
+	var quotaExceededError *QuotaExceededError
+	var maxBytesError *http.MaxBytesError
+	var integrityError *IntegrityError
+	var validationError *SchemaValidationError
+	var alreadyStartedError *OperationAlreadyStartedError
 	var operationState OperationState
 	statusCode := http.StatusInternalServerError
 
-	if errors.As(err, &unsuccessfulError) {
+	if errors.As(err, &quotaExceededError) {
+		failure = &Failure{Message: quotaExceededError.Error()}
+		statusCode = http.StatusTooManyRequests
+		addQuotaExceededErrorToHTTPHeader(quotaExceededError, writer.Header())
+	} else if errors.As(err, &maxBytesError) {
+		failure = &Failure{Message: fmt.Sprintf("request body too large, limit is %d bytes", maxBytesError.Limit)}
+		statusCode = http.StatusRequestEntityTooLarge
+	} else if errors.As(err, &integrityError) {
+		failure = integrityError.failure()
+		statusCode = http.StatusBadRequest
+	} else if errors.As(err, &validationError) && validationError.Kind == PayloadKindInput {
+		failure = validationError.failure()
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, errNotAcceptable) {
+		failure = &Failure{Message: err.Error()}
+		statusCode = http.StatusNotAcceptable
+	} else if errors.Is(err, errMethodNotAllowed) {
+		failure = &Failure{Message: err.Error()}
+		statusCode = http.StatusMethodNotAllowed
+	} else if errors.As(err, &unsuccessfulError) {
 		operationState = unsuccessfulError.State
 		failure = &unsuccessfulError.Failure
-		statusCode = statusOperationFailed
+		statusCode = StatusOperationFailed
 
 		if operationState == OperationStateFailed || operationState == OperationStateCanceled {
-			writer.Header().Set(headerOperationState, string(operationState))
+			writer.Header().Set(HeaderOperationState, string(operationState))
 		} else {
 			h.logger.Error("unexpected operation state", "state", operationState)
 			writer.WriteHeader(http.StatusInternalServerError)
@@ -220,30 +380,22 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 		}
 	} else if errors.As(err, &handlerError) {
 		failure = handlerError.Failure
-		switch handlerError.Type {
-		case HandlerErrorTypeBadRequest:
-			statusCode = http.StatusBadRequest
-		case HandlerErrorTypeUnauthenticated:
-			statusCode = http.StatusUnauthorized
-		case HandlerErrorTypeUnauthorized:
-			statusCode = http.StatusForbidden
-		case HandlerErrorTypeNotFound:
-			statusCode = http.StatusNotFound
-		case HandlerErrorTypeResourceExhausted:
-			statusCode = http.StatusTooManyRequests
-		case HandlerErrorTypeInternal:
-			statusCode = http.StatusInternalServerError
-		case HandlerErrorTypeNotImplemented:
-			statusCode = http.StatusNotImplemented
-		case HandlerErrorTypeUnavailable:
-			statusCode = http.StatusServiceUnavailable
-		case HandlerErrorTypeDownstreamError:
-			statusCode = StatusDownstreamError
-		case HandlerErrorTypeDownstreamTimeout:
-			statusCode = StatusDownstreamTimeout
-		default:
+		if _, ok := handlerErrorTypeStatusCodes[handlerError.Type]; !ok {
 			h.logger.Error("unexpected handler error type", "type", handlerError.Type)
 		}
+		statusCode = HTTPStatusFromHandlerErrorType(handlerError.Type)
+	} else if errors.Is(err, ErrOperationNotFound) {
+		failure = &Failure{Message: err.Error()}
+		statusCode = http.StatusNotFound
+	} else if errors.Is(err, ErrOperationAlreadyStarted) {
+		failure = &Failure{Message: ErrOperationAlreadyStarted.Error()}
+		statusCode = http.StatusConflict
+		if errors.As(err, &alreadyStartedError) && alreadyStartedError.OperationID != "" {
+			writer.Header().Set(HeaderOperationID, alreadyStartedError.OperationID)
+		}
+	} else if errors.Is(err, ErrOperationResultGone) {
+		failure = &Failure{Message: err.Error()}
+		statusCode = http.StatusGone
 	} else {
 		failure = &Failure{
 			Message: "internal server error",
@@ -251,9 +403,10 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 		h.logger.Error("handler failed", "error", err)
 	}
 
+	failure = h.encodeFailure(failure)
 	var bytes []byte
 	if failure != nil {
-		bytes, err = json.Marshal(failure)
+		bytes, err = h.json.marshal(failure)
 		if err != nil {
 			h.logger.Error("failed to marshal failure", "error", err)
 			writer.WriteHeader(http.StatusInternalServerError)
@@ -270,23 +423,23 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 }
 
 func (h *httpHandler) startOperation(writer http.ResponseWriter, request *http.Request) {
-	operation, err := url.PathUnescape(path.Base(request.URL.EscapedPath()))
+	operation, err := pathVar(request, "operation")
 	if err != nil {
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 		return
 	}
-	options := StartOperationOptions{
-		RequestID:      request.Header.Get(headerRequestID),
-		CallbackURL:    request.URL.Query().Get(queryCallbackURL),
-		CallbackHeader: prefixStrippedHTTPHeaderToNexusHeader(request.Header, "nexus-callback-"),
-		Header:         httpHeaderToNexusHeader(request.Header, "content-", "nexus-callback-"),
+	if err := checkOperationName(operation); err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "%v", err))
+		return
+	}
+	options, err := StartOperationOptionsFromHTTPRequest(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "%v", err))
+		return
 	}
 	value := &LazyValue{
 		serializer: h.options.Serializer,
-		Reader: &Reader{
-			request.Body,
-			prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-"),
-		},
+		Reader:     readerFromHTTPRequest(request),
 	}
 
 	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
@@ -295,28 +448,50 @@ func (h *httpHandler) startOperation(writer http.ResponseWriter, request *http.R
 	}
 	defer cancel()
 
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodStartOperation,
+		Operation:   operation,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	if err := h.validateCallbackURLs(ctx, options.Callbacks()); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	if err := h.validateInput(operation, value); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
 	response, err := h.options.Handler.StartOperation(ctx, operation, value, options)
 	if err != nil {
 		h.writeFailure(writer, err)
-	} else {
-		response.applyToHTTPResponse(writer, h)
+		return
 	}
+	if async, ok := response.(*HandlerStartOperationResultAsync); ok {
+		if recorder, ok := writer.(*statusRecorder); ok {
+			recorder.operationID = async.OperationID
+		}
+	}
+	response.applyToHTTPResponse(writer, h, operation)
 }
 
 func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *http.Request) {
-	// strip /result
-	prefix, operationIDEscaped := path.Split(path.Dir(request.URL.EscapedPath()))
-	operationID, err := url.PathUnescape(operationIDEscaped)
+	operation, operationID, err := operationPathVars(request)
 	if err != nil {
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 		return
 	}
-	operation, err := url.PathUnescape(path.Base(prefix))
-	if err != nil {
-		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
-		return
+	options := GetOperationResultOptions{
+		Header:         httpHeaderToNexusHeader(request.Header),
+		AcceptRedirect: request.Header.Get(HeaderAcceptResultRedirect) == "true",
+		AcceptPages:    request.Header.Get(HeaderAcceptResultPages) == "true",
+		PageToken:      request.URL.Query().Get(QueryPageToken),
 	}
-	options := GetOperationResultOptions{Header: httpHeaderToNexusHeader(request.Header)}
 
 	// If both Request-Timeout http header and wait query string are set, the minimum of the Request-Timeout header
 	// and h.options.GetResultTimeout will be used.
@@ -325,7 +500,7 @@ func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *ht
 	if !ok {
 		return
 	}
-	waitStr := request.URL.Query().Get(queryWait)
+	waitStr := request.URL.Query().Get(QueryWait)
 	if waitStr != "" {
 		waitDuration, err := time.ParseDuration(waitStr)
 		if err != nil {
@@ -342,32 +517,54 @@ func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *ht
 	}
 	if requestTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(request.Context(), requestTimeout)
+		ctx, cancel = contextWithClockTimeout(request.Context(), h.options.Clock, requestTimeout)
+		defer cancel()
+	}
+	if options.Wait > 0 {
+		// End the long poll on shutdown, responding with 408.
+		var cancel context.CancelFunc
+		ctx, cancel = h.drainer.longPollContext(ctx)
 		defer cancel()
 	}
 
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodGetOperationResult,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
 	result, err := h.options.Handler.GetOperationResult(ctx, operation, operationID, options)
 	if err != nil {
 		if options.Wait > 0 && ctx.Err() != nil {
 			writer.WriteHeader(http.StatusRequestTimeout)
 		} else if errors.Is(err, ErrOperationStillRunning) {
-			writer.WriteHeader(statusOperationRunning)
+			writer.WriteHeader(StatusOperationRunning)
+		} else if errors.Is(err, ErrOperationResultGone) {
+			writer.WriteHeader(http.StatusGone)
 		} else {
 			h.writeFailure(writer, err)
 		}
 		return
 	}
-	h.writeResult(writer, result)
+	if page, ok := result.(*ResultPage); ok {
+		if page.NextPageToken != "" {
+			writer.Header().Set(HeaderResultNextPageToken, page.NextPageToken)
+		}
+		result = page.Value
+	}
+	if redirect, ok := result.(*ResultRedirect); ok {
+		h.writeRedirect(writer, redirect)
+		return
+	}
+	h.writeResult(writer, operation, result)
 }
 
 func (h *httpHandler) getOperationInfo(writer http.ResponseWriter, request *http.Request) {
-	prefix, operationIDEscaped := path.Split(request.URL.EscapedPath())
-	operationID, err := url.PathUnescape(operationIDEscaped)
-	if err != nil {
-		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
-		return
-	}
-	operation, err := url.PathUnescape(path.Base(prefix))
+	operation, operationID, err := operationPathVars(request)
 	if err != nil {
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 		return
@@ -380,13 +577,24 @@ func (h *httpHandler) getOperationInfo(writer http.ResponseWriter, request *http
 	}
 	defer cancel()
 
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodGetOperationInfo,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
 	info, err := h.options.Handler.GetOperationInfo(ctx, operation, operationID, options)
 	if err != nil {
 		h.writeFailure(writer, err)
 		return
 	}
+	info = h.encodeInfoFailures(info)
 
-	bytes, err := json.Marshal(info)
+	bytes, err := h.json.marshal(info)
 	if err != nil {
 		h.writeFailure(writer, fmt.Errorf("failed to marshal operation info: %w", err))
 		return
@@ -398,19 +606,27 @@ func (h *httpHandler) getOperationInfo(writer http.ResponseWriter, request *http
 }
 
 func (h *httpHandler) cancelOperation(writer http.ResponseWriter, request *http.Request) {
-	// strip /cancel
-	prefix, operationIDEscaped := path.Split(path.Dir(request.URL.EscapedPath()))
-	operationID, err := url.PathUnescape(operationIDEscaped)
+	operation, operationID, err := operationPathVars(request)
 	if err != nil {
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 		return
 	}
-	operation, err := url.PathUnescape(path.Base(prefix))
+	reason, err := cancelReasonFromHTTPRequest(request, h.json)
 	if err != nil {
-		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
-	options := CancelOperationOptions{Header: httpHeaderToNexusHeader(request.Header)}
+	mode, err := cancelModeFromHTTPRequest(request)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	options := CancelOperationOptions{
+		Header:    httpHeaderToNexusHeader(request.Header),
+		RequestID: request.Header.Get(HeaderRequestID),
+		Reason:    reason,
+		Mode:      mode,
+	}
 
 	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
 	if !ok {
@@ -418,6 +634,16 @@ func (h *httpHandler) cancelOperation(writer http.ResponseWriter, request *http.
 	}
 	defer cancel()
 
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodCancelOperation,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
 	if err := h.options.Handler.CancelOperation(ctx, operation, operationID, options); err != nil {
 		h.writeFailure(writer, err)
 		return
@@ -426,11 +652,74 @@ func (h *httpHandler) cancelOperation(writer http.ResponseWriter, request *http.
 	writer.WriteHeader(http.StatusAccepted)
 }
 
-// parseRequestTimeoutHeader checks if the Request-Timeout HTTP header is set and returns the parsed duration if so.
+func (h *httpHandler) watchOperation(writer http.ResponseWriter, request *http.Request) {
+	operation, operationID, err := operationPathVars(request)
+	if err != nil {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
+		return
+	}
+	options := WatchOperationOptions{Header: httpHeaderToNexusHeader(request.Header)}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+	// Close the stream on shutdown.
+	ctx, stop := h.drainer.longPollContext(ctx)
+	defer stop()
+
+	if !h.authorize(ctx, writer, &AuthorizationRequest{
+		Method:      HandlerMethodWatchOperation,
+		Operation:   operation,
+		OperationID: operationID,
+		Header:      options.Header,
+		HTTPRequest: request,
+	}) {
+		return
+	}
+
+	events, err := h.options.Handler.WatchOperation(ctx, operation, operationID, options)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	controller := http.NewResponseController(writer)
+	writer.Header().Set("Content-Type", contentTypeEventStream)
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		h.logger.Error("failed to flush response", "error", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(writer, h.json, operationEventState, h.encodeInfoFailures(info)); err != nil {
+				h.logger.Error("failed to write operation event", "error", err)
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				h.logger.Error("failed to flush response", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// parseRequestTimeoutHeader checks if the Request-Timeout HTTP header is set and returns the parsed duration if so,
+// clamped to the handler's [HandlerOptions.MinRequestTimeout] and [HandlerOptions.MaxRequestTimeout].
 // Returns (0, true) if unset. Returns ({parsedDuration}, true) if set. If set and there is an error parsing the
 // duration, it writes a failure response and returns (0, false).
 func (h *httpHandler) parseRequestTimeoutHeader(writer http.ResponseWriter, request *http.Request) (time.Duration, bool) {
-	timeoutStr := request.Header.Get(headerRequestTimeout)
+	timeoutStr := request.Header.Get(HeaderRequestTimeout)
 	if timeoutStr != "" {
 		timeoutDuration, err := time.ParseDuration(timeoutStr)
 		if err != nil {
@@ -438,6 +727,12 @@ func (h *httpHandler) parseRequestTimeoutHeader(writer http.ResponseWriter, requ
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request timeout header"))
 			return 0, false
 		}
+		if h.options.MinRequestTimeout > 0 {
+			timeoutDuration = max(timeoutDuration, h.options.MinRequestTimeout)
+		}
+		if h.options.MaxRequestTimeout > 0 {
+			timeoutDuration = min(timeoutDuration, h.options.MaxRequestTimeout)
+		}
 		return timeoutDuration, true
 	}
 	return 0, true
@@ -451,7 +746,7 @@ func (h *httpHandler) contextWithTimeoutFromHTTPRequest(writer http.ResponseWrit
 		return nil, nil, false
 	}
 	if requestTimeout > 0 {
-		ctx, cancel := context.WithTimeout(request.Context(), requestTimeout)
+		ctx, cancel := contextWithClockTimeout(request.Context(), h.options.Clock, requestTimeout)
 		return ctx, cancel, true
 	}
 	return request.Context(), func() {}, true
@@ -461,21 +756,115 @@ func (h *httpHandler) contextWithTimeoutFromHTTPRequest(writer http.ResponseWrit
 type HandlerOptions struct {
 	// Handler for handling service requests.
 	Handler Handler
+	// Optional name of the service, exposed to handler methods via [HandlerInfoFromContext].
+	Service string
 	// A stuctured logger.
 	// Defaults to slog.Default().
+	//
+	// Log lines of a request carry request scoped attributes: the method, operation, operation ID, request ID and
+	// remote address.
 	Logger *slog.Logger
+	// Optional provider of per request loggers, e.g. to attach correlation IDs extracted from request headers, see
+	// [NewHeaderLoggerProvider].
+	LoggerProvider LoggerProvider
+	// Determines which requests a summary line with the response status and latency is logged for.
+	// Defaults to failed requests only.
+	RequestLogVerbosity RequestLogVerbosity
 	// Max duration to allow waiting for a single get result request.
 	// Enforced if provided for requests with the wait query parameter set.
 	//
 	// Defaults to one minute.
 	GetResultTimeout time.Duration
+	// Bounds of the timeout callers request via the Request-Timeout header, which sets the deadline of the context
+	// passed to handler methods. Shorter timeouts are raised to MinRequestTimeout, e.g. to leave handlers enough time to
+	// respond, and longer timeouts are lowered to MaxRequestTimeout, e.g. to bound the work done on behalf of a single
+	// request. Requests without the header are not bounded. Zero means no bound.
+	MinRequestTimeout time.Duration
+	MaxRequestTimeout time.Duration
 	// A [Serializer] to customize handler serialization behavior.
 	// By default the handler handles, JSONables, byte slices, and nil.
+	// A [NegotiatingSerializer] serializes results as the media type preferred by the request's Accept header.
 	Serializer Serializer
+	// JSON implementation for the default serializer and for encoding operation info and failures. Defaults to
+	// encoding/json.
+	JSON JSONEngine
+	// Optional converter for encoding failures before they are sent to callers, in failure responses and in operation
+	// info, see [FailureConverter].
+	FailureConverter FailureConverter
+	// Maximum size of request bodies, e.g. operation inputs, in bytes. Requests declaring a larger Content-Length are
+	// failed with 413 Request Entity Too Large before their body is read, reading past the limit of bodies of unknown
+	// length fails with an [http.MaxBytesError], which is translated to 413 unless wrapped in a [HandlerError].
+	// Zero means no limit.
+	MaxRequestBodySize int64
+	// Optional algorithm of checksums sent with operation results in the "digest" content header, i.e. the HTTP
+	// Content-Digest header, letting clients detect results corrupted in transit. Results returned as a [Reader] are
+	// sent without a checksum unless their header has a digest. Received inputs are verified against their digest, if
+	// any, regardless of this option. See [ChecksumAlgorithm] and [IntegrityError].
+	Checksum ChecksumAlgorithm
+	// Optional registry of payload schemas. Inputs are validated before they are passed to the Handler, rejecting
+	// requests with invalid inputs with a 400 (Bad Request) status code, and results before they are sent, see
+	// [SchemaValidationError].
+	SchemaRegistry *SchemaRegistry
+	// Panics of Handler methods are recovered, logged with their stack and fail the request with a 500 [HandlerError].
+	// OnPanic is an optional function called with the recovered value and stack, e.g. to record a metric. The context
+	// carries the request's [HandlerInfo].
+	OnPanic func(ctx context.Context, value any, stack []byte)
+	// Include the panic value, stripped of control characters and truncated, in the failure of requests failed due to a
+	// panic. By default the failure message is a generic "internal server error".
+	ExposePanicDetails bool
+	// Optional provider of header fields attached to every response, including asynchronous start responses and
+	// failures, e.g. Retry-After hints or rate-limit headers. See [ResponseHeaderProvider].
+	ResponseHeaderProvider ResponseHeaderProvider
+	// Optional sink recording an audit event for every state-changing request, e.g. start and cancel requests, with
+	// the caller's identity, the operation, the outcome and timing. See [AuditSink].
+	AuditSink AuditSink
+	// Faults injected into requests before they are dispatched to the Handler, for resilience testing. See [Fault].
+	Faults []Fault
+	// An optional [Authorizer] invoked before dispatching every request to the Handler.
+	Authorizer Authorizer
+	// Maximum number of requests handled concurrently across all methods. Requests exceeding the limit are rejected
+	// with 503 Service Unavailable and a Retry-After header instead of being queued. Zero means no limit.
+	MaxConcurrentRequests int
+	// Maximum number of requests handled concurrently per method, enforced in addition to MaxConcurrentRequests.
+	// Useful for bounding long polls, e.g. [HandlerMethodGetOperationResult] and [HandlerMethodWatchOperation], which
+	// hold on to a goroutine for their entire duration, separately from start and cancel requests.
+	MaxConcurrentRequestsPerMethod map[HandlerMethod]int
+	// Delay clients are asked to wait before retrying requests rejected due to concurrency limits, rounded up to whole
+	// seconds. Defaults to one second.
+	LoadSheddingRetryAfter time.Duration
+	// Validates the callback URLs of start requests, see [StartOperationOptions.Callbacks]. Start requests with
+	// invalid callback URLs are rejected with 400 before being dispatched to the Handler.
+	//
+	// Defaults to rejecting URLs whose host is a link-local address or a well-known metadata endpoint, such as
+	// 169.254.169.254. Use a [CallbackURLPolicy] to restrict callback URLs further, e.g. to an allowlist of hosts.
+	CallbackURLValidator CallbackURLValidator
+	// Serve liveness and readiness probes at GET /healthz and GET /readyz, e.g. for load balancers and Kubernetes.
+	// Probes are neither authorized nor subject to concurrency limits. Liveness probes always succeed. Readiness
+	// probes fail with 503 while the handler is shutting down or when the HealthChecker fails.
+	HealthEndpoints bool
+	// Optional checker invoked by readiness probes, see HealthEndpoints. Defaults to the Handler if it implements
+	// [HealthChecker].
+	HealthChecker HealthChecker
+	// Timeout of readiness checks. Defaults to 5 seconds.
+	HealthCheckTimeout time.Duration
+	// Clock measuring the GetResultTimeout and request timeouts, and resolving start delays to start times, see
+	// [StartOperationOptions.StartDelay]. Attached to the context passed to Handler methods, see [ClockFromContext].
+	// Defaults to [SystemClock].
+	Clock Clock
+	// Strictly validate the URL, header fields and query parameters of requests before dispatching them, rejecting
+	// requests that would otherwise be handled leniently, e.g. with duplicate or malformed header fields, unknown
+	// operation states in list filters, negative durations or bodies without a Content-Type, with 400 Bad Request.
+	// The failure of rejected requests carries a machine readable [RequestValidationCode] in its metadata, see
+	// [RequestValidationErrorFromFailure].
+	StrictValidation bool
+	// Layout of the URL paths requests are routed by, e.g. to serve the handler behind a gateway with existing path
+	// conventions. Clients must be created with the same [ClientOptions.PathBuilder]. NewHTTPHandler panics if the
+	// PathBuilder's templates are invalid. Defaults to the zero value layout, see [PathBuilder].
+	PathBuilder PathBuilder
 }
 
-// NewHTTPHandler constructs an [http.Handler] from given options for handling Nexus service requests.
-func NewHTTPHandler(options HandlerOptions) http.Handler {
+// NewHTTPHandler constructs an [HTTPHandler] from given options for handling Nexus service requests.
+func NewHTTPHandler(options HandlerOptions) HTTPHandler {
 	if options.Logger == nil {
 		options.Logger = slog.Default()
 	}
@@ -483,19 +872,118 @@ func NewHTTPHandler(options HandlerOptions) http.Handler {
 		options.GetResultTimeout = time.Minute
 	}
 	if options.Serializer == nil {
-		options.Serializer = defaultSerializer
+		options.Serializer = NewJSONSerializer(options.JSON)
+	}
+	if options.HealthCheckTimeout == 0 {
+		options.HealthCheckTimeout = defaultHealthCheckTimeout
+	}
+	if options.Clock == nil {
+		options.Clock = SystemClock
+	}
+	options.PathBuilder = options.PathBuilder.withDefaults(options.Service)
+	if err := options.PathBuilder.validate(); err != nil {
+		panic(err)
 	}
 	handler := &httpHandler{
 		baseHTTPHandler: baseHTTPHandler{
-			logger: options.Logger,
+			logger:                 options.Logger,
+			loggerProvider:         options.LoggerProvider,
+			requestLogVerbosity:    options.RequestLogVerbosity,
+			failureConverter:       options.FailureConverter,
+			onPanic:                options.OnPanic,
+			exposePanicDetails:     options.ExposePanicDetails,
+			responseHeaderProvider: options.ResponseHeaderProvider,
+			json:                   options.JSON,
+			auditSink:              options.AuditSink,
 		},
 		options: options,
+		limiter: newConcurrencyLimiter(options),
+		drainer: newDrainer(),
+	}
+
+	router := &router{
+		notFound:         handler.unrouted(HandlerErrorf(HandlerErrorTypeNotFound, "not found")),
+		methodNotAllowed: handler.unrouted(errMethodNotAllowed),
+	}
+	if options.HealthEndpoints {
+		router.handle("GET", "/healthz", handler.healthz)
+		router.handle("GET", "/readyz", handler.readyz)
+	}
+	paths := options.PathBuilder
+	router.handle("GET", paths.route(paths.Root, "_nexus"), handler.route(HandlerMethodPing, (*httpHandler).ping))
+	router.handle("GET", paths.route(paths.Root), handler.route(HandlerMethodListOperations, (*httpHandler).listOperations))
+	router.handle("POST", paths.route(paths.Root, "_admin", "cancel", "batch"), handler.route(HandlerMethodCancelOperations, (*httpHandler).cancelOperations))
+	router.handle("POST", paths.route(paths.Root, "_admin", "cancel"), handler.route(HandlerMethodCancelMatchingOperations, (*httpHandler).cancelMatchingOperations))
+	router.handle("GET", paths.route(paths.Root, "_admin", "usage", "{subject}"), handler.route(HandlerMethodGetQuotaUsage, (*httpHandler).getQuotaUsage))
+	router.handle("POST", paths.route(paths.Root, "_admin", "quarantine", "{operation}", "{operation_id}"), handler.route(HandlerMethodResolveQuarantine, (*httpHandler).resolveQuarantine))
+	router.handle("POST", paths.route(paths.Root, "_admin", "complete", "{operation}", "{operation_id}"), handler.route(HandlerMethodForceCompleteOperation, (*httpHandler).forceCompleteOperation))
+	router.handle("POST", paths.route(paths.Operation), handler.route(HandlerMethodStartOperation, (*httpHandler).startOperation))
+	router.handle("GET", paths.route(paths.OperationInstance), handler.route(HandlerMethodGetOperationInfo, (*httpHandler).getOperationInfo))
+	router.handle("GET", paths.route(paths.OperationInstance, "result"), handler.route(HandlerMethodGetOperationResult, (*httpHandler).getOperationResult))
+	router.handle("POST", paths.route(paths.OperationInstance, "cancel"), handler.route(HandlerMethodCancelOperation, (*httpHandler).cancelOperation))
+	router.handle("GET", paths.route(paths.OperationInstance, "events"), handler.route(HandlerMethodWatchOperation, (*httpHandler).watchOperation))
+	router.handle("POST", paths.route(paths.OperationInstance, "heartbeat"), handler.route(HandlerMethodHeartbeatOperation, (*httpHandler).heartbeatOperation))
+	router.handle("POST", paths.route(paths.OperationInstance, "result", "claim"), handler.route(HandlerMethodClaimOperationResult, (*httpHandler).claimOperationResult))
+	router.handle("POST", paths.route(paths.OperationInstance, "result", "ack"), handler.route(HandlerMethodAckOperationResult, (*httpHandler).ackOperationResult))
+	handler.router = router
+	return handler
+}
+
+// unrouted returns a handler failing requests that match no route with the given error. Like routed requests, these
+// requests are logged and get the headers of the ResponseHeaderProvider.
+func (h *httpHandler) unrouted(err error) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		rh := *h
+		writer, done := rh.startRequestLog(writer, request, "httpMethod", request.Method, "path", request.URL.EscapedPath())
+		defer done()
+		defer rh.provideResponseHeaders(request.Context(), writer)()
+		rh.writeFailure(writer, err)
 	}
+}
 
-	router := mux.NewRouter().UseEncodedPath()
-	router.HandleFunc("/{operation}", handler.startOperation).Methods("POST")
-	router.HandleFunc("/{operation}/{operation_id}", handler.getOperationInfo).Methods("GET")
-	router.HandleFunc("/{operation}/{operation_id}/result", handler.getOperationResult).Methods("GET")
-	router.HandleFunc("/{operation}/{operation_id}/cancel", handler.cancelOperation).Methods("POST")
-	return router
+// route wraps a route handler for the given method. Each request is handled by a copy of h whose logger carries
+// request scoped attributes, its context carries the request's [HandlerInfo], and it is subject to the configured
+// concurrency limits. Requests are rejected once the handler is shut down.
+func (h *httpHandler) route(method HandlerMethod, handle func(*httpHandler, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		rh := *h
+		rh.accept = request.Header.Get("Accept")
+		writer, done := rh.startRequestLog(writer, request, routeLogAttrs(method, request)...)
+		request = request.WithContext(ContextWithHandlerInfo(ContextWithClock(request.Context(), h.options.Clock), HandlerInfo{
+			Method:        method,
+			Service:       h.options.Service,
+			RequestID:     request.Header.Get(HeaderRequestID),
+			UserAgent:     request.Header.Get(headerUserAgent),
+			ClientName:    request.Header.Get(HeaderClientName),
+			ClientVersion: request.Header.Get(HeaderClientVersion),
+		}))
+		defer done()
+		defer rh.startAudit(writer, request, method)()
+		defer rh.provideResponseHeaders(request.Context(), writer)()
+		if !h.drainer.enter() {
+			writer.Header().Set("Connection", "close")
+			rh.writeFailure(writer, HandlerErrorf(HandlerErrorTypeUnavailable, "server is shutting down"))
+			return
+		}
+		defer h.drainer.leave()
+		if !rh.limitRequestBody(writer, request, h.options.MaxRequestBodySize) {
+			return
+		}
+		if h.options.StrictValidation {
+			if err := validateRequest(method, request); err != nil {
+				rh.writeFailure(writer, err.handlerError())
+				return
+			}
+		}
+		release, ok := rh.limit(method, writer)
+		if !ok {
+			return
+		}
+		defer release()
+		defer rh.recoverPanic(request.Context(), writer)
+		if !rh.injectFaults(writer, request, h.options.Faults) {
+			return
+		}
+		handle(&rh, writer, request)
+	}
 }
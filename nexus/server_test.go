@@ -54,9 +54,9 @@ func TestWriteFailure_UnsuccessfulOperationError(t *testing.T) {
 		Failure: Failure{Message: "canceled"},
 	})
 
-	require.Equal(t, statusOperationFailed, writer.Code)
+	require.Equal(t, StatusOperationFailed, writer.Code)
 	require.Equal(t, contentTypeJSON, writer.Header().Get("Content-Type"))
-	require.Equal(t, string(OperationStateCanceled), writer.Header().Get(headerOperationState))
+	require.Equal(t, string(OperationStateCanceled), writer.Header().Get(HeaderOperationState))
 
 	var failure *Failure
 	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
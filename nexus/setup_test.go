@@ -14,21 +14,27 @@ import (
 const testTimeout = time.Second * 5
 const getResultMaxTimeout = time.Millisecond * 300
 
-func setupSerializer(t *testing.T, handler Handler, serializer Serializer) (ctx context.Context, client *Client, teardown func()) {
-	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
-
-	httpHandler := NewHTTPHandler(HandlerOptions{
+func setupSerializer(t testing.TB, handler Handler, serializer Serializer) (ctx context.Context, client *Client, teardown func()) {
+	return setupCustom(t, HandlerOptions{
 		GetResultTimeout: getResultMaxTimeout,
 		Handler:          handler,
 		Serializer:       serializer,
+	}, ClientOptions{
+		Serializer: serializer,
 	})
+}
+
+// setupCustom serves an HTTP handler constructed from the given options and creates a client pointing at it.
+// The client's ServiceBaseURL is overridden.
+func setupCustom(t testing.TB, handlerOptions HandlerOptions, clientOptions ClientOptions) (ctx context.Context, client *Client, teardown func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+
+	httpHandler := NewHTTPHandler(handlerOptions)
 
 	listener, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
-	client, err = NewClient(ClientOptions{
-		ServiceBaseURL: fmt.Sprintf("http://%s/", listener.Addr().String()),
-		Serializer:     serializer,
-	})
+	clientOptions.ServiceBaseURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+	client, err = NewClient(clientOptions)
 	require.NoError(t, err)
 
 	go func() {
@@ -42,7 +48,7 @@ func setupSerializer(t *testing.T, handler Handler, serializer Serializer) (ctx
 	}
 }
 
-func setup(t *testing.T, handler Handler) (ctx context.Context, client *Client, teardown func()) {
+func setup(t testing.TB, handler Handler) (ctx context.Context, client *Client, teardown func()) {
 	return setupSerializer(t, handler, nil)
 }
 
@@ -0,0 +1,99 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// An HTTPHandler is an [http.Handler] serving Nexus service requests that can be shut down gracefully.
+//
+// Obtain one via [NewHTTPHandler].
+type HTTPHandler interface {
+	http.Handler
+	// Shutdown stops accepting new requests, rejecting them with 503 Service Unavailable, and promptly ends in-flight
+	// long polls: get result requests waiting for the operation to complete are responded to with 408 Request
+	// Timeout, prompting the caller to retry elsewhere, and watch streams are closed. It then waits for all in-flight
+	// requests to be handled or for ctx to be done, in which case ctx's error is returned.
+	//
+	// Shutdown does not close listeners or connections. Call it when shutting down the [http.Server] serving the
+	// handler, e.g. via [http.Server.RegisterOnShutdown], so that long polls do not hold up the server's shutdown.
+	Shutdown(ctx context.Context) error
+}
+
+// drainer tracks in-flight requests of an HTTP handler for graceful shutdown.
+type drainer struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+	// Canceled on shutdown, ending long polls.
+	shutdownCtx context.Context
+	shutdown    context.CancelFunc
+}
+
+func newDrainer() *drainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &drainer{shutdownCtx: ctx, shutdown: cancel}
+}
+
+// enter registers an in-flight request, returning false if the handler is shut down.
+func (d *drainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return false
+	}
+	d.inFlight.Add(1)
+	return true
+}
+
+// draining reports whether the handler is shut down.
+func (d *drainer) draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// leave unregisters an in-flight request registered with enter.
+func (d *drainer) leave() {
+	d.inFlight.Done()
+}
+
+// longPollContext returns a copy of ctx that is canceled on shutdown.
+func (d *drainer) longPollContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(d.shutdownCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.shutdown()
+
+	drained := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown implements HTTPHandler.
+func (h *httpHandler) Shutdown(ctx context.Context) error {
+	return h.drainer.drain(ctx)
+}
+
+// ServeHTTP implements HTTPHandler.
+func (h *httpHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	h.router.ServeHTTP(writer, request)
+}
@@ -0,0 +1,85 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type blockingHandler struct {
+	UnimplementedHandler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.started <- struct{}{}
+	<-h.release
+	return &HandlerStartOperationResultAsync{OperationID: "a"}, nil
+}
+
+func (h *blockingHandler) GetOperationResult(ctx context.Context, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	h.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func setupShutdown(t *testing.T, handler Handler) (HTTPHandler, *Client, func()) {
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler})
+	server := httptest.NewServer(httpHandler)
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+	return httpHandler, client, server.Close
+}
+
+func TestShutdown_EndsLongPolls(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}, 1)}
+	httpHandler, client, teardown := setupShutdown(t, handler)
+	defer teardown()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	handle, err := client.NewHandle("foo", "a")
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute, SinglePoll: true})
+		errCh <- err
+	}()
+	<-handler.started
+
+	require.NoError(t, httpHandler.Shutdown(ctx))
+	require.ErrorIs(t, <-errCh, ErrOperationStillRunning)
+
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusServiceUnavailable, unexpectedError.Response.StatusCode)
+}
+
+func TestShutdown_WaitsForInFlightRequests(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}, 1), release: make(chan struct{})}
+	httpHandler, client, teardown := setupShutdown(t, handler)
+	defer teardown()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+		errCh <- err
+	}()
+	<-handler.started
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer shutdownCancel()
+	require.ErrorIs(t, httpHandler.Shutdown(shutdownCtx), context.DeadlineExceeded)
+
+	close(handler.release)
+	require.NoError(t, <-errCh)
+	require.NoError(t, httpHandler.Shutdown(ctx))
+}
@@ -0,0 +1,148 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// pageToken identifies the last record of a page returned by Store.ListOperations.
+type pageToken struct {
+	CreatedAt   int64  `json:"c"`
+	Operation   string `json:"o"`
+	OperationID string `json:"i"`
+}
+
+func decodePageToken(token string) (*pageToken, error) {
+	if token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "invalid page token")
+	}
+	var after pageToken
+	if err := json.Unmarshal(b, &after); err != nil {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "invalid page token")
+	}
+	return &after, nil
+}
+
+func encodePageToken(token pageToken) (string, error) {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// listQuery builds a query for up to limit records matching the filter's indexed fields, ordered newest first and
+// starting after the given position. Tags are stored in the encoded record and filtered by the caller.
+func (s *Store) listQuery(filter nexus.OperationFilter, after *pageToken, limit int) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.Operation != "" {
+		conditions = append(conditions, "operation = ?")
+		args = append(args, filter.Operation)
+	}
+	if len(filter.States) > 0 {
+		placeholders := make([]string, len(filter.States))
+		for i, state := range filter.States {
+			placeholders[i] = "?"
+			args = append(args, string(state))
+		}
+		conditions = append(conditions, fmt.Sprintf("state IN (%s)", strings.Join(placeholders, ", ")))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedAfter.UnixNano())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedBefore.UnixNano())
+	}
+	if after != nil {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND (operation < ? OR (operation = ? AND id < ?))))")
+		args = append(args, after.CreatedAt, after.CreatedAt, after.Operation, after.Operation, after.OperationID)
+	}
+	q := fmt.Sprintf("SELECT record, result_data, version FROM %s", s.options.TableName)
+	if len(conditions) > 0 {
+		q += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	q += fmt.Sprintf(" ORDER BY created_at DESC, operation DESC, id DESC LIMIT %d", limit)
+	return s.query(q), args
+}
+
+// ListOperations implements nexus.OperationLister.
+//
+// Records are ordered by creation time, newest first. Filtering by operation name, state and creation time is done by
+// the database; filtering by tags and expiry time is done by the store after decoding records, so listing by tag or
+// expiry alone scans all records in the worst case.
+func (s *Store) ListOperations(ctx context.Context, filter nexus.OperationFilter, pageSize int, token string) ([]*nexus.OperationRecord, string, error) {
+	after, err := decodePageToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+	if pageSize <= 0 {
+		pageSize = nexus.DefaultListPageSize
+	}
+	var matches []*nexus.OperationRecord
+	// Fetch one more record than requested to determine whether there is a next page.
+	for len(matches) <= pageSize {
+		batch, err := s.listBatch(ctx, filter, after, pageSize+1)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, record := range batch {
+			if filter.Matches(record) {
+				matches = append(matches, record)
+			}
+		}
+		if len(batch) < pageSize+1 {
+			break
+		}
+		last := batch[len(batch)-1]
+		after = &pageToken{last.CreatedAt.UnixNano(), last.Operation, last.ID}
+	}
+	if len(matches) <= pageSize {
+		return matches, "", nil
+	}
+	matches = matches[:pageSize]
+	last := matches[pageSize-1]
+	next, err := encodePageToken(pageToken{last.CreatedAt.UnixNano(), last.Operation, last.ID})
+	if err != nil {
+		return nil, "", err
+	}
+	return matches, next, nil
+}
+
+func (s *Store) listBatch(ctx context.Context, filter nexus.OperationFilter, after *pageToken, limit int) ([]*nexus.OperationRecord, error) {
+	q, args := s.listQuery(filter, after, limit)
+	rows, err := s.options.DB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*nexus.OperationRecord
+	for rows.Next() {
+		var values string
+		var data []byte
+		var version int64
+		if err := rows.Scan(&values, &data, &version); err != nil {
+			return nil, err
+		}
+		record, err := decodeRecord(values, data)
+		if err != nil {
+			return nil, err
+		}
+		record.Version = version
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+var _ nexus.OperationLister = &Store{}
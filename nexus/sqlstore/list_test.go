@@ -0,0 +1,45 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListQuery(t *testing.T) {
+	s := &Store{options: Options{Dialect: PostgreSQL, TableName: "ops"}}
+	q, args := s.listQuery(nexus.OperationFilter{}, nil, 10)
+	require.Equal(t, "SELECT record, result_data, version FROM ops ORDER BY created_at DESC, operation DESC, id DESC LIMIT 10", q)
+	require.Empty(t, args)
+
+	createdAfter := time.Unix(0, 100)
+	q, args = s.listQuery(nexus.OperationFilter{
+		Operation:    "foo",
+		States:       []nexus.OperationState{nexus.OperationStateRunning, nexus.OperationStateFailed},
+		CreatedAfter: createdAfter,
+		Tags:         map[string]string{"tenant": "a"},
+	}, &pageToken{CreatedAt: 200, Operation: "foo", OperationID: "id"}, 11)
+	require.Equal(t, "SELECT record, result_data, version FROM ops WHERE operation = $1 AND state IN ($2, $3) AND created_at >= $4 AND "+
+		"(created_at < $5 OR (created_at = $6 AND (operation < $7 OR (operation = $8 AND id < $9)))) "+
+		"ORDER BY created_at DESC, operation DESC, id DESC LIMIT 11", q)
+	require.Equal(t, []any{"foo", "running", "failed", int64(100), int64(200), int64(200), "foo", "foo", "id"}, args)
+}
+
+func TestPageToken(t *testing.T) {
+	token, err := encodePageToken(pageToken{CreatedAt: 1, Operation: "foo", OperationID: "id"})
+	require.NoError(t, err)
+	decoded, err := decodePageToken(token)
+	require.NoError(t, err)
+	require.Equal(t, &pageToken{CreatedAt: 1, Operation: "foo", OperationID: "id"}, decoded)
+
+	decoded, err = decodePageToken("")
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	_, err = decodePageToken("not a token")
+	var handlerError *nexus.HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, nexus.HandlerErrorTypeBadRequest, handlerError.Type)
+}
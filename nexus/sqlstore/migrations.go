@@ -0,0 +1,67 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+)
+
+// A migration upgrades the schema by one version.
+type migration func(table string, dialect Dialect) []string
+
+// Schema migrations, applied in order. Append new migrations to the end of this list, never modify existing ones.
+var migrations = []migration{
+	func(table string, d Dialect) []string {
+		return []string{
+			fmt.Sprintf(`CREATE TABLE %s (
+	operation %s NOT NULL,
+	id %s NOT NULL,
+	request_id %s NOT NULL,
+	state %s NOT NULL,
+	version BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	record %s NOT NULL,
+	result_data %s,
+	PRIMARY KEY (operation, id)
+)`, table, d.StringType, d.StringType, d.StringType, d.StringType, d.TextType, d.BlobType),
+			fmt.Sprintf("CREATE INDEX %s_state_idx ON %s (state, created_at)", table, table),
+		}
+	},
+}
+
+// Migrate creates or upgrades the store's schema to the latest version.
+//
+// Applied migrations are tracked in a table named after the store's table with a "_migrations" suffix. Migrate is not
+// safe to run concurrently from multiple processes; run it once as part of deployment or service startup.
+func (s *Store) Migrate(ctx context.Context) error {
+	migrationsTable := s.options.TableName + "_migrations"
+	_, err := s.options.DB.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL PRIMARY KEY)", migrationsTable))
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	var current int
+	err = s.options.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", migrationsTable)).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	for i := current; i < len(migrations); i++ {
+		tx, err := s.options.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, statement := range migrations[i](s.options.TableName, s.options.Dialect) {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, s.query(fmt.Sprintf("INSERT INTO %s (version) VALUES (?)", migrationsTable)), i+1); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+// Package sqlstore provides a database-agnostic, SQL-backed [nexus.OperationStore], [nexus.OperationLister] and
+// [nexus.OperationDeleter] built on [database/sql].
+//
+// The store works with any database/sql driver (e.g. pgx's stdlib adapter, lib/pq, go-sql-driver/mysql, or a SQLite
+// driver); select the matching [Dialect] when constructing the store and call [Store.Migrate] to create or upgrade
+// the schema.
+//
+// State transitions are guarded by optimistic concurrency control on a version column. Long polls are woken up by
+// polling the version column, or - when a [Listener] is provided - via notifications published by the store, such as
+// PostgreSQL's LISTEN/NOTIFY.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// A Dialect captures the differences between SQL databases that are relevant to the store.
+type Dialect struct {
+	// Name of the dialect.
+	Name string
+	// Placeholder returns the bind parameter placeholder for the given 1-based parameter index.
+	Placeholder func(index int) string
+	// Column type used for short, indexable strings.
+	StringType string
+	// Column type used for large text values.
+	TextType string
+	// Column type used for binary values.
+	BlobType string
+	// Optional statement template used to publish a notification when a record changes. Invoked with the channel
+	// name and the changed record's key as its two bind parameters. Leave empty for databases without notification
+	// support.
+	NotifyStatement string
+}
+
+// PostgreSQL dialect. Publishes record changes via pg_notify for use with a [Listener].
+var PostgreSQL = Dialect{
+	Name:            "postgresql",
+	Placeholder:     func(index int) string { return fmt.Sprintf("$%d", index) },
+	StringType:      "VARCHAR(255)",
+	TextType:        "TEXT",
+	BlobType:        "BYTEA",
+	NotifyStatement: "SELECT pg_notify($1, $2)",
+}
+
+// MySQL dialect.
+var MySQL = Dialect{
+	Name:        "mysql",
+	Placeholder: func(int) string { return "?" },
+	StringType:  "VARCHAR(255)",
+	TextType:    "LONGTEXT",
+	BlobType:    "LONGBLOB",
+}
+
+// SQLite dialect.
+var SQLite = Dialect{
+	Name:        "sqlite",
+	Placeholder: func(int) string { return "?" },
+	StringType:  "TEXT",
+	TextType:    "TEXT",
+	BlobType:    "BLOB",
+}
+
+// A Listener delivers notifications published by the store on [Store.NotificationChannel], e.g. backed by lib/pq's
+// Listener or pgx's WaitForNotification. Payloads are opaque to the caller.
+type Listener interface {
+	// Notifications returns a channel of notification payloads. The channel should be closed when the listener is
+	// closed.
+	Notifications() <-chan string
+}
+
+// Options are options for [New].
+type Options struct {
+	// Database handle. Required.
+	DB *sql.DB
+	// Dialect of the database. Required.
+	Dialect Dialect
+	// Name of the table to store operations in.
+	// Defaults to "nexus_operations".
+	TableName string
+	// Interval for polling for record updates in WaitForUpdate.
+	// Used as a fallback when a Listener is set in case notifications are missed.
+	// Defaults to 500 milliseconds.
+	PollInterval time.Duration
+	// Optional listener for record change notifications. Requires a dialect that supports notifications.
+	Listener Listener
+}
+
+// Store is a SQL-backed [nexus.OperationStore].
+type Store struct {
+	options Options
+
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+// New creates a new [Store] from the given options.
+//
+// When a Listener is set, the store consumes its notifications in a background goroutine until the listener's
+// notification channel is closed.
+func New(options Options) (*Store, error) {
+	if options.DB == nil {
+		return nil, errors.New("db is required")
+	}
+	if options.Dialect.Placeholder == nil {
+		return nil, errors.New("dialect is required")
+	}
+	if options.Listener != nil && options.Dialect.NotifyStatement == "" {
+		return nil, fmt.Errorf("dialect %q does not support notifications", options.Dialect.Name)
+	}
+	if options.TableName == "" {
+		options.TableName = "nexus_operations"
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = 500 * time.Millisecond
+	}
+	s := &Store{
+		options: options,
+		waiters: make(map[string]chan struct{}),
+	}
+	if options.Listener != nil {
+		go s.dispatchNotifications(options.Listener.Notifications())
+	}
+	return s, nil
+}
+
+// NotificationChannel is the name of the channel the store publishes record changes on.
+// Subscribe the [Listener] to this channel, e.g. with "LISTEN nexus_operations" in PostgreSQL.
+func (s *Store) NotificationChannel() string {
+	return s.options.TableName
+}
+
+// query rewrites "?" placeholders in q to the dialect's placeholders.
+func (s *Store) query(q string) string {
+	var b strings.Builder
+	index := 0
+	for _, r := range q {
+		if r == '?' {
+			index++
+			b.WriteString(s.options.Dialect.Placeholder(index))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// recordKey is the key used to identify a record in notifications. Both parts are escaped so that names and IDs
+// containing slashes can't collide.
+func recordKey(operation, operationID string) string {
+	return url.PathEscape(operation) + "/" + url.PathEscape(operationID)
+}
+
+// Create implements nexus.OperationStore.
+func (s *Store) Create(ctx context.Context, record *nexus.OperationRecord) error {
+	values, data, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	tx, err := s.options.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	exists, err := s.exists(ctx, tx, record.Operation, record.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nexus.ErrOperationRecordExists
+	}
+	_, err = tx.ExecContext(ctx, s.query(fmt.Sprintf(
+		"INSERT INTO %s (operation, id, request_id, state, version, created_at, updated_at, record, result_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		s.options.TableName,
+	)), record.Operation, record.ID, record.RequestID, string(record.State), 1, record.CreatedAt.UnixNano(), record.UpdatedAt.UnixNano(), values, data)
+	if err != nil {
+		// Another writer may have created the record after the existence check. Some databases, e.g. PostgreSQL,
+		// abort the transaction on a failed statement, so roll it back and check outside of it.
+		_ = tx.Rollback()
+		if exists, existsErr := s.exists(ctx, s.options.DB, record.Operation, record.ID); existsErr == nil && exists {
+			return nexus.ErrOperationRecordExists
+		}
+		return err
+	}
+	if err := s.notify(ctx, tx, record.Operation, record.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	record.Version = 1
+	return nil
+}
+
+// queryer is implemented by both [sql.DB] and [sql.Tx].
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *Store) exists(ctx context.Context, q queryer, operation, operationID string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx, s.query(fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE operation = ? AND id = ?",
+		s.options.TableName,
+	)), operation, operationID).Scan(&count)
+	return count > 0, err
+}
+
+// Get implements nexus.OperationStore.
+func (s *Store) Get(ctx context.Context, operation, operationID string) (*nexus.OperationRecord, error) {
+	var values string
+	var data []byte
+	var version int64
+	err := s.options.DB.QueryRowContext(ctx, s.query(fmt.Sprintf(
+		"SELECT record, result_data, version FROM %s WHERE operation = ? AND id = ?",
+		s.options.TableName,
+	)), operation, operationID).Scan(&values, &data, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nexus.ErrOperationRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	record, err := decodeRecord(values, data)
+	if err != nil {
+		return nil, err
+	}
+	record.Version = version
+	return record, nil
+}
+
+// Update implements nexus.OperationStore.
+func (s *Store) Update(ctx context.Context, record *nexus.OperationRecord) error {
+	values, data, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	tx, err := s.options.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	result, err := tx.ExecContext(ctx, s.query(fmt.Sprintf(
+		"UPDATE %s SET request_id = ?, state = ?, version = version + 1, updated_at = ?, record = ?, result_data = ? WHERE operation = ? AND id = ? AND version = ?",
+		s.options.TableName,
+	)), record.RequestID, string(record.State), record.UpdatedAt.UnixNano(), values, data, record.Operation, record.ID, record.Version)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		exists, err := s.exists(ctx, tx, record.Operation, record.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return nexus.ErrOperationRecordNotFound
+		}
+		return nexus.ErrOperationRecordVersionConflict
+	}
+	if err := s.notify(ctx, tx, record.Operation, record.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	record.Version++
+	return nil
+}
+
+// Delete implements nexus.OperationDeleter.
+func (s *Store) Delete(ctx context.Context, operation, operationID string) error {
+	tx, err := s.options.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.ExecContext(ctx, s.query(fmt.Sprintf(
+		"DELETE FROM %s WHERE operation = ? AND id = ?",
+		s.options.TableName,
+	)), operation, operationID)
+	if err != nil {
+		return err
+	}
+	if err := s.notify(ctx, tx, operation, operationID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// WaitForUpdate implements nexus.OperationStore.
+func (s *Store) WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	key := recordKey(operation, operationID)
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Register before checking to avoid missing a notification published between the check and the wait.
+		var notified <-chan struct{}
+		if s.options.Listener != nil {
+			notified = s.waiter(key)
+		}
+		var current int64
+		err := s.options.DB.QueryRowContext(ctx, s.query(fmt.Sprintf(
+			"SELECT version FROM %s WHERE operation = ? AND id = ?",
+			s.options.TableName,
+		)), operation, operationID).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if current > version {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-notified:
+		}
+	}
+}
+
+func (s *Store) waiter(key string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.waiters[key]
+	if !ok {
+		ch = make(chan struct{})
+		s.waiters[key] = ch
+	}
+	return ch
+}
+
+func (s *Store) dispatchNotifications(notifications <-chan string) {
+	for key := range notifications {
+		s.mu.Lock()
+		if ch, ok := s.waiters[key]; ok {
+			close(ch)
+			delete(s.waiters, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Store) notify(ctx context.Context, tx *sql.Tx, operation, operationID string) error {
+	if s.options.Dialect.NotifyStatement == "" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, s.options.Dialect.NotifyStatement, s.NotificationChannel(), recordKey(operation, operationID))
+	return err
+}
+
+// encodeRecord splits a record into a JSON encoded set of values and the raw result data, which is stored in a
+// separate binary column to avoid encoding overhead.
+func encodeRecord(record *nexus.OperationRecord) (string, []byte, error) {
+	r := *record
+	var data []byte
+	if record.Result != nil {
+		data = record.Result.Data
+		r.Result = &nexus.Content{Header: record.Result.Header}
+	}
+	values, err := json.Marshal(r)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(values), data, nil
+}
+
+func decodeRecord(values string, data []byte) (*nexus.OperationRecord, error) {
+	var record nexus.OperationRecord
+	if err := json.Unmarshal([]byte(values), &record); err != nil {
+		return nil, err
+	}
+	if record.Result != nil {
+		record.Result.Data = data
+	}
+	return &record, nil
+}
+
+var _ nexus.OperationStore = &Store{}
+var _ nexus.OperationDeleter = &Store{}
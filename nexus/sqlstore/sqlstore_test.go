@@ -0,0 +1,613 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPlaceholders(t *testing.T) {
+	s := &Store{options: Options{Dialect: PostgreSQL}}
+	require.Equal(t, "SELECT * FROM t WHERE a = $1 AND b = $2", s.query("SELECT * FROM t WHERE a = ? AND b = ?"))
+	s = &Store{options: Options{Dialect: MySQL}}
+	require.Equal(t, "SELECT * FROM t WHERE a = ? AND b = ?", s.query("SELECT * FROM t WHERE a = ? AND b = ?"))
+}
+
+func TestRecordKey(t *testing.T) {
+	require.Equal(t, "foo/id", recordKey("foo", "id"))
+	require.NotEqual(t, recordKey("a/b", "c"), recordKey("a", "b/c"))
+}
+
+func TestRecordEncoding(t *testing.T) {
+	now := time.Now().UTC()
+	record := &nexus.OperationRecord{
+		Operation: "foo",
+		ID:        "id",
+		RequestID: "request-id",
+		State:     nexus.OperationStateSucceeded,
+		Result:    &nexus.Content{Header: nexus.Header{"type": "application/octet-stream"}, Data: []byte{0x00, 0x01}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	values, data, err := encodeRecord(record)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x01}, data)
+	require.NotContains(t, values, "AAE=")
+
+	decoded, err := decodeRecord(values, data)
+	require.NoError(t, err)
+	require.Equal(t, record, decoded)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Options{Dialect: PostgreSQL})
+	require.Error(t, err)
+	_, err = New(Options{DB: &sql.DB{}})
+	require.Error(t, err)
+	_, err = New(Options{DB: &sql.DB{}, Dialect: MySQL, Listener: testListener{}})
+	require.Error(t, err)
+}
+
+type testListener struct{}
+
+func (testListener) Notifications() <-chan string { return nil }
+
+// fakeDB emulates the statements issued by the store in memory, behind a database/sql driver. Transactions are
+// serialized and undo their changes when rolled back.
+type fakeDB struct {
+	// Optional hook invoked before each statement, outside of the lock.
+	beforeStatement func(query string)
+
+	txMu sync.Mutex
+
+	mu            sync.Mutex
+	tables        map[string]map[[2]string]fakeRow
+	migrations    map[string][]int64
+	notifications chan string
+}
+
+type fakeRow struct {
+	requestID, state, record  string
+	version, created, updated int64
+	resultData                []byte
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		tables:        make(map[string]map[[2]string]fakeRow),
+		migrations:    make(map[string][]int64),
+		notifications: make(chan string, 100),
+	}
+}
+
+// open returns a database handle backed by db.
+func (db *fakeDB) open(t *testing.T) *sql.DB {
+	handle := sql.OpenDB(fakeConnector{db})
+	t.Cleanup(func() { handle.Close() })
+	return handle
+}
+
+// Notifications implements Listener, delivering the notifications published by committed transactions.
+func (db *fakeDB) Notifications() <-chan string {
+	return db.notifications
+}
+
+var (
+	fakePlaceholder       = regexp.MustCompile(`\$\d+`)
+	fakeWhitespace        = regexp.MustCompile(`\s+`)
+	fakeCreateMigrations  = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+) \(version BIGINT NOT NULL PRIMARY KEY\)$`)
+	fakeMigrationsVersion = regexp.MustCompile(`^SELECT COALESCE\(MAX\(version\), 0\) FROM (\w+)$`)
+	fakeInsertMigration   = regexp.MustCompile(`^INSERT INTO (\w+) \(version\) VALUES \(\?\)$`)
+	fakeCreateTable       = regexp.MustCompile(`^CREATE TABLE (\w+) \(`)
+	fakeCreateIndex       = regexp.MustCompile(`^CREATE INDEX \w+ ON (\w+) `)
+	fakeInsert            = regexp.MustCompile(`^INSERT INTO (\w+) \(operation, id, request_id, state, version, created_at, updated_at, record, result_data\) VALUES`)
+	fakeCount             = regexp.MustCompile(`^SELECT COUNT\(\*\) FROM (\w+) WHERE operation = \? AND id = \?$`)
+	fakeSelectRecord      = regexp.MustCompile(`^SELECT record, result_data, version FROM (\w+) WHERE operation = \? AND id = \?$`)
+	fakeSelectVersion     = regexp.MustCompile(`^SELECT version FROM (\w+) WHERE operation = \? AND id = \?$`)
+	fakeUpdate            = regexp.MustCompile(`^UPDATE (\w+) SET request_id = \?, state = \?, version = version \+ 1, updated_at = \?, record = \?, result_data = \? WHERE operation = \? AND id = \? AND version = \?$`)
+	fakeDelete            = regexp.MustCompile(`^DELETE FROM (\w+) WHERE operation = \? AND id = \?$`)
+	fakeNotify            = regexp.MustCompile(`^SELECT pg_notify\(\?, \?\)$`)
+)
+
+// execute executes a statement, returning the rows it selects and the number of rows it changes. Changes made in a
+// transaction are undone when it is rolled back, and its notifications published when it is committed.
+func (db *fakeDB) execute(tx *fakeTx, query string, args []driver.NamedValue) (*fakeRows, int64, error) {
+	if db.beforeStatement != nil {
+		db.beforeStatement(query)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	query = strings.TrimSpace(fakeWhitespace.ReplaceAllString(fakePlaceholder.ReplaceAllString(query, "?"), " "))
+	arg := func(i int) driver.Value { return args[i].Value }
+	table := func(name string) (map[[2]string]fakeRow, error) {
+		rows, ok := db.tables[name]
+		if !ok {
+			return nil, fmt.Errorf("no such table: %s", name)
+		}
+		return rows, nil
+	}
+	key := func(i int) [2]string { return [2]string{arg(i).(string), arg(i + 1).(string)} }
+	undo := func(f func()) {
+		if tx != nil {
+			tx.undo = append(tx.undo, f)
+		}
+	}
+	// set sets or deletes a row, recording how to undo it.
+	set := func(rows map[[2]string]fakeRow, k [2]string, row *fakeRow) {
+		old, existed := rows[k]
+		undo(func() {
+			if existed {
+				rows[k] = old
+			} else {
+				delete(rows, k)
+			}
+		})
+		if row != nil {
+			rows[k] = *row
+		} else {
+			delete(rows, k)
+		}
+	}
+
+	if m := fakeCreateMigrations.FindStringSubmatch(query); m != nil {
+		if _, ok := db.migrations[m[1]]; !ok {
+			db.migrations[m[1]] = []int64{}
+			undo(func() { delete(db.migrations, m[1]) })
+		}
+		return nil, 0, nil
+	}
+	if m := fakeMigrationsVersion.FindStringSubmatch(query); m != nil {
+		var max int64
+		for _, v := range db.migrations[m[1]] {
+			if v > max {
+				max = v
+			}
+		}
+		return &fakeRows{columns: []string{"version"}, values: [][]driver.Value{{max}}}, 0, nil
+	}
+	if m := fakeInsertMigration.FindStringSubmatch(query); m != nil {
+		versions := db.migrations[m[1]]
+		db.migrations[m[1]] = append(versions, arg(0).(int64))
+		undo(func() { db.migrations[m[1]] = versions })
+		return nil, 1, nil
+	}
+	if m := fakeCreateTable.FindStringSubmatch(query); m != nil {
+		if _, ok := db.tables[m[1]]; ok {
+			return nil, 0, fmt.Errorf("table %s already exists", m[1])
+		}
+		db.tables[m[1]] = make(map[[2]string]fakeRow)
+		undo(func() { delete(db.tables, m[1]) })
+		return nil, 0, nil
+	}
+	if m := fakeCreateIndex.FindStringSubmatch(query); m != nil {
+		_, err := table(m[1])
+		return nil, 0, err
+	}
+	if m := fakeInsert.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, ok := rows[key(0)]; ok {
+			return nil, 0, errors.New("UNIQUE constraint failed")
+		}
+		row := fakeRow{
+			requestID: arg(2).(string),
+			state:     arg(3).(string),
+			version:   arg(4).(int64),
+			created:   arg(5).(int64),
+			updated:   arg(6).(int64),
+			record:    arg(7).(string),
+		}
+		row.resultData, _ = arg(8).([]byte)
+		set(rows, key(0), &row)
+		return nil, 1, nil
+	}
+	if m := fakeCount.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		_, ok := rows[key(0)]
+		count := int64(0)
+		if ok {
+			count = 1
+		}
+		return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{count}}}, 0, nil
+	}
+	if m := fakeSelectRecord.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		result := &fakeRows{columns: []string{"record", "result_data", "version"}}
+		if row, ok := rows[key(0)]; ok {
+			result.values = append(result.values, []driver.Value{row.record, row.resultData, row.version})
+		}
+		return result, 0, nil
+	}
+	if m := fakeSelectVersion.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		result := &fakeRows{columns: []string{"version"}}
+		if row, ok := rows[key(0)]; ok {
+			result.values = append(result.values, []driver.Value{row.version})
+		}
+		return result, 0, nil
+	}
+	if m := fakeUpdate.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		row, ok := rows[key(5)]
+		if !ok || row.version != arg(7).(int64) {
+			return nil, 0, nil
+		}
+		row.requestID, row.state, row.updated, row.record = arg(0).(string), arg(1).(string), arg(2).(int64), arg(3).(string)
+		row.resultData, _ = arg(4).([]byte)
+		row.version++
+		set(rows, key(5), &row)
+		return nil, 1, nil
+	}
+	if m := fakeDelete.FindStringSubmatch(query); m != nil {
+		rows, err := table(m[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, ok := rows[key(0)]; !ok {
+			return nil, 0, nil
+		}
+		set(rows, key(0), nil)
+		return nil, 1, nil
+	}
+	if fakeNotify.MatchString(query) {
+		if tx != nil {
+			tx.notifications = append(tx.notifications, arg(1).(string))
+		} else {
+			db.notifications <- arg(1).(string)
+		}
+		return &fakeRows{columns: []string{"pg_notify"}, values: [][]driver.Value{{""}}}, 0, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported statement: %s", query)
+}
+
+type fakeConnector struct {
+	db *fakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("use fakeConnector")
+}
+
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, affected, err := c.db.execute(c.tx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, _, err := c.db.execute(c.tx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = &fakeRows{}
+	}
+	return rows, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.txMu.Lock()
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+type fakeTx struct {
+	conn          *fakeConn
+	undo          []func()
+	notifications []string
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.conn.tx = nil
+	tx.conn.db.txMu.Unlock()
+	for _, key := range tx.notifications {
+		tx.conn.db.notifications <- key
+	}
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	db.mu.Unlock()
+	tx.conn.tx = nil
+	db.txMu.Unlock()
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTestStore(t *testing.T, db *fakeDB, options Options) *Store {
+	options.DB = db.open(t)
+	if options.Dialect.Placeholder == nil {
+		options.Dialect = SQLite
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = 10 * time.Millisecond
+	}
+	store, err := New(options)
+	require.NoError(t, err)
+	require.NoError(t, store.Migrate(context.Background()))
+	return store
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB()
+	store, err := New(Options{DB: db.open(t), Dialect: PostgreSQL, TableName: "ops"})
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "foo", "id")
+	require.ErrorContains(t, err, "no such table: ops")
+
+	require.NoError(t, store.Migrate(ctx))
+	require.Contains(t, db.tables, "ops")
+	require.Equal(t, []int64{1}, db.migrations["ops_migrations"])
+	// Applied migrations are skipped.
+	require.NoError(t, store.Migrate(ctx))
+	require.Equal(t, []int64{1}, db.migrations["ops_migrations"])
+
+	_, err = store.Get(ctx, "foo", "id")
+	require.ErrorIs(t, err, nexus.ErrOperationRecordNotFound)
+}
+
+func TestMigrate_Failure(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB()
+	db.tables["nexus_operations"] = make(map[[2]string]fakeRow)
+	store, err := New(Options{DB: db.open(t), Dialect: SQLite})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, store.Migrate(ctx), "failed to apply migration 1: table nexus_operations already exists")
+	require.Empty(t, db.migrations["nexus_operations_migrations"])
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, newFakeDB(), Options{})
+
+	_, err := store.Get(ctx, "foo", "id")
+	require.ErrorIs(t, err, nexus.ErrOperationRecordNotFound)
+
+	now := time.Now().UTC()
+	record := &nexus.OperationRecord{
+		Operation: "foo",
+		ID:        "id",
+		RequestID: "request-id",
+		State:     nexus.OperationStateRunning,
+		Tags:      map[string]string{"tenant": "a"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, store.Create(ctx, record))
+	require.Equal(t, int64(1), record.Version)
+	require.ErrorIs(t, store.Create(ctx, record), nexus.ErrOperationRecordExists)
+
+	stored, err := store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, record, stored)
+
+	record.State = nexus.OperationStateSucceeded
+	record.Result = &nexus.Content{Header: nexus.Header{"type": "application/octet-stream"}, Data: []byte{0x00, 0x01}}
+	record.UpdatedAt = now.Add(time.Second)
+	require.NoError(t, store.Update(ctx, record))
+	require.Equal(t, int64(2), record.Version)
+
+	stored, err = store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, record, stored)
+
+	// Updates of stale versions conflict and leave the record unchanged.
+	stale := *stored
+	stale.Version = 1
+	stale.State = nexus.OperationStateFailed
+	require.ErrorIs(t, store.Update(ctx, &stale), nexus.ErrOperationRecordVersionConflict)
+	require.Equal(t, int64(1), stale.Version)
+	stored, err = store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateSucceeded, stored.State)
+	require.Equal(t, int64(2), stored.Version)
+
+	missing := &nexus.OperationRecord{Operation: "foo", ID: "missing", Version: 1}
+	require.ErrorIs(t, store.Update(ctx, missing), nexus.ErrOperationRecordNotFound)
+
+	require.NoError(t, store.Delete(ctx, "foo", "id"))
+	_, err = store.Get(ctx, "foo", "id")
+	require.ErrorIs(t, err, nexus.ErrOperationRecordNotFound)
+	require.NoError(t, store.Delete(ctx, "foo", "id"))
+	// Deleted records may be created again.
+	record.Version = 0
+	require.NoError(t, store.Create(ctx, record))
+	require.Equal(t, int64(1), record.Version)
+}
+
+func TestStore_CreateRace(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB()
+	store := newTestStore(t, db, Options{})
+	other := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateRunning}
+
+	// Another writer creates the record between the existence check and the insert.
+	var once sync.Once
+	db.beforeStatement = func(query string) {
+		if strings.HasPrefix(query, "INSERT") {
+			once.Do(func() {
+				values, data, err := encodeRecord(other)
+				require.NoError(t, err)
+				db.mu.Lock()
+				defer db.mu.Unlock()
+				db.tables["nexus_operations"][[2]string{"foo", "id"}] = fakeRow{state: string(other.State), version: 1, record: values, resultData: data}
+			})
+		}
+	}
+	record := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateSucceeded}
+	require.ErrorIs(t, store.Create(ctx, record), nexus.ErrOperationRecordExists)
+	require.Equal(t, int64(0), record.Version)
+
+	stored, err := store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateRunning, stored.State)
+}
+
+func TestStore_SingleConnection(t *testing.T) {
+	// Existence checks share the connection of the transaction they belong to, so stores work with a single connection.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	db := newFakeDB()
+	store := newTestStore(t, db, Options{})
+	store.options.DB.SetMaxOpenConns(1)
+
+	record := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+	require.ErrorIs(t, store.Create(ctx, &nexus.OperationRecord{Operation: "foo", ID: "id"}), nexus.ErrOperationRecordExists)
+	require.ErrorIs(t, store.Update(ctx, &nexus.OperationRecord{Operation: "foo", ID: "id", Version: 2}), nexus.ErrOperationRecordVersionConflict)
+	require.ErrorIs(t, store.Update(ctx, &nexus.OperationRecord{Operation: "foo", ID: "missing", Version: 1}), nexus.ErrOperationRecordNotFound)
+
+	// Another writer creates the record between the existence check and the insert.
+	db.beforeStatement = func(query string) {
+		if strings.HasPrefix(query, "INSERT") {
+			db.mu.Lock()
+			defer db.mu.Unlock()
+			db.tables["nexus_operations"][[2]string{"foo", "raced"}] = fakeRow{state: string(nexus.OperationStateRunning), version: 1}
+		}
+	}
+	require.ErrorIs(t, store.Create(ctx, &nexus.OperationRecord{Operation: "foo", ID: "raced"}), nexus.ErrOperationRecordExists)
+}
+
+func TestStore_WaitForUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, newFakeDB(), Options{})
+
+	record := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+	// Versions older than the current one return immediately.
+	require.NoError(t, store.WaitForUpdate(ctx, "foo", "id", 0))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- store.WaitForUpdate(ctx, "foo", "id", 1)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("returned before the record was updated: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	record.State = nexus.OperationStateSucceeded
+	require.NoError(t, store.Update(ctx, record))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not woken up by update")
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, store.WaitForUpdate(timeoutCtx, "foo", "id", 2), context.DeadlineExceeded)
+}
+
+func TestStore_Listener(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB()
+	// Only notifications wake up waiters within the test's timeout.
+	store := newTestStore(t, db, Options{Dialect: PostgreSQL, Listener: db, PollInterval: time.Hour})
+	t.Cleanup(func() { close(db.notifications) })
+
+	record := &nexus.OperationRecord{Operation: "foo", ID: "id", State: nexus.OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- store.WaitForUpdate(ctx, "foo", "id", 1)
+	}()
+	// Conflicting updates are rolled back without publishing a notification.
+	stale := *record
+	stale.Version = 0
+	require.ErrorIs(t, store.Update(ctx, &stale), nexus.ErrOperationRecordVersionConflict)
+	select {
+	case err := <-done:
+		t.Fatalf("returned before the record was updated: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, store.Update(ctx, record))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not woken up by notification")
+	}
+}
@@ -0,0 +1,126 @@
+// Package sqscompletion delivers operation completions over Amazon SQS, for callback receivers that consume a queue
+// instead of exposing an HTTP endpoint.
+//
+// The package does not depend on the AWS SDK. Adapt your SQS client of choice to the minimal [Client] interface.
+//
+// Completions are sent as JSON encoded [nexus.CompletionMessage] bodies rather than message attributes, which are
+// limited to 10 per message. Messages are subject to the queue's maximum message size, large results should be
+// delivered over HTTP or referenced from the result instead.
+package sqscompletion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// Scheme is the callback URL scheme to register a [Transport] for in [nexus.CallbackDeliveryOptions.Transports].
+const Scheme = "sqs"
+
+// Message is a message to send to an SQS queue.
+type Message struct {
+	// URL of the queue to send the message to.
+	QueueURL string
+	// Body of the message.
+	Body string
+	// Message group ID for FIFO queues, the ID of the completed operation, or the request ID of the completion if the
+	// operation ID is unknown. Must be ignored for standard queues.
+	GroupID string
+	// Deduplication ID for FIFO queues, the request ID of the completion, which is identical for retried deliveries.
+	// Must be ignored for standard queues.
+	DeduplicationID string
+}
+
+// Client is the subset of SQS functionality required by this package.
+type Client interface {
+	// SendMessage sends a message to a queue, returning once the message was accepted by SQS.
+	SendMessage(ctx context.Context, message Message) error
+}
+
+// Options are options for [New].
+type Options struct {
+	// SQS client. Required.
+	Client Client
+	// Optional function returning the URL of the queue to deliver the completion for a callback to.
+	// Defaults to the callback URL with its scheme replaced with https, e.g. the queue for the callback
+	// "sqs://sqs.us-east-1.amazonaws.com/123456789012/completions" is
+	// "https://sqs.us-east-1.amazonaws.com/123456789012/completions".
+	QueueURL func(callback nexus.Callback) (string, error)
+}
+
+// Transport is a [nexus.CompletionTransport] sending completions to SQS queues.
+type Transport struct {
+	options Options
+}
+
+// New creates a new [Transport] from the given options.
+func New(options Options) (*Transport, error) {
+	if options.Client == nil {
+		return nil, errors.New("client is required")
+	}
+	if options.QueueURL == nil {
+		options.QueueURL = defaultQueueURL
+	}
+	return &Transport{options: options}, nil
+}
+
+func defaultQueueURL(callback nexus.Callback) (string, error) {
+	u, err := url.Parse(callback.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("callback URL has no host: %q", callback.URL)
+	}
+	u.Scheme = "https"
+	return u.String(), nil
+}
+
+// DeliverCompletion implements [nexus.CompletionTransport].
+func (t *Transport) DeliverCompletion(ctx context.Context, callback nexus.Callback, message *nexus.CompletionMessage) error {
+	queueURL, err := t.options.QueueURL(callback)
+	if err != nil {
+		return err
+	}
+	body, err := Encode(message)
+	if err != nil {
+		return err
+	}
+	requestID := message.Get(nexus.HeaderRequestID)
+	groupID := message.Get(nexus.HeaderOperationID)
+	if groupID == "" {
+		groupID = requestID
+	}
+	return t.options.Client.SendMessage(ctx, Message{
+		QueueURL:        queueURL,
+		Body:            body,
+		GroupID:         groupID,
+		DeduplicationID: requestID,
+	})
+}
+
+// Encode encodes a completion message as the body of an SQS message.
+func Encode(message *nexus.CompletionMessage) (string, error) {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Decode decodes the body of an SQS message sent by a [Transport], for handling it with a
+// [nexus.CompletionMessageHandler].
+func Decode(body string) (*nexus.CompletionMessage, error) {
+	var message nexus.CompletionMessage
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return nil, fmt.Errorf("invalid completion message: %w", err)
+	}
+	if message.Header == nil {
+		return nil, errors.New("invalid completion message: missing header")
+	}
+	return &message, nil
+}
@@ -0,0 +1,104 @@
+package sqscompletion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient records sent messages, failing the first failures sends.
+type fakeClient struct {
+	mu       sync.Mutex
+	failures int
+	messages []Message
+	sent     chan struct{}
+}
+
+func (c *fakeClient) SendMessage(ctx context.Context, message Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("throttled")
+	}
+	c.messages = append(c.messages, message)
+	c.sent <- struct{}{}
+	return nil
+}
+
+func TestTransport(t *testing.T) {
+	client := &fakeClient{failures: 1, sent: make(chan struct{}, 1)}
+	transport, err := New(Options{Client: client})
+	require.NoError(t, err)
+	handler, err := nexus.NewAsyncHandler(nexus.AsyncHandlerOptions{
+		Store: nexus.NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (any, error) {
+			return []byte{0, 1, 2}, nil
+		},
+		CallbackDelivery: &nexus.CallbackDeliveryOptions{
+			RetryPolicy: nexus.RetryPolicy{InitialInterval: time.Millisecond},
+			Transports:  map[string]nexus.CompletionTransport{Scheme: transport},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	result, err := handler.StartOperation(ctx, "foo", &nexus.LazyValue{Reader: nexus.NewReader(strings.NewReader(""), nil)}, nexus.StartOperationOptions{
+		CallbackURL: "sqs://sqs.us-east-1.amazonaws.com/123456789012/completions.fifo",
+	})
+	require.NoError(t, err)
+	operationID := result.(*nexus.HandlerStartOperationResultAsync).OperationID
+
+	select {
+	case <-client.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("completion not sent")
+	}
+	message := client.messages[0]
+	require.Equal(t, "https://sqs.us-east-1.amazonaws.com/123456789012/completions.fifo", message.QueueURL)
+	require.Equal(t, operationID, message.GroupID)
+	require.NotEmpty(t, message.DeduplicationID)
+
+	completion, err := Decode(message.Body)
+	require.NoError(t, err)
+	require.Equal(t, message.DeduplicationID, completion.Get(nexus.HeaderRequestID))
+
+	var output []byte
+	messageHandler := nexus.NewCompletionMessageHandler(nexus.CompletionHandlerOptions{
+		Handler: completionHandlerFunc(func(ctx context.Context, completion *nexus.CompletionRequest) error {
+			require.Equal(t, operationID, completion.OperationID)
+			return completion.Result.Consume(&output)
+		}),
+	})
+	require.NoError(t, messageHandler.HandleMessage(ctx, completion))
+	require.Equal(t, []byte{0, 1, 2}, output)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode("not json")
+	require.ErrorContains(t, err, "invalid completion message")
+	_, err = Decode("{}")
+	require.ErrorContains(t, err, "missing header")
+}
+
+func TestNew_InvalidCallbackURL(t *testing.T) {
+	_, err := New(Options{})
+	require.Error(t, err)
+
+	transport, err := New(Options{Client: &fakeClient{}})
+	require.NoError(t, err)
+	err = transport.DeliverCompletion(context.Background(), nexus.Callback{URL: "sqs:///queue"}, &nexus.CompletionMessage{})
+	require.ErrorContains(t, err, "no host")
+}
+
+type completionHandlerFunc func(ctx context.Context, completion *nexus.CompletionRequest) error
+
+func (f completionHandlerFunc) CompleteOperation(ctx context.Context, completion *nexus.CompletionRequest) error {
+	return f(ctx, completion)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -286,7 +287,7 @@ func TestStart_RequestTimeoutHeaderOverridesContextDeadline(t *testing.T) {
 	defer teardown()
 
 	timeout := 100 * time.Millisecond
-	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Header: Header{headerRequestTimeout: timeout.String()}})
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{Header: Header{HeaderRequestTimeout: timeout.String()}})
 
 	require.NoError(t, err)
 	requireTimeoutPropagated(t, result, timeout)
@@ -318,3 +319,57 @@ func TestStart_TimeoutNotPropagated(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("not set"), responseBody)
 }
+
+// inputHeaderHandler responds to start requests with the content header and data of their input.
+type inputHeaderHandler struct {
+	UnimplementedHandler
+}
+
+func (h *inputHeaderHandler) StartOperation(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	data, err := io.ReadAll(input.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{Value: map[string]string{
+		"type":   input.Reader.Header["type"],
+		"length": input.Reader.Header["length"],
+		"data":   string(data),
+	}}, nil
+}
+
+func TestStreamingInput(t *testing.T) {
+	ctx, client, teardown := setup(t, &inputHeaderHandler{})
+	defer teardown()
+
+	testCases := []struct {
+		name     string
+		input    any
+		expected map[string]string
+	}{
+		{
+			name:     "in-memory reader",
+			input:    strings.NewReader("abc"),
+			expected: map[string]string{"type": "application/octet-stream", "length": "3", "data": "abc"},
+		},
+		{
+			name:     "chunked",
+			input:    io.MultiReader(strings.NewReader("ab"), strings.NewReader("c")),
+			expected: map[string]string{"type": "application/octet-stream", "length": "", "data": "abc"},
+		},
+		{
+			name:     "explicit header",
+			input:    NewReader(io.MultiReader(strings.NewReader("abc")), Header{"type": "text/plain", "length": "3"}),
+			expected: map[string]string{"type": "text/plain", "length": "3", "data": "abc"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := client.StartOperation(ctx, "foo", tc.input, StartOperationOptions{})
+			require.NoError(t, err)
+			var output map[string]string
+			require.NoError(t, result.Successful.Consume(&output))
+			require.Equal(t, tc.expected, output)
+		})
+	}
+}
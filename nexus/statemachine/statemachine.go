@@ -0,0 +1,205 @@
+// Package statemachine implements the lifecycle of asynchronous operations, running to succeeded, failed or canceled,
+// on top of a [nexus.OperationStore].
+//
+// It is meant for handler authors implementing [nexus.Handler] directly instead of using [nexus.AsyncHandler], who
+// still want consistent state tracking: transitions are validated and persisted with optimistic concurrency control,
+// can be guarded and observed via hooks, and operation info and results are derived from the persisted records.
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// ErrInvalidTransition is returned when transitioning an operation to a state not reachable from its current state,
+// e.g. completing an operation that already completed.
+var ErrInvalidTransition = errors.New("invalid operation state transition")
+
+// ValidTransition reports whether an operation may transition from one state to another. Running operations may
+// transition to any of the terminal states succeeded, failed, and canceled. Terminal states are final.
+func ValidTransition(from, to nexus.OperationState) bool {
+	if from != nexus.OperationStateRunning {
+		return false
+	}
+	switch to {
+	case nexus.OperationStateSucceeded, nexus.OperationStateFailed, nexus.OperationStateCanceled:
+		return true
+	}
+	return false
+}
+
+// Transition describes a state transition of an operation.
+type Transition struct {
+	// State of the operation before the transition.
+	From nexus.OperationState
+	// State of the operation after the transition.
+	To nexus.OperationState
+	// Record of the operation with the transition applied. Must not be modified.
+	Record *nexus.OperationRecord
+}
+
+// Options are options for [New].
+type Options struct {
+	// Store persisting operation records. Required.
+	Store nexus.OperationStore
+	// Optional guard invoked before a transition is persisted. Returning an error rejects the transition, the error is
+	// returned to the caller. Invoked again if the transition is retried after a concurrent update.
+	Guard func(ctx context.Context, transition Transition) error
+	// Optional hook invoked after a transition has been persisted, e.g. to deliver completion callbacks.
+	OnTransition func(ctx context.Context, transition Transition)
+}
+
+// Machine manages the state of operations persisted in a [nexus.OperationStore].
+//
+// Safe for concurrent use.
+type Machine struct {
+	options Options
+}
+
+// New creates a [Machine] from the given options.
+func New(options Options) (*Machine, error) {
+	if options.Store == nil {
+		return nil, errors.New("store is required")
+	}
+	return &Machine{options: options}, nil
+}
+
+// Start creates a running operation with the given name and ID, recording the request ID, tags, deadline and start
+// time from the start options. Returns [nexus.ErrOperationRecordExists] if the operation was already started.
+func (m *Machine) Start(ctx context.Context, operation, operationID string, options nexus.StartOperationOptions) (*nexus.OperationRecord, error) {
+	now := time.Now()
+	record := &nexus.OperationRecord{
+		Operation: operation,
+		ID:        operationID,
+		RequestID: options.RequestID,
+		Tags:      options.Tags,
+		State:     nexus.OperationStateRunning,
+		Deadline:  options.Deadline,
+		StartTime: options.StartTime,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.options.Store.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Succeed completes a running operation successfully with the given result, which may be nil.
+func (m *Machine) Succeed(ctx context.Context, operation, operationID string, result *nexus.Content) error {
+	return m.transition(ctx, operation, operationID, nexus.OperationStateSucceeded, func(record *nexus.OperationRecord) {
+		record.Result = result
+		if result != nil {
+			record.ResultSize = int64(len(result.Data))
+		}
+	})
+}
+
+// Fail completes a running operation as failed.
+func (m *Machine) Fail(ctx context.Context, operation, operationID string, failure nexus.Failure) error {
+	return m.transition(ctx, operation, operationID, nexus.OperationStateFailed, func(record *nexus.OperationRecord) {
+		record.Failure = &failure
+	})
+}
+
+// Cancel completes a running operation as canceled.
+func (m *Machine) Cancel(ctx context.Context, operation, operationID string, failure nexus.Failure) error {
+	return m.transition(ctx, operation, operationID, nexus.OperationStateCanceled, func(record *nexus.OperationRecord) {
+		record.Failure = &failure
+	})
+}
+
+// Info returns the [nexus.OperationInfo] of an operation, suitable for returning from
+// [nexus.Handler.GetOperationInfo]. Returns a not found [nexus.HandlerError] if the operation does not exist.
+func (m *Machine) Info(ctx context.Context, operation, operationID string) (*nexus.OperationInfo, error) {
+	record, err := m.get(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	return record.Info(), nil
+}
+
+// Result returns the outcome of an operation, suitable for returning from [nexus.Handler.GetOperationResult]: the
+// result content of succeeded operations, an [nexus.UnsuccessfulOperationError] for failed and canceled operations,
+// and [nexus.ErrOperationStillRunning] for operations still running after waiting up to the given duration.
+func (m *Machine) Result(ctx context.Context, operation, operationID string, wait time.Duration) (any, error) {
+	record, err := m.get(ctx, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if record.State == nexus.OperationStateRunning && wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		for record.State == nexus.OperationStateRunning {
+			if err := m.options.Store.WaitForUpdate(waitCtx, operation, operationID, record.Version); err != nil {
+				if waitCtx.Err() != nil {
+					return nil, nexus.ErrOperationStillRunning
+				}
+				return nil, err
+			}
+			if record, err = m.get(ctx, operation, operationID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	switch record.State {
+	case nexus.OperationStateRunning:
+		return nil, nexus.ErrOperationStillRunning
+	case nexus.OperationStateSucceeded:
+		if record.Result == nil {
+			return nil, nil
+		}
+		return record.Result, nil
+	default:
+		var failure nexus.Failure
+		if record.Failure != nil {
+			failure = *record.Failure
+		}
+		return nil, &nexus.UnsuccessfulOperationError{State: record.State, Failure: failure}
+	}
+}
+
+func (m *Machine) get(ctx context.Context, operation, operationID string) (*nexus.OperationRecord, error) {
+	record, err := m.options.Store.Get(ctx, operation, operationID)
+	if errors.Is(err, nexus.ErrOperationRecordNotFound) {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "operation not found")
+	}
+	return record, err
+}
+
+// transition moves an operation to the given state, retrying on concurrent updates.
+func (m *Machine) transition(ctx context.Context, operation, operationID string, to nexus.OperationState, mutate func(*nexus.OperationRecord)) error {
+	for {
+		record, err := m.get(ctx, operation, operationID)
+		if err != nil {
+			return err
+		}
+		from := record.State
+		if !ValidTransition(from, to) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+		}
+		record.State = to
+		record.UpdatedAt = time.Now()
+		mutate(record)
+		transition := Transition{From: from, To: to, Record: record}
+		if m.options.Guard != nil {
+			if err := m.options.Guard(ctx, transition); err != nil {
+				return err
+			}
+		}
+		if err := m.options.Store.Update(ctx, record); err != nil {
+			if errors.Is(err, nexus.ErrOperationRecordVersionConflict) {
+				continue
+			}
+			return err
+		}
+		if m.options.OnTransition != nil {
+			m.options.OnTransition(ctx, transition)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,135 @@
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+)
+
+func newMachine(t *testing.T, options Options) *Machine {
+	options.Store = nexus.NewMemoryOperationStore()
+	m, err := New(options)
+	require.NoError(t, err)
+	return m
+}
+
+func TestNew_RequiresStore(t *testing.T) {
+	_, err := New(Options{})
+	require.Error(t, err)
+}
+
+func TestValidTransition(t *testing.T) {
+	require.True(t, ValidTransition(nexus.OperationStateRunning, nexus.OperationStateSucceeded))
+	require.True(t, ValidTransition(nexus.OperationStateRunning, nexus.OperationStateFailed))
+	require.True(t, ValidTransition(nexus.OperationStateRunning, nexus.OperationStateCanceled))
+	require.False(t, ValidTransition(nexus.OperationStateRunning, nexus.OperationStateRunning))
+	require.False(t, ValidTransition(nexus.OperationStateSucceeded, nexus.OperationStateFailed))
+	require.False(t, ValidTransition(nexus.OperationStateCanceled, nexus.OperationStateRunning))
+}
+
+func TestMachine_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	var transitions []Transition
+	m := newMachine(t, Options{
+		OnTransition: func(ctx context.Context, transition Transition) {
+			transitions = append(transitions, transition)
+		},
+	})
+
+	deadline := time.Now().Add(time.Hour)
+	_, err := m.Start(ctx, "op", "id", nexus.StartOperationOptions{RequestID: "req", Deadline: deadline})
+	require.NoError(t, err)
+	_, err = m.Start(ctx, "op", "id", nexus.StartOperationOptions{})
+	require.ErrorIs(t, err, nexus.ErrOperationRecordExists)
+
+	info, err := m.Info(ctx, "op", "id")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateRunning, info.State)
+	require.True(t, deadline.Equal(*info.Deadline))
+	_, err = m.Result(ctx, "op", "id", 0)
+	require.ErrorIs(t, err, nexus.ErrOperationStillRunning)
+
+	result := &nexus.Content{Header: nexus.Header{"type": "application/json"}, Data: []byte(`"ok"`)}
+	require.NoError(t, m.Succeed(ctx, "op", "id", result))
+	require.Len(t, transitions, 1)
+	require.Equal(t, nexus.OperationStateRunning, transitions[0].From)
+	require.Equal(t, nexus.OperationStateSucceeded, transitions[0].To)
+
+	value, err := m.Result(ctx, "op", "id", 0)
+	require.NoError(t, err)
+	require.Equal(t, result.Data, value.(*nexus.Content).Data)
+
+	err = m.Fail(ctx, "op", "id", nexus.Failure{Message: "too late"})
+	require.ErrorIs(t, err, ErrInvalidTransition)
+	require.Len(t, transitions, 1)
+}
+
+func TestMachine_Unsuccessful(t *testing.T) {
+	ctx := context.Background()
+	m := newMachine(t, Options{})
+	_, err := m.Start(ctx, "op", "failed", nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = m.Start(ctx, "op", "canceled", nexus.StartOperationOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Fail(ctx, "op", "failed", nexus.Failure{Message: "boom"}))
+	require.NoError(t, m.Cancel(ctx, "op", "canceled", nexus.Failure{Message: "canceled"}))
+
+	var unsuccessfulError *nexus.UnsuccessfulOperationError
+	_, err = m.Result(ctx, "op", "failed", 0)
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, nexus.OperationStateFailed, unsuccessfulError.State)
+	require.Equal(t, "boom", unsuccessfulError.Failure.Message)
+	_, err = m.Result(ctx, "op", "canceled", 0)
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, nexus.OperationStateCanceled, unsuccessfulError.State)
+}
+
+func TestMachine_Guard(t *testing.T) {
+	ctx := context.Background()
+	errGuard := errors.New("not allowed")
+	m := newMachine(t, Options{
+		Guard: func(ctx context.Context, transition Transition) error {
+			if transition.To == nexus.OperationStateCanceled {
+				return errGuard
+			}
+			return nil
+		},
+	})
+	_, err := m.Start(ctx, "op", "id", nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	require.ErrorIs(t, m.Cancel(ctx, "op", "id", nexus.Failure{}), errGuard)
+	info, err := m.Info(ctx, "op", "id")
+	require.NoError(t, err)
+	require.Equal(t, nexus.OperationStateRunning, info.State)
+}
+
+func TestMachine_ResultWait(t *testing.T) {
+	ctx := context.Background()
+	m := newMachine(t, Options{})
+	_, err := m.Start(ctx, "op", "id", nexus.StartOperationOptions{})
+	require.NoError(t, err)
+
+	_, err = m.Result(ctx, "op", "id", 10*time.Millisecond)
+	require.ErrorIs(t, err, nexus.ErrOperationStillRunning)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = m.Succeed(ctx, "op", "id", nil)
+	}()
+	value, err := m.Result(ctx, "op", "id", 5*time.Second)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestMachine_NotFound(t *testing.T) {
+	m := newMachine(t, Options{})
+	_, err := m.Info(context.Background(), "op", "missing")
+	var handlerError *nexus.HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, nexus.HandlerErrorTypeNotFound, handlerError.Type)
+}
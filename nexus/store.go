@@ -0,0 +1,308 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An OperationRecord is the persisted state of an asynchronous operation as managed by an [OperationStore].
+type OperationRecord struct {
+	// Name of the operation.
+	Operation string `json:"operation"`
+	// ID of the operation.
+	ID string `json:"id"`
+	// Request ID of the start request that created this operation.
+	RequestID string `json:"requestId,omitempty"`
+	// Tags attached to the operation when it was started.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Metadata surfaced in [OperationInfo.Metadata], in addition to the creation time.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Links surfaced in [OperationInfo.Links]. See [AsyncHandlerOptions.Links].
+	Links []Link `json:"links,omitempty"`
+	// State of the operation.
+	State OperationState `json:"state"`
+	// Result of the operation, set when State is succeeded.
+	// When the result is stored in a [PayloadBackend], only the result header is set.
+	Result *Content `json:"result,omitempty"`
+	// Key of the result in a [PayloadBackend], set when the result is stored outside of the store.
+	ResultPayloadKey string `json:"resultPayloadKey,omitempty"`
+	// Size of the result data in bytes, including results stored in a [PayloadBackend].
+	ResultSize int64 `json:"resultSize,omitempty"`
+	// Failure of the operation, set when State is failed or canceled.
+	Failure *Failure `json:"failure,omitempty"`
+	// Callbacks the operation's completion is delivered to and the state of their delivery.
+	// See [AsyncHandlerOptions.CallbackDelivery].
+	Callbacks []CallbackRecord `json:"callbacks,omitempty"`
+	// Current claims on the operation's outcome keyed by consumer group, the default group being the empty string.
+	// See [Handler.ClaimOperationResult].
+	Claims map[string]*OperationClaimRecord `json:"claims,omitempty"`
+	// Time by which the operation must complete. Zero if the operation has no deadline.
+	Deadline time.Time `json:"deadline"`
+	// Time before which the operation does not begin executing. Zero if the operation was started immediately.
+	StartTime time.Time `json:"startTime"`
+	// Time after which the outcome of the operation is no longer retained. Zero if the operation is running or its
+	// outcome is retained indefinitely. See [AsyncHandlerOptions.Retentions].
+	ExpireTime time.Time `json:"expireTime"`
+	// Time a heartbeat was last recorded for the operation. Zero if no heartbeat was recorded.
+	LastHeartbeat time.Time `json:"lastHeartbeat"`
+	// Details reported with the last heartbeat, if any.
+	HeartbeatDetails json.RawMessage `json:"heartbeatDetails,omitempty"`
+	// Input of the operation, retained for re-executing operations. See [AsyncHandlerOptions.MaxStaleRetries].
+	Input *Content `json:"input,omitempty"`
+	// Number of times execution of the operation was started.
+	Attempt int `json:"attempt,omitempty"`
+	// Failed execution attempts that were retried. See [AsyncHandlerOptions.RetryPolicies].
+	AttemptHistory []OperationAttempt `json:"attemptHistory,omitempty"`
+	// Set while the operation is quarantined. See [AsyncHandlerOptions.QuarantineAfterPanics].
+	Quarantine *OperationQuarantine `json:"quarantine,omitempty"`
+	// Version of the record, incremented by the store on every update and used for optimistic concurrency control.
+	Version int64 `json:"version"`
+	// Time the record was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// Time the record was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OperationClaimRecord is the state of a consumer group's claim on an operation's outcome.
+type OperationClaimRecord struct {
+	// Token of the current claim.
+	Token string `json:"token"`
+	// Time the current claim's lease expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Whether the current claim has been acknowledged.
+	Acked bool `json:"acked,omitempty"`
+}
+
+// Info returns the [OperationInfo] representation of this record.
+func (r *OperationRecord) Info() *OperationInfo {
+	info := &OperationInfo{
+		ID:    r.ID,
+		State: r.State,
+	}
+	if !r.LastHeartbeat.IsZero() {
+		t := r.LastHeartbeat
+		info.LastHeartbeatTime = &t
+	}
+	if !r.Deadline.IsZero() {
+		t := r.Deadline
+		info.Deadline = &t
+	}
+	if !r.StartTime.IsZero() {
+		t := r.StartTime
+		info.StartTime = &t
+	}
+	if !r.ExpireTime.IsZero() {
+		t := r.ExpireTime
+		info.ExpireTime = &t
+	}
+	info.HeartbeatDetails = r.HeartbeatDetails
+	info.AttemptHistory = r.AttemptHistory
+	info.Quarantine = r.Quarantine
+	info.Links = r.Links
+	if len(r.Metadata) > 0 || !r.CreatedAt.IsZero() {
+		info.Metadata = maps.Clone(r.Metadata)
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string, 1)
+		}
+		if !r.CreatedAt.IsZero() {
+			info.Metadata[OperationMetadataCreatedAt] = r.CreatedAt.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return info
+}
+
+// Clone returns a deep copy of this record.
+func (r *OperationRecord) Clone() *OperationRecord {
+	c := *r
+	c.Tags = maps.Clone(r.Tags)
+	c.Metadata = maps.Clone(r.Metadata)
+	c.Links = slices.Clone(r.Links)
+	if r.HeartbeatDetails != nil {
+		c.HeartbeatDetails = append(json.RawMessage(nil), r.HeartbeatDetails...)
+	}
+	if r.AttemptHistory != nil {
+		c.AttemptHistory = make([]OperationAttempt, len(r.AttemptHistory))
+		for i, attempt := range r.AttemptHistory {
+			attempt.Failure.Metadata = maps.Clone(attempt.Failure.Metadata)
+			c.AttemptHistory[i] = attempt
+		}
+	}
+	if r.Quarantine != nil {
+		q := *r.Quarantine
+		q.Failure.Metadata = maps.Clone(r.Quarantine.Failure.Metadata)
+		c.Quarantine = &q
+	}
+	if r.Input != nil {
+		c.Input = &Content{
+			Header: maps.Clone(r.Input.Header),
+			Data:   append([]byte(nil), r.Input.Data...),
+		}
+	}
+	if r.Result != nil {
+		c.Result = &Content{
+			Header: maps.Clone(r.Result.Header),
+			Data:   append([]byte(nil), r.Result.Data...),
+		}
+	}
+	if r.Failure != nil {
+		f := *r.Failure
+		f.Metadata = maps.Clone(r.Failure.Metadata)
+		c.Failure = &f
+	}
+	c.Callbacks = cloneCallbackRecords(r.Callbacks)
+	if r.Claims != nil {
+		c.Claims = make(map[string]*OperationClaimRecord, len(r.Claims))
+		for group, claim := range r.Claims {
+			cc := *claim
+			c.Claims[group] = &cc
+		}
+	}
+	return &c
+}
+
+// ErrOperationRecordNotFound is returned from [OperationStore] methods when a record does not exist.
+var ErrOperationRecordNotFound = errors.New("operation record not found")
+
+// ErrOperationRecordExists is returned from [OperationStore.Create] when a record with the same operation name and ID
+// already exists.
+var ErrOperationRecordExists = errors.New("operation record already exists")
+
+// ErrOperationRecordVersionConflict is returned from [OperationStore.Update] when the record was concurrently modified.
+var ErrOperationRecordVersionConflict = errors.New("operation record version conflict")
+
+// An OperationStore persists the state of asynchronous operations.
+//
+// Records are keyed by operation name and ID. State transitions are guarded by optimistic concurrency control: every
+// update must be based on the latest version of a record.
+//
+// Implementations must be safe for concurrent use.
+type OperationStore interface {
+	// Create persists a new record, setting its version to 1.
+	// Returns [ErrOperationRecordExists] if a record with the same operation name and ID already exists.
+	Create(ctx context.Context, record *OperationRecord) error
+	// Get returns the record for the given operation name and ID.
+	// Returns [ErrOperationRecordNotFound] if the record does not exist.
+	Get(ctx context.Context, operation, operationID string) (*OperationRecord, error)
+	// Update replaces a record if its version matches the stored version, incrementing the version on success.
+	// Returns [ErrOperationRecordVersionConflict] if the stored version does not match and [ErrOperationRecordNotFound]
+	// if the record does not exist.
+	Update(ctx context.Context, record *OperationRecord) error
+	// WaitForUpdate blocks until the version of the given record exceeds the provided version or the context is done,
+	// in which case the context error is returned. Used to wake up long polls.
+	WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error
+}
+
+type memoryStoreKey struct {
+	operation   string
+	operationID string
+}
+
+// MemoryOperationStore is an in-memory [OperationStore], useful for testing and for services that do not need to
+// retain operation state across restarts.
+type MemoryOperationStore struct {
+	mu      sync.Mutex
+	records map[memoryStoreKey]*OperationRecord
+	// Closed and replaced on every update to wake up waiters.
+	updated map[memoryStoreKey]chan struct{}
+}
+
+// NewMemoryOperationStore creates an empty [MemoryOperationStore].
+func NewMemoryOperationStore() *MemoryOperationStore {
+	return &MemoryOperationStore{
+		records: make(map[memoryStoreKey]*OperationRecord),
+		updated: make(map[memoryStoreKey]chan struct{}),
+	}
+}
+
+// Create implements OperationStore.
+func (s *MemoryOperationStore) Create(ctx context.Context, record *OperationRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryStoreKey{record.Operation, record.ID}
+	if _, ok := s.records[key]; ok {
+		return ErrOperationRecordExists
+	}
+	record.Version = 1
+	s.records[key] = record.Clone()
+	s.notifyLocked(key)
+	return nil
+}
+
+// Get implements OperationStore.
+func (s *MemoryOperationStore) Get(ctx context.Context, operation, operationID string) (*OperationRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[memoryStoreKey{operation, operationID}]
+	if !ok {
+		return nil, ErrOperationRecordNotFound
+	}
+	return record.Clone(), nil
+}
+
+// Update implements OperationStore.
+func (s *MemoryOperationStore) Update(ctx context.Context, record *OperationRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryStoreKey{record.Operation, record.ID}
+	existing, ok := s.records[key]
+	if !ok {
+		return ErrOperationRecordNotFound
+	}
+	if existing.Version != record.Version {
+		return ErrOperationRecordVersionConflict
+	}
+	record.Version++
+	s.records[key] = record.Clone()
+	s.notifyLocked(key)
+	return nil
+}
+
+// WaitForUpdate implements OperationStore.
+func (s *MemoryOperationStore) WaitForUpdate(ctx context.Context, operation, operationID string, version int64) error {
+	key := memoryStoreKey{operation, operationID}
+	for {
+		s.mu.Lock()
+		if record, ok := s.records[key]; ok && record.Version > version {
+			s.mu.Unlock()
+			return nil
+		}
+		ch, ok := s.updated[key]
+		if !ok {
+			ch = make(chan struct{})
+			s.updated[key] = ch
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *MemoryOperationStore) notifyLocked(key memoryStoreKey) {
+	if ch, ok := s.updated[key]; ok {
+		close(ch)
+		delete(s.updated, key)
+	}
+}
+
+var _ OperationStore = &MemoryOperationStore{}
+
+// joinKey joins the parts of a key with "/", escaping each part so that different parts always produce different
+// keys, e.g. ("a/b", "c") and ("a", "b/c"). Parts without reserved characters, such as UUIDs, are unchanged.
+func joinKey(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = url.PathEscape(part)
+	}
+	return strings.Join(escaped, "/")
+}
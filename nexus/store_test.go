@@ -0,0 +1,62 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryOperationStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOperationStore()
+
+	_, err := store.Get(ctx, "foo", "id")
+	require.ErrorIs(t, err, ErrOperationRecordNotFound)
+
+	record := &OperationRecord{Operation: "foo", ID: "id", State: OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+	require.Equal(t, int64(1), record.Version)
+	require.ErrorIs(t, store.Create(ctx, &OperationRecord{Operation: "foo", ID: "id"}), ErrOperationRecordExists)
+
+	stale, err := store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+
+	record.State = OperationStateSucceeded
+	record.Result = &Content{Header: Header{"type": "application/json"}, Data: []byte("1")}
+	require.NoError(t, store.Update(ctx, record))
+	require.Equal(t, int64(2), record.Version)
+
+	stale.State = OperationStateFailed
+	require.ErrorIs(t, store.Update(ctx, stale), ErrOperationRecordVersionConflict)
+
+	stored, err := store.Get(ctx, "foo", "id")
+	require.NoError(t, err)
+	require.Equal(t, OperationStateSucceeded, stored.State)
+	require.Equal(t, []byte("1"), stored.Result.Data)
+
+	require.ErrorIs(t, store.Update(ctx, &OperationRecord{Operation: "foo", ID: "missing"}), ErrOperationRecordNotFound)
+}
+
+func TestMemoryOperationStore_WaitForUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOperationStore()
+	record := &OperationRecord{Operation: "foo", ID: "id", State: OperationStateRunning}
+	require.NoError(t, store.Create(ctx, record))
+
+	// Already updated past the provided version.
+	require.NoError(t, store.WaitForUpdate(ctx, "foo", "id", 0))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, store.WaitForUpdate(timeoutCtx, "foo", "id", 1), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() {
+		done <- store.WaitForUpdate(ctx, "foo", "id", 1)
+	}()
+	record.State = OperationStateSucceeded
+	require.NoError(t, store.Update(ctx, record))
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,304 @@
+package nexus
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A RequestValidationCode identifies why a request was rejected by strict validation, see
+// [HandlerOptions.StrictValidation].
+type RequestValidationCode string
+
+const (
+	// A URL path segment, e.g. the operation name or ID, is empty, not properly escaped, or contains invalid UTF-8 or
+	// control characters.
+	RequestValidationCodeInvalidPath RequestValidationCode = "INVALID_PATH"
+	// A header field has an invalid value.
+	RequestValidationCodeInvalidHeader RequestValidationCode = "INVALID_HEADER"
+	// A single valued header field or query parameter is set more than once.
+	RequestValidationCodeDuplicateField RequestValidationCode = "DUPLICATE_FIELD"
+	// A query parameter has an invalid value, or the query is not properly escaped.
+	RequestValidationCodeInvalidQuery RequestValidationCode = "INVALID_QUERY"
+	// A duration, e.g. the Request-Timeout header or the wait query parameter, is malformed or out of range.
+	RequestValidationCodeInvalidDuration RequestValidationCode = "INVALID_DURATION"
+	// A timestamp, e.g. the operation deadline, is not in RFC 3339 format.
+	RequestValidationCodeInvalidTimestamp RequestValidationCode = "INVALID_TIMESTAMP"
+	// The Content-Type header is malformed, or missing for a request declaring a non-empty body.
+	RequestValidationCodeInvalidContentType RequestValidationCode = "INVALID_CONTENT_TYPE"
+	// An operation state, e.g. of a list filter, is not one of the OperationState constants.
+	RequestValidationCodeInvalidOperationState RequestValidationCode = "INVALID_OPERATION_STATE"
+)
+
+// Keys of the [Failure.Metadata] of 400 responses to requests rejected by strict validation, see
+// [HandlerOptions.StrictValidation].
+const (
+	// The [RequestValidationCode] the request was rejected with.
+	FailureMetadataValidationCode = "validationCode"
+	// The name of the offending header field, query parameter or path segment.
+	FailureMetadataValidationField = "validationField"
+)
+
+// RequestValidationError describes why a request was rejected by strict validation, see
+// [HandlerOptions.StrictValidation]. Handlers respond with a 400 [HandlerError] whose failure carries the code and field
+// in its metadata; clients recover the error via [RequestValidationErrorFromFailure].
+type RequestValidationError struct {
+	// Machine readable reason of the rejection.
+	Code RequestValidationCode
+	// Name of the offending header field, query parameter or path segment.
+	Field string
+	// Human readable description of the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *RequestValidationError) Error() string {
+	return fmt.Sprintf("invalid request (%s): %s", e.Code, e.Message)
+}
+
+// handlerError converts the validation error into the bad request handler error sent to the caller.
+func (e *RequestValidationError) handlerError() *HandlerError {
+	return &HandlerError{
+		Type: HandlerErrorTypeBadRequest,
+		Failure: &Failure{
+			Message: e.Message,
+			Metadata: map[string]string{
+				FailureMetadataValidationCode:  string(e.Code),
+				FailureMetadataValidationField: e.Field,
+			},
+		},
+	}
+}
+
+// RequestValidationErrorFromFailure returns the validation error of a request rejected by strict validation from the
+// failure of its response, e.g. [UnexpectedResponseError.Failure]. Returns false if the failure does not carry a
+// validation code.
+func RequestValidationErrorFromFailure(failure *Failure) (*RequestValidationError, bool) {
+	if failure == nil || failure.Metadata[FailureMetadataValidationCode] == "" {
+		return nil, false
+	}
+	return &RequestValidationError{
+		Code:    RequestValidationCode(failure.Metadata[FailureMetadataValidationCode]),
+		Field:   failure.Metadata[FailureMetadataValidationField],
+		Message: failure.Message,
+	}, true
+}
+
+func validationErrorf(code RequestValidationCode, field string, format string, args ...any) *RequestValidationError {
+	return &RequestValidationError{Code: code, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Header fields that may only be set once per request.
+var singleValuedHeaders = []string{
+	HeaderRequestID,
+	HeaderRequestTimeout,
+	HeaderOperationDeadline,
+	HeaderOperationStartTime,
+	HeaderOperationStartDelay,
+	HeaderCancelMode,
+	HeaderClaimToken,
+	HeaderCallerIdentity,
+	HeaderClientName,
+	HeaderClientVersion,
+	HeaderAcceptResultRedirect,
+	HeaderAcceptResultPages,
+	HeaderAdditionalCallbacks,
+	HeaderLinks,
+	"Content-Type",
+}
+
+// Query parameters that may only be set once per request.
+var singleValuedQueryParams = []string{
+	QueryCallbackURL,
+	QueryWait,
+	QueryLease,
+	QueryGroup,
+	QueryTerminate,
+	QueryReason,
+	QueryAction,
+	QueryOperation,
+	QueryCreatedAfter,
+	QueryCreatedBefore,
+	QueryExpiredBefore,
+	QueryPageSize,
+	QueryPageToken,
+}
+
+// validateRequest strictly validates the URL, header fields and query parameters of a request for the given method
+// before it is dispatched, see [HandlerOptions.StrictValidation]. The request body is not read.
+func validateRequest(method HandlerMethod, request *http.Request) *RequestValidationError {
+	for name, segment := range routeVars(request) {
+		if err := validatePathSegment(name, segment); err != nil {
+			return err
+		}
+	}
+	query, err := url.ParseQuery(request.URL.RawQuery)
+	if err != nil {
+		return validationErrorf(RequestValidationCodeInvalidQuery, "", "malformed query: %v", err)
+	}
+	for _, param := range singleValuedQueryParams {
+		if len(query[param]) > 1 {
+			return validationErrorf(RequestValidationCodeDuplicateField, param, "%s query parameter set more than once", param)
+		}
+	}
+	for _, name := range singleValuedHeaders {
+		if len(request.Header.Values(name)) > 1 {
+			return validationErrorf(RequestValidationCodeDuplicateField, name, "%s header set more than once", name)
+		}
+	}
+	for name, values := range request.Header {
+		for _, value := range values {
+			if !isPrintable(value) {
+				return validationErrorf(RequestValidationCodeInvalidHeader, name, "%s header contains invalid characters", name)
+			}
+		}
+	}
+	if err := validateDurationHeader(request, HeaderRequestTimeout, false); err != nil {
+		return err
+	}
+	for _, name := range []string{HeaderAcceptResultRedirect, HeaderAcceptResultPages} {
+		if v := request.Header.Get(name); v != "" && v != "true" && v != "false" {
+			return validationErrorf(RequestValidationCodeInvalidHeader, name, "%s header must be true or false", name)
+		}
+	}
+
+	switch method {
+	case HandlerMethodStartOperation:
+		for _, name := range []string{HeaderOperationDeadline, HeaderOperationStartTime} {
+			if v := request.Header.Get(name); v != "" {
+				if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+					return validationErrorf(RequestValidationCodeInvalidTimestamp, name, "%s header must be an RFC 3339 timestamp", name)
+				}
+			}
+		}
+		if err := validateDurationHeader(request, HeaderOperationStartDelay, true); err != nil {
+			return err
+		}
+		if err := validateContentType(request, false); err != nil {
+			return err
+		}
+	case HandlerMethodGetOperationResult:
+		if err := validateDurationQueryParam(query, QueryWait, true); err != nil {
+			return err
+		}
+	case HandlerMethodCancelOperation:
+		if _, err := cancelModeFromHTTPRequest(request); err != nil {
+			return validationErrorf(RequestValidationCodeInvalidHeader, HeaderCancelMode, "%s header must be %s or %s", HeaderCancelMode, CancelModeForce, CancelModeGraceful)
+		}
+		if err := validateContentType(request, true); err != nil {
+			return err
+		}
+	case HandlerMethodClaimOperationResult:
+		if err := validateDurationQueryParam(query, QueryLease, true); err != nil {
+			return err
+		}
+	case HandlerMethodListOperations, HandlerMethodCancelMatchingOperations:
+		if err := validateOperationFilterQuery(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePathSegment(name, segment string) *RequestValidationError {
+	unescaped, err := url.PathUnescape(segment)
+	if err != nil {
+		return validationErrorf(RequestValidationCodeInvalidPath, name, "%s path segment is not properly escaped", name)
+	}
+	if unescaped == "" || !isPrintable(unescaped) {
+		return validationErrorf(RequestValidationCodeInvalidPath, name, "%s path segment must be non-empty printable UTF-8", name)
+	}
+	return nil
+}
+
+// isPrintable reports whether s is valid UTF-8 without control characters.
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsControl) < 0
+}
+
+func validateDurationHeader(request *http.Request, name string, allowZero bool) *RequestValidationError {
+	if v := request.Header.Get(name); v != "" {
+		if !isValidDuration(v, allowZero) {
+			return validationErrorf(RequestValidationCodeInvalidDuration, name, "%s header must be a %s duration", name, durationRange(allowZero))
+		}
+	}
+	return nil
+}
+
+func validateDurationQueryParam(query url.Values, name string, allowZero bool) *RequestValidationError {
+	if v := query.Get(name); v != "" {
+		if !isValidDuration(v, allowZero) {
+			return validationErrorf(RequestValidationCodeInvalidDuration, name, "%s query parameter must be a %s duration", name, durationRange(allowZero))
+		}
+	}
+	return nil
+}
+
+func durationRange(allowZero bool) string {
+	if allowZero {
+		return "non-negative"
+	}
+	return "positive"
+}
+
+func isValidDuration(s string, allowZero bool) bool {
+	d, err := time.ParseDuration(s)
+	return err == nil && (d > 0 || allowZero && d == 0)
+}
+
+// validateContentType requires a well-formed Content-Type header on requests declaring a non-empty body, which must be
+// JSON if requireJSON is set.
+func validateContentType(request *http.Request, requireJSON bool) *RequestValidationError {
+	contentType := request.Header.Get("Content-Type")
+	if contentType == "" {
+		// Bodies of unknown length may be empty, e.g. those of start requests without input.
+		if request.ContentLength > 0 {
+			return validationErrorf(RequestValidationCodeInvalidContentType, "Content-Type", "Content-Type header is required for requests with a body")
+		}
+		return nil
+	}
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		return validationErrorf(RequestValidationCodeInvalidContentType, "Content-Type", "malformed Content-Type header: %q", contentType)
+	}
+	if requireJSON && !isMediaTypeJSON(contentType) {
+		return validationErrorf(RequestValidationCodeInvalidContentType, "Content-Type", "Content-Type header must be application/json")
+	}
+	return nil
+}
+
+func validateOperationFilterQuery(query url.Values) *RequestValidationError {
+	for _, state := range query[QueryState] {
+		switch OperationState(state) {
+		case OperationStateRunning, OperationStateSucceeded, OperationStateFailed, OperationStateCanceled:
+		default:
+			return validationErrorf(RequestValidationCodeInvalidOperationState, QueryState, "invalid operation state: %q", state)
+		}
+	}
+	for _, param := range []string{QueryCreatedAfter, QueryCreatedBefore, QueryExpiredBefore} {
+		if v := query.Get(param); v != "" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return validationErrorf(RequestValidationCodeInvalidTimestamp, param, "%s query parameter must be an RFC 3339 timestamp", param)
+			}
+		}
+	}
+	for _, tag := range query[QueryTag] {
+		if k, _, ok := strings.Cut(tag, ":"); !ok || k == "" {
+			return validationErrorf(RequestValidationCodeInvalidQuery, QueryTag, "%s query parameter must be of the form key:value", QueryTag)
+		}
+	}
+	if v := query.Get(QueryPageSize); v != "" {
+		if pageSize, err := strconv.Atoi(v); err != nil || pageSize <= 0 {
+			return validationErrorf(RequestValidationCodeInvalidQuery, QueryPageSize, "%s query parameter must be a positive integer", QueryPageSize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupStrict(t *testing.T) (context.Context, *Client, http.Handler) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return "done", nil
+		},
+	})
+	require.NoError(t, err)
+	options := HandlerOptions{Handler: handler, StrictValidation: true}
+	ctx, client, teardown := setupCustom(t, options, ClientOptions{})
+	t.Cleanup(teardown)
+	return ctx, client, NewHTTPHandler(options)
+}
+
+func TestStrictValidation_ClientRequests(t *testing.T) {
+	ctx, client, _ := setupStrict(t)
+
+	for _, input := range []any{"hello", []byte("hello"), nil} {
+		result, err := client.StartOperation(ctx, "foo", input, StartOperationOptions{
+			RequestID:  "request-" + time.Now().String(),
+			Deadline:   time.Now().Add(time.Hour),
+			StartDelay: time.Millisecond,
+			Tags:       map[string]string{"team": "billing"},
+		})
+		require.NoError(t, err)
+		value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+		require.NoError(t, err)
+		var s string
+		require.NoError(t, value.Consume(&s))
+		require.Equal(t, "done", s)
+		_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	}
+
+	list, err := client.ListOperations(ctx, ListOperationsOptions{
+		Filter:   OperationFilter{States: []OperationState{OperationStateSucceeded}, CreatedAfter: time.Now().Add(-time.Hour)},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 3)
+
+	_, err = client.ListOperations(ctx, ListOperationsOptions{Filter: OperationFilter{States: []OperationState{"done"}}})
+	var unexpectedErr *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedErr)
+	require.Equal(t, http.StatusBadRequest, unexpectedErr.Response.StatusCode)
+	validationErr, ok := RequestValidationErrorFromFailure(unexpectedErr.Failure)
+	require.True(t, ok)
+	require.Equal(t, RequestValidationCodeInvalidOperationState, validationErr.Code)
+	require.Equal(t, QueryState, validationErr.Field)
+}
+
+func TestStrictValidation_MalformedRequests(t *testing.T) {
+	_, _, handler := setupStrict(t)
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		header http.Header
+		body   string
+		code   RequestValidationCode
+		field  string
+	}{
+		{
+			name:   "control character in operation ID",
+			method: "GET",
+			target: "/foo/id%00",
+			code:   RequestValidationCodeInvalidPath,
+			field:  "operation_id",
+		},
+		{
+			name:   "invalid UTF-8 in operation name",
+			method: "POST",
+			target: "/%ff",
+			code:   RequestValidationCodeInvalidPath,
+			field:  "operation",
+		},
+		{
+			name:   "malformed query",
+			method: "GET",
+			target: "/foo/id/result?wait=%zz",
+			code:   RequestValidationCodeInvalidQuery,
+		},
+		{
+			name:   "duplicate query parameter",
+			method: "GET",
+			target: "/foo/id/result?wait=1s&wait=2s",
+			code:   RequestValidationCodeDuplicateField,
+			field:  QueryWait,
+		},
+		{
+			name:   "negative wait",
+			method: "GET",
+			target: "/foo/id/result?wait=-1s",
+			code:   RequestValidationCodeInvalidDuration,
+			field:  QueryWait,
+		},
+		{
+			name:   "duplicate request ID",
+			method: "POST",
+			target: "/foo",
+			header: http.Header{HeaderRequestID: {"a", "b"}},
+			code:   RequestValidationCodeDuplicateField,
+			field:  HeaderRequestID,
+		},
+		{
+			name:   "zero request timeout",
+			method: "GET",
+			target: "/foo/id",
+			header: http.Header{HeaderRequestTimeout: {"0s"}},
+			code:   RequestValidationCodeInvalidDuration,
+			field:  HeaderRequestTimeout,
+		},
+		{
+			name:   "invalid boolean header",
+			method: "GET",
+			target: "/foo/id/result",
+			header: http.Header{HeaderAcceptResultPages: {"yes"}},
+			code:   RequestValidationCodeInvalidHeader,
+			field:  HeaderAcceptResultPages,
+		},
+		{
+			name:   "invalid UTF-8 in header",
+			method: "GET",
+			target: "/foo/id",
+			header: http.Header{"Nexus-Custom": {"\xff"}},
+			code:   RequestValidationCodeInvalidHeader,
+			field:  "Nexus-Custom",
+		},
+		{
+			name:   "malformed deadline",
+			method: "POST",
+			target: "/foo",
+			header: http.Header{HeaderOperationDeadline: {"tomorrow"}},
+			code:   RequestValidationCodeInvalidTimestamp,
+			field:  HeaderOperationDeadline,
+		},
+		{
+			name:   "malformed start delay",
+			method: "POST",
+			target: "/foo",
+			header: http.Header{HeaderOperationStartDelay: {"soon"}},
+			code:   RequestValidationCodeInvalidDuration,
+			field:  HeaderOperationStartDelay,
+		},
+		{
+			name:   "body without content type",
+			method: "POST",
+			target: "/foo",
+			body:   "hello",
+			code:   RequestValidationCodeInvalidContentType,
+			field:  "Content-Type",
+		},
+		{
+			name:   "malformed content type",
+			method: "POST",
+			target: "/foo",
+			header: http.Header{"Content-Type": {"application/"}},
+			body:   "hello",
+			code:   RequestValidationCodeInvalidContentType,
+			field:  "Content-Type",
+		},
+		{
+			name:   "non JSON cancel reason",
+			method: "POST",
+			target: "/foo/id/cancel",
+			header: http.Header{"Content-Type": {"text/plain"}},
+			body:   "reason",
+			code:   RequestValidationCodeInvalidContentType,
+			field:  "Content-Type",
+		},
+		{
+			name:   "invalid cancel mode",
+			method: "POST",
+			target: "/foo/id/cancel",
+			header: http.Header{HeaderCancelMode: {"now"}},
+			code:   RequestValidationCodeInvalidHeader,
+			field:  HeaderCancelMode,
+		},
+		{
+			name:   "unknown operation state",
+			method: "GET",
+			target: "/?state=RUNNING",
+			code:   RequestValidationCodeInvalidOperationState,
+			field:  QueryState,
+		},
+		{
+			name:   "malformed created after",
+			method: "GET",
+			target: "/?createdAfter=yesterday",
+			code:   RequestValidationCodeInvalidTimestamp,
+			field:  QueryCreatedAfter,
+		},
+		{
+			name:   "zero page size",
+			method: "GET",
+			target: "/?pageSize=0",
+			code:   RequestValidationCodeInvalidQuery,
+			field:  QueryPageSize,
+		},
+		{
+			name:   "negative lease",
+			method: "POST",
+			target: "/foo/id/result/claim?lease=-1m",
+			code:   RequestValidationCodeInvalidDuration,
+			field:  QueryLease,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			for k, v := range c.header {
+				request.Header[k] = v
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var failure Failure
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &failure))
+			validationErr, ok := RequestValidationErrorFromFailure(&failure)
+			require.True(t, ok)
+			require.Equal(t, c.code, validationErr.Code)
+			require.Equal(t, c.field, validationErr.Field)
+			require.NotEmpty(t, validationErr.Message)
+		})
+	}
+}
+
+func TestStrictValidation_Disabled(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	request := httptest.NewRequest("GET", "http://localhost/?state=RUNNING", nil)
+	recorder := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: handler}).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	_, ok := RequestValidationErrorFromFailure(&Failure{Message: "invalid"})
+	require.False(t, ok)
+}
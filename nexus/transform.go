@@ -0,0 +1,106 @@
+package nexus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"maps"
+)
+
+// A ContentTransformer transforms serialized content on the wire, e.g. to compress or encrypt it, or to replace large
+// payloads with a reference to an external store.
+//
+// Transformers are applied in order when sending content and in reverse order when receiving it. Transformers should
+// mark content they encode via its [Header] so that Decode can pass through content it did not encode.
+type ContentTransformer interface {
+	// Encode transforms content before it is sent.
+	Encode(*Content) (*Content, error)
+	// Decode reverses Encode on received content. Content not encoded by this transformer must be returned unchanged.
+	Decode(*Content) (*Content, error)
+}
+
+// encodeContent applies transformers to content in order.
+func encodeContent(transformers []ContentTransformer, content *Content) (*Content, error) {
+	original := content
+	for _, t := range transformers {
+		var err error
+		if content, err = t.Encode(content); err != nil {
+			return nil, err
+		}
+	}
+	if content != original {
+		// Keep the digest of transformed content valid for the data it is sent or received with.
+		content = redigest(content)
+	}
+	return content, nil
+}
+
+// decodeContent reverses transformers on content in reverse order.
+func decodeContent(transformers []ContentTransformer, content *Content) (*Content, error) {
+	original := content
+	for i := len(transformers) - 1; i >= 0; i-- {
+		var err error
+		if content, err = transformers[i].Decode(content); err != nil {
+			return nil, err
+		}
+	}
+	if content != original {
+		// Keep the digest of transformed content valid for the data it is sent or received with.
+		content = redigest(content)
+	}
+	return content, nil
+}
+
+// Header value of the "encoding" content header marking gzip compressed content.
+const gzipEncoding = "gzip"
+
+type gzipTransformer struct {
+	minSize int
+}
+
+// NewGzipTransformer creates a [ContentTransformer] that gzip compresses content of at least minSize bytes, marking it
+// with a "gzip" Content-Encoding.
+func NewGzipTransformer(minSize int) ContentTransformer {
+	return gzipTransformer{minSize: minSize}
+}
+
+// Encode implements ContentTransformer.
+func (t gzipTransformer) Encode(content *Content) (*Content, error) {
+	if len(content.Data) < t.minSize || content.Header["encoding"] != "" {
+		return content, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content.Data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	header := maps.Clone(content.Header)
+	if header == nil {
+		header = Header{}
+	}
+	delete(header, "length")
+	header["encoding"] = gzipEncoding
+	return &Content{Header: header, Data: buf.Bytes()}, nil
+}
+
+// Decode implements ContentTransformer.
+func (gzipTransformer) Decode(content *Content) (*Content, error) {
+	if content.Header["encoding"] != gzipEncoding {
+		return content, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(content.Data))
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	header := maps.Clone(content.Header)
+	delete(header, "length")
+	delete(header, "encoding")
+	return &Content{Header: header, Data: data}, nil
+}
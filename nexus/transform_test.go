@@ -0,0 +1,106 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// xorTransformer is a toy cipher for verifying transformer ordering.
+type xorTransformer struct{}
+
+func (xorTransformer) Encode(content *Content) (*Content, error) {
+	header := Header{"cipher": "xor"}
+	for k, v := range content.Header {
+		header[k] = v
+	}
+	return &Content{Header: header, Data: xor(content.Data)}, nil
+}
+
+func (xorTransformer) Decode(content *Content) (*Content, error) {
+	if content.Header["cipher"] != "xor" {
+		return content, nil
+	}
+	header := Header{}
+	for k, v := range content.Header {
+		if k != "cipher" {
+			header[k] = v
+		}
+	}
+	return &Content{Header: header, Data: xor(content.Data)}, nil
+}
+
+func xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5a
+	}
+	return out
+}
+
+type recordingCompletionHandler struct {
+	results chan string
+}
+
+func (h *recordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	var result string
+	if err := completion.Result.Consume(&result); err != nil {
+		return err
+	}
+	h.results <- result
+	return nil
+}
+
+func TestCompletionTransformers(t *testing.T) {
+	transformers := []ContentTransformer{NewGzipTransformer(10), xorTransformer{}}
+	handler := &recordingCompletionHandler{results: make(chan string, 2)}
+	headers := make(chan http.Header, 2)
+	completionHandler := NewCompletionHTTPHandler(CompletionHandlerOptions{Handler: handler, Transformers: transformers})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		headers <- request.Header.Clone()
+		completionHandler.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	for _, result := range []string{"short", strings.Repeat("compressible", 100)} {
+		completion, err := NewOperationCompletionSuccessful(result, OperationCompletionSuccesfulOptions{Transformers: transformers})
+		require.NoError(t, err)
+		request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, result, <-handler.results)
+
+		header := <-headers
+		require.Equal(t, "xor", header.Get("Content-Cipher"))
+		if len(result) > 10 {
+			require.Equal(t, "gzip", header.Get("Content-Encoding"))
+			require.Less(t, request.ContentLength, int64(len(result)))
+		} else {
+			require.Empty(t, header.Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestCompletionTransformers_DecodeFailure(t *testing.T) {
+	server := httptest.NewServer(NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:      &recordingCompletionHandler{results: make(chan string, 1)},
+		Transformers: []ContentTransformer{NewGzipTransformer(0)},
+	}))
+	defer server.Close()
+
+	completion, err := NewOperationCompletionSuccessful(&Content{Header: Header{"type": "application/json", "encoding": "gzip"}, Data: []byte(`"not gzip"`)}, OperationCompletionSuccesfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), server.URL, completion)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
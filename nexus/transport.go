@@ -0,0 +1,201 @@
+package nexus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Defaults of [HTTPTransportOptions].
+const (
+	defaultHTTPTransportDialTimeout         = 10 * time.Second
+	defaultHTTPTransportKeepAlive           = 15 * time.Second
+	defaultHTTPTransportTLSHandshakeTimeout = 10 * time.Second
+	defaultHTTPTransportIdleConnTimeout     = 5 * time.Minute
+	defaultHTTPTransportMaxIdleConns        = 256
+	defaultHTTPTransportMaxIdleConnsPerHost = 64
+)
+
+// HTTPTransportOptions are options for [NewHTTPTransport]. Zero values select defaults suited to Nexus traffic.
+type HTTPTransportOptions struct {
+	// TLS configuration, e.g. client certificates for mutual TLS (see [NewMTLSConfig]). The configuration is cloned.
+	TLSConfig *tls.Config
+	// Custom function for establishing network connections. DialTimeout and KeepAlive are ignored if set.
+	DialContext DialContextFunc
+	// Maximum amount of time to wait for a connection to be established. Defaults to 10 seconds.
+	DialTimeout time.Duration
+	// Interval of TCP keep-alive probes, which keep idle connections and connections held by long polls alive across
+	// NATs and load balancers that drop silent connections. Defaults to 15 seconds, negative values disable probes.
+	KeepAlive time.Duration
+	// Maximum amount of time to wait for a TLS handshake. Defaults to 10 seconds.
+	TLSHandshakeTimeout time.Duration
+	// Maximum amount of time an idle connection remains in the pool before closing itself. Defaults to 5 minutes,
+	// which retains connections between polls that are minutes apart.
+	IdleConnTimeout time.Duration
+	// Maximum number of idle connections to keep across all hosts. Defaults to 256.
+	MaxIdleConns int
+	// Maximum number of idle connections to keep per host. Defaults to 64, allowing bursts of concurrent long polls to
+	// reuse connections instead of closing all but a few of them once they return.
+	MaxIdleConnsPerHost int
+	// Maximum number of connections per host, including connections in use. Zero means no limit.
+	MaxConnsPerHost int
+	// Function that returns the proxy to use for a given request. Defaults to [http.ProxyFromEnvironment].
+	Proxy func(*http.Request) (*url.URL, error)
+	// Disable HTTP/2, which is otherwise negotiated with servers supporting it over TLS, multiplexing requests over a
+	// single connection per host.
+	DisableHTTP2 bool
+}
+
+// NewHTTPTransport creates an [http.Transport] tuned for Nexus clients, used by the [Client] unless a custom
+// HTTPCaller is set.
+//
+// Unlike [http.DefaultTransport], the transport sends TCP keep-alive probes more often, keeps idle connections longer
+// and keeps more of them per host, and attempts HTTP/2 even with a custom TLS configuration or dialer. It sets no
+// response header timeout, which would cut long polls short; bound requests with their context instead.
+func NewHTTPTransport(options HTTPTransportOptions) *http.Transport {
+	dialContext := options.DialContext
+	if dialContext == nil {
+		dialer := &net.Dialer{
+			Timeout:   valueOrDefault(options.DialTimeout, defaultHTTPTransportDialTimeout),
+			KeepAlive: valueOrDefault(options.KeepAlive, defaultHTTPTransportKeepAlive),
+		}
+		dialContext = dialer.DialContext
+	}
+	proxy := options.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext,
+		ForceAttemptHTTP2:     !options.DisableHTTP2,
+		TLSHandshakeTimeout:   valueOrDefault(options.TLSHandshakeTimeout, defaultHTTPTransportTLSHandshakeTimeout),
+		IdleConnTimeout:       valueOrDefault(options.IdleConnTimeout, defaultHTTPTransportIdleConnTimeout),
+		MaxIdleConns:          valueOrDefault(options.MaxIdleConns, defaultHTTPTransportMaxIdleConns),
+		MaxIdleConnsPerHost:   valueOrDefault(options.MaxIdleConnsPerHost, defaultHTTPTransportMaxIdleConnsPerHost),
+		MaxConnsPerHost:       options.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+	}
+	if options.TLSConfig != nil {
+		transport.TLSClientConfig = options.TLSConfig.Clone()
+	}
+	if options.DisableHTTP2 {
+		// A non-nil empty map disables HTTP/2.
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
+
+// valueOrDefault returns v, or def if v is zero.
+func valueOrDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
+
+// Transport shared by clients without transport options, so that they share a connection pool.
+var defaultHTTPTransport = NewHTTPTransport(HTTPTransportOptions{})
+
+var defaultHTTPClient = &http.Client{Transport: defaultHTTPTransport}
+
+var errTransportOptionsWithHTTPCaller = errors.New("transport options cannot be combined with a custom HTTPCaller")
+
+var errSeparateLongPollTransportWithCaller = errors.New("SeparateLongPollTransport cannot be combined with a custom LongPollHTTPCaller")
+
+// hasTransportOptions reports whether any of the options used to construct the client's HTTP transport are set.
+func (o *ClientOptions) hasTransportOptions() bool {
+	return o.TLSConfig != nil || o.DialContext != nil || o.DialTimeout > 0 || o.KeepAlive != 0 ||
+		o.TLSHandshakeTimeout > 0 || o.IdleConnTimeout > 0 || o.MaxIdleConnsPerHost > 0 || o.MaxIdleConns > 0 ||
+		o.MaxConnsPerHost > 0 || o.Proxy != nil || o.DisableHTTP2 || o.SeparateLongPollTransport || o.LongPollMaxIdleConnsPerHost > 0 ||
+		o.LongPollMaxConnsPerHost > 0 || o.LongPollIdleConnTimeout > 0
+}
+
+// newHTTPClient creates the HTTP client used when no HTTPCaller is provided.
+func newHTTPClient(options ClientOptions) *http.Client {
+	if !options.hasTransportOptions() {
+		if options.FollowResultRedirects {
+			return noRedirectHTTPClient
+		}
+		return defaultHTTPClient
+	}
+	return newHTTPClientWithTransport(options, newHTTPTransport(options))
+}
+
+// newLongPollHTTPClient creates the dedicated HTTP client for long polls used when SeparateLongPollTransport is set.
+func newLongPollHTTPClient(options ClientOptions) *http.Client {
+	transport := newHTTPTransport(options)
+	if options.LongPollMaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.LongPollMaxIdleConnsPerHost
+	}
+	if options.LongPollMaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = options.LongPollMaxConnsPerHost
+	}
+	if options.LongPollIdleConnTimeout > 0 {
+		transport.IdleConnTimeout = options.LongPollIdleConnTimeout
+	}
+	return newHTTPClientWithTransport(options, transport)
+}
+
+func newHTTPClientWithTransport(options ClientOptions, transport *http.Transport) *http.Client {
+	client := &http.Client{Transport: transport}
+	if options.FollowResultRedirects {
+		client.CheckRedirect = noRedirectHTTPClient.CheckRedirect
+	}
+	return client
+}
+
+// newHTTPTransport creates an HTTP transport configured with the client's transport options.
+func newHTTPTransport(options ClientOptions) *http.Transport {
+	return NewHTTPTransport(HTTPTransportOptions{
+		TLSConfig:           options.TLSConfig,
+		DialContext:         options.DialContext,
+		DialTimeout:         options.DialTimeout,
+		KeepAlive:           options.KeepAlive,
+		TLSHandshakeTimeout: options.TLSHandshakeTimeout,
+		IdleConnTimeout:     options.IdleConnTimeout,
+		MaxIdleConns:        options.MaxIdleConns,
+		MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     options.MaxConnsPerHost,
+		Proxy:               options.Proxy,
+		DisableHTTP2:        options.DisableHTTP2,
+	})
+}
+
+// NewMTLSConfig creates a [tls.Config] for mutual TLS from PEM encoded files, for use as [ClientOptions.TLSConfig].
+//
+// The client certificate and key are presented to the server. If caFile is non-empty, the server certificate is
+// verified against the CA certificates it contains instead of the system roots.
+func NewMTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in %q", caFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// DialContextFunc is the signature of [ClientOptions.DialContext], matching [net.Dialer.DialContext].
+type DialContextFunc = func(ctx context.Context, network, address string) (net.Conn, error)
@@ -0,0 +1,219 @@
+package nexus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type infoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *infoHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func TestMTLS(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	clientCert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", certDER)
+	writePEM(t, filepath.Join(dir, "client.key"), "EC PRIVATE KEY", keyDER)
+
+	server := httptest.NewUnstartedServer(NewHTTPHandler(HandlerOptions{Handler: &infoHandler{}}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", server.Certificate().Raw)
+
+	tlsConfig, err := NewMTLSConfig(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.pem"))
+	require.NoError(t, err)
+	var dials atomic.Int32
+	dialer := &net.Dialer{}
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: server.URL,
+		TLSConfig:      tlsConfig,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dials.Add(1)
+			return dialer.DialContext(ctx, network, address)
+		},
+		Proxy: func(*http.Request) (*url.URL, error) { return nil, nil },
+	})
+	require.NoError(t, err)
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "id", info.ID)
+	require.Equal(t, int32(1), dials.Load())
+
+	// Without a client certificate the handshake fails.
+	client, err = NewClient(ClientOptions{
+		ServiceBaseURL: server.URL,
+		TLSConfig:      &tls.Config{RootCAs: tlsConfig.RootCAs},
+	})
+	require.NoError(t, err)
+	handle, err = client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+}
+
+func TestNewClient_TransportOptionsWithHTTPCaller(t *testing.T) {
+	_, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://localhost",
+		HTTPCaller:     http.DefaultClient.Do,
+		DialTimeout:    time.Second,
+	})
+	require.ErrorIs(t, err, errTransportOptionsWithHTTPCaller)
+}
+
+func TestNewClient_LongPollHTTPCaller(t *testing.T) {
+	handler, err := NewAsyncHandler(AsyncHandlerOptions{
+		Store: NewMemoryOperationStore(),
+		Executor: func(ctx context.Context, operation string, input *LazyValue, options StartOperationOptions) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	var calls, longPolls atomic.Int32
+	ctx, client, teardown := setupCustom(t, HandlerOptions{Handler: handler, GetResultTimeout: getResultMaxTimeout}, ClientOptions{
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return http.DefaultClient.Do(request)
+		},
+		LongPollHTTPCaller: func(request *http.Request) (*http.Response, error) {
+			longPolls.Add(1)
+			return http.DefaultClient.Do(request)
+		},
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, int32(0), longPolls.Load())
+
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: 10 * time.Millisecond})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	stream, err := result.Pending.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	require.Equal(t, int32(2), calls.Load())
+	require.GreaterOrEqual(t, longPolls.Load(), int32(2))
+}
+
+func TestNewClient_SeparateLongPollTransport(t *testing.T) {
+	options := ClientOptions{
+		ServiceBaseURL:              "http://localhost",
+		MaxIdleConnsPerHost:         10,
+		MaxConnsPerHost:             20,
+		IdleConnTimeout:             time.Minute,
+		SeparateLongPollTransport:   true,
+		LongPollMaxIdleConnsPerHost: 50,
+		LongPollIdleConnTimeout:     2 * time.Minute,
+	}
+	transport := newHTTPClient(options).Transport.(*http.Transport)
+	longPollTransport := newLongPollHTTPClient(options).Transport.(*http.Transport)
+	require.NotSame(t, transport, longPollTransport)
+	require.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 20, transport.MaxConnsPerHost)
+	require.Equal(t, time.Minute, transport.IdleConnTimeout)
+	require.Equal(t, 50, longPollTransport.MaxIdleConnsPerHost)
+	require.Equal(t, 20, longPollTransport.MaxConnsPerHost)
+	require.Equal(t, 2*time.Minute, longPollTransport.IdleConnTimeout)
+
+	_, err := NewClient(options)
+	require.NoError(t, err)
+
+	options.LongPollHTTPCaller = http.DefaultClient.Do
+	_, err = NewClient(options)
+	require.ErrorIs(t, err, errSeparateLongPollTransportWithCaller)
+}
+
+func TestNewHTTPTransport(t *testing.T) {
+	transport := NewHTTPTransport(HTTPTransportOptions{})
+	require.True(t, transport.ForceAttemptHTTP2)
+	require.Equal(t, defaultHTTPTransportIdleConnTimeout, transport.IdleConnTimeout)
+	require.Equal(t, defaultHTTPTransportMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	require.Zero(t, transport.ResponseHeaderTimeout)
+	require.NotNil(t, transport.Proxy)
+
+	transport = NewHTTPTransport(HTTPTransportOptions{IdleConnTimeout: time.Minute, MaxIdleConnsPerHost: 4, DisableHTTP2: true})
+	require.False(t, transport.ForceAttemptHTTP2)
+	require.NotNil(t, transport.TLSNextProto)
+	require.Equal(t, time.Minute, transport.IdleConnTimeout)
+	require.Equal(t, 4, transport.MaxIdleConnsPerHost)
+}
+
+func TestNewHTTPTransport_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	for _, disable := range []bool{false, true} {
+		client := &http.Client{Transport: NewHTTPTransport(HTTPTransportOptions{TLSConfig: tlsConfig, DisableHTTP2: disable})}
+		response, err := client.Get(server.URL)
+		require.NoError(t, err)
+		response.Body.Close()
+		if disable {
+			require.Equal(t, 1, response.ProtoMajor)
+		} else {
+			require.Equal(t, 2, response.ProtoMajor)
+		}
+	}
+}
+
+func TestNewClient_DefaultTransport(t *testing.T) {
+	require.Same(t, defaultHTTPTransport, newHTTPClient(ClientOptions{}).Transport)
+	require.Same(t, defaultHTTPTransport, newHTTPClient(ClientOptions{FollowResultRedirects: true}).Transport)
+	require.NotSame(t, defaultHTTPTransport, newHTTPClient(ClientOptions{KeepAlive: time.Minute}).Transport)
+}
@@ -31,6 +31,51 @@ func (h UnimplementedHandler) CancelOperation(ctx context.Context, operation, op
 	return &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
 }
 
+// WatchOperation implements the Handler interface.
+func (h UnimplementedHandler) WatchOperation(ctx context.Context, operation, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// ClaimOperationResult implements the Handler interface.
+func (h UnimplementedHandler) ClaimOperationResult(ctx context.Context, operation, operationID string, options ClaimOperationResultOptions) (*HandlerResultClaim, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// AckOperationResult implements the Handler interface.
+func (h UnimplementedHandler) AckOperationResult(ctx context.Context, operation, operationID, token string, options AckOperationResultOptions) error {
+	return &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// ListOperations implements the Handler interface.
+func (h UnimplementedHandler) ListOperations(ctx context.Context, options ListOperationsOptions) (*OperationList, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// CancelMatchingOperations implements the Handler interface.
+func (h UnimplementedHandler) CancelMatchingOperations(ctx context.Context, options CancelMatchingOperationsOptions) (<-chan *BatchProgress, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// GetQuotaUsage implements the Handler interface.
+func (h UnimplementedHandler) GetQuotaUsage(ctx context.Context, subject string, options GetQuotaUsageOptions) (*QuotaStatus, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// HeartbeatOperation implements the Handler interface.
+func (h UnimplementedHandler) HeartbeatOperation(ctx context.Context, operation, operationID string, options HeartbeatOperationOptions) (*OperationInfo, error) {
+	return nil, &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// ResolveQuarantine implements the Handler interface.
+func (h UnimplementedHandler) ResolveQuarantine(ctx context.Context, operation, operationID string, options ResolveQuarantineOptions) error {
+	return &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
+// ForceCompleteOperation implements the Handler interface.
+func (h UnimplementedHandler) ForceCompleteOperation(ctx context.Context, operation, operationID string, options ForceCompleteOperationOptions) error {
+	return &HandlerError{HandlerErrorTypeNotImplemented, &Failure{Message: "not implemented"}}
+}
+
 // UnimplementedOperation must be embedded into any [Operation] implementation for future compatibility.
 // It implements all methods on the [Operation] interface except for `Name`, returning unimplemented errors if they are
 // not implemented by the embedding type.
@@ -56,6 +101,11 @@ func (*UnimplementedOperation[I, O]) GetResult(context.Context, string, GetOpera
 	return empty, HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
 }
 
+// Watch implements Operation.
+func (*UnimplementedOperation[I, O]) Watch(context.Context, string, WatchOperationOptions) (<-chan *OperationInfo, error) {
+	return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
+}
+
 // Start implements Operation.
 func (h *UnimplementedOperation[I, O]) Start(ctx context.Context, input I, options StartOperationOptions) (HandlerStartOperationResult[O], error) {
 	return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
@@ -0,0 +1,75 @@
+package nexus
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// A WaitStrategy determines the wait duration of each long poll request issued by [OperationHandle.GetResult] and
+// [Client.ExecuteOperation] within the wait period of the call, see [ClientOptions.WaitStrategy].
+//
+// Implementations must be safe for concurrent use.
+type WaitStrategy interface {
+	// Wait returns the wait duration of the given long poll request of a call, starting at 1, given the remaining wait
+	// period of the call, which is always positive. Durations exceeding remaining are capped to it.
+	Wait(poll int, remaining time.Duration) time.Duration
+}
+
+type fixedWaitStrategy struct{}
+
+// NewFixedWaitStrategy creates a [WaitStrategy] that waits for the entire remaining wait period in every request.
+func NewFixedWaitStrategy() WaitStrategy {
+	return fixedWaitStrategy{}
+}
+
+// Wait implements WaitStrategy.
+func (fixedWaitStrategy) Wait(poll int, remaining time.Duration) time.Duration {
+	return remaining
+}
+
+// AdaptiveWaitStrategyOptions are options for [NewAdaptiveWaitStrategy].
+type AdaptiveWaitStrategyOptions struct {
+	// Wait duration of the first request. Defaults to 5 seconds.
+	InitialWait time.Duration
+	// Factor by which the wait duration grows with every request. Defaults to 2.
+	Multiplier float64
+	// Maximum wait duration of a request. Defaults to one minute.
+	MaxWait time.Duration
+	// Fraction by which wait durations are randomly shortened, between 0 and 1, to spread the requests of concurrent
+	// pollers over time. Defaults to 0.2, set to a negative value to disable jitter.
+	Jitter float64
+}
+
+type adaptiveWaitStrategy struct {
+	options AdaptiveWaitStrategyOptions
+}
+
+// NewAdaptiveWaitStrategy creates a [WaitStrategy] that starts with short waits, growing toward a maximum with every
+// request, and randomly shortens each wait. Pollers waiting on operations that complete quickly are served by short
+// requests, while the jitter keeps pollers whose requests were interrupted at once, e.g. by a handler deploy, from
+// reconnecting in lockstep.
+func NewAdaptiveWaitStrategy(options AdaptiveWaitStrategyOptions) WaitStrategy {
+	if options.InitialWait <= 0 {
+		options.InitialWait = 5 * time.Second
+	}
+	if options.Multiplier < 1 {
+		options.Multiplier = 2
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = time.Minute
+	}
+	if options.Jitter == 0 {
+		options.Jitter = 0.2
+	}
+	options.Jitter = min(max(options.Jitter, 0), 1)
+	return &adaptiveWaitStrategy{options}
+}
+
+// Wait implements WaitStrategy.
+func (s *adaptiveWaitStrategy) Wait(poll int, remaining time.Duration) time.Duration {
+	wait := float64(s.options.InitialWait) * math.Pow(s.options.Multiplier, float64(poll-1))
+	wait = min(wait, float64(s.options.MaxWait))
+	wait *= 1 - s.options.Jitter*rand.Float64()
+	return min(max(time.Duration(wait), time.Millisecond), remaining)
+}
@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedWaitStrategy(t *testing.T) {
+	strategy := NewFixedWaitStrategy()
+	require.Equal(t, time.Minute, strategy.Wait(1, time.Minute))
+	require.Equal(t, time.Second, strategy.Wait(5, time.Second))
+}
+
+func TestAdaptiveWaitStrategy(t *testing.T) {
+	strategy := NewAdaptiveWaitStrategy(AdaptiveWaitStrategyOptions{
+		InitialWait: time.Second,
+		Multiplier:  3,
+		MaxWait:     10 * time.Second,
+		Jitter:      -1,
+	})
+	require.Equal(t, time.Second, strategy.Wait(1, time.Hour))
+	require.Equal(t, 3*time.Second, strategy.Wait(2, time.Hour))
+	require.Equal(t, 9*time.Second, strategy.Wait(3, time.Hour))
+	require.Equal(t, 10*time.Second, strategy.Wait(4, time.Hour))
+	require.Equal(t, 2*time.Second, strategy.Wait(3, 2*time.Second))
+
+	strategy = NewAdaptiveWaitStrategy(AdaptiveWaitStrategyOptions{})
+	waits := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		wait := strategy.Wait(1, time.Hour)
+		require.LessOrEqual(t, wait, 5*time.Second)
+		require.GreaterOrEqual(t, wait, 4*time.Second)
+		waits[wait] = true
+	}
+	require.Greater(t, len(waits), 1, "expected jittered waits")
+}
+
+func TestAdaptiveWaitStrategy_GetResult(t *testing.T) {
+	handler := asyncWithResultHandler{timesToBlock: 2}
+	ctx, client, teardown := setupCustom(t, HandlerOptions{
+		GetResultTimeout: getResultMaxTimeout,
+		Handler:          &handler,
+	}, ClientOptions{WaitStrategy: NewAdaptiveWaitStrategy(AdaptiveWaitStrategyOptions{
+		InitialWait: 20 * time.Millisecond,
+		Jitter:      -1,
+	})})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	// Polls expiring with a still running status are repeated while the wait period lasts.
+	value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var body []byte
+	require.NoError(t, value.Consume(&body))
+	require.Equal(t, []byte("body"), body)
+
+	requests := handler.getRequests()
+	require.Len(t, requests, 3)
+	require.Equal(t, 20*time.Millisecond, requests[0].options.Wait)
+	require.Equal(t, 40*time.Millisecond, requests[1].options.Wait)
+	require.Equal(t, 80*time.Millisecond, requests[2].options.Wait)
+}
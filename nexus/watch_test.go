@@ -0,0 +1,126 @@
+package nexus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type asyncWithWatchHandler struct {
+	UnimplementedHandler
+}
+
+func (h *asyncWithWatchHandler) WatchOperation(ctx context.Context, operation, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error) {
+	if operation != "f/o/o" {
+		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation not found: %s", operation)
+	}
+	if options.Header.Get("User-Agent") != userAgent {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'User-Agent' header: %q", options.Header.Get("User-Agent"))
+	}
+	ch := make(chan *OperationInfo)
+	go func() {
+		defer close(ch)
+		for _, state := range []OperationState{OperationStateRunning, OperationStateSucceeded} {
+			select {
+			case ch <- &OperationInfo{ID: operationID, State: state}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func TestWatch(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithWatchHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("f/o/o", "a/sync")
+	require.NoError(t, err)
+	stream, err := handle.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	info, err := stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, &OperationInfo{ID: "a/sync", State: OperationStateRunning}, info)
+	info, err = stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, &OperationInfo{ID: "a/sync", State: OperationStateSucceeded}, info)
+	_, err = stream.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestWatch_HandlerError(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithWatchHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("bar", "a/sync")
+	require.NoError(t, err)
+	_, err = handle.Watch(ctx, WatchOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestWatch_NotImplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "async")
+	require.NoError(t, err)
+	_, err = handle.Watch(ctx, WatchOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+}
+
+// largeEventWatchHandler sends a single state change with heartbeat details of the given size.
+type largeEventWatchHandler struct {
+	UnimplementedHandler
+	size int
+}
+
+func (h *largeEventWatchHandler) WatchOperation(ctx context.Context, operation, operationID string, options WatchOperationOptions) (<-chan *OperationInfo, error) {
+	details, err := json.Marshal(strings.Repeat("x", h.size))
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *OperationInfo, 1)
+	ch <- &OperationInfo{ID: operationID, State: OperationStateRunning, HeartbeatDetails: details}
+	close(ch)
+	return ch, nil
+}
+
+func TestWatch_LargeEvents(t *testing.T) {
+	// Events exceeding the default buffer size of bufio.Scanner are received.
+	handler := &largeEventWatchHandler{size: 100_000}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	stream, err := handle.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+	info, err := stream.Next()
+	require.NoError(t, err)
+	require.Len(t, info.HeartbeatDetails, handler.size+2)
+
+	// Events exceeding the configured maximum fail the stream.
+	ctx, client, teardown = setupCustom(t, HandlerOptions{Handler: handler}, ClientOptions{MaxEventSize: 10_000})
+	defer teardown()
+	handle, err = client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	stream, err = handle.Watch(ctx, WatchOperationOptions{})
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Next()
+	require.ErrorIs(t, err, bufio.ErrTooLong)
+}
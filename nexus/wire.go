@@ -0,0 +1,154 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HTTP status codes of failed responses per [HandlerErrorType].
+var handlerErrorTypeStatusCodes = map[HandlerErrorType]int{
+	HandlerErrorTypeBadRequest:        http.StatusBadRequest,
+	HandlerErrorTypeUnauthenticated:   http.StatusUnauthorized,
+	HandlerErrorTypeUnauthorized:      http.StatusForbidden,
+	HandlerErrorTypeNotFound:          http.StatusNotFound,
+	HandlerErrorTypeResourceExhausted: http.StatusTooManyRequests,
+	HandlerErrorTypeInternal:          http.StatusInternalServerError,
+	HandlerErrorTypeNotImplemented:    http.StatusNotImplemented,
+	HandlerErrorTypeUnavailable:       http.StatusServiceUnavailable,
+	HandlerErrorTypeDownstreamError:   StatusDownstreamError,
+	HandlerErrorTypeDownstreamTimeout: StatusDownstreamTimeout,
+}
+
+// HTTPStatusFromHandlerErrorType returns the HTTP status code handlers respond with to requests failed with a
+// [HandlerError] of the given type. Unknown types map to 500.
+func HTTPStatusFromHandlerErrorType(typ HandlerErrorType) int {
+	if code, ok := handlerErrorTypeStatusCodes[typ]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// HandlerErrorTypeFromHTTPStatus returns the [HandlerErrorType] a failed response with the given status code
+// represents, or an empty type if the status code does not represent a handler error.
+func HandlerErrorTypeFromHTTPStatus(code int) HandlerErrorType {
+	for typ, c := range handlerErrorTypeStatusCodes {
+		if c == code {
+			return typ
+		}
+	}
+	return ""
+}
+
+// FormatDurationParam formats a duration for the [QueryWait] and [QueryLease] query params.
+func FormatDurationParam(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// NewStartOperationHTTPRequest creates an HTTP request to start an operation at the service with the given base URL,
+// encoding the options as [Client.StartOperation] does. A request ID is generated if none is set and the request
+// timeout is derived from the context deadline. The input's length header is sent as the request's Content-Length,
+// inputs of unknown length are streamed with chunked transfer encoding.
+//
+// Use this to build compliant start requests outside of the [Client], e.g. in gateways and test tools.
+func NewStartOperationHTTPRequest(ctx context.Context, serviceBaseURL, operation string, input *Reader, options StartOperationOptions) (*http.Request, error) {
+	base, err := url.Parse(serviceBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if options.ServiceBasePath != "" {
+		base = base.JoinPath(options.ServiceBasePath)
+	}
+	return newStartOperationHTTPRequest(ctx, PathBuilder{}.withDefaults("").operationURL(base, operation), input, options)
+}
+
+// newStartOperationHTTPRequest constructs a start request to the given operation URL.
+func newStartOperationHTTPRequest(ctx context.Context, u *url.URL, input *Reader, options StartOperationOptions) (*http.Request, error) {
+	if options.CallbackURL != "" {
+		q := u.Query()
+		q.Set(QueryCallbackURL, options.CallbackURL)
+		u.RawQuery = q.Encode()
+	}
+	var body io.Reader = http.NoBody
+	if input.ReadCloser != nil {
+		body = input
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if input.ReadCloser != nil {
+		// Bodies of unknown length are sent with chunked transfer encoding.
+		request.ContentLength = -1
+		if n, err := strconv.ParseInt(input.Header["length"], 10, 64); err == nil && n >= 0 {
+			request.ContentLength = n
+		}
+	}
+
+	if options.RequestID == "" {
+		options.RequestID = uuid.NewString()
+	}
+	request.Header.Set(HeaderRequestID, options.RequestID)
+	request.Header.Set(headerUserAgent, userAgent)
+	addContentHeaderToHTTPHeader(input.Header, request.Header)
+	addCallbackHeaderToHTTPHeader(options.CallbackHeader, request.Header)
+	if err := addAdditionalCallbacksToHTTPHeader(options.AdditionalCallbacks, request.Header); err != nil {
+		return nil, err
+	}
+	if err := addLinksToHTTPHeader(options.Links, request.Header); err != nil {
+		return nil, err
+	}
+	addTagsToHTTPHeader(options.Tags, request.Header)
+	addDeadlineToHTTPHeader(options.Deadline, request.Header)
+	addStartTimeToHTTPHeader(options.StartTime, options.StartDelay, request.Header)
+	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	return request, nil
+}
+
+// StartOperationOptionsFromHTTPRequest parses the [StartOperationOptions] of a start request as the handler returned
+// by [NewHTTPHandler] does. The request body is not read. Start delays are resolved to start times using the clock
+// attached to the request's context, see [ClockFromContext].
+func StartOperationOptionsFromHTTPRequest(request *http.Request) (StartOperationOptions, error) {
+	options := StartOperationOptions{
+		RequestID:      request.Header.Get(HeaderRequestID),
+		CallbackURL:    request.URL.Query().Get(QueryCallbackURL),
+		CallbackHeader: prefixStrippedHTTPHeaderToNexusHeader(request.Header, "nexus-callback-"),
+		Header:         httpHeaderToNexusHeader(request.Header, "content-", "nexus-callback-", "nexus-tag-", "nexus-additional-callbacks", "nexus-links"),
+	}
+	var err error
+	if options.AdditionalCallbacks, err = httpHeaderToAdditionalCallbacks(request.Header); err != nil {
+		return options, fmt.Errorf("invalid %q header", HeaderAdditionalCallbacks)
+	}
+	if options.Links, err = httpHeaderToLinks(request.Header); err != nil {
+		return options, fmt.Errorf("invalid %q header", HeaderLinks)
+	}
+	if tags := prefixStrippedHTTPHeaderToNexusHeader(request.Header, "nexus-tag-"); len(tags) > 0 {
+		options.Tags = tags
+	}
+	if deadline := request.Header.Get(HeaderOperationDeadline); deadline != "" {
+		if options.Deadline, err = time.Parse(time.RFC3339Nano, deadline); err != nil {
+			return options, fmt.Errorf("invalid %q header", HeaderOperationDeadline)
+		}
+	}
+	if startTime := request.Header.Get(HeaderOperationStartTime); startTime != "" {
+		if options.StartTime, err = time.Parse(time.RFC3339Nano, startTime); err != nil {
+			return options, fmt.Errorf("invalid %q header", HeaderOperationStartTime)
+		}
+	}
+	if startDelay := request.Header.Get(HeaderOperationStartDelay); startDelay != "" {
+		if options.StartDelay, err = time.ParseDuration(startDelay); err != nil || options.StartDelay < 0 {
+			return options, fmt.Errorf("invalid %q header", HeaderOperationStartDelay)
+		}
+		if delayed := ClockFromContext(request.Context()).Now().Add(options.StartDelay); delayed.After(options.StartTime) {
+			options.StartTime = delayed
+		}
+	}
+	return options, nil
+}
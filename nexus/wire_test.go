@@ -0,0 +1,84 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerErrorTypeHTTPStatus(t *testing.T) {
+	for typ, code := range handlerErrorTypeStatusCodes {
+		require.Equal(t, code, HTTPStatusFromHandlerErrorType(typ))
+		require.Equal(t, typ, HandlerErrorTypeFromHTTPStatus(code))
+	}
+	require.Equal(t, http.StatusInternalServerError, HTTPStatusFromHandlerErrorType("UNKNOWN"))
+	require.Equal(t, HandlerErrorType(""), HandlerErrorTypeFromHTTPStatus(http.StatusOK))
+	require.Equal(t, HandlerErrorType(""), HandlerErrorTypeFromHTTPStatus(StatusOperationFailed))
+}
+
+func TestFormatDurationParam(t *testing.T) {
+	require.Equal(t, "1500ms", FormatDurationParam(1500*time.Millisecond))
+	require.Equal(t, "0ms", FormatDurationParam(0))
+}
+
+func TestStartOperationHTTPRequest_RoundTrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline := time.Now().Add(time.Hour).UTC()
+	options := StartOperationOptions{
+		CallbackURL:         "http://localhost/callback",
+		CallbackHeader:      Header{"token": "secret"},
+		AdditionalCallbacks: []Callback{{URL: "http://localhost/other"}},
+		Tags:                map[string]string{"tenant": "a"},
+		Deadline:            deadline,
+		Header:              Header{"custom": "value"},
+	}
+	input := &Reader{Header: Header{"type": contentTypeJSON}}
+	request, err := NewStartOperationHTTPRequest(ctx, "http://localhost/nexus", "my op", input, options)
+	require.NoError(t, err)
+	require.Equal(t, "POST", request.Method)
+	require.Equal(t, "/nexus/my%20op", request.URL.EscapedPath())
+	require.Equal(t, contentTypeJSON, request.Header.Get("Content-Type"))
+	require.NotEmpty(t, request.Header.Get(HeaderRequestID))
+	require.NotEmpty(t, request.Header.Get(HeaderRequestTimeout))
+
+	parsed, err := StartOperationOptionsFromHTTPRequest(request)
+	require.NoError(t, err)
+	require.Equal(t, request.Header.Get(HeaderRequestID), parsed.RequestID)
+	require.Equal(t, options.CallbackURL, parsed.CallbackURL)
+	require.Equal(t, options.CallbackHeader, parsed.CallbackHeader)
+	require.Equal(t, options.AdditionalCallbacks, parsed.AdditionalCallbacks)
+	require.Equal(t, options.Tags, parsed.Tags)
+	require.True(t, deadline.Equal(parsed.Deadline))
+	require.Equal(t, "value", parsed.Header.Get("custom"))
+	require.Empty(t, parsed.Header.Get("content-type"))
+}
+
+func TestStartOperationHTTPRequest_ContentLength(t *testing.T) {
+	ctx := context.Background()
+	request, err := NewStartOperationHTTPRequest(ctx, "http://localhost/nexus", "op", &Reader{}, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, http.NoBody, request.Body)
+	require.Equal(t, int64(0), request.ContentLength)
+
+	request, err = NewStartOperationHTTPRequest(ctx, "http://localhost/nexus", "op", NewReader(strings.NewReader("abc"), nil), StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), request.ContentLength)
+
+	request, err = NewStartOperationHTTPRequest(ctx, "http://localhost/nexus", "op", NewReader(io.MultiReader(), nil), StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), request.ContentLength)
+}
+
+func TestStartOperationOptionsFromHTTPRequest_InvalidDeadline(t *testing.T) {
+	request, err := http.NewRequest("POST", "http://localhost/nexus/op", nil)
+	require.NoError(t, err)
+	request.Header.Set(HeaderOperationDeadline, "tomorrow")
+	_, err = StartOperationOptionsFromHTTPRequest(request)
+	require.ErrorContains(t, err, HeaderOperationDeadline)
+}